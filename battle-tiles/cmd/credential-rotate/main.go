@@ -0,0 +1,163 @@
+// credential-rotate 把 game_ctrl_account / game_account 的密码密文、game_webhook_endpoint 的签名密钥重新封装到当前主密钥版本。
+// 历史明文（版本号为 0）会被加密；旧版本密文用旧密钥解开后用 active 版本重新加密。
+// 轮换步骤：配置中新增密钥版本并设为 active_version -> 执行本命令 -> 确认无旧版本数据后移除旧密钥。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"battle-tiles/internal/conf"
+	gameModel "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/utils/sealx"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+var (
+	flagconf string
+	dryRun   bool
+	batch    int
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "./configs/config.yaml", "config path, eg: -conf config.yaml")
+	flag.BoolVar(&dryRun, "dry-run", false, "only count rows that need re-encryption")
+	flag.IntVar(&batch, "batch", 200, "rows per batch")
+}
+
+// sealedColumn 需要轮换的密文列及其密钥版本列
+type sealedColumn struct {
+	table string
+	value string
+	ver   string
+	seal  func(kr *sealx.Keyring, plain string) (string, int32, error)
+}
+
+var sealedColumns = []sealedColumn{
+	{table: gameModel.TableNameGameCtrlAccount, value: "pwd_md5", ver: "pwd_key_ver", seal: sealPassword},
+	{table: gameModel.TableNameGameAccount, value: "pwd_md5", ver: "pwd_key_ver", seal: sealPassword},
+	{table: gameModel.TableNameGameWebhookEndpoint, value: "secret", ver: "secret_key_ver", seal: sealRaw},
+}
+
+func sealPassword(kr *sealx.Keyring, plain string) (string, int32, error) {
+	cred, err := plaza.SealCredential(kr, plain)
+	return cred.Sealed, cred.KeyVer, err
+}
+
+func sealRaw(kr *sealx.Keyring, plain string) (string, int32, error) {
+	return kr.Seal(plain)
+}
+
+// sealedRow 密文列的通用读取结构
+type sealedRow struct {
+	ID     int32  `gorm:"column:id"`
+	Value  string `gorm:"column:value"`
+	KeyVer int32  `gorm:"column:key_ver"`
+}
+
+func main() {
+	flag.Parse()
+	logger := log.NewStdLogger(os.Stdout)
+	helper := log.NewHelper(logger)
+
+	c := config.New(config.WithSource(file.NewSource(flagconf)))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+
+	keyring, err := plaza.NewCredentialKeyring(bc.Global)
+	if err != nil {
+		helper.Fatalf("load keyring: %v", err)
+	}
+	helper.Infof("active key v%d, loaded versions %v", keyring.Active(), keyring.Versions())
+
+	// 各平台库逐个处理；未配置多库时退回主库
+	dbs := infra.NewDBMap(bc.Data, logger)
+	if len(dbs) == 0 {
+		dbs = map[string]*gorm.DB{"default": infra.NewPSQL(bc.Data)}
+	}
+	aliases := make([]string, 0, len(dbs))
+	for alias := range dbs {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	failed := 0
+	for _, alias := range aliases {
+		for _, col := range sealedColumns {
+			done, bad, err := rotateColumn(dbs[alias], col, keyring)
+			if err != nil {
+				helper.Errorf("[%s] %s.%s: %v", alias, col.table, col.value, err)
+				failed++
+				continue
+			}
+			failed += bad
+			helper.Infof("[%s] %s.%s: rotated=%d failed=%d dry_run=%v", alias, col.table, col.value, done, bad, dryRun)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d rows/tables failed, see log\n", failed)
+		os.Exit(1)
+	}
+}
+
+// rotateColumn 按 id 顺序分批处理非 active 版本的行；更新时带上原版本号，避免覆盖并发写入的新密文
+func rotateColumn(db *gorm.DB, col sealedColumn, kr *sealx.Keyring) (done, bad int, err error) {
+	active := kr.Active()
+	if dryRun {
+		var n int64
+		err = db.Table(col.table).Where(col.ver+" <> ?", active).Count(&n).Error
+		return int(n), 0, err
+	}
+
+	lastID := int32(0)
+	for {
+		var rows []sealedRow
+		if err = db.Table(col.table).
+			Select(fmt.Sprintf("id, %s AS value, %s AS key_ver", col.value, col.ver)).
+			Where(col.ver+" <> ? AND id > ?", active, lastID).
+			Order("id").
+			Limit(batch).
+			Find(&rows).Error; err != nil {
+			return done, bad, err
+		}
+		if len(rows) == 0 {
+			return done, bad, nil
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			plain := row.Value
+			if row.KeyVer != 0 {
+				if plain, err = kr.Open(row.Value, row.KeyVer); err != nil {
+					log.Errorf("%s id=%d open v%d: %v", col.table, row.ID, row.KeyVer, err)
+					bad++
+					continue
+				}
+			}
+			sealed, ver, err := col.seal(kr, plain)
+			if err != nil {
+				return done, bad, err
+			}
+			res := db.Table(col.table).
+				Where("id = ? AND "+col.ver+" = ?", row.ID, row.KeyVer).
+				Updates(map[string]any{col.value: sealed, col.ver: ver})
+			if res.Error != nil {
+				return done, bad, res.Error
+			}
+			done += int(res.RowsAffected)
+		}
+	}
+}
@@ -8,9 +8,13 @@ package main
 
 import (
 	"battle-tiles/internal/biz"
+	basic2 "battle-tiles/internal/biz/basic"
+	game2 "battle-tiles/internal/biz/game"
 	"battle-tiles/internal/conf"
 	"battle-tiles/internal/dal/repo"
+	"battle-tiles/internal/dal/repo/basic"
 	"battle-tiles/internal/dal/repo/cloud"
+	"battle-tiles/internal/dal/repo/game"
 	"battle-tiles/internal/infra"
 	"battle-tiles/internal/infra/plaza"
 	"battle-tiles/internal/server"
@@ -34,7 +38,11 @@ func wireApp(global *conf.Global, confServer *conf.Server, data *conf.Data, logg
 	if err != nil {
 		return nil, nil, err
 	}
-	manager := plaza.NewManager(global, logger)
+	keyring, err := plaza.NewCredentialKeyring(global)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager := plaza.NewManager(global, keyring, logger)
 	infraData, cleanup, err := infra.NewData(data, logger, client, db, v, v2, manager)
 	if err != nil {
 		return nil, nil, err
@@ -46,14 +54,50 @@ func wireApp(global *conf.Global, confServer *conf.Server, data *conf.Data, logg
 		cleanup()
 		return nil, nil, err
 	}
-	asyNQService := service.NewAsyNQService(logger, asyNQUseCase, basePlatformRepo)
+	reportRepo := game.NewReportRepo(infraData, logger)
+	gameStatsRepo := game.NewStatsRepo(infraData, logger)
+	walletReadRepo := game.NewWalletReadRepo(infraData, logger)
+	feeSettleRepo := game.NewFeeSettleRepo(infraData, logger)
+	leaderboardRepo := game.NewLeaderboardRepo(infraData, logger)
+	leaderboardUseCase := game2.NewLeaderboardUseCase(leaderboardRepo, logger)
+	reportUseCase := game2.NewReportUseCase(reportRepo, gameStatsRepo, walletReadRepo, feeSettleRepo, leaderboardUseCase, logger)
+	auditLogRepo := basic.NewAuditLogRepo(infraData, logger)
+	auditUseCase := basic2.NewAuditUseCase(auditLogRepo, logger)
+	webhookRepo := game.NewWebhookRepo(infraData, logger)
+	taskQueue, cleanup2, err := infra.NewTaskQueue(confServer)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	webhookUseCase := game2.NewWebhookUseCase(webhookRepo, keyring, taskQueue, logger)
+	applicationRuleRepo := game.NewApplicationRuleRepo(infraData, logger)
+	shopApplicationLogRepo := game.NewShopApplicationLogRepo(infraData, logger)
+	userApplicationRepo := game.NewUserApplicationRepo(infraData, logger)
+	gameMemberRepo := game.NewGameMemberRepo(infraData, logger)
+	gameAccountRepo := game.NewGameAccountRepo(infraData, logger)
+	houseSettingsRepo := game.NewHouseSettingsRepo(infraData, logger)
+	gameShopAdminRepo := game.NewShopAdminRepo(infraData, logger)
+	authRepo := basic.NewAuthRepo(infraData, logger)
+	onboardingRepo := game.NewOnboardingRepo(infraData, logger)
+	shopGroupRepo := game.NewShopGroupRepo(infraData, logger)
+	shopGroupMemberRepo := game.NewShopGroupMemberRepo(infraData, logger)
+	walletRepo := game.NewWalletRepo(infraData, logger)
+	memberRuleRepo := game.NewMemberRuleRepo(infraData, logger)
+	fundsUseCase := game2.NewFundsUseCase(walletRepo, walletReadRepo)
+	onboardingUseCase := game2.NewOnboardingUseCase(onboardingRepo, gameMemberRepo, shopGroupRepo, shopGroupMemberRepo, userApplicationRepo, walletRepo, memberRuleRepo, houseSettingsRepo, fundsUseCase, logger)
+	blocklistRepo := game.NewBlocklistRepo(infraData, logger)
+	blocklistUseCase := game2.NewBlocklistUseCase(blocklistRepo, logger)
+	applicationUseCase := game2.NewApplicationUseCase(applicationRuleRepo, shopApplicationLogRepo, userApplicationRepo, gameMemberRepo, gameAccountRepo, houseSettingsRepo, gameShopAdminRepo, authRepo, manager, onboardingUseCase, blocklistUseCase, logger)
+	asyNQService := service.NewAsyNQService(logger, asyNQUseCase, basePlatformRepo, reportUseCase, auditUseCase, webhookUseCase, applicationUseCase)
 	asynqServer, err := server.NewAsyNQServer(confServer, logger, asyNQService)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
 	app := newApp(logger, asynqServer)
 	return app, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
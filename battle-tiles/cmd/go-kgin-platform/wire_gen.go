@@ -41,7 +41,11 @@ func wireApp(global *conf.Global, confServer *conf.Server, data *conf.Data, logg
 	if err != nil {
 		return nil, nil, err
 	}
-	manager := plaza.NewManager(global, logger)
+	keyring, err := plaza.NewCredentialKeyring(global)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager := plaza.NewManager(global, keyring, logger)
 	infraData, cleanup, err := infra.NewData(data, logger, client, db, v, v2, manager)
 	if err != nil {
 		return nil, nil, err
@@ -52,78 +56,131 @@ func wireApp(global *conf.Global, confServer *conf.Server, data *conf.Data, logg
 	basicUserService := basic3.NewBasicUserService(basicUserUseCase, store)
 	basicLoginRepo := basic.NewBasicLoginRepo(infraData, logger)
 	authRepo := basic.NewAuthRepo(infraData, logger)
+	loginSessionRepo := basic.NewLoginSessionRepo(infraData, logger)
+	loginSessionUseCase := basic2.NewLoginSessionUseCase(loginSessionRepo, authRepo, store, logger)
+	loginSecurityRepo := basic.NewLoginSecurityRepo(infraData, logger)
+	loginSecurityUseCase := basic2.NewLoginSecurityUseCase(loginSecurityRepo, logger)
 	gameAccountRepo := game.NewGameAccountRepo(infraData, logger)
 	gameCtrlAccountRepo := game.NewCtrlAccountRepo(infraData, logger)
 	gameAccountHouseRepo := game.NewGameAccountHouseRepo(infraData, logger)
 	sessionRepo := game.NewSessionRepo(infraData)
-	gameAccountUseCase := game2.NewGameAccountUseCase(gameAccountRepo, gameCtrlAccountRepo, gameAccountHouseRepo, sessionRepo, manager, logger)
-	basicLoginUseCase := basic2.NewBasicLoginUseCase(basicLoginRepo, global, authRepo, gameAccountUseCase, logger)
-	basicLoginService := basic3.NewBasicLoginService(basicLoginUseCase)
+	blocklistRepo := game.NewBlocklistRepo(infraData, logger)
+	blocklistUseCase := game2.NewBlocklistUseCase(blocklistRepo, logger)
+	gameAccountUseCase := game2.NewGameAccountUseCase(gameAccountRepo, gameCtrlAccountRepo, gameAccountHouseRepo, sessionRepo, manager, keyring, blocklistUseCase, logger)
+	basicLoginUseCase := basic2.NewBasicLoginUseCase(basicLoginRepo, global, authRepo, gameAccountUseCase, loginSessionUseCase, loginSecurityUseCase, logger)
+	basicLoginService := basic3.NewBasicLoginService(basicLoginUseCase, loginSessionUseCase)
 	basicMenuRepo := basic.NewBaseMenuRepo(infraData, logger)
 	baseRoleMenuRelRepo := basic.NewBaseRoleMenuRelRepo(infraData, logger)
 	baseRoleMenuBtnRelRepo := basic.NewBaseRoleMenuBtnRelRepo(infraData, logger)
 	basicMenuUseCase := basic2.NewBasicMenuUseCase(global, basicMenuRepo, baseRoleMenuRelRepo, authRepo, baseRoleMenuBtnRelRepo, logger)
 	basicMenuService := basic3.NewBasicMenuService(basicMenuUseCase, logger)
-	basicRoleService := basic3.NewBasicRoleService(infraData)
+	basicRoleService := basic3.NewBasicRoleService(infraData, loginSessionUseCase)
 	permissionRepo := basic.NewPermissionRepo(infraData, logger)
-	basicPermissionService := basic3.NewBasicPermissionService(permissionRepo)
-	basicRouter := router.NewBasicRouter(basicUserService, basicLoginService, basicMenuService, basicRoleService, basicPermissionService)
+	basicPermissionService := basic3.NewBasicPermissionService(permissionRepo, loginSessionUseCase)
+	userScopeRoleRepo := basic.NewUserScopeRoleRepo(infraData, logger)
+	userScopeRoleUseCase := basic2.NewUserScopeRoleUseCase(userScopeRoleRepo, store, logger)
+	basicScopeRoleService := basic3.NewBasicScopeRoleService(userScopeRoleUseCase)
+	auditLogRepo := basic.NewAuditLogRepo(infraData, logger)
+	auditUseCase := basic2.NewAuditUseCase(auditLogRepo, logger)
+	basicAuditService := basic3.NewBasicAuditService(auditUseCase)
+	basicSecurityService := basic3.NewBasicSecurityService(loginSecurityUseCase)
+	apiKeyRepo := basic.NewAPIKeyRepo(infraData, logger)
+	apiKeyUseCase := basic2.NewAPIKeyUseCase(apiKeyRepo, store, logger)
+	basicAPIKeyService := basic3.NewBasicAPIKeyService(apiKeyUseCase)
+	basicRouter := router.NewBasicRouter(basicUserService, basicLoginService, basicMenuService, basicRoleService, basicPermissionService, basicScopeRoleService, basicAuditService, basicSecurityService, basicAPIKeyService)
 	accountService := game3.NewAccountService(gameAccountUseCase)
 	gameCtrlAccountHouseRepo := game.NewCtrlAccountHouseRepo(infraData, logger)
 	battleRecordRepo := game.NewBattleRecordRepo(infraData, logger)
-	battleSyncManager := game2.NewBattleSyncManager(battleRecordRepo, infraData, logger)
-	ctrlSessionUseCase := game2.NewCtrlSessionUseCase(gameCtrlAccountRepo, gameCtrlAccountHouseRepo, sessionRepo, manager, battleSyncManager, logger)
-	sessionService := game3.NewSessionService(ctrlSessionUseCase)
+	leaderboardRepo := game.NewLeaderboardRepo(infraData, logger)
+	leaderboardUseCase := game2.NewLeaderboardUseCase(leaderboardRepo, logger)
+	battleConfigRepo := game.NewBattleConfigRepo(infraData, logger)
+	battleConfigUseCase := game2.NewBattleConfigUseCase(battleConfigRepo, manager, logger)
+	basePlatformRepo, err := cloud.NewBasePlatformRepo(infraData, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	battleWatermarkRepo := game.NewBattleWatermarkRepo(infraData, logger)
+	groResolver := game2.NewGroResolver(global, basePlatformRepo, logger)
+	battleSyncManager := game2.NewBattleSyncManager(battleRecordRepo, infraData, leaderboardUseCase, battleConfigUseCase, manager, battleWatermarkRepo, groResolver, logger)
+	applicationRuleRepo := game.NewApplicationRuleRepo(infraData, logger)
+	shopApplicationLogRepo := game.NewShopApplicationLogRepo(infraData, logger)
+	userApplicationRepo := game.NewUserApplicationRepo(infraData, logger)
+	gameMemberRepo := game.NewGameMemberRepo(infraData, logger)
+	houseSettingsRepo := game.NewHouseSettingsRepo(infraData, logger)
+	diamondRepo := game.NewDiamondRepo(infraData, logger)
+	diamondUseCase := game2.NewDiamondUseCase(diamondRepo, houseSettingsRepo, battleRecordRepo, manager, logger)
+	memberSyncUseCase := game2.NewMemberSyncUseCase(gameMemberRepo, logger)
+	gameShopAdminRepo := game.NewShopAdminRepo(infraData, logger)
+	onboardingRepo := game.NewOnboardingRepo(infraData, logger)
+	shopGroupRepo := game.NewShopGroupRepo(infraData, logger)
+	shopGroupMemberRepo := game.NewShopGroupMemberRepo(infraData, logger)
 	walletRepo := game.NewWalletRepo(infraData, logger)
+	memberRuleRepo := game.NewMemberRuleRepo(infraData, logger)
 	walletReadRepo := game.NewWalletReadRepo(infraData, logger)
 	fundsUseCase := game2.NewFundsUseCase(walletRepo, walletReadRepo)
+	onboardingUseCase := game2.NewOnboardingUseCase(onboardingRepo, gameMemberRepo, shopGroupRepo, shopGroupMemberRepo, userApplicationRepo, walletRepo, memberRuleRepo, houseSettingsRepo, fundsUseCase, logger)
+	applicationUseCase := game2.NewApplicationUseCase(applicationRuleRepo, shopApplicationLogRepo, userApplicationRepo, gameMemberRepo, gameAccountRepo, houseSettingsRepo, gameShopAdminRepo, authRepo, manager, onboardingUseCase, blocklistUseCase, logger)
+	ctrlSessionUseCase := game2.NewCtrlSessionUseCase(gameCtrlAccountRepo, gameCtrlAccountHouseRepo, sessionRepo, manager, battleSyncManager, applicationUseCase, blocklistUseCase, battleConfigUseCase, diamondUseCase, memberSyncUseCase, logger)
+	sessionService := game3.NewSessionService(ctrlSessionUseCase)
 	fundsService := game3.NewFundsService(fundsUseCase, manager)
-	ctrlAccountUseCase := game2.NewCtrlAccountUseCase(gameCtrlAccountRepo, gameCtrlAccountHouseRepo, gameAccountRepo, manager, logger)
+	ctrlAccountUseCase := game2.NewCtrlAccountUseCase(gameCtrlAccountRepo, gameCtrlAccountHouseRepo, gameAccountRepo, manager, keyring, logger)
 	ctrlAccountService := game3.NewCtrlAccountService(ctrlAccountUseCase)
-	gameShopAdminRepo := game.NewShopAdminRepo(infraData, logger)
-	shopGroupRepo := game.NewShopGroupRepo(infraData, logger)
-	shopAdminUseCase := game2.NewShopAdminUseCase(gameShopAdminRepo, shopGroupRepo, basicUserRepo, logger)
+	shopAdminUseCase := game2.NewShopAdminUseCase(gameShopAdminRepo, shopGroupRepo, basicUserRepo, userScopeRoleRepo, store, logger)
 	shopAdminService := game3.NewShopAdminService(shopAdminUseCase, basicUserRepo)
 	shopTableService := game3.NewShopTableService(manager)
-	memberRuleRepo := game.NewMemberRuleRepo(infraData, logger)
 	memberRuleUseCase := game2.NewMemberRuleUseCase(memberRuleRepo, logger)
-	userApplicationRepo := game.NewUserApplicationRepo(infraData, logger)
-	gameShopMemberService := game3.NewGameShopMemberService(manager, memberRuleUseCase, gameShopAdminRepo, basicUserRepo, userApplicationRepo)
+	gameShopMemberService := game3.NewGameShopMemberService(manager, memberRuleUseCase, gameShopAdminRepo, basicUserRepo, userApplicationRepo, blocklistUseCase)
 	gameStatsRepo := game.NewStatsRepo(infraData, logger)
 	gameStatsUseCase := game2.NewGameStatsUseCase(gameStatsRepo, logger)
 	gameStatsService := game3.NewGameStatsService(gameStatsUseCase, shopAdminUseCase, manager)
-	walletQueryService := game3.NewWalletQueryService(fundsUseCase)
-	shopApplicationLogRepo := game.NewShopApplicationLogRepo(infraData, logger)
-	shopApplicationService := game3.NewShopApplicationService(manager, userApplicationRepo, basicUserRepo, authRepo, gameShopAdminRepo, shopApplicationLogRepo)
+	walletQueryService := game3.NewWalletQueryService(fundsUseCase, groResolver)
+	shopApplicationService := game3.NewShopApplicationService(manager, userApplicationRepo, basicUserRepo, authRepo, gameShopAdminRepo, applicationUseCase)
 	gameGroupService := game3.NewGameGroupService(manager)
-	houseSettingsRepo := game.NewHouseSettingsRepo(infraData, logger)
 	feeSettleRepo := game.NewFeeSettleRepo(infraData, logger)
 	houseSettingsUseCase := game2.NewHouseSettingsUseCase(houseSettingsRepo, feeSettleRepo, logger)
 	houseSettingsService := game3.NewHouseSettingsService(houseSettingsUseCase)
-	gameMemberRepo := game.NewGameMemberRepo(infraData, logger)
-	battleRecordUseCase := game2.NewBattleRecordUseCase(battleRecordRepo, gameCtrlAccountRepo, gameCtrlAccountHouseRepo, gameAccountRepo, gameMemberRepo, houseSettingsRepo, feeSettleRepo, logger)
+	battleRecordUseCase := game2.NewBattleRecordUseCase(battleRecordRepo, gameCtrlAccountRepo, gameCtrlAccountHouseRepo, gameAccountRepo, gameMemberRepo, houseSettingsRepo, feeSettleRepo, leaderboardUseCase, battleConfigUseCase, logger)
 	battleRecordService := game3.NewBattleRecordService(battleRecordUseCase)
-	shopGroupMemberRepo := game.NewShopGroupMemberRepo(infraData, logger)
-	shopGroupUseCase := game2.NewShopGroupUseCase(shopGroupRepo, shopGroupMemberRepo, gameShopAdminRepo, logger)
+	shopGroupUseCase := game2.NewShopGroupUseCase(shopGroupRepo, shopGroupMemberRepo, gameShopAdminRepo, manager, logger)
 	shopGroupService := game3.NewShopGroupService(shopGroupUseCase, logger)
 	memberUseCase := game2.NewMemberUseCase(basicUserRepo, gameShopAdminRepo, logger)
 	memberService := game3.NewMemberService(memberUseCase, logger)
-	gameRouter := router.NewGameRouter(accountService, sessionService, fundsService, ctrlAccountService, shopAdminService, shopTableService, gameShopMemberService, gameStatsService, walletQueryService, shopApplicationService, gameGroupService, houseSettingsService, battleRecordService, shopGroupService, memberService)
-	opsService := service.NewOpsService(manager)
-	opsRouter := router.NewOpsRouter(opsService)
-	basePlatformRepo, err := cloud.NewBasePlatformRepo(infraData, logger)
+	groupSettlementRepo := game.NewGroupSettlementRepo(infraData, logger)
+	groupSettlementUseCase := game2.NewGroupSettlementUseCase(groupSettlementRepo, shopGroupRepo, feeSettleRepo, gameShopAdminRepo, logger)
+	groupSettlementService := game3.NewGroupSettlementService(groupSettlementUseCase)
+	leaderboardService := game3.NewLeaderboardService(leaderboardUseCase)
+	playerProfileUseCase := game2.NewPlayerProfileUseCase(gameAccountRepo, gameMemberRepo, walletReadRepo, memberRuleRepo, battleRecordRepo, userApplicationRepo, manager, logger)
+	playerProfileService := game3.NewPlayerProfileService(playerProfileUseCase)
+	reportRepo := game.NewReportRepo(infraData, logger)
+	reportUseCase := game2.NewReportUseCase(reportRepo, gameStatsRepo, walletReadRepo, feeSettleRepo, leaderboardUseCase, logger)
+	reportService := game3.NewReportService(reportUseCase)
+	webhookRepo := game.NewWebhookRepo(infraData, logger)
+	taskQueue, cleanup2, err := infra.NewTaskQueue(confServer)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
+	webhookUseCase := game2.NewWebhookUseCase(webhookRepo, keyring, taskQueue, logger)
+	webhookService := game3.NewWebhookService(webhookUseCase)
+	applicationRuleService := game3.NewApplicationRuleService(applicationUseCase)
+	onboardingService := game3.NewOnboardingService(onboardingUseCase)
+	blocklistService := game3.NewBlocklistService(blocklistUseCase)
+	battleConfigService := game3.NewBattleConfigService(battleConfigUseCase)
+	diamondService := game3.NewDiamondService(diamondUseCase)
+	battleSyncService := game3.NewBattleSyncService(battleSyncManager)
+	gameRouter := router.NewGameRouter(accountService, sessionService, fundsService, ctrlAccountService, shopAdminService, shopTableService, gameShopMemberService, gameStatsService, walletQueryService, shopApplicationService, gameGroupService, houseSettingsService, battleRecordService, shopGroupService, memberService, groupSettlementService, leaderboardService, playerProfileService, reportService, webhookService, applicationRuleService, onboardingService, blocklistService, battleConfigService, diamondService, battleSyncService)
+	opsService := service.NewOpsService(manager)
+	opsRouter := router.NewOpsRouter(opsService)
 	platformUsecase := cloud2.NewPlatformUsecase(basePlatformRepo, logger)
 	platformService := service.NewPlatformService(platformUsecase)
 	rootRouter := router.NewRootRouter(basicRouter, gameRouter, opsRouter, platformService)
-	ginServer := server.NewHTTPServer(confServer, logger, rootRouter, infraData)
-	sessionMonitor := service.NewSessionMonitor(logger, manager, basePlatformRepo, gameCtrlAccountHouseRepo, sessionRepo, gameCtrlAccountRepo, battleSyncManager, ctrlSessionUseCase)
+	ginServer := server.NewHTTPServer(confServer, logger, rootRouter, infraData, auditUseCase)
+	sessionMonitor := service.NewSessionMonitor(logger, manager, basePlatformRepo, gameCtrlAccountHouseRepo, sessionRepo, gameCtrlAccountRepo, battleSyncManager, ctrlSessionUseCase, applicationUseCase)
 	transportServer := server.NewMonitorServer(sessionMonitor)
 	app := newApp(logger, ginServer, transportServer)
 	return app, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,335 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	rbacstore "battle-tiles/internal/dal/repo/rbac"
+	"battle-tiles/pkg/plugin/auditx"
+	"battle-tiles/pkg/plugin/middleware"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+)
+
+// API Key 格式：btk_<8 位十六进制前缀>_<随机串>；前缀明文入库用于定位，整串只存 sha256
+const (
+	apiKeyScheme        = "btk"
+	apiKeyPrefixBytes   = 4
+	apiKeySecretBytes   = 32
+	serviceUserPrefix   = "svc_"
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("API Key 无效")
+	ErrAPIKeyInactive = errors.New("API Key 已过期或已吊销")
+)
+
+// IssueAPIKeyInput 签发参数
+type IssueAPIKeyInput struct {
+	UserID    int32
+	Name      string
+	Perms     []string
+	HouseGIDs []int32
+	ExpiresAt *time.Time
+}
+
+// APIKeyUseCase 服务账号与 API Key：供运维脚本、合作方机器人调用资金/成员等接口，
+// 不再需要以真人账号登录。Key 的权限为显式列出的权限码，可再限定店铺。
+type APIKeyUseCase struct {
+	repo basicRepo.APIKeyRepo
+	rbac apiKeyPermStore
+	log  *log.Helper
+}
+
+// apiKeyPermStore 签发时校验操作人自身权限（全局 + 店铺作用域）
+type apiKeyPermStore interface {
+	middleware.PermissionStore
+	middleware.ScopedPermissionStore
+}
+
+func NewAPIKeyUseCase(repo basicRepo.APIKeyRepo, rbac *rbacstore.Store, logger log.Logger) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		repo: repo,
+		rbac: rbac,
+		log:  log.NewHelper(log.With(logger, "module", "usecase/api_key")),
+	}
+}
+
+// CreateServiceAccount 新建服务账号：role=service、无密码，无法通过用户名密码登录
+func (uc *APIKeyUseCase) CreateServiceAccount(ctx context.Context, username, nickname string) (*basicModel.BasicUser, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+	if !strings.HasPrefix(username, serviceUserPrefix) {
+		username = serviceUserPrefix + username
+	}
+	if nickname == "" {
+		nickname = username
+	}
+	u := &basicModel.BasicUser{
+		Username: truncate(username, 50),
+		NickName: truncate(nickname, 50),
+		Role:     basicModel.UserRoleService,
+	}
+	if err := uc.repo.CreateServiceAccount(ctx, u); err != nil {
+		return nil, errors.Wrap(err, "create service account")
+	}
+	auditx.SetTarget(ctx, "user", u.Id)
+	return u, nil
+}
+
+func (uc *APIKeyUseCase) ListServiceAccounts(ctx context.Context) ([]*basicModel.BasicUser, error) {
+	return uc.repo.ListServiceAccounts(ctx)
+}
+
+// Issue 签发 Key，明文只在此返回一次。非超管不能授出自己没有的权限，且必须限定到自己管理的店铺。
+func (uc *APIKeyUseCase) Issue(ctx context.Context, operatorID int32, operatorIsSuper bool, in IssueAPIKeyInput) (*basicModel.BasicAPIKey, string, error) {
+	acct, err := uc.repo.GetUser(ctx, in.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if acct == nil || !acct.IsService() {
+		return nil, "", errors.New("服务账号不存在")
+	}
+	perms := normalizePerms(in.Perms)
+	if len(perms) == 0 {
+		return nil, "", errors.New("perms is required")
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+	houses := normalizeHouseGIDs(in.HouseGIDs)
+	if !operatorIsSuper {
+		if err = uc.checkOperatorGrant(ctx, operatorID, perms, houses); err != nil {
+			return nil, "", err
+		}
+	}
+
+	prefix, key, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	k := &basicModel.BasicAPIKey{
+		UserID:    acct.Id,
+		Name:      truncate(strings.TrimSpace(in.Name), 64),
+		Prefix:    prefix,
+		KeyHash:   hashToken(key),
+		Perms:     perms,
+		HouseGIDs: houses,
+		ExpiresAt: in.ExpiresAt,
+		CreatedBy: operatorID,
+		CreatedAt: time.Now(),
+	}
+	if err = uc.repo.Create(ctx, k); err != nil {
+		return nil, "", errors.Wrap(err, "save api key")
+	}
+	auditx.SetTarget(ctx, "api_key", k.Id)
+	uc.log.Infof("api key issued: id=%d prefix=%s account=%d by=%d", k.Id, k.Prefix, k.UserID, operatorID)
+	return k, key, nil
+}
+
+// checkOperatorGrant 非超管签发：必须限定店铺且只能是自己有权限的店铺，
+// 每个权限码需在全局或在每个所列店铺内都持有
+func (uc *APIKeyUseCase) checkOperatorGrant(ctx context.Context, operatorID int32, perms []string, houses []int32) error {
+	if len(houses) == 0 {
+		return errors.New("house_gids is required")
+	}
+	own, err := uc.rbac.GetUserPermCodes(ctx, operatorID)
+	if err != nil {
+		return err
+	}
+	scoped := make([]map[string]struct{}, 0, len(houses))
+	for _, h := range houses {
+		codes, err := uc.rbac.GetUserHousePermCodes(ctx, operatorID, h, 0)
+		if err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return errors.Errorf("不能授予自己无权管理的店铺: %d", h)
+		}
+		scoped = append(scoped, codes)
+	}
+	for _, p := range perms {
+		if _, ok := own[p]; ok {
+			continue
+		}
+		for i, codes := range scoped {
+			if _, ok := codes[p]; !ok {
+				return errors.Errorf("不能授予自己没有的权限: %s（店铺 %d）", p, houses[i])
+			}
+		}
+	}
+	return nil
+}
+
+// List userID > 0 时只列该服务账号的 Key；非超管只能看到自己签发的或限定店铺都归自己管理的 Key
+func (uc *APIKeyUseCase) List(ctx context.Context, operatorID int32, operatorIsSuper bool, userID int32) ([]*basicModel.BasicAPIKey, error) {
+	list, err := uc.repo.List(ctx, userID)
+	if err != nil || operatorIsSuper {
+		return list, err
+	}
+	houses := map[int32]bool{}
+	out := make([]*basicModel.BasicAPIKey, 0, len(list))
+	for _, k := range list {
+		ok, err := uc.operatorCanManage(ctx, operatorID, k, houses)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+// Revoke 吊销 Key，立即生效；非超管的可见范围同 List
+func (uc *APIKeyUseCase) Revoke(ctx context.Context, id, operatorID int32, operatorIsSuper bool) error {
+	if !operatorIsSuper {
+		k, err := uc.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		ok := false
+		if k != nil {
+			if ok, err = uc.operatorCanManage(ctx, operatorID, k, map[int32]bool{}); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return errors.New("API Key 不存在或已吊销")
+		}
+	}
+	ok, err := uc.repo.Revoke(ctx, id, operatorID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("API Key 不存在或已吊销")
+	}
+	auditx.SetTarget(ctx, "api_key", id)
+	return nil
+}
+
+// operatorCanManage 自己签发的，或限定的店铺操作人都有权限（不限店铺的 Key 只有超管能签发，也只归超管管理）；
+// houses 缓存操作人对各店铺的判断结果
+func (uc *APIKeyUseCase) operatorCanManage(ctx context.Context, operatorID int32, k *basicModel.BasicAPIKey, houses map[int32]bool) (bool, error) {
+	if k.CreatedBy == operatorID {
+		return true, nil
+	}
+	if len(k.HouseGIDs) == 0 {
+		return false, nil
+	}
+	for _, h := range k.HouseGIDs {
+		own, ok := houses[h]
+		if !ok {
+			codes, err := uc.rbac.GetUserHousePermCodes(ctx, operatorID, h, 0)
+			if err != nil {
+				return false, err
+			}
+			own = len(codes) > 0
+			houses[h] = own
+		}
+		if !own {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// VerifyAPIKey 实现 middleware.APIKeyVerifier
+func (uc *APIKeyUseCase) VerifyAPIKey(ctx context.Context, key, clientIP string) (*middleware.APIKeyPrincipal, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != apiKeyPrefixBytes*2 {
+		return nil, ErrAPIKeyInvalid
+	}
+	k, err := uc.repo.GetByPrefix(ctx, parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "load api key")
+	}
+	if k == nil || subtle.ConstantTimeCompare([]byte(hashToken(key)), []byte(k.KeyHash)) != 1 {
+		return nil, ErrAPIKeyInvalid
+	}
+	now := time.Now()
+	if !k.Active(now) {
+		return nil, ErrAPIKeyInactive
+	}
+	acct, err := uc.repo.GetUser(ctx, k.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil || !acct.IsService() {
+		return nil, ErrAPIKeyInactive
+	}
+	// 最近使用时间按分钟粒度记录，避免每个请求都写库
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval || k.LastUsedIP != clientIP {
+		if err = uc.repo.TouchLastUsed(ctx, k.Id, now, clientIP); err != nil {
+			uc.log.Warnf("touch api key %d failed: %v", k.Id, err)
+		}
+	}
+	return &middleware.APIKeyPrincipal{
+		KeyID:     k.Id,
+		UserID:    acct.Id,
+		Username:  acct.Username,
+		Perms:     k.Perms,
+		HouseGIDs: k.HouseGIDs,
+	}, nil
+}
+
+func newAPIKey() (prefix, key string, err error) {
+	b := make([]byte, apiKeyPrefixBytes)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+	secret, err := randomToken(apiKeySecretBytes)
+	if err != nil {
+		return "", "", err
+	}
+	// randomToken 为 RawURL base64，可能含 '_'，换成 '-' 以免和分隔符冲突
+	secret = strings.ReplaceAll(secret, "_", "-")
+	return prefix, apiKeyScheme + "_" + prefix + "_" + secret, nil
+}
+
+func normalizePerms(in []string) []string {
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func normalizeHouseGIDs(in []int32) []int32 {
+	seen := make(map[int32]struct{}, len(in))
+	out := make([]int32, 0, len(in))
+	for _, h := range in {
+		if h <= 0 {
+			continue
+		}
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,239 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memAPIKeys 内存版 API Key 存储
+type memAPIKeys struct {
+	basicRepo.APIKeyRepo
+	users map[int32]*basicModel.BasicUser
+	keys  map[int32]*basicModel.BasicAPIKey
+}
+
+func newMemAPIKeys() *memAPIKeys {
+	svc := &basicModel.BasicUser{Username: "svc_bot", Role: basicModel.UserRoleService}
+	svc.Id = 10
+	human := &basicModel.BasicUser{Username: "alice"}
+	human.Id = 11
+	return &memAPIKeys{
+		users: map[int32]*basicModel.BasicUser{10: svc, 11: human},
+		keys:  map[int32]*basicModel.BasicAPIKey{},
+	}
+}
+
+func (r *memAPIKeys) GetUser(_ context.Context, id int32) (*basicModel.BasicUser, error) {
+	return r.users[id], nil
+}
+
+func (r *memAPIKeys) Create(_ context.Context, k *basicModel.BasicAPIKey) error {
+	k.Id = int32(len(r.keys) + 1)
+	r.keys[k.Id] = k
+	return nil
+}
+
+func (r *memAPIKeys) GetByPrefix(_ context.Context, prefix string) (*basicModel.BasicAPIKey, error) {
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memAPIKeys) Get(_ context.Context, id int32) (*basicModel.BasicAPIKey, error) {
+	return r.keys[id], nil
+}
+
+func (r *memAPIKeys) List(_ context.Context, userID int32) ([]*basicModel.BasicAPIKey, error) {
+	var out []*basicModel.BasicAPIKey
+	for id := int32(1); id <= int32(len(r.keys)); id++ {
+		if k := r.keys[id]; k != nil && (userID <= 0 || k.UserID == userID) {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (r *memAPIKeys) Revoke(_ context.Context, id, operator int32, at time.Time) (bool, error) {
+	k, ok := r.keys[id]
+	if !ok || k.RevokedAt != nil {
+		return false, nil
+	}
+	k.RevokedAt, k.RevokedBy = &at, operator
+	return true, nil
+}
+
+func (r *memAPIKeys) TouchLastUsed(_ context.Context, id int32, at time.Time, ip string) error {
+	r.keys[id].LastUsedAt, r.keys[id].LastUsedIP = &at, ip
+	return nil
+}
+
+// fakePermStore 全局权限 + 按店铺的作用域权限
+type fakePermStore struct {
+	global map[string]struct{}
+	house  map[int32]map[string]struct{}
+}
+
+func (s *fakePermStore) GetUserPermCodes(context.Context, int32) (map[string]struct{}, error) {
+	return s.global, nil
+}
+
+func (s *fakePermStore) GetUserHousePermCodes(_ context.Context, _ int32, houseGID, _ int32) (map[string]struct{}, error) {
+	return s.house[houseGID], nil
+}
+
+func newAPIKeyUC(repo *memAPIKeys, perms *fakePermStore) *APIKeyUseCase {
+	return &APIKeyUseCase{repo: repo, rbac: perms, log: log.NewHelper(log.DefaultLogger)}
+}
+
+func TestAPIKeyIssueLimitsHouses(t *testing.T) {
+	ctx := context.Background()
+	perms := &fakePermStore{
+		global: map[string]struct{}{"member:view": {}},
+		house: map[int32]map[string]struct{}{
+			100: {"fund:deposit": {}, "fund:withdraw": {}},
+			200: {"fund:deposit": {}},
+		},
+	}
+	uc := newAPIKeyUC(newMemAPIKeys(), perms)
+
+	cases := []struct {
+		name   string
+		perms  []string
+		houses []int32
+		ok     bool
+	}{
+		{"no houses", []string{"fund:deposit"}, nil, false},
+		{"foreign house", []string{"fund:deposit"}, []int32{100, 300}, false},
+		{"perm missing in one house", []string{"fund:withdraw"}, []int32{100, 200}, false},
+		{"perm held in every house", []string{"fund:deposit"}, []int32{200, 100, 100}, true},
+		{"global perm", []string{"member:view"}, []int32{200}, true},
+	}
+	for _, tc := range cases {
+		k, key, err := uc.Issue(ctx, 1, false, IssueAPIKeyInput{UserID: 10, Perms: tc.perms, HouseGIDs: tc.houses})
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+		if tc.ok && (key == "" || len(k.HouseGIDs) == 0) {
+			t.Fatalf("%s: key=%q houses=%v", tc.name, key, k.HouseGIDs)
+		}
+	}
+
+	k, _, err := uc.Issue(ctx, 1, false, IssueAPIKeyInput{UserID: 10, Perms: []string{"fund:deposit"}, HouseGIDs: []int32{200, 100, 200}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(k.HouseGIDs, []int32{100, 200}) {
+		t.Fatalf("houses = %v, want deduplicated [100 200]", k.HouseGIDs)
+	}
+}
+
+func TestAPIKeyIssueSuperAndAccountChecks(t *testing.T) {
+	ctx := context.Background()
+	uc := newAPIKeyUC(newMemAPIKeys(), &fakePermStore{})
+
+	k, _, err := uc.Issue(ctx, 1, true, IssueAPIKeyInput{UserID: 10, Perms: []string{"fund:deposit"}})
+	if err != nil {
+		t.Fatalf("super admin issue: %v", err)
+	}
+	if k.HouseGIDs == nil || len(k.HouseGIDs) != 0 {
+		t.Fatalf("unrestricted key houses = %#v, want empty slice", k.HouseGIDs)
+	}
+	if _, _, err = uc.Issue(ctx, 1, true, IssueAPIKeyInput{UserID: 11, Perms: []string{"fund:deposit"}}); err == nil {
+		t.Fatal("issuing a key for a non-service account should fail")
+	}
+	if _, _, err = uc.Issue(ctx, 1, true, IssueAPIKeyInput{UserID: 10}); err == nil {
+		t.Fatal("issuing a key without perms should fail")
+	}
+}
+
+func TestAPIKeyVerifyAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemAPIKeys()
+	perms := &fakePermStore{house: map[int32]map[string]struct{}{100: {"fund:deposit": {}}}}
+	uc := newAPIKeyUC(repo, perms)
+
+	k, key, err := uc.Issue(ctx, 1, false, IssueAPIKeyInput{UserID: 10, Perms: []string{"FUND:deposit"}, HouseGIDs: []int32{100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := uc.VerifyAPIKey(ctx, key, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if p.UserID != 10 || !reflect.DeepEqual(p.Perms, []string{"fund:deposit"}) || !reflect.DeepEqual(p.HouseGIDs, []int32{100}) {
+		t.Fatalf("principal = %+v", p)
+	}
+	if _, err = uc.VerifyAPIKey(ctx, key+"x", ""); err != ErrAPIKeyInvalid {
+		t.Fatalf("tampered key err = %v, want ErrAPIKeyInvalid", err)
+	}
+
+	if err = uc.Revoke(ctx, k.Id, 1, false); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err = uc.VerifyAPIKey(ctx, key, ""); err != ErrAPIKeyInactive {
+		t.Fatalf("revoked key err = %v, want ErrAPIKeyInactive", err)
+	}
+	if err = uc.Revoke(ctx, k.Id, 1, false); err == nil {
+		t.Fatal("revoking twice should fail")
+	}
+	if err = uc.Revoke(ctx, 999, 1, false); err == nil {
+		t.Fatal("revoking an unknown key should fail")
+	}
+}
+
+func TestAPIKeyListAndRevokeScopedToOperator(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemAPIKeys()
+	uc := newAPIKeyUC(repo, &fakePermStore{house: map[int32]map[string]struct{}{
+		100: {"fund:deposit": {}},
+		200: {"fund:deposit": {}},
+	}})
+	// 1: 超管签发不限店铺；2: 他人签发限定 100；3: 他人签发限定 100+300；4: 本人签发
+	for _, k := range []*basicModel.BasicAPIKey{
+		{UserID: 10, CreatedBy: 1},
+		{UserID: 10, CreatedBy: 2, HouseGIDs: []int32{100}},
+		{UserID: 10, CreatedBy: 2, HouseGIDs: []int32{100, 300}},
+		{UserID: 10, CreatedBy: 5, HouseGIDs: []int32{300}},
+	} {
+		_ = repo.Create(ctx, k)
+	}
+
+	list, err := uc.List(ctx, 5, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int32
+	for _, k := range list {
+		ids = append(ids, k.Id)
+	}
+	if !reflect.DeepEqual(ids, []int32{2, 4}) {
+		t.Fatalf("visible keys = %v, want [2 4]", ids)
+	}
+	if all, _ := uc.List(ctx, 5, true, 0); len(all) != 4 {
+		t.Fatalf("super admin sees %d keys, want 4", len(all))
+	}
+
+	for _, id := range []int32{1, 3} {
+		if err = uc.Revoke(ctx, id, 5, false); err == nil {
+			t.Fatalf("revoking key %d outside the operator's houses should fail", id)
+		}
+		if repo.keys[id].RevokedAt != nil {
+			t.Fatalf("key %d revoked", id)
+		}
+	}
+	if err = uc.Revoke(ctx, 2, 5, false); err != nil {
+		t.Fatalf("revoke key in own house: %v", err)
+	}
+	if err = uc.Revoke(ctx, 1, 5, true); err != nil {
+		t.Fatalf("super admin revoke: %v", err)
+	}
+}
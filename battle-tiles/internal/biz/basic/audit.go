@@ -0,0 +1,95 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"battle-tiles/pkg/plugin/auditx"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// AuditRetention 审计日志保留时长，超期的由 audit:purge 定时任务清理
+const AuditRetention = 180 * 24 * time.Hour
+
+// AuditUseCase 审计日志：作为 auditx.Sink 落库，并提供查询与过期清理
+type AuditUseCase struct {
+	repo basicRepo.AuditLogRepo
+	log  *log.Helper
+}
+
+func NewAuditUseCase(repo basicRepo.AuditLogRepo, logger log.Logger) *AuditUseCase {
+	return &AuditUseCase{
+		repo: repo,
+		log:  log.NewHelper(log.With(logger, "module", "usecase/audit")),
+	}
+}
+
+// WriteAudit 实现 auditx.Sink
+func (uc *AuditUseCase) WriteAudit(ctx context.Context, e *auditx.Entry) error {
+	m := &basicModel.BasicAuditLog{
+		CreatedAt:  e.CreatedAt,
+		Platform:   truncate(e.Platform, 64),
+		ActorID:    e.ActorID,
+		ActorName:  truncate(e.ActorName, 64),
+		HouseGID:   e.HouseGID,
+		Action:     truncate(e.Action, 100),
+		Method:     e.Method,
+		Path:       truncate(e.Path, 255),
+		TargetType: truncate(e.TargetType, 64),
+		TargetIDs:  truncate(strings.Join(e.TargetIDs, ","), 512),
+		BeforeJSON: auditJSON(e.Before),
+		AfterJSON:  auditJSON(e.After),
+		Reason:     truncate(e.Reason, 255),
+		ClientIP:   truncate(e.ClientIP, 64),
+		UserAgent:  truncate(e.UserAgent, 255),
+		Success:    e.Success,
+		ResultCode: int32(e.ResultCode),
+		ResultMsg:  truncate(e.ResultMsg, 255),
+		LatencyMs:  e.LatencyMs,
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	return uc.repo.Create(ctx, m)
+}
+
+// List 查询审计日志
+func (uc *AuditUseCase) List(ctx context.Context, f basicRepo.AuditLogFilter, page, size int32) ([]*basicModel.BasicAuditLog, int64, error) {
+	return uc.repo.List(ctx, f, page, size)
+}
+
+// Purge 清理超过保留期的审计日志
+func (uc *AuditUseCase) Purge(ctx context.Context) error {
+	n, err := uc.repo.PurgeBefore(ctx, time.Now().Add(-AuditRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		uc.log.Infof("purged %d audit logs", n)
+	}
+	return nil
+}
+
+func auditJSON(v any) *string {
+	if v == nil {
+		return nil
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	s := string(bs)
+	return &s
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
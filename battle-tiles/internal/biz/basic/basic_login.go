@@ -14,6 +14,7 @@ import (
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/request"
 	"context"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -26,25 +27,38 @@ type BasicLoginUseCase struct {
 	repo          basicRepo.BasicLoginRepo
 	authRepo      basicRepo.AuthRepo
 	gameAccountUC *game.GameAccountUseCase // 游戏账号用例（可选，用于注册时绑定游戏账号）
+	sessions      *LoginSessionUseCase
+	security      *LoginSecurityUseCase
 	global        *conf.Global
 	log           *log.Helper
 }
 
-func NewBasicLoginUseCase(repo basicRepo.BasicLoginRepo, global *conf.Global, authRepo basicRepo.AuthRepo, gameAccountUC *game.GameAccountUseCase, logger log.Logger) *BasicLoginUseCase {
+func NewBasicLoginUseCase(repo basicRepo.BasicLoginRepo, global *conf.Global, authRepo basicRepo.AuthRepo, gameAccountUC *game.GameAccountUseCase, sessions *LoginSessionUseCase, security *LoginSecurityUseCase, logger log.Logger) *BasicLoginUseCase {
 	return &BasicLoginUseCase{
 		repo:          repo,
 		authRepo:      authRepo,
 		gameAccountUC: gameAccountUC,
+		sessions:      sessions,
+		security:      security,
 		global:        global,
 		log:           log.NewHelper(log.With(logger, "module", "usecase/basic_login")),
 	}
 }
 
-// 用户名 + 密码登录
+// 用户名 + 密码登录（失败计数锁定 + 可选 TOTP 二次验证）
 func (uc *BasicLoginUseCase) LoginByUsernamePassword(ctx context.Context, c *gin.Context, req *req.UsernamePasswordLoginRequest) (*resp.LoginResponse, error) {
+	attempt := LoginAttempt{Username: req.Username, ClientIP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if err := uc.security.CheckLocked(ctx, attempt); err != nil {
+		return nil, err
+	}
+
 	user, err := uc.repo.FindByUsername(ctx, req.Username)
-	if err != nil || user == nil {
-		return nil, errors.New("用户不存在")
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		uc.security.Fail(ctx, attempt, 0, LoginFailNoUser)
+		return nil, errors.New("用户名或密码错误")
 	}
 	if user.Password == "" || user.Salt == "" {
 		return nil, errors.New("该用户未设置密码")
@@ -55,11 +69,47 @@ func (uc *BasicLoginUseCase) LoginByUsernamePassword(ctx context.Context, c *gin
 		return nil, err
 	}
 	if !checkPassword(passwordPlain, user.Salt, user.Password) {
-		return nil, errors.New("密码错误")
+		uc.security.Fail(ctx, attempt, user.Id, LoginFailPassword)
+		return nil, errors.New("用户名或密码错误")
 	}
 
+	// 密码正确后才提示需要动态码，避免泄露账号是否开启二次验证
+	usedTOTP, err := uc.security.TOTPEnabled(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err = uc.security.VerifyLoginTOTP(ctx, user.Id, req.OTPCode); err != nil {
+		if errors.Is(err, ErrTOTPInvalid) {
+			uc.security.Fail(ctx, attempt, user.Id, LoginFailTOTP)
+		}
+		return nil, err
+	}
+
+	uc.security.Succeed(ctx, attempt, user.Id, usedTOTP)
 	_ = uc.repo.UpdateLastLoginAt(ctx, user.Id)
-	return uc.buildLoginResponse(c, user) // 改为调用方法，便于取权限
+	res, err := uc.buildLoginResponse(c, user) // 改为调用方法，便于取权限
+	if err != nil {
+		return nil, err
+	}
+	// 管理员或持有资金权限的账号未开启二次验证时，提示前端引导绑定
+	res.TOTPSuggested = !usedTOTP && needsTOTP(user, res.Roles, res.Perms)
+	return res, nil
+}
+
+// needsTOTP 超管/店铺管理员或持有资金、结算类权限的账号
+func needsTOTP(user *basicModel.BasicUser, roles []int32, perms []string) bool {
+	if user.Role == basicModel.UserRoleSuperAdmin || user.Role == basicModel.UserRoleStoreAdmin {
+		return true
+	}
+	if (request.BaseClaims{Roles: roles}).IsSuperAdmin() {
+		return true
+	}
+	for _, p := range perms {
+		if strings.HasPrefix(p, "fund:") || strings.HasPrefix(p, "settlement:") || strings.HasPrefix(p, "shop:admin:") {
+			return true
+		}
+	}
+	return false
 }
 
 // 用户注册（用户名 + 密码 + 可选微信号 + 可选游戏账号）
@@ -132,7 +182,8 @@ func (uc *BasicLoginUseCase) Register(ctx context.Context, c *gin.Context, req *
 		}
 
 		// 绑定游戏账号
-		if _, err := uc.gameAccountUC.BindSingle(ctx, user.Id, mode, req.GameAccount, req.GamePassword, nickName); err != nil {
+		if _, err := uc.gameAccountUC.BindSingle(ctx, user.Id, mode, req.GameAccount, req.GamePassword, nickName,
+			game.BlocklistSubject{Device: c.GetHeader("X-Device-Id"), IP: c.ClientIP()}); err != nil {
 			uc.log.Errorf("bind game account failed: %v", err)
 			// 不阻断注册流程，只记录错误
 		} else {
@@ -144,18 +195,55 @@ skipGameAccountBind:
 	return uc.buildLoginResponse(c, user) // 新用户注册后同样返回携带权限的 token
 }
 
+// RefreshToken 用刷新令牌换取新的 access token（同时轮换刷新令牌，角色/权限按当前库内数据重新签发）
+func (uc *BasicLoginUseCase) RefreshToken(ctx context.Context, c *gin.Context, refreshToken string) (*resp.LoginResponse, error) {
+	sess, refresh, err := uc.sessions.Rotate(ctx, refreshToken, sessionClient(c))
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.repo.FindByID(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		_ = uc.sessions.Revoke(ctx, sess.UserID, sess.SID)
+		return nil, errors.New("用户不存在")
+	}
+	return uc.issueTokens(c, user, sess.SID, refresh)
+}
+
 // ===== 内部工具 =====
 
-// 把平台、角色、权限写入 JWT，并返回登录响应
-func (uc *BasicLoginUseCase) buildLoginResponse(c *gin.Context, user *basicModel.BasicUser) (*resp.LoginResponse, error) {
-	j := utils.NewJWT()
+func sessionClient(c *gin.Context) SessionClient {
+	return SessionClient{
+		Device:    c.GetHeader("X-Device-Name"),
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
 
-	platform := ""
+func platformFromCtx(c *gin.Context) string {
 	if v := c.Request.Context().Value(pdb.CtxDBKey); v != nil {
 		if s, ok := v.(string); ok {
-			platform = s
+			return s
 		}
 	}
+	return ""
+}
+
+// 登录/注册成功：新建会话并签发令牌
+func (uc *BasicLoginUseCase) buildLoginResponse(c *gin.Context, user *basicModel.BasicUser) (*resp.LoginResponse, error) {
+	sess, refresh, err := uc.sessions.Open(c.Request.Context(), user.Id, platformFromCtx(c), sessionClient(c))
+	if err != nil {
+		return nil, err
+	}
+	return uc.issueTokens(c, user, sess.SID, refresh)
+}
+
+// 把平台、角色、权限、会话写入 JWT，并返回登录响应
+func (uc *BasicLoginUseCase) issueTokens(c *gin.Context, user *basicModel.BasicUser, sid, refreshToken string) (*resp.LoginResponse, error) {
+	j := utils.NewJWT()
+	platform := platformFromCtx(c)
 
 	// 查询角色 & 权限（出错不阻断登录，仅记录日志）
 	var roles []int32
@@ -171,15 +259,16 @@ func (uc *BasicLoginUseCase) buildLoginResponse(c *gin.Context, user *basicModel
 	}
 
 	claims := request.BaseClaims{
-		UserID:   user.Id,
-		Platform: platform,
-		Username: user.Username,
-		NickName: user.NickName,
-		Roles:    roles,
-		Perms:    perms,
+		UserID:    user.Id,
+		Platform:  platform,
+		Username:  user.Username,
+		NickName:  user.NickName,
+		Roles:     roles,
+		Perms:     perms,
+		SessionID: sid,
 	}
 	tokenClaims := j.CreateClaims(claims)
-	accessToken, refreshToken, err := j.CreateToken2(tokenClaims)
+	accessToken, err := j.CreateToken(tokenClaims)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +288,7 @@ func (uc *BasicLoginUseCase) buildLoginResponse(c *gin.Context, user *basicModel
 		Role:         user.Role, // 添加用户角色
 		Roles:        roles,
 		Perms:        perms,
+		SessionID:    sid,
 	}, nil
 }
 
@@ -0,0 +1,270 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"battle-tiles/pkg/utils"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+)
+
+// 暴力破解防护：按用户名、按 IP 分别计数，超过阈值后锁定，锁定时长随失败次数翻倍
+const (
+	loginUserFailThreshold = 5
+	loginIPFailThreshold   = 20
+	loginFailWindow        = 30 * time.Minute
+	loginLockBase          = time.Minute
+	loginLockMax           = time.Hour
+
+	totpIssuer = "BattleTiles"
+	totpSkew   = 1
+)
+
+// 登录失败原因（写入登录记录）
+const (
+	LoginFailNoUser   = "no_user"
+	LoginFailPassword = "bad_password"
+	LoginFailTOTP     = "bad_totp"
+	LoginFailLocked   = "locked"
+)
+
+var (
+	ErrTOTPRequired = errors.New("需要输入动态验证码")
+	ErrTOTPInvalid  = errors.New("动态验证码错误")
+)
+
+// LoginLockedError 账号或 IP 处于锁定期
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	mins := int(time.Until(e.Until).Minutes()) + 1
+	return fmt.Sprintf("尝试次数过多，请 %d 分钟后再试", mins)
+}
+
+// LoginAttempt 一次登录尝试的上下文
+type LoginAttempt struct {
+	Username  string
+	ClientIP  string
+	UserAgent string
+}
+
+type LoginSecurityUseCase struct {
+	repo basicRepo.LoginSecurityRepo
+	log  *log.Helper
+}
+
+func NewLoginSecurityUseCase(repo basicRepo.LoginSecurityRepo, logger log.Logger) *LoginSecurityUseCase {
+	return &LoginSecurityUseCase{
+		repo: repo,
+		log:  log.NewHelper(log.With(logger, "module", "usecase/login_security")),
+	}
+}
+
+func userGuardKey(username string) string { return "user:" + strings.ToLower(strings.TrimSpace(username)) }
+func ipGuardKey(ip string) string         { return "ip:" + ip }
+
+// CheckLocked 登录前检查用户名 / IP 是否处于锁定期
+func (uc *LoginSecurityUseCase) CheckLocked(ctx context.Context, a LoginAttempt) error {
+	for _, key := range []string{userGuardKey(a.Username), ipGuardKey(a.ClientIP)} {
+		until, err := uc.repo.LockedUntil(ctx, key)
+		if err != nil {
+			// Redis 异常时不阻断登录，仅记录
+			uc.log.Warnf("check login lock %s failed: %v", key, err)
+			continue
+		}
+		if until.After(time.Now()) {
+			uc.record(ctx, a, 0, false, LoginFailLocked, false)
+			return &LoginLockedError{Until: until}
+		}
+	}
+	return nil
+}
+
+// Fail 记录一次失败并按阈值锁定
+func (uc *LoginSecurityUseCase) Fail(ctx context.Context, a LoginAttempt, userID int32, reason string) {
+	uc.record(ctx, a, userID, false, reason, reason == LoginFailTOTP)
+	uc.bump(ctx, userGuardKey(a.Username), loginUserFailThreshold)
+	uc.bump(ctx, ipGuardKey(a.ClientIP), loginIPFailThreshold)
+}
+
+func (uc *LoginSecurityUseCase) bump(ctx context.Context, key string, threshold int64) {
+	n, err := uc.repo.IncrFail(ctx, key, loginFailWindow)
+	if err != nil {
+		uc.log.Warnf("incr login fail %s failed: %v", key, err)
+		return
+	}
+	if n < threshold {
+		return
+	}
+	d := lockDuration(n - threshold)
+	if err = uc.repo.Lock(ctx, key, time.Now().Add(d)); err != nil {
+		uc.log.Warnf("lock %s failed: %v", key, err)
+		return
+	}
+	uc.log.Warnf("login locked: key=%s fails=%d for=%s", key, n, d)
+}
+
+// lockDuration 超过阈值后第 over 次失败的锁定时长：1m, 2m, 4m ... 封顶 1h
+func lockDuration(over int64) time.Duration {
+	d := loginLockBase
+	for i := int64(0); i < over && d < loginLockMax; i++ {
+		d *= 2
+	}
+	if d > loginLockMax {
+		d = loginLockMax
+	}
+	return d
+}
+
+// Succeed 登录成功：清除该用户名的失败计数（IP 计数保留，避免同一 IP 轮换账号爆破）
+func (uc *LoginSecurityUseCase) Succeed(ctx context.Context, a LoginAttempt, userID int32, usedTOTP bool) {
+	uc.record(ctx, a, userID, true, "", usedTOTP)
+	if err := uc.repo.ResetFail(ctx, userGuardKey(a.Username)); err != nil {
+		uc.log.Warnf("reset login fail for %s failed: %v", a.Username, err)
+	}
+}
+
+// Unlock 管理员解除用户名锁定
+func (uc *LoginSecurityUseCase) Unlock(ctx context.Context, username string) error {
+	return uc.repo.ResetFail(ctx, userGuardKey(username))
+}
+
+func (uc *LoginSecurityUseCase) record(ctx context.Context, a LoginAttempt, userID int32, success bool, reason string, usedTOTP bool) {
+	err := uc.repo.CreateHistory(ctx, &basicModel.BasicLoginHistory{
+		CreatedAt:  time.Now(),
+		UserID:     userID,
+		Username:   truncate(a.Username, 50),
+		ClientIP:   a.ClientIP,
+		UserAgent:  truncate(a.UserAgent, 255),
+		Success:    success,
+		FailReason: reason,
+		UsedTOTP:   usedTOTP,
+	})
+	if err != nil {
+		uc.log.Errorf("write login history failed: %v", err)
+	}
+}
+
+// ListHistory 登录记录
+func (uc *LoginSecurityUseCase) ListHistory(ctx context.Context, f basicRepo.LoginHistoryFilter, page, size int32) ([]*basicModel.BasicLoginHistory, int64, error) {
+	return uc.repo.ListHistory(ctx, f, page, size)
+}
+
+// ===== TOTP =====
+
+// TOTPEnabled 用户是否已启用二次验证
+func (uc *LoginSecurityUseCase) TOTPEnabled(ctx context.Context, userID int32) (bool, error) {
+	m, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return m != nil && m.Enabled, nil
+}
+
+// VerifyLoginTOTP 登录时校验动态码（同一时间步只能用一次）
+func (uc *LoginSecurityUseCase) VerifyLoginTOTP(ctx context.Context, userID int32, code string) error {
+	m, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || !m.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return ErrTOTPRequired
+	}
+	_, err = uc.verify(ctx, m, code)
+	return err
+}
+
+// verify 校验动态码并推进已用时间步，返回命中的步数
+func (uc *LoginSecurityUseCase) verify(ctx context.Context, m *basicModel.BasicUserTOTP, code string) (int64, error) {
+	step, ok := utils.VerifyTOTP(m.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return 0, ErrTOTPInvalid
+	}
+	fresh, err := uc.repo.AdvanceTOTPStep(ctx, m.UserID, step)
+	if err != nil {
+		return 0, err
+	}
+	if !fresh {
+		return 0, ErrTOTPInvalid
+	}
+	return step, nil
+}
+
+// TOTPSetup 生成（或重新生成）待启用的密钥；已启用时需先停用
+func (uc *LoginSecurityUseCase) TOTPSetup(ctx context.Context, userID int32, username string) (secret, otpURL string, err error) {
+	m, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if m != nil && m.Enabled {
+		return "", "", errors.New("已启用二次验证，如需更换请先停用")
+	}
+	secret, err = utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	if err = uc.repo.SaveTOTP(ctx, &basicModel.BasicUserTOTP{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		return "", "", err
+	}
+	return secret, utils.TOTPURL(totpIssuer, username, secret), nil
+}
+
+// TOTPEnable 用验证器上的动态码确认启用
+func (uc *LoginSecurityUseCase) TOTPEnable(ctx context.Context, userID int32, code string) error {
+	m, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return errors.New("请先生成密钥")
+	}
+	if m.Enabled {
+		return nil
+	}
+	step, err := uc.verify(ctx, m, code)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	m.Enabled = true
+	m.EnabledAt = &now
+	m.UpdatedAt = now
+	m.LastStep = step
+	return uc.repo.SaveTOTP(ctx, m)
+}
+
+// TOTPDisable 本人停用，需要当前动态码
+func (uc *LoginSecurityUseCase) TOTPDisable(ctx context.Context, userID int32, code string) error {
+	m, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || !m.Enabled {
+		return errors.New("未启用二次验证")
+	}
+	if _, err = uc.verify(ctx, m, code); err != nil {
+		return err
+	}
+	return uc.repo.DeleteTOTP(ctx, userID)
+}
+
+// TOTPReset 管理员为丢失验证器的用户重置（不需要动态码）
+func (uc *LoginSecurityUseCase) TOTPReset(ctx context.Context, userID int32) error {
+	return uc.repo.DeleteTOTP(ctx, userID)
+}
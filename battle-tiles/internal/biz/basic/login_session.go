@@ -0,0 +1,247 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	rbacstore "battle-tiles/internal/dal/repo/rbac"
+	"battle-tiles/pkg/utils"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrSessionRevoked = errors.New("会话已失效，请重新登录")
+	ErrTokenStale     = errors.New("权限已变更，请刷新令牌")
+	ErrRefreshReused  = errors.New("刷新令牌已被使用，会话已吊销")
+)
+
+// LoginSessionUseCase 登录会话：短期 access token + 轮换刷新令牌，支持单设备/全部登出，
+// 角色或权限变更后令相关用户的 access token 立即失效（需用刷新令牌换新，拿到最新角色）。
+type LoginSessionUseCase struct {
+	repo     basicRepo.LoginSessionRepo
+	authRepo basicRepo.AuthRepo
+	rbac     *rbacstore.Store
+	log      *log.Helper
+}
+
+func NewLoginSessionUseCase(repo basicRepo.LoginSessionRepo, authRepo basicRepo.AuthRepo, rbac *rbacstore.Store, logger log.Logger) *LoginSessionUseCase {
+	return &LoginSessionUseCase{
+		repo:     repo,
+		authRepo: authRepo,
+		rbac:     rbac,
+		log:      log.NewHelper(log.With(logger, "module", "usecase/login_session")),
+	}
+}
+
+// SessionClient 登录端信息
+type SessionClient struct {
+	Device    string
+	ClientIP  string
+	UserAgent string
+}
+
+func refreshTTL() time.Duration {
+	d, _ := utils.ParseDuration(utils.HeaderSignTokenExpires)
+	return d
+}
+
+func accessTTL() time.Duration {
+	d, _ := utils.ParseDuration(utils.AccessTokenExpires)
+	return d
+}
+
+// Open 新建会话，返回会话与刷新令牌（明文只在此返回一次）
+func (uc *LoginSessionUseCase) Open(ctx context.Context, userID int32, platform string, client SessionClient) (*basicModel.LoginSession, string, error) {
+	sid, err := randomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	refresh, hash, err := newRefreshToken(sid)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	device := client.Device
+	if device == "" {
+		device = client.UserAgent
+	}
+	s := &basicModel.LoginSession{
+		SID:          sid,
+		UserID:       userID,
+		Platform:     platform,
+		Device:       truncate(device, 128),
+		ClientIP:     client.ClientIP,
+		UserAgent:    truncate(client.UserAgent, 255),
+		RefreshHash:  hash,
+		CreatedAt:    now.Unix(),
+		LastActiveAt: now.Unix(),
+		ExpiresAt:    now.Add(refreshTTL()).Unix(),
+	}
+	if err = uc.repo.Save(ctx, s); err != nil {
+		return nil, "", errors.Wrap(err, "save session")
+	}
+	return s, refresh, nil
+}
+
+// Rotate 用刷新令牌换新：旧令牌作废；同一令牌第二次出现视为被盗用，直接吊销该会话
+func (uc *LoginSessionUseCase) Rotate(ctx context.Context, refreshToken string, client SessionClient) (*basicModel.LoginSession, string, error) {
+	sid, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sid == "" {
+		return nil, "", ErrSessionRevoked
+	}
+	s, err := uc.repo.Get(ctx, sid)
+	if err != nil {
+		return nil, "", err
+	}
+	if s == nil {
+		return nil, "", ErrSessionRevoked
+	}
+	hash := hashToken(refreshToken)
+	if s.PrevHash != "" && hash == s.PrevHash {
+		uc.revokeReused(ctx, s)
+		return nil, "", ErrRefreshReused
+	}
+	if hash != s.RefreshHash {
+		return nil, "", ErrSessionRevoked
+	}
+	// 并发刷新时只有一个请求能消费成功
+	fresh, err := uc.repo.ConsumeRefresh(ctx, hash, refreshTTL())
+	if err != nil {
+		return nil, "", err
+	}
+	if !fresh {
+		uc.revokeReused(ctx, s)
+		return nil, "", ErrRefreshReused
+	}
+
+	refresh, newHash, err := newRefreshToken(sid)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	s.PrevHash = s.RefreshHash
+	s.RefreshHash = newHash
+	s.LastActiveAt = now.Unix()
+	s.ExpiresAt = now.Add(refreshTTL()).Unix()
+	if client.ClientIP != "" {
+		s.ClientIP = client.ClientIP
+	}
+	if err = uc.repo.Save(ctx, s); err != nil {
+		return nil, "", errors.Wrap(err, "save session")
+	}
+	return s, refresh, nil
+}
+
+func (uc *LoginSessionUseCase) revokeReused(ctx context.Context, s *basicModel.LoginSession) {
+	uc.log.Warnf("refresh token reuse detected: user=%d sid=%s", s.UserID, s.SID)
+	if err := uc.repo.Delete(ctx, s.UserID, s.SID); err != nil {
+		uc.log.Errorf("revoke session %s failed: %v", s.SID, err)
+	}
+}
+
+// List 用户的在线会话，最近活跃在前
+func (uc *LoginSessionUseCase) List(ctx context.Context, userID int32) ([]*basicModel.LoginSession, error) {
+	list, err := uc.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastActiveAt > list[j].LastActiveAt })
+	return list, nil
+}
+
+// Revoke 吊销用户的某个会话（只能操作自己的会话）
+func (uc *LoginSessionUseCase) Revoke(ctx context.Context, userID int32, sid string) error {
+	s, err := uc.repo.Get(ctx, sid)
+	if err != nil {
+		return err
+	}
+	if s == nil || s.UserID != userID {
+		return errors.New("会话不存在")
+	}
+	return uc.repo.Delete(ctx, userID, sid)
+}
+
+// RevokeAll 全部设备登出
+func (uc *LoginSessionUseCase) RevokeAll(ctx context.Context, userID int32) (int, error) {
+	return uc.repo.DeleteByUser(ctx, userID)
+}
+
+// InvalidateUsers 权限变更：清权限缓存，并让此前签发的 access token 失效（会话保留，刷新即可拿到新角色）
+func (uc *LoginSessionUseCase) InvalidateUsers(ctx context.Context, userIDs ...int32) {
+	now := time.Now()
+	for _, uid := range userIDs {
+		if err := uc.repo.MarkStale(ctx, uid, now, accessTTL()+time.Minute); err != nil {
+			uc.log.Errorf("mark sessions stale for user %d failed: %v", uid, err)
+		}
+		if uc.rbac != nil {
+			uc.rbac.InvalidateUser(ctx, uid)
+		}
+	}
+}
+
+// InvalidateRole 角色被修改/删除/调整权限后，作用到持有该角色的所有用户
+func (uc *LoginSessionUseCase) InvalidateRole(ctx context.Context, roleID int32) error {
+	ids, err := uc.authRepo.ListUserIDsByRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	uc.InvalidateUsers(ctx, ids...)
+	return nil
+}
+
+// InvalidatePermission 权限被删除后，作用到通过任一角色持有它的用户
+func (uc *LoginSessionUseCase) InvalidatePermission(ctx context.Context, permissionID int32) error {
+	ids, err := uc.authRepo.ListUserIDsByPermission(ctx, permissionID)
+	if err != nil {
+		return err
+	}
+	uc.InvalidateUsers(ctx, ids...)
+	return nil
+}
+
+// CheckSession 实现 middleware.SessionChecker
+func (uc *LoginSessionUseCase) CheckSession(ctx context.Context, userID int32, sid string, issuedAt time.Time) error {
+	exists, staleAt, err := uc.repo.Check(ctx, userID, sid)
+	if err != nil {
+		return errors.Wrap(err, "check session")
+	}
+	if !exists {
+		return ErrSessionRevoked
+	}
+	if staleAt > 0 && issuedAt.Unix() < staleAt {
+		return ErrTokenStale
+	}
+	return nil
+}
+
+// 刷新令牌格式：<sid>.<随机串>，服务端只存 sha256
+func newRefreshToken(sid string) (token, hash string, err error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	token = sid + "." + secret
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,145 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memSessions 内存版会话存储
+type memSessions struct {
+	sessions map[string]basicModel.LoginSession
+	consumed map[string]bool
+	stale    map[int32]int64
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{sessions: map[string]basicModel.LoginSession{}, consumed: map[string]bool{}, stale: map[int32]int64{}}
+}
+
+func (r *memSessions) Save(_ context.Context, s *basicModel.LoginSession) error {
+	r.sessions[s.SID] = *s
+	return nil
+}
+
+func (r *memSessions) Get(_ context.Context, sid string) (*basicModel.LoginSession, error) {
+	s, ok := r.sessions[sid]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (r *memSessions) ListByUser(_ context.Context, userID int32) ([]*basicModel.LoginSession, error) {
+	var out []*basicModel.LoginSession
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			s := s
+			out = append(out, &s)
+		}
+	}
+	return out, nil
+}
+
+func (r *memSessions) Delete(_ context.Context, _ int32, sid string) error {
+	delete(r.sessions, sid)
+	return nil
+}
+
+func (r *memSessions) DeleteByUser(_ context.Context, userID int32) (int, error) {
+	n := 0
+	for sid, s := range r.sessions {
+		if s.UserID == userID {
+			delete(r.sessions, sid)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memSessions) ConsumeRefresh(_ context.Context, hash string, _ time.Duration) (bool, error) {
+	if r.consumed[hash] {
+		return false, nil
+	}
+	r.consumed[hash] = true
+	return true, nil
+}
+
+func (r *memSessions) MarkStale(_ context.Context, userID int32, at time.Time, _ time.Duration) error {
+	r.stale[userID] = at.Unix()
+	return nil
+}
+
+func (r *memSessions) Check(_ context.Context, userID int32, sid string) (bool, int64, error) {
+	s, ok := r.sessions[sid]
+	return ok && s.UserID == userID, r.stale[userID], nil
+}
+
+func TestLoginSessionRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemSessions()
+	uc := NewLoginSessionUseCase(repo, nil, nil, log.DefaultLogger)
+
+	s, first, err := uc.Open(ctx, 7, "p1", SessionClient{UserAgent: "ua", ClientIP: "1.1.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Device != "ua" || repo.sessions[s.SID].RefreshHash == first {
+		t.Fatalf("session = %+v; refresh token must be stored hashed", s)
+	}
+
+	_, second, err := uc.Rotate(ctx, first, SessionClient{ClientIP: "2.2.2.2"})
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if second == first || repo.sessions[s.SID].ClientIP != "2.2.2.2" {
+		t.Fatalf("rotation did not issue a new token / update client")
+	}
+	if err := uc.CheckSession(ctx, 7, s.SID, time.Now()); err != nil {
+		t.Fatalf("check after rotate: %v", err)
+	}
+
+	// 旧令牌再次出现视为泄露：吊销整个会话，新令牌随之失效
+	if _, _, err := uc.Rotate(ctx, first, SessionClient{}); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("reuse err = %v", err)
+	}
+	if _, _, err := uc.Rotate(ctx, second, SessionClient{}); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("rotate after revoke err = %v", err)
+	}
+	if err := uc.CheckSession(ctx, 7, s.SID, time.Now()); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("check after revoke err = %v", err)
+	}
+}
+
+func TestLoginSessionStaleAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemSessions()
+	uc := NewLoginSessionUseCase(repo, nil, nil, log.DefaultLogger)
+
+	s, _, err := uc.Open(ctx, 7, "p1", SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := time.Now().Add(-time.Minute)
+	uc.InvalidateUsers(ctx, 7)
+	if err := uc.CheckSession(ctx, 7, s.SID, issued); !errors.Is(err, ErrTokenStale) {
+		t.Fatalf("token issued before role change: err = %v", err)
+	}
+	if err := uc.CheckSession(ctx, 7, s.SID, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("token issued after role change: %v", err)
+	}
+
+	if err := uc.Revoke(ctx, 8, s.SID); err == nil {
+		t.Fatal("revoking another user's session should fail")
+	}
+	if _, _, err := uc.Open(ctx, 7, "p1", SessionClient{}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := uc.RevokeAll(ctx, 7); err != nil || n != 2 {
+		t.Fatalf("revoke all = %d, %v", n, err)
+	}
+}
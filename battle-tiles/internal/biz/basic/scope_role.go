@@ -0,0 +1,141 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	rbacstore "battle-tiles/internal/dal/repo/rbac"
+	"battle-tiles/pkg/plugin/auditx"
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+)
+
+// 非超级管理员可授予的作用域角色
+var delegableScopeRoles = map[string]struct{}{
+	basicModel.RoleCodeShopAdmin:    {},
+	basicModel.RoleCodeShopOperator: {},
+}
+
+// ScopeRoleGrant 作用域角色授权参数（RoleID 与 RoleCode 二选一）
+type ScopeRoleGrant struct {
+	UserID   int32
+	RoleID   int32
+	RoleCode string
+	HouseGID int32
+	GroupID  int32
+}
+
+// UserScopeRoleUseCase 店铺/圈作用域角色授权
+type UserScopeRoleUseCase struct {
+	repo  basicRepo.UserScopeRoleRepo
+	store *rbacstore.Store
+	log   *log.Helper
+}
+
+func NewUserScopeRoleUseCase(repo basicRepo.UserScopeRoleRepo, store *rbacstore.Store, logger log.Logger) *UserScopeRoleUseCase {
+	return &UserScopeRoleUseCase{
+		repo:  repo,
+		store: store,
+		log:   log.NewHelper(log.With(logger, "module", "usecase/user_scope_role")),
+	}
+}
+
+// Grant 授予作用域角色；superAdmin=false 时只能授予店铺管理员/运营角色
+func (uc *UserScopeRoleUseCase) Grant(ctx context.Context, opUser int32, superAdmin bool, in ScopeRoleGrant) error {
+	roleID, err := uc.resolveRole(ctx, superAdmin, in)
+	if err != nil {
+		return err
+	}
+	m := &basicModel.BasicUserScopeRole{
+		UserID:    in.UserID,
+		RoleID:    roleID,
+		HouseGID:  in.HouseGID,
+		GroupID:   in.GroupID,
+		GrantedBy: opUser,
+	}
+	before := uc.houseRoles(ctx, in.UserID, in.HouseGID)
+	if err := uc.repo.Grant(ctx, m); err != nil {
+		return err
+	}
+	uc.auditRoles(ctx, in, before)
+	uc.store.InvalidateUser(ctx, in.UserID)
+	return nil
+}
+
+// Revoke 撤销作用域角色
+func (uc *UserScopeRoleUseCase) Revoke(ctx context.Context, superAdmin bool, in ScopeRoleGrant) error {
+	roleID, err := uc.resolveRole(ctx, superAdmin, in)
+	if err != nil {
+		return err
+	}
+	before := uc.houseRoles(ctx, in.UserID, in.HouseGID)
+	if err := uc.repo.Revoke(ctx, in.UserID, roleID, in.HouseGID, in.GroupID); err != nil {
+		return err
+	}
+	uc.auditRoles(ctx, in, before)
+	uc.store.InvalidateUser(ctx, in.UserID)
+	return nil
+}
+
+// houseRoles 用户在店铺下的作用域角色（审计用，无审计上下文或查询失败时返回 nil）
+func (uc *UserScopeRoleUseCase) houseRoles(ctx context.Context, userID, houseGID int32) []*basicRepo.UserScopeRoleView {
+	if auditx.FromContext(ctx) == nil {
+		return nil
+	}
+	all, err := uc.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	out := make([]*basicRepo.UserScopeRoleView, 0, len(all))
+	for _, v := range all {
+		if v.HouseGID == houseGID {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (uc *UserScopeRoleUseCase) auditRoles(ctx context.Context, in ScopeRoleGrant, before []*basicRepo.UserScopeRoleView) {
+	auditx.SetHouse(ctx, in.HouseGID)
+	auditx.SetTarget(ctx, "user", in.UserID)
+	auditx.SetBefore(ctx, map[string]any{"roles": before})
+	auditx.SetAfter(ctx, map[string]any{"roles": uc.houseRoles(ctx, in.UserID, in.HouseGID)})
+}
+
+// ListByHouse 店铺下的作用域授权
+func (uc *UserScopeRoleUseCase) ListByHouse(ctx context.Context, houseGID int32) ([]*basicRepo.UserScopeRoleView, error) {
+	return uc.repo.ListByHouse(ctx, houseGID)
+}
+
+// ListByUser 用户的作用域授权
+func (uc *UserScopeRoleUseCase) ListByUser(ctx context.Context, userID int32) ([]*basicRepo.UserScopeRoleView, error) {
+	return uc.repo.ListByUser(ctx, userID)
+}
+
+func (uc *UserScopeRoleUseCase) resolveRole(ctx context.Context, superAdmin bool, in ScopeRoleGrant) (int32, error) {
+	if in.UserID <= 0 || in.HouseGID <= 0 || in.GroupID < 0 {
+		return 0, errors.New("invalid user_id, house_gid or group_id")
+	}
+	code := in.RoleCode
+	if code == "" {
+		if !superAdmin {
+			return 0, errors.New("role_code is required")
+		}
+		if in.RoleID <= 0 {
+			return 0, errors.New("role_id or role_code is required")
+		}
+		return in.RoleID, nil
+	}
+	if _, ok := delegableScopeRoles[code]; !ok && !superAdmin {
+		return 0, errors.Errorf("role %s cannot be granted by house admins", code)
+	}
+	id, err := uc.repo.RoleIDByCode(ctx, code)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, errors.Errorf("role %s not found", code)
+	}
+	return id, nil
+}
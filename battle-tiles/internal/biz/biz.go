@@ -14,7 +14,12 @@ var ProviderSet = wire.NewSet(
 	NewAsyNQUseCase,
 	basic.NewBasicUserUseCase,
 	basic.NewBasicLoginUseCase,
+	basic.NewLoginSessionUseCase,
+	basic.NewLoginSecurityUseCase,
+	basic.NewAPIKeyUseCase,
 	basic.NewBasicMenuUseCase,
+	basic.NewUserScopeRoleUseCase,
+	basic.NewAuditUseCase,
 
 	cloud.NewPlatformUsecase,
 
@@ -32,4 +37,16 @@ var ProviderSet = wire.NewSet(
 	game.NewMemberUseCase,
 	game.NewBattleQueryUseCase,
 	game.NewBalanceQueryUseCase,
+	game.NewGroupSettlementUseCase,
+	game.NewLeaderboardUseCase,
+	game.NewPlayerProfileUseCase,
+	game.NewReportUseCase,
+	game.NewWebhookUseCase,
+	game.NewApplicationUseCase,
+	game.NewOnboardingUseCase,
+	game.NewBlocklistUseCase,
+	game.NewBattleConfigUseCase,
+	game.NewDiamondUseCase,
+	game.NewMemberSyncUseCase,
+	game.NewGroResolver,
 )
@@ -0,0 +1,700 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"battle-tiles/pkg/plugin/auditx"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ApplicationExpireTask 平台侧待审申请超时清理（asynq 定时任务）
+const ApplicationExpireTask = "application:expire"
+
+// 游戏内申请超时检查间隔（由会话巡检驱动，按店铺节流）
+const gameApplicationSweepInterval = time.Minute
+
+// 申请类型
+const (
+	applyTypeAdmin int32 = 1 // 申请成为店铺管理员
+	applyTypeJoin  int32 = 2 // 申请入圈
+)
+
+const errAdminApplicationBulk = "管理员申请不支持批量处理"
+
+// ApplicationCandidate 待决策的申请，游戏内/平台侧统一成同一结构参与规则匹配
+type ApplicationCandidate struct {
+	Source        string
+	ApplicationID int32
+	HouseGID      int32
+	ApplyType     int32
+	ApplierGID    int32 // 申请人游戏ID；平台申请取绑定的游戏账号，未绑定为 0
+	ApplierGName  string
+	ApplierUserID int32
+	AdminUID      int32 // 入圈申请的圈主（平台申请）
+	Recommender   string
+	CreatedAt     time.Time
+}
+
+// ApplicationDecideResult 批量处理的单条结果
+type ApplicationDecideResult struct {
+	ID    int32  `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// ApplicationRuleInput 新建/修改规则入参
+type ApplicationRuleInput struct {
+	HouseGID  int32
+	Name      string
+	Priority  int32
+	Condition string
+	Action    string
+	Params    model.ApplicationRuleParams
+	Enabled   bool
+}
+
+// applicationDecision 一次决策的来龙去脉，落库到 game_shop_application_log
+type applicationDecision struct {
+	action  int32
+	decider string
+	rule    *model.GameApplicationRule
+	adminID int32
+	reason  string
+}
+
+func (d applicationDecision) approve() bool { return d.action == model.ApplicationActionApproved }
+
+// ApplicationUseCase 店铺申请处理：自动规则、超时、批量决策，所有决策留痕
+type ApplicationUseCase struct {
+	rules    repo.ApplicationRuleRepo
+	logs     repo.ShopApplicationLogRepo
+	apps     repo.UserApplicationRepo
+	members  repo.GameMemberRepo
+	accounts repo.GameAccountRepo
+	settings repo.HouseSettingsRepo
+	sAdm     repo.GameShopAdminRepo
+	auth     basicRepo.AuthRepo
+	mgr      plaza.Manager
+	onboard  *OnboardingUseCase
+	block    *BlocklistUseCase
+	log      *log.Helper
+
+	sweepMu   sync.Mutex
+	lastSweep map[int32]time.Time
+}
+
+func NewApplicationUseCase(
+	rules repo.ApplicationRuleRepo,
+	logs repo.ShopApplicationLogRepo,
+	apps repo.UserApplicationRepo,
+	members repo.GameMemberRepo,
+	accounts repo.GameAccountRepo,
+	settings repo.HouseSettingsRepo,
+	sAdm repo.GameShopAdminRepo,
+	auth basicRepo.AuthRepo,
+	mgr plaza.Manager,
+	onboard *OnboardingUseCase,
+	block *BlocklistUseCase,
+	logger log.Logger,
+) *ApplicationUseCase {
+	return &ApplicationUseCase{
+		rules:     rules,
+		logs:      logs,
+		apps:      apps,
+		members:   members,
+		accounts:  accounts,
+		settings:  settings,
+		sAdm:      sAdm,
+		auth:      auth,
+		mgr:       mgr,
+		onboard:   onboard,
+		block:     block,
+		log:       log.NewHelper(log.With(logger, "module", "usecase/application")),
+		lastSweep: make(map[int32]time.Time),
+	}
+}
+
+// ============ 规则管理 ============
+
+func validateApplicationRule(in *ApplicationRuleInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.HouseGID <= 0 {
+		return errors.New("invalid house_gid")
+	}
+	if in.Name == "" {
+		return errors.New("name required")
+	}
+	switch in.Condition {
+	case model.ApplicationRuleRecommenderMember, model.ApplicationRuleAny:
+	case model.ApplicationRuleBlocklist:
+		if len(in.Params.GameIDs) == 0 {
+			return errors.New("blocklist requires game_ids")
+		}
+	default:
+		return fmt.Errorf("unknown condition: %s", in.Condition)
+	}
+	if in.Action != model.ApplicationRuleApprove && in.Action != model.ApplicationRuleReject {
+		return fmt.Errorf("unknown action: %s", in.Action)
+	}
+	// 自动通过只允许入圈申请；管理员申请通过会授予店铺管理员，必须人工处理
+	if in.Action == model.ApplicationRuleApprove {
+		if len(in.Params.ApplyTypes) == 0 {
+			return errors.New("approve rule requires apply_types [2]")
+		}
+		for _, t := range in.Params.ApplyTypes {
+			if t != applyTypeJoin {
+				return fmt.Errorf("approve rule only supports apply_type 2, got %d", t)
+			}
+		}
+	}
+	for _, s := range in.Params.Sources {
+		if s != model.ApplicationSourceGame && s != model.ApplicationSourcePlatform {
+			return fmt.Errorf("unknown source: %s", s)
+		}
+	}
+	return nil
+}
+
+func (uc *ApplicationUseCase) CreateRule(ctx context.Context, opUser int32, in ApplicationRuleInput) (*model.GameApplicationRule, error) {
+	if err := validateApplicationRule(&in); err != nil {
+		return nil, err
+	}
+	m := &model.GameApplicationRule{
+		HouseGID:  in.HouseGID,
+		Name:      in.Name,
+		Priority:  in.Priority,
+		Condition: in.Condition,
+		Action:    in.Action,
+		Params:    in.Params,
+		Enabled:   in.Enabled,
+		CreatedBy: opUser,
+	}
+	if err := uc.rules.Create(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (uc *ApplicationUseCase) UpdateRule(ctx context.Context, id int32, in ApplicationRuleInput) (*model.GameApplicationRule, error) {
+	if err := validateApplicationRule(&in); err != nil {
+		return nil, err
+	}
+	m, err := uc.rules.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.HouseGID != in.HouseGID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	m.Name, m.Priority, m.Condition, m.Action, m.Params, m.Enabled = in.Name, in.Priority, in.Condition, in.Action, in.Params, in.Enabled
+	if err := uc.rules.Update(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (uc *ApplicationUseCase) DeleteRule(ctx context.Context, houseGID, id int32) error {
+	return uc.rules.Delete(ctx, houseGID, id)
+}
+
+func (uc *ApplicationUseCase) ListRules(ctx context.Context, houseGID int32) ([]*model.GameApplicationRule, error) {
+	return uc.rules.ListByHouse(ctx, houseGID)
+}
+
+// SetExpireHours 设置待审申请过期小时数，0 表示不过期
+func (uc *ApplicationUseCase) SetExpireHours(ctx context.Context, opUser, houseGID, hours int32) error {
+	if hours < 0 {
+		return errors.New("hours must be >= 0")
+	}
+	return auditHouseSettings(ctx, uc.settings, houseGID, func() error {
+		return uc.settings.UpsertApplicationExpireHours(ctx, houseGID, hours, opUser)
+	})
+}
+
+// ListDecisions 处理记录
+func (uc *ApplicationUseCase) ListDecisions(ctx context.Context, houseGID int32, f repo.ShopApplicationLogFilter, page, size int32) ([]*model.GameShopApplicationLog, int64, error) {
+	return uc.logs.List(ctx, houseGID, f, page, size)
+}
+
+// ============ 规则匹配 ============
+
+func containsInt32(list []int32, v int32) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// matchApplicationRule 判断规则是否命中；isMember 用于 recommender_member 查询推荐人是否为本店成员
+func matchApplicationRule(r *model.GameApplicationRule, c *ApplicationCandidate, isMember func(gameID int32) bool) bool {
+	if len(r.Params.ApplyTypes) > 0 && !containsInt32(r.Params.ApplyTypes, c.ApplyType) {
+		return false
+	}
+	if len(r.Params.Sources) > 0 && !containsString(r.Params.Sources, c.Source) {
+		return false
+	}
+	switch r.Condition {
+	case model.ApplicationRuleAny:
+		return true
+	case model.ApplicationRuleBlocklist:
+		return c.ApplierGID > 0 && containsInt32(r.Params.GameIDs, c.ApplierGID)
+	case model.ApplicationRuleRecommenderMember:
+		rid, err := strconv.Atoi(strings.TrimSpace(c.Recommender))
+		if err != nil || rid <= 0 || int32(rid) == c.ApplierGID {
+			return false
+		}
+		return isMember(int32(rid))
+	}
+	return false
+}
+
+// evaluate 先查平台黑名单（命中直接拒绝），再按优先级匹配已启用规则，返回命中的第一条
+func (uc *ApplicationUseCase) evaluate(ctx context.Context, c *ApplicationCandidate) (*applicationDecision, error) {
+	if uc.block != nil {
+		hit, err := uc.block.Check(ctx, BlocklistSubject{GameID: c.ApplierGID, UserID: c.ApplierUserID})
+		if err != nil {
+			return nil, err
+		}
+		if hit != nil {
+			return &applicationDecision{action: model.ApplicationActionRejected, decider: model.ApplicationDeciderBlocklist, reason: blocklistReason(hit)}, nil
+		}
+	}
+	rules, err := uc.rules.ListEnabled(ctx, c.HouseGID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	isMember := func(gameID int32) bool {
+		m, err := uc.members.GetByGameID(ctx, c.HouseGID, gameID)
+		return err == nil && m != nil
+	}
+	for _, r := range rules {
+		// 存量规则可能未限定申请类型：自动通过始终只作用于入圈申请
+		if r.Action == model.ApplicationRuleApprove && c.ApplyType != applyTypeJoin {
+			continue
+		}
+		if !matchApplicationRule(r, c, isMember) {
+			continue
+		}
+		d := &applicationDecision{action: model.ApplicationActionRejected, decider: model.ApplicationDeciderRule, rule: r, reason: r.Condition}
+		if r.Action == model.ApplicationRuleApprove {
+			d.action = model.ApplicationActionApproved
+		}
+		return d, nil
+	}
+	return nil, nil
+}
+
+// expireHours 店铺配置的过期小时数，未配置返回 0
+func (uc *ApplicationUseCase) expireHours(ctx context.Context, houseGID int32) int32 {
+	s, err := uc.settings.Get(ctx, houseGID)
+	if err != nil || s == nil {
+		return 0
+	}
+	return s.ApplicationExpireHours
+}
+
+// ============ 游戏内申请 ============
+
+// gameCandidate 游戏内申请不带推荐人，取申请人在本店已有成员记录上的推荐人（退圈后重新申请的场景）
+func (uc *ApplicationUseCase) gameCandidate(ctx context.Context, houseGID int32, ai *plazaUtils.ApplyInfo) *ApplicationCandidate {
+	c := &ApplicationCandidate{
+		Source:        model.ApplicationSourceGame,
+		ApplicationID: int32(ai.MessageId),
+		HouseGID:      houseGID,
+		ApplyType:     int32(ai.ApplyType),
+		ApplierGID:    int32(ai.ApplierGid),
+		ApplierGName:  ai.ApplierGName,
+		ApplierUserID: int32(ai.AplierId),
+		CreatedAt:     time.Unix(ai.CreatedAt, 0),
+	}
+	if m, err := uc.members.GetByGameID(ctx, houseGID, c.ApplierGID); err == nil && m != nil {
+		c.Recommender = m.Recommender
+	}
+	return c
+}
+
+// gameSession 处理游戏内申请所用会话：优先操作人自己的会话，否则任意在线会话
+func (uc *ApplicationUseCase) gameSession(opUser, houseGID int32) (*plazaUtils.Session, bool) {
+	if opUser > 0 {
+		if sess, ok := uc.mgr.Get(int(opUser), int(houseGID)); ok && sess != nil {
+			return sess, true
+		}
+	}
+	sess, ok := uc.mgr.GetAnyByHouse(int(houseGID))
+	return sess, ok && sess != nil
+}
+
+// OnGameApplies 会话收到新的游戏内申请：先看是否已超时，再跑自动规则；已有处理记录的跳过
+func (uc *ApplicationUseCase) OnGameApplies(ctx context.Context, houseGID int32, list []*plazaUtils.ApplyInfo) {
+	if len(list) == 0 {
+		return
+	}
+	sess, ok := uc.gameSession(0, houseGID)
+	if !ok {
+		return
+	}
+	hours := uc.expireHours(ctx, houseGID)
+	for _, ai := range list {
+		if ai == nil {
+			continue
+		}
+		// 已处理的快速跳过；并发时以 decideGame 写入的唯一处理记录为准
+		if done, err := uc.logs.ExistsDecision(ctx, houseGID, model.ApplicationSourceGame, int32(ai.MessageId)); err != nil || done {
+			continue
+		}
+		c := uc.gameCandidate(ctx, houseGID, ai)
+		d := uc.expiryDecision(c, hours)
+		if d == nil {
+			var err error
+			if d, err = uc.evaluate(ctx, c); err != nil {
+				uc.log.Warnf("evaluate application rules house=%d msg=%d err=%v", houseGID, ai.MessageId, err)
+				continue
+			}
+		}
+		if d == nil {
+			continue
+		}
+		if _, err := uc.decideGame(ctx, sess, ai, c, d); err != nil {
+			uc.log.Warnf("auto decide application house=%d msg=%d err=%v", houseGID, ai.MessageId, err)
+		}
+	}
+}
+
+// SweepGame 游戏内待处理申请超时检查（由会话巡检调用，每店铺每分钟最多一次）
+func (uc *ApplicationUseCase) SweepGame(ctx context.Context, houseGID int32) {
+	now := time.Now()
+	uc.sweepMu.Lock()
+	if now.Sub(uc.lastSweep[houseGID]) < gameApplicationSweepInterval {
+		uc.sweepMu.Unlock()
+		return
+	}
+	uc.lastSweep[houseGID] = now
+	uc.sweepMu.Unlock()
+
+	hours := uc.expireHours(ctx, houseGID)
+	if hours <= 0 {
+		return
+	}
+	sess, ok := uc.gameSession(0, houseGID)
+	if !ok {
+		return
+	}
+	for _, ai := range sess.ListApplications(int(houseGID)) {
+		c := uc.gameCandidate(ctx, houseGID, ai)
+		d := uc.expiryDecision(c, hours)
+		if d == nil {
+			continue
+		}
+		if done, err := uc.logs.ExistsDecision(ctx, houseGID, model.ApplicationSourceGame, c.ApplicationID); err != nil || done {
+			continue
+		}
+		if _, err := uc.decideGame(ctx, sess, ai, c, d); err != nil {
+			uc.log.Warnf("expire application house=%d msg=%d err=%v", houseGID, ai.MessageId, err)
+		}
+	}
+}
+
+// applicationResponder 回发游戏内申请结果（*plazaUtils.Session）
+type applicationResponder interface {
+	RespondApplication(ai *plazaUtils.ApplyInfo, agree bool) error
+}
+
+// decideGame 游戏内申请先写唯一处理记录占住该申请，再回发协议；已被其他途径处理过的返回 false。
+// 回发失败时删掉处理记录，申请保持待审，不做后续授权/入店
+func (uc *ApplicationUseCase) decideGame(ctx context.Context, sess applicationResponder, ai *plazaUtils.ApplyInfo, c *ApplicationCandidate, d *applicationDecision) (bool, error) {
+	entry := newApplicationLog(c, d)
+	ok, err := uc.logs.CreateDecision(ctx, entry)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err = sess.RespondApplication(ai, d.approve()); err != nil {
+		if derr := uc.logs.DeleteDecision(ctx, entry.Id); derr != nil {
+			uc.log.Errorf("release application log house=%d msg=%d err=%v", c.HouseGID, c.ApplicationID, derr)
+		}
+		return false, errors.Wrap(err, "respond application")
+	}
+	uc.afterDecision(ctx, c, d, entry)
+	return true, nil
+}
+
+func (uc *ApplicationUseCase) expiryDecision(c *ApplicationCandidate, hours int32) *applicationDecision {
+	if hours <= 0 || c.CreatedAt.IsZero() || time.Since(c.CreatedAt) < time.Duration(hours)*time.Hour {
+		return nil
+	}
+	return &applicationDecision{action: model.ApplicationActionExpired, decider: model.ApplicationDeciderExpiry, reason: fmt.Sprintf("pending over %dh", hours)}
+}
+
+// ============ 平台侧申请 ============
+
+func (uc *ApplicationUseCase) platformCandidate(ctx context.Context, a *model.UserApplication) *ApplicationCandidate {
+	c := &ApplicationCandidate{
+		Source:        model.ApplicationSourcePlatform,
+		ApplicationID: a.Id,
+		HouseGID:      a.HouseGID,
+		ApplyType:     a.Type,
+		ApplierUserID: a.Applicant,
+		AdminUID:      a.AdminUID,
+		Recommender:   a.Recommender,
+		CreatedAt:     a.CreatedAt,
+	}
+	if acc, err := uc.accounts.GetOneByUser(ctx, a.Applicant); err == nil && acc != nil {
+		if gid, err := strconv.Atoi(acc.GameUserID); err == nil {
+			c.ApplierGID = int32(gid)
+		}
+		c.ApplierGName = acc.Nickname
+	}
+	return c
+}
+
+// OnPlatformApplication 平台侧申请入库后跑一遍自动规则，未命中保持待审
+func (uc *ApplicationUseCase) OnPlatformApplication(ctx context.Context, a *model.UserApplication) {
+	c := uc.platformCandidate(ctx, a)
+	d, err := uc.evaluate(ctx, c)
+	if err != nil {
+		uc.log.Warnf("evaluate application rules house=%d app=%d err=%v", a.HouseGID, a.Id, err)
+		return
+	}
+	if d == nil {
+		return
+	}
+	if _, err := uc.decidePlatform(ctx, c, d); err != nil {
+		uc.log.Warnf("auto decide application house=%d app=%d err=%v", a.HouseGID, a.Id, err)
+	}
+}
+
+// decidePlatform 仅当申请仍为待审时落状态（1通过/2拒绝/4超时），返回是否由本次处理
+func (uc *ApplicationUseCase) decidePlatform(ctx context.Context, c *ApplicationCandidate, d *applicationDecision) (bool, error) {
+	status := int32(2)
+	switch d.action {
+	case model.ApplicationActionApproved:
+		status = 1
+	case model.ApplicationActionExpired:
+		status = 4
+	}
+	ok, err := uc.apps.DecidePending(ctx, c.ApplicationID, status)
+	if err != nil || !ok {
+		return false, err
+	}
+	// 状态流转已保证只处理一次，处理记录写失败不回滚审批结果
+	entry := newApplicationLog(c, d)
+	if _, err := uc.logs.CreateDecision(ctx, entry); err != nil {
+		uc.log.Warnf("save application log house=%d app=%d err=%v", c.HouseGID, c.ApplicationID, err)
+	}
+	uc.afterDecision(ctx, c, d, entry)
+	return true, nil
+}
+
+// ExpirePlatform 平台侧待审申请超时（asynq 定时任务，按平台执行）
+func (uc *ApplicationUseCase) ExpirePlatform(ctx context.Context) error {
+	houses, err := uc.apps.ListPendingHouses(ctx)
+	if err != nil {
+		return err
+	}
+	for _, houseGID := range houses {
+		hours := uc.expireHours(ctx, houseGID)
+		if hours <= 0 {
+			continue
+		}
+		list, err := uc.apps.ListPendingBefore(ctx, houseGID, time.Now().Add(-time.Duration(hours)*time.Hour))
+		if err != nil {
+			uc.log.Warnf("list expired applications house=%d err=%v", houseGID, err)
+			continue
+		}
+		for _, a := range list {
+			c := uc.platformCandidate(ctx, a)
+			if _, err := uc.decidePlatform(ctx, c, uc.expiryDecision(c, hours)); err != nil {
+				uc.log.Warnf("expire application house=%d app=%d err=%v", houseGID, a.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ============ 手动/批量 ============
+
+// Decide 手动处理（单条即长度为 1 的批量）。游戏内申请需店铺有在线会话；平台申请已处理过的返回错误
+func (uc *ApplicationUseCase) Decide(ctx context.Context, opUser, houseGID int32, source string, ids []int32, approve bool, reason string) ([]*ApplicationDecideResult, error) {
+	return uc.decide(ctx, opUser, houseGID, source, ids, approve, reason, true)
+}
+
+// DecideBulk 批量处理；管理员申请涉及授权，不参与批量，需单条处理
+func (uc *ApplicationUseCase) DecideBulk(ctx context.Context, opUser, houseGID int32, source string, ids []int32, approve bool, reason string) ([]*ApplicationDecideResult, error) {
+	return uc.decide(ctx, opUser, houseGID, source, ids, approve, reason, false)
+}
+
+func (uc *ApplicationUseCase) decide(ctx context.Context, opUser, houseGID int32, source string, ids []int32, approve bool, reason string, allowAdmin bool) ([]*ApplicationDecideResult, error) {
+	d := &applicationDecision{action: model.ApplicationActionRejected, decider: model.ApplicationDeciderManual, adminID: opUser, reason: strings.TrimSpace(reason)}
+	if approve {
+		d.action = model.ApplicationActionApproved
+	}
+	out := make([]*ApplicationDecideResult, 0, len(ids))
+	switch source {
+	case model.ApplicationSourceGame:
+		sess, ok := uc.gameSession(opUser, houseGID)
+		if !ok {
+			return nil, errors.New("会话不存在，请先登录游戏")
+		}
+		for _, id := range ids {
+			r := &ApplicationDecideResult{ID: id}
+			out = append(out, r)
+			ai, ok := sess.FindApplicationByID(int(id))
+			if !ok || ai == nil {
+				r.Error = "申请信息不存在或已过期"
+				continue
+			}
+			if !allowAdmin && int32(ai.ApplyType) == applyTypeAdmin {
+				r.Error = errAdminApplicationBulk
+				continue
+			}
+			done, err := uc.decideGame(ctx, sess, ai, uc.gameCandidate(ctx, houseGID, ai), d)
+			switch {
+			case err != nil:
+				r.Error = err.Error()
+			case !done:
+				r.Error = "申请已处理"
+			default:
+				r.OK = true
+			}
+		}
+	case model.ApplicationSourcePlatform:
+		for _, id := range ids {
+			r := &ApplicationDecideResult{ID: id}
+			out = append(out, r)
+			a, err := uc.apps.GetByID(ctx, id)
+			if err != nil || a == nil || a.HouseGID != houseGID {
+				r.Error = "申请不存在"
+				continue
+			}
+			if !allowAdmin && a.Type == applyTypeAdmin {
+				r.Error = errAdminApplicationBulk
+				continue
+			}
+			done, err := uc.decidePlatform(ctx, uc.platformCandidate(ctx, a), d)
+			switch {
+			case err != nil:
+				r.Error = err.Error()
+			case !done:
+				r.Error = "申请已处理"
+			default:
+				r.OK = true
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown source: %s", source)
+	}
+	// 批量时逐条写会互相覆盖，统一在这里记一条审计
+	auditIDs := make([]any, 0, len(ids))
+	for _, id := range ids {
+		auditIDs = append(auditIDs, id)
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "application", auditIDs...)
+	auditx.SetBefore(ctx, map[string]any{"source": source, "status": "pending"})
+	auditx.SetAfter(ctx, map[string]any{"action": d.action, "results": out})
+	auditx.SetReason(ctx, d.reason)
+	return out, nil
+}
+
+// newApplicationLog 一次决策对应的处理记录
+func newApplicationLog(c *ApplicationCandidate, d *applicationDecision) *model.GameShopApplicationLog {
+	entry := &model.GameShopApplicationLog{
+		HouseGID:      c.HouseGID,
+		Source:        c.Source,
+		ApplicationID: c.ApplicationID,
+		ApplyType:     c.ApplyType,
+		ApplierGID:    c.ApplierGID,
+		ApplierGName:  c.ApplierGName,
+		ApplierUserID: c.ApplierUserID,
+		Recommender:   c.Recommender,
+		Action:        d.action,
+		Decider:       d.decider,
+		AdminUserID:   d.adminID,
+		Reason:        d.reason,
+	}
+	if d.rule != nil {
+		entry.RuleID, entry.RuleName = &d.rule.Id, d.rule.Name
+	}
+	return entry
+}
+
+// afterDecision 决策的公共收尾（处理记录已写入）：管理员申请通过的授权 / 入圈申请通过的入店流程、发布 application.decided
+func (uc *ApplicationUseCase) afterDecision(ctx context.Context, c *ApplicationCandidate, d *applicationDecision, entry *model.GameShopApplicationLog) {
+	if d.approve() && c.ApplyType == applyTypeAdmin && c.ApplierUserID > 0 { // 管理员申请通过 -> 仅保留管理员角色并写入店铺管理员
+		if err := uc.auth.EnsureUserHasOnlyRoleByCode(ctx, c.ApplierUserID, "shop_admin"); err != nil {
+			uc.log.Warnf("ensure shop_admin role user=%d err=%v", c.ApplierUserID, err)
+		}
+		if err := uc.sAdm.Assign(ctx, &model.GameShopAdmin{HouseGID: c.HouseGID, UserID: c.ApplierUserID, Role: "admin"}); err != nil {
+			uc.log.Warnf("assign shop admin house=%d user=%d err=%v", c.HouseGID, c.ApplierUserID, err)
+		} else {
+			// 授权是单独的权限变更，无论手动还是规则通过都单独记一条
+			_ = auditx.Write(ctx, &auditx.Entry{
+				Platform:   pdb.GetDBKeyFromCtx(ctx),
+				ActorID:    d.adminID,
+				HouseGID:   c.HouseGID,
+				Action:     "shop:admin:assign",
+				TargetType: "user",
+				TargetIDs:  []string{strconv.Itoa(int(c.ApplierUserID))},
+				After:      map[string]any{"role": "admin", "application_id": c.ApplicationID, "decider": d.decider},
+				Success:    true,
+			})
+		}
+	} else if d.approve() && uc.onboard != nil {
+		uc.startOnboarding(ctx, c, d)
+	}
+
+	eventx.Publish(ctx, c.HouseGID, eventx.TypeApplicationDecided, map[string]any{
+		"source":         c.Source,
+		"application_id": c.ApplicationID,
+		"applier_gid":    c.ApplierGID,
+		"applier_gname":  c.ApplierGName,
+		"approved":       d.approve(),
+		"action":         d.action,
+		"decider":        d.decider,
+		"rule_id":        entry.RuleID,
+		"admin_user_id":  d.adminID,
+	})
+}
+
+// startOnboarding 入圈申请通过后发起入店流程；失败停在断点，可在入店流程列表重试，不影响审批结果
+func (uc *ApplicationUseCase) startOnboarding(ctx context.Context, c *ApplicationCandidate, d *applicationDecision) {
+	in := OnboardingInput{
+		HouseGID:      c.HouseGID,
+		Source:        c.Source,
+		ApplicationID: c.ApplicationID,
+		GameID:        c.ApplierGID,
+		GameName:      c.ApplierGName,
+		Recommender:   c.Recommender,
+		GroupAdminUID: c.AdminUID,
+	}
+	if c.Source == model.ApplicationSourcePlatform {
+		in.ApplicantUserID = c.ApplierUserID
+	}
+	if _, err := uc.onboard.Start(ctx, d.adminID, in); err != nil {
+		uc.log.Warnf("onboarding house=%d app=%d err=%v", c.HouseGID, c.ApplicationID, err)
+	}
+}
@@ -0,0 +1,276 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	repo "battle-tiles/internal/dal/repo/game"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestMatchApplicationRule(t *testing.T) {
+	members := map[int32]bool{7001: true}
+	isMember := func(id int32) bool { return members[id] }
+
+	cases := []struct {
+		name string
+		rule model.GameApplicationRule
+		c    ApplicationCandidate
+		want bool
+	}{
+		{"blocklist hit", model.GameApplicationRule{Condition: model.ApplicationRuleBlocklist, Params: model.ApplicationRuleParams{GameIDs: []int32{9001}}},
+			ApplicationCandidate{ApplierGID: 9001}, true},
+		{"blocklist miss", model.GameApplicationRule{Condition: model.ApplicationRuleBlocklist, Params: model.ApplicationRuleParams{GameIDs: []int32{9001}}},
+			ApplicationCandidate{ApplierGID: 9002}, false},
+		{"recommender is member", model.GameApplicationRule{Condition: model.ApplicationRuleRecommenderMember},
+			ApplicationCandidate{ApplierGID: 9001, Recommender: " 7001 "}, true},
+		{"recommender not member", model.GameApplicationRule{Condition: model.ApplicationRuleRecommenderMember},
+			ApplicationCandidate{ApplierGID: 9001, Recommender: "7002"}, false},
+		{"self recommend", model.GameApplicationRule{Condition: model.ApplicationRuleRecommenderMember},
+			ApplicationCandidate{ApplierGID: 7001, Recommender: "7001"}, false},
+		{"apply type filtered", model.GameApplicationRule{Condition: model.ApplicationRuleAny, Params: model.ApplicationRuleParams{ApplyTypes: []int32{2}}},
+			ApplicationCandidate{ApplyType: 1}, false},
+		{"source filtered", model.GameApplicationRule{Condition: model.ApplicationRuleAny, Params: model.ApplicationRuleParams{Sources: []string{model.ApplicationSourcePlatform}}},
+			ApplicationCandidate{Source: model.ApplicationSourceGame}, false},
+		{"any", model.GameApplicationRule{Condition: model.ApplicationRuleAny},
+			ApplicationCandidate{Source: model.ApplicationSourceGame}, true},
+	}
+	for _, tc := range cases {
+		if got := matchApplicationRule(&tc.rule, &tc.c, isMember); got != tc.want {
+			t.Errorf("%s: got %v want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestValidateApplicationRuleApproveOnlyJoin(t *testing.T) {
+	cases := []struct {
+		name   string
+		action string
+		types  []int32
+		ok     bool
+	}{
+		{"approve join", model.ApplicationRuleApprove, []int32{2}, true},
+		{"approve unrestricted", model.ApplicationRuleApprove, nil, false},
+		{"approve admin", model.ApplicationRuleApprove, []int32{1, 2}, false},
+		{"reject admin", model.ApplicationRuleReject, []int32{1}, true},
+		{"reject unrestricted", model.ApplicationRuleReject, nil, true},
+	}
+	for _, tc := range cases {
+		in := ApplicationRuleInput{HouseGID: 20001, Name: tc.name, Condition: model.ApplicationRuleAny, Action: tc.action,
+			Params: model.ApplicationRuleParams{ApplyTypes: tc.types}}
+		if err := validateApplicationRule(&in); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+// memApplicationLogs 按 店铺+来源+申请ID 唯一的处理记录
+type memApplicationLogs struct {
+	repo.ShopApplicationLogRepo
+	rows []*model.GameShopApplicationLog
+}
+
+func (r *memApplicationLogs) CreateDecision(_ context.Context, in *model.GameShopApplicationLog) (bool, error) {
+	for _, e := range r.rows {
+		if e.HouseGID == in.HouseGID && e.Source == in.Source && e.ApplicationID == in.ApplicationID {
+			return false, nil
+		}
+	}
+	in.Id = int32(len(r.rows) + 1)
+	r.rows = append(r.rows, in)
+	return true, nil
+}
+
+func (r *memApplicationLogs) DeleteDecision(_ context.Context, id int32) error {
+	for i, e := range r.rows {
+		if e.Id == id {
+			r.rows = append(r.rows[:i], r.rows[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type memUserApplications struct {
+	repo.UserApplicationRepo
+	apps map[int32]*model.UserApplication
+}
+
+func (r *memUserApplications) GetByID(_ context.Context, id int32) (*model.UserApplication, error) {
+	return r.apps[id], nil
+}
+
+func (r *memUserApplications) DecidePending(_ context.Context, id int32, status int32) (bool, error) {
+	a, ok := r.apps[id]
+	if !ok || a.Status != 0 {
+		return false, nil
+	}
+	a.Status = status
+	return true, nil
+}
+
+type fakeAppRules struct {
+	repo.ApplicationRuleRepo
+	rules []*model.GameApplicationRule
+}
+
+func (r *fakeAppRules) ListEnabled(context.Context, int32) ([]*model.GameApplicationRule, error) {
+	return r.rules, nil
+}
+
+type fakeAppAccounts struct{ repo.GameAccountRepo }
+
+func (fakeAppAccounts) GetOneByUser(context.Context, int32) (*model.GameAccount, error) {
+	return nil, nil
+}
+
+type fakeAppMembers struct{ repo.GameMemberRepo }
+
+func (fakeAppMembers) GetByGameID(context.Context, int32, int32) (*model.GameMember, error) {
+	return nil, nil
+}
+
+// fakeAppGrants 记录管理员申请通过后的授权
+type fakeAppGrants struct {
+	basicRepo.AuthRepo
+	repo.GameShopAdminRepo
+	roles  []string
+	admins []int32
+}
+
+func (f *fakeAppGrants) EnsureUserHasOnlyRoleByCode(_ context.Context, userID int32, code string) error {
+	f.roles = append(f.roles, fmt.Sprintf("%d:%s", userID, code))
+	return nil
+}
+
+func (f *fakeAppGrants) Assign(_ context.Context, m *model.GameShopAdmin) error {
+	f.admins = append(f.admins, m.UserID)
+	return nil
+}
+
+func newTestApplicationUseCase(apps map[int32]*model.UserApplication, rules ...*model.GameApplicationRule) (*ApplicationUseCase, *memApplicationLogs, *fakeAppGrants) {
+	logs := &memApplicationLogs{}
+	grants := &fakeAppGrants{}
+	uc := NewApplicationUseCase(&fakeAppRules{rules: rules}, logs, &memUserApplications{apps: apps}, fakeAppMembers{}, fakeAppAccounts{},
+		nil, grants, grants, nil, nil, nil, log.DefaultLogger)
+	return uc, logs, grants
+}
+
+func TestDecidePlatformOnce(t *testing.T) {
+	ctx := context.Background()
+	uc, logs, grants := newTestApplicationUseCase(map[int32]*model.UserApplication{
+		5: {Id: 5, HouseGID: 20001, Applicant: 300, Type: 1},
+		6: {Id: 6, HouseGID: 20002, Applicant: 301, Type: 2},
+	})
+
+	res, err := uc.Decide(ctx, 9, 20001, model.ApplicationSourcePlatform, []int32{5, 5, 6}, true, " ok ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0].OK || res[1].OK || res[1].Error != "申请已处理" || res[2].OK {
+		t.Fatalf("results = %+v %+v %+v", res[0], res[1], res[2])
+	}
+	if len(logs.rows) != 1 {
+		t.Fatalf("decision rows = %d, want 1", len(logs.rows))
+	}
+	e := logs.rows[0]
+	if e.ApplicationID != 5 || e.Decider != model.ApplicationDeciderManual || e.AdminUserID != 9 || e.Reason != "ok" || e.Action != model.ApplicationActionApproved {
+		t.Fatalf("decision row = %+v", e)
+	}
+	// 管理员申请通过：授予 shop_admin 并写入店铺管理员，且只做一次
+	if len(grants.roles) != 1 || grants.roles[0] != "300:shop_admin" || len(grants.admins) != 1 {
+		t.Fatalf("grants = %v %v", grants.roles, grants.admins)
+	}
+}
+
+func TestPlatformAutoApproveOnlyJoin(t *testing.T) {
+	ctx := context.Background()
+	// 存量规则未限定申请类型
+	rule := &model.GameApplicationRule{Id: 3, HouseGID: 20001, Name: "all in", Condition: model.ApplicationRuleAny, Action: model.ApplicationRuleApprove}
+	apps := map[int32]*model.UserApplication{
+		1: {Id: 1, HouseGID: 20001, Applicant: 300, Type: 1},
+		2: {Id: 2, HouseGID: 20001, Applicant: 301, Type: 2},
+	}
+	uc, logs, grants := newTestApplicationUseCase(apps, rule)
+
+	uc.OnPlatformApplication(ctx, apps[1])
+	uc.OnPlatformApplication(ctx, apps[2])
+	if apps[1].Status != 0 || len(grants.roles) != 0 {
+		t.Fatalf("admin application auto-approved: status=%d grants=%v", apps[1].Status, grants.roles)
+	}
+	if apps[2].Status != 1 || len(logs.rows) != 1 {
+		t.Fatalf("join application status=%d rows=%d", apps[2].Status, len(logs.rows))
+	}
+	if e := logs.rows[0]; e.Decider != model.ApplicationDeciderRule || e.RuleID == nil || *e.RuleID != 3 || e.RuleName != "all in" {
+		t.Fatalf("decision row = %+v", e)
+	}
+}
+
+func TestDecideGameClaimsBeforeResponding(t *testing.T) {
+	ctx := context.Background()
+	uc, logs, grants := newTestApplicationUseCase(nil)
+	ai := &plazaUtils.ApplyInfo{MessageId: 77, ApplyType: 1, AplierId: 300}
+	c := uc.gameCandidate(ctx, 20001, ai)
+	d := &applicationDecision{action: model.ApplicationActionApproved, decider: model.ApplicationDeciderRule}
+	if _, err := logs.CreateDecision(ctx, newApplicationLog(c, d)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已被处理：不回发协议（会话为 nil，触达即 panic），也不做授权
+	done, err := uc.decideGame(ctx, nil, ai, c, d)
+	if err != nil || done {
+		t.Fatalf("decideGame = %v, %v; want false, nil", done, err)
+	}
+	if len(logs.rows) != 1 || len(grants.roles) != 0 {
+		t.Fatalf("rows=%d grants=%v", len(logs.rows), grants.roles)
+	}
+}
+
+// failingResponder 回发失败（如会话已关闭）
+type failingResponder struct{}
+
+func (failingResponder) RespondApplication(*plazaUtils.ApplyInfo, bool) error {
+	return plazaUtils.ErrSessionClosed
+}
+
+func TestDecideGameReleasesClaimWhenRespondFails(t *testing.T) {
+	ctx := context.Background()
+	uc, logs, grants := newTestApplicationUseCase(nil)
+	ai := &plazaUtils.ApplyInfo{MessageId: 78, ApplyType: 1, AplierId: 300}
+	c := uc.gameCandidate(ctx, 20001, ai)
+	c.ApplierUserID = 300
+	d := &applicationDecision{action: model.ApplicationActionApproved, decider: model.ApplicationDeciderManual}
+
+	done, err := uc.decideGame(ctx, failingResponder{}, ai, c, d)
+	if err == nil || done {
+		t.Fatalf("decideGame = %v, %v; want false with error", done, err)
+	}
+	// 处理记录被释放，可再次处理；未做授权
+	if len(logs.rows) != 0 || len(grants.roles) != 0 || len(grants.admins) != 0 {
+		t.Fatalf("rows=%d grants=%v %v", len(logs.rows), grants.roles, grants.admins)
+	}
+}
+
+func TestDecideBulkSkipsAdminApplications(t *testing.T) {
+	ctx := context.Background()
+	apps := map[int32]*model.UserApplication{
+		5: {Id: 5, HouseGID: 20001, Applicant: 300, Type: 1},
+		6: {Id: 6, HouseGID: 20001, Applicant: 301, Type: 2},
+	}
+	uc, logs, grants := newTestApplicationUseCase(apps)
+
+	res, err := uc.DecideBulk(ctx, 9, 20001, model.ApplicationSourcePlatform, []int32{5, 6}, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].OK || res[0].Error != errAdminApplicationBulk || !res[1].OK {
+		t.Fatalf("results = %+v %+v", res[0], res[1])
+	}
+	if apps[5].Status != 0 || apps[6].Status != 1 || len(logs.rows) != 1 || len(grants.roles) != 0 {
+		t.Fatalf("status=%d/%d rows=%d grants=%v", apps[5].Status, apps[6].Status, len(logs.rows), grants.roles)
+	}
+}
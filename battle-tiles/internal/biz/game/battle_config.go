@@ -0,0 +1,209 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+)
+
+// BattleConfigInput 添加/修改玩法入参（添加时 ConfigID 为 0）
+type BattleConfigInput struct {
+	ConfigID    int32
+	KindID      int32
+	BaseScore   int32
+	PlayCount   int32
+	PlayerCount int32
+	Name        string
+}
+
+// BattleConfigUseCase 店铺玩法：会话推送落库，通过中控会话增删改，战绩按玩法关联
+type BattleConfigUseCase struct {
+	repo repo.BattleConfigRepo
+	mgr  plaza.Manager
+	log  *log.Helper
+}
+
+func NewBattleConfigUseCase(r repo.BattleConfigRepo, mgr plaza.Manager, logger log.Logger) *BattleConfigUseCase {
+	return &BattleConfigUseCase{
+		repo: r,
+		mgr:  mgr,
+		log:  log.NewHelper(log.With(logger, "module", "usecase/battle_config")),
+	}
+}
+
+// OnSessionConfigs 会话玩法快照变化（全量）
+func (uc *BattleConfigUseCase) OnSessionConfigs(ctx context.Context, houseGID int32, list []*plazaUtils.BattleConfig) {
+	rows := make([]*model.GameBattleConfig, 0, len(list))
+	for _, c := range list {
+		if c == nil || c.ConfigID == 0 {
+			continue
+		}
+		rows = append(rows, &model.GameBattleConfig{
+			ConfigID:    int32(c.ConfigID),
+			KindID:      int32(c.KindID),
+			BaseScore:   int32(c.BaseScore),
+			PlayCount:   int32(c.PlayCount),
+			PlayerCount: int32(c.PlayerCount),
+			Name:        c.Name,
+		})
+	}
+	if err := uc.repo.Sync(ctx, houseGID, rows); err != nil {
+		uc.log.Errorf("sync battle configs house=%d failed: %v", houseGID, err)
+	}
+}
+
+func (uc *BattleConfigUseCase) List(ctx context.Context, houseGID int32, includeRemoved bool) ([]*model.GameBattleConfig, error) {
+	return uc.repo.ListByHouse(ctx, houseGID, includeRemoved)
+}
+
+func (uc *BattleConfigUseCase) session(opUser, houseGID int32) (*plazaUtils.Session, error) {
+	return onlineSession(uc.mgr, opUser, houseGID)
+}
+
+// onlineSession 优先操作人自己的会话，否则任意在线会话
+func onlineSession(mgr plaza.Manager, opUser, houseGID int32) (*plazaUtils.Session, error) {
+	if sess, ok := mgr.Get(int(opUser), int(houseGID)); ok && sess != nil {
+		return sess, nil
+	}
+	if sess, ok := mgr.GetAnyByHouse(int(houseGID)); ok && sess != nil {
+		return sess, nil
+	}
+	return nil, errors.New("no online session")
+}
+
+func validateBattleConfig(in *BattleConfigInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.KindID <= 0 || in.KindID > 0xFFFF {
+		return errors.New("invalid kind_id")
+	}
+	if in.BaseScore < 0 {
+		return errors.New("base_score must be >= 0")
+	}
+	if in.PlayCount <= 0 || in.PlayCount > 0xFFFF {
+		return errors.New("invalid play_count")
+	}
+	if in.PlayerCount <= 0 || in.PlayerCount > 0xFFFF {
+		return errors.New("invalid player_count")
+	}
+	return nil
+}
+
+func (in BattleConfigInput) toPlaza() *plazaUtils.BattleConfig {
+	return &plazaUtils.BattleConfig{
+		ConfigID:    uint32(in.ConfigID),
+		KindID:      uint16(in.KindID),
+		BaseScore:   uint32(in.BaseScore),
+		PlayCount:   uint16(in.PlayCount),
+		PlayerCount: uint16(in.PlayerCount),
+		Name:        in.Name,
+	}
+}
+
+func hasBattleConfig(sess *plazaUtils.Session, configID int32) bool {
+	for _, c := range sess.ListBattleConfigs() {
+		if int32(c.ConfigID) == configID {
+			return true
+		}
+	}
+	return false
+}
+
+// Append 通过中控会话添加玩法；生效以游戏端推送为准（异步）
+func (uc *BattleConfigUseCase) Append(ctx context.Context, opUser, houseGID int32, in BattleConfigInput) error {
+	in.ConfigID = 0
+	if err := validateBattleConfig(&in); err != nil {
+		return err
+	}
+	sess, err := uc.session(opUser, houseGID)
+	if err != nil {
+		return err
+	}
+	sess.AppendBattleConfig(in.toPlaza())
+	return nil
+}
+
+// Modify 修改玩法（需在会话快照中存在）
+func (uc *BattleConfigUseCase) Modify(ctx context.Context, opUser, houseGID int32, in BattleConfigInput) error {
+	if in.ConfigID <= 0 {
+		return errors.New("invalid config_id")
+	}
+	if err := validateBattleConfig(&in); err != nil {
+		return err
+	}
+	sess, err := uc.session(opUser, houseGID)
+	if err != nil {
+		return err
+	}
+	if !hasBattleConfig(sess, in.ConfigID) {
+		return errors.New("config not found")
+	}
+	sess.ModifyBattleConfig(in.toPlaza())
+	return nil
+}
+
+// Delete 删除玩法（需在会话快照中存在）
+func (uc *BattleConfigUseCase) Delete(ctx context.Context, opUser, houseGID, configID int32) error {
+	sess, err := uc.session(opUser, houseGID)
+	if err != nil {
+		return err
+	}
+	if !hasBattleConfig(sess, configID) {
+		return errors.New("config not found")
+	}
+	sess.DeleteBattleConfig(uint32(configID))
+	return nil
+}
+
+// matchBattleConfig 按 kind_id + base_score 匹配玩法，唯一命中才返回
+func matchBattleConfig(configs []*model.GameBattleConfig, kindID, baseScore int32) *int32 {
+	var hit *int32
+	for _, c := range configs {
+		if c.KindID != kindID || c.BaseScore != baseScore {
+			continue
+		}
+		if hit != nil {
+			return nil
+		}
+		id := c.ConfigID
+		hit = &id
+	}
+	return hit
+}
+
+// LinkRecords 为一批战绩填充 config_id；已删除的玩法也参与匹配（战绩可能晚于删除入库）
+func (uc *BattleConfigUseCase) LinkRecords(ctx context.Context, houseGID int32, records []*model.GameBattleRecord) {
+	if uc == nil || len(records) == 0 {
+		return
+	}
+	configs, err := uc.repo.ListByHouse(ctx, houseGID, true)
+	if err != nil {
+		uc.log.Warnf("load battle configs house=%d failed: %v", houseGID, err)
+		return
+	}
+	if len(configs) == 0 {
+		return
+	}
+	active := make([]*model.GameBattleConfig, 0, len(configs))
+	for _, c := range configs {
+		if !c.Removed {
+			active = append(active, c)
+		}
+	}
+	for _, r := range records {
+		if r.ConfigID != nil {
+			continue
+		}
+		// 先在现存玩法中找，找不到再看已删除的
+		if id := matchBattleConfig(active, r.KindID, r.BaseScore); id != nil {
+			r.ConfigID = id
+		} else {
+			r.ConfigID = matchBattleConfig(configs, r.KindID, r.BaseScore)
+		}
+	}
+}
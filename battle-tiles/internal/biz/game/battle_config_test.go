@@ -0,0 +1,116 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memBattleConfigs 玩法快照：Sync 为全量，未出现的标记 removed
+type memBattleConfigs struct {
+	repo.BattleConfigRepo
+	rows map[int32]*model.GameBattleConfig
+}
+
+func (r *memBattleConfigs) Sync(_ context.Context, houseGID int32, list []*model.GameBattleConfig) error {
+	seen := make(map[int32]bool, len(list))
+	for _, c := range list {
+		c.HouseGID = houseGID
+		r.rows[c.ConfigID] = c
+		seen[c.ConfigID] = true
+	}
+	for id, c := range r.rows {
+		if !seen[id] {
+			c.Removed = true
+		}
+	}
+	return nil
+}
+
+func (r *memBattleConfigs) ListByHouse(_ context.Context, _ int32, includeRemoved bool) ([]*model.GameBattleConfig, error) {
+	var out []*model.GameBattleConfig
+	for _, c := range r.rows {
+		if includeRemoved || !c.Removed {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type noSessionManager struct{ plaza.Manager }
+
+func (noSessionManager) Get(int, int) (*plazaUtils.Session, bool)      { return nil, false }
+func (noSessionManager) GetAnyByHouse(int) (*plazaUtils.Session, bool) { return nil, false }
+
+func TestBattleConfigSyncAndLinkRecords(t *testing.T) {
+	ctx := context.Background()
+	store := &memBattleConfigs{rows: map[int32]*model.GameBattleConfig{}}
+	uc := NewBattleConfigUseCase(store, noSessionManager{}, log.DefaultLogger)
+
+	uc.OnSessionConfigs(ctx, 20001, []*plazaUtils.BattleConfig{
+		{ConfigID: 11, KindID: 301, BaseScore: 10, PlayCount: 8, PlayerCount: 4, Name: "十分场"},
+		{ConfigID: 12, KindID: 301, BaseScore: 20, PlayCount: 8, PlayerCount: 4},
+		{ConfigID: 13, KindID: 302, BaseScore: 5},
+		{ConfigID: 14, KindID: 302, BaseScore: 5},
+		{ConfigID: 0, KindID: 999}, // 无效玩法不落库
+		nil,
+	})
+	if len(store.rows) != 4 || store.rows[11].Name != "十分场" || store.rows[11].PlayerCount != 4 {
+		t.Fatalf("synced = %+v", store.rows)
+	}
+	// 游戏端删除 12、新增 15
+	uc.OnSessionConfigs(ctx, 20001, []*plazaUtils.BattleConfig{
+		{ConfigID: 11, KindID: 301, BaseScore: 10},
+		{ConfigID: 13, KindID: 302, BaseScore: 5},
+		{ConfigID: 14, KindID: 302, BaseScore: 5},
+		{ConfigID: 15, KindID: 301, BaseScore: 30},
+		{ConfigID: 16, KindID: 301, BaseScore: 10},
+	})
+	if !store.rows[12].Removed || store.rows[15].Removed {
+		t.Fatalf("removed flags: 12=%v 15=%v", store.rows[12].Removed, store.rows[15].Removed)
+	}
+
+	preset := int32(99)
+	records := []*model.GameBattleRecord{
+		{KindID: 301, BaseScore: 30},                    // 唯一现存玩法
+		{KindID: 301, BaseScore: 20},                    // 只剩已删除的 12（战绩可能晚于删除入库）
+		{KindID: 302, BaseScore: 5},                     // 两个玩法同规则：不关联
+		{KindID: 301, BaseScore: 10},                    // 11、16 同规则：不关联
+		{KindID: 303, BaseScore: 1},                     // 无匹配
+		{KindID: 301, BaseScore: 30, ConfigID: &preset}, // 已关联的不改
+	}
+	uc.LinkRecords(ctx, 20001, records)
+	want := []int32{15, 12, 0, 0, 0, 99}
+	for i, r := range records {
+		got := int32(0)
+		if r.ConfigID != nil {
+			got = *r.ConfigID
+		}
+		if got != want[i] {
+			t.Errorf("record %d config_id = %d, want %d", i, got, want[i])
+		}
+	}
+}
+
+func TestBattleConfigCommandsNeedValidInputAndSession(t *testing.T) {
+	ctx := context.Background()
+	uc := NewBattleConfigUseCase(&memBattleConfigs{rows: map[int32]*model.GameBattleConfig{}}, noSessionManager{}, log.DefaultLogger)
+
+	if err := uc.Append(ctx, 1, 20001, BattleConfigInput{KindID: 0, PlayCount: 8, PlayerCount: 4}); err == nil || err.Error() != "invalid kind_id" {
+		t.Fatalf("invalid kind err = %v", err)
+	}
+	if err := uc.Modify(ctx, 1, 20001, BattleConfigInput{KindID: 301, PlayCount: 8, PlayerCount: 4}); err == nil || err.Error() != "invalid config_id" {
+		t.Fatalf("modify without id err = %v", err)
+	}
+	if err := uc.Append(ctx, 1, 20001, BattleConfigInput{KindID: 301, BaseScore: 10, PlayCount: 8, PlayerCount: 4}); err == nil || err.Error() != "no online session" {
+		t.Fatalf("offline append err = %v", err)
+	}
+	if err := uc.Delete(ctx, 1, 20001, 11); err == nil || err.Error() != "no online session" {
+		t.Fatalf("offline delete err = %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/pkg/plugin/eventx"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
@@ -23,6 +24,8 @@ type BattleRecordUseCase struct {
 	memberRepo   repo.GameMemberRepo
 	settingsRepo repo.HouseSettingsRepo
 	feeRepo      repo.FeeSettleRepo
+	leaderboard  *LeaderboardUseCase
+	configs      *BattleConfigUseCase
 	log          *log.Helper
 }
 
@@ -34,6 +37,8 @@ func NewBattleRecordUseCase(
 	memberRepo repo.GameMemberRepo,
 	settingsRepo repo.HouseSettingsRepo,
 	feeRepo repo.FeeSettleRepo,
+	leaderboard *LeaderboardUseCase,
+	configs *BattleConfigUseCase,
 	logger log.Logger,
 ) *BattleRecordUseCase {
 	return &BattleRecordUseCase{
@@ -44,13 +49,15 @@ func NewBattleRecordUseCase(
 		memberRepo:   memberRepo,
 		settingsRepo: settingsRepo,
 		feeRepo:      feeRepo,
+		leaderboard:  leaderboard,
+		configs:      configs,
 		log:          log.NewHelper(log.With(logger, "module", "usecase/battle_record")),
 	}
 }
 
 // PullAndSave 拉取 foxuc 战绩并入库
-func (uc *BattleRecordUseCase) PullAndSave(ctx context.Context, httpc plazaHTTP.HTTPDoer, base string, houseGID, groupID, typeid int) (int, error) {
-	list, err := plazaHTTP.GetGroupBattleInfoCtx(ctx, httpc, base, groupID, typeid)
+func (uc *BattleRecordUseCase) PullAndSave(ctx context.Context, httpc plazaHTTP.HTTPDoer, gro plazaHTTP.GroEndpoint, houseGID, groupID, typeid int) (int, error) {
+	list, err := plazaHTTP.GetGroupBattleInfoCtx(ctx, httpc, gro, groupID, typeid)
 	if err != nil {
 		return 0, err
 	}
@@ -149,9 +156,12 @@ func (uc *BattleRecordUseCase) PullAndSave(ctx context.Context, httpc plazaHTTP.
 		return 0, nil
 	}
 
+	uc.configs.LinkRecords(ctx, int32(houseGID), batch)
 	if err := uc.repo.SaveBatch(ctx, batch); err != nil {
 		return 0, fmt.Errorf("保存战绩失败: %w", err)
 	}
+	uc.leaderboard.OnBattlesIngested(ctx, int32(houseGID))
+	eventx.Publish(ctx, int32(houseGID), eventx.TypeBattleIngested, map[string]any{"group_id": groupID, "records": len(batch), "battles": len(list)})
 
 	uc.log.Infof("Successfully saved %d battle records for house %d (processed %d battles)", len(batch), houseGID, len(list))
 	return len(batch), nil
@@ -0,0 +1,159 @@
+package game
+
+import (
+	gameVO "battle-tiles/internal/dal/vo/game"
+	"battle-tiles/internal/infra/plaza"
+	plazautils "battle-tiles/internal/utils/plaza"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// 会话分页查询的页大小与单次同步的最大页数
+	sessionBattlePageSize = 50
+	sessionBattleMaxPages = 40
+)
+
+// BattleSource 战绩来源：返回 since 之后结算的对局（来源可能多给，入库时去重）
+type BattleSource interface {
+	Name() string
+	Fetch(ctx context.Context, houseGID int, since time.Time) (*BattleFetch, error)
+}
+
+// BattleFetch 一次拉取的结果；CoveredFrom 为本次确实完整覆盖的起点，晚于 since 说明中间有缺口
+type BattleFetch struct {
+	Battles     []*gameVO.BattleInfo
+	CoveredFrom time.Time
+}
+
+// battleGap 已覆盖处与本次覆盖起点之间未拉到的区间
+type battleGap struct {
+	From, To time.Time
+}
+
+func (g *battleGap) String() string {
+	return fmt.Sprintf("gap %s ~ %s", g.From.Format(time.DateTime), g.To.Format(time.DateTime))
+}
+
+// detectBattleGap coveredUntil 之前已完整覆盖；本次从 coveredFrom 开始，两者不衔接即为缺口
+func detectBattleGap(coveredUntil, coveredFrom time.Time) *battleGap {
+	if !coveredUntil.Before(coveredFrom) {
+		return nil
+	}
+	return &battleGap{From: coveredUntil, To: coveredFrom}
+}
+
+// oldestBattle 最早一局的结算时间；无数据返回零值
+func oldestBattle(battles []*gameVO.BattleInfo) time.Time {
+	var oldest time.Time
+	for _, b := range battles {
+		t := time.Unix(int64(b.CreateTime), 0)
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// battleRecordPager 会话的约战记录分页查询（由 *plaza.Session 实现）
+type battleRecordPager interface {
+	QueryBattleRecords(ctx context.Context, start, end time.Time, pageIndex, pageSize int) (*plazautils.BattleRecordPage, error)
+}
+
+// sessionBattleSource 通过中控会话（SUB_GP_QUERY_RECORD_LUA）分页查询，无条数与时间窗限制
+type sessionBattleSource struct {
+	session func(houseGID int) (battleRecordPager, bool)
+}
+
+func newSessionBattleSource(mgr plaza.Manager, userID int) BattleSource {
+	return &sessionBattleSource{session: func(houseGID int) (battleRecordPager, bool) {
+		sess, ok := mgr.Get(userID, houseGID)
+		if !ok || sess == nil {
+			return nil, false
+		}
+		return sess, true
+	}}
+}
+
+func (s *sessionBattleSource) Name() string { return "session" }
+
+func (s *sessionBattleSource) Fetch(ctx context.Context, houseGID int, since time.Time) (*BattleFetch, error) {
+	sess, ok := s.session(houseGID)
+	if !ok {
+		return nil, errors.New("no online session")
+	}
+	end := time.Now()
+	var out []*gameVO.BattleInfo
+	for page := 0; page < sessionBattleMaxPages; page++ {
+		p, err := sess.QueryBattleRecords(ctx, since, end, page, sessionBattlePageSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "page %d", page)
+		}
+		out = append(out, p.Records...)
+		if p.LastPage() {
+			return &BattleFetch{Battles: out, CoveredFrom: since}, nil
+		}
+	}
+	// 页数用尽：按时间倒序返回，只有最早一局之后是完整的
+	return &BattleFetch{Battles: out, CoveredFrom: oldestBattle(out)}, nil
+}
+
+// httpBattleSource GroService.ashx：只有固定时间窗（3分钟/30分钟/1小时），单次最多 50 条
+type httpBattleSource struct {
+	gro *GroResolver
+}
+
+func newHTTPBattleSource(gro *GroResolver) BattleSource {
+	return &httpBattleSource{gro: gro}
+}
+
+func (s *httpBattleSource) Name() string { return "http" }
+
+func (s *httpBattleSource) Fetch(ctx context.Context, houseGID int, since time.Time) (*BattleFetch, error) {
+	// typeid: 0 最近3分钟 / 1 最近30分钟 / 2 最近1小时；超过 1 小时的缺口只能补到 1 小时
+	now := time.Now()
+	typeid, window := 0, 3*time.Minute
+	switch d := now.Sub(since); {
+	case d > 30*time.Minute:
+		typeid, window = 2, time.Hour
+	case d > 3*time.Minute:
+		typeid, window = 1, 30*time.Minute
+	}
+	battles, err := plazautils.GetGroupNewBattleInfoCtx(ctx, s.gro.Doer(), s.gro.Battle(ctx), houseGID, typeid)
+	if err != nil {
+		return nil, err
+	}
+	covered := now.Add(-window)
+	// 满 50 条说明被截断，只有最早一局之后是完整的
+	if len(battles) >= plazautils.HTTPBattlePageSize {
+		if oldest := oldestBattle(battles); oldest.After(covered) {
+			covered = oldest
+		}
+	}
+	return &BattleFetch{Battles: battles, CoveredFrom: covered}, nil
+}
+
+// backfillTypeIDs getgroupbattleinfo 的 typeid（0 今日 / 1 昨日 / 2 本周）：选能覆盖 [start, end) 的最小集合
+func backfillTypeIDs(now, start, end time.Time) ([]int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	weekday := int(today.Weekday()+6) % 7 // 周一为 0
+	monday := today.AddDate(0, 0, -weekday)
+	switch {
+	case !end.After(start):
+		return nil, errors.New("empty range")
+	case start.Before(monday) && start.Before(yesterday):
+		return nil, errors.Errorf("range before %s is no longer available", monday.Format(time.DateOnly))
+	case !start.Before(today):
+		return []int{0}, nil
+	case !start.Before(yesterday) && !end.After(today):
+		return []int{1}, nil
+	case !start.Before(yesterday):
+		return []int{1, 0}, nil
+	default:
+		return []int{2}, nil
+	}
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	gameVO "battle-tiles/internal/dal/vo/game"
+	plazautils "battle-tiles/internal/utils/plaza"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestBackfillTypeIDs(t *testing.T) {
+	now := time.Date(2026, 10, 22, 15, 0, 0, 0, time.Local) // 周四
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.Local) }
+	cases := []struct {
+		start, end time.Time
+		want       []int
+	}{
+		{day(22), day(23), []int{0}},
+		{day(21), day(22), []int{1}},
+		{day(21), day(23), []int{1, 0}},
+		{day(19), day(23), []int{2}},
+	}
+	for _, c := range cases {
+		got, err := backfillTypeIDs(now, c.start, c.end)
+		if err != nil || len(got) != len(c.want) {
+			t.Fatalf("%s~%s: got %v err %v, want %v", c.start, c.end, got, err, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s~%s: got %v, want %v", c.start, c.end, got, c.want)
+			}
+		}
+	}
+	if _, err := backfillTypeIDs(now, day(18), day(20)); err == nil {
+		t.Fatal("range before monday should be rejected")
+	}
+}
+
+func TestDetectBattleGap(t *testing.T) {
+	t0 := time.Date(2026, 10, 22, 15, 0, 0, 0, time.Local)
+	if g := detectBattleGap(t0, t0.Add(-time.Minute)); g != nil {
+		t.Fatalf("overlapping coverage reported gap %s", g)
+	}
+	g := detectBattleGap(t0, t0.Add(2*time.Hour))
+	if g == nil || !g.From.Equal(t0) || !g.To.Equal(t0.Add(2*time.Hour)) {
+		t.Fatalf("gap = %v", g)
+	}
+}
+
+// pagedRecords 按页返回约战记录，每页一局，结算时间逐页向前
+type pagedRecords struct {
+	pages int
+	asked []int
+}
+
+func (p *pagedRecords) QueryBattleRecords(_ context.Context, start, _ time.Time, pageIndex, pageSize int) (*plazautils.BattleRecordPage, error) {
+	p.asked = append(p.asked, pageIndex)
+	created := start.Add(time.Duration(p.pages-pageIndex) * time.Minute)
+	return &plazautils.BattleRecordPage{
+		PageIndex: uint16(pageIndex),
+		PageCount: uint16(p.pages),
+		Records:   []*gameVO.BattleInfo{{RoomID: 1000 + pageIndex, CreateTime: int(created.Unix())}},
+	}, nil
+}
+
+// stubBattleSource 固定结果的战绩来源
+type stubBattleSource struct {
+	name    string
+	fetched *BattleFetch
+	err     error
+	calls   int
+}
+
+func (s *stubBattleSource) Name() string { return s.name }
+
+func (s *stubBattleSource) Fetch(context.Context, int, time.Time) (*BattleFetch, error) {
+	s.calls++
+	return s.fetched, s.err
+}
+
+func TestSessionBattleSourcePagesAndFallsBack(t *testing.T) {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour).Truncate(time.Second)
+	pager := &pagedRecords{pages: 3}
+	online := &sessionBattleSource{session: func(int) (battleRecordPager, bool) { return pager, true }}
+
+	got, err := online.Fetch(ctx, 20001, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Battles) != 3 || len(pager.asked) != 3 || pager.asked[2] != 2 || !got.CoveredFrom.Equal(since) {
+		t.Fatalf("fetched %d battles, asked pages %v, covered from %s", len(got.Battles), pager.asked, got.CoveredFrom)
+	}
+
+	// 页数用尽：只认最早一局之后的区间
+	pager = &pagedRecords{pages: sessionBattleMaxPages + 5}
+	got, err = online.Fetch(ctx, 20001, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Battles) != sessionBattleMaxPages || !got.CoveredFrom.Equal(since.Add(time.Duration(pager.pages-sessionBattleMaxPages+1)*time.Minute)) {
+		t.Fatalf("truncated fetch: %d battles, covered from %s", len(got.Battles), got.CoveredFrom)
+	}
+
+	// 会话不在线时回退 HTTP
+	offline := &sessionBattleSource{session: func(int) (battleRecordPager, bool) { return nil, false }}
+	fallback := &stubBattleSource{name: "http", fetched: &BattleFetch{CoveredFrom: since}}
+	syncer := &battleSyncer{houseGID: 20001, sources: []BattleSource{offline, fallback}, logger: log.NewHelper(log.DefaultLogger)}
+	fetched, source, err := syncer.fetch(ctx, since)
+	if err != nil || source != "http" || fetched != fallback.fetched {
+		t.Fatalf("fallback: source=%q err=%v", source, err)
+	}
+
+	fallback.err, fallback.fetched = errors.New("503"), nil
+	if _, _, err = syncer.fetch(ctx, since); err == nil || !strings.Contains(err.Error(), "session: no online session") || !strings.Contains(err.Error(), "http: 503") {
+		t.Fatalf("all sources failed err = %v", err)
+	}
+}
@@ -2,17 +2,21 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	model "battle-tiles/internal/dal/model/game"
 	repo "battle-tiles/internal/dal/repo/game"
+	gameVO "battle-tiles/internal/dal/vo/game"
 	"battle-tiles/internal/infra"
-	plazautils "battle-tiles/internal/utils/plaza"
+	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/eventx"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
 )
 
 // BattleSyncManager 管理所有会话的战绩同步
@@ -21,15 +25,34 @@ type BattleSyncManager struct {
 	syncers map[string]*battleSyncer // key: "userID:houseGID"
 	repo    repo.BattleRecordRepo
 	data    *infra.Data // 用于记录同步日志
+	rank    *LeaderboardUseCase
+	configs *BattleConfigUseCase // 战绩关联玩法
+	mgr     plaza.Manager        // 会话战绩来源
+	marks   repo.BattleWatermarkRepo
+	gro     *GroResolver // HTTP 战绩来源地址
 	logger  *log.Helper
 }
 
+const (
+	// 首次同步回看的时长
+	battleSyncLookback = time.Hour
+	// 有高水位时（如重启后）最多回看的时长，更早的缺口需手动回填
+	battleSyncMaxLookback = 24 * time.Hour
+	// 增量同步与上次成功时间的重叠，覆盖结算入库的延迟（重复由入库去重处理）
+	battleSyncOverlap = time.Minute
+)
+
 // NewBattleSyncManager 创建战绩同步管理器
-func NewBattleSyncManager(battleRepo repo.BattleRecordRepo, data *infra.Data, logger log.Logger) *BattleSyncManager {
+func NewBattleSyncManager(battleRepo repo.BattleRecordRepo, data *infra.Data, rank *LeaderboardUseCase, configs *BattleConfigUseCase, mgr plaza.Manager, marks repo.BattleWatermarkRepo, gro *GroResolver, logger log.Logger) *BattleSyncManager {
 	return &BattleSyncManager{
 		syncers: make(map[string]*battleSyncer),
 		repo:    battleRepo,
 		data:    data,
+		rank:    rank,
+		configs: configs,
+		mgr:     mgr,
+		marks:   marks,
+		gro:     gro,
 		logger:  log.NewHelper(logger),
 	}
 }
@@ -48,7 +71,9 @@ func (m *BattleSyncManager) StartSync(ctx context.Context, userID int, houseGID
 	}
 
 	// 创建新的同步器，传入带 platform 的 context
-	syncer := newBattleSyncer(ctx, userID, houseGID, m.repo, m.data, m.logger)
+	// 会话查询优先，失败回退 GroService HTTP
+	sources := []BattleSource{newSessionBattleSource(m.mgr, userID), newHTTPBattleSource(m.gro)}
+	syncer := m.newSyncer(ctx, userID, houseGID, sources)
 	m.syncers[key] = syncer
 	syncer.start()
 
@@ -82,6 +107,10 @@ func (m *BattleSyncManager) StopAll() {
 	m.logger.Info("Stopped all battle syncers")
 }
 
+func (m *BattleSyncManager) newSyncer(ctx context.Context, userID int, houseGID int, sources []BattleSource) *battleSyncer {
+	return newBattleSyncer(ctx, userID, houseGID, m.repo, m.data, m.rank, m.configs, m.marks, sources, m.logger)
+}
+
 // battleSyncer 单个会话的战绩同步器
 type battleSyncer struct {
 	ctx          context.Context // 保存带 platform 的 context
@@ -89,30 +118,37 @@ type battleSyncer struct {
 	houseGID     int
 	battleRepo   repo.BattleRecordRepo
 	data         *infra.Data // 用于记录同步日志
+	rank         *LeaderboardUseCase
+	configs      *BattleConfigUseCase
+	marks        repo.BattleWatermarkRepo
+	sources      []BattleSource // 按顺序尝试，第一个成功的为准
 	logger       *log.Helper
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
 	syncInterval time.Duration
-	isFirstSync  bool
-	sessionID    int32 // 会话 ID，用于记录同步日志
+	lastSyncAt   time.Time // 上次成功同步的开始时间，零值表示首次
+	sessionID    int32     // 会话 ID，用于记录同步日志
 }
 
-func newBattleSyncer(ctx context.Context, userID int, houseGID int, battleRepo repo.BattleRecordRepo, data *infra.Data, logger *log.Helper) *battleSyncer {
+func newBattleSyncer(ctx context.Context, userID int, houseGID int, battleRepo repo.BattleRecordRepo, data *infra.Data, rank *LeaderboardUseCase, configs *BattleConfigUseCase, marks repo.BattleWatermarkRepo, sources []BattleSource, logger *log.Helper) *battleSyncer {
 	return &battleSyncer{
 		ctx:          ctx, // 保存 context
 		userID:       userID,
 		houseGID:     houseGID,
 		battleRepo:   battleRepo,
 		data:         data,
+		rank:         rank,
+		configs:      configs,
+		marks:        marks,
+		sources:      sources,
 		logger:       logger,
 		stopChan:     make(chan struct{}),
 		syncInterval: 10 * time.Second, // 改为10秒一次
-		isFirstSync:  true,
 	}
 }
 
 func (s *battleSyncer) start() {
-	s.logger.Infof("Starting battle syncer for house %d, isFirstSync=%v", s.houseGID, s.isFirstSync)
+	s.logger.Infof("Starting battle syncer for house %d", s.houseGID)
 	s.wg.Add(1)
 	go s.syncLoop()
 }
@@ -146,51 +182,91 @@ func (s *battleSyncer) syncOnce() {
 	ctx := s.ctx
 	startTime := time.Now()
 
-	// 获取 session_id（用于记录同步日志）
-	if s.sessionID == 0 {
-		var session model.GameSession
-		err := s.data.GetDBWithContext(ctx).
-			Where("user_id = ? AND house_gid = ?", s.userID, s.houseGID).
-			Order("created_at DESC").
-			First(&session).Error
-		if err == nil {
-			s.sessionID = session.Id
+	s.loadSessionID(ctx)
+
+	// 首次回看 1 小时（有高水位时从其覆盖处继续，最多 24 小时），之后从上次成功同步处（含重叠）继续
+	mark := s.loadWatermark(ctx)
+	since := startTime.Add(-battleSyncLookback)
+	if !s.lastSyncAt.IsZero() {
+		since = s.lastSyncAt.Add(-battleSyncOverlap)
+	} else if mark != nil && mark.CoveredUntil != nil && mark.CoveredUntil.Before(since) {
+		since = mark.CoveredUntil.Add(-battleSyncOverlap)
+		if floor := startTime.Add(-battleSyncMaxLookback); since.Before(floor) {
+			since = floor
 		}
 	}
 
-	// 第一次同步获取最近1小时的数据，之后获取最近3分钟的数据
-	typeid := 0 // 最近3分钟
-	typeDesc := "last 3 minutes"
-	if s.isFirstSync {
-		typeid = 2 // 最近1小时
-		typeDesc = "last 1 hour"
-		s.isFirstSync = false
+	fetched, source, err := s.fetch(ctx, since)
+	if err != nil {
+		s.logger.Errorf("Failed to fetch battle info for house %d: %v", s.houseGID, err)
+		// 记录失败日志
+		s.recordSyncLog(ctx, startTime, 0, model.SyncStatusFailed, err.Error())
+		return
 	}
+	battles := fetched.Battles
 
-	s.logger.Infof("Fetching battle records for house %d (%s)...", s.houseGID, typeDesc)
+	s.logger.Infof("Fetched %d battle records from %s for house %d", len(battles), source, s.houseGID)
 
-	// 从 plaza 获取战绩数据
-	// 使用现有的 HTTP API 函数
-	httpClient := &http.Client{Timeout: 10 * time.Second}
-	baseURL := "http://phone2.foxuc.com/Ashx/GroService.ashx"
-	battles, err := plazautils.GetGroupNewBattleInfoCtx(ctx, httpClient, baseURL, s.houseGID, typeid)
+	saved, err := s.ingest(ctx, battles)
 	if err != nil {
-		s.logger.Errorf("Failed to fetch battle info for house %d: %v", s.houseGID, err)
+		s.logger.Errorf("Failed to save battle records for house %d: %v", s.houseGID, err)
 		// 记录失败日志
 		s.recordSyncLog(ctx, startTime, 0, model.SyncStatusFailed, err.Error())
 		return
 	}
 
-	s.logger.Infof("Fetched %d battle records from plaza for house %d", len(battles), s.houseGID)
+	// 来源实际覆盖晚于已覆盖处：中间的战绩没有拉到，记缺口等待回填
+	if mark != nil && mark.CoveredUntil != nil {
+		if gap := detectBattleGap(*mark.CoveredUntil, fetched.CoveredFrom); gap != nil {
+			s.logger.Warnf("battle gap for house %d: %s (source %s)", s.houseGID, gap, source)
+			s.recordSyncLog(ctx, startTime, int32(saved), model.SyncStatusPartial, gap.String())
+		}
+	}
+	s.lastSyncAt = startTime
+	covered := startTime
+	if err := s.marks.Advance(ctx, int32(s.houseGID), 0, 0, &covered); err != nil {
+		s.logger.Warnf("advance battle watermark for house %d failed: %v", s.houseGID, err)
+	}
 
-	if len(battles) == 0 {
-		// 记录成功日志（0条记录）
-		s.recordSyncLog(ctx, startTime, 0, model.SyncStatusSuccess, "")
+	// 记录成功日志
+	s.recordSyncLog(ctx, startTime, int32(saved), model.SyncStatusSuccess, "")
+}
+
+// loadSessionID 获取 session_id（用于记录同步日志）；userID 为 0 时取该店铺最近的会话
+func (s *battleSyncer) loadSessionID(ctx context.Context) {
+	if s.sessionID != 0 {
 		return
 	}
+	q := s.data.GetDBWithContext(ctx).Where("house_gid = ?", s.houseGID)
+	if s.userID != 0 {
+		q = q.Where("user_id = ?", s.userID)
+	}
+	var session model.GameSession
+	if err := q.Order("created_at DESC").First(&session).Error; err == nil {
+		s.sessionID = session.Id
+	}
+}
+
+func (s *battleSyncer) loadWatermark(ctx context.Context) *model.GameBattleWatermark {
+	mark, err := s.marks.Get(ctx, int32(s.houseGID))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warnf("load battle watermark for house %d failed: %v", s.houseGID, err)
+		}
+		return nil
+	}
+	return mark
+}
+
+// ingest 校验、转换并去重入库，推进最新一局高水位；返回新增条数
+func (s *battleSyncer) ingest(ctx context.Context, battles []*gameVO.BattleInfo) (int, error) {
+	if len(battles) == 0 {
+		return 0, nil
+	}
 
 	// 转换为数据库模型
 	records := make([]*model.GameBattleRecord, 0)
+	var last *gameVO.BattleInfo
 	for _, bi := range battles {
 		// 验证数据完整性：零和游戏的总分应该为0
 		totalScore := 0
@@ -201,6 +277,9 @@ func (s *battleSyncer) syncOnce() {
 			s.logger.Warnf("Invalid battle data: room %d, total score %d != 0", bi.RoomID, totalScore)
 			continue
 		}
+		if last == nil || bi.CreateTime > last.CreateTime || (bi.CreateTime == last.CreateTime && bi.RoomID > last.RoomID) {
+			last = bi
+		}
 
 		// 为每个玩家创建一条记录
 		for _, player := range bi.Players {
@@ -224,21 +303,39 @@ func (s *battleSyncer) syncOnce() {
 		}
 	}
 
+	s.configs.LinkRecords(ctx, int32(s.houseGID), records)
+
 	// 批量保存到数据库（带去重）
 	saved, err := s.battleRepo.SaveBatchWithDedup(ctx, records)
 	if err != nil {
-		s.logger.Errorf("Failed to save battle records for house %d: %v", s.houseGID, err)
-		// 记录失败日志
-		s.recordSyncLog(ctx, startTime, 0, model.SyncStatusFailed, err.Error())
-		return
+		return 0, err
+	}
+	if last != nil {
+		if err := s.marks.Advance(ctx, int32(s.houseGID), int64(last.CreateTime), int32(last.RoomID), nil); err != nil {
+			s.logger.Warnf("advance battle watermark for house %d failed: %v", s.houseGID, err)
+		}
 	}
 
 	if saved > 0 {
 		s.logger.Infof("Synced %d battle records for house %d", saved, s.houseGID)
+		s.rank.OnBattlesIngested(ctx, int32(s.houseGID))
+		eventx.Publish(ctx, int32(s.houseGID), eventx.TypeBattleIngested, map[string]any{"records": saved, "battles": len(battles)})
 	}
+	return saved, nil
+}
 
-	// 记录成功日志
-	s.recordSyncLog(ctx, startTime, int32(saved), model.SyncStatusSuccess, "")
+// fetch 依次尝试各来源，返回第一个成功的结果与来源名；全部失败时合并错误
+func (s *battleSyncer) fetch(ctx context.Context, since time.Time) (*BattleFetch, string, error) {
+	errs := make([]string, 0, len(s.sources))
+	for _, src := range s.sources {
+		fetched, err := src.Fetch(ctx, s.houseGID, since)
+		if err == nil {
+			return fetched, src.Name(), nil
+		}
+		s.logger.Warnf("battle source %s failed for house %d: %v", src.Name(), s.houseGID, err)
+		errs = append(errs, src.Name()+": "+err.Error())
+	}
+	return nil, "", fmt.Errorf("all battle sources failed: %s", strings.Join(errs, "; "))
 }
 
 // recordSyncLog 记录同步日志
@@ -247,11 +344,15 @@ func (s *battleSyncer) recordSyncLog(ctx context.Context, startTime time.Time, r
 	if s.sessionID == 0 || status == "success" {
 		return
 	}
+	s.writeSyncLog(ctx, model.SyncTypeBattleRecord, startTime, recordsSynced, status, errorMsg)
+}
 
+// writeSyncLog 写入一条同步日志（不检查 sessionID）
+func (s *battleSyncer) writeSyncLog(ctx context.Context, syncType string, startTime time.Time, recordsSynced int32, status string, errorMsg string) {
 	completedAt := time.Now()
 	syncLog := &model.GameSyncLog{
 		SessionID:     s.sessionID,
-		SyncType:      model.SyncTypeBattleRecord,
+		SyncType:      syncType,
 		Status:        status,
 		RecordsSynced: recordsSynced,
 		ErrorMessage:  errorMsg,
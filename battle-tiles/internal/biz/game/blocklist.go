@@ -0,0 +1,233 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// 落座检查使用的游戏ID缓存有效期；增删时立即失效
+const blocklistCacheTTL = time.Minute
+
+var (
+	// ErrBlocklisted 命中平台黑名单
+	ErrBlocklisted = errors.New("blocklisted")
+	// ErrBlocklistReportedByOther 同一标识已由其他店铺上报
+	ErrBlocklistReportedByOther = errors.New("already reported by another house")
+)
+
+// BlocklistSubject 一次检查涉及的标识，零值不参与匹配
+type BlocklistSubject struct {
+	GameID int32
+	UserID int32
+	Device string
+	IP     string
+}
+
+func (s BlocklistSubject) keys() []repo.BlocklistKey {
+	out := make([]repo.BlocklistKey, 0, 4)
+	if s.GameID > 0 {
+		out = append(out, repo.BlocklistKey{Kind: model.BlocklistKindGameID, Value: strconv.Itoa(int(s.GameID))})
+	}
+	if s.UserID > 0 {
+		out = append(out, repo.BlocklistKey{Kind: model.BlocklistKindUser, Value: strconv.Itoa(int(s.UserID))})
+	}
+	if v := strings.TrimSpace(s.Device); v != "" {
+		out = append(out, repo.BlocklistKey{Kind: model.BlocklistKindDevice, Value: v})
+	}
+	if v := strings.TrimSpace(s.IP); v != "" {
+		out = append(out, repo.BlocklistKey{Kind: model.BlocklistKindIP, Value: v})
+	}
+	return out
+}
+
+// BlocklistInput 新增/覆盖黑名单入参
+type BlocklistInput struct {
+	Kind             string
+	Value            string
+	Reason           string
+	ReportedHouseGID int32
+	ExpireAt         *time.Time
+}
+
+type blocklistGameIDs struct {
+	at  time.Time
+	ids map[int32]struct{}
+}
+
+// BlocklistUseCase 平台级黑名单：游戏ID/平台用户/设备/IP，跨店铺生效
+type BlocklistUseCase struct {
+	repo repo.BlocklistRepo
+	log  *log.Helper
+
+	mu    sync.Mutex
+	cache map[string]*blocklistGameIDs // platform → 未过期的游戏ID
+}
+
+func NewBlocklistUseCase(r repo.BlocklistRepo, logger log.Logger) *BlocklistUseCase {
+	return &BlocklistUseCase{
+		repo:  r,
+		log:   log.NewHelper(log.With(logger, "module", "usecase/blocklist")),
+		cache: make(map[string]*blocklistGameIDs),
+	}
+}
+
+func normalizeBlocklistInput(in *BlocklistInput) error {
+	in.Value = strings.TrimSpace(in.Value)
+	in.Reason = strings.TrimSpace(in.Reason)
+	if in.Value == "" {
+		return errors.New("value required")
+	}
+	switch in.Kind {
+	case model.BlocklistKindGameID, model.BlocklistKindUser:
+		n, err := strconv.Atoi(in.Value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%s must be a positive number", in.Kind)
+		}
+		in.Value = strconv.Itoa(n)
+	case model.BlocklistKindDevice, model.BlocklistKindIP:
+	default:
+		return fmt.Errorf("unknown kind: %s", in.Kind)
+	}
+	if in.ExpireAt != nil && !in.ExpireAt.After(time.Now()) {
+		return errors.New("expire_at must be in the future")
+	}
+	return nil
+}
+
+// Add 平台添加：新增或更新原因/过期时间（同一 kind+value 以最后一次为准），不改变上报店铺
+func (uc *BlocklistUseCase) Add(ctx context.Context, opUser int32, in BlocklistInput) (*model.GameBlocklist, error) {
+	return uc.upsert(ctx, opUser, in, false)
+}
+
+// Report 店铺上报：只能新增或更新本店上报的记录，他店已上报的返回 ErrBlocklistReportedByOther
+func (uc *BlocklistUseCase) Report(ctx context.Context, opUser int32, in BlocklistInput) (*model.GameBlocklist, error) {
+	if in.ReportedHouseGID <= 0 {
+		return nil, errors.New("house_gid required")
+	}
+	return uc.upsert(ctx, opUser, in, true)
+}
+
+func (uc *BlocklistUseCase) upsert(ctx context.Context, opUser int32, in BlocklistInput, ownerOnly bool) (*model.GameBlocklist, error) {
+	if err := normalizeBlocklistInput(&in); err != nil {
+		return nil, err
+	}
+	m := &model.GameBlocklist{
+		Kind:             in.Kind,
+		Value:            in.Value,
+		Reason:           in.Reason,
+		ReportedHouseGID: in.ReportedHouseGID,
+		ExpireAt:         in.ExpireAt,
+		CreatedBy:        opUser,
+	}
+	ok, err := uc.repo.Upsert(ctx, m, ownerOnly)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrBlocklistReportedByOther
+	}
+	uc.invalidate(ctx)
+	uc.log.Infof("blocklist add kind=%s value=%s house=%d by=%d", m.Kind, m.Value, m.ReportedHouseGID, opUser)
+	return m, nil
+}
+
+// Remove 删除；houseGID>0 时只能删除本店上报的记录
+func (uc *BlocklistUseCase) Remove(ctx context.Context, houseGID, id int32) error {
+	if houseGID > 0 {
+		m, err := uc.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if m.ReportedHouseGID != houseGID {
+			return errors.New("entry not reported by this house")
+		}
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.invalidate(ctx)
+	return nil
+}
+
+func (uc *BlocklistUseCase) List(ctx context.Context, f repo.BlocklistFilter, page, size int32) ([]*model.GameBlocklist, int64, error) {
+	return uc.repo.List(ctx, f, page, size)
+}
+
+// Check 返回命中的第一条记录，未命中返回 nil
+func (uc *BlocklistUseCase) Check(ctx context.Context, s BlocklistSubject) (*model.GameBlocklist, error) {
+	m, err := uc.repo.FindActive(ctx, s.keys(), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return m, err
+}
+
+// Guard 命中时返回 ErrBlocklisted（带原因）；查询失败放行并记录日志，不因黑名单故障阻断业务
+func (uc *BlocklistUseCase) Guard(ctx context.Context, s BlocklistSubject) error {
+	m, err := uc.Check(ctx, s)
+	if err != nil {
+		uc.log.Warnf("blocklist check failed: %v", err)
+		return nil
+	}
+	if m == nil {
+		return nil
+	}
+	return errors.Wrap(ErrBlocklisted, blocklistReason(m))
+}
+
+// blocklistReason 命中说明，如 "game_id 123456: 恶意拖欠"
+func blocklistReason(m *model.GameBlocklist) string {
+	if m.Reason != "" {
+		return fmt.Sprintf("%s %s: %s", m.Kind, m.Value, m.Reason)
+	}
+	return fmt.Sprintf("%s %s", m.Kind, m.Value)
+}
+
+// IsGameIDBlocked 落座等高频场景使用，按平台缓存未过期的游戏ID
+func (uc *BlocklistUseCase) IsGameIDBlocked(ctx context.Context, gameID int32) bool {
+	if gameID <= 0 {
+		return false
+	}
+	platform := pdb.GetDBKeyFromCtx(ctx)
+	uc.mu.Lock()
+	c := uc.cache[platform]
+	uc.mu.Unlock()
+	if c == nil || time.Since(c.at) > blocklistCacheTTL {
+		values, err := uc.repo.ListActiveValues(ctx, model.BlocklistKindGameID, time.Now())
+		if err != nil {
+			uc.log.Warnf("load blocklist game ids failed: %v", err)
+			if c == nil {
+				return false
+			}
+		} else {
+			c = &blocklistGameIDs{at: time.Now(), ids: make(map[int32]struct{}, len(values))}
+			for _, v := range values {
+				if n, err := strconv.Atoi(v); err == nil {
+					c.ids[int32(n)] = struct{}{}
+				}
+			}
+			uc.mu.Lock()
+			uc.cache[platform] = c
+			uc.mu.Unlock()
+		}
+	}
+	_, ok := c.ids[gameID]
+	return ok
+}
+
+func (uc *BlocklistUseCase) invalidate(ctx context.Context) {
+	uc.mu.Lock()
+	delete(uc.cache, pdb.GetDBKeyFromCtx(ctx))
+	uc.mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"battle-tiles/internal/consts"
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	gamevo "battle-tiles/internal/dal/vo/game"
+	"battle-tiles/internal/infra/plaza"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// memBlocklist 按 kind+value 唯一，语义同 blocklistRepo.Upsert
+type memBlocklist struct {
+	repo.BlocklistRepo
+	rows []*model.GameBlocklist
+}
+
+func (r *memBlocklist) Upsert(_ context.Context, m *model.GameBlocklist, ownerOnly bool) (bool, error) {
+	for _, e := range r.rows {
+		if e.Kind != m.Kind || e.Value != m.Value {
+			continue
+		}
+		if ownerOnly && e.ReportedHouseGID != m.ReportedHouseGID {
+			return false, nil
+		}
+		e.Reason, e.ExpireAt, e.CreatedBy = m.Reason, m.ExpireAt, m.CreatedBy
+		*m = *e
+		return true, nil
+	}
+	m.Id = int32(len(r.rows) + 1)
+	cp := *m
+	r.rows = append(r.rows, &cp)
+	return true, nil
+}
+
+func (r *memBlocklist) FindActive(_ context.Context, keys []repo.BlocklistKey, now time.Time) (*model.GameBlocklist, error) {
+	for _, e := range r.rows {
+		for _, k := range keys {
+			if e.Kind == k.Kind && e.Value == k.Value && (e.ExpireAt == nil || e.ExpireAt.After(now)) {
+				return e, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestBlocklistReportKeepsOwner(t *testing.T) {
+	ctx := context.Background()
+	store := &memBlocklist{}
+	uc := NewBlocklistUseCase(store, log.DefaultLogger)
+
+	if _, err := uc.Report(ctx, 1, BlocklistInput{Kind: model.BlocklistKindGameID, Value: " 0123 ", Reason: "拖欠", ReportedHouseGID: 100}); err != nil {
+		t.Fatal(err)
+	}
+	// 他店上报同一游戏ID：拒绝，不改原记录
+	if _, err := uc.Report(ctx, 2, BlocklistInput{Kind: model.BlocklistKindGameID, Value: "123", Reason: "other", ReportedHouseGID: 200}); !errors.Is(err, ErrBlocklistReportedByOther) {
+		t.Fatalf("takeover err = %v, want ErrBlocklistReportedByOther", err)
+	}
+	// 本店更新原因
+	if _, err := uc.Report(ctx, 1, BlocklistInput{Kind: model.BlocklistKindGameID, Value: "123", Reason: "拖欠不还", ReportedHouseGID: 100}); err != nil {
+		t.Fatal(err)
+	}
+	// 平台更新：原因更新，上报店铺不变
+	m, err := uc.Add(ctx, 9, BlocklistInput{Kind: model.BlocklistKindGameID, Value: "123", Reason: "平台确认"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.rows) != 1 || m.ReportedHouseGID != 100 || store.rows[0].Reason != "平台确认" {
+		t.Fatalf("rows = %+v, returned = %+v", store.rows, m)
+	}
+	if _, err := uc.Report(ctx, 1, BlocklistInput{Kind: model.BlocklistKindGameID, Value: "456"}); err == nil {
+		t.Fatal("report without house should fail")
+	}
+}
+
+type probeManager struct {
+	plaza.Manager
+	gameID uint32
+	probes int
+}
+
+func (m *probeManager) ProbeLoginWithInfo(context.Context, consts.GameLoginMode, string, string) (*gamevo.UserLogonInfo, error) {
+	m.probes++
+	return &gamevo.UserLogonInfo{UserID: 1, GameID: m.gameID}, nil
+}
+
+func TestBindSingleGuardsGameIDAndClient(t *testing.T) {
+	ctx := context.Background()
+	block := NewBlocklistUseCase(&memBlocklist{rows: []*model.GameBlocklist{
+		{Id: 1, Kind: model.BlocklistKindGameID, Value: "8001"},
+		{Id: 2, Kind: model.BlocklistKindIP, Value: "198.51.100.7"},
+	}}, log.DefaultLogger)
+	mgr := &probeManager{gameID: 8001}
+	// accRepo 为 nil：命中黑名单必须在写账号之前返回
+	uc := NewGameAccountUseCase(nil, nil, nil, nil, mgr, nil, block, log.DefaultLogger)
+
+	_, err := uc.BindSingle(ctx, 5, consts.GameLoginModeAccount, "acc", "pwd", "", BlocklistSubject{IP: "198.51.100.7"})
+	if !errors.Is(err, ErrBlocklisted) || mgr.probes != 0 {
+		t.Fatalf("blocked ip: err = %v, probes = %d", err, mgr.probes)
+	}
+	_, err = uc.BindSingle(ctx, 5, consts.GameLoginModeAccount, "acc", "pwd", "", BlocklistSubject{IP: "198.51.100.8"})
+	if !errors.Is(err, ErrBlocklisted) || mgr.probes != 1 {
+		t.Fatalf("blocked game id: err = %v, probes = %d", err, mgr.probes)
+	}
+}
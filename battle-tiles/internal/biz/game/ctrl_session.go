@@ -6,12 +6,16 @@ import (
 	repo "battle-tiles/internal/dal/repo/game"
 	"battle-tiles/internal/infra/plaza"
 	plazaUtils "battle-tiles/internal/utils/plaza"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
 	"context"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 )
 
@@ -24,6 +28,11 @@ type CtrlSessionUseCase struct {
 	sessRepo  repo.SessionRepo
 	mgr       plaza.Manager
 	syncMgr   *BattleSyncManager // 战绩同步管理器
+	apps      *ApplicationUseCase // 游戏内申请自动处理
+	block     *BlocklistUseCase   // 平台黑名单（落座即踢）
+	configs   *BattleConfigUseCase // 玩法快照落库
+	diamond   *DiamondUseCase      // 中控钻石余额
+	members   *MemberSyncUseCase   // 成员状态以游戏端为准回写
 	log       *log.Helper
 }
 
@@ -33,6 +42,11 @@ func NewCtrlSessionUseCase(
 	sess repo.SessionRepo,
 	mgr plaza.Manager,
 	syncMgr *BattleSyncManager,
+	apps *ApplicationUseCase,
+	block *BlocklistUseCase,
+	configs *BattleConfigUseCase,
+	diamond *DiamondUseCase,
+	members *MemberSyncUseCase,
 	logger log.Logger,
 ) *CtrlSessionUseCase {
 	uc := &CtrlSessionUseCase{
@@ -41,6 +55,11 @@ func NewCtrlSessionUseCase(
 		sessRepo: sess,
 		mgr:      mgr,
 		syncMgr:  syncMgr,
+		apps:     apps,
+		block:    block,
+		configs:  configs,
+		diamond:  diamond,
+		members:  members,
 		log:      log.NewHelper(log.With(logger, "module", "usecase/ctrl_session")),
 	}
 
@@ -88,7 +107,7 @@ func (uc *CtrlSessionUseCase) StartSession(ctx context.Context, userID int32, ct
 	}
 
 	// 4) 包一个 bootstrap handler：连接成功/收到房间列表时，做你想做的落库动作（可选）
-	h := uc.newBootstrapHandler(ctrl.Id, houseGID)
+	h := uc.newBootstrapHandler(pdb.NewCtxWithDB(ctx), userID, ctrl.Id, houseGID)
 
 	// 5) 不再强制关闭旧会话，改为“存在则更新、否则插入”
 
@@ -101,7 +120,7 @@ func (uc *CtrlSessionUseCase) StartSession(ctx context.Context, userID int32, ct
 	if v, err := strconv.Atoi(ctrl.GameUserID); err == nil {
 		gameUID = v
 	}
-	if err := uc.mgr.StartUser(ctx, int(userID), int(houseGID), mode, ctrl.Identifier, strings.ToUpper(ctrl.PwdMD5), gameUID, h); err != nil {
+	if err := uc.mgr.StartUser(ctx, int(userID), int(houseGID), mode, ctrl.Identifier, plaza.Credential{Sealed: ctrl.PwdMD5, KeyVer: ctrl.PwdKeyVer}, gameUID, h); err != nil {
 		// 启动失败：更新现有记录为 error 状态，而不是插入新记录
 		_ = uc.sessRepo.UpsertErrorByHouse(ctx, ctrl.Id, userID, houseGID, err.Error())
 		return errors.Wrap(err, "start session")
@@ -110,6 +129,11 @@ func (uc *CtrlSessionUseCase) StartSession(ctx context.Context, userID int32, ct
 	// 启动战绩同步，传入带 platform 的 context
 	uc.syncMgr.StartSync(ctx, int(userID), int(houseGID))
 
+	// 上线即查一次钻石余额，之后以游戏端财富推送为准
+	if sess, ok := uc.mgr.Get(int(userID), int(houseGID)); ok && sess != nil {
+		sess.GetDiamond()
+	}
+
 	// 7) 成功：若存在该店铺记录则更新最新一条为 online，否则插入
 	return uc.sessRepo.UpsertOnlineByHouse(ctx, ctrl.Id, userID, houseGID)
 }
@@ -125,7 +149,11 @@ func (uc *CtrlSessionUseCase) StopSession(ctx context.Context, userID int32, ctr
 	uc.syncMgr.StopSync(int(userID), int(houseGID))
 
 	// 更新现有记录为 offline，而不是插入新记录
-	return uc.sessRepo.SetOfflineByHouse(ctx, houseGID)
+	if err := uc.sessRepo.SetOfflineByHouse(ctx, houseGID); err != nil {
+		return err
+	}
+	eventx.Publish(ctx, houseGID, eventx.TypeSessionOffline, map[string]any{"ctrl_account_id": ctrlAccID, "reason": "stopped", "operator_user_id": userID})
+	return nil
 }
 
 // ===== 可选：登录/房间回调后，再做落库 =====
@@ -145,13 +173,31 @@ func (*noopHandler) OnTableRenew(*plazaUtils.TableRenew)           {}
 func (*noopHandler) OnDismissTable(int)                            {}
 func (*noopHandler) OnAppliesForHouse([]*plazaUtils.ApplyInfo)     {}
 func (*noopHandler) OnReconnectFailed(int, int)                    {}
+func (*noopHandler) OnBattleConfigsUpdated([]*plazaUtils.BattleConfig) {}
+func (*noopHandler) OnDiamondUpdated(int64, bool)                       {}
+func (*noopHandler) OnMemberUpdated(*plazaUtils.MemberUpdated)          {}
+func (*noopHandler) OnGroupUpdated(*plazaUtils.GroupProperty)           {}
+func (*noopHandler) OnGroupDeleted(int)                                 {}
+
+// seenAppliesTTL 申请从推送列表消失后多久忘记（已处理/过期的申请不会再出现）
+const seenAppliesTTL = 24 * time.Hour
 
 type bootstrapHandler struct {
 	noopHandler
 	once      sync.Once
+	ctx       context.Context // 仅携带 platform，用于发布业务事件
+	userID    int32           // 会话所属平台用户（manager 按 userID+houseGID 索引会话）
 	ctrlID    int32
 	houseGID  int32
 	bootstrap func()
+	apps      *ApplicationUseCase
+	block     *BlocklistUseCase
+	configs   *BattleConfigUseCase
+	diamond   *DiamondUseCase
+	members   *MemberSyncUseCase
+	mgr       plaza.Manager
+
+	seenApplies *cache.Cache // 已发布过 application.received 的申请；仍在推送中的续期，消失后过期
 }
 
 func (h *bootstrapHandler) OnLoginDone(ok bool) {
@@ -165,10 +211,139 @@ func (h *bootstrapHandler) OnRoomListUpdated(ts []*plazaUtils.TableInfo) {
 	h.noopHandler.OnRoomListUpdated(ts)
 }
 
-func (uc *CtrlSessionUseCase) newBootstrapHandler(ctrlID int32, houseGID int32) plaza.Handler {
+// OnDismissTable 桌台解散
+func (h *bootstrapHandler) OnDismissTable(table int) {
+	eventx.Publish(h.ctx, h.houseGID, eventx.TypeTableDismissed, map[string]any{"table": table})
+	h.noopHandler.OnDismissTable(table)
+}
+
+// OnAppliesForHouse 申请列表为全量推送，只对首次出现的申请发布 application.received
+func (h *bootstrapHandler) OnAppliesForHouse(list []*plazaUtils.ApplyInfo) {
+	fresh := make([]*plazaUtils.ApplyInfo, 0, len(list))
+	for _, a := range list {
+		if a == nil {
+			continue
+		}
+		key := strconv.Itoa(a.MessageId)
+		if err := h.seenApplies.Add(key, struct{}{}, cache.DefaultExpiration); err != nil {
+			h.seenApplies.SetDefault(key, struct{}{})
+			continue
+		}
+		fresh = append(fresh, a)
+	}
+
+	for _, a := range fresh {
+		eventx.Publish(h.ctx, h.houseGID, eventx.TypeApplicationReceived, map[string]any{
+			"message_id":    a.MessageId,
+			"applier_gid":   a.ApplierGid,
+			"applier_gname": a.ApplierGName,
+			"apply_type":    a.ApplyType,
+			"created_at":    a.CreatedAt,
+		})
+	}
+	// 自动规则/超时处理会回发协议并落库，不阻塞会话读循环
+	if h.apps != nil && len(fresh) > 0 {
+		go h.apps.OnGameApplies(h.ctx, h.houseGID, fresh)
+	}
+	h.noopHandler.OnAppliesForHouse(list)
+}
+
+// OnUserSitDown 黑名单游戏ID落座即踢出店铺；缓存过期时会查库，不阻塞会话读循环
+func (h *bootstrapHandler) OnUserSitDown(e *plazaUtils.UserSitDown) {
+	if e != nil && h.block != nil {
+		go h.kickIfBlocked(e.GameID)
+	}
+	h.noopHandler.OnUserSitDown(e)
+}
+
+// kickIfBlocked 成员ID取会话内成员快照
+func (h *bootstrapHandler) kickIfBlocked(gameID uint32) {
+	if !h.block.IsGameIDBlocked(h.ctx, int32(gameID)) {
+		return
+	}
+	sess, ok := h.mgr.Get(int(h.userID), int(h.houseGID))
+	if !ok || sess == nil {
+		return
+	}
+	for _, m := range sess.ListMembers() {
+		if m == nil || m.GameID != gameID {
+			continue
+		}
+		if err := h.mgr.KickMember(int(h.userID), int(h.houseGID), int(m.MemberID)); err != nil {
+			return
+		}
+		eventx.Publish(h.ctx, h.houseGID, eventx.TypeMemberBlockKicked, map[string]any{
+			"game_id":   gameID,
+			"member_id": m.MemberID,
+		})
+		return
+	}
+}
+
+// OnBattleConfigsUpdated 玩法快照落库（读循环内回调，异步写库）
+func (h *bootstrapHandler) OnBattleConfigsUpdated(list []*plazaUtils.BattleConfig) {
+	if h.configs != nil {
+		go h.configs.OnSessionConfigs(h.ctx, h.houseGID, list)
+	}
+	h.noopHandler.OnBattleConfigsUpdated(list)
+}
+
+// OnMemberListUpdated 全量成员列表：禁止状态回写（读循环内回调，异步写库）
+func (h *bootstrapHandler) OnMemberListUpdated(list []*plazaUtils.GroupMember) {
+	if h.members != nil {
+		go h.members.OnSessionMembers(h.ctx, h.houseGID, list)
+	}
+	h.noopHandler.OnMemberListUpdated(list)
+}
+
+// OnMemberUpdated 成员类型/权限变化：禁止状态回写
+func (h *bootstrapHandler) OnMemberUpdated(m *plazaUtils.MemberUpdated) {
+	if h.members != nil {
+		go h.members.OnSessionMemberUpdated(h.ctx, h.houseGID, m)
+	}
+	h.noopHandler.OnMemberUpdated(m)
+}
+
+// OnGroupDeleted 店铺被游戏端移除
+func (h *bootstrapHandler) OnGroupDeleted(groupID int) {
+	if h.members != nil {
+		h.members.OnGroupDeleted(h.ctx, h.houseGID, groupID)
+	}
+	h.noopHandler.OnGroupDeleted(groupID)
+}
+
+// OnDiamondUpdated 钻石余额落库并检查告警线（读循环内回调，异步写库）
+func (h *bootstrapHandler) OnDiamondUpdated(v int64, pushed bool) {
+	if h.diamond != nil {
+		go h.diamond.OnSessionDiamond(h.ctx, h.houseGID, h.ctrlID, v, pushed)
+	}
+	h.noopHandler.OnDiamondUpdated(v, pushed)
+}
+
+// OnReconnectFailed 重连失败，会话已下线（中控账号由 manager 回调停用）
+func (h *bootstrapHandler) OnReconnectFailed(houseGID int, retryCount int) {
+	eventx.Publish(h.ctx, h.houseGID, eventx.TypeSessionOffline, map[string]any{
+		"ctrl_account_id": h.ctrlID,
+		"reason":          "reconnect_failed",
+		"retry_count":     retryCount,
+	})
+	h.noopHandler.OnReconnectFailed(houseGID, retryCount)
+}
+
+func (uc *CtrlSessionUseCase) newBootstrapHandler(ctx context.Context, userID, ctrlID int32, houseGID int32) plaza.Handler {
 	return &bootstrapHandler{
+		ctx:      ctx,
+		userID:   userID,
 		ctrlID:   ctrlID,
 		houseGID: houseGID,
+		apps:     uc.apps,
+		block:    uc.block,
+		configs:  uc.configs,
+		diamond:  uc.diamond,
+		members:  uc.members,
+		mgr:      uc.mgr,
+
+		seenApplies: cache.New(seenAppliesTTL, seenAppliesTTL/4),
 		bootstrap: func() {
 			// 按你的需求：连接成功/房间有了 → 再确保店铺落库、绑定关系等
 			// 示例（伪代码，按你的仓储接口替换）：
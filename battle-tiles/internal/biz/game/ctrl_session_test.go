@@ -0,0 +1,61 @@
+package game
+
+import (
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// chanPublisher 把发布的事件送入通道
+type chanPublisher chan *eventx.Event
+
+func (p chanPublisher) PublishEvent(_ context.Context, e *eventx.Event) error {
+	p <- e
+	return nil
+}
+
+func TestOnAppliesForHousePublishesOnce(t *testing.T) {
+	events := make(chanPublisher, 16)
+	eventx.Bind(events)
+	t.Cleanup(func() { eventx.Bind(nil) })
+
+	h := &bootstrapHandler{
+		ctx:         context.WithValue(context.Background(), pdb.CtxDBKey, "test"),
+		houseGID:    20001,
+		seenApplies: cache.New(time.Hour, time.Hour),
+	}
+	h.OnAppliesForHouse([]*plazaUtils.ApplyInfo{{MessageId: 1}, {MessageId: 2}, nil})
+	// 全量推送：已见过的申请不再发布
+	h.OnAppliesForHouse([]*plazaUtils.ApplyInfo{{MessageId: 2}, {MessageId: 3}})
+
+	var got []int
+	for len(got) < 3 {
+		select {
+		case e := <-events:
+			if e.Type != eventx.TypeApplicationReceived || e.HouseGID != 20001 {
+				t.Fatalf("unexpected event: %+v", e)
+			}
+			got = append(got, e.Data.(map[string]any)["message_id"].(int))
+		case <-time.After(time.Second):
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("duplicate event: %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	sort.Ints(got)
+	if got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("published %v, want [1 2 3]", got)
+	}
+	if n := h.seenApplies.ItemCount(); n != 3 {
+		t.Fatalf("seen = %d, want 3", n)
+	}
+}
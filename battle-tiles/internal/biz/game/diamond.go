@@ -0,0 +1,267 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// 余额不变时的最小落库间隔（推送/查询频繁时避免刷表）
+	diamondSnapshotInterval = 10 * time.Minute
+	// 消耗预测的观察窗口
+	diamondProjectionWindow = 24 * time.Hour
+)
+
+// DiamondProjection 按近期消耗推算的余量
+type DiamondProjection struct {
+	HouseGID      int32     `json:"house_gid"`
+	CtrlAccountID int32     `json:"ctrl_account_id"`
+	Balance       int64     `json:"balance"`
+	ObservedAt    time.Time `json:"observed_at"`
+	Threshold     int64     `json:"threshold"` // 告警线，0 不告警
+	WindowHours   float64   `json:"window_hours"`
+	Consumed      int64     `json:"consumed"`              // 窗口内消耗（只累计下降，充值不抵扣）
+	Tables        int64     `json:"tables"`                // 窗口内开桌数（按战绩）
+	PerTable      float64   `json:"per_table"`             // 每桌消耗
+	PerHour       float64   `json:"per_hour"`              // 每小时消耗
+	TablesLeft    *int64    `json:"tables_left,omitempty"` // 余额还能开的桌数，无消耗数据时为空
+	HoursLeft     *float64  `json:"hours_left,omitempty"`  // 按当前速度还能撑的小时数，无消耗数据时为空
+}
+
+type diamondState struct {
+	balance int64
+	at      time.Time
+	alerted bool // 已告警，余额回到告警线以上后复位
+}
+
+// DiamondUseCase 中控钻石余额：会话查询回包/推送落库、开桌消耗推算、低余额告警
+type DiamondUseCase struct {
+	repo     repo.DiamondRepo
+	settings repo.HouseSettingsRepo
+	battles  repo.BattleRecordRepo
+	mgr      plaza.Manager
+	log      *log.Helper
+
+	mu    sync.Mutex
+	state map[string]*diamondState // platform:house:ctrl → 最近余额
+}
+
+func NewDiamondUseCase(r repo.DiamondRepo, settings repo.HouseSettingsRepo, battles repo.BattleRecordRepo, mgr plaza.Manager, logger log.Logger) *DiamondUseCase {
+	return &DiamondUseCase{
+		repo:     r,
+		settings: settings,
+		battles:  battles,
+		mgr:      mgr,
+		log:      log.NewHelper(log.With(logger, "module", "usecase/diamond")),
+		state:    make(map[string]*diamondState),
+	}
+}
+
+func diamondStateKey(ctx context.Context, houseGID, ctrlID int32) string {
+	return fmt.Sprintf("%s:%d:%d", pdb.GetDBKeyFromCtx(ctx), houseGID, ctrlID)
+}
+
+// OnSessionDiamond 会话得到钻石余额（读循环外调用）
+func (uc *DiamondUseCase) OnSessionDiamond(ctx context.Context, houseGID, ctrlID int32, balance int64, pushed bool) {
+	source := model.DiamondSourceQuery
+	if pushed {
+		source = model.DiamondSourcePush
+	}
+	now := time.Now()
+	key := diamondStateKey(ctx, houseGID, ctrlID)
+
+	st := uc.loadState(ctx, key, houseGID, ctrlID)
+	uc.mu.Lock()
+	prev := st.balance
+	save := prev != balance || now.Sub(st.at) >= diamondSnapshotInterval
+	if save {
+		st.balance, st.at = balance, now
+	}
+	uc.mu.Unlock()
+
+	if save {
+		if err := uc.repo.Insert(ctx, &model.GameDiamondSnapshot{
+			HouseGID:      houseGID,
+			CtrlAccountID: ctrlID,
+			Balance:       balance,
+			Source:        source,
+		}); err != nil {
+			uc.log.Errorf("save diamond house=%d ctrl=%d failed: %v", houseGID, ctrlID, err)
+		}
+	}
+	uc.checkAlert(ctx, houseGID, ctrlID, balance)
+}
+
+// loadState 内存中的最近余额；首次从库里取最近一条（查库不持锁，并发加载时以先放入的为准）
+func (uc *DiamondUseCase) loadState(ctx context.Context, key string, houseGID, ctrlID int32) *diamondState {
+	uc.mu.Lock()
+	st := uc.state[key]
+	uc.mu.Unlock()
+	if st != nil {
+		return st
+	}
+	loaded := &diamondState{balance: -1}
+	if last, err := uc.repo.Latest(ctx, houseGID, ctrlID); err == nil {
+		loaded.balance, loaded.at = last.Balance, last.CreatedAt
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if st = uc.state[key]; st == nil {
+		st = loaded
+		uc.state[key] = st
+	}
+	return st
+}
+
+// checkAlert 跌到告警线（含）以下时告警一次；回到告警线以上复位
+func (uc *DiamondUseCase) checkAlert(ctx context.Context, houseGID, ctrlID int32, balance int64) {
+	threshold := uc.threshold(ctx, houseGID)
+	key := diamondStateKey(ctx, houseGID, ctrlID)
+
+	uc.mu.Lock()
+	st := uc.state[key]
+	fire := false
+	if st != nil {
+		switch {
+		case threshold <= 0 || balance > threshold:
+			st.alerted = false
+		case !st.alerted:
+			st.alerted = true
+			fire = true
+		}
+	}
+	uc.mu.Unlock()
+	if !fire {
+		return
+	}
+
+	p, err := uc.project(ctx, houseGID, ctrlID, balance, time.Now(), threshold)
+	if err != nil {
+		uc.log.Warnf("project diamond house=%d failed: %v", houseGID, err)
+		p = &DiamondProjection{HouseGID: houseGID, CtrlAccountID: ctrlID, Balance: balance, ObservedAt: time.Now(), Threshold: threshold}
+	}
+	uc.log.Warnf("diamond low house=%d ctrl=%d balance=%d threshold=%d", houseGID, ctrlID, balance, threshold)
+	eventx.Publish(ctx, houseGID, eventx.TypeHouseDiamondLow, p)
+}
+
+func (uc *DiamondUseCase) threshold(ctx context.Context, houseGID int32) int64 {
+	s, err := uc.settings.Get(ctx, houseGID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.log.Warnf("load house settings house=%d failed: %v", houseGID, err)
+		}
+		return 0
+	}
+	return s.DiamondAlertThreshold
+}
+
+// SetThreshold 设置告警线，0 关闭
+func (uc *DiamondUseCase) SetThreshold(ctx context.Context, opUser, houseGID int32, threshold int64) error {
+	if threshold < 0 {
+		return errors.New("threshold must be >= 0")
+	}
+	return auditHouseSettings(ctx, uc.settings, houseGID, func() error {
+		return uc.settings.UpsertDiamondAlertThreshold(ctx, houseGID, threshold, opUser)
+	})
+}
+
+// Refresh 通过在线会话查询一次余额，结果异步回调落库
+func (uc *DiamondUseCase) Refresh(opUser, houseGID int32) error {
+	sess, ok := uc.mgr.Get(int(opUser), int(houseGID))
+	if !ok || sess == nil {
+		if sess, ok = uc.mgr.GetAnyByHouse(int(houseGID)); !ok || sess == nil {
+			return errors.New("no online session")
+		}
+	}
+	sess.GetDiamond()
+	return nil
+}
+
+// Status 店铺最近余额与消耗推算（取最近有记录的中控账号）
+func (uc *DiamondUseCase) Status(ctx context.Context, houseGID int32) (*DiamondProjection, error) {
+	last, err := uc.repo.Latest(ctx, houseGID, 0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := uc.project(ctx, houseGID, last.CtrlAccountID, last.Balance, last.CreatedAt, uc.threshold(ctx, houseGID))
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Series 余额时间序列
+func (uc *DiamondUseCase) Series(ctx context.Context, houseGID, ctrlID int32, start, end time.Time) ([]*model.GameDiamondSnapshot, error) {
+	if !end.After(start) {
+		return nil, errors.New("end must be after start")
+	}
+	return uc.repo.ListRange(ctx, houseGID, ctrlID, start, end, 0)
+}
+
+// project 用窗口内余额下降量与同期开桌数推算每桌消耗和剩余可开桌数
+func (uc *DiamondUseCase) project(ctx context.Context, houseGID, ctrlID int32, balance int64, at time.Time, threshold int64) (*DiamondProjection, error) {
+	end := time.Now()
+	list, err := uc.repo.ListRange(ctx, houseGID, ctrlID, end.Add(-diamondProjectionWindow), end, 0)
+	if err != nil {
+		return nil, err
+	}
+	p := &DiamondProjection{
+		HouseGID:      houseGID,
+		CtrlAccountID: ctrlID,
+		Balance:       balance,
+		ObservedAt:    at,
+		Threshold:     threshold,
+	}
+	p.Consumed, p.WindowHours = diamondConsumption(list)
+	if p.WindowHours > 0 {
+		// 开桌数只统计余额序列覆盖的时段，与消耗口径一致
+		tables, err := uc.battles.CountTables(ctx, houseGID, list[0].CreatedAt, list[len(list)-1].CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		p.Tables = tables
+	}
+	fillDiamondProjection(p)
+	return p, nil
+}
+
+// diamondConsumption 累计相邻两点的下降量（上升视为充值，不计入），返回消耗与序列覆盖的小时数
+func diamondConsumption(list []*model.GameDiamondSnapshot) (int64, float64) {
+	if len(list) < 2 {
+		return 0, 0
+	}
+	var consumed int64
+	for i := 1; i < len(list); i++ {
+		if d := list[i-1].Balance - list[i].Balance; d > 0 {
+			consumed += d
+		}
+	}
+	return consumed, list[len(list)-1].CreatedAt.Sub(list[0].CreatedAt).Hours()
+}
+
+func fillDiamondProjection(p *DiamondProjection) {
+	if p.Consumed <= 0 {
+		return
+	}
+	if p.Tables > 0 {
+		p.PerTable = float64(p.Consumed) / float64(p.Tables)
+		left := int64(float64(p.Balance) / p.PerTable)
+		p.TablesLeft = &left
+	}
+	if p.WindowHours > 0 {
+		p.PerHour = float64(p.Consumed) / p.WindowHours
+		left := float64(p.Balance) / p.PerHour
+		p.HoursLeft = &left
+	}
+}
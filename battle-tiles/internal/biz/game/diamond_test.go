@@ -0,0 +1,134 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+func TestDiamondConsumptionIgnoresRecharge(t *testing.T) {
+	t0 := time.Date(2026, 10, 30, 20, 0, 0, 0, time.UTC)
+	list := []*model.GameDiamondSnapshot{
+		{Balance: 1000, CreatedAt: t0},
+		{Balance: 900, CreatedAt: t0.Add(time.Hour)},
+		{Balance: 1500, CreatedAt: t0.Add(2 * time.Hour)}, // 充值
+		{Balance: 1300, CreatedAt: t0.Add(4 * time.Hour)},
+	}
+	consumed, hours := diamondConsumption(list)
+	if consumed != 300 || hours != 4 {
+		t.Fatalf("consumed=%d hours=%v, want 300 and 4", consumed, hours)
+	}
+
+	p := &DiamondProjection{Balance: 1300, Consumed: consumed, WindowHours: hours, Tables: 30}
+	fillDiamondProjection(p)
+	if p.PerTable != 10 || p.TablesLeft == nil || *p.TablesLeft != 130 {
+		t.Fatalf("per_table=%v tables_left=%v", p.PerTable, p.TablesLeft)
+	}
+	if p.PerHour != 75 || p.HoursLeft == nil || *p.HoursLeft < 17.3 || *p.HoursLeft > 17.4 {
+		t.Fatalf("per_hour=%v hours_left=%v", p.PerHour, p.HoursLeft)
+	}
+}
+
+func TestDiamondProjectionWithoutConsumption(t *testing.T) {
+	p := &DiamondProjection{Balance: 500}
+	p.Consumed, p.WindowHours = diamondConsumption([]*model.GameDiamondSnapshot{{Balance: 500}})
+	fillDiamondProjection(p)
+	if p.TablesLeft != nil || p.HoursLeft != nil {
+		t.Fatalf("expected no projection, got %+v", p)
+	}
+}
+
+// memDiamonds 余额快照：记录写入次数，Latest 返回预置的最近一条
+type memDiamonds struct {
+	repo.DiamondRepo
+	latest  *model.GameDiamondSnapshot
+	inserts []int64
+}
+
+func (r *memDiamonds) Insert(_ context.Context, m *model.GameDiamondSnapshot) error {
+	r.inserts = append(r.inserts, m.Balance)
+	return nil
+}
+
+func (r *memDiamonds) Latest(context.Context, int32, int32) (*model.GameDiamondSnapshot, error) {
+	if r.latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.latest, nil
+}
+
+func (r *memDiamonds) ListRange(context.Context, int32, int32, time.Time, time.Time, int) ([]*model.GameDiamondSnapshot, error) {
+	return nil, nil
+}
+
+type diamondThreshold struct {
+	repo.HouseSettingsRepo
+	value int64
+}
+
+func (s *diamondThreshold) Get(context.Context, int32) (*model.GameHouseSettings, error) {
+	return &model.GameHouseSettings{DiamondAlertThreshold: s.value}, nil
+}
+
+func TestDiamondAlertFiresOncePerDip(t *testing.T) {
+	events := make(chanPublisher, 16)
+	eventx.Bind(events)
+	t.Cleanup(func() { eventx.Bind(nil) })
+
+	ctx := context.WithValue(context.Background(), pdb.CtxDBKey, "test")
+	threshold := &diamondThreshold{value: 100}
+	uc := NewDiamondUseCase(&memDiamonds{}, threshold, nil, nil, log.DefaultLogger)
+
+	expect := func(balance int64, fire bool) {
+		t.Helper()
+		uc.OnSessionDiamond(ctx, 20001, 3, balance, true)
+		select {
+		case e := <-events:
+			if !fire {
+				t.Fatalf("balance %d: unexpected event %+v", balance, e)
+			}
+			if p := e.Data.(*DiamondProjection); e.Type != eventx.TypeHouseDiamondLow || p.Balance != balance || p.Threshold != 100 {
+				t.Fatalf("balance %d: event %+v data %+v", balance, e, p)
+			}
+		case <-time.After(50 * time.Millisecond):
+			if fire {
+				t.Fatalf("balance %d: no alert", balance)
+			}
+		}
+	}
+	expect(150, false)
+	expect(100, true) // 等于告警线即告警
+	expect(90, false) // 仍在线下，不重复
+	expect(101, false)
+	expect(80, true) // 回到线上后复位，再次跌破重新告警
+
+	threshold.value = 0 // 0 关闭告警
+	expect(101, false)
+	expect(10, false)
+}
+
+func TestDiamondSnapshotThrottle(t *testing.T) {
+	ctx := context.WithValue(context.Background(), pdb.CtxDBKey, "test")
+	store := &memDiamonds{latest: &model.GameDiamondSnapshot{Balance: 500, CreatedAt: time.Now()}}
+	uc := NewDiamondUseCase(store, &diamondThreshold{}, nil, nil, log.DefaultLogger)
+
+	uc.OnSessionDiamond(ctx, 20001, 3, 500, false) // 与库里最近一条相同且未到间隔
+	uc.OnSessionDiamond(ctx, 20001, 3, 480, true)  // 余额变化立即落库
+	uc.OnSessionDiamond(ctx, 20001, 3, 480, false)
+	if len(store.inserts) != 1 || store.inserts[0] != 480 {
+		t.Fatalf("inserts = %v, want [480]", store.inserts)
+	}
+
+	uc.state[diamondStateKey(ctx, 20001, 3)].at = time.Now().Add(-diamondSnapshotInterval)
+	uc.OnSessionDiamond(ctx, 20001, 3, 480, false) // 余额不变但超过间隔
+	if len(store.inserts) != 2 {
+		t.Fatalf("inserts = %v, want a snapshot after the interval", store.inserts)
+	}
+}
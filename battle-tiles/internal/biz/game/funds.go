@@ -9,6 +9,8 @@ import (
 
 	model "battle-tiles/internal/dal/model/game"
 	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/plugin/auditx"
+	"battle-tiles/pkg/plugin/eventx"
 
 	"gorm.io/gorm"
 )
@@ -81,6 +83,10 @@ func (uc *FundsUseCase) Deposit(ctx context.Context, opUser int32, houseGID, mem
 	if commit := tx.Commit(); commit.Error != nil {
 		return nil, commit.Error
 	}
+	auditWallet(ctx, houseGID, memberID, reason, before, after)
+	eventx.Publish(ctx, houseGID, eventx.TypeFundsDeposit, FundsEventData{
+		MemberID: memberID, Amount: amount, BalanceBefore: before, BalanceAfter: after, BizNo: bizNo,
+	})
 	return w, nil
 }
 
@@ -148,19 +154,24 @@ func (uc *FundsUseCase) Withdraw(ctx context.Context, opUser int32, houseGID, me
 	if commit := tx.Commit(); commit.Error != nil {
 		return nil, commit.Error
 	}
+	auditWallet(ctx, houseGID, memberID, reason, before, after)
+	eventx.Publish(ctx, houseGID, eventx.TypeFundsWithdraw, FundsEventData{
+		MemberID: memberID, Amount: amount, BalanceBefore: before, BalanceAfter: after, BizNo: bizNo, Force: force,
+	})
 	return w, nil
 }
 
-func (uc *FundsUseCase) UpdateLimit(ctx context.Context, opUser int32, houseGID, memberID int32, limitMin *int32, forbid *bool, reason string) (*model.GameMemberWallet, error) {
+// UpdateLimit 更新额度/禁分；forbidChanged 表示禁分状态确实发生了变化（需要同步到游戏端）
+func (uc *FundsUseCase) UpdateLimit(ctx context.Context, opUser int32, houseGID, memberID int32, limitMin *int32, forbid *bool, reason string) (w *model.GameMemberWallet, forbidChanged bool, err error) {
 	tx, txErr := uc.wallet.BeginTx(ctx)
 	if txErr != nil {
-		return nil, txErr
+		return nil, false, txErr
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	w, err := uc.wallet.GetForUpdate(ctx, tx, houseGID, memberID)
+	w, err = uc.wallet.GetForUpdate(ctx, tx, houseGID, memberID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
+		return nil, false, err
 	}
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		w = &model.GameMemberWallet{
@@ -168,6 +179,8 @@ func (uc *FundsUseCase) UpdateLimit(ctx context.Context, opUser int32, houseGID,
 			MemberID: memberID,
 		}
 	}
+	beforeLimit := map[string]any{"limit_min": w.LimitMin, "forbid": w.Forbid}
+	beforeForbid := w.Forbid
 	if limitMin != nil {
 		w.LimitMin = *limitMin
 	}
@@ -176,7 +189,7 @@ func (uc *FundsUseCase) UpdateLimit(ctx context.Context, opUser int32, houseGID,
 	}
 
 	if err = uc.wallet.Upsert(ctx, tx, w); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	// 审计流水：调整，变动额=0
 	if err = uc.wallet.AppendLedger(ctx, tx, &model.GameWalletLedger{
@@ -190,13 +203,34 @@ func (uc *FundsUseCase) UpdateLimit(ctx context.Context, opUser int32, houseGID,
 		OperatorUserID: opUser, // int32
 		BizNo:          fmt.Sprintf("limit-%d-%d-%d", houseGID, memberID, opUser),
 	}); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if commit := tx.Commit(); commit.Error != nil {
-		return nil, commit.Error
+		return nil, false, commit.Error
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "member", memberID)
+	auditx.SetBefore(ctx, beforeLimit)
+	auditx.SetAfter(ctx, map[string]any{"limit_min": w.LimitMin, "forbid": w.Forbid})
+	auditx.SetReason(ctx, reason)
+	if w.Forbid != beforeForbid {
+		typ := eventx.TypeMemberUnforbidden
+		if w.Forbid {
+			typ = eventx.TypeMemberForbidden
+		}
+		eventx.Publish(ctx, houseGID, typ, map[string]any{"member_id": memberID, "reason": reason, "operator_user_id": opUser})
 	}
-	return w, nil
+	return w, w.Forbid != beforeForbid, nil
+}
+
+// auditWallet 上分/下分的审计补充：目标成员与余额变化
+func auditWallet(ctx context.Context, houseGID, memberID int32, reason string, before, after int32) {
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "member", memberID)
+	auditx.SetBefore(ctx, map[string]any{"balance": before})
+	auditx.SetAfter(ctx, map[string]any{"balance": after})
+	auditx.SetReason(ctx, reason)
 }
 
 // —— 单人钱包 ——
@@ -6,6 +6,7 @@ import (
 	model "battle-tiles/internal/dal/model/game"
 	repo "battle-tiles/internal/dal/repo/game"
 	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/utils/sealx"
 	"context"
 	"fmt"
 	"strings"
@@ -21,6 +22,8 @@ type GameAccountUseCase struct {
 	accHouseRepo       repo.GameAccountHouseRepo
 	sessRepo           repo.SessionRepo
 	mgr                plaza.Manager
+	keyring            *sealx.Keyring
+	block              *BlocklistUseCase
 	log                *log.Helper
 }
 
@@ -30,6 +33,8 @@ func NewGameAccountUseCase(
 	accHouse repo.GameAccountHouseRepo,
 	sess repo.SessionRepo,
 	mgr plaza.Manager,
+	keyring *sealx.Keyring,
+	block *BlocklistUseCase,
 	logger log.Logger,
 ) *GameAccountUseCase {
 	return &GameAccountUseCase{
@@ -38,17 +43,31 @@ func NewGameAccountUseCase(
 		accHouseRepo:       accHouse,
 		sessRepo:           sess,
 		mgr:                mgr,
+		keyring:            keyring,
+		block:              block,
 		log:                log.NewHelper(log.With(logger, "module", "usecase/game_account")),
 	}
 }
 
 // 只绑定“我的”账号 普通用户才使用这个方法
-func (uc *GameAccountUseCase) BindSingle(ctx context.Context, userID int32, mode consts.GameLoginMode, identifier, pwdMD5, nickname string) (*model.GameAccount, error) {
+// client 为请求来源（设备/IP），与平台用户、游戏ID 一起做黑名单检查
+func (uc *GameAccountUseCase) BindSingle(ctx context.Context, userID int32, mode consts.GameLoginMode, identifier, pwdMD5, nickname string, client BlocklistSubject) (*model.GameAccount, error) {
+	// 平台黑名单：先按用户/设备/IP 拦截，被拉黑的来源不能借探活试密码
+	subject := client
+	subject.UserID = userID
+	if err := uc.guardBlocklist(ctx, subject); err != nil {
+		return nil, err
+	}
 	// 探活并获取游戏用户信息
 	info, err := uc.mgr.ProbeLoginWithInfo(ctx, mode, identifier, pwdMD5)
 	if err != nil {
 		return nil, err
 	}
+	// 游戏ID 需探活后才能拿到
+	subject.GameID = int32(info.GameID)
+	if err := uc.guardBlocklist(ctx, subject); err != nil {
+		return nil, err
+	}
 	// 普通用户仅允许1条（DB 触发器也兜底）
 	if _, err := uc.accRepo.GetOneByUser(ctx, userID); err == nil {
 		return nil, errors.New("you have already bound a game account")
@@ -56,6 +75,11 @@ func (uc *GameAccountUseCase) BindSingle(ctx context.Context, userID int32, mode
 		return nil, err
 	}
 
+	cred, err := plaza.SealCredential(uc.keyring, pwdMD5)
+	if err != nil {
+		return nil, err
+	}
+
 	loginMode := "account"
 	if mode == consts.GameLoginModeMobile {
 		loginMode = "mobile"
@@ -63,7 +87,8 @@ func (uc *GameAccountUseCase) BindSingle(ctx context.Context, userID int32, mode
 	a := &model.GameAccount{
 		UserID:     userID,
 		Account:    strings.TrimSpace(identifier),
-		PwdMD5:     strings.ToUpper(strings.TrimSpace(pwdMD5)),
+		PwdMD5:     cred.Sealed,
+		PwdKeyVer:  cred.KeyVer,
 		Nickname:   nickname,
 		IsDefault:  true,
 		Status:     1,
@@ -76,6 +101,13 @@ func (uc *GameAccountUseCase) BindSingle(ctx context.Context, userID int32, mode
 	return a, nil
 }
 
+func (uc *GameAccountUseCase) guardBlocklist(ctx context.Context, s BlocklistSubject) error {
+	if uc.block == nil {
+		return nil
+	}
+	return uc.block.Guard(ctx, s)
+}
+
 func (uc *GameAccountUseCase) GetMine(ctx context.Context, userID int32) (*model.GameAccount, error) {
 	return uc.accRepo.GetOneByUser(ctx, userID)
 }
@@ -121,7 +153,7 @@ func (uc *GameAccountUseCase) FixEmptyGameUserID(ctx context.Context) (fixed int
 			mode = consts.GameLoginModeMobile
 		}
 
-		info, err := uc.mgr.ProbeLoginWithInfo(ctx, mode, acc.Account, acc.PwdMD5)
+		info, err := uc.mgr.ProbeStoredLoginWithInfo(ctx, mode, acc.Account, plaza.Credential{Sealed: acc.PwdMD5, KeyVer: acc.PwdKeyVer})
 		if err != nil {
 			uc.log.Warnf("Failed to get game user info for account %s: %v", acc.Account, err)
 			failed++
@@ -9,6 +9,8 @@ import (
 	"battle-tiles/internal/dal/resp"
 	"battle-tiles/internal/dal/vo/game"
 	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/auditx"
+	"battle-tiles/pkg/utils/sealx"
 	"context"
 	"fmt"
 	"strings"
@@ -24,6 +26,7 @@ type CtrlAccountUseCase struct {
 	linkRepo repo.GameCtrlAccountHouseRepo
 	accRepo  repo.GameAccountRepo
 	mgr      plaza.Manager
+	keyring  *sealx.Keyring
 	log      *log.Helper
 }
 
@@ -32,6 +35,7 @@ func NewCtrlAccountUseCase(
 	link repo.GameCtrlAccountHouseRepo,
 	acc repo.GameAccountRepo,
 	mgr plaza.Manager,
+	keyring *sealx.Keyring,
 	logger log.Logger,
 ) *CtrlAccountUseCase {
 	return &CtrlAccountUseCase{
@@ -39,6 +43,7 @@ func NewCtrlAccountUseCase(
 		linkRepo: link,
 		accRepo:  acc,
 		mgr:      mgr,
+		keyring:  keyring,
 		log:      log.NewHelper(log.With(logger, "module", "usecase/ctrl_account")),
 	}
 }
@@ -55,11 +60,19 @@ func (uc *CtrlAccountUseCase) CreateOrUpdateCtrl(ctx context.Context, mode const
 	if err != nil {
 		return nil, errors.Wrap(err, "probe login failed")
 	}
+	cred, err := plaza.SealCredential(uc.keyring, md5)
+	if err != nil {
+		return nil, err
+	}
+	if old, err := uc.ctrlRepo.GetByIdentifier(ctx, int32(mode), id); err == nil && old != nil {
+		auditx.SetBefore(ctx, ctrlAuditView(old))
+	}
 	now := time.Now()
 	m := &model.GameCtrlAccount{
 		LoginMode:    int32(mode),
 		Identifier:   id,
-		PwdMD5:       md5,
+		PwdMD5:       cred.Sealed,
+		PwdKeyVer:    cred.KeyVer,
 		GameUserID:   fmt.Sprintf("%d", info.UserID),
 		GameID:       fmt.Sprintf("%d", info.GameID),
 		Status:       status,
@@ -69,6 +82,8 @@ func (uc *CtrlAccountUseCase) CreateOrUpdateCtrl(ctx context.Context, mode const
 	if err != nil {
 		return nil, err
 	}
+	auditx.SetTarget(ctx, "ctrl_account", m.Id)
+	auditx.SetAfter(ctx, ctrlAuditView(m))
 	// 确保存在一个与中控关联的 game_account，便于后续绑定店铺（如果还没有）
 	if uc.accRepo != nil {
 		if cnt, _ := uc.accRepo.CountByCtrl(ctx, m.Id); cnt == 0 {
@@ -79,7 +94,8 @@ func (uc *CtrlAccountUseCase) CreateOrUpdateCtrl(ctx context.Context, mode const
 			_ = uc.accRepo.Create(ctx, &model.GameAccount{
 				UserID:        operatorUserID,
 				Account:       id,
-				PwdMD5:        md5,
+				PwdMD5:        cred.Sealed,
+				PwdKeyVer:     cred.KeyVer,
 				Nickname:      "",
 				IsDefault:     true,
 				Status:        1,
@@ -105,6 +121,7 @@ func (uc *CtrlAccountUseCase) BindCtrlToHouse(ctx context.Context, ctrlID int32,
 					UserID:        operatorUserID,
 					Account:       ctrl.Identifier,
 					PwdMD5:        ctrl.PwdMD5,
+					PwdKeyVer:     ctrl.PwdKeyVer,
 					Nickname:      "",
 					IsDefault:     true,
 					Status:        1,
@@ -123,11 +140,20 @@ func (uc *CtrlAccountUseCase) BindCtrlToHouse(ctx context.Context, ctrlID int32,
 		}
 		return err
 	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "ctrl_account", ctrlID)
+	auditx.SetAfter(ctx, map[string]any{"house_gid": houseGID, "status": status})
 	return nil
 }
 
 func (uc *CtrlAccountUseCase) UnbindCtrlFromHouse(ctx context.Context, ctrlID int32, houseGID int32) error {
-	return uc.linkRepo.UnbindByCtrl(ctx, ctrlID, houseGID)
+	if err := uc.linkRepo.UnbindByCtrl(ctx, ctrlID, houseGID); err != nil {
+		return err
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "ctrl_account", ctrlID)
+	auditx.SetBefore(ctx, map[string]any{"house_gid": houseGID})
+	return nil
 }
 
 // UpdateStatus 更新中控账号状态
@@ -137,10 +163,18 @@ func (uc *CtrlAccountUseCase) UpdateStatus(ctx context.Context, ctrlID int32, st
 		return errors.New("invalid status, must be 0 or 1")
 	}
 
+	before, err := uc.ctrlRepo.Get(ctx, ctrlID)
+	if err != nil {
+		return errors.Wrap(err, "get ctrl account failed")
+	}
+
 	// 更新数据库状态
 	if err := uc.ctrlRepo.UpdateStatus(ctx, ctrlID, status); err != nil {
 		return errors.Wrap(err, "update ctrl account status failed")
 	}
+	auditx.SetTarget(ctx, "ctrl_account", ctrlID)
+	auditx.SetBefore(ctx, map[string]any{"status": before.Status})
+	auditx.SetAfter(ctx, map[string]any{"status": status})
 
 	uc.log.Infof("中控账号 %d 状态已更新为 %d", ctrlID, status)
 	return nil
@@ -229,6 +263,26 @@ func (uc *CtrlAccountUseCase) Delete(ctx context.Context, ctrlID int32) error {
 		return errors.Wrap(err, "delete ctrl account failed")
 	}
 
+	houseGIDs := make([]int32, 0, len(houses))
+	for _, h := range houses {
+		houseGIDs = append(houseGIDs, h.HouseGID)
+	}
+	before := ctrlAuditView(ctrl)
+	before["houses"] = houseGIDs
+	auditx.SetTarget(ctx, "ctrl_account", ctrl.Id)
+	auditx.SetBefore(ctx, before)
+
 	uc.log.Infof("已删除中控账号 %d (identifier=%s)", ctrl.Id, ctrl.Identifier)
 	return nil
 }
+
+// ctrlAuditView 审计中记录的中控信息（不含密码）
+func ctrlAuditView(m *model.GameCtrlAccount) map[string]any {
+	return map[string]any{
+		"login_mode":   m.LoginMode,
+		"identifier":   m.Identifier,
+		"game_user_id": m.GameUserID,
+		"game_id":      m.GameID,
+		"status":       m.Status,
+	}
+}
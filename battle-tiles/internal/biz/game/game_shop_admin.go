@@ -6,6 +6,8 @@ import (
 	model "battle-tiles/internal/dal/model/game"
 	basicRepo "battle-tiles/internal/dal/repo/basic"
 	repo "battle-tiles/internal/dal/repo/game"
+	rbacstore "battle-tiles/internal/dal/repo/rbac"
+	"battle-tiles/pkg/plugin/auditx"
 	"context"
 	"strings"
 
@@ -18,6 +20,8 @@ type ShopAdminUseCase struct {
 	repo          repo.GameShopAdminRepo
 	shopGroupRepo repo.ShopGroupRepo
 	basicUserRepo basicRepo.BasicUserRepo
+	scopeRepo     basicRepo.UserScopeRoleRepo
+	rbac          *rbacstore.Store
 	log           *log.Helper
 }
 
@@ -25,12 +29,16 @@ func NewShopAdminUseCase(
 	r repo.GameShopAdminRepo,
 	shopGroupRepo repo.ShopGroupRepo,
 	basicUserRepo basicRepo.BasicUserRepo,
+	scopeRepo basicRepo.UserScopeRoleRepo,
+	rbac *rbacstore.Store,
 	logger log.Logger,
 ) *ShopAdminUseCase {
 	return &ShopAdminUseCase{
 		repo:          r,
 		shopGroupRepo: shopGroupRepo,
 		basicUserRepo: basicUserRepo,
+		scopeRepo:     scopeRepo,
+		rbac:          rbac,
 		log:           log.NewHelper(log.With(logger, "module", "usecase/shop_admin")),
 	}
 }
@@ -49,6 +57,8 @@ func validateRole(role string) (string, error) {
 }
 
 func (uc *ShopAdminUseCase) Assign(ctx context.Context, houseGID int32, targetUserID int32, role string) error {
+	auditx.SetTarget(ctx, "user", targetUserID)
+	auditx.SetAfter(ctx, map[string]any{"role": role})
 	if houseGID <= 0 || targetUserID <= 0 {
 		return errors.New("invalid house_gid or user_id")
 	}
@@ -76,6 +86,11 @@ func (uc *ShopAdminUseCase) Assign(ctx context.Context, houseGID int32, targetUs
 		return err
 	}
 
+	// 同步店铺作用域角色（店铺内的权限以此为准）
+	if err := uc.syncScopeRole(ctx, houseGID, targetUserID, r); err != nil {
+		return errors.Wrap(err, "授予店铺角色失败")
+	}
+
 	// 3. 更新用户角色为店铺管理员
 	user.Role = basicModel.UserRoleStoreAdmin
 	if _, err := uc.basicUserRepo.UpdateByPK(ctx, user); err != nil {
@@ -106,6 +121,7 @@ func (uc *ShopAdminUseCase) Assign(ctx context.Context, houseGID int32, targetUs
 }
 
 func (uc *ShopAdminUseCase) Revoke(ctx context.Context, houseGID int32, targetUserID int32) error {
+	auditx.SetTarget(ctx, "user", targetUserID)
 	if targetUserID <= 0 {
 		return errors.New("invalid user_id")
 	}
@@ -143,6 +159,10 @@ func (uc *ShopAdminUseCase) Revoke(ctx context.Context, houseGID int32, targetUs
 	if err := uc.repo.Revoke(ctx, actualHouseGID, targetUserID); err != nil {
 		return err
 	}
+	if err := uc.scopeRepo.RevokeHouse(ctx, targetUserID, actualHouseGID); err != nil {
+		uc.log.Errorf("撤销店铺角色失败: %v", err)
+	}
+	uc.rbac.InvalidateUser(ctx, targetUserID)
 
 	// 3. 更新用户角色为普通用户
 	user, err := uc.basicUserRepo.SelectOneByPK(ctx, targetUserID)
@@ -157,6 +177,35 @@ func (uc *ShopAdminUseCase) Revoke(ctx context.Context, houseGID int32, targetUs
 	return nil
 }
 
+// syncScopeRole 按店铺管理员身份（admin/operator）授予对应的作用域角色，并移除另一种
+func (uc *ShopAdminUseCase) syncScopeRole(ctx context.Context, houseGID, userID int32, role string) error {
+	grant, drop := basicModel.RoleCodeShopAdmin, basicModel.RoleCodeShopOperator
+	if role == model.AdminRoleOperator {
+		grant, drop = drop, grant
+	}
+	dropID, err := uc.scopeRepo.RoleIDByCode(ctx, drop)
+	if err != nil {
+		return err
+	}
+	if dropID > 0 {
+		if err := uc.scopeRepo.Revoke(ctx, userID, dropID, houseGID, 0); err != nil {
+			return err
+		}
+	}
+	grantID, err := uc.scopeRepo.RoleIDByCode(ctx, grant)
+	if err != nil {
+		return err
+	}
+	if grantID == 0 {
+		return errors.Errorf("role %s not found", grant)
+	}
+	if err := uc.scopeRepo.Grant(ctx, &basicModel.BasicUserScopeRole{UserID: userID, RoleID: grantID, HouseGID: houseGID}); err != nil {
+		return err
+	}
+	uc.rbac.InvalidateUser(ctx, userID)
+	return nil
+}
+
 func (uc *ShopAdminUseCase) IsAdmin(ctx context.Context, houseGID int32, userID int32) (bool, error) {
 	return uc.repo.Exists(ctx, houseGID, userID)
 }
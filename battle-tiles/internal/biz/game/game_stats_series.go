@@ -0,0 +1,139 @@
+package game
+
+import (
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/dal/resp"
+	"battle-tiles/pkg/utils/timeutil"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 时间序列分桶粒度
+const (
+	SeriesBucketHour = "hour"
+	SeriesBucketDay  = "day"
+	SeriesBucketWeek = "week"
+)
+
+// 各粒度允许的最大桶数，避免一次拉取过长区间
+var seriesMaxBuckets = map[string]int{
+	SeriesBucketHour: 24 * 31,
+	SeriesBucketDay:  366,
+	SeriesBucketWeek: 104,
+}
+
+// Series 按 hour/day/week 分桶的时间序列（局数/运费/输赢流水/活跃玩家/上下分），无数据的桶补零
+func (uc *GameStatsUseCase) Series(ctx context.Context, bucket string, scope repo.SeriesScope, start, end time.Time) (*resp.StatsSeriesVO, error) {
+	if scope.HouseGID <= 0 {
+		return nil, errors.New("invalid house_gid")
+	}
+	if !end.After(start) {
+		return nil, errors.New("end_time must be after start_time")
+	}
+	buckets, err := seriesBuckets(bucket, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	battles, err := uc.repo.BattleSeries(ctx, bucket, scope, buckets[0], end)
+	if err != nil {
+		return nil, err
+	}
+	ledgers, err := uc.repo.LedgerSeries(ctx, bucket, scope, buckets[0], end)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := seriesKeyLayout(bucket)
+	points := make([]resp.StatsSeriesPointVO, len(buckets))
+	index := make(map[string]int, len(buckets))
+	for i, t := range buckets {
+		points[i] = resp.StatsSeriesPointVO{Time: t, Label: seriesLabel(bucket, t)}
+		index[t.Format(layout)] = i
+	}
+	for _, row := range battles {
+		if i, ok := index[row.Bucket.In(start.Location()).Format(layout)]; ok {
+			points[i].Games = row.Games
+			points[i].Fee = row.Fee
+			points[i].ScoreVolume = row.ScoreVolume
+			points[i].ActivePlayers = row.ActivePlayers
+		}
+	}
+	for _, row := range ledgers {
+		if i, ok := index[row.Bucket.In(start.Location()).Format(layout)]; ok {
+			points[i].Deposit = row.Deposit
+			points[i].Withdraw = row.Withdraw
+		}
+	}
+
+	return &resp.StatsSeriesVO{
+		HouseGID:   scope.HouseGID,
+		GroupID:    scope.GroupID,
+		MemberID:   scope.MemberID,
+		Bucket:     bucket,
+		RangeStart: buckets[0],
+		RangeEnd:   end,
+		Points:     points,
+	}, nil
+}
+
+// seriesBuckets 生成 [start, end) 内各桶的起始时间，start 向下对齐到桶边界
+func seriesBuckets(bucket string, start, end time.Time) ([]time.Time, error) {
+	var (
+		out []time.Time
+		err error
+	)
+	switch bucket {
+	case SeriesBucketHour:
+		out, err = timeutil.GenerateIntervalVal4(start.Truncate(time.Hour), end, 1, timeutil.StepTypeHour)
+	case SeriesBucketDay, SeriesBucketWeek:
+		step, aligned := 1, time.Time{}
+		if bucket == SeriesBucketDay {
+			aligned, _ = dayRange(start)
+		} else {
+			step = 7
+			aligned, _ = weekRange(start)
+		}
+		var days []int64
+		if days, err = timeutil.GenerateIntervalVal2(aligned, end, step, timeutil.StepTypeDay); err == nil {
+			out = make([]time.Time, 0, len(days))
+			for _, d := range days {
+				t, perr := time.ParseInLocation(timeutil.CSTDateDayLayout, strconv.FormatInt(d, 10), start.Location())
+				if perr != nil {
+					return nil, perr
+				}
+				out = append(out, t)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bucket: %s", bucket)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("empty range")
+	}
+	if len(out) > seriesMaxBuckets[bucket] {
+		return nil, fmt.Errorf("too many %s buckets: %d > %d", bucket, len(out), seriesMaxBuckets[bucket])
+	}
+	return out, nil
+}
+
+func seriesKeyLayout(bucket string) string {
+	if bucket == SeriesBucketHour {
+		return timeutil.CSTDateHourLayout
+	}
+	return timeutil.CSTDateDayLayout
+}
+
+func seriesLabel(bucket string, t time.Time) string {
+	if bucket == SeriesBucketHour {
+		return t.Format("2006-01-02 15:00")
+	}
+	return t.Format(timeutil.CSTDateLayout)
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type fakeSeriesStats struct {
+	repo.GameStatsRepo
+	battles []repo.BattleSeriesRow
+	ledgers []repo.LedgerSeriesRow
+	start   time.Time
+}
+
+func (r *fakeSeriesStats) BattleSeries(_ context.Context, _ string, _ repo.SeriesScope, start, _ time.Time) ([]repo.BattleSeriesRow, error) {
+	r.start = start
+	return r.battles, nil
+}
+
+func (r *fakeSeriesStats) LedgerSeries(context.Context, string, repo.SeriesScope, time.Time, time.Time) ([]repo.LedgerSeriesRow, error) {
+	return r.ledgers, nil
+}
+
+func TestSeriesBucketsInCallerZone(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	// 数据库按调用方时区截断后返回 UTC 时间点：东八区 3 日 0 点 = UTC 2 日 16 点
+	r := &fakeSeriesStats{
+		battles: []repo.BattleSeriesRow{{Bucket: time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC), Games: 5, Fee: 10}},
+		ledgers: []repo.LedgerSeriesRow{{Bucket: time.Date(2026, 3, 3, 16, 0, 0, 0, time.UTC), Deposit: 100}},
+	}
+	uc := NewGameStatsUseCase(r, log.DefaultLogger)
+
+	vo, err := uc.Series(context.Background(), SeriesBucketDay, repo.SeriesScope{HouseGID: 1},
+		time.Date(2026, 3, 2, 9, 30, 0, 0, cst), time.Date(2026, 3, 5, 0, 0, 0, 0, cst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.start.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, cst)) || r.start.Location() != cst {
+		t.Fatalf("repo start = %v", r.start)
+	}
+	if len(vo.Points) != 3 {
+		t.Fatalf("points = %d, want 3", len(vo.Points))
+	}
+	want := []struct{ games, deposit int64 }{{0, 0}, {5, 0}, {0, 100}}
+	for i, p := range vo.Points {
+		if p.Games != want[i].games || p.Deposit != want[i].deposit {
+			t.Fatalf("point %d (%s) = games %d deposit %d", i, p.Label, p.Games, p.Deposit)
+		}
+	}
+	if vo.Points[1].Label != "2026-03-03" {
+		t.Fatalf("label = %s", vo.Points[1].Label)
+	}
+}
+
+func TestSeriesWeekAlignedToMonday(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	uc := NewGameStatsUseCase(&fakeSeriesStats{}, log.DefaultLogger)
+	// 2026-03-04 为周三
+	vo, err := uc.Series(context.Background(), SeriesBucketWeek, repo.SeriesScope{HouseGID: 1},
+		time.Date(2026, 3, 4, 12, 0, 0, 0, cst), time.Date(2026, 3, 20, 0, 0, 0, 0, cst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vo.Points) != 3 || !vo.Points[0].Time.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, cst)) {
+		t.Fatalf("week points = %+v", vo.Points)
+	}
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"battle-tiles/internal/conf"
+	cloudRepo "battle-tiles/internal/dal/repo/cloud"
+	plazautils "battle-tiles/internal/utils/plaza"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/patrickmn/go-cache"
+)
+
+// 平台覆盖的缓存时间，修改 base_platform 后最迟这么久生效
+const groOverrideTTL = time.Minute
+
+// GroResolver 解析 GroService 地址与私钥：global.game.gro 为默认值，base_platform 中非空的字段按平台覆盖；
+// 所有调用共用一个带重试与熔断的 HTTPDoer
+type GroResolver struct {
+	def       *conf.Global_Game_Gro
+	platforms cloudRepo.BasePlatformRepo
+	doer      *plazautils.ResilientDoer
+	overrides *cache.Cache // db key -> groOverride
+	log       *log.Helper
+}
+
+type groOverride struct {
+	battleURL, queryURL, privateKey string
+}
+
+func NewGroResolver(global *conf.Global, platforms cloudRepo.BasePlatformRepo, logger log.Logger) *GroResolver {
+	def := global.GetGame().GetGro()
+	if def == nil {
+		def = &conf.Global_Game_Gro{}
+	}
+	return &GroResolver{
+		def:       def,
+		platforms: platforms,
+		doer: plazautils.NewResilientDoer(plazautils.DoerOptions{
+			Timeout:         time.Duration(def.GetTimeoutSeconds()) * time.Second,
+			Retries:         int(def.GetRetries()),
+			BreakerFailures: int(def.GetBreakerFailures()),
+			BreakerCooldown: time.Duration(def.GetBreakerCooldownSeconds()) * time.Second,
+		}),
+		overrides: cache.New(groOverrideTTL, 2*groOverrideTTL),
+		log:       log.NewHelper(log.With(logger, "module", "biz/gro")),
+	}
+}
+
+// Doer 共用的 HTTP 客户端
+func (r *GroResolver) Doer() plazautils.HTTPDoer { return r.doer }
+
+// Battle 战绩同步接口（GetGroupNewBattleInfo / getgroupbattleinfo 回填）
+func (r *GroResolver) Battle(ctx context.Context) plazautils.GroEndpoint {
+	o := r.override(ctx)
+	return plazautils.GroEndpoint{
+		BaseURL:    orDefault(o.battleURL, r.def.GetBattleUrl()),
+		PrivateKey: orDefault(o.privateKey, r.def.GetPrivateKey()),
+	}
+}
+
+// Query 后台战绩查询/导出页
+func (r *GroResolver) Query(ctx context.Context) plazautils.GroEndpoint {
+	o := r.override(ctx)
+	return plazautils.GroEndpoint{
+		BaseURL:    orDefault(o.queryURL, r.def.GetQueryUrl()),
+		PrivateKey: orDefault(o.privateKey, r.def.GetPrivateKey()),
+	}
+}
+
+func (r *GroResolver) override(ctx context.Context) groOverride {
+	key := pdb.GetDBKeyFromCtx(ctx)
+	if v, ok := r.overrides.Get(key); ok {
+		return v.(groOverride)
+	}
+	var o groOverride
+	p, err := r.platforms.GetPlatformInfo(ctx)
+	if err != nil {
+		// 查不到平台时用默认配置，同样缓存，避免每次请求都查库
+		r.log.Debugf("load gro override for %q failed: %v", key, err)
+	} else {
+		if p.GroBattleURL != nil {
+			o.battleURL = *p.GroBattleURL
+		}
+		if p.GroQueryURL != nil {
+			o.queryURL = *p.GroQueryURL
+		}
+		if p.GroPrivateKey != nil {
+			o.privateKey = *p.GroPrivateKey
+		}
+	}
+	r.overrides.SetDefault(key, o)
+	return o
+}
+
+func orDefault(v, def string) string {
+	if v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,223 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// commissionRateBase 抽成比例基数（万分比）
+const commissionRateBase = 10000
+
+// ErrSettlementForbidden 非该店铺管理员操作结算单/圈子抽成
+var ErrSettlementForbidden = errors.New("无权操作该店铺的结算单")
+
+// GroupSettlementUseCase 圈主结算单：按周期汇总各圈战绩、运费与分运结转，计算圈主抽成
+type GroupSettlementUseCase struct {
+	repo      repo.GroupSettlementRepo
+	groupRepo repo.ShopGroupRepo
+	feeRepo   repo.FeeSettleRepo
+	adminRepo repo.GameShopAdminRepo
+	log       *log.Helper
+}
+
+func NewGroupSettlementUseCase(
+	r repo.GroupSettlementRepo,
+	groupRepo repo.ShopGroupRepo,
+	feeRepo repo.FeeSettleRepo,
+	adminRepo repo.GameShopAdminRepo,
+	logger log.Logger,
+) *GroupSettlementUseCase {
+	return &GroupSettlementUseCase{
+		repo:      r,
+		groupRepo: groupRepo,
+		feeRepo:   feeRepo,
+		adminRepo: adminRepo,
+		log:       log.NewHelper(log.With(logger, "module", "usecase/group_settlement")),
+	}
+}
+
+// Generate 生成（或重算）店铺下所有圈在 [start, end) 的结算单。
+// 已冻结/已打款的结算单保持不变，直接返回原单。
+func (uc *GroupSettlementUseCase) Generate(ctx context.Context, opUser int32, superAdmin bool, houseGID int32, start, end *time.Time) ([]*model.GameGroupSettlement, error) {
+	if houseGID <= 0 {
+		return nil, errors.New("invalid house_gid")
+	}
+	if err := uc.authorize(ctx, opUser, superAdmin, houseGID); err != nil {
+		return nil, err
+	}
+	s, e := settlementRange(start, end)
+	if !e.After(s) {
+		return nil, errors.New("invalid period: end must be after start")
+	}
+
+	groups, err := uc.groupRepo.ListByHouse(ctx, houseGID)
+	if err != nil {
+		return nil, err
+	}
+	aggs, err := uc.repo.AggregateBattleByGroup(ctx, houseGID, s, e)
+	if err != nil {
+		return nil, err
+	}
+	aggByGroup := make(map[int32]repo.GroupBattleAgg, len(aggs))
+	for _, a := range aggs {
+		aggByGroup[a.GroupID] = a
+	}
+	// game_fee_settle 中 play_group=group_<id>，金额为该圈承担的运费 + 分运结转（正数=支出，负数=收入）
+	sums, err := uc.feeRepo.ListGroupSums(ctx, houseGID, s, e)
+	if err != nil {
+		return nil, err
+	}
+	settleByGroup := make(map[string]int64, len(sums))
+	for _, v := range sums {
+		settleByGroup[v.PlayGroup] = v.Sum
+	}
+
+	out := make([]*model.GameGroupSettlement, 0, len(groups))
+	for _, g := range groups {
+		existing, err := uc.repo.GetByPeriod(ctx, houseGID, g.Id, s, e)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.Status != model.SettlementStatusDraft {
+			out = append(out, existing)
+			continue
+		}
+
+		agg := aggByGroup[g.Id]
+		st := &model.GameGroupSettlement{
+			HouseGID:         houseGID,
+			GroupID:          g.Id,
+			GroupName:        g.GroupName,
+			AdminUserID:      g.AdminUserID,
+			PeriodStart:      s,
+			PeriodEnd:        e,
+			Games:            agg.Games,
+			PlayerGames:      agg.PlayerGames,
+			ScoreNet:         agg.ScoreNet,
+			ScoreFlow:        agg.ScoreFlow,
+			FeeTotal:         agg.FeeTotal,
+			FeeShareNet:      settleByGroup[fmt.Sprintf("group_%d", g.Id)] - agg.FeeTotal,
+			CommissionRate:   g.CommissionRate,
+			CommissionAmount: agg.FeeTotal * int64(g.CommissionRate) / commissionRateBase,
+			Status:           model.SettlementStatusDraft,
+			CreatedBy:        opUser,
+		}
+		if err := uc.repo.Upsert(ctx, st); err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	uc.log.Infof("generated %d group settlements house=%d period=[%s, %s)", len(out), houseGID, s.Format(time.DateTime), e.Format(time.DateTime))
+	return out, nil
+}
+
+// List 分页查询结算单；非超管必须指定自己管理的店铺
+func (uc *GroupSettlementUseCase) List(ctx context.Context, opUser int32, superAdmin bool, f repo.SettlementFilter, page, size int32) ([]*model.GameGroupSettlement, int64, error) {
+	if !superAdmin && f.HouseGID <= 0 {
+		return nil, 0, errors.New("house_gid is required")
+	}
+	if err := uc.authorize(ctx, opUser, superAdmin, f.HouseGID); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.List(ctx, f, page, size)
+}
+
+// Get 获取结算单
+func (uc *GroupSettlementUseCase) Get(ctx context.Context, opUser int32, superAdmin bool, id int32) (*model.GameGroupSettlement, error) {
+	st, err := uc.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("结算单不存在")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, opUser, superAdmin, st.HouseGID); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Freeze 冻结结算单（草稿 -> 已冻结），冻结后不再随战绩重算
+func (uc *GroupSettlementUseCase) Freeze(ctx context.Context, opUser int32, superAdmin bool, id int32) (*model.GameGroupSettlement, error) {
+	return uc.transit(ctx, opUser, superAdmin, id, model.SettlementStatusDraft, model.SettlementStatusFrozen)
+}
+
+// MarkPaid 标记已打款（已冻结 -> 已打款）
+func (uc *GroupSettlementUseCase) MarkPaid(ctx context.Context, opUser int32, superAdmin bool, id int32) (*model.GameGroupSettlement, error) {
+	return uc.transit(ctx, opUser, superAdmin, id, model.SettlementStatusFrozen, model.SettlementStatusPaid)
+}
+
+// SetCommissionRate 设置圈主抽成比例（万分比，0~10000），对之后生成的草稿生效
+func (uc *GroupSettlementUseCase) SetCommissionRate(ctx context.Context, opUser int32, superAdmin bool, groupID, rate int32) error {
+	if rate < 0 || rate > commissionRateBase {
+		return fmt.Errorf("commission_rate must be within [0, %d]", commissionRateBase)
+	}
+	g, err := uc.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("圈子不存在")
+		}
+		return err
+	}
+	if err := uc.authorize(ctx, opUser, superAdmin, g.HouseGID); err != nil {
+		return err
+	}
+	return uc.groupRepo.UpdateCommissionRate(ctx, groupID, rate)
+}
+
+// authorize 非超管须为该店铺的管理员
+func (uc *GroupSettlementUseCase) authorize(ctx context.Context, opUser int32, superAdmin bool, houseGID int32) error {
+	if superAdmin {
+		return nil
+	}
+	ok, err := uc.adminRepo.Exists(ctx, houseGID, opUser)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSettlementForbidden
+	}
+	return nil
+}
+
+func (uc *GroupSettlementUseCase) transit(ctx context.Context, opUser int32, superAdmin bool, id, from, to int32) (*model.GameGroupSettlement, error) {
+	if _, err := uc.Get(ctx, opUser, superAdmin, id); err != nil {
+		return nil, err
+	}
+	ok, err := uc.repo.UpdateStatus(ctx, id, from, to, opUser, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("结算单不存在或状态不允许该操作（需为状态 %d）", from)
+	}
+	return uc.repo.GetByID(ctx, id)
+}
+
+// settlementRange 结算周期，默认上周 [上周一 00:00, 本周一 00:00)
+func settlementRange(start, end *time.Time) (time.Time, time.Time) {
+	if start != nil && end != nil {
+		return *start, *end
+	}
+	thisMonday, _ := weekRange(time.Now())
+	return thisMonday.AddDate(0, 0, -7), thisMonday
+}
+
+// weekRange 自然周 [本周一 00:00, 下周一 00:00)
+func weekRange(now time.Time) (start, end time.Time) {
+	todayStart, _ := dayRange(now)
+	weekday := int(todayStart.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	start = todayStart.AddDate(0, 0, 1-weekday)
+	end = start.AddDate(0, 0, 7)
+	return
+}
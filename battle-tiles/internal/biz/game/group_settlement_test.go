@@ -0,0 +1,98 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type fakeSettlementRepo struct {
+	repo.GroupSettlementRepo
+	items map[int32]*model.GameGroupSettlement
+}
+
+func (r *fakeSettlementRepo) GetByID(_ context.Context, id int32) (*model.GameGroupSettlement, error) {
+	st, ok := r.items[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return st, nil
+}
+
+func (r *fakeSettlementRepo) UpdateStatus(_ context.Context, id int32, from, to int32, opUser int32, _ time.Time) (bool, error) {
+	st, ok := r.items[id]
+	if !ok || st.Status != from {
+		return false, nil
+	}
+	st.Status = to
+	return true, nil
+}
+
+type fakeSettlementGroups struct {
+	repo.ShopGroupRepo
+	groups map[int32]*model.GameShopGroup
+}
+
+func (r *fakeSettlementGroups) GetByID(_ context.Context, id int32) (*model.GameShopGroup, error) {
+	return r.groups[id], nil
+}
+
+func (r *fakeSettlementGroups) UpdateCommissionRate(_ context.Context, id int32, rate int32) error {
+	r.groups[id].CommissionRate = rate
+	return nil
+}
+
+// fakeShopAdmins houseGID -> 管理员 userID
+type fakeShopAdmins struct {
+	repo.GameShopAdminRepo
+	admins map[int32]int32
+}
+
+func (r *fakeShopAdmins) Exists(_ context.Context, houseGID int32, userID int32) (bool, error) {
+	return r.admins[houseGID] == userID, nil
+}
+
+func TestGroupSettlementHouseScope(t *testing.T) {
+	ctx := context.Background()
+	sts := &fakeSettlementRepo{items: map[int32]*model.GameGroupSettlement{
+		1: {Id: 1, HouseGID: 100, GroupID: 11, Status: model.SettlementStatusDraft},
+	}}
+	groups := &fakeSettlementGroups{groups: map[int32]*model.GameShopGroup{11: {Id: 11, HouseGID: 100}}}
+	uc := NewGroupSettlementUseCase(sts, groups, nil, &fakeShopAdmins{admins: map[int32]int32{100: 7, 200: 8}}, log.DefaultLogger)
+
+	// 其他店铺的管理员不能查看、冻结或改抽成
+	if _, err := uc.Get(ctx, 8, false, 1); !errors.Is(err, ErrSettlementForbidden) {
+		t.Fatalf("get by other house admin: %v", err)
+	}
+	if _, err := uc.Freeze(ctx, 8, false, 1); !errors.Is(err, ErrSettlementForbidden) {
+		t.Fatalf("freeze by other house admin: %v", err)
+	}
+	if err := uc.SetCommissionRate(ctx, 8, false, 11, 500); !errors.Is(err, ErrSettlementForbidden) {
+		t.Fatalf("commission by other house admin: %v", err)
+	}
+	if _, _, err := uc.List(ctx, 8, false, repo.SettlementFilter{}, 1, 20); err == nil {
+		t.Fatal("list without house_gid should be rejected for non-super")
+	}
+	if sts.items[1].Status != model.SettlementStatusDraft || groups.groups[11].CommissionRate != 0 {
+		t.Fatal("rejected operations must not change state")
+	}
+
+	// 本店管理员与超管可以
+	if _, err := uc.Freeze(ctx, 7, false, 1); err != nil {
+		t.Fatalf("freeze by house admin: %v", err)
+	}
+	if _, err := uc.MarkPaid(ctx, 1, true, 1); err != nil {
+		t.Fatalf("mark paid by super admin: %v", err)
+	}
+	if err := uc.SetCommissionRate(ctx, 7, false, 11, 500); err != nil || groups.groups[11].CommissionRate != 500 {
+		t.Fatalf("commission by house admin: %v", err)
+	}
+	if sts.items[1].Status != model.SettlementStatusPaid {
+		t.Fatalf("status = %d", sts.items[1].Status)
+	}
+}
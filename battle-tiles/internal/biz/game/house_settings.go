@@ -3,6 +3,7 @@ package game
 import (
 	model "battle-tiles/internal/dal/model/game"
 	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/plugin/auditx"
 	"context"
 	"time"
 
@@ -24,15 +25,38 @@ func (uc *HouseSettingsUseCase) Get(ctx context.Context, houseGID int32) (*model
 }
 
 func (uc *HouseSettingsUseCase) SetFees(ctx context.Context, opUser int32, houseGID int32, feesJSON string) error {
-	return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, FeesJSON: feesJSON, UpdatedBy: opUser})
+	return auditHouseSettings(ctx, uc.repo, houseGID, func() error {
+		return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, FeesJSON: feesJSON, UpdatedBy: opUser})
+	})
 }
 
 func (uc *HouseSettingsUseCase) SetShareFee(ctx context.Context, opUser int32, houseGID int32, share bool) error {
-	return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, ShareFee: share, UpdatedBy: opUser})
+	return auditHouseSettings(ctx, uc.repo, houseGID, func() error {
+		return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, ShareFee: share, UpdatedBy: opUser})
+	})
 }
 
 func (uc *HouseSettingsUseCase) SetPushCredit(ctx context.Context, opUser int32, houseGID int32, credit int32) error {
-	return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, PushCredit: credit, UpdatedBy: opUser})
+	return auditHouseSettings(ctx, uc.repo, houseGID, func() error {
+		return uc.repo.Upsert(ctx, &model.GameHouseSettings{HouseGID: houseGID, PushCredit: credit, UpdatedBy: opUser})
+	})
+}
+
+// auditHouseSettings 执行店铺设置变更，并把变更前后的设置记入当前请求的审计（无审计上下文时不额外查询）
+func auditHouseSettings(ctx context.Context, settings repo.HouseSettingsRepo, houseGID int32, update func() error) error {
+	if auditx.FromContext(ctx) == nil {
+		return update()
+	}
+	before, _ := settings.Get(ctx, houseGID)
+	if err := update(); err != nil {
+		return err
+	}
+	after, _ := settings.Get(ctx, houseGID)
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "house_settings", houseGID)
+	auditx.SetBefore(ctx, before)
+	auditx.SetAfter(ctx, after)
+	return nil
 }
 
 // ---- Fee Settle ----
@@ -0,0 +1,72 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/plugin/auditx"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeHouseSettings 按 house_gid 保存一行设置，Upsert 只覆盖本次设置的字段
+type fakeHouseSettings struct {
+	repo.HouseSettingsRepo
+	rows map[int32]model.GameHouseSettings
+}
+
+func (r *fakeHouseSettings) Get(_ context.Context, houseGID int32) (*model.GameHouseSettings, error) {
+	s, ok := r.rows[houseGID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &s, nil
+}
+
+func (r *fakeHouseSettings) Upsert(_ context.Context, in *model.GameHouseSettings) error {
+	s := r.rows[in.HouseGID]
+	s.HouseGID, s.ShareFee, s.PushCredit, s.UpdatedBy = in.HouseGID, in.ShareFee, in.PushCredit, in.UpdatedBy
+	r.rows[in.HouseGID] = s
+	return nil
+}
+
+func (r *fakeHouseSettings) UpsertDiamondAlertThreshold(_ context.Context, houseGID int32, threshold int64, opUser int32) error {
+	s := r.rows[houseGID]
+	s.HouseGID, s.DiamondAlertThreshold, s.UpdatedBy = houseGID, threshold, opUser
+	r.rows[houseGID] = s
+	return nil
+}
+
+func TestHouseSettingsAuditBeforeAfter(t *testing.T) {
+	settings := &fakeHouseSettings{rows: map[int32]model.GameHouseSettings{}}
+	uc := NewHouseSettingsUseCase(settings, nil, log.DefaultLogger)
+
+	e := &auditx.Entry{}
+	ctx := auditx.WithEntry(context.Background(), e)
+	if err := uc.SetShareFee(ctx, 7, 100, true); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := e.After.(*model.GameHouseSettings)
+	if e.HouseGID != 100 || e.TargetType != "house_settings" || e.Before.(*model.GameHouseSettings) != nil || after == nil || !after.ShareFee {
+		t.Fatalf("first change audit = %+v", e)
+	}
+
+	e = &auditx.Entry{}
+	ctx = auditx.WithEntry(context.Background(), e)
+	diamond := NewDiamondUseCase(nil, settings, nil, nil, log.DefaultLogger)
+	if err := diamond.SetThreshold(ctx, 7, 100, 500); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := e.Before.(*model.GameHouseSettings)
+	after, _ = e.After.(*model.GameHouseSettings)
+	if before == nil || before.DiamondAlertThreshold != 0 || after == nil || after.DiamondAlertThreshold != 500 || !after.ShareFee {
+		t.Fatalf("threshold audit before=%+v after=%+v", before, after)
+	}
+
+	// 无审计上下文时照常保存
+	if err := uc.SetPushCredit(context.Background(), 7, 100, 30); err != nil || settings.rows[100].PushCredit != 30 {
+		t.Fatalf("push credit = %d, err = %v", settings.rows[100].PushCredit, err)
+	}
+}
@@ -0,0 +1,271 @@
+package game
+
+import (
+	"battle-tiles/internal/consts"
+	repo "battle-tiles/internal/dal/repo/game"
+	resp "battle-tiles/internal/dal/resp"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/patrickmn/go-cache"
+)
+
+// 排行维度
+const (
+	RankMetricWin     = "win"     // 赢分最多
+	RankMetricLose    = "lose"    // 输分最多
+	RankMetricGames   = "games"   // 局数最多
+	RankMetricFee     = "fee"     // 运费贡献最高
+	RankMetricWinRate = "winrate" // 胜率最高
+)
+
+const (
+	leaderboardTTL          = 30 * time.Minute // 榜单缓存时长（访问/入库时续期）
+	leaderboardMaxStaleness = time.Minute      // 未收到入库通知时的最长复用时间
+	winRateDefaultMinGames  = 5
+)
+
+// LeaderboardQuery 排行榜查询条件
+type LeaderboardQuery struct {
+	HouseGID int32
+	GroupID  *int32
+	KindID   *int32
+	Metric   string
+	Period   string // today|yesterday|thisweek|custom
+	Start    *time.Time
+	End      *time.Time
+	Limit    int
+	MinGames int64
+}
+
+// LeaderboardUseCase 基于 game_battle_record 的排行榜。
+// 同一 (店铺, 圈, 玩法, 时间窗口) 的玩家聚合缓存在内存中，记录已聚合到的最大战绩ID作为水位；
+// 战绩入库后只增量聚合水位之后的新记录，所有排行维度共享同一份聚合。
+type LeaderboardUseCase struct {
+	repo   repo.LeaderboardRepo
+	boards *cache.Cache
+	log    *log.Helper
+}
+
+func NewLeaderboardUseCase(r repo.LeaderboardRepo, logger log.Logger) *LeaderboardUseCase {
+	return &LeaderboardUseCase{
+		repo:   r,
+		boards: cache.New(leaderboardTTL, 2*leaderboardTTL),
+		log:    log.NewHelper(log.With(logger, "module", "usecase/leaderboard")),
+	}
+}
+
+// rankBoard 一个过滤条件下的玩家聚合
+type rankBoard struct {
+	mu          sync.Mutex
+	filter      repo.LeaderboardFilter
+	watermark   int32
+	players     map[int32]*repo.PlayerBattleAgg
+	refreshedAt time.Time
+}
+
+// refresh 增量聚合水位之后的新战绩
+func (b *rankBoard) refresh(ctx context.Context, r repo.LeaderboardRepo) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	maxID, err := r.MaxRecordID(ctx, b.filter)
+	if err != nil {
+		return err
+	}
+	if maxID > b.watermark {
+		rows, err := r.AggregatePlayers(ctx, b.filter, b.watermark, maxID)
+		if err != nil {
+			return err
+		}
+		for i := range rows {
+			row := rows[i]
+			p, ok := b.players[row.PlayerGameID]
+			if !ok {
+				b.players[row.PlayerGameID] = &row
+				continue
+			}
+			p.Games += row.Games
+			p.Wins += row.Wins
+			p.Score += row.Score
+			p.Fee += row.Fee
+			if row.PlayerGameName != "" {
+				p.PlayerGameName = row.PlayerGameName
+			}
+		}
+		b.watermark = maxID
+	}
+	b.refreshedAt = time.Now()
+	return nil
+}
+
+// Query 查询排行榜
+func (uc *LeaderboardUseCase) Query(ctx context.Context, q LeaderboardQuery) (*resp.LeaderboardVO, error) {
+	if q.HouseGID <= 0 {
+		return nil, errors.New("invalid house_gid")
+	}
+	start, end, err := leaderboardRange(q.Period, q.Start, q.End, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	f := repo.LeaderboardFilter{HouseGID: q.HouseGID, GroupID: q.GroupID, KindID: q.KindID, Start: start, End: end}
+	key := leaderboardKey(f)
+
+	var b *rankBoard
+	if v, ok := uc.boards.Get(key); ok {
+		b = v.(*rankBoard)
+	} else {
+		b = &rankBoard{filter: f, players: make(map[int32]*repo.PlayerBattleAgg)}
+	}
+	b.mu.Lock()
+	stale := time.Since(b.refreshedAt) > leaderboardMaxStaleness
+	b.mu.Unlock()
+	if stale {
+		if err := b.refresh(ctx, uc.repo); err != nil {
+			return nil, err
+		}
+	}
+	uc.boards.Set(key, b, cache.DefaultExpiration)
+
+	out := &resp.LeaderboardVO{
+		HouseGID:   q.HouseGID,
+		GroupID:    q.GroupID,
+		KindID:     q.KindID,
+		Metric:     q.Metric,
+		RangeStart: start,
+		RangeEnd:   end,
+	}
+	if q.KindID != nil {
+		out.KindName = consts.GetKindName(int(*q.KindID))
+	}
+	b.mu.Lock()
+	out.RefreshedAt = b.refreshedAt
+	out.Items = rankPlayers(b.players, q.Metric, q.Limit, q.MinGames)
+	b.mu.Unlock()
+	return out, nil
+}
+
+// OnBattlesIngested 战绩入库后调用：增量刷新该店铺下已缓存的榜单
+func (uc *LeaderboardUseCase) OnBattlesIngested(ctx context.Context, houseGID int32) {
+	if uc == nil {
+		return
+	}
+	for key, item := range uc.boards.Items() {
+		b := item.Object.(*rankBoard)
+		if b.filter.HouseGID != houseGID {
+			continue
+		}
+		if err := b.refresh(ctx, uc.repo); err != nil {
+			uc.log.Warnf("refresh leaderboard %s failed: %v", key, err)
+		}
+	}
+}
+
+func rankPlayers(players map[int32]*repo.PlayerBattleAgg, metric string, limit int, minGames int64) []resp.LeaderboardItemVO {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if metric == RankMetricWinRate && minGames <= 0 {
+		minGames = winRateDefaultMinGames
+	}
+
+	items := make([]resp.LeaderboardItemVO, 0, len(players))
+	for _, p := range players {
+		if p.Games < minGames {
+			continue
+		}
+		// 输分榜只看净输家，赢分榜只看净赢家
+		if (metric == RankMetricWin && p.Score <= 0) || (metric == RankMetricLose && p.Score >= 0) {
+			continue
+		}
+		it := resp.LeaderboardItemVO{
+			PlayerGameID:   p.PlayerGameID,
+			PlayerGameName: p.PlayerGameName,
+			Games:          p.Games,
+			Wins:           p.Wins,
+			Score:          p.Score,
+			Fee:            p.Fee,
+		}
+		if p.Games > 0 {
+			it.WinRate = float64(p.Wins) / float64(p.Games)
+		}
+		items = append(items, it)
+	}
+
+	less := func(a, b resp.LeaderboardItemVO) bool {
+		switch metric {
+		case RankMetricWin:
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+		case RankMetricLose:
+			if a.Score != b.Score {
+				return a.Score < b.Score
+			}
+		case RankMetricGames:
+			if a.Games != b.Games {
+				return a.Games > b.Games
+			}
+		case RankMetricFee:
+			if a.Fee != b.Fee {
+				return a.Fee > b.Fee
+			}
+		case RankMetricWinRate:
+			if a.WinRate != b.WinRate {
+				return a.WinRate > b.WinRate
+			}
+		}
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		return a.PlayerGameID < b.PlayerGameID
+	}
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	for i := range items {
+		items[i].Rank = i + 1
+	}
+	return items
+}
+
+// leaderboardRange 解析时间窗口 [start, end)
+func leaderboardRange(period string, start, end *time.Time, now time.Time) (time.Time, time.Time, error) {
+	todayStart, tomorrowStart := dayRange(now)
+	switch period {
+	case "today":
+		return todayStart, tomorrowStart, nil
+	case "yesterday":
+		return todayStart.AddDate(0, 0, -1), todayStart, nil
+	case "thisweek":
+		s, e := weekRange(now)
+		return s, e, nil
+	case "custom":
+		if start == nil || end == nil {
+			return time.Time{}, time.Time{}, errors.New("custom period requires start_time and end_time")
+		}
+		if !end.After(*start) {
+			return time.Time{}, time.Time{}, errors.New("end_time must be after start_time")
+		}
+		return *start, *end, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unsupported period: %s", period)
+}
+
+func leaderboardKey(f repo.LeaderboardFilter) string {
+	group, kind := int32(-1), int32(-1)
+	if f.GroupID != nil {
+		group = *f.GroupID
+	}
+	if f.KindID != nil {
+		kind = *f.KindID
+	}
+	return fmt.Sprintf("%d:%d:%d:%d:%d", f.HouseGID, group, kind, f.Start.Unix(), f.End.Unix())
+}
@@ -0,0 +1,110 @@
+package game
+
+import (
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeLeaderboardRepo 按战绩ID保存逐条的玩家结果，聚合 (afterID, uptoID] 区间
+type fakeLeaderboardRepo struct {
+	rows  map[int32][]repo.PlayerBattleAgg
+	calls int
+}
+
+func (r *fakeLeaderboardRepo) MaxRecordID(context.Context, repo.LeaderboardFilter) (int32, error) {
+	var max int32
+	for id := range r.rows {
+		max = maxInt32(max, id)
+	}
+	return max, nil
+}
+
+func (r *fakeLeaderboardRepo) AggregatePlayers(_ context.Context, _ repo.LeaderboardFilter, afterID, uptoID int32) ([]repo.PlayerBattleAgg, error) {
+	r.calls++
+	agg := map[int32]*repo.PlayerBattleAgg{}
+	for id, rows := range r.rows {
+		if id <= afterID || id > uptoID {
+			continue
+		}
+		for _, row := range rows {
+			p, ok := agg[row.PlayerGameID]
+			if !ok {
+				p = &repo.PlayerBattleAgg{PlayerGameID: row.PlayerGameID, PlayerGameName: row.PlayerGameName}
+				agg[row.PlayerGameID] = p
+			}
+			p.Games += row.Games
+			p.Wins += row.Wins
+			p.Score += row.Score
+			p.Fee += row.Fee
+		}
+	}
+	out := make([]repo.PlayerBattleAgg, 0, len(agg))
+	for _, p := range agg {
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func TestLeaderboardIncrementalRefresh(t *testing.T) {
+	ctx := context.Background()
+	r := &fakeLeaderboardRepo{rows: map[int32][]repo.PlayerBattleAgg{
+		1: {{PlayerGameID: 11, Games: 1, Wins: 1, Score: 30, Fee: 2}, {PlayerGameID: 12, Games: 1, Score: -30, Fee: 2}},
+		2: {{PlayerGameID: 12, Games: 1, Wins: 1, Score: 10, Fee: 1}, {PlayerGameID: 13, Games: 1, Score: -10, Fee: 1}},
+	}}
+	uc := NewLeaderboardUseCase(r, log.DefaultLogger)
+	q := LeaderboardQuery{HouseGID: 100, Metric: RankMetricWin, Period: "today"}
+
+	vo, err := uc.Query(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vo.Items) != 1 || vo.Items[0].PlayerGameID != 11 || vo.Items[0].Score != 30 {
+		t.Fatalf("initial win board = %+v", vo.Items)
+	}
+
+	// 新战绩入库后只聚合水位之后的记录，并与已有聚合合并
+	r.rows[3] = []repo.PlayerBattleAgg{{PlayerGameID: 12, Games: 1, Wins: 1, Score: 50, Fee: 3}}
+	uc.OnBattlesIngested(ctx, 100)
+	if r.calls != 2 {
+		t.Fatalf("aggregate calls = %d, want 2", r.calls)
+	}
+	vo, err = uc.Query(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同分按局数多者在前
+	if len(vo.Items) != 2 || vo.Items[0].PlayerGameID != 12 || vo.Items[0].Score != 30 || vo.Items[0].Games != 3 || vo.Items[1].PlayerGameID != 11 {
+		t.Fatalf("refreshed win board = %+v", vo.Items)
+	}
+
+	// 其他店铺的入库通知不触发刷新；同一缓存在 staleness 内复用
+	uc.OnBattlesIngested(ctx, 200)
+	if _, err = uc.Query(ctx, LeaderboardQuery{HouseGID: 100, Metric: RankMetricLose, Period: "today"}); err != nil {
+		t.Fatal(err)
+	}
+	if r.calls != 2 {
+		t.Fatalf("aggregate calls = %d, want 2", r.calls)
+	}
+}
+
+func TestRankPlayersWinRateMinGames(t *testing.T) {
+	players := map[int32]*repo.PlayerBattleAgg{
+		1: {PlayerGameID: 1, Games: 2, Wins: 2},
+		2: {PlayerGameID: 2, Games: 10, Wins: 6},
+		3: {PlayerGameID: 3, Games: 8, Wins: 6},
+	}
+	items := rankPlayers(players, RankMetricWinRate, 10, 0)
+	if len(items) != 2 || items[0].PlayerGameID != 3 || items[1].PlayerGameID != 2 || items[0].Rank != 1 {
+		t.Fatalf("winrate board = %+v", items)
+	}
+}
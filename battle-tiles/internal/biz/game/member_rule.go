@@ -3,6 +3,7 @@ package game
 import (
 	model "battle-tiles/internal/dal/model/game"
 	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/plugin/auditx"
 	"context"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -18,11 +19,28 @@ func NewMemberRuleUseCase(r repo.MemberRuleRepo, logger log.Logger) *MemberRuleU
 }
 
 func (uc *MemberRuleUseCase) SetVIP(ctx context.Context, op int32, houseGID, memberID int32, vip bool) error {
-	return uc.repo.Upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, VIP: vip, UpdatedBy: op})
+	return uc.upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, VIP: vip, UpdatedBy: op})
 }
 func (uc *MemberRuleUseCase) SetMultiGIDs(ctx context.Context, op int32, houseGID, memberID int32, allow bool) error {
-	return uc.repo.Upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, MultiGIDs: allow, UpdatedBy: op})
+	return uc.upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, MultiGIDs: allow, UpdatedBy: op})
 }
 func (uc *MemberRuleUseCase) SetTempRelease(ctx context.Context, op int32, houseGID, memberID int32, limit int32) error {
-	return uc.repo.Upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, TempRelease: limit, UpdatedBy: op})
+	return uc.upsert(ctx, &model.GameMemberRule{HouseGID: houseGID, MemberID: memberID, TempRelease: limit, UpdatedBy: op})
+}
+
+// upsert 保存成员规则，并把变更前后的规则记入当前请求的审计
+func (uc *MemberRuleUseCase) upsert(ctx context.Context, rule *model.GameMemberRule) error {
+	if auditx.FromContext(ctx) == nil {
+		return uc.repo.Upsert(ctx, rule)
+	}
+	before, _ := uc.repo.Get(ctx, rule.HouseGID, rule.MemberID)
+	if err := uc.repo.Upsert(ctx, rule); err != nil {
+		return err
+	}
+	after, _ := uc.repo.Get(ctx, rule.HouseGID, rule.MemberID)
+	auditx.SetHouse(ctx, rule.HouseGID)
+	auditx.SetTarget(ctx, "member", rule.MemberID)
+	auditx.SetBefore(ctx, before)
+	auditx.SetAfter(ctx, after)
+	return nil
 }
@@ -0,0 +1,48 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/plugin/auditx"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type fakeMemberRules struct {
+	repo.MemberRuleRepo
+	rules map[int32]model.GameMemberRule
+}
+
+func (r *fakeMemberRules) Get(_ context.Context, _, memberID int32) (*model.GameMemberRule, error) {
+	m, ok := r.rules[memberID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &m, nil
+}
+
+func (r *fakeMemberRules) Upsert(_ context.Context, in *model.GameMemberRule) error {
+	r.rules[in.MemberID] = *in
+	return nil
+}
+
+func TestMemberRuleAuditBeforeAfter(t *testing.T) {
+	rules := &fakeMemberRules{rules: map[int32]model.GameMemberRule{5: {HouseGID: 100, MemberID: 5, TempRelease: 200}}}
+	uc := NewMemberRuleUseCase(rules, log.DefaultLogger)
+
+	e := &auditx.Entry{}
+	if err := uc.SetVIP(auditx.WithEntry(context.Background(), e), 7, 100, 5, true); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := e.Before.(*model.GameMemberRule)
+	after, _ := e.After.(*model.GameMemberRule)
+	if e.HouseGID != 100 || e.TargetType != "member" || len(e.TargetIDs) != 1 || e.TargetIDs[0] != "5" {
+		t.Fatalf("audit target = %+v", e)
+	}
+	if before == nil || before.VIP || before.TempRelease != 200 || after == nil || !after.VIP || after.UpdatedBy != 7 {
+		t.Fatalf("audit before=%+v after=%+v", before, after)
+	}
+}
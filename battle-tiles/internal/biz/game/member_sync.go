@@ -0,0 +1,79 @@
+package game
+
+import (
+	repo "battle-tiles/internal/dal/repo/game"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"battle-tiles/pkg/plugin/eventx"
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// MemberSyncUseCase 以游戏端为准同步成员状态：成员列表/成员更新推送中的禁止位写回 game_member.forbid
+type MemberSyncUseCase struct {
+	members repo.GameMemberRepo
+	log     *log.Helper
+}
+
+func NewMemberSyncUseCase(members repo.GameMemberRepo, logger log.Logger) *MemberSyncUseCase {
+	return &MemberSyncUseCase{
+		members: members,
+		log:     log.NewHelper(log.With(logger, "module", "usecase/member_sync")),
+	}
+}
+
+// OnSessionMembers 全量成员列表
+func (uc *MemberSyncUseCase) OnSessionMembers(ctx context.Context, houseGID int32, list []*plazaUtils.GroupMember) {
+	var forbidden, allowed []int32
+	for _, m := range list {
+		if m == nil || m.GameID == 0 {
+			continue
+		}
+		if m.Forbidden() {
+			forbidden = append(forbidden, int32(m.GameID))
+		} else {
+			allowed = append(allowed, int32(m.GameID))
+		}
+	}
+	uc.syncForbid(ctx, houseGID, forbidden, true)
+	uc.syncForbid(ctx, houseGID, allowed, false)
+}
+
+// OnSessionMemberUpdated 单个成员更新；成员不在会话快照中（无 GameID）时等下次全量列表
+func (uc *MemberSyncUseCase) OnSessionMemberUpdated(ctx context.Context, houseGID int32, upd *plazaUtils.MemberUpdated) {
+	if upd == nil {
+		return
+	}
+	if upd.GameID == 0 {
+		uc.log.Debugf("member update without game id house=%d member=%d", houseGID, upd.MemberID)
+		return
+	}
+	uc.syncForbid(ctx, houseGID, []int32{int32(upd.GameID)}, upd.Forbidden())
+}
+
+// OnGroupDeleted 店铺被游戏端移除（会话仍在线，成员/玩法快照已清空）
+func (uc *MemberSyncUseCase) OnGroupDeleted(ctx context.Context, houseGID int32, groupID int) {
+	uc.log.Warnf("group deleted by game house=%d group=%d", houseGID, groupID)
+}
+
+// syncForbid 只对状态实际变化的成员发布 member.forbidden / member.unforbidden
+func (uc *MemberSyncUseCase) syncForbid(ctx context.Context, houseGID int32, gameIDs []int32, forbid bool) {
+	if len(gameIDs) == 0 {
+		return
+	}
+	changed, err := uc.members.UpdateForbid(ctx, houseGID, gameIDs, forbid)
+	if err != nil {
+		uc.log.Errorf("sync member forbid house=%d forbid=%v failed: %v", houseGID, forbid, err)
+		return
+	}
+	typ := eventx.TypeMemberUnforbidden
+	if forbid {
+		typ = eventx.TypeMemberForbidden
+	}
+	for _, id := range changed {
+		eventx.Publish(ctx, houseGID, typ, map[string]any{"game_id": id, "source": "game"})
+	}
+	if len(changed) > 0 {
+		uc.log.Infof("member forbid synced from game house=%d forbid=%v count=%d", houseGID, forbid, len(changed))
+	}
+}
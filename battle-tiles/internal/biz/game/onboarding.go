@@ -0,0 +1,355 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// OnboardingInput 发起入店流程
+type OnboardingInput struct {
+	HouseGID        int32
+	Source          string // game/platform/manual
+	ApplicationID   int32
+	ApplicantUserID int32
+	GameID          int32
+	GameName        string
+	Recommender     string
+	GroupID         *int32
+	GroupAdminUID   int32 // 未指定 GroupID 时按圈主查找圈子（入圈申请）
+}
+
+// onboardingFunds 入店流程用到的资金操作（*FundsUseCase）
+type onboardingFunds interface {
+	Deposit(ctx context.Context, opUser int32, houseGID, memberID int32, amount int32, bizNo, reason string) (*model.GameMemberWallet, error)
+	Withdraw(ctx context.Context, opUser int32, houseGID, memberID int32, amount int32, bizNo, reason string, force bool) (*model.GameMemberWallet, error)
+	UpdateLimit(ctx context.Context, opUser int32, houseGID, memberID int32, limitMin *int32, forbid *bool, reason string) (*model.GameMemberWallet, bool, error)
+}
+
+// onboardingStep 流程中的一步：run 必须幂等，成功后 Step 推进到 name
+type onboardingStep struct {
+	name string
+	run  func(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error
+}
+
+// OnboardingUseCase 成员入店编排：审批通过后依次建成员、挂圈子、开钱包（默认额度 + 入店赠送）、写规则默认值。
+// 每步完成即落库，失败记录错误并停在断点，Retry 从断点继续；Cancel 按相反顺序撤销本流程产生的数据。
+type OnboardingUseCase struct {
+	repo      repo.OnboardingRepo
+	members   repo.GameMemberRepo
+	groups    repo.ShopGroupRepo
+	groupMbrs repo.ShopGroupMemberRepo
+	apps      repo.UserApplicationRepo
+	wallet    repo.WalletRepo
+	rules     repo.MemberRuleRepo
+	settings  repo.HouseSettingsRepo
+	funds     onboardingFunds
+	log       *log.Helper
+
+	steps []onboardingStep
+}
+
+func NewOnboardingUseCase(
+	r repo.OnboardingRepo,
+	members repo.GameMemberRepo,
+	groups repo.ShopGroupRepo,
+	groupMbrs repo.ShopGroupMemberRepo,
+	apps repo.UserApplicationRepo,
+	wallet repo.WalletRepo,
+	rules repo.MemberRuleRepo,
+	settings repo.HouseSettingsRepo,
+	funds *FundsUseCase,
+	logger log.Logger,
+) *OnboardingUseCase {
+	uc := &OnboardingUseCase{
+		repo:      r,
+		members:   members,
+		groups:    groups,
+		groupMbrs: groupMbrs,
+		apps:      apps,
+		wallet:    wallet,
+		rules:     rules,
+		settings:  settings,
+		funds:     funds,
+		log:       log.NewHelper(log.With(logger, "module", "usecase/onboarding")),
+	}
+	uc.steps = []onboardingStep{
+		{model.OnboardingStepMember, uc.ensureMember},
+		{model.OnboardingStepGroup, uc.ensureGroup},
+		{model.OnboardingStepWallet, uc.ensureWallet},
+		{model.OnboardingStepRules, uc.ensureRules},
+	}
+	return uc
+}
+
+// Start 发起并执行入店流程；同一申请重复发起时复用已有流程（未完成则继续执行）
+func (uc *OnboardingUseCase) Start(ctx context.Context, opUser int32, in OnboardingInput) (*model.GameMemberOnboarding, error) {
+	if in.HouseGID <= 0 {
+		return nil, errors.New("invalid house_gid")
+	}
+	if in.Source == "" {
+		in.Source = "manual"
+	}
+	if in.GroupID == nil && in.GroupAdminUID > 0 {
+		g, err := uc.groups.GetByAdmin(ctx, in.HouseGID, in.GroupAdminUID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get group by admin")
+		}
+		in.GroupID = &g.Id
+	}
+	ob := &model.GameMemberOnboarding{
+		HouseGID:        in.HouseGID,
+		Source:          in.Source,
+		ApplicationID:   in.ApplicationID,
+		ApplicantUserID: in.ApplicantUserID,
+		GameID:          in.GameID,
+		GameName:        in.GameName,
+		Recommender:     in.Recommender,
+		GroupID:         in.GroupID,
+		Step:            model.OnboardingStepPending,
+		Status:          model.OnboardingStatusRunning,
+		CreatedBy:       opUser,
+	}
+	created, err := uc.repo.Create(ctx, ob)
+	if err != nil {
+		return nil, err
+	}
+	if !created { // 同一申请已有流程（唯一索引冲突）：复用
+		if ob, err = uc.repo.GetByApplication(ctx, in.HouseGID, in.Source, in.ApplicationID); err != nil {
+			return nil, err
+		}
+		if ob.Status == model.OnboardingStatusCompleted || ob.Status == model.OnboardingStatusCompensated {
+			return ob, nil
+		}
+	}
+	return ob, uc.run(ctx, opUser, ob)
+}
+
+// Retry 从断点继续执行失败的流程
+func (uc *OnboardingUseCase) Retry(ctx context.Context, opUser, houseGID, id int32) (*model.GameMemberOnboarding, error) {
+	ob, err := uc.get(ctx, houseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ob.Status == model.OnboardingStatusCompleted || ob.Status == model.OnboardingStatusCompensated {
+		return ob, fmt.Errorf("onboarding already %s", ob.Status)
+	}
+	return ob, uc.run(ctx, opUser, ob)
+}
+
+// Cancel 撤销流程：回退入店赠送、移出圈子、删除本流程创建的成员（钱包保留并冻结，流水可追溯）
+func (uc *OnboardingUseCase) Cancel(ctx context.Context, opUser, houseGID, id int32) (*model.GameMemberOnboarding, error) {
+	ob, err := uc.get(ctx, houseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ob.Status == model.OnboardingStatusCompensated {
+		return ob, nil
+	}
+	if err := uc.compensate(ctx, opUser, ob); err != nil {
+		ob.Status, ob.LastError = model.OnboardingStatusFailed, "compensate: "+err.Error()
+		_ = uc.repo.Save(ctx, ob)
+		return ob, err
+	}
+	ob.Status, ob.LastError = model.OnboardingStatusCompensated, ""
+	return ob, uc.repo.Save(ctx, ob)
+}
+
+// List 店铺入店流程
+func (uc *OnboardingUseCase) List(ctx context.Context, houseGID int32, status string, page, size int32) ([]*model.GameMemberOnboarding, int64, error) {
+	return uc.repo.List(ctx, houseGID, status, page, size)
+}
+
+// SetDefaults 设置新成员钱包默认下限与入店赠送
+func (uc *OnboardingUseCase) SetDefaults(ctx context.Context, opUser, houseGID, limitMin, welcomeCredit int32) error {
+	if welcomeCredit < 0 {
+		return errors.New("welcome_credit must be >= 0")
+	}
+	return auditHouseSettings(ctx, uc.settings, houseGID, func() error {
+		return uc.settings.UpsertOnboardingDefaults(ctx, houseGID, limitMin, welcomeCredit, opUser)
+	})
+}
+
+func (uc *OnboardingUseCase) get(ctx context.Context, houseGID, id int32) (*model.GameMemberOnboarding, error) {
+	ob, err := uc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ob.HouseGID != houseGID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ob, nil
+}
+
+// run 从 Step 之后的第一步开始依次执行，每步成功即落库
+func (uc *OnboardingUseCase) run(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	ob.Status, ob.Attempts = model.OnboardingStatusRunning, ob.Attempts+1
+	for _, st := range uc.pendingSteps(ob.Step) {
+		if err := st.run(ctx, opUser, ob); err != nil {
+			ob.Status, ob.LastError = model.OnboardingStatusFailed, fmt.Sprintf("%s: %v", st.name, err)
+			if saveErr := uc.repo.Save(ctx, ob); saveErr != nil {
+				uc.log.Errorf("save onboarding %d failed: %v", ob.Id, saveErr)
+			}
+			return errors.Wrap(err, st.name)
+		}
+		ob.Step, ob.LastError = st.name, ""
+		if err := uc.repo.Save(ctx, ob); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	ob.Status, ob.CompletedAt = model.OnboardingStatusCompleted, &now
+	return uc.repo.Save(ctx, ob)
+}
+
+// pendingSteps 已完成步骤之后的步骤
+func (uc *OnboardingUseCase) pendingSteps(done string) []onboardingStep {
+	for i, st := range uc.steps {
+		if st.name == done {
+			return uc.steps[i+1:]
+		}
+	}
+	return uc.steps
+}
+
+// reached 流程是否已完成指定步骤
+func (uc *OnboardingUseCase) reached(ob *model.GameMemberOnboarding, step string) bool {
+	for _, st := range uc.pendingSteps(ob.Step) {
+		if st.name == step {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureMember 按 (店铺, 游戏ID, 圈子) 复用或新建 game_member
+func (uc *OnboardingUseCase) ensureMember(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	if ob.GameID <= 0 {
+		return errors.New("申请人未绑定游戏账号")
+	}
+	if m, err := uc.members.GetByGameIDAndGroup(ctx, ob.HouseGID, ob.GameID, ob.GroupID); err == nil {
+		ob.MemberID = m.Id
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	m := &model.GameMember{
+		HouseGID:    ob.HouseGID,
+		GameID:      ob.GameID,
+		GameName:    ob.GameName,
+		GroupID:     ob.GroupID,
+		Recommender: ob.Recommender,
+	}
+	if ob.GroupID != nil {
+		g, err := uc.groups.GetByID(ctx, *ob.GroupID)
+		if err != nil {
+			return errors.Wrap(err, "get group")
+		}
+		m.GroupName = g.GroupName
+	}
+	if err := uc.members.Create(ctx, m); err != nil {
+		return err
+	}
+	ob.MemberID, ob.MemberCreated = m.Id, true
+	return nil
+}
+
+// ensureGroup 平台用户写入圈子成员关系与已通过的入圈记录；游戏内申请只记录在 game_member.group_id 上
+func (uc *OnboardingUseCase) ensureGroup(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	if ob.GroupID == nil || ob.ApplicantUserID <= 0 {
+		return nil
+	}
+	g, err := uc.groups.GetByID(ctx, *ob.GroupID)
+	if err != nil {
+		return errors.Wrap(err, "get group")
+	}
+	in, err := uc.groupMbrs.IsMember(ctx, g.Id, ob.ApplicantUserID)
+	if err != nil {
+		return err
+	}
+	if !in {
+		if err := uc.groupMbrs.AddMember(ctx, &model.GameShopGroupMember{GroupID: g.Id, UserID: ob.ApplicantUserID, JoinedAt: time.Now()}); err != nil {
+			return err
+		}
+		ob.GroupLinked = true
+	}
+	return uc.apps.AddApprovedJoin(ctx, ob.HouseGID, g.AdminUserID, ob.ApplicantUserID)
+}
+
+// ensureWallet 无钱包时按店铺默认下限开户，再发放入店赠送（按流程ID生成业务号，重复执行不会重复入账）
+func (uc *OnboardingUseCase) ensureWallet(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	var limitMin, credit int32
+	if s, err := uc.settings.Get(ctx, ob.HouseGID); err == nil {
+		limitMin, credit = s.OnboardLimitMin, s.OnboardWelcomeCredit
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if _, err := uc.wallet.GetForUpdate(ctx, nil, ob.HouseGID, ob.MemberID); errors.Is(err, gorm.ErrRecordNotFound) {
+		w := &model.GameMemberWallet{HouseGID: ob.HouseGID, MemberID: ob.MemberID, GroupID: ob.GroupID, LimitMin: limitMin, UpdatedBy: opUser}
+		if err := uc.wallet.Upsert(ctx, nil, w); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if credit > 0 && ob.WelcomeCredit == 0 {
+		if _, err := uc.funds.Deposit(ctx, opUser, ob.HouseGID, ob.MemberID, credit, onboardingBizNo(ob.Id), "入店赠送"); err != nil {
+			return err
+		}
+		ob.WelcomeCredit = credit
+	}
+	return nil
+}
+
+// ensureRules 成员规则默认值（非 VIP、不允许多号、无临时解禁），已有规则不覆盖
+func (uc *OnboardingUseCase) ensureRules(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	if _, err := uc.rules.Get(ctx, ob.HouseGID, ob.MemberID); err == nil {
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return uc.rules.Upsert(ctx, &model.GameMemberRule{HouseGID: ob.HouseGID, MemberID: ob.MemberID, UpdatedBy: opUser})
+}
+
+func onboardingBizNo(id int32) string { return fmt.Sprintf("onboard-%d", id) }
+
+// compensate 与执行顺序相反地撤销；每步幂等，中途失败可再次撤销
+func (uc *OnboardingUseCase) compensate(ctx context.Context, opUser int32, ob *model.GameMemberOnboarding) error {
+	if ob.WelcomeCredit > 0 && ob.MemberID > 0 {
+		if _, err := uc.funds.Withdraw(ctx, opUser, ob.HouseGID, ob.MemberID, ob.WelcomeCredit, onboardingBizNo(ob.Id)+"-revert", "撤销入店赠送", true); err != nil {
+			return errors.Wrap(err, "revert welcome credit")
+		}
+	}
+	if ob.MemberCreated && uc.reached(ob, model.OnboardingStepWallet) {
+		forbid := true
+		if _, _, err := uc.funds.UpdateLimit(ctx, opUser, ob.HouseGID, ob.MemberID, nil, &forbid, "撤销入店"); err != nil {
+			return errors.Wrap(err, "freeze wallet")
+		}
+	}
+	if ob.GroupID != nil && ob.ApplicantUserID > 0 {
+		if g, err := uc.groups.GetByID(ctx, *ob.GroupID); err == nil {
+			if ob.GroupLinked {
+				if err := uc.groupMbrs.RemoveMember(ctx, g.Id, ob.ApplicantUserID); err != nil {
+					return errors.Wrap(err, "remove group member")
+				}
+			}
+			if _, err := uc.apps.RemoveApprovedJoin(ctx, ob.HouseGID, g.AdminUserID, ob.ApplicantUserID); err != nil {
+				return errors.Wrap(err, "remove approved join")
+			}
+		}
+	}
+	if ob.MemberCreated && ob.MemberID > 0 {
+		if err := uc.members.Delete(ctx, ob.HouseGID, ob.MemberID); err != nil {
+			return errors.Wrap(err, "delete member")
+		}
+	}
+	return nil
+}
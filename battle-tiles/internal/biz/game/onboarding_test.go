@@ -0,0 +1,408 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+func TestOnboardingResumeFromStep(t *testing.T) {
+	uc := &OnboardingUseCase{}
+	uc.steps = []onboardingStep{
+		{name: model.OnboardingStepMember},
+		{name: model.OnboardingStepGroup},
+		{name: model.OnboardingStepWallet},
+		{name: model.OnboardingStepRules},
+	}
+
+	if got := uc.pendingSteps(model.OnboardingStepPending); len(got) != 4 {
+		t.Fatalf("pending: %d steps left, want 4", len(got))
+	}
+	got := uc.pendingSteps(model.OnboardingStepGroup)
+	if len(got) != 2 || got[0].name != model.OnboardingStepWallet {
+		t.Fatalf("after group: %+v", got)
+	}
+	if got := uc.pendingSteps(model.OnboardingStepRules); len(got) != 0 {
+		t.Fatalf("after rules: %d steps left, want 0", len(got))
+	}
+
+	ob := &model.GameMemberOnboarding{Step: model.OnboardingStepGroup}
+	if !uc.reached(ob, model.OnboardingStepMember) || !uc.reached(ob, model.OnboardingStepGroup) {
+		t.Fatal("completed steps should be reached")
+	}
+	if uc.reached(ob, model.OnboardingStepWallet) {
+		t.Fatal("wallet step should not be reached yet")
+	}
+}
+
+// onboardingWorld 入店流程依赖的内存实现：calls 按顺序记录有副作用的操作，fail 按操作名注入一次性失败
+type onboardingWorld struct {
+	calls   []string
+	fail    map[string]error
+	obs     map[int32]*model.GameMemberOnboarding
+	members map[int32]*model.GameMember
+	wallets map[int32]bool
+	ledger  map[string]int32 // 业务号 -> 金额
+	inGroup map[int32]bool
+}
+
+func newOnboardingWorld() *onboardingWorld {
+	return &onboardingWorld{
+		fail:    map[string]error{},
+		obs:     map[int32]*model.GameMemberOnboarding{},
+		members: map[int32]*model.GameMember{},
+		wallets: map[int32]bool{},
+		ledger:  map[string]int32{},
+		inGroup: map[int32]bool{},
+	}
+}
+
+func (w *onboardingWorld) check(op string) error {
+	if err := w.fail[op]; err != nil {
+		delete(w.fail, op)
+		return err
+	}
+	return nil
+}
+
+func (w *onboardingWorld) do(op string) error {
+	if err := w.check(op); err != nil {
+		return err
+	}
+	w.calls = append(w.calls, op)
+	return nil
+}
+
+func (w *onboardingWorld) count(op string) int {
+	n := 0
+	for _, c := range w.calls {
+		if c == op {
+			n++
+		}
+	}
+	return n
+}
+
+// 流程记录按值存取，模拟每次从库里重新读取
+type obRepo struct {
+	repo.OnboardingRepo
+	w *onboardingWorld
+}
+
+func (r obRepo) Create(_ context.Context, m *model.GameMemberOnboarding) (bool, error) {
+	for _, ob := range r.w.obs {
+		if m.ApplicationID > 0 && ob.HouseGID == m.HouseGID && ob.Source == m.Source && ob.ApplicationID == m.ApplicationID {
+			return false, nil
+		}
+	}
+	m.Id = int32(len(r.w.obs) + 1)
+	cp := *m
+	r.w.obs[m.Id] = &cp
+	return true, nil
+}
+
+func (r obRepo) Save(_ context.Context, m *model.GameMemberOnboarding) error {
+	if err := r.w.check("save:" + m.Step); err != nil {
+		return err
+	}
+	cp := *m
+	r.w.obs[m.Id] = &cp
+	return nil
+}
+
+func (r obRepo) Get(_ context.Context, id int32) (*model.GameMemberOnboarding, error) {
+	ob, ok := r.w.obs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *ob
+	return &cp, nil
+}
+
+func (r obRepo) GetByApplication(_ context.Context, houseGID int32, source string, applicationID int32) (*model.GameMemberOnboarding, error) {
+	for _, ob := range r.w.obs {
+		if ob.HouseGID == houseGID && ob.Source == source && ob.ApplicationID == applicationID {
+			cp := *ob
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type obMembers struct {
+	repo.GameMemberRepo
+	w *onboardingWorld
+}
+
+func (r obMembers) GetByGameIDAndGroup(_ context.Context, houseGID, gameID int32, _ *int32) (*model.GameMember, error) {
+	for _, m := range r.w.members {
+		if m.HouseGID == houseGID && m.GameID == gameID {
+			return m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r obMembers) Create(_ context.Context, m *model.GameMember) error {
+	if err := r.w.do("member.create"); err != nil {
+		return err
+	}
+	m.Id = int32(100 + len(r.w.members))
+	r.w.members[m.Id] = m
+	return nil
+}
+
+func (r obMembers) Delete(_ context.Context, _, memberID int32) error {
+	if err := r.w.do("member.delete"); err != nil {
+		return err
+	}
+	delete(r.w.members, memberID)
+	return nil
+}
+
+type obGroups struct{ repo.ShopGroupRepo }
+
+func (obGroups) GetByID(_ context.Context, id int32) (*model.GameShopGroup, error) {
+	return &model.GameShopGroup{Id: id, GroupName: "一圈", AdminUserID: 500}, nil
+}
+
+type obGroupMembers struct {
+	repo.ShopGroupMemberRepo
+	w *onboardingWorld
+}
+
+func (r obGroupMembers) IsMember(_ context.Context, _, userID int32) (bool, error) {
+	return r.w.inGroup[userID], nil
+}
+
+func (r obGroupMembers) AddMember(_ context.Context, m *model.GameShopGroupMember) error {
+	if err := r.w.do("group.add"); err != nil {
+		return err
+	}
+	r.w.inGroup[m.UserID] = true
+	return nil
+}
+
+func (r obGroupMembers) RemoveMember(_ context.Context, _, userID int32) error {
+	if err := r.w.do("group.remove"); err != nil {
+		return err
+	}
+	delete(r.w.inGroup, userID)
+	return nil
+}
+
+type obApps struct {
+	repo.UserApplicationRepo
+	w *onboardingWorld
+}
+
+func (r obApps) AddApprovedJoin(context.Context, int32, int32, int32) error {
+	return r.w.do("join.add")
+}
+
+func (r obApps) RemoveApprovedJoin(context.Context, int32, int32, int32) (int64, error) {
+	return 1, r.w.do("join.remove")
+}
+
+type obWallets struct {
+	repo.WalletRepo
+	w *onboardingWorld
+}
+
+func (r obWallets) GetForUpdate(_ context.Context, _ *gorm.DB, _, memberID int32) (*model.GameMemberWallet, error) {
+	if !r.w.wallets[memberID] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.GameMemberWallet{MemberID: memberID}, nil
+}
+
+func (r obWallets) Upsert(_ context.Context, _ *gorm.DB, m *model.GameMemberWallet) error {
+	if err := r.w.do("wallet.open"); err != nil {
+		return err
+	}
+	r.w.wallets[m.MemberID] = true
+	return nil
+}
+
+type obRules struct {
+	repo.MemberRuleRepo
+	w *onboardingWorld
+}
+
+func (obRules) Get(context.Context, int32, int32) (*model.GameMemberRule, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r obRules) Upsert(context.Context, *model.GameMemberRule) error {
+	return r.w.do("rules")
+}
+
+type obSettings struct{ repo.HouseSettingsRepo }
+
+func (obSettings) Get(context.Context, int32) (*model.GameHouseSettings, error) {
+	return &model.GameHouseSettings{OnboardLimitMin: -1000, OnboardWelcomeCredit: 500}, nil
+}
+
+// obFunds 同 FundsUseCase：已有同业务号流水时不重复入账
+type obFunds struct{ w *onboardingWorld }
+
+func (f obFunds) Deposit(_ context.Context, _, _, memberID, amount int32, bizNo, _ string) (*model.GameMemberWallet, error) {
+	return f.post("deposit", memberID, amount, bizNo)
+}
+
+func (f obFunds) Withdraw(_ context.Context, _, _, memberID, amount int32, bizNo, _ string, _ bool) (*model.GameMemberWallet, error) {
+	return f.post("withdraw", memberID, -amount, bizNo)
+}
+
+func (f obFunds) post(op string, memberID, amount int32, bizNo string) (*model.GameMemberWallet, error) {
+	if _, ok := f.w.ledger[bizNo]; ok {
+		return &model.GameMemberWallet{MemberID: memberID}, nil
+	}
+	if err := f.w.do(op); err != nil {
+		return nil, err
+	}
+	f.w.ledger[bizNo] = amount
+	return &model.GameMemberWallet{MemberID: memberID}, nil
+}
+
+func (f obFunds) UpdateLimit(_ context.Context, _, _, memberID int32, _ *int32, _ *bool, _ string) (*model.GameMemberWallet, bool, error) {
+	return &model.GameMemberWallet{MemberID: memberID}, true, f.w.do("freeze")
+}
+
+func newTestOnboarding() (*OnboardingUseCase, *onboardingWorld) {
+	w := newOnboardingWorld()
+	uc := NewOnboardingUseCase(obRepo{w: w}, obMembers{w: w}, obGroups{}, obGroupMembers{w: w}, obApps{w: w},
+		obWallets{w: w}, obRules{w: w}, obSettings{}, nil, log.DefaultLogger)
+	uc.funds = obFunds{w: w}
+	return uc, w
+}
+
+func testOnboardingInput() OnboardingInput {
+	groupID := int32(10)
+	return OnboardingInput{HouseGID: 20001, Source: model.ApplicationSourcePlatform, ApplicationID: 7, ApplicantUserID: 300, GameID: 9001, GameName: "p1", GroupID: &groupID}
+}
+
+func TestOnboardingResumesAfterWalletFailure(t *testing.T) {
+	ctx := context.Background()
+	uc, w := newTestOnboarding()
+	w.fail["deposit"] = errors.New("wallet busy")
+
+	ob, err := uc.Start(ctx, 1, testOnboardingInput())
+	if err == nil || ob.Status != model.OnboardingStatusFailed || ob.Step != model.OnboardingStepGroup {
+		t.Fatalf("start = %+v, %v; want failed after %s", ob, err, model.OnboardingStepGroup)
+	}
+
+	ob, err = uc.Retry(ctx, 1, 20001, ob.Id)
+	if err != nil || ob.Status != model.OnboardingStatusCompleted || ob.WelcomeCredit != 500 {
+		t.Fatalf("retry = %+v, %v", ob, err)
+	}
+	// 断点之前的步骤不重做
+	if w.count("member.create") != 1 || w.count("group.add") != 1 || w.count("wallet.open") != 1 || w.count("deposit") != 1 {
+		t.Fatalf("calls = %v", w.calls)
+	}
+
+	// 同一申请再次发起（重复推送/并发审批）：唯一索引冲突后复用已有流程
+	again, err := uc.Start(ctx, 1, testOnboardingInput())
+	if err != nil || again.Id != ob.Id || len(w.obs) != 1 || w.count("member.create") != 1 {
+		t.Fatalf("start again = %+v, %v; flows=%d calls=%v", again, err, len(w.obs), w.calls)
+	}
+}
+
+func TestOnboardingRetryDoesNotCreditTwice(t *testing.T) {
+	ctx := context.Background()
+	uc, w := newTestOnboarding()
+	// 赠送已入账，但推进到 wallet_ready 的记录没写进去：库里仍停在 group_assigned、welcome_credit=0
+	w.fail["save:"+model.OnboardingStepWallet] = errors.New("db down")
+
+	ob, err := uc.Start(ctx, 1, testOnboardingInput())
+	if err == nil {
+		t.Fatal("start should fail when the step cannot be saved")
+	}
+	if stored := w.obs[ob.Id]; stored.Step != model.OnboardingStepGroup || stored.WelcomeCredit != 0 {
+		t.Fatalf("stored = %+v", stored)
+	}
+
+	ob, err = uc.Retry(ctx, 1, 20001, ob.Id)
+	if err != nil || ob.Status != model.OnboardingStatusCompleted {
+		t.Fatalf("retry = %+v, %v", ob, err)
+	}
+	if w.count("deposit") != 1 || len(w.ledger) != 1 || w.ledger[onboardingBizNo(ob.Id)] != 500 {
+		t.Fatalf("deposits = %d ledger = %v", w.count("deposit"), w.ledger)
+	}
+}
+
+func TestOnboardingCancelOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("created by flow", func(t *testing.T) {
+		uc, w := newTestOnboarding()
+		ob, err := uc.Start(ctx, 1, testOnboardingInput())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.calls = nil
+		if ob, err = uc.Cancel(ctx, 1, 20001, ob.Id); err != nil || ob.Status != model.OnboardingStatusCompensated {
+			t.Fatalf("cancel = %+v, %v", ob, err)
+		}
+		want := []string{"withdraw", "freeze", "group.remove", "join.remove", "member.delete"}
+		if !reflect.DeepEqual(w.calls, want) {
+			t.Fatalf("calls = %v, want %v", w.calls, want)
+		}
+	})
+
+	t.Run("existing member", func(t *testing.T) {
+		uc, w := newTestOnboarding()
+		w.members[42] = &model.GameMember{Id: 42, HouseGID: 20001, GameID: 9001}
+		w.inGroup[300] = true
+		ob, err := uc.Start(ctx, 1, testOnboardingInput())
+		if err != nil || ob.MemberCreated || ob.GroupLinked {
+			t.Fatalf("start = %+v, %v", ob, err)
+		}
+		w.calls = nil
+		if _, err = uc.Cancel(ctx, 1, 20001, ob.Id); err != nil {
+			t.Fatal(err)
+		}
+		// 不冻结、不移出、不删除不是本流程产生的成员与圈子关系
+		want := []string{"withdraw", "join.remove"}
+		if !reflect.DeepEqual(w.calls, want) || w.members[42] == nil {
+			t.Fatalf("calls = %v, want %v", w.calls, want)
+		}
+	})
+}
+
+func TestOnboardingCancelRetryAfterPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	uc, w := newTestOnboarding()
+	ob, err := uc.Start(ctx, 1, testOnboardingInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.calls = nil
+	w.fail["group.remove"] = errors.New("db down")
+
+	ob, err = uc.Cancel(ctx, 1, 20001, ob.Id)
+	if err == nil || ob.Status != model.OnboardingStatusFailed {
+		t.Fatalf("cancel = %+v, %v; want failed", ob, err)
+	}
+	if w.members[ob.MemberID] == nil {
+		t.Fatal("member deleted before the group link was removed")
+	}
+
+	ob, err = uc.Cancel(ctx, 1, 20001, ob.Id)
+	if err != nil || ob.Status != model.OnboardingStatusCompensated {
+		t.Fatalf("cancel again = %+v, %v", ob, err)
+	}
+	// 赠送只扣回一次，成员只删一次
+	if w.count("withdraw") != 1 || w.count("group.remove") != 1 || w.count("member.delete") != 1 {
+		t.Fatalf("calls = %v", w.calls)
+	}
+	if w.members[ob.MemberID] != nil || w.inGroup[300] {
+		t.Fatalf("members = %v inGroup = %v", w.members, w.inGroup)
+	}
+}
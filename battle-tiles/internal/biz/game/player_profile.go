@@ -0,0 +1,241 @@
+package game
+
+import (
+	"battle-tiles/internal/consts"
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/dal/resp"
+	"battle-tiles/internal/infra/plaza"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	profileRecentSize   = 20
+	profileLedgerWindow = 30 * 24 * time.Hour
+)
+
+// PlayerProfileUseCase 玩家 360° 视图：聚合账号、成员、钱包、规则、流水、战绩、申请与在线状态
+type PlayerProfileUseCase struct {
+	accountRepo repo.GameAccountRepo
+	memberRepo  repo.GameMemberRepo
+	walletRepo  repo.WalletReadRepo
+	ruleRepo    repo.MemberRuleRepo
+	battleRepo  repo.BattleRecordRepo
+	appRepo     repo.UserApplicationRepo
+	mgr         plaza.Manager
+	log         *log.Helper
+}
+
+func NewPlayerProfileUseCase(
+	accountRepo repo.GameAccountRepo,
+	memberRepo repo.GameMemberRepo,
+	walletRepo repo.WalletReadRepo,
+	ruleRepo repo.MemberRuleRepo,
+	battleRepo repo.BattleRecordRepo,
+	appRepo repo.UserApplicationRepo,
+	mgr plaza.Manager,
+	logger log.Logger,
+) *PlayerProfileUseCase {
+	return &PlayerProfileUseCase{
+		accountRepo: accountRepo,
+		memberRepo:  memberRepo,
+		walletRepo:  walletRepo,
+		ruleRepo:    ruleRepo,
+		battleRepo:  battleRepo,
+		appRepo:     appRepo,
+		mgr:         mgr,
+		log:         log.NewHelper(log.With(logger, "module", "usecase/player_profile")),
+	}
+}
+
+// PlayerProfileAccess 调用方在店铺内的资金查看权限；没有的部分不返回
+type PlayerProfileAccess struct {
+	Wallet bool // fund:wallet:view
+	Ledger bool // fund:ledger:view
+}
+
+// Get 按游戏ID或平台用户ID查询玩家档案（二选一，优先 gameID）
+func (uc *PlayerProfileUseCase) Get(ctx context.Context, houseGID int32, gameID, userID *int32, access PlayerProfileAccess) (*resp.PlayerProfileVO, error) {
+	if houseGID <= 0 {
+		return nil, errors.New("invalid house_gid")
+	}
+	account, gid, err := uc.resolveAccount(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &resp.PlayerProfileVO{HouseGID: houseGID, GameID: gid}
+	if account != nil {
+		out.UserID = account.UserID
+		out.Account = &resp.PlayerAccountVO{
+			Id:                 account.Id,
+			UserID:             account.UserID,
+			Account:            account.Account,
+			Nickname:           account.Nickname,
+			GameUserID:         account.GameUserID,
+			Status:             account.Status,
+			VerificationStatus: account.VerificationStatus,
+			LastLoginAt:        account.LastLoginAt,
+		}
+	}
+
+	// 成员/钱包/规则（一个玩家可在多个圈）
+	members, err := uc.memberRepo.ListByGameID(ctx, houseGID, gid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, m := range members {
+		ms := resp.PlayerMembershipVO{Member: m}
+		if access.Wallet {
+			if w, err := uc.walletRepo.Get(ctx, houseGID, m.Id, m.GroupID); err == nil {
+				ms.Wallet = w
+			}
+		}
+		if r, err := uc.ruleRepo.Get(ctx, houseGID, m.Id); err == nil {
+			ms.Rule = r
+		}
+		out.Memberships = append(out.Memberships, ms)
+
+		if !access.Ledger {
+			continue
+		}
+		ledger, _, err := uc.walletRepo.ListLedger(ctx, houseGID, &m.Id, nil, now.Add(-profileLedgerWindow), now, 1, profileRecentSize)
+		if err != nil {
+			uc.log.Warnf("list ledger for member %d failed: %v", m.Id, err)
+			continue
+		}
+		out.Ledger = append(out.Ledger, ledger...)
+	}
+
+	// 最近战绩
+	battles, _, err := uc.battleRepo.ListByPlayer(ctx, houseGID, gid, nil, nil, nil, 1, profileRecentSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range battles {
+		out.Battles = append(out.Battles, resp.PlayerBattleVO{
+			Id:        b.Id,
+			GroupID:   b.GroupID,
+			RoomUID:   b.RoomUID,
+			KindID:    b.KindID,
+			KindName:  consts.GetKindName(int(b.KindID)),
+			BaseScore: b.BaseScore,
+			BattleAt:  b.BattleAt,
+			Score:     b.Score,
+			Fee:       b.Fee,
+			NetScore:  b.Score - b.Fee,
+		})
+	}
+
+	// 全部 / 今日 / 本周统计
+	todayStart, tomorrowStart := dayRange(now)
+	weekStart, weekEnd := weekRange(now)
+	if out.Stats.Lifetime, err = uc.playerStats(ctx, houseGID, gid, nil, nil); err != nil {
+		return nil, err
+	}
+	if out.Stats.Today, err = uc.playerStats(ctx, houseGID, gid, &todayStart, &tomorrowStart); err != nil {
+		return nil, err
+	}
+	if out.Stats.ThisWeek, err = uc.playerStats(ctx, houseGID, gid, &weekStart, &weekEnd); err != nil {
+		return nil, err
+	}
+
+	// 待审核申请
+	if out.UserID > 0 {
+		pending := int32(0)
+		apps, err := uc.appRepo.ListHistory(ctx, houseGID, &out.UserID, nil, &pending, nil, nil)
+		if err != nil {
+			uc.log.Warnf("list pending applications for user %d failed: %v", out.UserID, err)
+		}
+		out.Applications = apps
+	}
+
+	out.Online = uc.onlineStatus(int(houseGID), gid)
+	return out, nil
+}
+
+// resolveAccount 解析游戏账号与游戏ID
+func (uc *PlayerProfileUseCase) resolveAccount(ctx context.Context, gameID, userID *int32) (*model.GameAccount, int32, error) {
+	if gameID != nil && *gameID > 0 {
+		acc, err := uc.accountRepo.GetByGameUserID(ctx, strconv.Itoa(int(*gameID)))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, err
+		}
+		return acc, *gameID, nil
+	}
+	if userID == nil || *userID <= 0 {
+		return nil, 0, errors.New("game_id or user_id is required")
+	}
+	accounts, err := uc.accountRepo.ListByUser(ctx, *userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, a := range accounts {
+		if a.GameUserID == "" {
+			continue
+		}
+		gid, err := strconv.Atoi(a.GameUserID)
+		if err != nil {
+			continue
+		}
+		return a, int32(gid), nil
+	}
+	return nil, 0, errors.New("该用户未绑定游戏账号")
+}
+
+func (uc *PlayerProfileUseCase) playerStats(ctx context.Context, houseGID, gameID int32, start, end *time.Time) (resp.PlayerStatsItemVO, error) {
+	games, score, fee, err := uc.battleRepo.GetPlayerStats(ctx, houseGID, gameID, nil, start, end)
+	if err != nil {
+		return resp.PlayerStatsItemVO{}, err
+	}
+	return resp.PlayerStatsItemVO{Games: games, Score: score, Fee: fee}, nil
+}
+
+// onlineStatus 从该店铺任一在线中控会话的成员快照中查找玩家状态
+func (uc *PlayerProfileUseCase) onlineStatus(houseGID int, gameID int32) *resp.PlayerOnlineVO {
+	out := &resp.PlayerOnlineVO{}
+	sess, ok := uc.mgr.GetAnyByHouse(houseGID)
+	if !ok || sess == nil {
+		return out
+	}
+	out.SessionOnline = true
+	for _, m := range sess.ListMembers() {
+		if int32(m.GameID) != gameID {
+			continue
+		}
+		out.InGroup = true
+		out.MemberID = int32(m.MemberID)
+		out.MemberType = m.MemberType
+		out.UserStatus = m.UserStatus
+		out.StatusName = userStatusName(m.UserStatus)
+		out.NickName = m.NickName
+		break
+	}
+	return out
+}
+
+func userStatusName(status int) string {
+	switch status {
+	case consts.US_FREE:
+		return "free"
+	case consts.US_SIT:
+		return "sit"
+	case consts.US_READY:
+		return "ready"
+	case consts.US_LOOKON:
+		return "lookon"
+	case consts.US_PLAYING:
+		return "playing"
+	case consts.US_OFFLINE:
+		return "offline"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	utilsplaza "battle-tiles/internal/utils/plaza"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type fakeProfileAccounts struct {
+	repo.GameAccountRepo
+	byUser map[int32][]*model.GameAccount
+}
+
+func (r *fakeProfileAccounts) ListByUser(_ context.Context, userID int32) ([]*model.GameAccount, error) {
+	return r.byUser[userID], nil
+}
+
+type fakeProfileMembers struct {
+	repo.GameMemberRepo
+	members []*model.GameMember
+}
+
+func (r *fakeProfileMembers) ListByGameID(_ context.Context, houseGID, gameID int32) ([]*model.GameMember, error) {
+	var out []*model.GameMember
+	for _, m := range r.members {
+		if m.HouseGID == houseGID && m.GameID == gameID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+type fakeProfileWallets struct {
+	repo.WalletReadRepo
+	wallets map[int32]*model.GameMemberWallet
+}
+
+func (r *fakeProfileWallets) Get(_ context.Context, _, memberID int32, _ *int32) (*model.GameMemberWallet, error) {
+	if w, ok := r.wallets[memberID]; ok {
+		return w, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *fakeProfileWallets) ListLedger(_ context.Context, _ int32, memberID *int32, _ *int32, _, _ time.Time, _, _ int32) ([]*model.GameWalletLedger, int64, error) {
+	return []*model.GameWalletLedger{{MemberID: *memberID}}, 1, nil
+}
+
+type fakeProfileRules struct{ repo.MemberRuleRepo }
+
+func (fakeProfileRules) Get(context.Context, int32, int32) (*model.GameMemberRule, error) {
+	return nil, errors.New("not found")
+}
+
+type fakeProfileBattles struct {
+	repo.BattleRecordRepo
+	battles []*model.GameBattleRecord
+}
+
+func (r *fakeProfileBattles) ListByPlayer(_ context.Context, _ int32, _ interface{}, _ *int32, _, _ *time.Time, _, _ int32) ([]*model.GameBattleRecord, int64, error) {
+	return r.battles, int64(len(r.battles)), nil
+}
+
+// GetPlayerStats 全部统计 10 局，限定时间范围时 1 局
+func (r *fakeProfileBattles) GetPlayerStats(_ context.Context, _ int32, _ interface{}, _ *int32, start, _ *time.Time) (int64, int, int, error) {
+	if start == nil {
+		return 10, 100, 20, nil
+	}
+	return 1, 5, 2, nil
+}
+
+type fakeProfileApps struct {
+	repo.UserApplicationRepo
+	applicant *int32
+}
+
+func (r *fakeProfileApps) ListHistory(_ context.Context, _ int32, applicant *int32, _, _ *int32, _, _ *time.Time) ([]*model.UserApplication, error) {
+	r.applicant = applicant
+	return []*model.UserApplication{{Id: 1}}, nil
+}
+
+// fakeOfflineManager 店铺没有在线中控会话
+type fakeOfflineManager struct{ plaza.Manager }
+
+func (fakeOfflineManager) GetAnyByHouse(int) (*utilsplaza.Session, bool) { return nil, false }
+
+func TestPlayerProfileByUserID(t *testing.T) {
+	ctx := context.Background()
+	g1 := int32(11)
+	apps := &fakeProfileApps{}
+	uc := NewPlayerProfileUseCase(
+		&fakeProfileAccounts{byUser: map[int32][]*model.GameAccount{
+			7: {{Id: 1, UserID: 7, GameUserID: ""}, {Id: 2, UserID: 7, GameUserID: "123456", Nickname: "p"}},
+		}},
+		&fakeProfileMembers{members: []*model.GameMember{
+			{Id: 101, HouseGID: 100, GameID: 123456},
+			{Id: 102, HouseGID: 100, GameID: 123456, GroupID: &g1},
+			{Id: 103, HouseGID: 200, GameID: 123456},
+		}},
+		&fakeProfileWallets{wallets: map[int32]*model.GameMemberWallet{101: {MemberID: 101, Balance: 50}}},
+		fakeProfileRules{},
+		&fakeProfileBattles{battles: []*model.GameBattleRecord{{Id: 9, KindID: 1, Score: 30, Fee: 2}}},
+		apps,
+		fakeOfflineManager{},
+		log.DefaultLogger,
+	)
+
+	uid := int32(7)
+	vo, err := uc.Get(ctx, 100, nil, &uid, PlayerProfileAccess{Wallet: true, Ledger: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 跳过未回填游戏ID的账号
+	if vo.GameID != 123456 || vo.UserID != 7 || vo.Account == nil || vo.Account.Id != 2 {
+		t.Fatalf("resolved account = %+v / %+v", vo, vo.Account)
+	}
+	// 只包含本店铺的成员，钱包缺失不影响整体
+	if len(vo.Memberships) != 2 || vo.Memberships[0].Wallet == nil || vo.Memberships[0].Wallet.Balance != 50 || vo.Memberships[1].Wallet != nil {
+		t.Fatalf("memberships = %+v", vo.Memberships)
+	}
+	if len(vo.Ledger) != 2 || len(vo.Battles) != 1 || vo.Battles[0].NetScore != 28 {
+		t.Fatalf("ledger = %d, battles = %+v", len(vo.Ledger), vo.Battles)
+	}
+	if vo.Stats.Lifetime.Games != 10 || vo.Stats.Today.Games != 1 || vo.Stats.ThisWeek.Games != 1 {
+		t.Fatalf("stats = %+v", vo.Stats)
+	}
+	if apps.applicant == nil || *apps.applicant != 7 || len(vo.Applications) != 1 {
+		t.Fatalf("pending applications queried for %v", apps.applicant)
+	}
+	if vo.Online == nil || vo.Online.SessionOnline {
+		t.Fatalf("online = %+v", vo.Online)
+	}
+
+	// 无资金查看权限时不返回钱包与流水
+	vo, err = uc.Get(ctx, 100, nil, &uid, PlayerProfileAccess{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vo.Memberships) != 2 || vo.Memberships[0].Wallet != nil || len(vo.Ledger) != 0 {
+		t.Fatalf("without fund access: memberships = %+v, ledger = %d", vo.Memberships, len(vo.Ledger))
+	}
+
+	if _, err := uc.Get(ctx, 100, nil, nil, PlayerProfileAccess{}); err == nil {
+		t.Fatal("missing game_id and user_id should be rejected")
+	}
+	other := int32(8)
+	if _, err := uc.Get(ctx, 100, nil, &other, PlayerProfileAccess{}); err == nil {
+		t.Fatal("user without bound game account should be rejected")
+	}
+}
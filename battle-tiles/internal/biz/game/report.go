@@ -0,0 +1,453 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	reportFileDir       = "data/reports" // 文件渠道的输出目录
+	reportDefaultLimit  = 50
+	reportMaxLimit      = 100
+	reportTriggerCron   = "schedule"
+	reportTriggerManual = "manual"
+)
+
+// ReportParams 报表参数（按类型取用，存于 params 列）
+type ReportParams struct {
+	// low_balance：余额不高于该值（分）的成员，默认 0
+	Threshold int32 `json:"threshold"`
+	// 明细行数上限，默认 50
+	Limit int `json:"limit"`
+	// 只看某个圈
+	GroupID *int32 `json:"group_id,omitempty"`
+}
+
+// ReportDefinitionInput 新建/修改报表定义
+type ReportDefinitionInput struct {
+	HouseGID      int32
+	Name          string
+	ReportType    string
+	Period        string
+	Schedule      string
+	Params        ReportParams
+	Channel       string
+	ChannelTarget string
+	Enabled       bool
+}
+
+// ReportUseCase 店铺定时报表：定义管理、按 cron 到期渲染并投递、记录执行历史
+type ReportUseCase struct {
+	repo       repo.ReportRepo
+	stats      repo.GameStatsRepo
+	wallets    repo.WalletReadRepo
+	fees       repo.FeeSettleRepo
+	rank       *LeaderboardUseCase
+	deliveries map[string]ReportDelivery
+	log        *log.Helper
+}
+
+func NewReportUseCase(
+	r repo.ReportRepo,
+	stats repo.GameStatsRepo,
+	wallets repo.WalletReadRepo,
+	fees repo.FeeSettleRepo,
+	rank *LeaderboardUseCase,
+	logger log.Logger,
+) *ReportUseCase {
+	return &ReportUseCase{
+		repo:    r,
+		stats:   stats,
+		wallets: wallets,
+		fees:    fees,
+		rank:    rank,
+		deliveries: map[string]ReportDelivery{
+			model.ReportChannelFile:    NewFileReportDelivery(reportFileDir),
+			model.ReportChannelWebhook: NewWebhookReportDelivery(nil),
+		},
+		log: log.NewHelper(log.With(logger, "module", "usecase/report")),
+	}
+}
+
+// RegisterDelivery 注册/替换投递渠道（如接入 IM 机器人）
+func (uc *ReportUseCase) RegisterDelivery(channel string, d ReportDelivery) {
+	uc.deliveries[channel] = d
+}
+
+// CreateDefinition 新建报表定义
+func (uc *ReportUseCase) CreateDefinition(ctx context.Context, opUser int32, in ReportDefinitionInput) (*model.GameReportDefinition, error) {
+	m := &model.GameReportDefinition{HouseGID: in.HouseGID, CreatedBy: opUser}
+	if err := uc.applyInput(m, in, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.CreateDefinition(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UpdateDefinition 修改报表定义（重新计算下次执行时间）
+func (uc *ReportUseCase) UpdateDefinition(ctx context.Context, id int32, in ReportDefinitionInput) (*model.GameReportDefinition, error) {
+	m, err := uc.getOwned(ctx, in.HouseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.applyInput(m, in, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateDefinition(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeleteDefinition 删除报表定义（执行历史保留）
+func (uc *ReportUseCase) DeleteDefinition(ctx context.Context, houseGID, id int32) error {
+	return uc.repo.DeleteDefinition(ctx, houseGID, id)
+}
+
+// ListDefinitions 店铺下的报表定义
+func (uc *ReportUseCase) ListDefinitions(ctx context.Context, houseGID int32) ([]*model.GameReportDefinition, error) {
+	return uc.repo.ListDefinitions(ctx, houseGID)
+}
+
+// ListRuns 执行历史
+func (uc *ReportUseCase) ListRuns(ctx context.Context, houseGID int32, definitionID *int32, page, size int32) ([]*model.GameReportRun, int64, error) {
+	return uc.repo.ListRuns(ctx, houseGID, definitionID, page, size)
+}
+
+// RunNow 立即执行一次（不影响定时计划）
+func (uc *ReportUseCase) RunNow(ctx context.Context, houseGID, id int32) (*model.GameReportRun, error) {
+	def, err := uc.getOwned(ctx, houseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.execute(ctx, def, reportTriggerManual, time.Now())
+}
+
+// RunDue 执行当前平台下所有到期的报表，由 asynq 定时任务按平台调用
+func (uc *ReportUseCase) RunDue(ctx context.Context) error {
+	now := time.Now()
+	defs, err := uc.repo.ListDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		sched, err := cron.ParseStandard(def.Schedule)
+		if err != nil {
+			uc.log.Warnf("report %d has invalid schedule %q: %v", def.Id, def.Schedule, err)
+			continue
+		}
+		ok, err := uc.repo.ClaimDue(ctx, def.Id, now, sched.Next(now))
+		if err != nil {
+			uc.log.Errorf("claim report %d failed: %v", def.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		// next_run_at 为空说明是刚创建/修改且未计算过计划，只排期不执行
+		if def.NextRunAt == nil {
+			continue
+		}
+		if _, err := uc.execute(ctx, def, reportTriggerCron, now); err != nil {
+			uc.log.Warnf("report %d (%s) failed: %v", def.Id, def.ReportType, err)
+		}
+	}
+	return nil
+}
+
+// execute 渲染并投递，执行结果写入 game_report_run
+func (uc *ReportUseCase) execute(ctx context.Context, def *model.GameReportDefinition, trigger string, now time.Time) (*model.GameReportRun, error) {
+	start, end, err := reportRange(def.Period, now)
+	if err != nil {
+		return nil, err
+	}
+	run := &model.GameReportRun{
+		DefinitionID: def.Id,
+		HouseGID:     def.HouseGID,
+		ReportType:   def.ReportType,
+		Trigger:      trigger,
+		Status:       model.ReportRunStatusRunning,
+		RangeStart:   start,
+		RangeEnd:     end,
+		Channel:      def.Channel,
+		StartedAt:    now,
+	}
+	if err := uc.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	runErr := func() error {
+		d, ok := uc.deliveries[def.Channel]
+		if !ok {
+			return fmt.Errorf("unsupported channel: %s", def.Channel)
+		}
+		out, err := uc.Render(ctx, def, start, end)
+		if err != nil {
+			return err
+		}
+		run.Rows = int32(len(out.Rows))
+		run.DeliveryRef, err = d.Deliver(ctx, def.ChannelTarget, out)
+		return err
+	}()
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = model.ReportRunStatusSuccess
+	if runErr != nil {
+		run.Status = model.ReportRunStatusFailed
+		run.ErrorMessage = runErr.Error()
+	}
+	if err := uc.repo.FinishRun(ctx, run); err != nil {
+		uc.log.Errorf("finish report run %d failed: %v", run.Id, err)
+	}
+	return run, runErr
+}
+
+// Render 渲染报表内容
+func (uc *ReportUseCase) Render(ctx context.Context, def *model.GameReportDefinition, start, end time.Time) (*RenderedReport, error) {
+	params, err := parseReportParams(def.Params)
+	if err != nil {
+		return nil, err
+	}
+	out := &RenderedReport{
+		DefinitionID: def.Id,
+		Name:         def.Name,
+		HouseGID:     def.HouseGID,
+		ReportType:   def.ReportType,
+		RangeStart:   start,
+		RangeEnd:     end,
+		GeneratedAt:  time.Now(),
+		Rows:         [][]any{},
+	}
+	switch def.ReportType {
+	case model.ReportTypeDailyStats:
+		err = uc.renderDailyStats(ctx, out)
+	case model.ReportTypeLowBalance:
+		err = uc.renderLowBalance(ctx, out, params)
+	case model.ReportTypeTopLosers:
+		err = uc.renderTopLosers(ctx, out, params)
+	case model.ReportTypeFeeSummary:
+		err = uc.renderFeeSummary(ctx, out)
+	case model.ReportTypeSessionOutage:
+		err = uc.renderSessionOutage(ctx, out)
+	default:
+		err = fmt.Errorf("unsupported report type: %s", def.ReportType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (uc *ReportUseCase) renderDailyStats(ctx context.Context, out *RenderedReport) error {
+	house := int(out.HouseGID)
+	ledger, err := uc.stats.AggregateLedger(ctx, house, out.RangeStart, out.RangeEnd)
+	if err != nil {
+		return err
+	}
+	wallet, err := uc.stats.AggregateWallet(ctx, house)
+	if err != nil {
+		return err
+	}
+	sessions, err := uc.stats.CountActiveSessions(ctx, house)
+	if err != nil {
+		return err
+	}
+	out.Summary = map[string]any{
+		"records":             ledger.Records,
+		"members_involved":    ledger.MembersInvolved,
+		"score_total":         ledger.Income,
+		"fee_total":           ledger.Payout,
+		"net":                 ledger.Net,
+		"balance_total":       wallet.BalanceTotal,
+		"wallet_members":      wallet.Members,
+		"low_balance_members": wallet.LowBalanceMembers,
+		"active_sessions":     sessions,
+	}
+	out.Columns = []string{"metric", "value"}
+	for _, k := range []string{"records", "members_involved", "score_total", "fee_total", "net", "balance_total", "wallet_members", "low_balance_members", "active_sessions"} {
+		out.Rows = append(out.Rows, []any{k, out.Summary[k]})
+	}
+	return nil
+}
+
+func (uc *ReportUseCase) renderLowBalance(ctx context.Context, out *RenderedReport, p ReportParams) error {
+	threshold := p.Threshold
+	list, total, err := uc.wallets.ListWallets(ctx, out.HouseGID, p.GroupID, nil, &threshold, nil, 1, int32(p.Limit))
+	if err != nil {
+		return err
+	}
+	out.Summary = map[string]any{"threshold": threshold, "members": total}
+	out.Columns = []string{"member_id", "group_id", "balance", "forbid", "updated_at"}
+	for _, w := range list {
+		var group any
+		if w.GroupID != nil {
+			group = *w.GroupID
+		}
+		out.Rows = append(out.Rows, []any{w.MemberID, group, w.Balance, w.Forbid, w.UpdatedAt})
+	}
+	return nil
+}
+
+func (uc *ReportUseCase) renderTopLosers(ctx context.Context, out *RenderedReport, p ReportParams) error {
+	if uc.rank == nil {
+		return errors.New("leaderboard is not available")
+	}
+	start, end := out.RangeStart, out.RangeEnd
+	board, err := uc.rank.Query(ctx, LeaderboardQuery{
+		HouseGID: out.HouseGID,
+		GroupID:  p.GroupID,
+		Metric:   RankMetricLose,
+		Period:   "custom",
+		Start:    &start,
+		End:      &end,
+		Limit:    p.Limit,
+	})
+	if err != nil {
+		return err
+	}
+	out.Columns = []string{"rank", "game_id", "nickname", "games", "score", "fee"}
+	for _, it := range board.Items {
+		out.Rows = append(out.Rows, []any{it.Rank, it.PlayerGameID, it.PlayerGameName, it.Games, it.Score, it.Fee})
+	}
+	return nil
+}
+
+func (uc *ReportUseCase) renderFeeSummary(ctx context.Context, out *RenderedReport) error {
+	sums, err := uc.fees.ListGroupSums(ctx, out.HouseGID, out.RangeStart, out.RangeEnd)
+	if err != nil {
+		return err
+	}
+	ledger, err := uc.stats.AggregateLedger(ctx, int(out.HouseGID), out.RangeStart, out.RangeEnd)
+	if err != nil {
+		return err
+	}
+	var settled int64
+	out.Columns = []string{"play_group", "amount"}
+	for _, g := range sums {
+		settled += g.Sum
+		out.Rows = append(out.Rows, []any{g.PlayGroup, g.Sum})
+	}
+	out.Summary = map[string]any{"battle_fee_total": ledger.Payout, "settled_total": settled}
+	return nil
+}
+
+func (uc *ReportUseCase) renderSessionOutage(ctx context.Context, out *RenderedReport) error {
+	rows, err := uc.repo.ListSessionOutages(ctx, out.HouseGID, out.RangeStart, out.RangeEnd)
+	if err != nil {
+		return err
+	}
+	var failed int64
+	out.Columns = []string{"session_id", "ctrl_account_id", "state", "error", "end_at", "failed_syncs", "sync_error"}
+	for _, r := range rows {
+		failed += r.FailedSyncs
+		out.Rows = append(out.Rows, []any{r.SessionID, r.GameCtrlAccountID, r.State, r.ErrorMsg, r.EndAt, r.FailedSyncs, r.SyncError})
+	}
+	out.Summary = map[string]any{"sessions": len(rows), "failed_syncs": failed}
+	return nil
+}
+
+func (uc *ReportUseCase) getOwned(ctx context.Context, houseGID, id int32) (*model.GameReportDefinition, error) {
+	m, err := uc.repo.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.HouseGID != houseGID {
+		return nil, errors.New("report does not belong to this house")
+	}
+	return m, nil
+}
+
+// applyInput 校验并写入定义字段，同时计算下次执行时间
+func (uc *ReportUseCase) applyInput(m *model.GameReportDefinition, in ReportDefinitionInput, now time.Time) error {
+	if in.HouseGID <= 0 {
+		return errors.New("invalid house_gid")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	switch in.ReportType {
+	case model.ReportTypeDailyStats, model.ReportTypeLowBalance, model.ReportTypeTopLosers,
+		model.ReportTypeFeeSummary, model.ReportTypeSessionOutage:
+	default:
+		return fmt.Errorf("unsupported report type: %s", in.ReportType)
+	}
+	period := in.Period
+	if period == "" {
+		period = model.ReportPeriodDaily
+	}
+	if _, _, err := reportRange(period, now); err != nil {
+		return err
+	}
+	sched, err := cron.ParseStandard(in.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	switch in.Channel {
+	case model.ReportChannelWebhook:
+		if err := validateWebhookURL(in.ChannelTarget); err != nil {
+			return err
+		}
+	default:
+		if _, ok := uc.deliveries[in.Channel]; !ok {
+			return fmt.Errorf("unsupported channel: %s", in.Channel)
+		}
+	}
+	params, err := json.Marshal(in.Params)
+	if err != nil {
+		return err
+	}
+
+	next := sched.Next(now)
+	m.Name = name
+	m.ReportType = in.ReportType
+	m.Period = period
+	m.Schedule = in.Schedule
+	m.Params = string(params)
+	m.Channel = in.Channel
+	m.ChannelTarget = in.ChannelTarget
+	m.Enabled = in.Enabled
+	m.NextRunAt = &next
+	return nil
+}
+
+func parseReportParams(raw string) (ReportParams, error) {
+	var p ReportParams
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &p); err != nil {
+			return p, fmt.Errorf("invalid report params: %w", err)
+		}
+	}
+	if p.Limit <= 0 {
+		p.Limit = reportDefaultLimit
+	}
+	if p.Limit > reportMaxLimit {
+		p.Limit = reportMaxLimit
+	}
+	return p, nil
+}
+
+// reportRange 报表统计窗口 [start, end)：daily=昨日，weekly=上周
+func reportRange(period string, now time.Time) (time.Time, time.Time, error) {
+	switch period {
+	case model.ReportPeriodDaily:
+		todayStart, _ := dayRange(now)
+		return todayStart.AddDate(0, 0, -1), todayStart, nil
+	case model.ReportPeriodWeekly:
+		weekStart, _ := weekRange(now)
+		return weekStart.AddDate(0, 0, -7), weekStart, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unsupported period: %s", period)
+}
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ReportDelivery 报表投递渠道。target 为报表定义中的 channel_target（webhook 地址等），
+// 返回的 ref 会写入执行记录（文件路径、HTTP 状态等）便于排查。
+type ReportDelivery interface {
+	Deliver(ctx context.Context, target string, r *RenderedReport) (ref string, err error)
+}
+
+// RenderedReport 渲染后的报表内容，各渠道统一以 JSON 投递
+type RenderedReport struct {
+	DefinitionID int32          `json:"definition_id"`
+	Name         string         `json:"name"`
+	HouseGID     int32          `json:"house_gid"`
+	ReportType   string         `json:"report_type"`
+	RangeStart   time.Time      `json:"range_start"`
+	RangeEnd     time.Time      `json:"range_end"`
+	GeneratedAt  time.Time      `json:"generated_at"`
+	Summary      map[string]any `json:"summary,omitempty"`
+	Columns      []string       `json:"columns"`
+	Rows         [][]any        `json:"rows"`
+}
+
+// FileReportDelivery 写入本地目录：<baseDir>/<house_gid>/<report_type>_<definition_id>_<时间>.json。
+// 目录由部署方决定，报表定义里的 target 不参与路径拼接，避免越权写文件。
+type FileReportDelivery struct {
+	baseDir string
+}
+
+func NewFileReportDelivery(baseDir string) *FileReportDelivery {
+	return &FileReportDelivery{baseDir: baseDir}
+}
+
+func (d *FileReportDelivery) Deliver(_ context.Context, _ string, r *RenderedReport) (string, error) {
+	dir := filepath.Join(d.baseDir, fmt.Sprintf("%d", r.HouseGID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	body, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%d_%s.json", r.ReportType, r.DefinitionID, r.GeneratedAt.Format("20060102150405"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WebhookReportDelivery 以 JSON POST 到 target，非 2xx 视为失败
+type WebhookReportDelivery struct {
+	client *http.Client
+}
+
+func NewWebhookReportDelivery(client *http.Client) *WebhookReportDelivery {
+	if client == nil {
+		client = newWebhookClient(10 * time.Second)
+	}
+	return &WebhookReportDelivery{client: client}
+}
+
+func (d *WebhookReportDelivery) Deliver(ctx context.Context, target string, r *RenderedReport) (string, error) {
+	if err := validateWebhookURL(target); err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	ref := fmt.Sprintf("HTTP %d", res.StatusCode)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return ref, fmt.Errorf("webhook responded %s", res.Status)
+	}
+	return ref, nil
+}
+
+// 出站回调的地址限制：只允许公网地址，防止借回调地址探测内网（SSRF）
+const (
+	webhookResolveTimeout = 5 * time.Second
+	webhookMaxRedirects   = 3
+)
+
+var errWebhookAddrBlocked = errors.New("webhook address is not allowed")
+
+// webhookBlockedPrefixes 标准库分类之外仍需拦截的保留网段
+var webhookBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT，部分云厂商元数据服务在此段
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// webhookAddrAllowed 判断回调可以连接的地址，测试中替换以放行本地服务
+var webhookAddrAllowed = func(ap netip.AddrPort) bool {
+	return isPublicAddr(ap.Addr())
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range webhookBlockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateWebhookURL 校验回调地址：仅 http/https，且主机解析出的全部地址都是公网地址。
+// 解析结果可能在投递时变化，连接时由 newWebhookClient 再校验一次
+func validateWebhookURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook url: %s", target)
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	pn, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %s", target)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve webhook host: %w", err)
+	}
+	for _, addr := range addrs {
+		if !webhookAddrAllowed(netip.AddrPortFrom(addr.Unmap(), uint16(pn))) {
+			return fmt.Errorf("%w: %s resolves to %s", errWebhookAddrBlocked, u.Hostname(), addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// newWebhookClient 出站回调用的 HTTP 客户端：不走代理，实际连接的地址在拨号时校验
+// （覆盖重定向与 DNS 重绑定），重定向次数受限且目标须同样是 http/https
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil || !webhookAddrAllowed(netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port())) {
+				return fmt.Errorf("%w: %s", errWebhookAddrBlocked, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= webhookMaxRedirects {
+				return errors.New("too many webhook redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("invalid webhook redirect: %s", req.URL)
+			}
+			return nil
+		},
+	}
+}
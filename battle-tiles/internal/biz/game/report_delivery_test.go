@@ -0,0 +1,140 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// allowWebhookServers 只放行给定的本地测试服务，其余地址仍按公网规则校验
+func allowWebhookServers(t *testing.T, servers ...*httptest.Server) {
+	allowed := map[netip.AddrPort]bool{}
+	for _, srv := range servers {
+		u, _ := url.Parse(srv.URL)
+		allowed[netip.MustParseAddrPort(u.Host)] = true
+	}
+	prev := webhookAddrAllowed
+	webhookAddrAllowed = func(ap netip.AddrPort) bool { return allowed[ap] || prev(ap) }
+	t.Cleanup(func() { webhookAddrAllowed = prev })
+}
+
+func testReport() *RenderedReport {
+	return &RenderedReport{
+		DefinitionID: 7,
+		Name:         "低余额成员",
+		HouseGID:     20001,
+		ReportType:   "low_balance",
+		GeneratedAt:  time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local),
+		Columns:      []string{"member_id", "balance"},
+		Rows:         [][]any{{1, 0}, {2, -300}},
+	}
+}
+
+func TestFileReportDelivery(t *testing.T) {
+	dir := t.TempDir()
+	ref, err := NewFileReportDelivery(dir).Deliver(context.Background(), "../ignored", testReport())
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if want := filepath.Join(dir, "20001", "low_balance_7_20261019080000.json"); ref != want {
+		t.Fatalf("ref = %q, want %q", ref, want)
+	}
+
+	body, err := os.ReadFile(ref)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var got RenderedReport
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.HouseGID != 20001 || len(got.Rows) != 2 {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+}
+
+func TestWebhookReportDelivery(t *testing.T) {
+	var got RenderedReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content-type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	allowWebhookServers(t, srv)
+
+	ref, err := NewWebhookReportDelivery(nil).Deliver(context.Background(), srv.URL, testReport())
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if ref != "HTTP 204" || got.ReportType != "low_balance" {
+		t.Fatalf("ref = %q, report_type = %q", ref, got.ReportType)
+	}
+}
+
+func TestWebhookReportDeliveryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	allowWebhookServers(t, srv)
+
+	d := NewWebhookReportDelivery(nil)
+	ref, err := d.Deliver(context.Background(), srv.URL, testReport())
+	if err == nil || ref != "HTTP 502" {
+		t.Fatalf("ref = %q, err = %v; want HTTP 502 error", ref, err)
+	}
+	if _, err := d.Deliver(context.Background(), "file:///etc/passwd", testReport()); err == nil {
+		t.Fatal("expected non-http target to be rejected")
+	}
+}
+
+func TestValidateWebhookURLBlocksInternal(t *testing.T) {
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.100.100.200/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[fd00::1]/hook",
+	} {
+		if err := validateWebhookURL(target); !errors.Is(err, errWebhookAddrBlocked) {
+			t.Errorf("%s: err = %v, want blocked", target, err)
+		}
+	}
+	if err := validateWebhookURL("https://203.0.113.10/hook"); err != nil {
+		t.Fatalf("public address rejected: %v", err)
+	}
+}
+
+func TestWebhookReportDeliveryBlocksRedirect(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect to internal address must not be followed")
+	}))
+	defer internal.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+	allowWebhookServers(t, srv)
+
+	if _, err := NewWebhookReportDelivery(nil).Deliver(context.Background(), srv.URL, testReport()); !errors.Is(err, errWebhookAddrBlocked) {
+		t.Fatalf("err = %v, want blocked redirect", err)
+	}
+}
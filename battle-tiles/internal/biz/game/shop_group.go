@@ -7,6 +7,8 @@ import (
 	basicModel "battle-tiles/internal/dal/model/basic"
 	model "battle-tiles/internal/dal/model/game"
 	"battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"gorm.io/gorm"
@@ -16,6 +18,7 @@ type ShopGroupUseCase struct {
 	groupRepo     game.ShopGroupRepo
 	memberRepo    game.ShopGroupMemberRepo
 	shopAdminRepo game.GameShopAdminRepo
+	mgr           plaza.Manager // 与游戏端群组同步
 	log           *log.Helper
 }
 
@@ -23,12 +26,14 @@ func NewShopGroupUseCase(
 	groupRepo game.ShopGroupRepo,
 	memberRepo game.ShopGroupMemberRepo,
 	shopAdminRepo game.GameShopAdminRepo,
+	mgr plaza.Manager,
 	logger log.Logger,
 ) *ShopGroupUseCase {
 	return &ShopGroupUseCase{
 		groupRepo:     groupRepo,
 		memberRepo:    memberRepo,
 		shopAdminRepo: shopAdminRepo,
+		mgr:           mgr,
 		log:           log.NewHelper(log.With(logger, "module", "usecase/shop_group")),
 	}
 }
@@ -65,6 +70,9 @@ func (uc *ShopGroupUseCase) CreateGroup(ctx context.Context, houseGID int32, adm
 	if err := uc.groupRepo.Create(ctx, group); err != nil {
 		return nil, err
 	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "shop_group", group.Id)
+	auditx.SetAfter(ctx, map[string]any{"group_name": groupName, "description": description})
 
 	return group, nil
 }
@@ -90,6 +98,11 @@ func (uc *ShopGroupUseCase) UpdateGroup(ctx context.Context, groupID int32, admi
 		return fmt.Errorf("无权修改该圈子")
 	}
 
+	auditx.SetHouse(ctx, group.HouseGID)
+	auditx.SetTarget(ctx, "shop_group", groupID)
+	auditx.SetBefore(ctx, map[string]any{"group_name": group.GroupName, "description": group.Description})
+	auditx.SetAfter(ctx, map[string]any{"group_name": groupName, "description": description})
+
 	group.GroupName = groupName
 	group.Description = description
 
@@ -135,6 +148,10 @@ func (uc *ShopGroupUseCase) AddMembersToGroup(ctx context.Context, groupID int32
 		})
 	}
 
+	auditx.SetHouse(ctx, group.HouseGID)
+	auditx.SetTarget(ctx, "shop_group", groupID)
+	auditx.SetAfter(ctx, map[string]any{"added_user_ids": userIDs})
+
 	return uc.memberRepo.BatchAddMembers(ctx, members)
 }
 
@@ -148,6 +165,9 @@ func (uc *ShopGroupUseCase) RemoveMemberFromGroup(ctx context.Context, groupID i
 	if group.AdminUserID != adminUserID {
 		return fmt.Errorf("无权操作该圈子")
 	}
+	auditx.SetHouse(ctx, group.HouseGID)
+	auditx.SetTarget(ctx, "shop_group", groupID)
+	auditx.SetBefore(ctx, map[string]any{"user_id": userID})
 
 	return uc.memberRepo.RemoveMember(ctx, groupID, userID)
 }
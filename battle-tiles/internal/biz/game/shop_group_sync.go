@@ -0,0 +1,210 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	model "battle-tiles/internal/dal/model/game"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+
+	"github.com/pkg/errors"
+)
+
+// 对账状态
+const (
+	GroupReconcileMatched      = "matched"       // 已关联且名称一致
+	GroupReconcileNameMismatch = "name_mismatch" // 已关联但名称不一致
+	GroupReconcileMissingLocal = "missing_local" // 游戏端有、本地没有关联的圈子
+	GroupReconcileMissingGame  = "missing_game"  // 本地已关联，但中控账号所在群组中没有
+	GroupReconcileUnlinked     = "unlinked"      // 本地圈子未同步到游戏
+)
+
+// GroupReconcileItem 一条对账结果
+type GroupReconcileItem struct {
+	Status       string `json:"status"`
+	GameGroupID  int32  `json:"game_group_id,omitempty"`
+	LocalGroupID int32  `json:"local_group_id,omitempty"`
+	GameName     string `json:"game_name,omitempty"`
+	LocalName    string `json:"local_name,omitempty"`
+	MemberCount  int    `json:"member_count,omitempty"` // 游戏端成员数
+	Imported     bool   `json:"imported,omitempty"`     // 本次导入时新建了本地圈子
+}
+
+// GroupReconcileReport 本地圈子与游戏端群组的对账报告
+type GroupReconcileReport struct {
+	HouseGID int32                 `json:"house_gid"`
+	Items    []*GroupReconcileItem `json:"items"`
+	Summary  map[string]int        `json:"summary"` // 状态 -> 条数
+	Imported int                   `json:"imported"`
+}
+
+// MirrorGroup 把本地圈子同步到游戏：未关联时以中控账号创建群组并记录关联，已关联时同步名称
+func (uc *ShopGroupUseCase) MirrorGroup(ctx context.Context, opUser, houseGID, groupID int32) (*model.GameShopGroup, error) {
+	group, err := uc.houseGroup(ctx, houseGID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := onlineSession(uc.mgr, opUser, houseGID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.GameGroupID != nil {
+		if err := sess.UpdateGroup(ctx, int(*group.GameGroupID), group.GroupName); err != nil {
+			return nil, errors.Wrap(err, "update game group")
+		}
+		return group, nil
+	}
+
+	before := make(map[uint32]bool)
+	for _, g := range sess.ListGroups() {
+		before[g.GroupID] = true
+	}
+	created, err := sess.CreateGroup(ctx, group.GroupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "create game group")
+	}
+	if created == nil {
+		// 只收到“操作成功”：在群组列表中找新出现的同名群组
+		for _, g := range sess.ListGroups() {
+			if !before[g.GroupID] && g.Name == group.GroupName {
+				created = g
+			}
+		}
+	}
+	if created == nil {
+		return nil, errors.New("game group created but not yet listed, retry later")
+	}
+	gameID := int32(created.GroupID)
+	if err := uc.groupRepo.SetGameGroupID(ctx, group.Id, &gameID); err != nil {
+		return nil, err
+	}
+	group.GameGroupID = &gameID
+	uc.log.Infof("group %d mirrored to game group %d (house %d)", group.Id, gameID, houseGID)
+	return group, nil
+}
+
+// DeleteGroup 停用本地圈子；inGame 时同时删除已关联的游戏端群组（失败则本地不变）
+func (uc *ShopGroupUseCase) DeleteGroup(ctx context.Context, opUser, houseGID, groupID int32, inGame bool) error {
+	group, err := uc.houseGroup(ctx, houseGID, groupID)
+	if err != nil {
+		return err
+	}
+	if inGame && group.GameGroupID != nil {
+		sess, err := onlineSession(uc.mgr, opUser, houseGID)
+		if err != nil {
+			return err
+		}
+		if err := sess.DeleteGroup(ctx, int(*group.GameGroupID)); err != nil {
+			return errors.Wrap(err, "delete game group")
+		}
+	}
+	return uc.groupRepo.Deactivate(ctx, group.Id)
+}
+
+// SearchGameGroup 在游戏端按群组标识搜索
+func (uc *ShopGroupUseCase) SearchGameGroup(ctx context.Context, opUser, houseGID, gameGroupID int32) (*plazaUtils.GroupProperty, error) {
+	sess, err := onlineSession(uc.mgr, opUser, houseGID)
+	if err != nil {
+		return nil, err
+	}
+	return sess.SearchGroup(ctx, int(gameGroupID))
+}
+
+// ReconcileGroups 比对本地圈子与中控账号所在的游戏群组；importMissing 时为游戏端独有的群组建本地圈子（未指定圈主）
+func (uc *ShopGroupUseCase) ReconcileGroups(ctx context.Context, opUser, houseGID int32, importMissing bool) (*GroupReconcileReport, error) {
+	sess, err := onlineSession(uc.mgr, opUser, houseGID)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := uc.groupRepo.ListByHouse(ctx, houseGID)
+	if err != nil {
+		return nil, err
+	}
+	report := reconcileGroups(houseGID, locals, sess.ListGroups())
+
+	if importMissing {
+		for _, it := range report.Items {
+			if it.Status != GroupReconcileMissingLocal {
+				continue
+			}
+			gameID := it.GameGroupID
+			g := &model.GameShopGroup{
+				HouseGID:    houseGID,
+				GroupName:   it.GameName,
+				Description: fmt.Sprintf("从游戏导入（群组 %d）", gameID),
+				IsActive:    true,
+				GameGroupID: &gameID,
+			}
+			if err := uc.groupRepo.Create(ctx, g); err != nil {
+				return report, errors.Wrapf(err, "import game group %d", gameID)
+			}
+			it.LocalGroupID = g.Id
+			it.LocalName = g.GroupName
+			it.Imported = true
+			report.Imported++
+		}
+	}
+	return report, nil
+}
+
+// reconcileGroups 按 game_group_id 关联比对；游戏端在前（按群组标识），本地未关联的在后
+func reconcileGroups(houseGID int32, locals []*model.GameShopGroup, games []*plazaUtils.GroupProperty) *GroupReconcileReport {
+	report := &GroupReconcileReport{HouseGID: houseGID, Summary: map[string]int{}}
+	linked := make(map[int32]*model.GameShopGroup)
+	var unlinked []*model.GameShopGroup
+	for _, l := range locals {
+		if l.GameGroupID != nil {
+			linked[*l.GameGroupID] = l
+		} else {
+			unlinked = append(unlinked, l)
+		}
+	}
+
+	for _, g := range games {
+		it := &GroupReconcileItem{GameGroupID: int32(g.GroupID), GameName: g.Name, MemberCount: int(g.MemberCount)}
+		if l, ok := linked[it.GameGroupID]; ok {
+			delete(linked, it.GameGroupID)
+			it.LocalGroupID, it.LocalName = l.Id, l.GroupName
+			it.Status = GroupReconcileMatched
+			if strings.TrimSpace(l.GroupName) != strings.TrimSpace(g.Name) {
+				it.Status = GroupReconcileNameMismatch
+			}
+		} else {
+			it.Status = GroupReconcileMissingLocal
+		}
+		report.Items = append(report.Items, it)
+	}
+
+	rest := make([]*model.GameShopGroup, 0, len(linked))
+	for _, l := range linked {
+		rest = append(rest, l)
+	}
+	sort.Slice(rest, func(i, j int) bool { return *rest[i].GameGroupID < *rest[j].GameGroupID })
+	for _, l := range rest {
+		report.Items = append(report.Items, &GroupReconcileItem{
+			Status: GroupReconcileMissingGame, GameGroupID: *l.GameGroupID, LocalGroupID: l.Id, LocalName: l.GroupName,
+		})
+	}
+	for _, l := range unlinked {
+		report.Items = append(report.Items, &GroupReconcileItem{Status: GroupReconcileUnlinked, LocalGroupID: l.Id, LocalName: l.GroupName})
+	}
+
+	for _, it := range report.Items {
+		report.Summary[it.Status]++
+	}
+	return report
+}
+
+func (uc *ShopGroupUseCase) houseGroup(ctx context.Context, houseGID, groupID int32) (*model.GameShopGroup, error) {
+	group, err := uc.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.HouseGID != houseGID {
+		return nil, fmt.Errorf("圈子不属于该店铺")
+	}
+	return group, nil
+}
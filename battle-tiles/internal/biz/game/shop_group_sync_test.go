@@ -0,0 +1,47 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"testing"
+)
+
+func TestReconcileGroups(t *testing.T) {
+	id := func(v int32) *int32 { return &v }
+	locals := []*model.GameShopGroup{
+		{Id: 1, GroupName: "一圈", GameGroupID: id(101)},
+		{Id: 2, GroupName: "二圈（旧名）", GameGroupID: id(102)},
+		{Id: 3, GroupName: "已解散", GameGroupID: id(109)},
+		{Id: 4, GroupName: "未同步"},
+	}
+	games := []*plazaUtils.GroupProperty{
+		{GroupID: 101, Name: "一圈"},
+		{GroupID: 102, Name: "二圈"},
+		{GroupID: 103, Name: "三圈", MemberCount: 7},
+	}
+
+	r := reconcileGroups(20001, locals, games)
+	want := []struct {
+		status string
+		game   int32
+		local  int32
+	}{
+		{GroupReconcileMatched, 101, 1},
+		{GroupReconcileNameMismatch, 102, 2},
+		{GroupReconcileMissingLocal, 103, 0},
+		{GroupReconcileMissingGame, 109, 3},
+		{GroupReconcileUnlinked, 0, 4},
+	}
+	if len(r.Items) != len(want) {
+		t.Fatalf("items = %d, want %d", len(r.Items), len(want))
+	}
+	for i, w := range want {
+		it := r.Items[i]
+		if it.Status != w.status || it.GameGroupID != w.game || it.LocalGroupID != w.local {
+			t.Fatalf("item %d = %+v, want %+v", i, it, w)
+		}
+	}
+	if r.Summary[GroupReconcileMatched] != 1 || r.Summary[GroupReconcileMissingLocal] != 1 {
+		t.Fatalf("summary = %v", r.Summary)
+	}
+}
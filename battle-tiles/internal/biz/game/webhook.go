@@ -0,0 +1,449 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/infra"
+	"battle-tiles/pkg/plugin/auditx"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/plugin/eventx"
+	"battle-tiles/pkg/utils/sealx"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// WebhookDeliverTask asynq 任务类型，由 go-kgin-asynq 进程消费
+	WebhookDeliverTask = "webhook:deliver"
+	// webhookPingEvent 测试投递使用的事件类型，不可订阅
+	webhookPingEvent = "ping"
+
+	webhookMaxAttempts  = 8 // 含首次投递；重试间隔使用 asynq 默认的指数退避
+	webhookTaskTimeout  = 30 * time.Second
+	webhookHTTPTimeout  = 10 * time.Second
+	webhookDrainLimit   = 64 << 10
+	webhookSecretPrefix = "whsec_"
+)
+
+// webhook 请求头。签名为 HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制，接收方应校验时间戳防重放
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// WebhookEndpointInput 新建/修改回调地址
+type WebhookEndpointInput struct {
+	HouseGID            int32
+	Name                string
+	URL                 string
+	Events              []string
+	LowBalanceThreshold int32
+	Enabled             bool
+}
+
+// FundsEventData funds.* / member.low_balance 事件数据
+type FundsEventData struct {
+	MemberID      int32  `json:"member_id"`
+	Amount        int32  `json:"amount"`
+	BalanceBefore int32  `json:"balance_before"`
+	BalanceAfter  int32  `json:"balance_after"`
+	BizNo         string `json:"biz_no,omitempty"`
+	Force         bool   `json:"force,omitempty"`
+	Threshold     *int32 `json:"threshold,omitempty"` // 仅 member.low_balance
+}
+
+// webhookBody 实际 POST 的请求体
+type webhookBody struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	HouseGID   int32     `json:"house_gid"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// WebhookUseCase 店铺 webhook：回调地址管理、按订阅生成投递记录并经 asynq 投递/重试、投递记录与手动重投
+type WebhookUseCase struct {
+	repo    repo.WebhookRepo
+	keyring *sealx.Keyring
+	queue   *infra.TaskQueue
+	client  *http.Client
+	log     *log.Helper
+}
+
+func NewWebhookUseCase(r repo.WebhookRepo, keyring *sealx.Keyring, queue *infra.TaskQueue, logger log.Logger) *WebhookUseCase {
+	// client 与报表推送共用：拒绝内网/回环/元数据地址，连接时复核解析结果
+	uc := &WebhookUseCase{
+		repo:    r,
+		keyring: keyring,
+		queue:   queue,
+		client:  newWebhookClient(webhookHTTPTimeout),
+		log:     log.NewHelper(log.With(logger, "module", "usecase/webhook")),
+	}
+	// 作为业务事件的处理方：eventx.Publish 的事件按店铺订阅转成投递任务
+	eventx.Bind(uc)
+	return uc
+}
+
+// ===== 回调地址管理 =====
+
+// CreateEndpoint 新建回调地址，返回签名密钥明文（仅此一次）
+func (uc *WebhookUseCase) CreateEndpoint(ctx context.Context, opUser int32, in WebhookEndpointInput) (*model.GameWebhookEndpoint, string, error) {
+	if err := validateWebhookInput(&in); err != nil {
+		return nil, "", err
+	}
+	secret, sealed, ver, err := uc.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	m := &model.GameWebhookEndpoint{
+		HouseGID:            in.HouseGID,
+		Name:                in.Name,
+		URL:                 in.URL,
+		Secret:              sealed,
+		SecretKeyVer:        ver,
+		Events:              in.Events,
+		LowBalanceThreshold: in.LowBalanceThreshold,
+		Enabled:             in.Enabled,
+		CreatedBy:           opUser,
+	}
+	if err := uc.repo.CreateEndpoint(ctx, m); err != nil {
+		return nil, "", err
+	}
+	auditx.SetHouse(ctx, m.HouseGID)
+	auditx.SetTarget(ctx, "webhook_endpoint", m.Id)
+	auditx.SetAfter(ctx, m)
+	return m, secret, nil
+}
+
+// UpdateEndpoint 修改回调地址（不改密钥）
+func (uc *WebhookUseCase) UpdateEndpoint(ctx context.Context, id int32, in WebhookEndpointInput) (*model.GameWebhookEndpoint, error) {
+	if err := validateWebhookInput(&in); err != nil {
+		return nil, err
+	}
+	m, err := uc.getEndpoint(ctx, in.HouseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *m
+	m.Name = in.Name
+	m.URL = in.URL
+	m.Events = in.Events
+	m.LowBalanceThreshold = in.LowBalanceThreshold
+	m.Enabled = in.Enabled
+	if err := uc.repo.UpdateEndpoint(ctx, m); err != nil {
+		return nil, err
+	}
+	auditx.SetHouse(ctx, m.HouseGID)
+	auditx.SetTarget(ctx, "webhook_endpoint", m.Id)
+	auditx.SetBefore(ctx, before)
+	auditx.SetAfter(ctx, m)
+	return m, nil
+}
+
+// RotateSecret 重置签名密钥，返回新密钥明文；旧密钥立即失效
+func (uc *WebhookUseCase) RotateSecret(ctx context.Context, houseGID, id int32) (string, error) {
+	m, err := uc.getEndpoint(ctx, houseGID, id)
+	if err != nil {
+		return "", err
+	}
+	secret, sealed, ver, err := uc.newSecret()
+	if err != nil {
+		return "", err
+	}
+	m.Secret, m.SecretKeyVer = sealed, ver
+	if err := uc.repo.UpdateEndpoint(ctx, m); err != nil {
+		return "", err
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "webhook_endpoint", id)
+	return secret, nil
+}
+
+// DeleteEndpoint 删除回调地址；已有投递记录保留
+func (uc *WebhookUseCase) DeleteEndpoint(ctx context.Context, houseGID, id int32) error {
+	if err := uc.repo.DeleteEndpoint(ctx, houseGID, id); err != nil {
+		return err
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "webhook_endpoint", id)
+	return nil
+}
+
+func (uc *WebhookUseCase) ListEndpoints(ctx context.Context, houseGID int32) ([]*model.GameWebhookEndpoint, error) {
+	return uc.repo.ListEndpoints(ctx, houseGID)
+}
+
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, houseGID int32, endpointID *int32, status string, page, size int32) ([]*model.GameWebhookDelivery, int64, error) {
+	return uc.repo.ListDeliveries(ctx, houseGID, endpointID, status, page, size)
+}
+
+// ===== 事件 -> 投递 =====
+
+// PublishEvent 实现 eventx.Publisher：为订阅了该事件的已启用地址各生成一条投递记录并入队。
+// funds.withdraw 额外按各地址的 low_balance_threshold 派生 member.low_balance。
+func (uc *WebhookUseCase) PublishEvent(ctx context.Context, e *eventx.Event) error {
+	eps, err := uc.repo.ListEnabledEndpoints(ctx, e.HouseGID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ep := range eps {
+		if ep.Subscribed(e.Type) {
+			if err := uc.enqueue(ctx, ep, e.ID, e.Type, e.OccurredAt, e.Data); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if e.Type == eventx.TypeFundsWithdraw && ep.Subscribed(eventx.TypeMemberLowBalance) {
+			if d, ok := e.Data.(FundsEventData); ok && d.BalanceBefore > ep.LowBalanceThreshold && d.BalanceAfter <= ep.LowBalanceThreshold {
+				threshold := ep.LowBalanceThreshold
+				d.Threshold = &threshold
+				if err := uc.enqueue(ctx, ep, uuid.NewString(), eventx.TypeMemberLowBalance, e.OccurredAt, d); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (uc *WebhookUseCase) enqueue(ctx context.Context, ep *model.GameWebhookEndpoint, eventID, eventType string, at time.Time, data any) error {
+	d, err := uc.createDelivery(ctx, ep, eventID, eventType, at, data)
+	if err != nil {
+		return err
+	}
+	return uc.dispatch(ctx, d)
+}
+
+func (uc *WebhookUseCase) createDelivery(ctx context.Context, ep *model.GameWebhookEndpoint, eventID, eventType string, at time.Time, data any) (*model.GameWebhookDelivery, error) {
+	body, err := json.Marshal(webhookBody{ID: eventID, Type: eventType, HouseGID: ep.HouseGID, OccurredAt: at, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	d := &model.GameWebhookDelivery{
+		EndpointID: ep.Id,
+		HouseGID:   ep.HouseGID,
+		EventID:    eventID,
+		EventType:  eventType,
+		Payload:    string(body),
+		Status:     model.WebhookDeliveryPending,
+	}
+	if err := uc.repo.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// dispatch 投递任务入队；入队失败时记录在投递记录上，可手动重投
+func (uc *WebhookUseCase) dispatch(ctx context.Context, d *model.GameWebhookDelivery) error {
+	err := uc.queue.Enqueue(ctx, WebhookDeliverTask, pdb.GetDBKeyFromCtx(ctx),
+		map[string]any{"delivery_id": d.Id},
+		asynq.MaxRetry(webhookMaxAttempts-1),
+		asynq.Timeout(webhookTaskTimeout),
+	)
+	if err != nil {
+		d.LastError = err.Error()
+		_ = uc.repo.SaveAttempt(ctx, d)
+		return fmt.Errorf("delivery %d: %w", d.Id, err)
+	}
+	return nil
+}
+
+// Deliver asynq 任务入口：投递一次。返回 error 时由 asynq 退避重试；
+// 成功、重试耗尽或地址已删除/停用时返回 nil 结束任务。
+func (uc *WebhookUseCase) Deliver(ctx context.Context, deliveryID int32) error {
+	d, err := uc.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if d.Status != model.WebhookDeliveryPending {
+		return nil
+	}
+	ep, err := uc.repo.GetEndpoint(ctx, d.EndpointID)
+	if err != nil || !ep.Enabled {
+		d.Status = model.WebhookDeliveryFailed
+		d.LastError = "endpoint removed or disabled"
+		return uc.repo.SaveAttempt(ctx, d)
+	}
+	attemptErr := uc.attempt(ctx, ep, d)
+	if attemptErr != nil && d.Attempts >= webhookMaxAttempts {
+		d.Status = model.WebhookDeliveryFailed
+	}
+	if err := uc.repo.SaveAttempt(ctx, d); err != nil {
+		return err
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		return attemptErr
+	}
+	return nil
+}
+
+// Redeliver 手动重投：重置次数后重新入队，请求体与事件 ID 不变
+func (uc *WebhookUseCase) Redeliver(ctx context.Context, houseGID, deliveryID int32) error {
+	d, err := uc.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if d.HouseGID != houseGID {
+		return errors.New("delivery not found in this house")
+	}
+	if err := uc.repo.ResetDelivery(ctx, d.Id); err != nil {
+		return err
+	}
+	auditx.SetHouse(ctx, houseGID)
+	auditx.SetTarget(ctx, "webhook_delivery", d.Id)
+	return uc.dispatch(ctx, d)
+}
+
+// Test 向指定地址同步发送一次 ping 事件并返回投递记录，不经过队列、不重试
+func (uc *WebhookUseCase) Test(ctx context.Context, houseGID, id int32) (*model.GameWebhookDelivery, error) {
+	ep, err := uc.getEndpoint(ctx, houseGID, id)
+	if err != nil {
+		return nil, err
+	}
+	d, err := uc.createDelivery(ctx, ep, uuid.NewString(), webhookPingEvent, time.Now(), map[string]any{"endpoint_id": ep.Id})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.attempt(ctx, ep, d); err != nil {
+		d.Status = model.WebhookDeliveryFailed
+	}
+	if err := uc.repo.SaveAttempt(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// attempt 发送一次并把结果写回 d（不落库）
+func (uc *WebhookUseCase) attempt(ctx context.Context, ep *model.GameWebhookEndpoint, d *model.GameWebhookDelivery) error {
+	now := time.Now()
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.LastStatusCode = 0
+
+	err := uc.post(ctx, ep, d)
+	if err != nil {
+		d.LastError = err.Error()
+		return err
+	}
+	d.Status = model.WebhookDeliverySuccess
+	d.LastError = ""
+	d.DeliveredAt = &now
+	return nil
+}
+
+func (uc *WebhookUseCase) post(ctx context.Context, ep *model.GameWebhookEndpoint, d *model.GameWebhookDelivery) error {
+	secret, err := uc.keyring.Open(ep.Secret, ep.SecretKeyVer)
+	if err != nil {
+		return fmt.Errorf("open secret: %w", err)
+	}
+	body := []byte(d.Payload)
+	ts := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderEvent, d.EventType)
+	req.Header.Set(WebhookHeaderDelivery, d.EventID)
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhook(secret, ts, body))
+
+	res, err := uc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	d.LastStatusCode = int32(res.StatusCode)
+	// 响应体不回显到投递记录，避免把目标地址的内容透出给调用方
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, webhookDrainLimit))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", res.Status)
+	}
+	return nil
+}
+
+// SignWebhook 计算签名头："sha256=" + hex(HMAC-SHA256(secret, "<timestamp>.<body>"))
+func SignWebhook(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (uc *WebhookUseCase) getEndpoint(ctx context.Context, houseGID, id int32) (*model.GameWebhookEndpoint, error) {
+	m, err := uc.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.HouseGID != houseGID {
+		return nil, errors.New("webhook endpoint not found in this house")
+	}
+	return m, nil
+}
+
+func (uc *WebhookUseCase) newSecret() (plain, sealed string, ver int32, err error) {
+	buf := make([]byte, 24)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", 0, err
+	}
+	plain = webhookSecretPrefix + hex.EncodeToString(buf)
+	sealed, ver, err = uc.keyring.Seal(plain)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("seal webhook secret: %w", err)
+	}
+	return plain, sealed, ver, nil
+}
+
+func validateWebhookInput(in *WebhookEndpointInput) error {
+	in.URL = strings.TrimSpace(in.URL)
+	if err := validateWebhookURL(in.URL); err != nil {
+		return err
+	}
+	if len(in.Events) == 0 {
+		return errors.New("events is required")
+	}
+	seen := make(map[string]bool, len(in.Events))
+	events := make([]string, 0, len(in.Events))
+	for _, t := range in.Events {
+		if !isWebhookEventType(t) {
+			return fmt.Errorf("unknown event type: %s", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			events = append(events, t)
+		}
+	}
+	in.Events = events
+	if in.LowBalanceThreshold < 0 {
+		return errors.New("low_balance_threshold must be >= 0")
+	}
+	return nil
+}
+
+func isWebhookEventType(t string) bool {
+	for _, v := range eventx.Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/pkg/utils/sealx"
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testWebhookUseCase(t *testing.T) (*WebhookUseCase, *model.GameWebhookEndpoint, string) {
+	t.Helper()
+	kr, err := sealx.NewKeyring(1, map[int32]string{1: base64.StdEncoding.EncodeToString(make([]byte, 32))})
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	uc := &WebhookUseCase{keyring: kr, client: http.DefaultClient}
+	plain, sealed, ver, err := uc.newSecret()
+	if err != nil {
+		t.Fatalf("secret: %v", err)
+	}
+	return uc, &model.GameWebhookEndpoint{Id: 1, HouseGID: 20001, Secret: sealed, SecretKeyVer: ver}, plain
+}
+
+func TestWebhookAttemptSigned(t *testing.T) {
+	uc, ep, secret := testWebhookUseCase(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, err := strconv.ParseInt(r.Header.Get(WebhookHeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("timestamp: %v", err)
+		}
+		if want := SignWebhook(secret, ts, body); !hmac.Equal([]byte(want), []byte(r.Header.Get(WebhookHeaderSignature))) {
+			t.Errorf("signature mismatch")
+		}
+		if got := r.Header.Get(WebhookHeaderEvent); got != "funds.deposit" {
+			t.Errorf("event header = %q", got)
+		}
+		if got := r.Header.Get(WebhookHeaderDelivery); got != "evt-1" {
+			t.Errorf("delivery header = %q", got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	ep.URL = srv.URL
+
+	d := &model.GameWebhookDelivery{EventID: "evt-1", EventType: "funds.deposit", Payload: `{"id":"evt-1"}`, Status: model.WebhookDeliveryPending}
+	if err := uc.attempt(context.Background(), ep, d); err != nil {
+		t.Fatalf("attempt: %v", err)
+	}
+	if d.Status != model.WebhookDeliverySuccess || d.Attempts != 1 || d.LastStatusCode != http.StatusNoContent || d.DeliveredAt == nil {
+		t.Fatalf("unexpected delivery: %+v", d)
+	}
+}
+
+func TestWebhookAttemptFailure(t *testing.T) {
+	uc, ep, _ := testWebhookUseCase(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	ep.URL = srv.URL
+
+	d := &model.GameWebhookDelivery{EventID: "evt-2", EventType: "ping", Payload: `{}`, Status: model.WebhookDeliveryPending}
+	if err := uc.attempt(context.Background(), ep, d); err == nil {
+		t.Fatal("expected error for 502")
+	}
+	if d.Status != model.WebhookDeliveryPending || d.LastStatusCode != http.StatusBadGateway || d.LastError == "" {
+		t.Fatalf("unexpected delivery: %+v", d)
+	}
+	if strings.Contains(d.LastError, "boom") {
+		t.Fatalf("response body echoed into last_error: %q", d.LastError)
+	}
+}
+
+// memWebhooks 内存版回调地址/投递记录
+type memWebhooks struct {
+	repo.WebhookRepo
+	endpoints map[int32]*model.GameWebhookEndpoint
+	saved     []model.GameWebhookDelivery
+}
+
+func (r *memWebhooks) GetEndpoint(_ context.Context, id int32) (*model.GameWebhookEndpoint, error) {
+	ep, ok := r.endpoints[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return ep, nil
+}
+
+func (r *memWebhooks) UpdateEndpoint(_ context.Context, m *model.GameWebhookEndpoint) error {
+	r.endpoints[m.Id] = m
+	return nil
+}
+
+func (r *memWebhooks) CreateDelivery(_ context.Context, d *model.GameWebhookDelivery) error {
+	d.Id = int32(len(r.saved) + 1)
+	return nil
+}
+
+func (r *memWebhooks) SaveAttempt(_ context.Context, d *model.GameWebhookDelivery) error {
+	r.saved = append(r.saved, *d)
+	return nil
+}
+
+func TestWebhookTestUsesGuardedClientAndHouse(t *testing.T) {
+	uc, ep, _ := testWebhookUseCase(t)
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	ep.URL = srv.URL
+	store := &memWebhooks{endpoints: map[int32]*model.GameWebhookEndpoint{ep.Id: ep}}
+	uc.repo = store
+	uc.client = newWebhookClient(time.Second)
+	ctx := context.Background()
+
+	// 其他店铺的地址：不可测试、不可重置密钥
+	if _, err := uc.Test(ctx, 20002, ep.Id); err == nil {
+		t.Fatal("testing another house's endpoint should fail")
+	}
+	if _, err := uc.RotateSecret(ctx, 20002, ep.Id); err == nil {
+		t.Fatal("rotating another house's secret should fail")
+	}
+
+	// 回环地址在连接时被拦截
+	d, err := uc.Test(ctx, ep.HouseGID, ep.Id)
+	if err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if d.Status != model.WebhookDeliveryFailed || !strings.Contains(d.LastError, "not allowed") || atomic.LoadInt32(&hits) != 0 {
+		t.Fatalf("loopback delivery = %+v, hits = %d", d, hits)
+	}
+
+	allowWebhookServers(t, srv)
+	if d, err = uc.Test(ctx, ep.HouseGID, ep.Id); err != nil {
+		t.Fatalf("test: %v", err)
+	}
+	if d.Status != model.WebhookDeliverySuccess || atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("allowed delivery = %+v, hits = %d", d, hits)
+	}
+}
+
+func TestValidateWebhookInput(t *testing.T) {
+	in := WebhookEndpointInput{URL: " https://203.0.113.10/hook ", Events: []string{"funds.deposit", "funds.deposit"}}
+	if err := validateWebhookInput(&in); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if in.URL != "https://203.0.113.10/hook" || len(in.Events) != 1 {
+		t.Fatalf("not normalized: %+v", in)
+	}
+	if err := validateWebhookInput(&WebhookEndpointInput{URL: "https://example.com", Events: []string{"nope"}}); err == nil {
+		t.Fatal("expected unknown event error")
+	}
+	if err := validateWebhookInput(&WebhookEndpointInput{URL: "ftp://example.com", Events: []string{"ping"}}); err == nil {
+		t.Fatal("expected url error")
+	}
+}
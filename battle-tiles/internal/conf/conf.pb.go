@@ -208,8 +208,9 @@ func (x *Data) GetRedisList() []*Data_Redis {
 // ============== Global ==============
 type Global struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rsa           *Global_RSA            `protobuf:"bytes,1,opt,name=rsa,proto3" json:"rsa,omitempty"`   // YAML: global.rsa
-	Game          *Global_Game           `protobuf:"bytes,2,opt,name=game,proto3" json:"game,omitempty"` // YAML: global.game
+	Rsa           *Global_RSA            `protobuf:"bytes,1,opt,name=rsa,proto3" json:"rsa,omitempty"`       // YAML: global.rsa
+	Game          *Global_Game           `protobuf:"bytes,2,opt,name=game,proto3" json:"game,omitempty"`     // YAML: global.game
+	Crypto        *Global_Crypto         `protobuf:"bytes,3,opt,name=crypto,proto3" json:"crypto,omitempty"` // YAML: global.crypto
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -258,6 +259,13 @@ func (x *Global) GetGame() *Global_Game {
 	return nil
 }
 
+func (x *Global) GetCrypto() *Global_Crypto {
+	if x != nil {
+		return x.Crypto
+	}
+	return nil
+}
+
 type Server_HTTP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
@@ -621,6 +629,7 @@ func (x *Global_RSA) GetPrivate() string {
 type Global_Game struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Plaza         *Global_Game_Plaza     `protobuf:"bytes,1,opt,name=plaza,proto3" json:"plaza,omitempty"`
+	Gro           *Global_Game_Gro       `protobuf:"bytes,2,opt,name=gro,proto3" json:"gro,omitempty"` // YAML: global.game.gro
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -662,6 +671,65 @@ func (x *Global_Game) GetPlaza() *Global_Game_Plaza {
 	return nil
 }
 
+func (x *Global_Game) GetGro() *Global_Game_Gro {
+	if x != nil {
+		return x.Gro
+	}
+	return nil
+}
+
+type Global_Crypto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveVersion int32                  `protobuf:"varint,1,opt,name=active_version,json=activeVersion,proto3" json:"active_version,omitempty"`
+	Keys          map[int32]string       `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Global_Crypto) Reset() {
+	*x = Global_Crypto{}
+	mi := &file_conf_conf_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Global_Crypto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Global_Crypto) ProtoMessage() {}
+
+func (x *Global_Crypto) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Global_Crypto.ProtoReflect.Descriptor instead.
+func (*Global_Crypto) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *Global_Crypto) GetActiveVersion() int32 {
+	if x != nil {
+		return x.ActiveVersion
+	}
+	return 0
+}
+
+func (x *Global_Crypto) GetKeys() map[int32]string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
 type Global_Game_Plaza struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Server82         string                 `protobuf:"bytes,1,opt,name=server82,proto3" json:"server82,omitempty"`                                          // YAML: global.game.plaza.server82
@@ -674,7 +742,7 @@ type Global_Game_Plaza struct {
 
 func (x *Global_Game_Plaza) Reset() {
 	*x = Global_Game_Plaza{}
-	mi := &file_conf_conf_proto_msgTypes[11]
+	mi := &file_conf_conf_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -686,7 +754,7 @@ func (x *Global_Game_Plaza) String() string {
 func (*Global_Game_Plaza) ProtoMessage() {}
 
 func (x *Global_Game_Plaza) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_conf_proto_msgTypes[11]
+	mi := &file_conf_conf_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -730,6 +798,98 @@ func (x *Global_Game_Plaza) GetAutoReconnect() bool {
 	return false
 }
 
+type Global_Game_Gro struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	BattleUrl              string                 `protobuf:"bytes,1,opt,name=battle_url,json=battleUrl,proto3" json:"battle_url,omitempty"`                                           // YAML: global.game.gro.battle_url
+	QueryUrl               string                 `protobuf:"bytes,2,opt,name=query_url,json=queryUrl,proto3" json:"query_url,omitempty"`                                              // YAML: global.game.gro.query_url
+	PrivateKey             string                 `protobuf:"bytes,3,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`                                        // YAML: global.game.gro.private_key
+	TimeoutSeconds         int32                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`                           // YAML: global.game.gro.timeout_seconds
+	Retries                int32                  `protobuf:"varint,5,opt,name=retries,proto3" json:"retries,omitempty"`                                                               // YAML: global.game.gro.retries
+	BreakerFailures        int32                  `protobuf:"varint,6,opt,name=breaker_failures,json=breakerFailures,proto3" json:"breaker_failures,omitempty"`                        // YAML: global.game.gro.breaker_failures
+	BreakerCooldownSeconds int32                  `protobuf:"varint,7,opt,name=breaker_cooldown_seconds,json=breakerCooldownSeconds,proto3" json:"breaker_cooldown_seconds,omitempty"` // YAML: global.game.gro.breaker_cooldown_seconds
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Global_Game_Gro) Reset() {
+	*x = Global_Game_Gro{}
+	mi := &file_conf_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Global_Game_Gro) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Global_Game_Gro) ProtoMessage() {}
+
+func (x *Global_Game_Gro) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Global_Game_Gro.ProtoReflect.Descriptor instead.
+func (*Global_Game_Gro) Descriptor() ([]byte, []int) {
+	return file_conf_conf_proto_rawDescGZIP(), []int{3, 1, 1}
+}
+
+func (x *Global_Game_Gro) GetBattleUrl() string {
+	if x != nil {
+		return x.BattleUrl
+	}
+	return ""
+}
+
+func (x *Global_Game_Gro) GetQueryUrl() string {
+	if x != nil {
+		return x.QueryUrl
+	}
+	return ""
+}
+
+func (x *Global_Game_Gro) GetPrivateKey() string {
+	if x != nil {
+		return x.PrivateKey
+	}
+	return ""
+}
+
+func (x *Global_Game_Gro) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *Global_Game_Gro) GetRetries() int32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Global_Game_Gro) GetBreakerFailures() int32 {
+	if x != nil {
+		return x.BreakerFailures
+	}
+	return 0
+}
+
+func (x *Global_Game_Gro) GetBreakerCooldownSeconds() int32 {
+	if x != nil {
+		return x.BreakerCooldownSeconds
+	}
+	return 0
+}
+
 var File_conf_conf_proto protoreflect.FileDescriptor
 
 const file_conf_conf_proto_rawDesc = "" +
@@ -772,20 +932,38 @@ const file_conf_conf_proto_rawDesc = "" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x0e\n" +
 	"\x02db\x18\x03 \x01(\x05R\x02db\x12\x13\n" +
 	"\x05nq_db\x18\x04 \x01(\x05R\x04nqDb\x12\x14\n" +
-	"\x05alias\x18\x05 \x01(\tR\x05alias\"\xf5\x02\n" +
+	"\x05alias\x18\x05 \x01(\tR\x05alias\"\x88\a\n" +
 	"\x06Global\x12(\n" +
 	"\x03rsa\x18\x01 \x01(\v2\x16.kratos.api.Global.RSAR\x03rsa\x12+\n" +
-	"\x04game\x18\x02 \x01(\v2\x17.kratos.api.Global.GameR\x04game\x1a7\n" +
+	"\x04game\x18\x02 \x01(\v2\x17.kratos.api.Global.GameR\x04game\x121\n" +
+	"\x06crypto\x18\x03 \x01(\v2\x19.kratos.api.Global.CryptoR\x06crypto\x1a7\n" +
 	"\x03RSA\x12\x16\n" +
 	"\x06public\x18\x01 \x01(\tR\x06public\x12\x18\n" +
-	"\aprivate\x18\x02 \x01(\tR\aprivate\x1a\xda\x01\n" +
+	"\aprivate\x18\x02 \x01(\tR\aprivate\x1a\x96\x04\n" +
 	"\x04Game\x123\n" +
-	"\x05plaza\x18\x01 \x01(\v2\x1d.kratos.api.Global.Game.PlazaR\x05plaza\x1a\x9c\x01\n" +
+	"\x05plaza\x18\x01 \x01(\v2\x1d.kratos.api.Global.Game.PlazaR\x05plaza\x12-\n" +
+	"\x03gro\x18\x02 \x01(\v2\x1b.kratos.api.Global.Game.GroR\x03gro\x1a\x9c\x01\n" +
 	"\x05Plaza\x12\x1a\n" +
 	"\bserver82\x18\x01 \x01(\tR\bserver82\x12#\n" +
 	"\rserver87_host\x18\x02 \x01(\tR\fserver87Host\x12+\n" +
 	"\x11keepalive_seconds\x18\x03 \x01(\x05R\x10keepaliveSeconds\x12%\n" +
-	"\x0eauto_reconnect\x18\x04 \x01(\bR\rautoReconnectB!Z\x1fbattle-tiles/internal/conf;confb\x06proto3"
+	"\x0eauto_reconnect\x18\x04 \x01(\bR\rautoReconnect\x1a\x8a\x02\n" +
+	"\x03Gro\x12\x1d\n" +
+	"\n" +
+	"battle_url\x18\x01 \x01(\tR\tbattleUrl\x12\x1b\n" +
+	"\tquery_url\x18\x02 \x01(\tR\bqueryUrl\x12\x1f\n" +
+	"\vprivate_key\x18\x03 \x01(\tR\n" +
+	"privateKey\x12'\n" +
+	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\x12\x18\n" +
+	"\aretries\x18\x05 \x01(\x05R\aretries\x12)\n" +
+	"\x10breaker_failures\x18\x06 \x01(\x05R\x0fbreakerFailures\x128\n" +
+	"\x18breaker_cooldown_seconds\x18\a \x01(\x05R\x16breakerCooldownSeconds\x1a\xa1\x01\n" +
+	"\x06Crypto\x12%\n" +
+	"\x0eactive_version\x18\x01 \x01(\x05R\ractiveVersion\x127\n" +
+	"\x04keys\x18\x02 \x03(\v2#.kratos.api.Global.Crypto.KeysEntryR\x04keys\x1a7\n" +
+	"\tKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B!Z\x1fbattle-tiles/internal/conf;confb\x06proto3"
 
 var (
 	file_conf_conf_proto_rawDescOnce sync.Once
@@ -799,7 +977,7 @@ func file_conf_conf_proto_rawDescGZIP() []byte {
 	return file_conf_conf_proto_rawDescData
 }
 
-var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_conf_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_conf_conf_proto_goTypes = []any{
 	(*Bootstrap)(nil),               // 0: kratos.api.Bootstrap
 	(*Server)(nil),                  // 1: kratos.api.Server
@@ -812,8 +990,11 @@ var file_conf_conf_proto_goTypes = []any{
 	(*Data_Redis)(nil),              // 8: kratos.api.Data.Redis
 	(*Global_RSA)(nil),              // 9: kratos.api.Global.RSA
 	(*Global_Game)(nil),             // 10: kratos.api.Global.Game
-	(*Global_Game_Plaza)(nil),       // 11: kratos.api.Global.Game.Plaza
-	(*durationpb.Duration)(nil),     // 12: google.protobuf.Duration
+	(*Global_Crypto)(nil),           // 11: kratos.api.Global.Crypto
+	(*Global_Game_Plaza)(nil),       // 12: kratos.api.Global.Game.Plaza
+	(*Global_Game_Gro)(nil),         // 13: kratos.api.Global.Game.Gro
+	nil,                             // 14: kratos.api.Global.Crypto.KeysEntry
+	(*durationpb.Duration)(nil),     // 15: google.protobuf.Duration
 }
 var file_conf_conf_proto_depIdxs = []int32{
 	1,  // 0: kratos.api.Bootstrap.server:type_name -> kratos.api.Server
@@ -827,14 +1008,17 @@ var file_conf_conf_proto_depIdxs = []int32{
 	8,  // 8: kratos.api.Data.redis_list:type_name -> kratos.api.Data.Redis
 	9,  // 9: kratos.api.Global.rsa:type_name -> kratos.api.Global.RSA
 	10, // 10: kratos.api.Global.game:type_name -> kratos.api.Global.Game
-	12, // 11: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	6,  // 12: kratos.api.Server.Asynq.subscriber:type_name -> kratos.api.Server.Asynq.Subscriber
-	11, // 13: kratos.api.Global.Game.plaza:type_name -> kratos.api.Global.Game.Plaza
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	11, // 11: kratos.api.Global.crypto:type_name -> kratos.api.Global.Crypto
+	15, // 12: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	6,  // 13: kratos.api.Server.Asynq.subscriber:type_name -> kratos.api.Server.Asynq.Subscriber
+	12, // 14: kratos.api.Global.Game.plaza:type_name -> kratos.api.Global.Game.Plaza
+	13, // 15: kratos.api.Global.Game.gro:type_name -> kratos.api.Global.Game.Gro
+	14, // 16: kratos.api.Global.Crypto.keys:type_name -> kratos.api.Global.Crypto.KeysEntry
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_conf_conf_proto_init() }
@@ -848,7 +1032,7 @@ func file_conf_conf_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_conf_proto_rawDesc), len(file_conf_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -100,14 +100,15 @@ const (
 	LEN_GAME_SERVER_ITEM   = 236
 	LEN_CREATE_OPTION_ITEM = 93 + 40 + 16
 
-	LEN_MD5        = 33 //加密密码
-	LEN_ACCOUNTS   = 32 //帐号长度
-	LEN_NICKNAME   = 32 //昵称长度
-	LEN_PASSWORD   = 33 //密码长度
-	LEN_SERVER     = 32 //房名长度
-	LEN_PROCESS    = 32
-	LEN_DOMAIN     = 63
-	LEN_GROUP_NAME = 32
+	LEN_MD5         = 33 //加密密码
+	LEN_ACCOUNTS    = 32 //帐号长度
+	LEN_NICKNAME    = 32 //昵称长度
+	LEN_PASSWORD    = 33 //密码长度
+	LEN_SERVER      = 32 //房名长度
+	LEN_PROCESS     = 32
+	LEN_DOMAIN      = 63
+	LEN_GROUP_NAME  = 32
+	LEN_CONFIG_NAME = 32 //玩法名称
 
 	LEN_MOBILE_PHONE = 16 //移动电话
 	LEN_COMPELLATION = 16 //真实名字
@@ -146,6 +147,7 @@ const (
 	SUB_MB_LIST_AGENT    = 106 //代理列表
 
 	SUB_MB_SERVER_AGENT  = 107 //房间代理
+	SUB_MB_LIST_ACCESS   = 108 //接入列表
 	SUB_MB_LIST_FINISH   = 200 //列表完成
 	SUB_MB_SERVER_FINISH = 201 //房间完成
 
@@ -472,6 +474,8 @@ const (
 	UPMEMBER_KIND_TYPE  = 1
 	UPMEMBER_KIND_RIGHT = 2 //--馆员权限
 
+	MEMBER_RIGHT_FORBID = 0x1000 //--禁止（成员权限位）
+
 	MDM_GA_GROUP_SERVICE = 3 //					--群组服务
 	SUB_GA_ENTER_GROUP   = 1
 
@@ -0,0 +1,36 @@
+package basic
+
+import "time"
+
+const TableNameBasicAPIKey = "basic_api_key"
+
+// BasicAPIKey 服务账号的 API Key。明文只在签发时返回一次，库里只存 sha256；
+// Perms 为该 Key 可用的权限码（不超过签发人自身权限），HouseGIDs 非空时只能访问这些店铺。
+type BasicAPIKey struct {
+	Id         int32      `gorm:"primaryKey;column:id" json:"id"`
+	UserID     int32      `gorm:"column:user_id;not null;index:idx_basic_api_key_user;comment:所属服务账号（basic_user.id）" json:"user_id"`
+	Name       string     `gorm:"column:name;type:varchar(64);not null;comment:用途说明" json:"name"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(16);not null;uniqueIndex:uk_basic_api_key_prefix;comment:公开前缀，用于定位 Key" json:"prefix"`
+	KeyHash    string     `gorm:"column:key_hash;type:varchar(64);not null;comment:sha256(完整 Key)" json:"-"`
+	Perms      []string   `gorm:"column:perms;type:jsonb;not null;serializer:json;comment:权限码" json:"perms"`
+	HouseGIDs  []int32    `gorm:"column:house_gids;type:jsonb;not null;serializer:json;comment:限定店铺，空为不限" json:"house_gids"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;type:timestamp with time zone;comment:过期时间，空为永不过期" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:timestamp with time zone" json:"last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip;type:varchar(64);not null;default:''" json:"last_used_ip"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp with time zone" json:"revoked_at"`
+	RevokedBy  int32      `gorm:"column:revoked_by;not null;default:0" json:"revoked_by"`
+	CreatedBy  int32      `gorm:"column:created_by;not null" json:"created_by"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+}
+
+func (*BasicAPIKey) TableName() string {
+	return TableNameBasicAPIKey
+}
+
+// Active 未吊销且未过期
+func (k *BasicAPIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,34 @@
+package basic
+
+import "time"
+
+const TableNameBasicAuditLog = "basic_audit_log"
+
+// BasicAuditLog 管理操作审计日志（谁、在哪个店铺、对什么对象、做了什么、变更前后）
+type BasicAuditLog struct {
+	Id        int32     `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;index:idx_audit_log_created" json:"created_at"`
+
+	Platform   string  `gorm:"column:platform;type:varchar(64);not null;default:'';comment:平台" json:"platform"`
+	ActorID    int32   `gorm:"column:actor_id;type:int4;not null;default:0;index:idx_audit_log_actor;comment:操作人" json:"actor_id"`
+	ActorName  string  `gorm:"column:actor_name;type:varchar(64);not null;default:'';comment:操作人用户名" json:"actor_name"`
+	HouseGID   int32   `gorm:"column:house_gid;type:int4;not null;default:0;index:idx_audit_log_house;comment:店铺号" json:"house_gid"`
+	Action     string  `gorm:"column:action;type:varchar(100);not null;index:idx_audit_log_action;comment:操作码" json:"action"`
+	Method     string  `gorm:"column:method;type:varchar(10);not null;default:'';comment:HTTP 方法" json:"method"`
+	Path       string  `gorm:"column:path;type:varchar(255);not null;default:'';comment:请求路径" json:"path"`
+	TargetType string  `gorm:"column:target_type;type:varchar(64);not null;default:'';comment:对象类型" json:"target_type"`
+	TargetIDs  string  `gorm:"column:target_ids;type:varchar(512);not null;default:'';comment:对象ID（逗号分隔）" json:"target_ids"`
+	BeforeJSON *string `gorm:"column:before_json;type:jsonb;comment:变更前" json:"before_json"`
+	AfterJSON  *string `gorm:"column:after_json;type:jsonb;comment:变更后" json:"after_json"`
+	Reason     string  `gorm:"column:reason;type:varchar(255);not null;default:'';comment:原因/备注" json:"reason"`
+	ClientIP   string  `gorm:"column:client_ip;type:varchar(64);not null;default:'';comment:客户端IP" json:"client_ip"`
+	UserAgent  string  `gorm:"column:user_agent;type:varchar(255);not null;default:'';comment:UA" json:"user_agent"`
+	Success    bool    `gorm:"column:success;type:bool;not null;default:false;comment:是否成功" json:"success"`
+	ResultCode int32   `gorm:"column:result_code;type:int4;not null;default:0;comment:业务返回码" json:"result_code"`
+	ResultMsg  string  `gorm:"column:result_msg;type:varchar(255);not null;default:'';comment:业务返回信息" json:"result_msg"`
+	LatencyMs  int64   `gorm:"column:latency_ms;type:int8;not null;default:0;comment:耗时（毫秒）" json:"latency_ms"`
+}
+
+func (*BasicAuditLog) TableName() string {
+	return TableNameBasicAuditLog
+}
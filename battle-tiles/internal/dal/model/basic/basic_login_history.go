@@ -0,0 +1,22 @@
+package basic
+
+import "time"
+
+const TableNameBasicLoginHistory = "basic_login_history"
+
+// BasicLoginHistory 登录记录（成功与失败都记）
+type BasicLoginHistory struct {
+	Id         int32     `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;index:idx_login_history_created" json:"created_at"`
+	UserID     int32     `gorm:"column:user_id;type:int4;not null;default:0;index:idx_login_history_user;comment:用户ID（用户不存在时为0）" json:"user_id"`
+	Username   string    `gorm:"column:username;type:varchar(50);not null;default:'';index:idx_login_history_username;comment:登录名" json:"username"`
+	ClientIP   string    `gorm:"column:client_ip;type:varchar(64);not null;default:'';index:idx_login_history_ip;comment:客户端IP" json:"client_ip"`
+	UserAgent  string    `gorm:"column:user_agent;type:varchar(255);not null;default:'';comment:UA" json:"user_agent"`
+	Success    bool      `gorm:"column:success;type:bool;not null;default:false;comment:是否成功" json:"success"`
+	FailReason string    `gorm:"column:fail_reason;type:varchar(64);not null;default:'';comment:失败原因" json:"fail_reason"`
+	UsedTOTP   bool      `gorm:"column:used_totp;type:bool;not null;default:false;comment:是否经过二次验证" json:"used_totp"`
+}
+
+func (*BasicLoginHistory) TableName() string {
+	return TableNameBasicLoginHistory
+}
@@ -12,6 +12,7 @@ const (
 	UserRoleSuperAdmin  = "super_admin"  // Can manage multiple game accounts
 	UserRoleStoreAdmin  = "store_admin"  // Exclusive to one store under one game account
 	UserRoleRegularUser = "user"         // Regular user with game account binding
+	UserRoleService     = "service"      // Service account for machine integrations (API key only, no password login)
 )
 
 type BasicUser struct {
@@ -55,6 +56,11 @@ func (u *BasicUser) IsStoreAdmin() bool {
 	return u.Role == UserRoleStoreAdmin
 }
 
+// IsService checks if user is a service account
+func (u *BasicUser) IsService() bool {
+	return u.Role == UserRoleService
+}
+
 // IsRegularUser checks if user is a regular user
 func (u *BasicUser) IsRegularUser() bool {
 	return u.Role == UserRoleRegularUser
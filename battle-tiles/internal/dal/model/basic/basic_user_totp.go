@@ -0,0 +1,20 @@
+package basic
+
+import "time"
+
+const TableNameBasicUserTOTP = "basic_user_totp"
+
+// BasicUserTOTP 用户的 TOTP 二次验证配置。Enabled=false 表示已生成密钥、尚未验证启用。
+type BasicUserTOTP struct {
+	UserID    int32      `gorm:"column:user_id;primaryKey" json:"user_id"`
+	Secret    string     `gorm:"column:secret;type:varchar(64);not null;comment:base32 密钥" json:"-"`
+	Enabled   bool       `gorm:"column:enabled;type:bool;not null;default:false;comment:是否已启用" json:"enabled"`
+	LastStep  int64      `gorm:"column:last_step;type:int8;not null;default:0;comment:最近一次使用的时间步（防重放）" json:"-"`
+	EnabledAt *time.Time `gorm:"column:enabled_at;type:timestamp with time zone;comment:启用时间" json:"enabled_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (*BasicUserTOTP) TableName() string {
+	return TableNameBasicUserTOTP
+}
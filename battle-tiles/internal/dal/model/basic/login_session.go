@@ -0,0 +1,17 @@
+package basic
+
+// LoginSession 登录会话（存 Redis，不落库）。
+// 每次登录生成一个会话，access token 通过 sid 关联；刷新令牌只保存哈希，每次刷新轮换。
+type LoginSession struct {
+	SID          string `json:"sid"`
+	UserID       int32  `json:"user_id"`
+	Platform     string `json:"platform"`
+	Device       string `json:"device"` // 客户端自报的设备名，缺省取 UA
+	ClientIP     string `json:"client_ip"`
+	UserAgent    string `json:"user_agent"`
+	RefreshHash  string `json:"-"`
+	PrevHash     string `json:"-"` // 上一个刷新令牌的哈希，再次出现视为令牌被盗用
+	CreatedAt    int64  `json:"created_at"`
+	LastActiveAt int64  `json:"last_active_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
@@ -0,0 +1,28 @@
+package basic
+
+import "time"
+
+const TableNameBasicUserScopeRole = "basic_user_scope_role"
+
+// 店铺作用域角色编码（对应 basic_role.code）
+const (
+	RoleCodeShopAdmin    = "shop_admin"    // 店铺管理员
+	RoleCodeShopOperator = "shop_operator" // 店铺运营
+)
+
+// BasicUserScopeRole 作用域角色授权：用户在某个店铺（可选：某个圈）内拥有某角色。
+// GroupID = 0 表示对整个店铺生效。
+type BasicUserScopeRole struct {
+	Id        int32     `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+
+	UserID    int32 `gorm:"column:user_id;type:int4;not null;uniqueIndex:uk_user_scope_role,priority:1;comment:用户ID" json:"user_id"`
+	RoleID    int32 `gorm:"column:role_id;type:int4;not null;uniqueIndex:uk_user_scope_role,priority:2;comment:角色ID" json:"role_id"`
+	HouseGID  int32 `gorm:"column:house_gid;type:int4;not null;uniqueIndex:uk_user_scope_role,priority:3;index:idx_user_scope_role_house;comment:店铺号" json:"house_gid"`
+	GroupID   int32 `gorm:"column:group_id;type:int4;not null;default:0;uniqueIndex:uk_user_scope_role,priority:4;comment:圈子ID（0=整个店铺）" json:"group_id"`
+	GrantedBy int32 `gorm:"column:granted_by;type:int4;not null;default:0;comment:授权人" json:"granted_by"`
+}
+
+func (*BasicUserScopeRole) TableName() string {
+	return TableNameBasicUserScopeRole
+}
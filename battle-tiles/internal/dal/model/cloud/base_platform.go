@@ -6,10 +6,13 @@ const TableNameBasePlatform = "base_platform"
 
 // BasePlatform mapped from table <base_platform>
 type BasePlatform struct {
-	Platform  string     `gorm:"column:platform;type:character varying(255);not null;comment:平台" json:"platform"`
-	Name      string     `gorm:"column:name;type:character varying(255);not null;comment:名称" json:"name"`                                                                                                                  // 名称
-	DBName    string     `gorm:"column:db_name;type:character varying(255);not null;comment:数据库名称" json:"db_name"`                                                                                                         // 机构数据库名称
-	CreatedAt *time.Time `gorm:"column:created_at;type:timestamp(6) with time zone;not null;default:now();comment:创建时间" json:"created_at" time_format:"2006-01-02 15:04:05" time_utc:"false" format:"2006-01-02 15:04:05"` // 创建时间
+	Platform      string     `gorm:"column:platform;type:character varying(255);not null;comment:平台" json:"platform"`
+	Name          string     `gorm:"column:name;type:character varying(255);not null;comment:名称" json:"name"`                                                                                                                  // 名称
+	DBName        string     `gorm:"column:db_name;type:character varying(255);not null;comment:数据库名称" json:"db_name"`                                                                                                         // 机构数据库名称
+	CreatedAt     *time.Time `gorm:"column:created_at;type:timestamp(6) with time zone;not null;default:now();comment:创建时间" json:"created_at" time_format:"2006-01-02 15:04:05" time_utc:"false" format:"2006-01-02 15:04:05"` // 创建时间
+	GroBattleURL  *string    `gorm:"column:gro_battle_url;type:character varying(255);comment:战绩同步接口地址" json:"gro_battle_url"`                                                                                                 // 为空使用 global.game.gro 配置，下同
+	GroQueryURL   *string    `gorm:"column:gro_query_url;type:character varying(255);comment:战绩查询接口地址" json:"gro_query_url"`
+	GroPrivateKey *string    `gorm:"column:gro_private_key;type:character varying(64);comment:token 签名私钥" json:"-"`
 }
 
 // TableName BaseBed's table name
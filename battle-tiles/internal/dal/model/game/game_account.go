@@ -24,7 +24,8 @@ type GameAccount struct {
 	IsDel         soft_delete.DeletedAt `gorm:"softDelete:flag,DeletedAtField:DeletedAt" json:"is_del"`
 	UserID        int32                 `gorm:"column:user_id;not null" json:"user_id"`
 	Account       string                `gorm:"column:account;type:varchar(64);not null" json:"account"`
-	PwdMD5        string                `gorm:"column:pwd_md5;type:varchar(64);not null" json:"pwd_md5"`
+	PwdMD5        string                `gorm:"column:pwd_md5;type:varchar(255);not null" json:"-"` // 加密后的密码 MD5，见 plaza.Credential
+	PwdKeyVer     int32                 `gorm:"column:pwd_key_ver;not null;default:0" json:"-"`     // 加密主密钥版本，0 为历史明文
 	Nickname      string                `gorm:"column:nickname;type:varchar(64);not null;default:''" json:"nickname"`
 	IsDefault     bool                  `gorm:"column:is_default;not null;default:false" json:"is_default"`
 	Status        int32                 `gorm:"column:status;not null;default:1" json:"status"`
@@ -0,0 +1,88 @@
+package game
+
+import "time"
+
+const (
+	TableNameGameApplicationRule    = "game_application_rule"
+	TableNameGameShopApplicationLog = "game_shop_application_log"
+)
+
+// 申请来源
+const (
+	ApplicationSourceGame     = "game"     // 游戏内申请（SUB_GA_APPLY_MESSAGE，会话内存）
+	ApplicationSourcePlatform = "platform" // 平台侧申请（game_user_application）
+)
+
+// 处理结果
+const (
+	ApplicationActionApproved int32 = 1
+	ApplicationActionRejected int32 = 2
+	ApplicationActionExpired  int32 = 3 // 超时未处理，游戏内申请会同时下发拒绝
+)
+
+// 决策方式
+const (
+	ApplicationDeciderManual    = "manual"    // 管理员手动（含批量）
+	ApplicationDeciderRule      = "rule"      // 自动规则
+	ApplicationDeciderExpiry    = "expiry"    // 超时
+	ApplicationDeciderBlocklist = "blocklist" // 命中平台黑名单
+)
+
+// 自动规则条件
+const (
+	ApplicationRuleRecommenderMember = "recommender_member" // 推荐人已是本店成员
+	ApplicationRuleBlocklist         = "blocklist"          // 申请人游戏ID在规则名单内
+	ApplicationRuleAny               = "any"                // 无条件（配合 apply_types/sources 过滤使用）
+)
+
+// 自动规则动作
+const (
+	ApplicationRuleApprove = "approve"
+	ApplicationRuleReject  = "reject"
+)
+
+// ApplicationRuleParams 规则参数；ApplyTypes/Sources 为空表示不限
+type ApplicationRuleParams struct {
+	GameIDs    []int32  `json:"game_ids,omitempty"`    // blocklist：游戏ID名单
+	ApplyTypes []int32  `json:"apply_types,omitempty"` // 限定申请类型（1=管理员 2=入圈）
+	Sources    []string `json:"sources,omitempty"`     // 限定来源 game/platform
+}
+
+// GameApplicationRule 店铺申请自动处理规则，按 priority 升序匹配，命中第一条即执行
+type GameApplicationRule struct {
+	Id        int32                 `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID  int32                 `gorm:"column:house_gid;not null;index:idx_app_rule_house" json:"house_gid"`
+	Name      string                `gorm:"column:name;type:varchar(64);not null" json:"name"`
+	Priority  int32                 `gorm:"column:priority;not null;default:100" json:"priority"`
+	Condition string                `gorm:"column:condition;type:varchar(32);not null" json:"condition"`
+	Action    string                `gorm:"column:action;type:varchar(16);not null" json:"action"`
+	Params    ApplicationRuleParams `gorm:"column:params;type:jsonb;serializer:json;not null" json:"params"`
+	Enabled   bool                  `gorm:"column:enabled;not null;default:true" json:"enabled"`
+	CreatedBy int32                 `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt time.Time             `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt time.Time             `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameApplicationRule) TableName() string { return TableNameGameApplicationRule }
+
+// GameShopApplicationLog 申请处理记录：手动、规则、超时三种决策都会落一条
+type GameShopApplicationLog struct {
+	Id            int32     `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID      int32     `gorm:"column:house_gid;not null;index:idx_app_log_house" json:"house_gid"`
+	Source        string    `gorm:"column:source;type:varchar(16);not null;default:'game'" json:"source"`
+	ApplicationID int32     `gorm:"column:application_id;not null;default:0" json:"application_id"` // 游戏消息ID / 平台申请ID
+	ApplyType     int32     `gorm:"column:apply_type;not null;default:0" json:"apply_type"`
+	ApplierGID    int32     `gorm:"column:applier_gid;not null;default:0" json:"applier_gid"`
+	ApplierGName  string    `gorm:"column:applier_gname;type:varchar(64);not null;default:''" json:"applier_gname"`
+	ApplierUserID int32     `gorm:"column:applier_user_id;not null;default:0" json:"applier_user_id"`
+	Recommender   string    `gorm:"column:recommender;type:varchar(64);not null;default:''" json:"recommender"`
+	Action        int32     `gorm:"column:action;not null" json:"action"`
+	Decider       string    `gorm:"column:decider;type:varchar(16);not null;default:'manual'" json:"decider"`
+	RuleID        *int32    `gorm:"column:rule_id" json:"rule_id"`
+	RuleName      string    `gorm:"column:rule_name;type:varchar(64);not null;default:''" json:"rule_name"`
+	AdminUserID   int32     `gorm:"column:admin_user_id;not null;default:0" json:"admin_user_id"`
+	Reason        string    `gorm:"column:reason;type:varchar(255);not null;default:''" json:"reason"`
+	CreatedAt     time.Time `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+}
+
+func (GameShopApplicationLog) TableName() string { return TableNameGameShopApplicationLog }
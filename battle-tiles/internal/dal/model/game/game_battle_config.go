@@ -0,0 +1,24 @@
+package game
+
+import "time"
+
+const TableNameGameBattleConfig = "game_battle_config"
+
+// GameBattleConfig 店铺玩法快照（来自会话推送 SUB_GA_BATTLE_CONFIG 及增删改推送）
+// 游戏端删除的玩法只标记 removed，保留给历史战绩关联
+type GameBattleConfig struct {
+	Id          int32      `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID    int32      `gorm:"column:house_gid;not null;uniqueIndex:uk_battle_config_house" json:"house_gid"`
+	ConfigID    int32      `gorm:"column:config_id;not null;uniqueIndex:uk_battle_config_house" json:"config_id"` // 游戏端玩法标识
+	KindID      int32      `gorm:"column:kind_id;not null" json:"kind_id"`
+	BaseScore   int32      `gorm:"column:base_score;not null;default:0" json:"base_score"`
+	PlayCount   int32      `gorm:"column:play_count;not null;default:0" json:"play_count"`     // 局数
+	PlayerCount int32      `gorm:"column:player_count;not null;default:0" json:"player_count"` // 人数
+	Name        string     `gorm:"column:name;type:varchar(64);not null;default:''" json:"name"`
+	Removed     bool       `gorm:"column:removed;not null;default:false" json:"removed"`
+	RemovedAt   *time.Time `gorm:"column:removed_at;type:timestamp with time zone" json:"removed_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameBattleConfig) TableName() string { return TableNameGameBattleConfig }
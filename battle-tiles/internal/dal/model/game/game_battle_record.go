@@ -13,6 +13,7 @@ type GameBattleRecord struct {
 	RoomUID         int32     `gorm:"column:room_uid;not null;index:idx_battle_room_uid" json:"room_uid"` // MappedNum
 	KindID          int32     `gorm:"column:kind_id;not null;index:idx_battle_kind_id" json:"kind_id"`
 	BaseScore       int32     `gorm:"column:base_score;not null" json:"base_score"`
+	ConfigID        *int32    `gorm:"column:config_id;index:idx_battle_config_id" json:"config_id"` // 对局所用玩法（game_battle_config.config_id），无法唯一确定时为空
 	BattleAt        time.Time `gorm:"column:battle_at;type:timestamp with time zone;not null;index:idx_battle_at" json:"battle_at"`
 	PlayersJSON     string    `gorm:"column:players_json;type:text;not null" json:"players_json"`
 	PlayerID        *int32    `gorm:"column:player_id;index:idx_battle_player_id" json:"player_id"`
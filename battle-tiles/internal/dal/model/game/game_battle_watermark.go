@@ -0,0 +1,16 @@
+package game
+
+import "time"
+
+const TableNameGameBattleWatermark = "game_battle_watermark"
+
+// GameBattleWatermark 店铺战绩同步高水位：已入库的最新一局与连续同步覆盖到的时间，只前进不后退
+type GameBattleWatermark struct {
+	HouseGID       int32      `gorm:"primaryKey;column:house_gid;autoIncrement:false" json:"house_gid"`
+	LastCreateTime int64      `gorm:"column:last_create_time;not null;default:0" json:"last_create_time"`      // 最新一局开局时间（秒）
+	LastRoomID     int32      `gorm:"column:last_room_id;not null;default:0" json:"last_room_id"`              // 同一秒内按房间号排序
+	CoveredUntil   *time.Time `gorm:"column:covered_until;type:timestamp with time zone" json:"covered_until"` // 此前的战绩已完整拉取（增量同步推进）
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameBattleWatermark) TableName() string { return TableNameGameBattleWatermark }
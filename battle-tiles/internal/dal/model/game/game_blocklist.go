@@ -0,0 +1,33 @@
+package game
+
+import "time"
+
+const TableNameGameBlocklist = "game_blocklist"
+
+// 黑名单标识类型
+const (
+	BlocklistKindGameID = "game_id" // 游戏ID
+	BlocklistKindUser   = "user"    // 平台用户ID
+	BlocklistKindDevice = "device"  // 设备标识
+	BlocklistKindIP     = "ip"      // IP
+)
+
+// GameBlocklist 平台级黑名单（跨店铺生效），同一 kind+value 只保留一条
+type GameBlocklist struct {
+	Id               int32      `gorm:"primaryKey;column:id" json:"id"`
+	Kind             string     `gorm:"column:kind;type:varchar(16);not null;uniqueIndex:uk_blocklist_kind_value" json:"kind"`
+	Value            string     `gorm:"column:value;type:varchar(128);not null;uniqueIndex:uk_blocklist_kind_value" json:"value"`
+	Reason           string     `gorm:"column:reason;type:varchar(255);not null;default:''" json:"reason"`
+	ReportedHouseGID int32      `gorm:"column:reported_house_gid;not null;default:0" json:"reported_house_gid"` // 上报店铺，0 为平台直接添加
+	ExpireAt         *time.Time `gorm:"column:expire_at;type:timestamp with time zone" json:"expire_at"`        // 空表示永久
+	CreatedBy        int32      `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameBlocklist) TableName() string { return TableNameGameBlocklist }
+
+// Active 未过期
+func (b *GameBlocklist) Active(now time.Time) bool {
+	return b.ExpireAt == nil || b.ExpireAt.After(now)
+}
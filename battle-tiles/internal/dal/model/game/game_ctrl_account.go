@@ -13,7 +13,8 @@ type GameCtrlAccount struct {
 	DeletedAt    time.Time  `gorm:"column:deleted_at;type:timestamp with time zone" json:"deleted_at"`
 	LoginMode    int32      `gorm:"column:login_mode;not null" json:"login_mode"` // smallint
 	Identifier   string     `gorm:"column:identifier;type:varchar(64);not null" json:"identifier"`
-	PwdMD5       string     `gorm:"column:pwd_md5;type:varchar(64);not null" json:"pwd_md5"`
+	PwdMD5       string     `gorm:"column:pwd_md5;type:varchar(255);not null" json:"-"` // 加密后的密码 MD5，见 plaza.Credential
+	PwdKeyVer    int32      `gorm:"column:pwd_key_ver;not null;default:0" json:"-"`     // 加密主密钥版本，0 为历史明文
 	GameUserID   string     `gorm:"column:game_user_id;type:varchar(32);not null;default:''" json:"game_user_id"`
 	GameID       string     `gorm:"column:game_id;type:varchar(32);not null;default:''" json:"game_id"`
 	Status       int32      `gorm:"column:status;not null;default:1" json:"status"`
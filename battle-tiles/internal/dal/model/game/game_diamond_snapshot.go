@@ -0,0 +1,23 @@
+package game
+
+import "time"
+
+const TableNameGameDiamondSnapshot = "game_diamond_snapshot"
+
+// 余额来源
+const (
+	DiamondSourceQuery = "query" // 主动查询（SUB_GP_USER_WEALTH）
+	DiamondSourcePush  = "push"  // 游戏端财富更新推送（SUB_GA_WEALTH_UPDATE）
+)
+
+// GameDiamondSnapshot 中控账号钻石余额时间序列（店铺开桌消耗的钻石）
+type GameDiamondSnapshot struct {
+	Id            int32     `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID      int32     `gorm:"column:house_gid;not null;index:idx_diamond_house_at" json:"house_gid"`
+	CtrlAccountID int32     `gorm:"column:ctrl_account_id;not null" json:"ctrl_account_id"`
+	Balance       int64     `gorm:"column:balance;not null" json:"balance"`
+	Source        string    `gorm:"column:source;type:varchar(16);not null" json:"source"`
+	CreatedAt     time.Time `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null;index:idx_diamond_house_at" json:"created_at"`
+}
+
+func (GameDiamondSnapshot) TableName() string { return TableNameGameDiamondSnapshot }
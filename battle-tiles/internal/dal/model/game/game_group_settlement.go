@@ -0,0 +1,43 @@
+package game
+
+import "time"
+
+const TableNameGameGroupSettlement = "game_group_settlement"
+
+// 结算单状态
+const (
+	SettlementStatusDraft  int32 = 0 // 草稿（可重新生成）
+	SettlementStatusFrozen int32 = 1 // 已冻结（数据锁定，待打款）
+	SettlementStatusPaid   int32 = 2 // 已打款
+)
+
+// GameGroupSettlement 圈主结算单（一个圈在一个结算周期内一张）
+// 金额单位均为分；FeeShareNet 正数=应付其他圈，负数=应收其他圈
+type GameGroupSettlement struct {
+	Id               int32      `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID         int32      `gorm:"column:house_gid;not null;uniqueIndex:uk_group_settlement_period,priority:1" json:"house_gid"`
+	GroupID          int32      `gorm:"column:group_id;not null;uniqueIndex:uk_group_settlement_period,priority:2" json:"group_id"`
+	GroupName        string     `gorm:"column:group_name;type:varchar(64);not null;default:''" json:"group_name"`
+	AdminUserID      int32      `gorm:"column:admin_user_id;not null;index:idx_group_settlement_admin" json:"admin_user_id"`
+	PeriodStart      time.Time  `gorm:"column:period_start;type:timestamp with time zone;not null;uniqueIndex:uk_group_settlement_period,priority:3" json:"period_start"`
+	PeriodEnd        time.Time  `gorm:"column:period_end;type:timestamp with time zone;not null;uniqueIndex:uk_group_settlement_period,priority:4" json:"period_end"`
+	Games            int64      `gorm:"column:games;not null;default:0" json:"games"`                         // 局数（按房间+时间去重）
+	PlayerGames      int64      `gorm:"column:player_games;not null;default:0" json:"player_games"`           // 人次
+	ScoreNet         int64      `gorm:"column:score_net;not null;default:0" json:"score_net"`                 // 圈内成员输赢合计
+	ScoreFlow        int64      `gorm:"column:score_flow;not null;default:0" json:"score_flow"`               // 输赢流水（绝对值合计）
+	FeeTotal         int64      `gorm:"column:fee_total;not null;default:0" json:"fee_total"`                 // 圈内产生的运费
+	FeeShareNet      int64      `gorm:"column:fee_share_net;not null;default:0" json:"fee_share_net"`         // 分运结转净额
+	CommissionRate   int32      `gorm:"column:commission_rate;not null;default:0" json:"commission_rate"`     // 万分比
+	CommissionAmount int64      `gorm:"column:commission_amount;not null;default:0" json:"commission_amount"` // 圈主抽成
+	Status           int32      `gorm:"column:status;not null;default:0;index:idx_group_settlement_status" json:"status"`
+	FrozenAt         *time.Time `gorm:"column:frozen_at;type:timestamp with time zone" json:"frozen_at"`
+	FrozenBy         int32      `gorm:"column:frozen_by;not null;default:0" json:"frozen_by"`
+	PaidAt           *time.Time `gorm:"column:paid_at;type:timestamp with time zone" json:"paid_at"`
+	PaidBy           int32      `gorm:"column:paid_by;not null;default:0" json:"paid_by"`
+	Remark           string     `gorm:"column:remark;type:varchar(255);not null;default:''" json:"remark"`
+	CreatedBy        int32      `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameGroupSettlement) TableName() string { return TableNameGameGroupSettlement }
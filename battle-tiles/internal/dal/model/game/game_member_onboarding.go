@@ -0,0 +1,49 @@
+package game
+
+import "time"
+
+const TableNameGameMemberOnboarding = "game_member_onboarding"
+
+// 入店流程步骤（按顺序执行，Step 记录最后完成的一步）
+const (
+	OnboardingStepPending = "pending"        // 已通过，尚未开始
+	OnboardingStepMember  = "member_created" // game_member 已就绪
+	OnboardingStepGroup   = "group_assigned" // 圈子关系已就绪
+	OnboardingStepWallet  = "wallet_ready"   // 钱包（默认额度 + 入店赠送）已就绪
+	OnboardingStepRules   = "rules_ready"    // 成员规则默认值已就绪
+)
+
+// 入店流程状态
+const (
+	OnboardingStatusRunning     = "running"
+	OnboardingStatusFailed      = "failed" // 某步失败，可重试，从 Step 之后继续
+	OnboardingStatusCompleted   = "completed"
+	OnboardingStatusCompensated = "compensated" // 已撤销：回退赠送、移出圈子、删除本流程创建的成员
+)
+
+// GameMemberOnboarding 成员入店流程：审批通过 → 成员 → 圈子 → 钱包 → 规则，每步幂等，失败可从断点重试
+type GameMemberOnboarding struct {
+	Id              int32      `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID        int32      `gorm:"column:house_gid;not null;index:idx_onboarding_house" json:"house_gid"`
+	Source          string     `gorm:"column:source;type:varchar(16);not null;default:'manual'" json:"source"` // game/platform/manual
+	ApplicationID   int32      `gorm:"column:application_id;not null;default:0" json:"application_id"`
+	ApplicantUserID int32      `gorm:"column:applicant_user_id;not null;default:0" json:"applicant_user_id"` // 平台用户（游戏内申请为 0）
+	GameID          int32      `gorm:"column:game_id;not null;default:0" json:"game_id"`
+	GameName        string     `gorm:"column:game_name;type:varchar(64);not null;default:''" json:"game_name"`
+	Recommender     string     `gorm:"column:recommender;type:varchar(64);not null;default:''" json:"recommender"`
+	GroupID         *int32     `gorm:"column:group_id" json:"group_id"` // game_shop_group.id，空表示不分圈
+	MemberID        int32      `gorm:"column:member_id;not null;default:0" json:"member_id"`
+	MemberCreated   bool       `gorm:"column:member_created;not null;default:false" json:"member_created"` // 成员由本流程创建（撤销时删除）
+	GroupLinked     bool       `gorm:"column:group_linked;not null;default:false" json:"group_linked"`     // 圈子关系由本流程写入（撤销时移除）
+	WelcomeCredit   int32      `gorm:"column:welcome_credit;not null;default:0" json:"welcome_credit"`     // 已发放的入店赠送（分）
+	Step            string     `gorm:"column:step;type:varchar(32);not null;default:'pending'" json:"step"`
+	Status          string     `gorm:"column:status;type:varchar(16);not null;default:'running';index:idx_onboarding_status" json:"status"`
+	Attempts        int32      `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	LastError       string     `gorm:"column:last_error;type:text;not null;default:''" json:"last_error"`
+	CreatedBy       int32      `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+	CompletedAt     *time.Time `gorm:"column:completed_at;type:timestamp with time zone" json:"completed_at"`
+}
+
+func (GameMemberOnboarding) TableName() string { return TableNameGameMemberOnboarding }
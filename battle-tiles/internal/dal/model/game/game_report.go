@@ -0,0 +1,78 @@
+package game
+
+import "time"
+
+const (
+	TableNameGameReportDefinition = "game_report_definition"
+	TableNameGameReportRun        = "game_report_run"
+)
+
+// 报表类型
+const (
+	ReportTypeDailyStats    = "daily_stats"    // 经营概况（局数/输赢/运费/钱包/在线会话）
+	ReportTypeLowBalance    = "low_balance"    // 低余额成员
+	ReportTypeTopLosers     = "top_losers"     // 输分榜
+	ReportTypeFeeSummary    = "fee_summary"    // 运费汇总（按圈）
+	ReportTypeSessionOutage = "session_outage" // 中控会话异常/同步失败
+)
+
+// 统计窗口
+const (
+	ReportPeriodDaily  = "daily"  // 昨日
+	ReportPeriodWeekly = "weekly" // 上周
+)
+
+// 投递渠道
+const (
+	ReportChannelFile    = "file"
+	ReportChannelWebhook = "webhook"
+)
+
+// 执行状态
+const (
+	ReportRunStatusRunning = "running"
+	ReportRunStatusSuccess = "success"
+	ReportRunStatusFailed  = "failed"
+)
+
+// GameReportDefinition 店铺定时报表定义
+// Schedule 为标准 5 段 cron 表达式（如 "0 8 * * *"）或 "@daily" 等描述符，按 Asia/Shanghai 解析
+type GameReportDefinition struct {
+	Id            int32      `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID      int32      `gorm:"column:house_gid;not null;index:idx_report_def_house" json:"house_gid"`
+	Name          string     `gorm:"column:name;type:varchar(64);not null" json:"name"`
+	ReportType    string     `gorm:"column:report_type;type:varchar(32);not null" json:"report_type"`
+	Period        string     `gorm:"column:period;type:varchar(16);not null;default:'daily'" json:"period"`
+	Schedule      string     `gorm:"column:schedule;type:varchar(64);not null" json:"schedule"`
+	Params        string     `gorm:"column:params;type:jsonb;not null;default:'{}'" json:"params"`
+	Channel       string     `gorm:"column:channel;type:varchar(16);not null" json:"channel"`
+	ChannelTarget string     `gorm:"column:channel_target;type:varchar(512);not null;default:''" json:"channel_target"`
+	Enabled       bool       `gorm:"column:enabled;not null;default:true;index:idx_report_def_enabled" json:"enabled"`
+	LastRunAt     *time.Time `gorm:"column:last_run_at;type:timestamp with time zone" json:"last_run_at"`
+	NextRunAt     *time.Time `gorm:"column:next_run_at;type:timestamp with time zone" json:"next_run_at"`
+	CreatedBy     int32      `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameReportDefinition) TableName() string { return TableNameGameReportDefinition }
+
+// GameReportRun 报表执行记录
+type GameReportRun struct {
+	Id           int32      `gorm:"primaryKey;column:id" json:"id"`
+	DefinitionID int32      `gorm:"column:definition_id;not null;index:idx_report_run_def" json:"definition_id"`
+	HouseGID     int32      `gorm:"column:house_gid;not null;index:idx_report_run_house" json:"house_gid"`
+	ReportType   string     `gorm:"column:report_type;type:varchar(32);not null" json:"report_type"`
+	Trigger      string     `gorm:"column:trigger;type:varchar(16);not null;default:'schedule'" json:"trigger"` // schedule|manual
+	Status       string     `gorm:"column:status;type:varchar(16);not null" json:"status"`
+	RangeStart   time.Time  `gorm:"column:range_start;type:timestamp with time zone;not null" json:"range_start"`
+	RangeEnd     time.Time  `gorm:"column:range_end;type:timestamp with time zone;not null" json:"range_end"`
+	Rows         int32      `gorm:"column:rows;not null;default:0" json:"rows"`
+	Channel      string     `gorm:"column:channel;type:varchar(16);not null" json:"channel"`
+	DeliveryRef  string     `gorm:"column:delivery_ref;type:varchar(512);not null;default:''" json:"delivery_ref"` // 文件路径 / webhook 响应状态
+	ErrorMessage string     `gorm:"column:error_message;type:text;not null;default:''" json:"error_message"`
+	StartedAt    time.Time  `gorm:"column:started_at;type:timestamp with time zone;not null" json:"started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at;type:timestamp with time zone" json:"finished_at"`
+}
+
+func (GameReportRun) TableName() string { return TableNameGameReportRun }
@@ -7,6 +7,7 @@ const TableNameGameSyncLog = "game_sync_log"
 // Sync type constants
 const (
 	SyncTypeBattleRecord = "battle_record"  // Battle record synchronization
+	SyncTypeBattleBackfill = "battle_backfill" // Battle record backfill for a date range
 	SyncTypeMemberList   = "member_list"    // Member list synchronization
 	SyncTypeWalletUpdate = "wallet_update"  // Wallet update synchronization
 	SyncTypeRoomList     = "room_list"      // Room list synchronization
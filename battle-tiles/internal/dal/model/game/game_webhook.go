@@ -0,0 +1,63 @@
+package game
+
+import "time"
+
+const (
+	TableNameGameWebhookEndpoint = "game_webhook_endpoint"
+	TableNameGameWebhookDelivery = "game_webhook_delivery"
+)
+
+// 投递状态
+const (
+	WebhookDeliveryPending = "pending" // 待投递/重试中
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed" // 重试耗尽或端点已停用
+)
+
+// GameWebhookEndpoint 店铺注册的 webhook 回调地址
+// Secret 为用 global.crypto 主密钥加密后的签名密钥，SecretKeyVer 为加密所用的密钥版本；明文只在创建/重置时返回一次
+type GameWebhookEndpoint struct {
+	Id                  int32     `gorm:"primaryKey;column:id" json:"id"`
+	HouseGID            int32     `gorm:"column:house_gid;not null;index:idx_webhook_ep_house" json:"house_gid"`
+	Name                string    `gorm:"column:name;type:varchar(64);not null;default:''" json:"name"`
+	URL                 string    `gorm:"column:url;type:varchar(512);not null" json:"url"`
+	Secret              string    `gorm:"column:secret;type:varchar(255);not null" json:"-"`
+	SecretKeyVer        int32     `gorm:"column:secret_key_ver;not null;default:0" json:"-"`
+	Events              []string  `gorm:"column:events;type:jsonb;serializer:json;not null" json:"events"`
+	LowBalanceThreshold int32     `gorm:"column:low_balance_threshold;not null;default:0" json:"low_balance_threshold"` // member.low_balance：下分后余额从高于该值跌到不高于该值时触发
+	Enabled             bool      `gorm:"column:enabled;not null;default:true" json:"enabled"`
+	CreatedBy           int32     `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt           time.Time `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime;column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (GameWebhookEndpoint) TableName() string { return TableNameGameWebhookEndpoint }
+
+// Subscribed 是否订阅了该事件类型
+func (e *GameWebhookEndpoint) Subscribed(eventType string) bool {
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// GameWebhookDelivery webhook 投递记录；Payload 为实际 POST 的请求体，重投时原样发送
+type GameWebhookDelivery struct {
+	Id             int32      `gorm:"primaryKey;column:id" json:"id"`
+	EndpointID     int32      `gorm:"column:endpoint_id;not null;index:idx_webhook_dlv_endpoint" json:"endpoint_id"`
+	HouseGID       int32      `gorm:"column:house_gid;not null;index:idx_webhook_dlv_house" json:"house_gid"`
+	EventID        string     `gorm:"column:event_id;type:varchar(64);not null" json:"event_id"`
+	EventType      string     `gorm:"column:event_type;type:varchar(32);not null" json:"event_type"`
+	Payload        string     `gorm:"column:payload;type:jsonb;not null" json:"payload"`
+	Status         string     `gorm:"column:status;type:varchar(16);not null;default:'pending'" json:"status"`
+	Attempts       int32      `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	LastStatusCode int32      `gorm:"column:last_status_code;not null;default:0" json:"last_status_code"`
+	LastError      string     `gorm:"column:last_error;type:text;not null;default:''" json:"last_error"`
+	LastAttemptAt  *time.Time `gorm:"column:last_attempt_at;type:timestamp with time zone" json:"last_attempt_at"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at;type:timestamp with time zone" json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+}
+
+func (GameWebhookDelivery) TableName() string { return TableNameGameWebhookDelivery }
@@ -0,0 +1,118 @@
+package basic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"battle-tiles/internal/infra"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepo 服务账号与 API Key
+type APIKeyRepo interface {
+	// CreateServiceAccount 新建服务账号（basic_user，role=service，无密码）
+	CreateServiceAccount(ctx context.Context, u *basicModel.BasicUser) error
+	// ListServiceAccounts 全部服务账号
+	ListServiceAccounts(ctx context.Context) ([]*basicModel.BasicUser, error)
+	// GetUser 读取用户（无则 nil, nil）
+	GetUser(ctx context.Context, userID int32) (*basicModel.BasicUser, error)
+
+	// Create 写入新 Key
+	Create(ctx context.Context, k *basicModel.BasicAPIKey) error
+	// Get 按 ID 读取（无则 nil, nil）
+	Get(ctx context.Context, id int32) (*basicModel.BasicAPIKey, error)
+	// GetByPrefix 按公开前缀读取（无则 nil, nil）
+	GetByPrefix(ctx context.Context, prefix string) (*basicModel.BasicAPIKey, error)
+	// List 列出 Key；userID > 0 时只列该服务账号的
+	List(ctx context.Context, userID int32) ([]*basicModel.BasicAPIKey, error)
+	// Revoke 吊销（已吊销的不重复处理）
+	Revoke(ctx context.Context, id, operator int32, at time.Time) (bool, error)
+	// TouchLastUsed 记录最近使用时间与来源 IP
+	TouchLastUsed(ctx context.Context, id int32, at time.Time, ip string) error
+}
+
+type apiKeyRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewAPIKeyRepo(data *infra.Data, logger log.Logger) APIKeyRepo {
+	return &apiKeyRepo{
+		data: data,
+		log:  log.NewHelper(log.With(logger, "module", "repo/api_key")),
+	}
+}
+
+func (r *apiKeyRepo) CreateServiceAccount(ctx context.Context, u *basicModel.BasicUser) error {
+	return r.data.GetDBWithContext(ctx).Create(u).Error
+}
+
+func (r *apiKeyRepo) ListServiceAccounts(ctx context.Context) ([]*basicModel.BasicUser, error) {
+	var out []*basicModel.BasicUser
+	err := r.data.GetDBWithContext(ctx).
+		Where("role = ?", basicModel.UserRoleService).
+		Order("id").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *apiKeyRepo) GetUser(ctx context.Context, userID int32) (*basicModel.BasicUser, error) {
+	var u basicModel.BasicUser
+	if err := r.data.GetDBWithContext(ctx).Where("id = ?", userID).First(&u).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, k *basicModel.BasicAPIKey) error {
+	return r.data.GetDBWithContext(ctx).Create(k).Error
+}
+
+func (r *apiKeyRepo) Get(ctx context.Context, id int32) (*basicModel.BasicAPIKey, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+func (r *apiKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*basicModel.BasicAPIKey, error) {
+	return r.first(ctx, "prefix = ?", prefix)
+}
+
+func (r *apiKeyRepo) first(ctx context.Context, query string, args ...any) (*basicModel.BasicAPIKey, error) {
+	var k basicModel.BasicAPIKey
+	if err := r.data.GetDBWithContext(ctx).Where(query, args...).First(&k).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepo) List(ctx context.Context, userID int32) ([]*basicModel.BasicAPIKey, error) {
+	db := r.data.GetDBWithContext(ctx).Model(&basicModel.BasicAPIKey{})
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	var out []*basicModel.BasicAPIKey
+	err := db.Order("id DESC").Find(&out).Error
+	return out, err
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id, operator int32, at time.Time) (bool, error) {
+	res := r.data.GetDBWithContext(ctx).Model(&basicModel.BasicAPIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "revoked_by": operator})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id int32, at time.Time, ip string) error {
+	return r.data.GetDBWithContext(ctx).Model(&basicModel.BasicAPIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,104 @@
+package basic
+
+import (
+	"context"
+	"time"
+
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"battle-tiles/internal/infra"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	ActorID    *int32
+	HouseGID   *int32
+	Action     string // 前缀匹配，如 "fund:" 查全部资金操作
+	TargetType string
+	TargetID   string
+	ClientIP   string
+	Success    *bool
+	Start      *time.Time
+	End        *time.Time
+}
+
+type AuditLogRepo interface {
+	// Create 写入审计日志
+	Create(ctx context.Context, m *basicModel.BasicAuditLog) error
+	// List 分页查询审计日志（按时间倒序）
+	List(ctx context.Context, f AuditLogFilter, page, size int32) ([]*basicModel.BasicAuditLog, int64, error)
+	// PurgeBefore 删除早于 before 的审计日志，返回删除条数
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type auditLogRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewAuditLogRepo(data *infra.Data, logger log.Logger) AuditLogRepo {
+	return &auditLogRepo{
+		data: data,
+		log:  log.NewHelper(log.With(logger, "module", "repo/audit_log")),
+	}
+}
+
+func (r *auditLogRepo) Create(ctx context.Context, m *basicModel.BasicAuditLog) error {
+	db := r.data.GetDBWithContext(ctx)
+	return db.Create(m).Error
+}
+
+func (r *auditLogRepo) List(ctx context.Context, f AuditLogFilter, page, size int32) ([]*basicModel.BasicAuditLog, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.data.GetDBWithContext(ctx).Model(&basicModel.BasicAuditLog{})
+	if f.ActorID != nil {
+		db = db.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.HouseGID != nil {
+		db = db.Where("house_gid = ?", *f.HouseGID)
+	}
+	if f.Action != "" {
+		db = db.Where("action LIKE ?", f.Action+"%")
+	}
+	if f.TargetType != "" {
+		db = db.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		db = db.Where("? = ANY(string_to_array(target_ids, ','))", f.TargetID)
+	}
+	if f.ClientIP != "" {
+		db = db.Where("client_ip = ?", f.ClientIP)
+	}
+	if f.Success != nil {
+		db = db.Where("success = ?", *f.Success)
+	}
+	if f.Start != nil {
+		db = db.Where("created_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		db = db.Where("created_at < ?", *f.End)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*basicModel.BasicAuditLog
+	err := db.Order("created_at DESC, id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *auditLogRepo) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	db := r.data.GetDBWithContext(ctx)
+	res := db.Where("created_at < ?", before).Delete(&basicModel.BasicAuditLog{})
+	return res.RowsAffected, res.Error
+}
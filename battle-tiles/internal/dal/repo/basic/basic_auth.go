@@ -19,6 +19,10 @@ type AuthRepo interface {
 	EnsureUserHasRoleByCode(ctx context.Context, userID int32, roleCode string) error
 	// EnsureUserHasOnlyRoleByCode 确保用户仅拥有指定 code 的单一角色（会清理该用户其它角色）
 	EnsureUserHasOnlyRoleByCode(ctx context.Context, userID int32, roleCode string) error
+	// ListUserIDsByRole 持有该角色的用户（全局角色 + 店铺作用域角色）
+	ListUserIDsByRole(ctx context.Context, roleID int32) ([]int32, error)
+	// ListUserIDsByPermission 通过任一角色持有该权限的用户
+	ListUserIDsByPermission(ctx context.Context, permissionID int32) ([]int32, error)
 }
 
 type authRepo struct {
@@ -144,3 +148,29 @@ func (r *authRepo) EnsureUserHasOnlyRoleByCode(ctx context.Context, userID int32
 		return nil
 	})
 }
+
+func (r *authRepo) ListUserIDsByRole(ctx context.Context, roleID int32) ([]int32, error) {
+	db := r.data.GetDBWithContext(ctx)
+	var ids []int32
+	err := db.Raw(`
+SELECT user_id FROM basic_user_role_rel WHERE role_id = ?
+UNION
+SELECT user_id FROM basic_user_scope_role WHERE role_id = ?`, roleID, roleID).
+		Scan(&ids).Error
+	return ids, err
+}
+
+func (r *authRepo) ListUserIDsByPermission(ctx context.Context, permissionID int32) ([]int32, error) {
+	db := r.data.GetDBWithContext(ctx)
+	var ids []int32
+	err := db.Raw(`
+SELECT ur.user_id FROM basic_user_role_rel ur
+JOIN basic_role_permission_rel rp ON rp.role_id = ur.role_id
+WHERE rp.permission_id = ?
+UNION
+SELECT us.user_id FROM basic_user_scope_role us
+JOIN basic_role_permission_rel rp ON rp.role_id = us.role_id
+WHERE rp.permission_id = ?`, permissionID, permissionID).
+		Scan(&ids).Error
+	return ids, err
+}
@@ -15,6 +15,7 @@ import (
 
 type BasicLoginRepo interface {
 	FindByUsername(ctx context.Context, username string) (*basic.BasicUser, error)
+	FindByID(ctx context.Context, id int32) (*basic.BasicUser, error)
 	FindByPhone(ctx context.Context, phone string) (*basic.BasicUser, error)
 	Create(ctx context.Context, user *basic.BasicUser) (int32, error)
 	UpdateLastLoginAt(ctx context.Context, id int32) error
@@ -49,6 +50,17 @@ func (r *basicLoginRepo) FindByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
+func (r *basicLoginRepo) FindByID(ctx context.Context, id int32) (*basic.BasicUser, error) {
+	var user basic.BasicUser
+	if err := r.data.GetDBWithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *basicLoginRepo) FindByPhone(ctx context.Context, phone string) (*basic.BasicUser, error) {
 	var user basic.BasicUser
 	if err := r.data.GetDBWithContext(ctx).Where("phone = ?", phone).First(&user).Error; err != nil {
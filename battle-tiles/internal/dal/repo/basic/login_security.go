@@ -0,0 +1,198 @@
+package basic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"battle-tiles/internal/infra"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginHistoryFilter 登录记录查询条件
+type LoginHistoryFilter struct {
+	UserID   *int32
+	Username string
+	ClientIP string
+	Success  *bool
+	Start    *time.Time
+	End      *time.Time
+}
+
+// LoginSecurityRepo 登录安全：登录记录、TOTP 配置（库）与失败计数/锁定（Redis）
+type LoginSecurityRepo interface {
+	// CreateHistory 写入登录记录
+	CreateHistory(ctx context.Context, m *basicModel.BasicLoginHistory) error
+	// ListHistory 分页查询登录记录（按时间倒序）
+	ListHistory(ctx context.Context, f LoginHistoryFilter, page, size int32) ([]*basicModel.BasicLoginHistory, int64, error)
+
+	// GetTOTP 读取用户 TOTP 配置（无则 nil, nil）
+	GetTOTP(ctx context.Context, userID int32) (*basicModel.BasicUserTOTP, error)
+	// SaveTOTP 新建或覆盖 TOTP 配置
+	SaveTOTP(ctx context.Context, m *basicModel.BasicUserTOTP) error
+	// AdvanceTOTPStep 记录已使用的时间步；step 不大于已记录值时返回 false（重放）
+	AdvanceTOTPStep(ctx context.Context, userID int32, step int64) (bool, error)
+	// DeleteTOTP 删除 TOTP 配置
+	DeleteTOTP(ctx context.Context, userID int32) error
+
+	// IncrFail 失败计数 +1（window 内滑动有效），返回当前计数
+	IncrFail(ctx context.Context, key string, window time.Duration) (int64, error)
+	// Lock 锁定到 until
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil 锁定截止时间（未锁定返回零值）
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// ResetFail 清除失败计数与锁定
+	ResetFail(ctx context.Context, key string) error
+}
+
+const (
+	loginFailKey = "auth:login_fail:%s"
+	loginLockKey = "auth:login_lock:%s"
+)
+
+type loginSecurityRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewLoginSecurityRepo(data *infra.Data, logger log.Logger) LoginSecurityRepo {
+	return &loginSecurityRepo{
+		data: data,
+		log:  log.NewHelper(log.With(logger, "module", "repo/login_security")),
+	}
+}
+
+func (r *loginSecurityRepo) CreateHistory(ctx context.Context, m *basicModel.BasicLoginHistory) error {
+	return r.data.GetDBWithContext(ctx).Create(m).Error
+}
+
+func (r *loginSecurityRepo) ListHistory(ctx context.Context, f LoginHistoryFilter, page, size int32) ([]*basicModel.BasicLoginHistory, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.data.GetDBWithContext(ctx).Model(&basicModel.BasicLoginHistory{})
+	if f.UserID != nil {
+		db = db.Where("user_id = ?", *f.UserID)
+	}
+	if f.Username != "" {
+		db = db.Where("username = ?", f.Username)
+	}
+	if f.ClientIP != "" {
+		db = db.Where("client_ip = ?", f.ClientIP)
+	}
+	if f.Success != nil {
+		db = db.Where("success = ?", *f.Success)
+	}
+	if f.Start != nil {
+		db = db.Where("created_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		db = db.Where("created_at < ?", *f.End)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*basicModel.BasicLoginHistory
+	err := db.Order("created_at DESC, id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *loginSecurityRepo) GetTOTP(ctx context.Context, userID int32) (*basicModel.BasicUserTOTP, error) {
+	var m basicModel.BasicUserTOTP
+	if err := r.data.GetDBWithContext(ctx).Where("user_id = ?", userID).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *loginSecurityRepo) SaveTOTP(ctx context.Context, m *basicModel.BasicUserTOTP) error {
+	return r.data.GetDBWithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled", "last_step", "enabled_at", "updated_at"}),
+	}).Create(m).Error
+}
+
+func (r *loginSecurityRepo) AdvanceTOTPStep(ctx context.Context, userID int32, step int64) (bool, error) {
+	res := r.data.GetDBWithContext(ctx).Model(&basicModel.BasicUserTOTP{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Updates(map[string]any{"last_step": step, "updated_at": time.Now()})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *loginSecurityRepo) DeleteTOTP(ctx context.Context, userID int32) error {
+	return r.data.GetDBWithContext(ctx).Where("user_id = ?", userID).Delete(&basicModel.BasicUserTOTP{}).Error
+}
+
+func (r *loginSecurityRepo) rdb() (*redis.Client, error) {
+	if r.data.RDB == nil {
+		return nil, errors.New("redis not configured")
+	}
+	return r.data.RDB, nil
+}
+
+func (r *loginSecurityRepo) IncrFail(ctx context.Context, key string, window time.Duration) (int64, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return 0, err
+	}
+	k := fmt.Sprintf(loginFailKey, key)
+	n, err := rdb.Incr(ctx, k).Result()
+	if err != nil {
+		return 0, err
+	}
+	_ = rdb.Expire(ctx, k, window).Err()
+	return n, nil
+}
+
+func (r *loginSecurityRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	rdb, err := r.rdb()
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, fmt.Sprintf(loginLockKey, key), until.Unix(), time.Until(until)).Err()
+}
+
+func (r *loginSecurityRepo) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, err := rdb.Get(ctx, fmt.Sprintf(loginLockKey, key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(ts, 0), nil
+}
+
+func (r *loginSecurityRepo) ResetFail(ctx context.Context, key string) error {
+	rdb, err := r.rdb()
+	if err != nil {
+		return err
+	}
+	return rdb.Del(ctx, fmt.Sprintf(loginFailKey, key), fmt.Sprintf(loginLockKey, key)).Err()
+}
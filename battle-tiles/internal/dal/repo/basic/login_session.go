@@ -0,0 +1,204 @@
+package basic
+
+import (
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"battle-tiles/internal/infra"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+// 登录会话全部存 Redis；RDB 的 hook 会自动给 key 加平台前缀，这里不再拼平台
+const (
+	loginSessionKey     = "auth:session:%s"       // 会话 JSON
+	loginUserSessionKey = "auth:user_sessions:%d" // 用户的会话 sid 集合
+	loginRefreshUsedKey = "auth:refresh_used:%s"  // 已消费的刷新令牌哈希
+	loginStaleKey       = "auth:stale:%d"         // 此时间之前签发的 access token 作废
+)
+
+type LoginSessionRepo interface {
+	// Save 写入/覆盖会话，TTL 取会话过期时间
+	Save(ctx context.Context, s *basicModel.LoginSession) error
+	// Get 读取会话（不存在返回 nil, nil）
+	Get(ctx context.Context, sid string) (*basicModel.LoginSession, error)
+	// ListByUser 列出用户的有效会话（顺带清理已过期的 sid）
+	ListByUser(ctx context.Context, userID int32) ([]*basicModel.LoginSession, error)
+	// Delete 删除单个会话
+	Delete(ctx context.Context, userID int32, sid string) error
+	// DeleteByUser 删除用户全部会话，返回删除数量
+	DeleteByUser(ctx context.Context, userID int32) (int, error)
+	// ConsumeRefresh 标记刷新令牌已使用；返回 false 表示该令牌此前已被使用过
+	ConsumeRefresh(ctx context.Context, hash string, ttl time.Duration) (bool, error)
+	// MarkStale 令用户在 at 之前签发的 access token 失效
+	MarkStale(ctx context.Context, userID int32, at time.Time, ttl time.Duration) error
+	// Check 会话是否存在，以及用户的失效时间点（unix 秒，0 表示无）
+	Check(ctx context.Context, userID int32, sid string) (exists bool, staleAt int64, err error)
+}
+
+type loginSessionRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewLoginSessionRepo(data *infra.Data, logger log.Logger) LoginSessionRepo {
+	return &loginSessionRepo{
+		data: data,
+		log:  log.NewHelper(log.With(logger, "module", "repo/loginSession")),
+	}
+}
+
+func (r *loginSessionRepo) rdb() (*redis.Client, error) {
+	if r.data.RDB == nil {
+		return nil, errors.New("redis not configured")
+	}
+	return r.data.RDB, nil
+}
+
+func (r *loginSessionRepo) Save(ctx context.Context, s *basicModel.LoginSession) error {
+	rdb, err := r.rdb()
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(time.Unix(s.ExpiresAt, 0))
+	if ttl <= 0 {
+		return errors.New("session already expired")
+	}
+	// RefreshHash/PrevHash 不对外序列化，单独包一层落 Redis
+	bs, err := json.Marshal(storedSession{LoginSession: s, RefreshHash: s.RefreshHash, PrevHash: s.PrevHash})
+	if err != nil {
+		return err
+	}
+	if err = rdb.Set(ctx, fmt.Sprintf(loginSessionKey, s.SID), bs, ttl).Err(); err != nil {
+		return err
+	}
+	userKey := fmt.Sprintf(loginUserSessionKey, s.UserID)
+	if err = rdb.SAdd(ctx, userKey, s.SID).Err(); err != nil {
+		return err
+	}
+	// 集合跟随最新会话续期；过期成员在 ListByUser 时清理
+	return rdb.Expire(ctx, userKey, ttl).Err()
+}
+
+type storedSession struct {
+	*basicModel.LoginSession
+	RefreshHash string `json:"refresh_hash"`
+	PrevHash    string `json:"prev_hash"`
+}
+
+func (r *loginSessionRepo) Get(ctx context.Context, sid string) (*basicModel.LoginSession, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return nil, err
+	}
+	bs, err := rdb.Get(ctx, fmt.Sprintf(loginSessionKey, sid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := storedSession{LoginSession: &basicModel.LoginSession{}}
+	if err = json.Unmarshal(bs, &st); err != nil {
+		return nil, err
+	}
+	st.LoginSession.RefreshHash = st.RefreshHash
+	st.LoginSession.PrevHash = st.PrevHash
+	return st.LoginSession, nil
+}
+
+func (r *loginSessionRepo) ListByUser(ctx context.Context, userID int32) ([]*basicModel.LoginSession, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return nil, err
+	}
+	userKey := fmt.Sprintf(loginUserSessionKey, userID)
+	sids, err := rdb.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*basicModel.LoginSession, 0, len(sids))
+	for _, sid := range sids {
+		s, err := r.Get(ctx, sid)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			_ = rdb.SRem(ctx, userKey, sid).Err()
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (r *loginSessionRepo) Delete(ctx context.Context, userID int32, sid string) error {
+	rdb, err := r.rdb()
+	if err != nil {
+		return err
+	}
+	if err = rdb.Del(ctx, fmt.Sprintf(loginSessionKey, sid)).Err(); err != nil {
+		return err
+	}
+	return rdb.SRem(ctx, fmt.Sprintf(loginUserSessionKey, userID), sid).Err()
+}
+
+func (r *loginSessionRepo) DeleteByUser(ctx context.Context, userID int32) (int, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return 0, err
+	}
+	userKey := fmt.Sprintf(loginUserSessionKey, userID)
+	sids, err := rdb.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(sids)+1)
+	for _, sid := range sids {
+		keys = append(keys, fmt.Sprintf(loginSessionKey, sid))
+	}
+	keys = append(keys, userKey)
+	if err = rdb.Del(ctx, keys...).Err(); err != nil {
+		return 0, err
+	}
+	return len(sids), nil
+}
+
+func (r *loginSessionRepo) ConsumeRefresh(ctx context.Context, hash string, ttl time.Duration) (bool, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return false, err
+	}
+	return rdb.SetNX(ctx, fmt.Sprintf(loginRefreshUsedKey, hash), 1, ttl).Result()
+}
+
+func (r *loginSessionRepo) MarkStale(ctx context.Context, userID int32, at time.Time, ttl time.Duration) error {
+	rdb, err := r.rdb()
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, fmt.Sprintf(loginStaleKey, userID), at.Unix(), ttl).Err()
+}
+
+func (r *loginSessionRepo) Check(ctx context.Context, userID int32, sid string) (bool, int64, error) {
+	rdb, err := r.rdb()
+	if err != nil {
+		return false, 0, err
+	}
+	n, err := rdb.Exists(ctx, fmt.Sprintf(loginSessionKey, sid)).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	if n == 0 {
+		return false, 0, nil
+	}
+	staleAt, err := rdb.Get(ctx, fmt.Sprintf(loginStaleKey, userID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return true, 0, err
+	}
+	return true, staleAt, nil
+}
@@ -0,0 +1,96 @@
+package basic
+
+import (
+	"context"
+
+	basicModel "battle-tiles/internal/dal/model/basic"
+	"battle-tiles/internal/infra"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm/clause"
+)
+
+// UserScopeRoleView 作用域授权 + 角色信息
+type UserScopeRoleView struct {
+	basicModel.BasicUserScopeRole
+	RoleCode string `gorm:"column:role_code" json:"role_code"`
+	RoleName string `gorm:"column:role_name" json:"role_name"`
+}
+
+type UserScopeRoleRepo interface {
+	// Grant 授予作用域角色（已存在则忽略）
+	Grant(ctx context.Context, m *basicModel.BasicUserScopeRole) error
+	// Revoke 撤销一条作用域角色
+	Revoke(ctx context.Context, userID, roleID, houseGID, groupID int32) error
+	// RevokeHouse 撤销用户在某店铺下的全部作用域角色
+	RevokeHouse(ctx context.Context, userID, houseGID int32) error
+	// ListByUser 用户的全部作用域角色
+	ListByUser(ctx context.Context, userID int32) ([]*UserScopeRoleView, error)
+	// ListByHouse 店铺下的全部作用域角色
+	ListByHouse(ctx context.Context, houseGID int32) ([]*UserScopeRoleView, error)
+	// RoleIDByCode 按角色编码查角色ID（不存在返回 0）
+	RoleIDByCode(ctx context.Context, code string) (int32, error)
+}
+
+type userScopeRoleRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewUserScopeRoleRepo(data *infra.Data, logger log.Logger) UserScopeRoleRepo {
+	return &userScopeRoleRepo{
+		data: data,
+		log:  log.NewHelper(log.With(logger, "module", "repo/user_scope_role")),
+	}
+}
+
+func (r *userScopeRoleRepo) Grant(ctx context.Context, m *basicModel.BasicUserScopeRole) error {
+	db := r.data.GetDBWithContext(ctx)
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}, {Name: "house_gid"}, {Name: "group_id"}},
+		DoNothing: true,
+	}).Create(m).Error
+}
+
+func (r *userScopeRoleRepo) Revoke(ctx context.Context, userID, roleID, houseGID, groupID int32) error {
+	db := r.data.GetDBWithContext(ctx)
+	return db.Where("user_id = ? AND role_id = ? AND house_gid = ? AND group_id = ?", userID, roleID, houseGID, groupID).
+		Delete(&basicModel.BasicUserScopeRole{}).Error
+}
+
+func (r *userScopeRoleRepo) RevokeHouse(ctx context.Context, userID, houseGID int32) error {
+	db := r.data.GetDBWithContext(ctx)
+	return db.Where("user_id = ? AND house_gid = ?", userID, houseGID).
+		Delete(&basicModel.BasicUserScopeRole{}).Error
+}
+
+func (r *userScopeRoleRepo) list(ctx context.Context, where string, arg int32) ([]*UserScopeRoleView, error) {
+	db := r.data.GetDBWithContext(ctx)
+	var out []*UserScopeRoleView
+	err := db.Table(basicModel.TableNameBasicUserScopeRole+" AS s").
+		Select("s.*, r.code AS role_code, r.name AS role_name").
+		Joins("JOIN basic_role AS r ON r.id = s.role_id").
+		Where(where, arg).
+		Order("s.house_gid, s.group_id, s.user_id").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *userScopeRoleRepo) ListByUser(ctx context.Context, userID int32) ([]*UserScopeRoleView, error) {
+	return r.list(ctx, "s.user_id = ?", userID)
+}
+
+func (r *userScopeRoleRepo) ListByHouse(ctx context.Context, houseGID int32) ([]*UserScopeRoleView, error) {
+	return r.list(ctx, "s.house_gid = ?", houseGID)
+}
+
+func (r *userScopeRoleRepo) RoleIDByCode(ctx context.Context, code string) (int32, error) {
+	db := r.data.GetDBWithContext(ctx)
+	var id int32
+	err := db.Table("basic_role").
+		Select("id").
+		Where("code = ? AND is_deleted = ?", code, false).
+		Limit(1).
+		Scan(&id).Error
+	return id, err
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ApplicationRuleRepo interface {
+	Create(ctx context.Context, m *model.GameApplicationRule) error
+	Update(ctx context.Context, m *model.GameApplicationRule) error
+	Delete(ctx context.Context, houseGID, id int32) error
+	Get(ctx context.Context, id int32) (*model.GameApplicationRule, error)
+	// ListByHouse 店铺全部规则（按优先级）
+	ListByHouse(ctx context.Context, houseGID int32) ([]*model.GameApplicationRule, error)
+	// ListEnabled 店铺已启用规则（按优先级）
+	ListEnabled(ctx context.Context, houseGID int32) ([]*model.GameApplicationRule, error)
+}
+
+type applicationRuleRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewApplicationRuleRepo(data *infra.Data, logger log.Logger) ApplicationRuleRepo {
+	return &applicationRuleRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/application_rule"))}
+}
+
+func (r *applicationRuleRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *applicationRuleRepo) Create(ctx context.Context, m *model.GameApplicationRule) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *applicationRuleRepo) Update(ctx context.Context, m *model.GameApplicationRule) error {
+	return r.db(ctx).Save(m).Error
+}
+
+func (r *applicationRuleRepo) Delete(ctx context.Context, houseGID, id int32) error {
+	res := r.db(ctx).Where("id = ? AND house_gid = ?", id, houseGID).Delete(&model.GameApplicationRule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *applicationRuleRepo) Get(ctx context.Context, id int32) (*model.GameApplicationRule, error) {
+	var out model.GameApplicationRule
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *applicationRuleRepo) ListByHouse(ctx context.Context, houseGID int32) ([]*model.GameApplicationRule, error) {
+	var list []*model.GameApplicationRule
+	err := r.db(ctx).Where("house_gid = ?", houseGID).Order("priority ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *applicationRuleRepo) ListEnabled(ctx context.Context, houseGID int32) ([]*model.GameApplicationRule, error) {
+	var list []*model.GameApplicationRule
+	err := r.db(ctx).Where("house_gid = ? AND enabled = ?", houseGID, true).Order("priority ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ShopApplicationLogFilter 处理记录查询条件
+type ShopApplicationLogFilter struct {
+	Source  string
+	Decider string
+	RuleID  *int32
+	Start   *time.Time
+	End     *time.Time
+}
+
+type ShopApplicationLogRepo interface {
+	// CreateDecision 写入处理记录；同一申请（店铺+来源+申请ID）已有记录时不写入并返回 false
+	CreateDecision(ctx context.Context, in *model.GameShopApplicationLog) (bool, error)
+	// List 分页查询处理记录（按时间倒序）
+	List(ctx context.Context, houseGID int32, f ShopApplicationLogFilter, page, size int32) ([]*model.GameShopApplicationLog, int64, error)
+	// ExistsDecision 是否已有该申请的处理记录（游戏内申请去重用）
+	ExistsDecision(ctx context.Context, houseGID int32, source string, applicationID int32) (bool, error)
+	// DeleteDecision 删除处理记录（游戏内申请回发失败时释放占位，之后可再处理）
+	DeleteDecision(ctx context.Context, id int32) error
+}
+
+type shopApplicationLogRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewShopApplicationLogRepo(data *infra.Data, logger log.Logger) ShopApplicationLogRepo {
+	return &shopApplicationLogRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/shop_application_log"))}
+}
+
+func (r *shopApplicationLogRepo) db(ctx context.Context) *gorm.DB {
+	return r.data.GetDBWithContext(ctx)
+}
+
+func (r *shopApplicationLogRepo) CreateDecision(ctx context.Context, in *model.GameShopApplicationLog) (bool, error) {
+	res := r.db(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "house_gid"}, {Name: "source"}, {Name: "application_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "application_id > 0"}}},
+		DoNothing:   true,
+	}).Create(in)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *shopApplicationLogRepo) List(ctx context.Context, houseGID int32, f ShopApplicationLogFilter, page, size int32) ([]*model.GameShopApplicationLog, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameShopApplicationLog{}).Where("house_gid = ?", houseGID)
+	if f.Source != "" {
+		db = db.Where("source = ?", f.Source)
+	}
+	if f.Decider != "" {
+		db = db.Where("decider = ?", f.Decider)
+	}
+	if f.RuleID != nil {
+		db = db.Where("rule_id = ?", *f.RuleID)
+	}
+	if f.Start != nil {
+		db = db.Where("created_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		db = db.Where("created_at < ?", *f.End)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameShopApplicationLog
+	err := db.Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *shopApplicationLogRepo) DeleteDecision(ctx context.Context, id int32) error {
+	return r.db(ctx).Delete(&model.GameShopApplicationLog{}, id).Error
+}
+
+func (r *shopApplicationLogRepo) ExistsDecision(ctx context.Context, houseGID int32, source string, applicationID int32) (bool, error) {
+	var cnt int64
+	err := r.db(ctx).Model(&model.GameShopApplicationLog{}).
+		Where("house_gid = ? AND source = ? AND application_id = ?", houseGID, source, applicationID).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BattleConfigRepo interface {
+	// Sync 以会话推送的全量玩法为准：逐条 upsert，不在列表中的标记 removed
+	Sync(ctx context.Context, houseGID int32, list []*model.GameBattleConfig) error
+	// ListByHouse 店铺玩法（按 config_id）；includeRemoved 为 false 时只返回现存玩法
+	ListByHouse(ctx context.Context, houseGID int32, includeRemoved bool) ([]*model.GameBattleConfig, error)
+}
+
+type battleConfigRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewBattleConfigRepo(data *infra.Data, logger log.Logger) BattleConfigRepo {
+	return &battleConfigRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/battle_config"))}
+}
+
+func (r *battleConfigRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *battleConfigRepo) Sync(ctx context.Context, houseGID int32, list []*model.GameBattleConfig) error {
+	return r.db(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]int32, 0, len(list))
+		for _, m := range list {
+			m.HouseGID = houseGID
+			m.Removed = false
+			m.RemovedAt = nil
+			ids = append(ids, m.ConfigID)
+		}
+		if len(list) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "house_gid"}, {Name: "config_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"kind_id", "base_score", "play_count", "player_count", "name", "removed", "removed_at", "updated_at"}),
+			}).Create(&list).Error; err != nil {
+				return err
+			}
+		}
+		q := tx.Model(&model.GameBattleConfig{}).Where("house_gid = ? AND removed = ?", houseGID, false)
+		if len(ids) > 0 {
+			q = q.Where("config_id NOT IN ?", ids)
+		}
+		now := time.Now()
+		return q.Updates(map[string]any{"removed": true, "removed_at": now, "updated_at": now}).Error
+	})
+}
+
+func (r *battleConfigRepo) ListByHouse(ctx context.Context, houseGID int32, includeRemoved bool) ([]*model.GameBattleConfig, error) {
+	db := r.db(ctx).Where("house_gid = ?", houseGID)
+	if !includeRemoved {
+		db = db.Where("removed = ?", false)
+	}
+	var list []*model.GameBattleConfig
+	err := db.Order("config_id ASC").Find(&list).Error
+	return list, err
+}
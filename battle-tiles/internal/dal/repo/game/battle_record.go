@@ -30,6 +30,9 @@ type BattleRecordRepo interface {
 	GetGroupStats(ctx context.Context, houseGID int32, groupID int32, start, end *time.Time) (totalGames int64, totalScore int, totalFee int, activeMembers int64, err error)
 
 	GetHouseStats(ctx context.Context, houseGID int32, start, end *time.Time) (totalGames int64, totalScore int, totalFee int, err error)
+
+	// CountTables 时间范围内开出的桌数（同一桌按 room_uid + battle_at 去重，一局多行只算一次）
+	CountTables(ctx context.Context, houseGID int32, start, end time.Time) (int64, error)
 }
 
 type battleRecordRepo struct {
@@ -342,3 +345,12 @@ func (r *battleRecordRepo) GetHouseStats(ctx context.Context, houseGID int32, st
 
 	return totalGames, result.TotalScore, result.TotalFee, nil
 }
+
+func (r *battleRecordRepo) CountTables(ctx context.Context, houseGID int32, start, end time.Time) (int64, error) {
+	var n int64
+	err := r.db(ctx).Model(&model.GameBattleRecord{}).
+		Where("house_gid = ? AND battle_at >= ? AND battle_at < ?", houseGID, start, end).
+		Select("COUNT(DISTINCT (room_uid, battle_at))").
+		Scan(&n).Error
+	return n, err
+}
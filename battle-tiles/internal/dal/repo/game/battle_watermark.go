@@ -0,0 +1,50 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type BattleWatermarkRepo interface {
+	// Get 无记录返回 gorm.ErrRecordNotFound
+	Get(ctx context.Context, houseGID int32) (*model.GameBattleWatermark, error)
+	// Advance 推进高水位：最新一局按 (create_time, room_id) 取大，coveredUntil 为 nil 时不变
+	Advance(ctx context.Context, houseGID int32, createTime int64, roomID int32, coveredUntil *time.Time) error
+}
+
+type battleWatermarkRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewBattleWatermarkRepo(data *infra.Data, logger log.Logger) BattleWatermarkRepo {
+	return &battleWatermarkRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/battle_watermark"))}
+}
+
+func (r *battleWatermarkRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *battleWatermarkRepo) Get(ctx context.Context, houseGID int32) (*model.GameBattleWatermark, error) {
+	var out model.GameBattleWatermark
+	if err := r.db(ctx).Where("house_gid = ?", houseGID).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *battleWatermarkRepo) Advance(ctx context.Context, houseGID int32, createTime int64, roomID int32, coveredUntil *time.Time) error {
+	return r.db(ctx).Exec(`
+INSERT INTO game_battle_watermark AS w (house_gid, last_create_time, last_room_id, covered_until, updated_at)
+VALUES (?, ?, ?, ?, now())
+ON CONFLICT (house_gid) DO UPDATE SET
+	last_room_id = CASE WHEN (EXCLUDED.last_create_time, EXCLUDED.last_room_id) > (w.last_create_time, w.last_room_id)
+		THEN EXCLUDED.last_room_id ELSE w.last_room_id END,
+	last_create_time = GREATEST(w.last_create_time, EXCLUDED.last_create_time),
+	covered_until = GREATEST(w.covered_until, EXCLUDED.covered_until),
+	updated_at = now()`,
+		houseGID, createTime, roomID, coveredUntil).Error
+}
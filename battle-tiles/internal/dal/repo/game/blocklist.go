@@ -0,0 +1,148 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlocklistKey 一个待检查的标识
+type BlocklistKey struct {
+	Kind  string
+	Value string
+}
+
+// BlocklistFilter 列表过滤；空值不过滤
+type BlocklistFilter struct {
+	Kind             string
+	Value            string
+	ReportedHouseGID int32
+	IncludeExpired   bool
+}
+
+type BlocklistRepo interface {
+	// Upsert 按 kind+value 新增或更新原因/过期时间，上报店铺保持首次上报的不变；
+	// ownerOnly 时仅当已有记录由同一店铺上报才更新，返回 false 表示已由其他店铺上报、未写入
+	Upsert(ctx context.Context, m *model.GameBlocklist, ownerOnly bool) (bool, error)
+	Delete(ctx context.Context, id int32) error
+	Get(ctx context.Context, id int32) (*model.GameBlocklist, error)
+	List(ctx context.Context, f BlocklistFilter, page, size int32) ([]*model.GameBlocklist, int64, error)
+	// FindActive 返回第一条命中且未过期的记录，未命中返回 gorm.ErrRecordNotFound
+	FindActive(ctx context.Context, keys []BlocklistKey, now time.Time) (*model.GameBlocklist, error)
+	// ListActiveValues 某类标识下全部未过期的值
+	ListActiveValues(ctx context.Context, kind string, now time.Time) ([]string, error)
+}
+
+type blocklistRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewBlocklistRepo(data *infra.Data, logger log.Logger) BlocklistRepo {
+	return &blocklistRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/blocklist"))}
+}
+
+func (r *blocklistRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *blocklistRepo) Upsert(ctx context.Context, m *model.GameBlocklist, ownerOnly bool) (bool, error) {
+	oc := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "expire_at", "created_by", "updated_at"}),
+	}
+	if ownerOnly {
+		oc.Where = clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: `"game_blocklist"."reported_house_gid" = EXCLUDED."reported_house_gid"`},
+		}}
+	}
+	// RETURNING 回填实际落库的行（更新时上报店铺为原值）
+	res := r.db(ctx).Clauses(oc, clause.Returning{}).Create(m)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *blocklistRepo) Delete(ctx context.Context, id int32) error {
+	res := r.db(ctx).Where("id = ?", id).Delete(&model.GameBlocklist{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *blocklistRepo) Get(ctx context.Context, id int32) (*model.GameBlocklist, error) {
+	var out model.GameBlocklist
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *blocklistRepo) List(ctx context.Context, f BlocklistFilter, page, size int32) ([]*model.GameBlocklist, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameBlocklist{})
+	if f.Kind != "" {
+		db = db.Where("kind = ?", f.Kind)
+	}
+	if f.Value != "" {
+		db = db.Where("value = ?", f.Value)
+	}
+	if f.ReportedHouseGID > 0 {
+		db = db.Where("reported_house_gid = ?", f.ReportedHouseGID)
+	}
+	if !f.IncludeExpired {
+		db = db.Where("expire_at IS NULL OR expire_at > ?", time.Now())
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameBlocklist
+	err := db.Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *blocklistRepo) FindActive(ctx context.Context, keys []BlocklistKey, now time.Time) (*model.GameBlocklist, error) {
+	if len(keys) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cond := r.db(ctx)
+	for i, k := range keys {
+		if i == 0 {
+			cond = cond.Where("kind = ? AND value = ?", k.Kind, k.Value)
+		} else {
+			cond = cond.Or("kind = ? AND value = ?", k.Kind, k.Value)
+		}
+	}
+	var out model.GameBlocklist
+	if err := r.db(ctx).
+		Where(cond).
+		Where("expire_at IS NULL OR expire_at > ?", now).
+		Order("id ASC").
+		First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *blocklistRepo) ListActiveValues(ctx context.Context, kind string, now time.Time) ([]string, error) {
+	var out []string
+	err := r.db(ctx).Model(&model.GameBlocklist{}).
+		Where("kind = ?", kind).
+		Where("expire_at IS NULL OR expire_at > ?", now).
+		Pluck("value", &out).Error
+	return out, err
+}
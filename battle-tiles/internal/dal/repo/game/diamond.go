@@ -0,0 +1,59 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type DiamondRepo interface {
+	Insert(ctx context.Context, m *model.GameDiamondSnapshot) error
+	// Latest 店铺最近一条余额；ctrlAccountID>0 时只看该中控账号，无记录返回 gorm.ErrRecordNotFound
+	Latest(ctx context.Context, houseGID, ctrlAccountID int32) (*model.GameDiamondSnapshot, error)
+	// ListRange 时间范围内的余额（按时间升序），ctrlAccountID>0 时只看该中控账号
+	ListRange(ctx context.Context, houseGID, ctrlAccountID int32, start, end time.Time, limit int) ([]*model.GameDiamondSnapshot, error)
+}
+
+type diamondRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewDiamondRepo(data *infra.Data, logger log.Logger) DiamondRepo {
+	return &diamondRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/diamond"))}
+}
+
+func (r *diamondRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *diamondRepo) Insert(ctx context.Context, m *model.GameDiamondSnapshot) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *diamondRepo) Latest(ctx context.Context, houseGID, ctrlAccountID int32) (*model.GameDiamondSnapshot, error) {
+	db := r.db(ctx).Where("house_gid = ?", houseGID)
+	if ctrlAccountID > 0 {
+		db = db.Where("ctrl_account_id = ?", ctrlAccountID)
+	}
+	var out model.GameDiamondSnapshot
+	if err := db.Order("created_at DESC, id DESC").First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *diamondRepo) ListRange(ctx context.Context, houseGID, ctrlAccountID int32, start, end time.Time, limit int) ([]*model.GameDiamondSnapshot, error) {
+	if limit <= 0 || limit > 5000 {
+		limit = 5000
+	}
+	db := r.db(ctx).Where("house_gid = ? AND created_at >= ? AND created_at < ?", houseGID, start, end)
+	if ctrlAccountID > 0 {
+		db = db.Where("ctrl_account_id = ?", ctrlAccountID)
+	}
+	var out []*model.GameDiamondSnapshot
+	err := db.Order("created_at ASC, id ASC").Limit(limit).Find(&out).Error
+	return out, err
+}
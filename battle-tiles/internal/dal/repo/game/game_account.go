@@ -3,7 +3,6 @@ package game
 
 import (
 	"context"
-	"strings"
 
 	model "battle-tiles/internal/dal/model/game"
 	"battle-tiles/internal/infra"
@@ -39,7 +38,6 @@ func NewGameAccountRepo(data *infra.Data, logger log.Logger) GameAccountRepo {
 }
 
 func (r *gameAccountRepo) Create(ctx context.Context, a *model.GameAccount) error {
-	a.PwdMD5 = strings.ToUpper(strings.TrimSpace(a.PwdMD5))
 	return r.data.GetDBWithContext(ctx).Create(a).Error
 }
 
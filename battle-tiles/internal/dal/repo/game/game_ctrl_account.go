@@ -57,7 +57,7 @@ func (r *gameCtrlAccountRepo) Upsert(ctx context.Context, in *model.GameCtrlAcco
 	err := db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "login_mode"}, {Name: "identifier"}},
 		DoUpdates: clause.AssignmentColumns([]string{
-			"pwd_md5", "status", "last_verify_at", "updated_at",
+			"pwd_md5", "pwd_key_ver", "status", "last_verify_at", "updated_at",
 			"game_user_id", "game_id",
 		}),
 	}).Create(in).Error
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GameMemberRepo interface {
@@ -25,6 +26,15 @@ type GameMemberRepo interface {
 
 	// 鏍规嵁 member_id 鏌ヨ鎴愬憳
 	GetByID(ctx context.Context, memberID int32) (*model.GameMember, error)
+
+	// Create 新建成员
+	Create(ctx context.Context, m *model.GameMember) error
+
+	// Delete 删除成员（入店流程撤销用）
+	Delete(ctx context.Context, houseGID int32, memberID int32) error
+
+	// UpdateForbid 按游戏ID设置禁止状态，只改动状态不同的行，返回实际变化的游戏ID
+	UpdateForbid(ctx context.Context, houseGID int32, gameIDs []int32, forbid bool) ([]int32, error)
 }
 
 type gameMemberRepo struct {
@@ -124,3 +134,36 @@ func (r *gameMemberRepo) GetByID(ctx context.Context, memberID int32) (*model.Ga
 
 	return &member, nil
 }
+
+// Create 新建成员
+func (r *gameMemberRepo) Create(ctx context.Context, m *model.GameMember) error {
+	return r.db(ctx).Create(m).Error
+}
+
+// Delete 删除成员（入店流程撤销用）
+func (r *gameMemberRepo) Delete(ctx context.Context, houseGID int32, memberID int32) error {
+	return r.db(ctx).Where("house_gid = ? AND id = ?", houseGID, memberID).Delete(&model.GameMember{}).Error
+}
+
+func (r *gameMemberRepo) UpdateForbid(ctx context.Context, houseGID int32, gameIDs []int32, forbid bool) ([]int32, error) {
+	if len(gameIDs) == 0 {
+		return nil, nil
+	}
+	var rows []*model.GameMember
+	err := r.db(ctx).Model(&rows).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "game_id"}}}).
+		Where("house_gid = ? AND game_id IN ? AND forbid <> ?", houseGID, gameIDs, forbid).
+		Updates(map[string]any{"forbid": forbid, "updated_at": gorm.Expr("now()")}).Error
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int32]struct{}, len(rows))
+	out := make([]int32, 0, len(rows))
+	for _, m := range rows {
+		if _, ok := seen[m.GameID]; !ok {
+			seen[m.GameID] = struct{}{}
+			out = append(out, m.GameID)
+		}
+	}
+	return out, nil
+}
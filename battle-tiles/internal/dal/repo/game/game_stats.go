@@ -4,6 +4,7 @@ package game
 import (
 	"battle-tiles/internal/infra"
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -27,6 +28,10 @@ type GameStatsRepo interface {
 	GetWalletByMember(ctx context.Context, houseGID, memberID int) (int64, error)
 	// 按店铺统计当前在线会话数
 	ListActiveSessionsByHouse(ctx context.Context) ([]ActiveByHouse, error)
+	// 战绩时间序列（按 hour/day/week 分桶）
+	BattleSeries(ctx context.Context, bucket string, scope SeriesScope, start, end time.Time) ([]BattleSeriesRow, error)
+	// 上下分时间序列（按 hour/day/week 分桶）
+	LedgerSeries(ctx context.Context, bucket string, scope SeriesScope, start, end time.Time) ([]LedgerSeriesRow, error)
 }
 
 type statsRepo struct {
@@ -194,3 +199,87 @@ func (r *statsRepo) ListActiveSessionsByHouse(ctx context.Context) ([]ActiveByHo
 		Scan(&rows).Error
 	return rows, err
 }
+
+// SeriesScope 时间序列统计范围：店铺 / 圈 / 成员（MemberID 为 game_member.id）
+type SeriesScope struct {
+	HouseGID int
+	GroupID  *int
+	MemberID *int
+}
+
+// BattleSeriesRow 战绩分桶聚合
+type BattleSeriesRow struct {
+	Bucket        time.Time `gorm:"column:bucket"`
+	Games         int64     `gorm:"column:games"`
+	Fee           int64     `gorm:"column:fee"`
+	ScoreVolume   int64     `gorm:"column:score_volume"`
+	ActivePlayers int64     `gorm:"column:active_players"`
+}
+
+// LedgerSeriesRow 上下分分桶聚合
+type LedgerSeriesRow struct {
+	Bucket   time.Time `gorm:"column:bucket"`
+	Deposit  int64     `gorm:"column:deposit"`
+	Withdraw int64     `gorm:"column:withdraw"`
+}
+
+// seriesZone 分桶所用的时区（取 start 的时区），保证 date_trunc 的日/周边界与调用方一致。
+// 未命名的时区（Local 等）退回 start 的固定偏移，写成 POSIX 形式（东八区为 UTC-08:00）
+func seriesZone(start time.Time) string {
+	if name := start.Location().String(); name != "" && name != "Local" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	_, off := start.Zone()
+	sign := "-"
+	if off < 0 {
+		sign, off = "+", -off
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, off/3600, off%3600/60)
+}
+
+// bucket 取值 hour/day/week，直接作为 date_trunc 的精度（week 以周一为起点），
+// 在调用方时区内截断后再转回时间点
+func (r *statsRepo) BattleSeries(ctx context.Context, bucket string, scope SeriesScope, start, end time.Time) ([]BattleSeriesRow, error) {
+	zone := seriesZone(start)
+	db := r.db(ctx).Table("game_battle_record").
+		Select(`date_trunc(?, battle_at AT TIME ZONE ?) AT TIME ZONE ? AS bucket,
+			COUNT(DISTINCT (room_uid, battle_at))           AS games,
+			COALESCE(SUM(fee), 0)                           AS fee,
+			COALESCE(SUM(ABS(score)), 0)                    AS score_volume,
+			COUNT(DISTINCT player_game_id)                  AS active_players`, bucket, zone, zone).
+		Where("house_gid = ? AND battle_at >= ? AND battle_at < ?", scope.HouseGID, start, end)
+	if scope.GroupID != nil {
+		db = db.Where("group_id = ?", *scope.GroupID)
+	}
+	if scope.MemberID != nil {
+		// 战绩按游戏账号记录，经 game_member 映射到成员
+		db = db.Where("player_game_id IN (?)", r.db(ctx).Table("game_member").
+			Select("game_id").
+			Where("id = ? AND house_gid = ?", *scope.MemberID, scope.HouseGID))
+	}
+	var rows []BattleSeriesRow
+	err := db.Group("bucket").Order("bucket").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *statsRepo) LedgerSeries(ctx context.Context, bucket string, scope SeriesScope, start, end time.Time) ([]LedgerSeriesRow, error) {
+	zone := seriesZone(start)
+	db := r.db(ctx).Table("game_wallet_ledger").
+		Select(`date_trunc(?, created_at AT TIME ZONE ?) AT TIME ZONE ?            AS bucket,
+			COALESCE(SUM(CASE WHEN type = 1 THEN change_amount ELSE 0 END), 0)        AS deposit,
+			COALESCE(SUM(CASE WHEN type IN (2, 3) THEN -change_amount ELSE 0 END), 0) AS withdraw`, bucket, zone, zone).
+		Where("house_gid = ? AND created_at >= ? AND created_at < ?", scope.HouseGID, start, end)
+	if scope.GroupID != nil {
+		db = db.Where("member_id IN (?)", r.db(ctx).Table("game_member_wallet").
+			Select("member_id").
+			Where("house_gid = ? AND group_id = ?", scope.HouseGID, *scope.GroupID))
+	}
+	if scope.MemberID != nil {
+		db = db.Where("member_id = ?", *scope.MemberID)
+	}
+	var rows []LedgerSeriesRow
+	err := db.Group("bucket").Order("bucket").Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,28 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesZone(t *testing.T) {
+	sh, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	cases := []struct {
+		start time.Time
+		want  string
+	}{
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, sh), "Asia/Shanghai"},
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "UTC"},
+		// 未命名时区按 POSIX 写法反号
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("", 8*3600)), "UTC-08:00"},
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("", -(5*3600+1800))), "UTC+05:30"},
+	}
+	for _, c := range cases {
+		if got := seriesZone(c.start); got != c.want {
+			t.Errorf("seriesZone(%v) = %q, want %q", c.start, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupSettlementRepo interface {
+	// AggregateBattleByGroup 按圈聚合战绩（局数/人次/输赢/运费）
+	AggregateBattleByGroup(ctx context.Context, houseGID int32, start, end time.Time) ([]GroupBattleAgg, error)
+	// Upsert 按 (house, group, period) 写入草稿结算单；已冻结/已打款的结算单不会被覆盖
+	Upsert(ctx context.Context, m *model.GameGroupSettlement) error
+	// GetByID 获取结算单
+	GetByID(ctx context.Context, id int32) (*model.GameGroupSettlement, error)
+	// GetByPeriod 按周期获取结算单
+	GetByPeriod(ctx context.Context, houseGID, groupID int32, start, end time.Time) (*model.GameGroupSettlement, error)
+	// List 分页查询结算单
+	List(ctx context.Context, f SettlementFilter, page, size int32) ([]*model.GameGroupSettlement, int64, error)
+	// UpdateStatus 状态流转（from -> to），返回是否命中
+	UpdateStatus(ctx context.Context, id int32, from, to int32, opUser int32, at time.Time) (bool, error)
+}
+
+// GroupBattleAgg 圈维度战绩聚合
+type GroupBattleAgg struct {
+	GroupID     int32 `gorm:"column:group_id"`
+	Games       int64 `gorm:"column:games"`
+	PlayerGames int64 `gorm:"column:player_games"`
+	ScoreNet    int64 `gorm:"column:score_net"`
+	ScoreFlow   int64 `gorm:"column:score_flow"`
+	FeeTotal    int64 `gorm:"column:fee_total"`
+}
+
+// SettlementFilter 结算单查询条件
+type SettlementFilter struct {
+	HouseGID    int32
+	GroupID     *int32
+	AdminUserID *int32
+	Status      *int32
+	Start       *time.Time
+	End         *time.Time
+}
+
+type groupSettlementRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewGroupSettlementRepo(data *infra.Data, logger log.Logger) GroupSettlementRepo {
+	return &groupSettlementRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/group_settlement"))}
+}
+
+func (r *groupSettlementRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *groupSettlementRepo) AggregateBattleByGroup(ctx context.Context, houseGID int32, start, end time.Time) ([]GroupBattleAgg, error) {
+	raw := `
+SELECT
+	group_id,
+	COUNT(DISTINCT (room_uid, battle_at)) AS games,
+	COUNT(*)                              AS player_games,
+	COALESCE(SUM(score), 0)               AS score_net,
+	COALESCE(SUM(ABS(score)), 0)          AS score_flow,
+	COALESCE(SUM(fee), 0)                 AS fee_total
+FROM game_battle_record
+WHERE house_gid = ? AND battle_at >= ? AND battle_at < ? AND group_id > 0
+GROUP BY group_id;
+`
+	var rows []GroupBattleAgg
+	if err := r.db(ctx).Raw(raw, houseGID, start, end).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *groupSettlementRepo) Upsert(ctx context.Context, m *model.GameGroupSettlement) error {
+	return r.db(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "house_gid"}, {Name: "group_id"}, {Name: "period_start"}, {Name: "period_end"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"group_name", "admin_user_id", "games", "player_games", "score_net", "score_flow",
+			"fee_total", "fee_share_net", "commission_rate", "commission_amount", "created_by", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: model.TableNameGameGroupSettlement, Name: "status"}, Value: model.SettlementStatusDraft},
+		}},
+	}).Create(m).Error
+}
+
+func (r *groupSettlementRepo) GetByID(ctx context.Context, id int32) (*model.GameGroupSettlement, error) {
+	var out model.GameGroupSettlement
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *groupSettlementRepo) GetByPeriod(ctx context.Context, houseGID, groupID int32, start, end time.Time) (*model.GameGroupSettlement, error) {
+	var out model.GameGroupSettlement
+	err := r.db(ctx).
+		Where("house_gid = ? AND group_id = ? AND period_start = ? AND period_end = ?", houseGID, groupID, start, end).
+		First(&out).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *groupSettlementRepo) List(ctx context.Context, f SettlementFilter, page, size int32) ([]*model.GameGroupSettlement, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameGroupSettlement{}).Where("house_gid = ?", f.HouseGID)
+	if f.GroupID != nil {
+		db = db.Where("group_id = ?", *f.GroupID)
+	}
+	if f.AdminUserID != nil {
+		db = db.Where("admin_user_id = ?", *f.AdminUserID)
+	}
+	if f.Status != nil {
+		db = db.Where("status = ?", *f.Status)
+	}
+	if f.Start != nil {
+		db = db.Where("period_start >= ?", *f.Start)
+	}
+	if f.End != nil {
+		db = db.Where("period_end <= ?", *f.End)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameGroupSettlement
+	err := db.Order("period_start DESC, group_id ASC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *groupSettlementRepo) UpdateStatus(ctx context.Context, id int32, from, to int32, opUser int32, at time.Time) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	switch to {
+	case model.SettlementStatusFrozen:
+		updates["frozen_at"] = at
+		updates["frozen_by"] = opUser
+	case model.SettlementStatusPaid:
+		updates["paid_at"] = at
+		updates["paid_by"] = opUser
+	}
+	res := r.db(ctx).Model(&model.GameGroupSettlement{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
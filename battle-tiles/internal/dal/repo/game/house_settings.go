@@ -14,6 +14,12 @@ type HouseSettingsRepo interface {
 	Upsert(ctx context.Context, in *model.GameHouseSettings) error
 	Get(ctx context.Context, houseGID int32) (*model.GameHouseSettings, error)
 	UpdateShareFee(ctx context.Context, houseGID int32, enable bool) error
+	// UpsertApplicationExpireHours 设置申请过期小时数（无设置行时创建）
+	UpsertApplicationExpireHours(ctx context.Context, houseGID, hours, opUser int32) error
+	// UpsertOnboardingDefaults 设置新成员默认额度与入店赠送（无设置行时创建）
+	UpsertOnboardingDefaults(ctx context.Context, houseGID, limitMin, welcomeCredit, opUser int32) error
+	// UpsertDiamondAlertThreshold 设置钻石余额告警线（无设置行时创建）
+	UpsertDiamondAlertThreshold(ctx context.Context, houseGID int32, threshold int64, opUser int32) error
 }
 
 type houseSettingsRepo struct {
@@ -50,3 +56,30 @@ func (r *houseSettingsRepo) UpdateShareFee(ctx context.Context, houseGID int32,
 		Where("house_gid = ?", houseGID).
 		Update("share_fee", enable).Error
 }
+
+func (r *houseSettingsRepo) UpsertApplicationExpireHours(ctx context.Context, houseGID, hours, opUser int32) error {
+	return r.db(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "house_gid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"application_expire_hours", "updated_at", "updated_by"}),
+		},
+	).Create(&model.GameHouseSettings{HouseGID: houseGID, ApplicationExpireHours: hours, UpdatedBy: opUser}).Error
+}
+
+func (r *houseSettingsRepo) UpsertOnboardingDefaults(ctx context.Context, houseGID, limitMin, welcomeCredit, opUser int32) error {
+	return r.db(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "house_gid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"onboard_limit_min", "onboard_welcome_credit", "updated_at", "updated_by"}),
+		},
+	).Create(&model.GameHouseSettings{HouseGID: houseGID, OnboardLimitMin: limitMin, OnboardWelcomeCredit: welcomeCredit, UpdatedBy: opUser}).Error
+}
+
+func (r *houseSettingsRepo) UpsertDiamondAlertThreshold(ctx context.Context, houseGID int32, threshold int64, opUser int32) error {
+	return r.db(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "house_gid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"diamond_alert_threshold", "updated_at", "updated_by"}),
+		},
+	).Create(&model.GameHouseSettings{HouseGID: houseGID, DiamondAlertThreshold: threshold, UpdatedBy: opUser}).Error
+}
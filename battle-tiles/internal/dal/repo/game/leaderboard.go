@@ -0,0 +1,81 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type LeaderboardRepo interface {
+	// MaxRecordID 满足条件的最大战绩ID（用作增量水位）
+	MaxRecordID(ctx context.Context, f LeaderboardFilter) (int32, error)
+	// AggregatePlayers 按玩家聚合 (afterID, uptoID] 区间内的战绩
+	AggregatePlayers(ctx context.Context, f LeaderboardFilter, afterID, uptoID int32) ([]PlayerBattleAgg, error)
+}
+
+// LeaderboardFilter 排行榜过滤条件（时间范围 [Start, End)）
+type LeaderboardFilter struct {
+	HouseGID int32
+	GroupID  *int32
+	KindID   *int32
+	Start    time.Time
+	End      time.Time
+}
+
+// PlayerBattleAgg 玩家维度战绩聚合
+type PlayerBattleAgg struct {
+	PlayerGameID   int32  `gorm:"column:player_game_id"`
+	PlayerGameName string `gorm:"column:player_game_name"`
+	Games          int64  `gorm:"column:games"`
+	Wins           int64  `gorm:"column:wins"`
+	Score          int64  `gorm:"column:score"`
+	Fee            int64  `gorm:"column:fee"`
+}
+
+type leaderboardRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewLeaderboardRepo(data *infra.Data, logger log.Logger) LeaderboardRepo {
+	return &leaderboardRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/leaderboard"))}
+}
+
+func (r *leaderboardRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *leaderboardRepo) scope(ctx context.Context, f LeaderboardFilter) *gorm.DB {
+	db := r.db(ctx).Model(&model.GameBattleRecord{}).
+		Where("house_gid = ? AND battle_at >= ? AND battle_at < ? AND player_game_id IS NOT NULL", f.HouseGID, f.Start, f.End)
+	if f.GroupID != nil {
+		db = db.Where("group_id = ?", *f.GroupID)
+	}
+	if f.KindID != nil {
+		db = db.Where("kind_id = ?", *f.KindID)
+	}
+	return db
+}
+
+func (r *leaderboardRepo) MaxRecordID(ctx context.Context, f LeaderboardFilter) (int32, error) {
+	var id int32
+	err := r.scope(ctx, f).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (r *leaderboardRepo) AggregatePlayers(ctx context.Context, f LeaderboardFilter, afterID, uptoID int32) ([]PlayerBattleAgg, error) {
+	var rows []PlayerBattleAgg
+	err := r.scope(ctx, f).
+		Where("id > ? AND id <= ?", afterID, uptoID).
+		Select(`player_game_id,
+			MAX(player_game_name)                       AS player_game_name,
+			COUNT(*)                                    AS games,
+			SUM(CASE WHEN score > 0 THEN 1 ELSE 0 END)  AS wins,
+			COALESCE(SUM(score), 0)                     AS score,
+			COALESCE(SUM(fee), 0)                       AS fee`).
+		Group("player_game_id").
+		Scan(&rows).Error
+	return rows, err
+}
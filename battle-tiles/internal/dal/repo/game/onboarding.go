@@ -0,0 +1,87 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OnboardingRepo interface {
+	// Create 写入新流程；同一申请（application_id > 0）已有流程时不写入并返回 false
+	Create(ctx context.Context, m *model.GameMemberOnboarding) (bool, error)
+	Save(ctx context.Context, m *model.GameMemberOnboarding) error
+	Get(ctx context.Context, id int32) (*model.GameMemberOnboarding, error)
+	// GetByApplication 同一申请只建一条流程
+	GetByApplication(ctx context.Context, houseGID int32, source string, applicationID int32) (*model.GameMemberOnboarding, error)
+	// List 分页查询（status 为空不过滤）
+	List(ctx context.Context, houseGID int32, status string, page, size int32) ([]*model.GameMemberOnboarding, int64, error)
+}
+
+type onboardingRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewOnboardingRepo(data *infra.Data, logger log.Logger) OnboardingRepo {
+	return &onboardingRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/onboarding"))}
+}
+
+func (r *onboardingRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *onboardingRepo) Create(ctx context.Context, m *model.GameMemberOnboarding) (bool, error) {
+	res := r.db(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "house_gid"}, {Name: "source"}, {Name: "application_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "application_id > 0"}}},
+		DoNothing:   true,
+	}).Create(m)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *onboardingRepo) Save(ctx context.Context, m *model.GameMemberOnboarding) error {
+	return r.db(ctx).Save(m).Error
+}
+
+func (r *onboardingRepo) Get(ctx context.Context, id int32) (*model.GameMemberOnboarding, error) {
+	var out model.GameMemberOnboarding
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *onboardingRepo) GetByApplication(ctx context.Context, houseGID int32, source string, applicationID int32) (*model.GameMemberOnboarding, error) {
+	var out model.GameMemberOnboarding
+	if err := r.db(ctx).
+		Where("house_gid = ? AND source = ? AND application_id = ?", houseGID, source, applicationID).
+		First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *onboardingRepo) List(ctx context.Context, houseGID int32, status string, page, size int32) ([]*model.GameMemberOnboarding, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameMemberOnboarding{}).Where("house_gid = ?", houseGID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameMemberOnboarding
+	err := db.Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
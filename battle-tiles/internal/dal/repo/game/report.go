@@ -0,0 +1,177 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type ReportRepo interface {
+	// CreateDefinition 新建报表定义
+	CreateDefinition(ctx context.Context, m *model.GameReportDefinition) error
+	// UpdateDefinition 更新报表定义（整行保存）
+	UpdateDefinition(ctx context.Context, m *model.GameReportDefinition) error
+	// DeleteDefinition 删除报表定义（限定店铺）
+	DeleteDefinition(ctx context.Context, houseGID, id int32) error
+	// GetDefinition 获取报表定义
+	GetDefinition(ctx context.Context, id int32) (*model.GameReportDefinition, error)
+	// ListDefinitions 店铺下的报表定义
+	ListDefinitions(ctx context.Context, houseGID int32) ([]*model.GameReportDefinition, error)
+	// ListDue 到期待执行的报表定义（已启用且 next_run_at 为空或不晚于 now）
+	ListDue(ctx context.Context, now time.Time) ([]*model.GameReportDefinition, error)
+	// ClaimDue 认领到期的报表定义：写入 last_run_at 并推进 next_run_at，返回是否认领成功（多实例下只有一个成功）
+	ClaimDue(ctx context.Context, id int32, now, nextRunAt time.Time) (bool, error)
+	// CreateRun 写入执行记录
+	CreateRun(ctx context.Context, m *model.GameReportRun) error
+	// FinishRun 回写执行结果
+	FinishRun(ctx context.Context, m *model.GameReportRun) error
+	// ListRuns 分页查询执行记录
+	ListRuns(ctx context.Context, houseGID int32, definitionID *int32, page, size int32) ([]*model.GameReportRun, int64, error)
+	// ListSessionOutages 时间窗口内异常/断开的中控会话及同步失败次数
+	ListSessionOutages(ctx context.Context, houseGID int32, start, end time.Time) ([]SessionOutageRow, error)
+}
+
+// SessionOutageRow 会话异常明细
+type SessionOutageRow struct {
+	SessionID         int32      `gorm:"column:session_id" json:"session_id"`
+	GameCtrlAccountID int32      `gorm:"column:game_ctrl_account_id" json:"game_ctrl_account_id"`
+	State             string     `gorm:"column:state" json:"state"`
+	ErrorMsg          string     `gorm:"column:error_msg" json:"error_msg"`
+	EndAt             *time.Time `gorm:"column:end_at" json:"end_at"`
+	FailedSyncs       int64      `gorm:"column:failed_syncs" json:"failed_syncs"`
+	SyncError         string     `gorm:"column:sync_error" json:"sync_error"`
+}
+
+type reportRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewReportRepo(data *infra.Data, logger log.Logger) ReportRepo {
+	return &reportRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/report"))}
+}
+
+func (r *reportRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *reportRepo) CreateDefinition(ctx context.Context, m *model.GameReportDefinition) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *reportRepo) UpdateDefinition(ctx context.Context, m *model.GameReportDefinition) error {
+	return r.db(ctx).Save(m).Error
+}
+
+func (r *reportRepo) DeleteDefinition(ctx context.Context, houseGID, id int32) error {
+	res := r.db(ctx).Where("id = ? AND house_gid = ?", id, houseGID).Delete(&model.GameReportDefinition{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *reportRepo) GetDefinition(ctx context.Context, id int32) (*model.GameReportDefinition, error) {
+	var out model.GameReportDefinition
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *reportRepo) ListDefinitions(ctx context.Context, houseGID int32) ([]*model.GameReportDefinition, error) {
+	var list []*model.GameReportDefinition
+	err := r.db(ctx).Where("house_gid = ?", houseGID).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *reportRepo) ListDue(ctx context.Context, now time.Time) ([]*model.GameReportDefinition, error) {
+	var list []*model.GameReportDefinition
+	err := r.db(ctx).
+		Where("enabled = ? AND (next_run_at IS NULL OR next_run_at <= ?)", true, now).
+		Order("id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *reportRepo) ClaimDue(ctx context.Context, id int32, now, nextRunAt time.Time) (bool, error) {
+	res := r.db(ctx).Model(&model.GameReportDefinition{}).
+		Where("id = ? AND enabled = ? AND (next_run_at IS NULL OR next_run_at <= ?)", id, true, now).
+		Updates(map[string]interface{}{"last_run_at": now, "next_run_at": nextRunAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *reportRepo) CreateRun(ctx context.Context, m *model.GameReportRun) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *reportRepo) FinishRun(ctx context.Context, m *model.GameReportRun) error {
+	return r.db(ctx).Model(&model.GameReportRun{}).Where("id = ?", m.Id).Updates(map[string]interface{}{
+		"status":        m.Status,
+		"rows":          m.Rows,
+		"delivery_ref":  m.DeliveryRef,
+		"error_message": m.ErrorMessage,
+		"finished_at":   m.FinishedAt,
+	}).Error
+}
+
+func (r *reportRepo) ListRuns(ctx context.Context, houseGID int32, definitionID *int32, page, size int32) ([]*model.GameReportRun, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameReportRun{}).Where("house_gid = ?", houseGID)
+	if definitionID != nil {
+		db = db.Where("definition_id = ?", *definitionID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameReportRun
+	err := db.Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *reportRepo) ListSessionOutages(ctx context.Context, houseGID int32, start, end time.Time) ([]SessionOutageRow, error) {
+	raw := `
+SELECT
+	s.id                                AS session_id,
+	s.game_ctrl_account_id              AS game_ctrl_account_id,
+	s.state                             AS state,
+	s.error_msg                         AS error_msg,
+	s.end_at                            AS end_at,
+	COUNT(l.id)                         AS failed_syncs,
+	COALESCE(MAX(l.error_message), '')  AS sync_error
+FROM game_session s
+LEFT JOIN game_sync_log l
+	ON l.session_id = s.id AND l.status = 'failed' AND l.started_at >= ? AND l.started_at < ?
+WHERE s.house_gid = ?
+	AND (
+		(s.state = 'error' AND s.updated_at >= ? AND s.updated_at < ?)
+		OR (s.end_at >= ? AND s.end_at < ?)
+		OR l.id IS NOT NULL
+	)
+GROUP BY s.id, s.game_ctrl_account_id, s.state, s.error_msg, s.end_at
+ORDER BY failed_syncs DESC, s.id ASC;
+`
+	var rows []SessionOutageRow
+	if err := r.db(ctx).Raw(raw, start, end, houseGID, start, end, start, end).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -27,6 +27,10 @@ type ShopGroupRepo interface {
 	Delete(ctx context.Context, id int32) error
 	// Deactivate 停用圈子
 	Deactivate(ctx context.Context, id int32) error
+	// UpdateCommissionRate 设置圈主抽成比例（万分比）
+	UpdateCommissionRate(ctx context.Context, id int32, rate int32) error
+	// SetGameGroupID 关联/解除游戏端群组（nil 解除）
+	SetGameGroupID(ctx context.Context, id int32, gameGroupID *int32) error
 }
 
 type shopGroupRepo struct {
@@ -110,3 +114,17 @@ func (r *shopGroupRepo) Deactivate(ctx context.Context, id int32) error {
 		Where("id = ?", id).
 		Update("is_active", false).Error
 }
+
+func (r *shopGroupRepo) UpdateCommissionRate(ctx context.Context, id int32, rate int32) error {
+	return r.db(ctx).
+		Model(&model.GameShopGroup{}).
+		Where("id = ?", id).
+		Update("commission_rate", rate).Error
+}
+
+func (r *shopGroupRepo) SetGameGroupID(ctx context.Context, id int32, gameGroupID *int32) error {
+	return r.db(ctx).
+		Model(&model.GameShopGroup{}).
+		Where("id = ?", id).
+		Update("game_group_id", gameGroupID).Error
+}
@@ -27,6 +27,12 @@ type UserApplicationRepo interface {
 	AddApprovedJoin(ctx context.Context, houseGID int32, adminUID int32, applicant int32) error
 	// GetUserApprovedJoin 获取用户在指定店铺下的已批准入圈记录
 	GetUserApprovedJoin(ctx context.Context, houseGID int32, applicant int32) (*model.UserApplication, error)
+	// DecidePending 仅当仍为待审（status=0）时更新状态，返回是否更新成功（防止重复处理）
+	DecidePending(ctx context.Context, id int32, status int32) (bool, error)
+	// ListPendingBefore 店铺内早于指定时间创建的待审申请
+	ListPendingBefore(ctx context.Context, houseGID int32, before time.Time) ([]*model.UserApplication, error)
+	// ListPendingHouses 存在待审申请的店铺
+	ListPendingHouses(ctx context.Context) ([]int32, error)
 }
 
 type userApplicationRepo struct {
@@ -168,3 +174,22 @@ func (r *userApplicationRepo) GetUserApprovedJoin(ctx context.Context, houseGID
 	}
 	return &app, nil
 }
+
+func (r *userApplicationRepo) DecidePending(ctx context.Context, id int32, status int32) (bool, error) {
+	tx := r.db(ctx).Model(&model.UserApplication{}).Where("id = ? AND status = 0", id).Update("status", status)
+	return tx.RowsAffected > 0, tx.Error
+}
+
+func (r *userApplicationRepo) ListPendingBefore(ctx context.Context, houseGID int32, before time.Time) ([]*model.UserApplication, error) {
+	var list []*model.UserApplication
+	err := r.db(ctx).Where("house_gid = ? AND status = 0 AND created_at < ?", houseGID, before).
+		Order("id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *userApplicationRepo) ListPendingHouses(ctx context.Context) ([]int32, error) {
+	var out []int32
+	err := r.db(ctx).Model(&model.UserApplication{}).Where("status = 0").Distinct().Pluck("house_gid", &out).Error
+	return out, err
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"battle-tiles/internal/infra"
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type WebhookRepo interface {
+	// CreateEndpoint 新建回调地址
+	CreateEndpoint(ctx context.Context, m *model.GameWebhookEndpoint) error
+	// UpdateEndpoint 更新回调地址（整行保存）
+	UpdateEndpoint(ctx context.Context, m *model.GameWebhookEndpoint) error
+	// DeleteEndpoint 删除回调地址（限定店铺）
+	DeleteEndpoint(ctx context.Context, houseGID, id int32) error
+	// GetEndpoint 获取回调地址
+	GetEndpoint(ctx context.Context, id int32) (*model.GameWebhookEndpoint, error)
+	// ListEndpoints 店铺下的回调地址
+	ListEndpoints(ctx context.Context, houseGID int32) ([]*model.GameWebhookEndpoint, error)
+	// ListEnabledEndpoints 店铺下已启用的回调地址
+	ListEnabledEndpoints(ctx context.Context, houseGID int32) ([]*model.GameWebhookEndpoint, error)
+	// CreateDelivery 写入投递记录
+	CreateDelivery(ctx context.Context, m *model.GameWebhookDelivery) error
+	// GetDelivery 获取投递记录
+	GetDelivery(ctx context.Context, id int32) (*model.GameWebhookDelivery, error)
+	// SaveAttempt 回写一次投递结果（状态/次数/响应码/错误）
+	SaveAttempt(ctx context.Context, m *model.GameWebhookDelivery) error
+	// ResetDelivery 手动重投前重置状态与次数
+	ResetDelivery(ctx context.Context, id int32) error
+	// ListDeliveries 分页查询投递记录
+	ListDeliveries(ctx context.Context, houseGID int32, endpointID *int32, status string, page, size int32) ([]*model.GameWebhookDelivery, int64, error)
+}
+
+type webhookRepo struct {
+	data *infra.Data
+	log  *log.Helper
+}
+
+func NewWebhookRepo(data *infra.Data, logger log.Logger) WebhookRepo {
+	return &webhookRepo{data: data, log: log.NewHelper(log.With(logger, "module", "repo/webhook"))}
+}
+
+func (r *webhookRepo) db(ctx context.Context) *gorm.DB { return r.data.GetDBWithContext(ctx) }
+
+func (r *webhookRepo) CreateEndpoint(ctx context.Context, m *model.GameWebhookEndpoint) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *webhookRepo) UpdateEndpoint(ctx context.Context, m *model.GameWebhookEndpoint) error {
+	return r.db(ctx).Save(m).Error
+}
+
+func (r *webhookRepo) DeleteEndpoint(ctx context.Context, houseGID, id int32) error {
+	res := r.db(ctx).Where("id = ? AND house_gid = ?", id, houseGID).Delete(&model.GameWebhookEndpoint{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) GetEndpoint(ctx context.Context, id int32) (*model.GameWebhookEndpoint, error) {
+	var out model.GameWebhookEndpoint
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *webhookRepo) ListEndpoints(ctx context.Context, houseGID int32) ([]*model.GameWebhookEndpoint, error) {
+	var list []*model.GameWebhookEndpoint
+	err := r.db(ctx).Where("house_gid = ?", houseGID).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *webhookRepo) ListEnabledEndpoints(ctx context.Context, houseGID int32) ([]*model.GameWebhookEndpoint, error) {
+	var list []*model.GameWebhookEndpoint
+	err := r.db(ctx).Where("house_gid = ? AND enabled = ?", houseGID, true).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *webhookRepo) CreateDelivery(ctx context.Context, m *model.GameWebhookDelivery) error {
+	return r.db(ctx).Create(m).Error
+}
+
+func (r *webhookRepo) GetDelivery(ctx context.Context, id int32) (*model.GameWebhookDelivery, error) {
+	var out model.GameWebhookDelivery
+	if err := r.db(ctx).Where("id = ?", id).First(&out).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *webhookRepo) SaveAttempt(ctx context.Context, m *model.GameWebhookDelivery) error {
+	return r.db(ctx).Model(&model.GameWebhookDelivery{}).Where("id = ?", m.Id).Updates(map[string]interface{}{
+		"status":           m.Status,
+		"attempts":         m.Attempts,
+		"last_status_code": m.LastStatusCode,
+		"last_error":       m.LastError,
+		"last_attempt_at":  m.LastAttemptAt,
+		"delivered_at":     m.DeliveredAt,
+	}).Error
+}
+
+func (r *webhookRepo) ResetDelivery(ctx context.Context, id int32) error {
+	return r.db(ctx).Model(&model.GameWebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":   model.WebhookDeliveryPending,
+		"attempts": 0,
+	}).Error
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, houseGID int32, endpointID *int32, status string, page, size int32) ([]*model.GameWebhookDelivery, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	db := r.db(ctx).Model(&model.GameWebhookDelivery{}).Where("house_gid = ?", houseGID)
+	if endpointID != nil {
+		db = db.Where("endpoint_id = ?", *endpointID)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.GameWebhookDelivery
+	err := db.Order("id DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&list).Error
+	return list, total, err
+}
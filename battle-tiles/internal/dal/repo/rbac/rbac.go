@@ -133,6 +133,65 @@ func (s *Store) queryUserPermCodesDB(ctx context.Context, userID int32) ([]strin
 	return out, nil
 }
 
+// GetUserHousePermCodes 用户在指定店铺（及圈）内通过作用域角色获得的权限码。
+// groupID <= 0 时只计整店授权；否则整店授权与该圈授权都生效。结果同样走缓存。
+func (s *Store) GetUserHousePermCodes(ctx context.Context, userID, houseGID, groupID int32) (map[string]struct{}, error) {
+	if groupID < 0 {
+		groupID = 0
+	}
+	key := fmt.Sprintf("%s:h%d:g%d", s.cacheKey(ctx, userID), houseGID, groupID)
+	if s.data.RDB != nil {
+		if bs, err := s.data.RDB.Get(ctx, key).Bytes(); err == nil && len(bs) > 0 {
+			var arr []string
+			if json.Unmarshal(bs, &arr) == nil {
+				return toSet(arr), nil
+			}
+		}
+	}
+
+	db := s.data.GetDBWithContext(ctx)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	var codes []string
+	err := db.
+		Table("basic_user_scope_role AS sr").
+		Select("DISTINCT LOWER(p.code) AS code").
+		Joins("JOIN basic_role_permission_rel AS rpr ON rpr.role_id = sr.role_id").
+		Joins("JOIN basic_permission AS p ON p.id = rpr.permission_id AND p.is_deleted = false").
+		Where("sr.user_id = ? AND sr.house_gid = ? AND (sr.group_id = 0 OR sr.group_id = ?)", userID, houseGID, groupID).
+		Scan(&codes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if s.data.RDB != nil {
+		_ = s.data.RDB.Set(ctx, key, mustJSON(codes), s.ttl).Err()
+	}
+	return toSet(codes), nil
+}
+
+// InvalidateUser 清除用户的权限缓存（全局 + 各店铺作用域），授权变更后调用
+func (s *Store) InvalidateUser(ctx context.Context, userID int32) {
+	if s.data.RDB == nil {
+		return
+	}
+	base := s.cacheKey(ctx, userID)
+	keys := []string{base}
+	// RDB 挂了平台前缀 hook：SCAN 返回的是带前缀的真实 key，DEL 会再加一次，这里先去掉
+	prefix := pdb.GetDBKeyFromCtx(ctx) + "_"
+	iter := s.data.RDB.Scan(ctx, 0, base+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), prefix))
+	}
+	if err := iter.Err(); err != nil {
+		s.logger.Warnf("scan rbac cache for user %d failed: %v", userID, err)
+	}
+	if err := s.data.RDB.Del(ctx, keys...).Err(); err != nil {
+		s.logger.Warnf("invalidate rbac cache for user %d failed: %v", userID, err)
+	}
+}
+
 // 小工具
 func toSet(arr []string) map[string]struct{} {
 	s := make(map[string]struct{}, len(arr))
@@ -16,10 +16,15 @@ var ProviderSet = wire.NewSet(
 	basic.NewBasicUseRepo,
 	basic.NewBasicLoginRepo,
 	basic.NewAuthRepo,
+	basic.NewLoginSessionRepo,
+	basic.NewLoginSecurityRepo,
+	basic.NewAPIKeyRepo,
 	basic.NewBaseMenuRepo,
 	basic.NewBaseRoleMenuRelRepo,
 	basic.NewBaseRoleMenuBtnRelRepo,
 	basic.NewPermissionRepo,
+	basic.NewUserScopeRoleRepo,
+	basic.NewAuditLogRepo,
 
 	cloud.NewBasePlatformRepo,
 
@@ -41,5 +46,15 @@ var ProviderSet = wire.NewSet(
 	game.NewShopGroupMemberRepo,
 	game.NewGameMemberRepo,
 	game.NewShopApplicationLogRepo,
+	game.NewGroupSettlementRepo,
+	game.NewLeaderboardRepo,
+	game.NewReportRepo,
+	game.NewWebhookRepo,
+	game.NewApplicationRuleRepo,
+	game.NewOnboardingRepo,
+	game.NewBlocklistRepo,
+	game.NewBattleConfigRepo,
+	game.NewDiamondRepo,
+	game.NewBattleWatermarkRepo,
 	rbac.NewStore,
 )
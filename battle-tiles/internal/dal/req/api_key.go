@@ -0,0 +1,27 @@
+package req
+
+// CreateServiceAccountRequest 新建服务账号（用户名自动加 svc_ 前缀）
+type CreateServiceAccountRequest struct {
+	Username string `json:"username" binding:"required,max=40"`
+	NickName string `json:"nick_name"`
+}
+
+// IssueAPIKeyRequest 为服务账号签发 API Key
+type IssueAPIKeyRequest struct {
+	UserID    int32    `json:"user_id" binding:"required,gt=0"` // 服务账号ID
+	Name      string   `json:"name" binding:"required,max=64"`  // 用途说明
+	Perms     []string `json:"perms" binding:"required,min=1"`  // 权限码
+	HouseGIDs []int32  `json:"house_gids"`                      // 限定店铺，空为不限
+	// RFC3339，空为永不过期
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ListAPIKeyRequest 查询 API Key
+type ListAPIKeyRequest struct {
+	UserID int32 `json:"user_id"` // 服务账号ID，0 为全部
+}
+
+// RevokeAPIKeyRequest 吊销 API Key
+type RevokeAPIKeyRequest struct {
+	ID int32 `json:"id" binding:"required,gt=0"`
+}
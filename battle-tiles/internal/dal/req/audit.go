@@ -0,0 +1,19 @@
+package req
+
+// ListAuditLogRequest 审计日志查询
+// @example {"house_gid":20001, "action":"fund:", "start_at":"2026-10-01T00:00:00+08:00", "page":1, "page_size":20}
+type ListAuditLogRequest struct {
+	ActorID  *int32 `json:"actor_id"`
+	HouseGID *int32 `json:"house_gid"`
+	// 操作码前缀，如 fund: / shop:admin:
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	ClientIP   string `json:"client_ip"`
+	Success    *bool  `json:"success"`
+	// RFC3339
+	StartAt  string `json:"start_at"`
+	EndAt    string `json:"end_at"`
+	Page     int32  `json:"page"`
+	PageSize int32  `json:"page_size"`
+}
@@ -26,6 +26,7 @@ type ListBasicUserReq struct {
 type UsernamePasswordLoginRequest struct {
 	Username string `json:"username" binding:"required" example:"testuser"` // 用户名
 	Password string `json:"password" binding:"required" example:"123456"`   // 密码
+	OTPCode  string `json:"otp_code" example:"123456"`                      // 动态验证码（开启二次验证的账号必填）
 }
 type PhoneCodeLoginRequest struct {
 	Phone string `json:"phone" binding:"required" example:"13800001111"` // 手机号
@@ -48,3 +49,11 @@ type RegisterRequest struct {
 	GameAccount     string `json:"game_account"`      // 游戏账号或手机号
 	GamePassword    string `json:"game_password"`     // 游戏密码（MD5）
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // 刷新令牌
+}
+
+type RevokeSessionRequest struct {
+	SID string `json:"sid" binding:"required"` // 会话ID
+}
@@ -0,0 +1,25 @@
+package req
+
+// ListBattleConfigsRequest 店铺玩法列表
+type ListBattleConfigsRequest struct {
+	HouseGID       int32 `json:"house_gid" binding:"required,gt=0"`
+	IncludeRemoved bool  `json:"include_removed"` // 包含游戏端已删除的玩法（历史战绩关联用）
+}
+
+// SaveBattleConfigRequest 添加/修改玩法（config_id 为空表示添加）
+// @example {"house_gid":20001, "kind_id":302, "base_score":1, "play_count":8, "player_count":4, "name":"红中8局"}
+type SaveBattleConfigRequest struct {
+	HouseGID    int32  `json:"house_gid" binding:"required,gt=0"`
+	ConfigID    int32  `json:"config_id"`
+	KindID      int32  `json:"kind_id" binding:"required,gt=0"`
+	BaseScore   int32  `json:"base_score" binding:"gte=0"`
+	PlayCount   int32  `json:"play_count" binding:"required,gt=0"`   // 局数
+	PlayerCount int32  `json:"player_count" binding:"required,gt=0"` // 人数
+	Name        string `json:"name" binding:"max=31"`
+}
+
+// DeleteBattleConfigRequest 删除玩法
+type DeleteBattleConfigRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	ConfigID int32 `json:"config_id" binding:"required,gt=0"`
+}
@@ -0,0 +1,14 @@
+package req
+
+// BattleBackfillRequest 回填店铺战绩；日期为 "2006-01-02"，含首尾两天，只支持本周内
+// @example {"house_gid":20001, "start_date":"2026-10-19", "end_date":"2026-10-20"}
+type BattleBackfillRequest struct {
+	HouseGID  int    `json:"house_gid" binding:"required,gt=0"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+// BattleWatermarkRequest 查看店铺战绩同步高水位
+type BattleWatermarkRequest struct {
+	HouseGID int `json:"house_gid" binding:"required,gt=0"`
+}
@@ -0,0 +1,38 @@
+package req
+
+// AddBlocklistRequest 平台加入黑名单；kind: game_id / user / device / ip
+// @example {"kind":"game_id", "value":"123456", "reason":"恶意拖欠", "house_gid":20001, "expire_at":"2026-12-31T00:00:00+08:00"}
+type AddBlocklistRequest struct {
+	Kind     string `json:"kind" binding:"required,oneof=game_id user device ip"`
+	Value    string `json:"value" binding:"required,max=128"`
+	Reason   string `json:"reason" binding:"max=255"`
+	HouseGID int32  `json:"house_gid"` // 上报店铺，0 为平台直接添加
+	// RFC3339，空为永久
+	ExpireAt string `json:"expire_at"`
+}
+
+// ReportBlocklistRequest 店铺上报黑名单（上报店铺即 house_gid）
+// @example {"house_gid":20001, "kind":"game_id", "value":"123456", "reason":"恶意拖欠"}
+type ReportBlocklistRequest struct {
+	HouseGID int32  `json:"house_gid" binding:"required,gt=0"`
+	Kind     string `json:"kind" binding:"required,oneof=game_id user device ip"`
+	Value    string `json:"value" binding:"required,max=128"`
+	Reason   string `json:"reason" binding:"required,max=255"`
+	ExpireAt string `json:"expire_at"`
+}
+
+// ListBlocklistRequest 黑名单列表；house_gid 为上报店铺，0 不过滤
+type ListBlocklistRequest struct {
+	HouseGID       int32  `json:"house_gid"`
+	Kind           string `json:"kind" binding:"omitempty,oneof=game_id user device ip"`
+	Value          string `json:"value"`
+	IncludeExpired bool   `json:"include_expired"`
+	Page           int32  `json:"page"`
+	PageSize       int32  `json:"page_size"`
+}
+
+// BlocklistIDRequest 按ID删除；店铺侧只能删除本店上报的记录
+type BlocklistIDRequest struct {
+	HouseGID int32 `json:"house_gid"`
+	ID       int32 `json:"id" binding:"required,gt=0"`
+}
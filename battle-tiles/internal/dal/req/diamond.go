@@ -0,0 +1,23 @@
+package req
+
+// DiamondStatusRequest 店铺钻石余额与消耗推算
+type DiamondStatusRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	Refresh  bool  `json:"refresh"` // 同时通过会话重新查询一次（结果异步落库）
+}
+
+// DiamondSeriesRequest 钻石余额时间序列；时间为秒级时间戳，默认最近 24 小时
+// @example {"house_gid":20001, "start_time":1761840000, "end_time":1761926400}
+type DiamondSeriesRequest struct {
+	HouseGID      int32  `json:"house_gid" binding:"required,gt=0"`
+	CtrlAccountID int32  `json:"ctrl_account_id"` // 为空不限中控账号
+	StartTime     *int64 `json:"start_time"`
+	EndTime       *int64 `json:"end_time"`
+}
+
+// SetDiamondThresholdRequest 设置钻石告警线，0 关闭告警
+// @example {"house_gid":20001, "threshold":500}
+type SetDiamondThresholdRequest struct {
+	HouseGID  int32 `json:"house_gid" binding:"required,gt=0"`
+	Threshold int64 `json:"threshold" binding:"gte=0"`
+}
@@ -13,6 +13,64 @@ type RespondGameApplicationRequest struct {
 	MessageID int   `json:"message_id" binding:"required"` // 游戏消息ID
 }
 
+// ============ 申请处理 v2：自动规则 / 超时 / 批量 ============
+
+// BulkDecideApplicationsRequest 批量通过/拒绝；source=game 时 ids 为游戏消息ID，source=platform 时为平台申请ID
+// @example {"house_gid":20001, "source":"game", "ids":[101,102], "agree":true, "reason":"批量通过"}
+type BulkDecideApplicationsRequest struct {
+	HouseGID int32   `json:"house_gid" binding:"required,gt=0"`
+	Source   string  `json:"source" binding:"required,oneof=game platform"`
+	IDs      []int32 `json:"ids" binding:"required,min=1,max=200"`
+	Agree    bool    `json:"agree"`
+	Reason   string  `json:"reason" binding:"max=255"`
+}
+
+// SaveApplicationRuleRequest 新建/修改自动处理规则（id 为空表示新建）
+// condition: recommender_member / blocklist / any；action: approve / reject
+// @example {"house_gid":20001, "name":"黑名单", "priority":10, "condition":"blocklist", "action":"reject", "game_ids":[123456], "enabled":true}
+type SaveApplicationRuleRequest struct {
+	ID         int32    `json:"id"`
+	HouseGID   int32    `json:"house_gid" binding:"required,gt=0"`
+	Name       string   `json:"name" binding:"required,max=64"`
+	Priority   int32    `json:"priority"`
+	Condition  string   `json:"condition" binding:"required,oneof=recommender_member blocklist any"`
+	Action     string   `json:"action" binding:"required,oneof=approve reject"`
+	GameIDs    []int32  `json:"game_ids"`    // blocklist 名单
+	ApplyTypes []int32  `json:"apply_types"` // 可选：限定申请类型（1=管理员 2=入圈）
+	Sources    []string `json:"sources"`     // 可选：限定来源 game/platform
+	Enabled    bool     `json:"enabled"`
+}
+
+// ApplicationRuleIDRequest 按规则ID操作
+type ApplicationRuleIDRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	ID       int32 `json:"id" binding:"required,gt=0"`
+}
+
+// ListApplicationRulesRequest 店铺规则列表
+type ListApplicationRulesRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+}
+
+// SetApplicationExpiryRequest 设置待审申请过期小时数（0 不过期）
+// @example {"house_gid":20001, "hours":48}
+type SetApplicationExpiryRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	Hours    int32 `json:"hours" binding:"gte=0,lte=720"`
+}
+
+// ListApplicationDecisionsRequest 处理记录；decider: manual / rule / expiry / blocklist
+type ListApplicationDecisionsRequest struct {
+	HouseGID  int32  `json:"house_gid" binding:"required,gt=0"`
+	Source    string `json:"source" binding:"omitempty,oneof=game platform"`
+	Decider   string `json:"decider" binding:"omitempty,oneof=manual rule expiry blocklist"`
+	RuleID    *int32 `json:"rule_id"`
+	StartTime *int64 `json:"start_time"` // 秒级时间戳
+	EndTime   *int64 `json:"end_time"`
+	Page      int32  `json:"page"`
+	PageSize  int32  `json:"page_size"`
+}
+
 // ============ 旧的管理员申请功能（已废弃，保留用于兼容）============
 
 // ListApplicationsRequest 请求：按店铺号列出管理员申请
@@ -38,4 +96,5 @@ type ApplyJoinGroupRequest struct {
 	HouseGID    int32  `json:"house_gid" binding:"required"`
 	AdminUserID int32  `json:"admin_user_id" binding:"required"`
 	Note        string `json:"note"`
+	Recommender string `json:"recommender" binding:"max=64"` // 可选：推荐人游戏ID
 }
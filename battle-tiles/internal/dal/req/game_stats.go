@@ -30,3 +30,20 @@ type BattleDetailRequest struct {
 	// 查询周期：today|yesterday|thisweek
 	Period string `json:"period" binding:"required,oneof=today yesterday thisweek"`
 }
+
+// StatsSeriesRequest 时间序列统计入参（group_id / member_id 二选一，均不传则按店铺）
+// @example {"house_gid":20001, "bucket":"day", "start_time":1760284800, "end_time":1760889600}
+type StatsSeriesRequest struct {
+	// 店铺号（圈ID/HouseGID）
+	HouseGID int `json:"house_gid" binding:"required"`
+	// 圈ID（可选）
+	GroupID *int `json:"group_id,omitempty"`
+	// 成员ID（可选）
+	MemberID *int `json:"member_id,omitempty"`
+	// 分桶：hour|day|week
+	Bucket string `json:"bucket" binding:"required,oneof=hour day week"`
+	// 开始时间（Unix 秒，含）
+	StartTime int64 `json:"start_time" binding:"required"`
+	// 结束时间（Unix 秒，不含）
+	EndTime int64 `json:"end_time" binding:"required"`
+}
@@ -0,0 +1,39 @@
+package req
+
+// GenerateSettlementRequest 生成圈主结算单（不传周期默认上周一至本周一）
+// @example {"house_gid":20001, "start_at":"2026-10-12T00:00:00+08:00", "end_at":"2026-10-19T00:00:00+08:00"}
+type GenerateSettlementRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	// 周期开始（RFC3339，含）
+	StartAt string `json:"start_at"`
+	// 周期结束（RFC3339，不含）
+	EndAt string `json:"end_at"`
+}
+
+// ListSettlementRequest 结算单列表
+// @example {"house_gid":20001, "status":1, "page":1, "page_size":20}
+type ListSettlementRequest struct {
+	HouseGID    int32  `json:"house_gid" binding:"required,gt=0"`
+	GroupID     *int32 `json:"group_id"`
+	AdminUserID *int32 `json:"admin_user_id"`
+	// 0=草稿 1=已冻结 2=已打款
+	Status   *int32 `json:"status" binding:"omitempty,oneof=0 1 2"`
+	StartAt  string `json:"start_at"`
+	EndAt    string `json:"end_at"`
+	Page     int32  `json:"page"`
+	PageSize int32  `json:"page_size"`
+}
+
+// SettlementIDRequest 按结算单ID操作
+// @example {"id":1}
+type SettlementIDRequest struct {
+	ID int32 `json:"id" binding:"required,gt=0"`
+}
+
+// SetCommissionRateRequest 设置圈主抽成比例
+// @example {"group_id":1, "commission_rate":3000}
+type SetCommissionRateRequest struct {
+	GroupID int32 `json:"group_id" binding:"required,gt=0"`
+	// 万分比：3000 表示 30%
+	CommissionRate int32 `json:"commission_rate" binding:"gte=0,lte=10000"`
+}
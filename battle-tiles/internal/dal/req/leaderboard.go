@@ -0,0 +1,23 @@
+package req
+
+// LeaderboardRequest 排行榜查询
+// @example {"house_gid":20001, "metric":"win", "period":"thisweek", "kind_id":60, "limit":20}
+type LeaderboardRequest struct {
+	// 店铺号
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	// 圈ID（可选）
+	GroupID *int32 `json:"group_id"`
+	// 玩法ID（可选，见 consts.GameKind*）
+	KindID *int32 `json:"kind_id"`
+	// 排行维度：win=赢分 lose=输分 games=局数 fee=运费贡献 winrate=胜率
+	Metric string `json:"metric" binding:"required,oneof=win lose games fee winrate"`
+	// 时间窗口：today|yesterday|thisweek|custom
+	Period string `json:"period" binding:"required,oneof=today yesterday thisweek custom"`
+	// custom 时必填（Unix 秒，[start_time, end_time)）
+	StartTime *int64 `json:"start_time"`
+	EndTime   *int64 `json:"end_time"`
+	// 返回条数，默认 20，最大 100
+	Limit int `json:"limit" binding:"omitempty,gte=1,lte=100"`
+	// 最少局数（胜率榜默认 5）
+	MinGames int64 `json:"min_games" binding:"omitempty,gte=0"`
+}
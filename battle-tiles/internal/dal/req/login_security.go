@@ -0,0 +1,29 @@
+package req
+
+// ListLoginHistoryRequest 登录记录查询（管理员；本人查询时 user_id/username 被忽略）
+type ListLoginHistoryRequest struct {
+	UserID   *int32 `json:"user_id"`
+	Username string `json:"username"`
+	ClientIP string `json:"client_ip"`
+	Success  *bool  `json:"success"`
+	// RFC3339
+	StartAt  string `json:"start_at"`
+	EndAt    string `json:"end_at"`
+	Page     int32  `json:"page"`
+	PageSize int32  `json:"page_size"`
+}
+
+// TOTPCodeRequest 动态验证码
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// TOTPResetRequest 管理员重置用户二次验证
+type TOTPResetRequest struct {
+	UserID int32 `json:"user_id" binding:"required,gt=0"`
+}
+
+// LoginUnlockRequest 管理员解除登录锁定
+type LoginUnlockRequest struct {
+	Username string `json:"username" binding:"required"`
+}
@@ -0,0 +1,34 @@
+package req
+
+// StartOnboardingRequest 手动发起入店流程（审批通过的入圈申请会自动发起）
+// @example {"house_gid":20001, "game_id":123456, "game_name":"玩家A", "group_id":3, "user_id":0}
+type StartOnboardingRequest struct {
+	HouseGID    int32  `json:"house_gid" binding:"required,gt=0"`
+	GameID      int32  `json:"game_id" binding:"required,gt=0"`
+	GameName    string `json:"game_name" binding:"max=64"`
+	GroupID     *int32 `json:"group_id"`
+	UserID      int32  `json:"user_id"` // 可选：平台用户ID，填写后同时写入圈子成员关系
+	Recommender string `json:"recommender" binding:"max=64"`
+}
+
+// OnboardingIDRequest 按流程ID操作（重试/撤销）
+type OnboardingIDRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	ID       int32 `json:"id" binding:"required,gt=0"`
+}
+
+// ListOnboardingRequest 入店流程列表；status: running/failed/completed/compensated
+type ListOnboardingRequest struct {
+	HouseGID int32  `json:"house_gid" binding:"required,gt=0"`
+	Status   string `json:"status" binding:"omitempty,oneof=running failed completed compensated"`
+	Page     int32  `json:"page"`
+	PageSize int32  `json:"page_size"`
+}
+
+// SetOnboardingDefaultsRequest 新成员钱包默认下限与入店赠送（分）
+// @example {"house_gid":20001, "limit_min":0, "welcome_credit":500}
+type SetOnboardingDefaultsRequest struct {
+	HouseGID      int32 `json:"house_gid" binding:"required,gt=0"`
+	LimitMin      int32 `json:"limit_min"`
+	WelcomeCredit int32 `json:"welcome_credit" binding:"gte=0"`
+}
@@ -0,0 +1,11 @@
+package req
+
+// PlayerProfileRequest 玩家 360° 视图（game_id / user_id 二选一）
+// @example {"house_gid":20001, "game_id":123456}
+type PlayerProfileRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	// 游戏ID
+	GameID *int32 `json:"game_id"`
+	// 平台用户ID
+	UserID *int32 `json:"user_id"`
+}
@@ -0,0 +1,51 @@
+package req
+
+// ReportParamsRequest 报表参数
+type ReportParamsRequest struct {
+	// low_balance：余额不高于该值（分）
+	Threshold int32 `json:"threshold"`
+	// 明细行数上限（默认 50，最大 100）
+	Limit   int    `json:"limit"`
+	GroupID *int32 `json:"group_id"`
+}
+
+// SaveReportDefinitionRequest 新建/修改报表定义（id 为空表示新建）
+// @example {"house_gid":20001, "name":"每日经营概况", "report_type":"daily_stats", "period":"daily", "schedule":"0 8 * * *", "channel":"webhook", "channel_target":"https://example.com/hook", "enabled":true}
+type SaveReportDefinitionRequest struct {
+	ID       int32  `json:"id"`
+	HouseGID int32  `json:"house_gid" binding:"required,gt=0"`
+	Name     string `json:"name" binding:"required,max=64"`
+	// daily_stats | low_balance | top_losers | fee_summary | session_outage
+	ReportType string `json:"report_type" binding:"required,oneof=daily_stats low_balance top_losers fee_summary session_outage"`
+	// daily=昨日 weekly=上周
+	Period string `json:"period" binding:"omitempty,oneof=daily weekly"`
+	// 5 段 cron 表达式或 @daily/@weekly 等描述符
+	Schedule string              `json:"schedule" binding:"required,max=64"`
+	Params   ReportParamsRequest `json:"params"`
+	// file | webhook
+	Channel       string `json:"channel" binding:"required,oneof=file webhook"`
+	ChannelTarget string `json:"channel_target" binding:"max=512"`
+	Enabled       bool   `json:"enabled"`
+}
+
+// ReportDefinitionIDRequest 按报表定义ID操作
+// @example {"house_gid":20001, "id":1}
+type ReportDefinitionIDRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	ID       int32 `json:"id" binding:"required,gt=0"`
+}
+
+// ListReportRunsRequest 报表执行历史
+// @example {"house_gid":20001, "definition_id":1, "page":1, "page_size":20}
+type ListReportRunsRequest struct {
+	HouseGID     int32  `json:"house_gid" binding:"required,gt=0"`
+	DefinitionID *int32 `json:"definition_id"`
+	Page         int32  `json:"page"`
+	PageSize     int32  `json:"page_size"`
+}
+
+// ListReportDefinitionsRequest 店铺报表定义列表
+// @example {"house_gid":20001}
+type ListReportDefinitionsRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+}
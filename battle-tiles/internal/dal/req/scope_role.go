@@ -0,0 +1,12 @@
+package req
+
+// ScopeRoleRequest 授予/撤销店铺作用域角色（role_id 与 role_code 二选一，店铺管理员只能用 role_code）
+// @example {"user_id":12, "role_code":"shop_operator", "house_gid":20001, "group_id":0}
+type ScopeRoleRequest struct {
+	UserID   int32  `json:"user_id" binding:"required,gt=0"`
+	RoleID   int32  `json:"role_id"`
+	RoleCode string `json:"role_code"`
+	HouseGID int32  `json:"house_gid" binding:"required,gt=0"`
+	// 0 = 整个店铺
+	GroupID int32 `json:"group_id" binding:"gte=0"`
+}
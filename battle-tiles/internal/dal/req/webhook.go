@@ -0,0 +1,46 @@
+package req
+
+// SaveWebhookEndpointRequest 新建/修改 webhook 回调地址（id 为空表示新建）
+// events 可选：funds.deposit funds.withdraw member.forbidden member.unforbidden member.low_balance
+// application.received application.decided table.dismissed session.offline battle.ingested
+// member.blocklist_kicked house.diamond_low
+// @example {"house_gid":20001, "name":"财务系统", "url":"https://example.com/hook", "events":["funds.deposit","funds.withdraw"], "low_balance_threshold":1000, "enabled":true}
+type SaveWebhookEndpointRequest struct {
+	ID                  int32    `json:"id"`
+	HouseGID            int32    `json:"house_gid" binding:"required,gt=0"`
+	Name                string   `json:"name" binding:"max=64"`
+	URL                 string   `json:"url" binding:"required,max=512"`
+	Events              []string `json:"events" binding:"required,min=1"`
+	LowBalanceThreshold int32    `json:"low_balance_threshold" binding:"gte=0"`
+	Enabled             bool     `json:"enabled"`
+}
+
+// WebhookEndpointIDRequest 按回调地址ID操作
+// @example {"house_gid":20001, "id":1}
+type WebhookEndpointIDRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+	ID       int32 `json:"id" binding:"required,gt=0"`
+}
+
+// ListWebhookEndpointsRequest 店铺回调地址列表
+// @example {"house_gid":20001}
+type ListWebhookEndpointsRequest struct {
+	HouseGID int32 `json:"house_gid" binding:"required,gt=0"`
+}
+
+// ListWebhookDeliveriesRequest 投递记录
+// @example {"house_gid":20001, "endpoint_id":1, "status":"failed", "page":1, "page_size":20}
+type ListWebhookDeliveriesRequest struct {
+	HouseGID   int32  `json:"house_gid" binding:"required,gt=0"`
+	EndpointID *int32 `json:"endpoint_id"`
+	Status     string `json:"status" binding:"omitempty,oneof=pending success failed"`
+	Page       int32  `json:"page"`
+	PageSize   int32  `json:"page_size"`
+}
+
+// RedeliverWebhookRequest 手动重投
+// @example {"house_gid":20001, "delivery_id":10}
+type RedeliverWebhookRequest struct {
+	HouseGID   int32 `json:"house_gid" binding:"required,gt=0"`
+	DeliveryID int32 `json:"delivery_id" binding:"required,gt=0"`
+}
@@ -9,6 +9,9 @@ type LoginResponse struct {
 	Role         string        `json:"role,omitempty"` // 用户角色：super_admin, store_admin, user
 	Roles        []int32       `json:"roles,omitempty"`
 	Perms        []string      `json:"perms,omitempty"`
+	SessionID    string        `json:"sid,omitempty"` // 当前登录会话
+	// 账号持有资金/管理权限但尚未开启二次验证
+	TOTPSuggested bool `json:"totp_suggested,omitempty"`
 }
 type BaseUserInfo struct {
 	ID           int32  `json:"id"`
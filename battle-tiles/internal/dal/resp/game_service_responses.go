@@ -58,6 +58,8 @@ type ShopMemberListItem struct {
 	MemberID   uint32  `json:"member_id"`
 	MemberType int     `json:"member_type"`
 	NickName   string  `json:"nick_name"`
+	// Forbid 游戏端成员权限含禁止位（随成员更新推送实时变化）
+	Forbid     bool    `json:"forbid"`
 	// GroupID 若协议侧提供则填充；当前为 0（未知/未分组）
 	GroupID    int     `json:"group_id"`
 	// GroupName 圈子名称（圈主昵称）
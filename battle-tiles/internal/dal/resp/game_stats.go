@@ -68,3 +68,35 @@ type ActiveByHouseVO struct {
 	HouseGID int   `json:"house_gid"`
 	Active   int64 `json:"active"`
 }
+
+// StatsSeriesVO 时间序列统计（零值桶已补齐）
+// @description 统计范围 [range_start, range_end)，按 bucket 分桶
+type StatsSeriesVO struct {
+	HouseGID   int                  `json:"house_gid"`
+	GroupID    *int                 `json:"group_id,omitempty"`
+	MemberID   *int                 `json:"member_id,omitempty"`
+	Bucket     string               `json:"bucket"`
+	RangeStart time.Time            `json:"range_start"`
+	RangeEnd   time.Time            `json:"range_end"`
+	Points     []StatsSeriesPointVO `json:"points"`
+}
+
+// StatsSeriesPointVO 单个时间桶
+// @example {"time":"2026-10-19T00:00:00+08:00","label":"2026-10-19","games":12,"fee":360,"score_volume":4800,"active_players":9,"deposit":1000,"withdraw":200}
+type StatsSeriesPointVO struct {
+	// 桶起始时间
+	Time  time.Time `json:"time"`
+	Label string    `json:"label"`
+	// 局数
+	Games int64 `json:"games"`
+	// 运费
+	Fee int64 `json:"fee"`
+	// 输赢流水（绝对值合计）
+	ScoreVolume int64 `json:"score_volume"`
+	// 活跃玩家数
+	ActivePlayers int64 `json:"active_players"`
+	// 上分
+	Deposit int64 `json:"deposit"`
+	// 下分（含强制下分）
+	Withdraw int64 `json:"withdraw"`
+}
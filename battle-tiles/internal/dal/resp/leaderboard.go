@@ -0,0 +1,31 @@
+package resp
+
+import "time"
+
+// LeaderboardVO 排行榜
+type LeaderboardVO struct {
+	HouseGID int32  `json:"house_gid"`
+	GroupID  *int32 `json:"group_id,omitempty"`
+	KindID   *int32 `json:"kind_id,omitempty"`
+	KindName string `json:"kind_name,omitempty"`
+	Metric   string `json:"metric"`
+	// 统计窗口 [range_start, range_end)
+	RangeStart time.Time `json:"range_start"`
+	RangeEnd   time.Time `json:"range_end"`
+	// 榜单数据刷新时间
+	RefreshedAt time.Time           `json:"refreshed_at"`
+	Items       []LeaderboardItemVO `json:"items"`
+}
+
+// LeaderboardItemVO 排行榜条目
+// @example {"rank":1,"player_game_id":123456,"player_game_name":"abc","games":30,"wins":18,"win_rate":0.6,"score":1200,"fee":90}
+type LeaderboardItemVO struct {
+	Rank           int     `json:"rank"`
+	PlayerGameID   int32   `json:"player_game_id"`
+	PlayerGameName string  `json:"player_game_name"`
+	Games          int64   `json:"games"`
+	Wins           int64   `json:"wins"`
+	WinRate        float64 `json:"win_rate"`
+	Score          int64   `json:"score"`
+	Fee            int64   `json:"fee"`
+}
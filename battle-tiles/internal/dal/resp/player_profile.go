@@ -0,0 +1,82 @@
+package resp
+
+import (
+	model "battle-tiles/internal/dal/model/game"
+	"time"
+)
+
+// PlayerProfileVO 玩家 360° 视图（一次返回账号/成员/钱包/规则/流水/战绩/统计/申请/在线状态）
+type PlayerProfileVO struct {
+	HouseGID int32 `json:"house_gid"`
+	// 游戏ID
+	GameID int32 `json:"game_id"`
+	// 平台用户ID（未绑定时为 0）
+	UserID int32 `json:"user_id"`
+
+	Account      *PlayerAccountVO          `json:"account"`
+	Memberships  []PlayerMembershipVO      `json:"memberships"`
+	Ledger       []*model.GameWalletLedger `json:"recent_ledger"`
+	Battles      []PlayerBattleVO          `json:"recent_battles"`
+	Stats        PlayerStatsVO             `json:"stats"`
+	Applications []*model.UserApplication  `json:"pending_applications"`
+	Online       *PlayerOnlineVO           `json:"online"`
+}
+
+// PlayerAccountVO 绑定的游戏账号（不含密码）
+type PlayerAccountVO struct {
+	Id                 int32      `json:"id"`
+	UserID             int32      `json:"user_id"`
+	Account            string     `json:"account"`
+	Nickname           string     `json:"nickname"`
+	GameUserID         string     `json:"game_user_id"`
+	Status             int32      `json:"status"`
+	VerificationStatus string     `json:"verification_status"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+}
+
+// PlayerMembershipVO 成员在某个圈的身份、钱包与规则
+type PlayerMembershipVO struct {
+	Member *model.GameMember       `json:"member"`
+	Wallet *model.GameMemberWallet `json:"wallet"`
+	Rule   *model.GameMemberRule   `json:"rule"`
+}
+
+// PlayerBattleVO 最近战绩
+type PlayerBattleVO struct {
+	Id        int32     `json:"id"`
+	GroupID   int32     `json:"group_id"`
+	RoomUID   int32     `json:"room_uid"`
+	KindID    int32     `json:"kind_id"`
+	KindName  string    `json:"kind_name"`
+	BaseScore int32     `json:"base_score"`
+	BattleAt  time.Time `json:"battle_at"`
+	Score     int32     `json:"score"`
+	Fee       int32     `json:"fee"`
+	// 净输赢 = score - fee
+	NetScore int32 `json:"net_score"`
+}
+
+// PlayerStatsVO 全部/今日/本周战绩统计
+type PlayerStatsVO struct {
+	Lifetime PlayerStatsItemVO `json:"lifetime"`
+	Today    PlayerStatsItemVO `json:"today"`
+	ThisWeek PlayerStatsItemVO `json:"this_week"`
+}
+
+type PlayerStatsItemVO struct {
+	Games int64 `json:"games"`
+	Score int   `json:"score"`
+	Fee   int   `json:"fee"`
+}
+
+// PlayerOnlineVO 当前在线状态（来自中控会话的成员快照）
+type PlayerOnlineVO struct {
+	// 中控会话是否在线（false 时以下字段无意义）
+	SessionOnline bool   `json:"session_online"`
+	InGroup       bool   `json:"in_group"`
+	MemberID      int32  `json:"member_id"`
+	MemberType    int    `json:"member_type"`
+	UserStatus    int    `json:"user_status"`
+	StatusName    string `json:"status_name"`
+	NickName      string `json:"nick_name"`
+}
@@ -32,6 +32,8 @@ var ProviderSet = wire.NewSet(
 	NewRdbMap,
 	NewData,
 	plaza.NewManager,
+	plaza.NewCredentialKeyring,
+	NewTaskQueue,
 )
 
 // Data .
@@ -0,0 +1,75 @@
+package plaza
+
+import (
+	"battle-tiles/internal/conf"
+	"battle-tiles/pkg/utils/sealx"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Credential 落库的游戏账号密码：Sealed 为加密后的 MD5，KeyVer 为加密所用的主密钥版本。
+// KeyVer 为 0 表示尚未加密的历史数据（轮换命令执行后不应再出现）。
+type Credential struct {
+	Sealed string
+	KeyVer int32
+}
+
+// CredentialKeyEnvPrefix 主密钥从环境变量注入：CREDENTIAL_KEY_<版本>=<base64 的 32 字节>，
+// 优先于配置文件中 global.crypto.keys 的同版本值（仓库内的配置不放真实密钥）
+const CredentialKeyEnvPrefix = "CREDENTIAL_KEY_"
+
+// NewCredentialKeyring 从 global.crypto.active_version 与环境变量/配置中的密钥构建主密钥环。
+// 未配置 active_version 或缺少其密钥时返回错误，服务不能在无法加解密凭据的状态下启动
+func NewCredentialKeyring(globalConf *conf.Global) (*sealx.Keyring, error) {
+	c := globalConf.GetCrypto()
+	keys := make(map[int32]string, len(c.GetKeys()))
+	for ver, k := range c.GetKeys() {
+		if k = strings.TrimSpace(k); k != "" {
+			keys[ver] = k
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, CredentialKeyEnvPrefix) || strings.TrimSpace(val) == "" {
+			continue
+		}
+		ver, err := strconv.ParseInt(strings.TrimPrefix(name, CredentialKeyEnvPrefix), 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("invalid %s: version must be a number", name)
+		}
+		keys[int32(ver)] = strings.TrimSpace(val)
+	}
+
+	active := c.GetActiveVersion()
+	if active <= 0 {
+		return nil, errors.New("global.crypto.active_version not configured")
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, errors.Errorf("credential key v%d not configured: set %s%d", active, CredentialKeyEnvPrefix, active)
+	}
+	return sealx.NewKeyring(active, keys)
+}
+
+// SealCredential 加密待落库的密码 MD5（统一转大写）
+func SealCredential(kr *sealx.Keyring, pwdMD5 string) (Credential, error) {
+	sealed, ver, err := kr.Seal(strings.ToUpper(strings.TrimSpace(pwdMD5)))
+	if err != nil {
+		return Credential{}, errors.Wrap(err, "seal credential")
+	}
+	return Credential{Sealed: sealed, KeyVer: ver}, nil
+}
+
+// openCredential 仅供 manager 在建立连接前解密使用
+func (m *manager) openCredential(c Credential) (string, error) {
+	if c.KeyVer == 0 {
+		return strings.ToUpper(c.Sealed), nil
+	}
+	plain, err := m.keyring.Open(c.Sealed, c.KeyVer)
+	if err != nil {
+		return "", errors.Wrapf(err, "open credential v%d", c.KeyVer)
+	}
+	return plain, nil
+}
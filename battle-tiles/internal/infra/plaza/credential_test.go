@@ -0,0 +1,33 @@
+package plaza
+
+import (
+	"battle-tiles/internal/conf"
+	"testing"
+)
+
+const testKeyV1 = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+func TestNewCredentialKeyringFromEnv(t *testing.T) {
+	global := &conf.Global{Crypto: &conf.Global_Crypto{ActiveVersion: 1}}
+	if _, err := NewCredentialKeyring(global); err == nil {
+		t.Fatal("missing active key should fail startup")
+	}
+
+	t.Setenv(CredentialKeyEnvPrefix+"1", testKeyV1)
+	kr, err := NewCredentialKeyring(global)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := SealCredential(kr, "abc")
+	if err != nil || c.KeyVer != 1 {
+		t.Fatalf("seal = %+v, %v", c, err)
+	}
+
+	if _, err := NewCredentialKeyring(&conf.Global{Crypto: &conf.Global_Crypto{}}); err == nil {
+		t.Fatal("missing active_version should fail startup")
+	}
+	t.Setenv(CredentialKeyEnvPrefix+"x", testKeyV1)
+	if _, err := NewCredentialKeyring(global); err == nil {
+		t.Fatal("non-numeric key version should be rejected")
+	}
+}
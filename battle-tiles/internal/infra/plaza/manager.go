@@ -5,6 +5,7 @@ import (
 	"battle-tiles/internal/conf"
 	"battle-tiles/internal/consts"
 	gamevo "battle-tiles/internal/dal/vo/game"
+	"battle-tiles/pkg/utils/sealx"
 	"context"
 	"fmt"
 	"strings"
@@ -27,8 +28,9 @@ type Config struct {
 }
 
 type Manager interface {
-	// 启动/替换某个用户在某个 House 的会话
-	StartUser(ctx context.Context, userID, houseGID int, mode consts.GameLoginMode, identifier, pwdMD5 string, gameUserID int, h Handler) error
+	// 启动/替换某个用户在某个 House 的会话；pwd 为落库的加密凭据，仅在此处解密。
+	// 同一中控账号已有在线连接时直接在该连接上进入店铺，不再重复登录
+	StartUser(ctx context.Context, userID, houseGID int, mode consts.GameLoginMode, identifier string, pwd Credential, gameUserID int, h Handler) error
 	// 获取指定用户在指定 House 的会话
 	Get(userID, houseGID int) (*utilsplaza.Session, bool)
 	// 获取任意用户在该 House 下的会话（用于共享读取场景）
@@ -56,6 +58,8 @@ type Manager interface {
 	ProbeLogin(ctx context.Context, mode consts.GameLoginMode, identifier, pwdMD5 string) error
 	// 探测并返回游戏端用户信息（用于持久化 game_user_id）
 	ProbeLoginWithInfo(ctx context.Context, mode consts.GameLoginMode, identifier, pwdMD5 string) (*gamevo.UserLogonInfo, error)
+	// 同上，但使用落库的加密凭据
+	ProbeStoredLoginWithInfo(ctx context.Context, mode consts.GameLoginMode, identifier string, pwd Credential) (*gamevo.UserLogonInfo, error)
 
 	// 尝试通过协议捕获可见店铺列表（best-effort）
 	ListHousesByLogin(ctx context.Context, mode consts.GameLoginMode, identifier, pwdMD5 string) ([]int, error)
@@ -71,11 +75,13 @@ type Manager interface {
 type manager struct {
 	cfg        Config
 	globalConf *conf.Global
+	keyring    *sealx.Keyring
 	logger     *log.Helper
 
 	mu       sync.RWMutex
 	sessions map[string]*utilsplaza.Session // key: userKey(userID, houseGID)
 	online   map[string]bool                // 在线状态
+	accounts map[string]string              // userKey -> accountKey，用于找同一中控账号的连接
 
 	// 指标
 	restartCount  map[string]int       // 重启次数，按 userID:houseGID 统计
@@ -90,7 +96,12 @@ func userKey(userID, houseGID int) string {
 	return fmt.Sprintf("%d:%d", userID, houseGID)
 }
 
-func NewManager(globalConf *conf.Global, logger log.Logger) Manager {
+// 中控账号 key：同一账号只保持一条 82/87 连接
+func accountKey(mode consts.GameLoginMode, identifier string) string {
+	return fmt.Sprintf("%d:%s", mode, strings.TrimSpace(identifier))
+}
+
+func NewManager(globalConf *conf.Global, keyring *sealx.Keyring, logger log.Logger) Manager {
 	cfg := Config{
 		Server82:      globalConf.Game.Plaza.Server82,
 		Server87Host:  globalConf.Game.Plaza.Server87Host,
@@ -100,9 +111,11 @@ func NewManager(globalConf *conf.Global, logger log.Logger) Manager {
 	return &manager{
 		cfg:           cfg,
 		globalConf:    globalConf,
+		keyring:       keyring,
 		logger:        log.NewHelper(log.With(logger, "module", "infra/plaza/manager")),
 		sessions:      make(map[string]*utilsplaza.Session),
 		online:        make(map[string]bool),
+		accounts:      make(map[string]string),
 		restartCount:  make(map[string]int),
 		lastRestartAt: make(map[string]time.Time),
 	}
@@ -145,6 +158,21 @@ func (w *handlerWrapper) OnMemberDeleted(m *utilsplaza.MemberDeleted) {
 		w.inner.OnMemberDeleted(m)
 	}
 }
+func (w *handlerWrapper) OnMemberUpdated(m *utilsplaza.MemberUpdated) {
+	if w.inner != nil {
+		w.inner.OnMemberUpdated(m)
+	}
+}
+func (w *handlerWrapper) OnGroupUpdated(p *utilsplaza.GroupProperty) {
+	if w.inner != nil {
+		w.inner.OnGroupUpdated(p)
+	}
+}
+func (w *handlerWrapper) OnGroupDeleted(groupID int) {
+	if w.inner != nil {
+		w.inner.OnGroupDeleted(groupID)
+	}
+}
 func (w *handlerWrapper) OnMemberRightUpdated(account string, right int, allow bool) {
 	if w.inner != nil {
 		w.inner.OnMemberRightUpdated(account, right, allow)
@@ -199,21 +227,38 @@ func (w *handlerWrapper) OnReconnectFailed(houseGID int, retryCount int) {
 		w.inner.OnReconnectFailed(houseGID, retryCount)
 	}
 }
+func (w *handlerWrapper) OnBattleConfigsUpdated(list []*utilsplaza.BattleConfig) {
+	if w.inner != nil {
+		w.inner.OnBattleConfigsUpdated(list)
+	}
+}
+func (w *handlerWrapper) OnDiamondUpdated(diamond int64, pushed bool) {
+	if w.inner != nil {
+		w.inner.OnDiamondUpdated(diamond, pushed)
+	}
+}
 
 // --- Manager 方法实现 ---
 
-func (m *manager) StartUser(ctx context.Context, userID, houseGID int, mode consts.GameLoginMode, identifier, pwdMD5 string, gameUserID int, h Handler) error {
+func (m *manager) StartUser(ctx context.Context, userID, houseGID int, mode consts.GameLoginMode, identifier string, pwd Credential, gameUserID int, h Handler) error {
+	pwdMD5, err := m.openCredential(pwd)
+	if err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	key := userKey(userID, houseGID)
 
-	// 若已存在该 House 的会话，先停掉老的
+	// 若已存在该 House 的会话，先停掉老的（共用连接时只离开店铺）
 	if old, ok := m.sessions[key]; ok && old != nil {
 		old.Shutdown()
 		delete(m.sessions, key)
 		delete(m.online, key)
+		delete(m.accounts, key)
 	}
+	acct := accountKey(mode, identifier)
 
 	// 包装 handler，用于写入在线状态/房间回调
 	w := &handlerWrapper{
@@ -253,6 +298,20 @@ func (m *manager) StartUser(ctx context.Context, userID, houseGID int, mode cons
 		},
 	}
 
+	// 同一中控账号已有连接：在该连接上进入店铺（登录态沿用，在线状态随连接）
+	if shared := m.accountSessionLocked(acct); shared != nil {
+		s, err := shared.EnterHouse(houseGID, w)
+		if err == nil {
+			m.sessions[key] = s
+			m.accounts[key] = acct
+			m.online[key] = m.online[m.keyOfLocked(shared)]
+			go s.GetGroupMembers()
+			m.logger.Infof("house %d joins shared session of %s (houses=%v)", houseGID, acct, s.EnteredHouses())
+			return nil
+		}
+		m.logger.Warnf("enter house %d on shared session failed, opening a new one: %v", houseGID, err)
+	}
+
 	cfg := utilsplaza.SessionConfig{
 		Server82:      m.cfg.Server82,
 		Server87Host:  m.cfg.Server87Host,
@@ -271,24 +330,53 @@ func (m *manager) StartUser(ctx context.Context, userID, houseGID int, mode cons
 		return err
 	}
 	m.sessions[key] = s
+	m.accounts[key] = acct
 
 	// 在线状态由 OnLoginDone 回调置位
 	return nil
 }
 
+// accountSessionLocked 找该中控账号仍在运行的任一店铺会话；调用方持有 m.mu
+func (m *manager) accountSessionLocked(acct string) *utilsplaza.Session {
+	for k, a := range m.accounts {
+		if a != acct {
+			continue
+		}
+		if s := m.sessions[k]; s != nil && !s.Closed() {
+			return s
+		}
+	}
+	return nil
+}
+
+// keyOfLocked 会话对应的 userKey；调用方持有 m.mu
+func (m *manager) keyOfLocked(s *utilsplaza.Session) string {
+	for k, v := range m.sessions {
+		if v == s {
+			return k
+		}
+	}
+	return ""
+}
+
 // Metrics 指标快照
 type Metrics struct {
-	TotalSessions int
-	OnlineCount   int
-	RestartTotal  int
-	RestartsByKey map[string]int
-	LastRestartAt map[string]time.Time
+	TotalSessions  int // 店铺会话数（userID:houseGID）
+	Connections    int // 实际 82/87 连接数，同一中控账号的多个店铺共用一条
+	OnlineCount    int
+	RestartTotal   int
+	RestartsByKey  map[string]int
+	LastRestartAt  map[string]time.Time
+	UnknownPackets map[string]int64 // 未登记回包的累计次数（"main/sub" -> 次数，进程级）
+	ProtocolErrors map[string]int64 // 各会话所在连接的协议错误累计（解密/解析失败、处理 panic）
+	UnroutedPushes map[string]int64 // 各会话所在连接丢弃的推送（群组标识不属于已进入的店铺）
 }
 
 // HealthStatus 健康检查结果
 type HealthStatus struct {
-	OK     bool
-	Reason string
+	OK             bool
+	Reason         string
+	ProtocolErrors map[string]int64 // 同 Metrics.ProtocolErrors
 }
 
 // Metrics 返回当前指标快照
@@ -303,6 +391,26 @@ func (m *manager) Metrics() Metrics {
 			online++
 		}
 	}
+	var conns []*utilsplaza.Session
+	protoErrs := make(map[string]int64, len(m.sessions))
+	unrouted := make(map[string]int64, len(m.sessions))
+	for k, s := range m.sessions {
+		if s == nil {
+			continue
+		}
+		protoErrs[k] = s.ProtocolErrors()
+		unrouted[k] = s.UnroutedPushes()
+		shared := false
+		for _, c := range conns {
+			if c.SameConn(s) {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			conns = append(conns, s)
+		}
+	}
 
 	// 拷贝 map 避免逃逸引用
 	restarts := make(map[string]int, len(m.restartCount))
@@ -320,28 +428,36 @@ func (m *manager) Metrics() Metrics {
 	}
 
 	return Metrics{
-		TotalSessions: total,
-		OnlineCount:   online,
-		RestartTotal:  sum,
-		RestartsByKey: restarts,
-		LastRestartAt: last,
+		TotalSessions:  total,
+		Connections:    len(conns),
+		OnlineCount:    online,
+		RestartTotal:   sum,
+		RestartsByKey:  restarts,
+		LastRestartAt:  last,
+		UnknownPackets: utilsplaza.UnknownPacketCounts(),
+		ProtocolErrors: protoErrs,
+		UnroutedPushes: unrouted,
 	}
 }
 
-// Health 依据在线率与重启次数给出粗略健康状态
+// Health 依据在线率与重启次数给出粗略健康状态，附带各会话的协议错误计数
 func (m *manager) Health() HealthStatus {
 	mt := m.Metrics()
+	h := HealthStatus{OK: true, Reason: "ok", ProtocolErrors: mt.ProtocolErrors}
 	if mt.TotalSessions == 0 {
-		return HealthStatus{OK: true, Reason: "no sessions"}
+		h.Reason = "no sessions"
+		return h
 	}
 	offline := mt.TotalSessions - mt.OnlineCount
 	if float64(mt.OnlineCount)/float64(mt.TotalSessions) < 0.5 {
-		return HealthStatus{OK: false, Reason: fmt.Sprintf("online %d/%d, too many offline: %d", mt.OnlineCount, mt.TotalSessions, offline)}
+		h.OK = false
+		h.Reason = fmt.Sprintf("online %d/%d, too many offline: %d", mt.OnlineCount, mt.TotalSessions, offline)
+		return h
 	}
 	if mt.RestartTotal > mt.TotalSessions*5 { // 任意粗略阈值
-		return HealthStatus{OK: true, Reason: fmt.Sprintf("high restarts total=%d", mt.RestartTotal)}
+		h.Reason = fmt.Sprintf("high restarts total=%d", mt.RestartTotal)
 	}
-	return HealthStatus{OK: true, Reason: "ok"}
+	return h
 }
 
 func (m *manager) Get(userID, houseGID int) (*utilsplaza.Session, bool) {
@@ -455,10 +571,11 @@ func (m *manager) StopUser(userID, houseGID int) {
 	defer m.mu.Unlock()
 	key := userKey(userID, houseGID)
 	if s, ok := m.sessions[key]; ok && s != nil {
-		s.Shutdown()
+		s.Shutdown() // 共用连接时只离开该店铺，最后一个店铺离开才断开
 		delete(m.sessions, key)
 	}
 	delete(m.online, key) // 清理在线标志
+	delete(m.accounts, key)
 }
 
 func (m *manager) StopUserAll(userID int) {
@@ -472,6 +589,7 @@ func (m *manager) StopUserAll(userID int) {
 				s.Shutdown()
 			}
 			delete(m.sessions, k)
+			delete(m.accounts, k)
 		}
 	}
 	// 清理在线表
@@ -492,6 +610,7 @@ func (m *manager) StopAll() {
 		delete(m.sessions, k)
 	}
 	m.online = make(map[string]bool)
+	m.accounts = make(map[string]string)
 }
 
 // 只做登录探测，不建连接
@@ -538,6 +657,14 @@ func (m *manager) ProbeLoginWithInfo(ctx context.Context, mode consts.GameLoginM
 	}
 }
 
+func (m *manager) ProbeStoredLoginWithInfo(ctx context.Context, mode consts.GameLoginMode, identifier string, pwd Credential) (*gamevo.UserLogonInfo, error) {
+	pwdMD5, err := m.openCredential(pwd)
+	if err != nil {
+		return nil, err
+	}
+	return m.ProbeLoginWithInfo(ctx, mode, identifier, pwdMD5)
+}
+
 func (m *manager) ListHousesByLogin(ctx context.Context, mode consts.GameLoginMode, identifier, pwdMD5 string) ([]int, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"battle-tiles/internal/conf"
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+)
+
+// TaskQueue 向 asynq 投递一次性任务，由 go-kgin-asynq 进程中注册的同名订阅者消费。
+// 载荷与 service.TaskPayload 保持一致：message 放平台标识，data 放任务参数。
+type TaskQueue struct {
+	client *asynq.Client
+}
+
+type taskPayload struct {
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// NewTaskQueue 按 server.asynq 配置连接任务队列；未配置地址时返回空队列（Enqueue 报错）
+func NewTaskQueue(c *conf.Server) (*TaskQueue, func(), error) {
+	q := &TaskQueue{}
+	if c.GetAsynq().GetAddr() == "" {
+		return q, func() {}, nil
+	}
+	q.client = asynq.NewClient(asynq.RedisClientOpt{
+		Addr:     c.Asynq.Addr,
+		Password: c.Asynq.Password,
+		DB:       int(c.Asynq.Db),
+	})
+	return q, func() { _ = q.client.Close() }, nil
+}
+
+// Enqueue 投递任务；platform 写入 message，消费端据此切换平台库
+func (q *TaskQueue) Enqueue(ctx context.Context, taskType, platform string, data any, opts ...asynq.Option) error {
+	if q == nil || q.client == nil {
+		return errors.New("task queue not configured")
+	}
+	body, err := json.Marshal(taskPayload{Message: platform, Data: data})
+	if err != nil {
+		return err
+	}
+	if _, err = q.client.EnqueueContext(ctx, asynq.NewTask(taskType, body), opts...); err != nil {
+		return errors.Wrapf(err, "enqueue %s", taskType)
+	}
+	return nil
+}
@@ -12,6 +12,10 @@ type BasicRouter struct {
 	menuService       *basic.BasicMenuService
 	roleService       *basic.BasicRoleService
 	permissionService *basic.BasicPermissionService
+	scopeRoleService  *basic.BasicScopeRoleService
+	auditService      *basic.BasicAuditService
+	securityService   *basic.BasicSecurityService
+	apiKeyService     *basic.BasicAPIKeyService
 }
 
 func (r *BasicRouter) InitRouter(root *gin.RouterGroup) {
@@ -20,6 +24,10 @@ func (r *BasicRouter) InitRouter(root *gin.RouterGroup) {
 	r.menuService.RegisterRouter(root)
 	r.roleService.RegisterRouter(root)
 	r.permissionService.RegisterRouter(root)
+	r.scopeRoleService.RegisterRouter(root)
+	r.auditService.RegisterRouter(root)
+	r.securityService.RegisterRouter(root)
+	r.apiKeyService.RegisterRouter(root)
 }
 
 func NewBasicRouter(
@@ -28,6 +36,10 @@ func NewBasicRouter(
 	menuService *basic.BasicMenuService,
 	roleService *basic.BasicRoleService,
 	permissionService *basic.BasicPermissionService,
+	scopeRoleService *basic.BasicScopeRoleService,
+	auditService *basic.BasicAuditService,
+	securityService *basic.BasicSecurityService,
+	apiKeyService *basic.BasicAPIKeyService,
 ) *BasicRouter {
 	return &BasicRouter{
 		userService:       userService,
@@ -35,5 +47,9 @@ func NewBasicRouter(
 		menuService:       menuService,
 		roleService:       roleService,
 		permissionService: permissionService,
+		scopeRoleService:  scopeRoleService,
+		auditService:      auditService,
+		securityService:   securityService,
+		apiKeyService:     apiKeyService,
 	}
 }
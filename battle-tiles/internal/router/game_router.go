@@ -22,6 +22,17 @@ type GameRouter struct {
 	battleRecordService    *game.BattleRecordService
 	shopGroupService       *game.ShopGroupService
 	memberService          *game.MemberService
+	groupSettlementService *game.GroupSettlementService
+	leaderboardService     *game.LeaderboardService
+	playerProfileService   *game.PlayerProfileService
+	reportService          *game.ReportService
+	webhookService         *game.WebhookService
+	applicationRuleService *game.ApplicationRuleService
+	onboardingService      *game.OnboardingService
+	blocklistService       *game.BlocklistService
+	battleConfigService    *game.BattleConfigService
+	diamondService         *game.DiamondService
+	battleSyncService      *game.BattleSyncService
 }
 
 func (r *GameRouter) InitRouter(root *gin.RouterGroup) {
@@ -59,6 +70,39 @@ func (r *GameRouter) InitRouter(root *gin.RouterGroup) {
 
 	// 成员管理
 	r.memberService.RegisterRouter(root)
+
+	// 圈主结算单
+	r.groupSettlementService.RegisterRouter(root)
+
+	// 排行榜
+	r.leaderboardService.RegisterRouter(root)
+
+	// 玩家 360° 视图
+	r.playerProfileService.RegisterRouter(root)
+
+	// 店铺定时报表
+	r.reportService.RegisterRouter(root)
+
+	// 店铺 webhook
+	r.webhookService.RegisterRouter(root)
+
+	// 申请自动处理规则
+	r.applicationRuleService.RegisterRouter(root)
+
+	// 成员入店流程
+	r.onboardingService.RegisterRouter(root)
+
+	// 平台黑名单
+	r.blocklistService.RegisterRouter(root)
+
+	// 店铺玩法
+	r.battleConfigService.RegisterRouter(root)
+
+	// 钻石余额与告警
+	r.diamondService.RegisterRouter(root)
+
+	// 战绩同步运维
+	r.battleSyncService.RegisterRouter(root)
 }
 
 func NewGameRouter(
@@ -77,6 +121,17 @@ func NewGameRouter(
 	battleRecordService *game.BattleRecordService,
 	shopGroupService *game.ShopGroupService,
 	memberService *game.MemberService,
+	groupSettlementService *game.GroupSettlementService,
+	leaderboardService *game.LeaderboardService,
+	playerProfileService *game.PlayerProfileService,
+	reportService *game.ReportService,
+	webhookService *game.WebhookService,
+	applicationRuleService *game.ApplicationRuleService,
+	onboardingService *game.OnboardingService,
+	blocklistService *game.BlocklistService,
+	battleConfigService *game.BattleConfigService,
+	diamondService *game.DiamondService,
+	battleSyncService *game.BattleSyncService,
 ) *GameRouter {
 	return &GameRouter{
 		accountService:         accountService,
@@ -94,5 +149,16 @@ func NewGameRouter(
 		battleRecordService:    battleRecordService,
 		shopGroupService:       shopGroupService,
 		memberService:          memberService,
+		groupSettlementService: groupSettlementService,
+		leaderboardService:     leaderboardService,
+		playerProfileService:   playerProfileService,
+		reportService:          reportService,
+		webhookService:         webhookService,
+		applicationRuleService: applicationRuleService,
+		onboardingService:      onboardingService,
+		blocklistService:       blocklistService,
+		battleConfigService:    battleConfigService,
+		diamondService:         diamondService,
+		battleSyncService:      battleSyncService,
 	}
 }
@@ -1,10 +1,13 @@
 package server
 
 import (
+	basicBiz "battle-tiles/internal/biz/basic"
 	"battle-tiles/internal/conf"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
 	rbacstore "battle-tiles/internal/dal/repo/rbac" // ★ 新增
 	"battle-tiles/internal/infra"
 	"battle-tiles/internal/router"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -14,7 +17,7 @@ import (
 )
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, logger log.Logger, router *router.RootRouter, data *infra.Data) *gin.Server {
+func NewHTTPServer(c *conf.Server, logger log.Logger, router *router.RootRouter, data *infra.Data, audit *basicBiz.AuditUseCase) *gin.Server {
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
@@ -36,7 +39,7 @@ func NewHTTPServer(c *conf.Server, logger log.Logger, router *router.RootRouter,
 	srv.Engine.Use(middleware.CORS())
 
 	// ★ 先完成 RBAC 绑定（在注册路由/挂鉴权中间件之前）
-	initPlugin(srv, logger, data)
+	initPlugin(srv, logger, data, audit)
 
 	// ★ 再注册你的业务路由（里面会用到 RequirePerm / RequireAnyPerm）
 	router.InitRouter(srv.Group(""))
@@ -45,9 +48,20 @@ func NewHTTPServer(c *conf.Server, logger log.Logger, router *router.RootRouter,
 }
 
 // 把“插件/全局绑定”都放这里，保持 server 是“组装层”
-func initPlugin(srv *gin.Server, logger log.Logger, data *infra.Data) {
+func initPlugin(srv *gin.Server, logger log.Logger, data *infra.Data, audit *basicBiz.AuditUseCase) {
 	ps := rbacstore.NewStore(data, logger)
 
 	middleware.BindPermissionStore(ps)
 
+	// 登录会话校验：JWTAuth 据此拒绝已登出/被吊销/权限变更前签发的 token
+	middleware.BindSessionChecker(basicBiz.NewLoginSessionUseCase(
+		basicRepo.NewLoginSessionRepo(data, logger), basicRepo.NewAuthRepo(data, logger), ps, logger))
+
+	// 服务账号 API Key：JWTOrAPIKeyAuth 据此认证 X-API-Key
+	middleware.BindAPIKeyVerifier(basicBiz.NewAPIKeyUseCase(basicRepo.NewAPIKeyRepo(data, logger), ps, logger))
+
+	// 审计落库 + 全局审计中间件（需先于业务路由挂载）
+	auditx.BindSink(audit)
+	srv.Engine.Use(middleware.AuditTrail())
+
 }
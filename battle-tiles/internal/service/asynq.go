@@ -2,9 +2,12 @@ package service
 
 import (
 	"battle-tiles/internal/biz"
+	basicBiz "battle-tiles/internal/biz/basic"
+	"battle-tiles/internal/biz/game"
 	cloudRepo "battle-tiles/internal/dal/repo/cloud"
 	pdb "battle-tiles/pkg/plugin/dbx"
 	"context"
+	"encoding/json"
 	"sync"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -24,17 +27,29 @@ type AsyNQService struct {
 	SubscriberMap map[string]Handler
 	uc            *biz.AsyNQUseCase
 	cloudRepo     cloudRepo.BasePlatformRepo
+	report        *game.ReportUseCase
+	audit         *basicBiz.AuditUseCase
+	webhook       *game.WebhookUseCase
+	apps          *game.ApplicationUseCase
 }
 
 func NewAsyNQService(
 	logger log.Logger,
 	uc *biz.AsyNQUseCase,
 	cloudRepo cloudRepo.BasePlatformRepo,
+	report *game.ReportUseCase,
+	audit *basicBiz.AuditUseCase,
+	webhook *game.WebhookUseCase,
+	apps *game.ApplicationUseCase,
 ) *AsyNQService {
 	s := &AsyNQService{
 		log:       log.NewHelper(log.With(logger, "module", "service/asynq")),
 		uc:        uc,
 		cloudRepo: cloudRepo,
+		report:    report,
+		audit:     audit,
+		webhook:   webhook,
+		apps:      apps,
 	}
 	s.initSubscriber()
 	return s
@@ -46,6 +61,50 @@ func (s *AsyNQService) initSubscriber() {
 			s.log.Infof("refresh:test:job start running [%s]", payload.Message)
 			return nil
 		},
+		// 各平台到期的店铺定时报表：渲染并投递
+		"report:dispatch": func(taskType string, payload *TaskPayload) error {
+			var p TaskPayload
+			if payload != nil {
+				p = *payload
+			}
+			s.AutoPlatformExec(s.report.RunDue, taskType, p)
+			return nil
+		},
+		// 各平台审计日志按保留期清理
+		"audit:purge": func(taskType string, payload *TaskPayload) error {
+			var p TaskPayload
+			if payload != nil {
+				p = *payload
+			}
+			s.AutoPlatformExec(s.audit.Purge, taskType, p)
+			return nil
+		},
+		// 店铺 webhook 单次投递：message 为平台，data.delivery_id 为投递记录；返回错误时由 asynq 退避重试
+		game.WebhookDeliverTask: func(taskType string, payload *TaskPayload) error {
+			if payload == nil || payload.Message == "" {
+				s.log.Errorf("%s: missing platform in payload", taskType)
+				return nil
+			}
+			var in struct {
+				DeliveryID int32 `json:"delivery_id"`
+			}
+			raw, _ := json.Marshal(payload.Data)
+			if err := json.Unmarshal(raw, &in); err != nil || in.DeliveryID <= 0 {
+				s.log.Errorf("%s: invalid payload %s", taskType, raw)
+				return nil
+			}
+			ctx := context.WithValue(context.Background(), pdb.CtxDBKey, payload.Message)
+			return s.webhook.Deliver(ctx, in.DeliveryID)
+		},
+		// 各平台待审申请超时（按店铺 application_expire_hours）
+		game.ApplicationExpireTask: func(taskType string, payload *TaskPayload) error {
+			var p TaskPayload
+			if payload != nil {
+				p = *payload
+			}
+			s.AutoPlatformExec(s.apps.ExpirePlatform, taskType, p)
+			return nil
+		},
 	}
 }
 func (s *AsyNQService) AutoPlatformExec(funcWithCtx HandlerWithCtx, taskType string, taskPayload TaskPayload) {
@@ -0,0 +1,174 @@
+package basic
+
+import (
+	basicBiz "battle-tiles/internal/biz/basic"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BasicAPIKeyService 服务账号与 API Key 管理
+type BasicAPIKeyService struct {
+	uc *basicBiz.APIKeyUseCase
+}
+
+func NewBasicAPIKeyService(uc *basicBiz.APIKeyUseCase) *BasicAPIKeyService {
+	return &BasicAPIKeyService{uc: uc}
+}
+
+func (s *BasicAPIKeyService) RegisterRouter(root *gin.RouterGroup) {
+	r := root.Group("/basic/apiKey").Use(middleware.JWTAuth(), middleware.RequirePerm("apikey:manage"))
+	r.POST("/serviceAccount/create", s.CreateServiceAccount)
+	r.GET("/serviceAccount/list", s.ListServiceAccounts)
+	r.POST("/issue", s.Issue)
+	r.POST("/list", s.List)
+	r.POST("/revoke", s.Revoke)
+}
+
+// CreateServiceAccount 新建服务账号
+// @Summary      新建服务账号
+// @Description  服务账号没有密码，只能通过 API Key 调用接口
+// @Tags         基础管理/API Key
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.CreateServiceAccountRequest true "服务账号"
+// @Success      200 {object} response.Body
+// @Router       /basic/apiKey/serviceAccount/create [post]
+func (s *BasicAPIKeyService) CreateServiceAccount(c *gin.Context) {
+	var in req.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	u, err := s.uc.CreateServiceAccount(c.Request.Context(), in.Username, in.NickName)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"id": u.Id, "username": u.Username, "nick_name": u.NickName})
+}
+
+// ListServiceAccounts 服务账号列表
+// @Summary      服务账号列表
+// @Tags         基础管理/API Key
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} response.Body
+// @Router       /basic/apiKey/serviceAccount/list [get]
+func (s *BasicAPIKeyService) ListServiceAccounts(c *gin.Context) {
+	list, err := s.uc.ListServiceAccounts(c.Request.Context())
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	out := make([]gin.H, 0, len(list))
+	for _, u := range list {
+		out = append(out, gin.H{"id": u.Id, "username": u.Username, "nick_name": u.NickName, "created_at": u.CreatedAt})
+	}
+	response.Success(c, out)
+}
+
+// Issue 签发 API Key
+// @Summary      签发 API Key
+// @Description  返回的 key 只显示这一次；调用时放在 X-API-Key 头，并用 Platform 头指定平台
+// @Tags         基础管理/API Key
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.IssueAPIKeyRequest true "签发参数"
+// @Success      200 {object} response.Body
+// @Router       /basic/apiKey/issue [post]
+func (s *BasicAPIKeyService) Issue(c *gin.Context) {
+	var in req.IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	input := basicBiz.IssueAPIKeyInput{
+		UserID:    in.UserID,
+		Name:      in.Name,
+		Perms:     in.Perms,
+		HouseGIDs: in.HouseGIDs,
+	}
+	if in.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, in.ExpiresAt)
+		if err != nil {
+			response.Fail(c, ecode.ParamsFailed, err)
+			return
+		}
+		input.ExpiresAt = &t
+	}
+	k, key, err := s.uc.Issue(c.Request.Context(), claims.UserID, claims.BaseClaims.IsSuperAdmin(), input)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"api_key": k, "key": key})
+}
+
+// List API Key 列表
+// @Summary      API Key 列表
+// @Description  非超管只能看到自己签发的、或限定店铺都在自己管理范围内的 Key
+// @Tags         基础管理/API Key
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListAPIKeyRequest true "过滤条件"
+// @Success      200 {object} response.Body
+// @Router       /basic/apiKey/list [post]
+func (s *BasicAPIKeyService) List(c *gin.Context) {
+	var in req.ListAPIKeyRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	list, err := s.uc.List(c.Request.Context(), claims.UserID, claims.BaseClaims.IsSuperAdmin(), in.UserID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, list)
+}
+
+// Revoke 吊销 API Key
+// @Summary      吊销 API Key
+// @Tags         基础管理/API Key
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.RevokeAPIKeyRequest true "Key ID"
+// @Success      200 {object} response.Body
+// @Router       /basic/apiKey/revoke [post]
+func (s *BasicAPIKeyService) Revoke(c *gin.Context) {
+	var in req.RevokeAPIKeyRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err = s.uc.Revoke(c.Request.Context(), in.ID, claims.UserID, claims.BaseClaims.IsSuperAdmin()); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
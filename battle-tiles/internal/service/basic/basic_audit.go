@@ -0,0 +1,83 @@
+package basic
+
+import (
+	basicBiz "battle-tiles/internal/biz/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BasicAuditService 审计日志查询
+type BasicAuditService struct {
+	uc *basicBiz.AuditUseCase
+}
+
+func NewBasicAuditService(uc *basicBiz.AuditUseCase) *BasicAuditService {
+	return &BasicAuditService{uc: uc}
+}
+
+func (s *BasicAuditService) RegisterRouter(root *gin.RouterGroup) {
+	r := root.Group("/audit").Use(middleware.JWTAuth())
+	r.POST("/list", middleware.RequirePerm("audit:view"), s.List)
+}
+
+// List 审计日志列表
+// @Summary      审计日志
+// @Description  按操作人、店铺、操作码前缀、对象、IP、结果、时间范围检索管理操作审计；日志保留 180 天
+// @Tags         基础管理/审计
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListAuditLogRequest true "过滤条件"
+// @Success      200 {object} response.Body
+// @Router       /audit/list [post]
+func (s *BasicAuditService) List(c *gin.Context) {
+	var in req.ListAuditLogRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	f := basicRepo.AuditLogFilter{
+		ActorID:    in.ActorID,
+		HouseGID:   in.HouseGID,
+		Action:     in.Action,
+		TargetType: in.TargetType,
+		TargetID:   in.TargetID,
+		ClientIP:   in.ClientIP,
+		Success:    in.Success,
+	}
+	if in.StartAt != "" {
+		t, err := time.Parse(time.RFC3339, in.StartAt)
+		if err != nil {
+			response.Fail(c, ecode.ParamsFailed, err)
+			return
+		}
+		f.Start = &t
+	}
+	if in.EndAt != "" {
+		t, err := time.Parse(time.RFC3339, in.EndAt)
+		if err != nil {
+			response.Fail(c, ecode.ParamsFailed, err)
+			return
+		}
+		f.End = &t
+	}
+	list, total, err := s.uc.List(c.Request.Context(), f, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	page, size := in.Page, in.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": page, "page_size": size})
+}
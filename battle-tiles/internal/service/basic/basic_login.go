@@ -4,24 +4,35 @@ import (
 	basicBiz "battle-tiles/internal/biz/basic"
 	"battle-tiles/internal/dal/req"
 	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
 	"battle-tiles/pkg/utils/response"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BasicLoginService struct {
-	uc *basicBiz.BasicLoginUseCase
+	uc       *basicBiz.BasicLoginUseCase
+	sessions *basicBiz.LoginSessionUseCase
 }
 
-func NewBasicLoginService(uc *basicBiz.BasicLoginUseCase) *BasicLoginService {
-	return &BasicLoginService{uc: uc}
+func NewBasicLoginService(uc *basicBiz.BasicLoginUseCase, sessions *basicBiz.LoginSessionUseCase) *BasicLoginService {
+	return &BasicLoginService{uc: uc, sessions: sessions}
 }
 
 func (s *BasicLoginService) RegisterRouter(router *gin.RouterGroup) {
 	r := router.Group("/login").Use(middleware.SwitchingDB())
 	r.POST("/username", s.LoginByUsernamePassword)
 	r.POST("/register", s.Register)
+	r.POST("/refresh", s.Refresh)
+
+	// 我的登录会话（设备列表/登出）
+	sess := router.Group("/basic/session").Use(middleware.JWTAuth())
+	sess.GET("/list", s.ListSessions)
+	sess.POST("/logout", s.Logout)
+	sess.POST("/logoutAll", s.LogoutAll)
+	sess.POST("/revoke", s.RevokeSession)
 }
 
 // Register
@@ -68,8 +79,130 @@ func (s *BasicLoginService) LoginByUsernamePassword(ctx *gin.Context) {
 	}
 	res, err := s.uc.LoginByUsernamePassword(ctx.Request.Context(), ctx, &req)
 	if err != nil {
-		response.Fail(ctx, ecode.LoginFailed, err)
+		var locked *basicBiz.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			response.Fail(ctx, ecode.LoginLocked, err)
+		case errors.Is(err, basicBiz.ErrTOTPRequired):
+			response.Fail(ctx, ecode.TOTPRequired, err)
+		default:
+			response.Fail(ctx, ecode.LoginFailed, err)
+		}
+		return
+	}
+	response.Success(ctx, res)
+}
+
+// Refresh
+// @Summary      刷新令牌
+// @Description  用刷新令牌换取新的 access token，刷新令牌同时轮换（旧令牌立即作废，重复使用会吊销会话）
+// @Tags         基础管理/登录
+// @Accept       json
+// @Produce      json
+// @Param        payload    body      req.RefreshTokenRequest  true  "刷新令牌"
+// @Success      200        {object}  response.Body{data=resp.LoginResponse,msg=string}
+// @Router       /login/refresh [post]
+func (s *BasicLoginService) Refresh(ctx *gin.Context) {
+	var in req.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&in); err != nil {
+		response.Fail(ctx, ecode.ParamsFailed, err)
+		return
+	}
+	res, err := s.uc.RefreshToken(ctx.Request.Context(), ctx, in.RefreshToken)
+	if err != nil {
+		response.Fail(ctx, ecode.TokenValidateFailed, err)
 		return
 	}
 	response.Success(ctx, res)
 }
+
+// ListSessions
+// @Summary      我的登录会话
+// @Tags         基础管理/登录
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200        {object}  response.Body
+// @Router       /basic/session/list [get]
+func (s *BasicLoginService) ListSessions(ctx *gin.Context) {
+	claims, err := utils.GetClaims(ctx)
+	if err != nil {
+		response.Fail(ctx, ecode.TokenValidateFailed, err)
+		return
+	}
+	list, err := s.sessions.List(ctx.Request.Context(), claims.UserID)
+	if err != nil {
+		response.Fail(ctx, ecode.Failed, err)
+		return
+	}
+	response.Success(ctx, gin.H{"list": list, "current": claims.SessionID})
+}
+
+// Logout
+// @Summary      退出当前会话
+// @Tags         基础管理/登录
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200        {object}  response.Body
+// @Router       /basic/session/logout [post]
+func (s *BasicLoginService) Logout(ctx *gin.Context) {
+	claims, err := utils.GetClaims(ctx)
+	if err != nil {
+		response.Fail(ctx, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err = s.sessions.Revoke(ctx.Request.Context(), claims.UserID, claims.SessionID); err != nil {
+		response.Fail(ctx, ecode.Failed, err)
+		return
+	}
+	utils.ClearToken(ctx)
+	response.Success(ctx, nil)
+}
+
+// LogoutAll
+// @Summary      所有设备退出登录
+// @Tags         基础管理/登录
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200        {object}  response.Body
+// @Router       /basic/session/logoutAll [post]
+func (s *BasicLoginService) LogoutAll(ctx *gin.Context) {
+	claims, err := utils.GetClaims(ctx)
+	if err != nil {
+		response.Fail(ctx, ecode.TokenValidateFailed, err)
+		return
+	}
+	n, err := s.sessions.RevokeAll(ctx.Request.Context(), claims.UserID)
+	if err != nil {
+		response.Fail(ctx, ecode.Failed, err)
+		return
+	}
+	utils.ClearToken(ctx)
+	response.Success(ctx, gin.H{"revoked": n})
+}
+
+// RevokeSession
+// @Summary      下线指定会话
+// @Tags         基础管理/登录
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload    body      req.RevokeSessionRequest  true  "会话ID"
+// @Success      200        {object}  response.Body
+// @Router       /basic/session/revoke [post]
+func (s *BasicLoginService) RevokeSession(ctx *gin.Context) {
+	var in req.RevokeSessionRequest
+	if err := ctx.ShouldBindJSON(&in); err != nil {
+		response.Fail(ctx, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(ctx)
+	if err != nil {
+		response.Fail(ctx, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err = s.sessions.Revoke(ctx.Request.Context(), claims.UserID, in.SID); err != nil {
+		response.Fail(ctx, ecode.Failed, err)
+		return
+	}
+	response.Success(ctx, nil)
+}
@@ -1,11 +1,14 @@
 package basic
 
 import (
+	basicBiz "battle-tiles/internal/biz/basic"
 	basicModel "battle-tiles/internal/dal/model/basic"
 	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils/ecode"
 	"battle-tiles/pkg/utils/response"
+	"context"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,11 +16,12 @@ import (
 // BasicPermissionService 权限服务
 type BasicPermissionService struct {
 	permRepo basicRepo.PermissionRepo
+	sessions *basicBiz.LoginSessionUseCase
 }
 
 // NewBasicPermissionService 创建权限服务
-func NewBasicPermissionService(permRepo basicRepo.PermissionRepo) *BasicPermissionService {
-	return &BasicPermissionService{permRepo: permRepo}
+func NewBasicPermissionService(permRepo basicRepo.PermissionRepo, sessions *basicBiz.LoginSessionUseCase) *BasicPermissionService {
+	return &BasicPermissionService{permRepo: permRepo, sessions: sessions}
 }
 
 // RegisterRouter 注册路由
@@ -119,6 +123,8 @@ func (s *BasicPermissionService) CreatePermission(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "permission", permission.Id)
+	auditx.SetAfter(c.Request.Context(), permission)
 
 	response.Success(c, permission)
 }
@@ -158,6 +164,8 @@ func (s *BasicPermissionService) UpdatePermission(c *gin.Context) {
 		return
 	}
 
+	before := *existing
+
 	// 更新字段
 	existing.Name = req.Name
 	existing.Category = req.Category
@@ -167,6 +175,9 @@ func (s *BasicPermissionService) UpdatePermission(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "permission", existing.Id)
+	auditx.SetBefore(c.Request.Context(), &before)
+	auditx.SetAfter(c.Request.Context(), existing)
 
 	response.Success(c, nil)
 }
@@ -192,10 +203,21 @@ func (s *BasicPermissionService) DeletePermission(c *gin.Context) {
 		return
 	}
 
+	before, err := s.permRepo.GetByID(c.Request.Context(), req.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
 	if err := s.permRepo.Delete(c.Request.Context(), req.ID); err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "permission", req.ID)
+	auditx.SetBefore(c.Request.Context(), before)
+	// 软删除保留了角色关联，删除后仍能查到持有者
+	if s.sessions != nil {
+		_ = s.sessions.InvalidatePermission(c.Request.Context(), req.ID)
+	}
 
 	response.Success(c, nil)
 }
@@ -252,10 +274,15 @@ func (s *BasicPermissionService) AssignPermissionsToRole(c *gin.Context) {
 		return
 	}
 
+	before := s.rolePermCodes(c.Request.Context(), req.RoleID)
 	if err := s.permRepo.AssignPermissionsToRole(c.Request.Context(), req.RoleID, req.PermissionIDs); err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	s.auditRolePerms(c.Request.Context(), req.RoleID, before)
+	if s.sessions != nil {
+		_ = s.sessions.InvalidateRole(c.Request.Context(), req.RoleID)
+	}
 
 	response.Success(c, nil)
 }
@@ -282,10 +309,35 @@ func (s *BasicPermissionService) RemovePermissionsFromRole(c *gin.Context) {
 		return
 	}
 
+	before := s.rolePermCodes(c.Request.Context(), req.RoleID)
 	if err := s.permRepo.RemovePermissionsFromRole(c.Request.Context(), req.RoleID, req.PermissionIDs); err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	s.auditRolePerms(c.Request.Context(), req.RoleID, before)
+	if s.sessions != nil {
+		_ = s.sessions.InvalidateRole(c.Request.Context(), req.RoleID)
+	}
 
 	response.Success(c, nil)
 }
+
+// rolePermCodes 角色当前的权限码（审计用，查询失败时返回 nil）
+func (s *BasicPermissionService) rolePermCodes(ctx context.Context, roleID int32) []string {
+	perms, err := s.permRepo.GetRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil
+	}
+	codes := make([]string, 0, len(perms))
+	for _, p := range perms {
+		codes = append(codes, p.Code)
+	}
+	return codes
+}
+
+// auditRolePerms 记录角色权限变更前后的权限码
+func (s *BasicPermissionService) auditRolePerms(ctx context.Context, roleID int32, before []string) {
+	auditx.SetTarget(ctx, "role", roleID)
+	auditx.SetBefore(ctx, map[string]any{"perms": before})
+	auditx.SetAfter(ctx, map[string]any{"perms": s.rolePermCodes(ctx, roleID)})
+}
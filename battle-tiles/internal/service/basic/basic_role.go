@@ -1,22 +1,44 @@
 package basic
 
 import (
+	basicBiz "battle-tiles/internal/biz/basic"
 	"battle-tiles/internal/infra"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils/ecode"
 	"battle-tiles/pkg/utils/response"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // BasicRoleService 提供角色查询接口
 type BasicRoleService struct {
-	data *infra.Data
+	data     *infra.Data
+	sessions *basicBiz.LoginSessionUseCase
 }
 
-func NewBasicRoleService(data *infra.Data) *BasicRoleService {
-	return &BasicRoleService{data: data}
+func NewBasicRoleService(data *infra.Data, sessions *basicBiz.LoginSessionUseCase) *BasicRoleService {
+	return &BasicRoleService{data: data, sessions: sessions}
+}
+
+// invalidateRole 角色变更后令持有者的权限缓存与已签发 token 失效（失败不影响本次操作结果）
+func (s *BasicRoleService) invalidateRole(c *gin.Context, roleID int32) {
+	if s.sessions == nil {
+		return
+	}
+	_ = s.sessions.InvalidateRole(c.Request.Context(), roleID)
+}
+
+// roleSnapshot 审计用的角色快照（不存在或查询失败时返回 nil）
+func roleSnapshot(db *gorm.DB, roleID int32) map[string]any {
+	row := map[string]any{}
+	if err := db.Table("basic_role").Select("id, code, name, remark, enable, is_deleted").
+		Where("id = ?", roleID).Take(&row).Error; err != nil {
+		return nil
+	}
+	return row
 }
 
 func (s *BasicRoleService) RegisterRouter(root *gin.RouterGroup) {
@@ -229,6 +251,8 @@ func (s *BasicRoleService) Create(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "role", req.Code)
+	auditx.SetAfter(c.Request.Context(), role)
 
 	response.Success(c, role)
 }
@@ -283,6 +307,7 @@ func (s *BasicRoleService) Update(c *gin.Context) {
 		}
 	}
 
+	before := roleSnapshot(db, req.ID)
 	updates := map[string]interface{}{
 		"updated_at":   time.Now(),
 		"updated_user": updatedUser,
@@ -302,6 +327,12 @@ func (s *BasicRoleService) Update(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "role", req.ID)
+	auditx.SetBefore(c.Request.Context(), before)
+	auditx.SetAfter(c.Request.Context(), roleSnapshot(db, req.ID))
+	if req.Enable != nil {
+		s.invalidateRole(c, req.ID)
+	}
 
 	response.Success(c, nil)
 }
@@ -348,6 +379,7 @@ func (s *BasicRoleService) Delete(c *gin.Context) {
 		}
 	}
 
+	before := roleSnapshot(db, req.ID)
 	updates := map[string]interface{}{
 		"is_deleted":   true,
 		"updated_at":   time.Now(),
@@ -358,6 +390,9 @@ func (s *BasicRoleService) Delete(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "role", req.ID)
+	auditx.SetBefore(c.Request.Context(), before)
+	s.invalidateRole(c, req.ID)
 
 	response.Success(c, nil)
 }
@@ -428,6 +463,9 @@ func (s *BasicRoleService) AssignMenus(c *gin.Context) {
 		return
 	}
 
+	var beforeMenus []int32
+	_ = db.Table("basic_role_menu_rel").Where("role_id = ?", req.RoleID).Pluck("menu_id", &beforeMenus).Error
+
 	// 开启事务
 	tx := db.Begin()
 	defer func() {
@@ -463,6 +501,10 @@ func (s *BasicRoleService) AssignMenus(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	auditx.SetTarget(c.Request.Context(), "role", req.RoleID)
+	auditx.SetBefore(c.Request.Context(), map[string]any{"menu_ids": beforeMenus})
+	auditx.SetAfter(c.Request.Context(), map[string]any{"menu_ids": req.MenuIDs})
+	s.invalidateRole(c, req.RoleID)
 
 	response.Success(c, nil)
 }
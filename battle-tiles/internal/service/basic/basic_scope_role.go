@@ -0,0 +1,135 @@
+package basic
+
+import (
+	basicBiz "battle-tiles/internal/biz/basic"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BasicScopeRoleService 店铺/圈作用域角色授权
+type BasicScopeRoleService struct {
+	uc *basicBiz.UserScopeRoleUseCase
+}
+
+func NewBasicScopeRoleService(uc *basicBiz.UserScopeRoleUseCase) *BasicScopeRoleService {
+	return &BasicScopeRoleService{uc: uc}
+}
+
+func (s *BasicScopeRoleService) RegisterRouter(root *gin.RouterGroup) {
+	r := root.Group("/basic/scope-role").Use(middleware.JWTAuth())
+	r.GET("/mine", s.Mine)
+	r.GET("/list", middleware.RequireHousePerm("role:scope:view"), s.List)
+	r.POST("/grant", middleware.RequireHousePerm("role:scope:assign"), s.Grant)
+	r.POST("/revoke", middleware.RequireHousePerm("role:scope:assign"), s.Revoke)
+}
+
+// Mine 当前用户的作用域角色
+// @Summary      我的店铺角色
+// @Tags         基础管理/角色
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} response.Body
+// @Router       /basic/scope-role/mine [get]
+func (s *BasicScopeRoleService) Mine(c *gin.Context) {
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	out, err := s.uc.ListByUser(c.Request.Context(), claims.BaseClaims.UserID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// List 店铺下的作用域角色
+// @Summary      店铺角色授权列表
+// @Tags         基础管理/角色
+// @Security     BearerAuth
+// @Produce      json
+// @Param        house_gid query int true "店铺号"
+// @Success      200 {object} response.Body
+// @Router       /basic/scope-role/list [get]
+func (s *BasicScopeRoleService) List(c *gin.Context) {
+	houseGID, _ := middleware.ScopeHouseGID(c)
+	out, err := s.uc.ListByHouse(c.Request.Context(), houseGID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// Grant 授予作用域角色
+// @Summary      授予店铺角色
+// @Description  在店铺（可选：圈）范围内授予角色；店铺管理员只能授予 shop_admin / shop_operator
+// @Tags         基础管理/角色
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ScopeRoleRequest true "user_id, role_code, house_gid, group_id"
+// @Success      200 {object} response.Body
+// @Router       /basic/scope-role/grant [post]
+func (s *BasicScopeRoleService) Grant(c *gin.Context) {
+	var in req.ScopeRoleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.Grant(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), toScopeRoleGrant(c, in)); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Revoke 撤销作用域角色
+// @Summary      撤销店铺角色
+// @Tags         基础管理/角色
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ScopeRoleRequest true "user_id, role_code, house_gid, group_id"
+// @Success      200 {object} response.Body
+// @Router       /basic/scope-role/revoke [post]
+func (s *BasicScopeRoleService) Revoke(c *gin.Context) {
+	var in req.ScopeRoleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.Revoke(c.Request.Context(), claims.BaseClaims.IsSuperAdmin(), toScopeRoleGrant(c, in)); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// toScopeRoleGrant 店铺取 RequireHousePerm 鉴权过的 house_gid
+func toScopeRoleGrant(c *gin.Context, in req.ScopeRoleRequest) basicBiz.ScopeRoleGrant {
+	houseGID, _ := middleware.ScopeHouseGID(c)
+	return basicBiz.ScopeRoleGrant{
+		UserID:   in.UserID,
+		RoleID:   in.RoleID,
+		RoleCode: in.RoleCode,
+		HouseGID: houseGID,
+		GroupID:  in.GroupID,
+	}
+}
@@ -0,0 +1,272 @@
+package basic
+
+import (
+	basicBiz "battle-tiles/internal/biz/basic"
+	basicRepo "battle-tiles/internal/dal/repo/basic"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BasicSecurityService 账号安全：登录记录、TOTP 二次验证、登录锁定
+type BasicSecurityService struct {
+	uc *basicBiz.LoginSecurityUseCase
+}
+
+func NewBasicSecurityService(uc *basicBiz.LoginSecurityUseCase) *BasicSecurityService {
+	return &BasicSecurityService{uc: uc}
+}
+
+func (s *BasicSecurityService) RegisterRouter(root *gin.RouterGroup) {
+	r := root.Group("/basic/security").Use(middleware.JWTAuth())
+	// 本人
+	r.POST("/loginHistory/mine", s.MyLoginHistory)
+	r.GET("/totp/status", s.TOTPStatus)
+	r.POST("/totp/setup", s.TOTPSetup)
+	r.POST("/totp/enable", middleware.Audit("user:totp:enable"), s.TOTPEnable)
+	r.POST("/totp/disable", middleware.Audit("user:totp:disable"), s.TOTPDisable)
+	// 管理员
+	r.POST("/loginHistory/list", middleware.RequirePerm("login:history:view"), s.ListLoginHistory)
+	r.POST("/totp/reset", middleware.RequirePerm("user:security:manage"), s.TOTPReset)
+	r.POST("/unlock", middleware.RequirePerm("user:security:manage"), s.Unlock)
+}
+
+func loginHistoryFilter(in *req.ListLoginHistoryRequest) (basicRepo.LoginHistoryFilter, error) {
+	f := basicRepo.LoginHistoryFilter{
+		UserID:   in.UserID,
+		Username: in.Username,
+		ClientIP: in.ClientIP,
+		Success:  in.Success,
+	}
+	if in.StartAt != "" {
+		t, err := time.Parse(time.RFC3339, in.StartAt)
+		if err != nil {
+			return f, err
+		}
+		f.Start = &t
+	}
+	if in.EndAt != "" {
+		t, err := time.Parse(time.RFC3339, in.EndAt)
+		if err != nil {
+			return f, err
+		}
+		f.End = &t
+	}
+	return f, nil
+}
+
+func (s *BasicSecurityService) listHistory(c *gin.Context, in *req.ListLoginHistoryRequest, f basicRepo.LoginHistoryFilter) {
+	list, total, err := s.uc.ListHistory(c.Request.Context(), f, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	page, size := in.Page, in.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 200 {
+		size = 20
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": page, "page_size": size})
+}
+
+// MyLoginHistory 我的登录记录
+// @Summary      我的登录记录
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListLoginHistoryRequest true "过滤条件"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/loginHistory/mine [post]
+func (s *BasicSecurityService) MyLoginHistory(c *gin.Context) {
+	var in req.ListLoginHistoryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	f, err := loginHistoryFilter(&in)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	uid := claims.UserID
+	f.UserID, f.Username = &uid, ""
+	s.listHistory(c, &in, f)
+}
+
+// ListLoginHistory 登录记录（管理员）
+// @Summary      登录记录
+// @Description  按用户、登录名、IP、结果、时间范围检索登录尝试（含失败与锁定）
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListLoginHistoryRequest true "过滤条件"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/loginHistory/list [post]
+func (s *BasicSecurityService) ListLoginHistory(c *gin.Context) {
+	var in req.ListLoginHistoryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	f, err := loginHistoryFilter(&in)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	s.listHistory(c, &in, f)
+}
+
+// TOTPStatus 我的二次验证状态
+// @Summary      二次验证状态
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} response.Body
+// @Router       /basic/security/totp/status [get]
+func (s *BasicSecurityService) TOTPStatus(c *gin.Context) {
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	enabled, err := s.uc.TOTPEnabled(c.Request.Context(), claims.UserID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"enabled": enabled})
+}
+
+// TOTPSetup 生成二次验证密钥
+// @Summary      生成二次验证密钥
+// @Description  返回密钥与 otpauth 地址（前端渲染二维码）；需再调用 enable 提交动态码后才生效
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} response.Body
+// @Router       /basic/security/totp/setup [post]
+func (s *BasicSecurityService) TOTPSetup(c *gin.Context) {
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	secret, url, err := s.uc.TOTPSetup(c.Request.Context(), claims.UserID, claims.Username)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"secret": secret, "otpauth_url": url})
+}
+
+// TOTPEnable 启用二次验证
+// @Summary      启用二次验证
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.TOTPCodeRequest true "动态码"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/totp/enable [post]
+func (s *BasicSecurityService) TOTPEnable(c *gin.Context) {
+	var in req.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err = s.uc.TOTPEnable(c.Request.Context(), claims.UserID, in.Code); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// TOTPDisable 停用二次验证
+// @Summary      停用二次验证
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.TOTPCodeRequest true "动态码"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/totp/disable [post]
+func (s *BasicSecurityService) TOTPDisable(c *gin.Context) {
+	var in req.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err = s.uc.TOTPDisable(c.Request.Context(), claims.UserID, in.Code); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// TOTPReset 重置用户二次验证（管理员）
+// @Summary      重置用户二次验证
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.TOTPResetRequest true "用户"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/totp/reset [post]
+func (s *BasicSecurityService) TOTPReset(c *gin.Context) {
+	var in req.TOTPResetRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if err := s.uc.TOTPReset(c.Request.Context(), in.UserID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Unlock 解除登录锁定（管理员）
+// @Summary      解除登录锁定
+// @Tags         基础管理/账号安全
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        in body req.LoginUnlockRequest true "登录名"
+// @Success      200 {object} response.Body
+// @Router       /basic/security/unlock [post]
+func (s *BasicSecurityService) Unlock(c *gin.Context) {
+	var in req.LoginUnlockRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if err := s.uc.Unlock(c.Request.Context(), in.Username); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
@@ -30,13 +30,13 @@ func NewBasicUserService(uc *basicBiz.BasicUserUseCase, rbac *rbacstore.Store) *
 func (s *BasicUserService) RegisterRouter(rootRouter *gin.RouterGroup) {
 
 	privateRouter := rootRouter.Group("/basic/user").Use(middleware.JWTAuth())
-	privateRouter.POST("/addOne", s.AddOne)
+	privateRouter.POST("/addOne", middleware.Audit("user:create"), s.AddOne)
 	privateRouter.GET("/delOne", s.DelOne)
-	privateRouter.POST("/delMany", s.DelMany)
+	privateRouter.POST("/delMany", middleware.Audit("user:delete"), s.DelMany)
 	privateRouter.GET("/getOne", s.GetOne)
 	privateRouter.GET("/getList", s.GetList)
 	privateRouter.GET("/getOption", s.GetOption)
-	privateRouter.POST("/updateOne", s.UpdateOne)
+	privateRouter.POST("/updateOne", middleware.Audit("user:update"), s.UpdateOne)
 
 	// 新增：我的角色/权限查询
 	privateRouter.GET("/me/roles", s.MeRoles)
@@ -44,7 +44,7 @@ func (s *BasicUserService) RegisterRouter(rootRouter *gin.RouterGroup) {
 	// 新增：我的平台账号
 	privateRouter.GET("/me", s.Me)
 	// 新增：修改我的登录密码
-	privateRouter.POST("/changePassword", s.ChangePassword)
+	privateRouter.POST("/changePassword", middleware.Audit("user:password:change"), s.ChangePassword)
 }
 
 // ChangePasswordRequest 修改密码请求体
@@ -0,0 +1,182 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	model "battle-tiles/internal/dal/model/game"
+	repo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApplicationRuleService 申请自动处理规则、超时设置与处理记录
+type ApplicationRuleService struct {
+	uc *biz.ApplicationUseCase
+}
+
+func NewApplicationRuleService(uc *biz.ApplicationUseCase) *ApplicationRuleService {
+	return &ApplicationRuleService{uc: uc}
+}
+
+func (s *ApplicationRuleService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/shops/application-rules").Use(middleware.JWTAuth())
+	g.POST("/list", middleware.RequireHousePerm("shop:applications:view"), s.List)
+	g.POST("/save", middleware.RequireHousePerm("shop:applications:rules"), s.Save)
+	g.POST("/delete", middleware.RequireHousePerm("shop:applications:rules"), s.Delete)
+	g.POST("/expiry/set", middleware.RequireHousePerm("shop:applications:rules"), s.SetExpiry)
+	g.POST("/decisions/list", middleware.RequireHousePerm("shop:applications:view"), s.ListDecisions)
+}
+
+// List
+// @Summary      申请自动处理规则列表
+// @Tags         店铺/申请规则
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListApplicationRulesRequest true "house_gid"
+// @Success      200 {object} response.Body{data=[]game.GameApplicationRule}
+// @Router       /shops/application-rules/list [post]
+func (s *ApplicationRuleService) List(c *gin.Context) {
+	var in req.ListApplicationRulesRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	out, err := s.uc.ListRules(c.Request.Context(), in.HouseGID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// Save
+// @Summary      新建/修改申请自动处理规则
+// @Description  按 priority 升序匹配，命中第一条即执行。recommender_member：推荐人已是本店成员；blocklist：申请人游戏ID在 game_ids 内；any：无条件
+// @Tags         店铺/申请规则
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SaveApplicationRuleRequest true "规则"
+// @Success      200 {object} response.Body{data=game.GameApplicationRule}
+// @Router       /shops/application-rules/save [post]
+func (s *ApplicationRuleService) Save(c *gin.Context) {
+	var in req.SaveApplicationRuleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	rule := biz.ApplicationRuleInput{
+		HouseGID:  in.HouseGID,
+		Name:      in.Name,
+		Priority:  in.Priority,
+		Condition: in.Condition,
+		Action:    in.Action,
+		Params:    model.ApplicationRuleParams{GameIDs: in.GameIDs, ApplyTypes: in.ApplyTypes, Sources: in.Sources},
+		Enabled:   in.Enabled,
+	}
+	var out *model.GameApplicationRule
+	if in.ID > 0 {
+		out, err = s.uc.UpdateRule(c.Request.Context(), in.ID, rule)
+	} else {
+		out, err = s.uc.CreateRule(c.Request.Context(), claims.BaseClaims.UserID, rule)
+	}
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// Delete
+// @Summary      删除申请自动处理规则
+// @Tags         店铺/申请规则
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ApplicationRuleIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body
+// @Router       /shops/application-rules/delete [post]
+func (s *ApplicationRuleService) Delete(c *gin.Context) {
+	var in req.ApplicationRuleIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	if err := s.uc.DeleteRule(c.Request.Context(), in.HouseGID, in.ID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// SetExpiry
+// @Summary      设置待审申请过期时间
+// @Description  待审超过 hours 小时的申请自动拒绝（游戏内申请同时下发拒绝），记为 expiry；0 表示不过期
+// @Tags         店铺/申请规则
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SetApplicationExpiryRequest true "house_gid, hours"
+// @Success      200 {object} response.Body
+// @Router       /shops/application-rules/expiry/set [post]
+func (s *ApplicationRuleService) SetExpiry(c *gin.Context) {
+	var in req.SetApplicationExpiryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.SetExpireHours(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.Hours); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ListDecisions
+// @Summary      申请处理记录
+// @Description  手动、规则、超时三种决策，规则决策带 rule_id/rule_name
+// @Tags         店铺/申请规则
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListApplicationDecisionsRequest true "筛选条件"
+// @Success      200 {object} response.Body{data=[]game.GameShopApplicationLog}
+// @Router       /shops/application-rules/decisions/list [post]
+func (s *ApplicationRuleService) ListDecisions(c *gin.Context) {
+	var in req.ListApplicationDecisionsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	f := repo.ShopApplicationLogFilter{Source: in.Source, Decider: in.Decider, RuleID: in.RuleID}
+	if in.StartTime != nil {
+		t := time.Unix(*in.StartTime, 0)
+		f.Start = &t
+	}
+	if in.EndTime != nil {
+		t := time.Unix(*in.EndTime, 0)
+		f.End = &t
+	}
+	list, total, err := s.uc.ListDecisions(c.Request.Context(), in.HouseGID, f, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": normPage(in.Page), "page_size": normSize(in.PageSize)})
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BattleConfigService 店铺玩法管理（通过中控会话下发，结果以游戏端推送为准）
+type BattleConfigService struct {
+	uc *biz.BattleConfigUseCase
+}
+
+func NewBattleConfigService(uc *biz.BattleConfigUseCase) *BattleConfigService {
+	return &BattleConfigService{uc: uc}
+}
+
+func (s *BattleConfigService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/shops/battle-configs").Use(middleware.JWTAuth())
+	g.POST("/list", middleware.RequireHousePerm("shop:table:view"), s.List)
+	g.POST("/save", middleware.RequireHousePerm("shop:table:config"), s.Save)
+	g.POST("/delete", middleware.RequireHousePerm("shop:table:config"), s.Delete)
+}
+
+// List
+// @Summary      店铺玩法列表
+// @Description  来自中控会话推送的玩法快照（会话离线时返回最后一次落库结果）
+// @Tags         店铺/玩法
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListBattleConfigsRequest true "house_gid"
+// @Success      200 {object} response.Body{data=[]game.GameBattleConfig}
+// @Router       /shops/battle-configs/list [post]
+func (s *BattleConfigService) List(c *gin.Context) {
+	var in req.ListBattleConfigsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	list, err := s.uc.List(c.Request.Context(), in.HouseGID, in.IncludeRemoved)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, list)
+}
+
+// Save
+// @Summary      添加/修改玩法
+// @Description  需要该店铺有在线中控会话；命令入队后立即返回，游戏端推送变更后列表更新
+// @Tags         店铺/玩法
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SaveBattleConfigRequest true "玩法"
+// @Success      200 {object} response.Body
+// @Router       /shops/battle-configs/save [post]
+func (s *BattleConfigService) Save(c *gin.Context) {
+	var in req.SaveBattleConfigRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	input := biz.BattleConfigInput{
+		ConfigID:    in.ConfigID,
+		KindID:      in.KindID,
+		BaseScore:   in.BaseScore,
+		PlayCount:   in.PlayCount,
+		PlayerCount: in.PlayerCount,
+		Name:        in.Name,
+	}
+	if in.ConfigID > 0 {
+		err = s.uc.Modify(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, input)
+	} else {
+		err = s.uc.Append(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, input)
+	}
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.SuccessWithOK(c)
+}
+
+// Delete
+// @Summary      删除玩法
+// @Description  需要该店铺有在线中控会话；已关联该玩法的历史战绩保留关联
+// @Tags         店铺/玩法
+// @Accept       json
+// @Produce      json
+// @Param        in body req.DeleteBattleConfigRequest true "house_gid, config_id"
+// @Success      200 {object} response.Body
+// @Router       /shops/battle-configs/delete [post]
+func (s *BattleConfigService) Delete(c *gin.Context) {
+	var in req.DeleteBattleConfigRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.Delete(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.ConfigID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.SuccessWithOK(c)
+}
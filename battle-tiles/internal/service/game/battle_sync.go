@@ -0,0 +1,77 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BattleSyncService 战绩同步运维：按日期回填、查看高水位
+type BattleSyncService struct {
+	mgr *biz.BattleSyncManager
+}
+
+func NewBattleSyncService(mgr *biz.BattleSyncManager) *BattleSyncService {
+	return &BattleSyncService{mgr: mgr}
+}
+
+func (s *BattleSyncService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/battle-sync").Use(middleware.JWTAuth())
+	g.POST("/backfill", middleware.RequirePerm("battles:backfill"), s.Backfill)
+	g.POST("/watermark", middleware.RequirePerm("battles:backfill"), s.Watermark)
+}
+
+// Backfill
+// @Summary      回填店铺战绩
+// @Description  通过 HTTP 接口逐页拉取区间内全部战绩并去重入库；拉取失败的页记为缺口写入 game_sync_log（battle_backfill）
+// @Tags         战绩同步
+// @Accept       json
+// @Produce      json
+// @Param        in body req.BattleBackfillRequest true "house_gid, 日期范围"
+// @Success      200 {object} response.Body{data=game.BattleBackfillResult}
+// @Router       /battle-sync/backfill [post]
+func (s *BattleSyncService) Backfill(c *gin.Context) {
+	var in req.BattleBackfillRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	start, err := time.ParseInLocation(time.DateOnly, in.StartDate, time.Local)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	end, err := time.ParseInLocation(time.DateOnly, in.EndDate, time.Local)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	res, err := s.mgr.Backfill(c.Request.Context(), in.HouseGID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, res)
+}
+
+// Watermark
+// @Summary      店铺战绩同步高水位
+// @Tags         战绩同步
+// @Accept       json
+// @Produce      json
+// @Param        in body req.BattleWatermarkRequest true "house_gid"
+// @Success      200 {object} response.Body{data=game.GameBattleWatermark}
+// @Router       /battle-sync/watermark [post]
+func (s *BattleSyncService) Watermark(c *gin.Context) {
+	var in req.BattleWatermarkRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	response.Success(c, s.mgr.Watermark(c.Request.Context(), in.HouseGID))
+}
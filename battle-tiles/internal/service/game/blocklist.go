@@ -0,0 +1,200 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	model "battle-tiles/internal/dal/model/game"
+	gameRepo "battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlocklistService 平台黑名单：平台管理（/blocklist）与店铺上报（/shops/blocklist）
+type BlocklistService struct {
+	uc *biz.BlocklistUseCase
+}
+
+func NewBlocklistService(uc *biz.BlocklistUseCase) *BlocklistService {
+	return &BlocklistService{uc: uc}
+}
+
+func (s *BlocklistService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/blocklist").Use(middleware.JWTAuth())
+	g.POST("/list", middleware.RequirePerm("blocklist:view"), s.List)
+	g.POST("/add", middleware.RequirePerm("blocklist:manage"), s.Add)
+	g.POST("/remove", middleware.RequirePerm("blocklist:manage"), s.Remove)
+
+	h := r.Group("/shops/blocklist").Use(middleware.JWTAuth())
+	h.POST("/list", middleware.RequireHousePerm("shop:member:view"), s.ListReported)
+	h.POST("/report", middleware.RequireHousePerm("shop:member:kick"), s.Report)
+	h.POST("/remove", middleware.RequireHousePerm("shop:member:kick"), s.RemoveReported)
+}
+
+func parseExpireAt(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List
+// @Summary      平台黑名单列表
+// @Description  默认只返回未过期记录；house_gid 按上报店铺过滤
+// @Tags         平台/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListBlocklistRequest true "过滤条件"
+// @Success      200 {object} response.Body{data=[]game.GameBlocklist}
+// @Router       /blocklist/list [post]
+func (s *BlocklistService) List(c *gin.Context) {
+	var in req.ListBlocklistRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	s.list(c, in)
+}
+
+// ListReported
+// @Summary      本店上报的黑名单
+// @Tags         店铺/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListBlocklistRequest true "house_gid 必填"
+// @Success      200 {object} response.Body{data=[]game.GameBlocklist}
+// @Router       /shops/blocklist/list [post]
+func (s *BlocklistService) ListReported(c *gin.Context) {
+	var in req.ListBlocklistRequest
+	if err := c.ShouldBindJSON(&in); err != nil || in.HouseGID <= 0 {
+		response.Fail(c, ecode.ParamsFailed, "house_gid required")
+		return
+	}
+	s.list(c, in)
+}
+
+func (s *BlocklistService) list(c *gin.Context, in req.ListBlocklistRequest) {
+	list, total, err := s.uc.List(c.Request.Context(), gameRepo.BlocklistFilter{
+		Kind:             in.Kind,
+		Value:            in.Value,
+		ReportedHouseGID: in.HouseGID,
+		IncludeExpired:   in.IncludeExpired,
+	}, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": normPage(in.Page), "page_size": normSize(in.PageSize)})
+}
+
+// Add
+// @Summary      加入平台黑名单
+// @Description  同一 kind+value 重复添加会覆盖原因/过期时间，上报店铺不变；命中后：申请自动拒绝、禁止拉入圈子与绑定账号、游戏ID落座即踢
+// @Tags         平台/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.AddBlocklistRequest true "黑名单"
+// @Success      200 {object} response.Body{data=game.GameBlocklist}
+// @Router       /blocklist/add [post]
+func (s *BlocklistService) Add(c *gin.Context) {
+	var in req.AddBlocklistRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	s.add(c, s.uc.Add, in.HouseGID, in.Kind, in.Value, in.Reason, in.ExpireAt)
+}
+
+// Report
+// @Summary      店铺上报黑名单
+// @Description  上报后全平台生效，记录上报店铺；已由其他店铺上报的标识不能重复上报
+// @Tags         店铺/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ReportBlocklistRequest true "黑名单"
+// @Success      200 {object} response.Body{data=game.GameBlocklist}
+// @Router       /shops/blocklist/report [post]
+func (s *BlocklistService) Report(c *gin.Context) {
+	var in req.ReportBlocklistRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	s.add(c, s.uc.Report, in.HouseGID, in.Kind, in.Value, in.Reason, in.ExpireAt)
+}
+
+func (s *BlocklistService) add(c *gin.Context, save func(context.Context, int32, biz.BlocklistInput) (*model.GameBlocklist, error), houseGID int32, kind, value, reason, expireAt string) {
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	exp, err := parseExpireAt(expireAt)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	m, err := save(c.Request.Context(), claims.BaseClaims.UserID, biz.BlocklistInput{
+		Kind:             kind,
+		Value:            value,
+		Reason:           reason,
+		ReportedHouseGID: houseGID,
+		ExpireAt:         exp,
+	})
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, m)
+}
+
+// Remove
+// @Summary      移出平台黑名单
+// @Tags         平台/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.BlocklistIDRequest true "id"
+// @Success      200 {object} response.Body
+// @Router       /blocklist/remove [post]
+func (s *BlocklistService) Remove(c *gin.Context) {
+	var in req.BlocklistIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if err := s.uc.Remove(c.Request.Context(), 0, in.ID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// RemoveReported
+// @Summary      撤回本店上报的黑名单
+// @Tags         店铺/黑名单
+// @Accept       json
+// @Produce      json
+// @Param        in body req.BlocklistIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body
+// @Router       /shops/blocklist/remove [post]
+func (s *BlocklistService) RemoveReported(c *gin.Context) {
+	var in req.BlocklistIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil || in.HouseGID <= 0 {
+		response.Fail(c, ecode.ParamsFailed, "house_gid required")
+		return
+	}
+	if err := s.uc.Remove(c.Request.Context(), in.HouseGID, in.ID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
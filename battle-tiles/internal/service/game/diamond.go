@@ -0,0 +1,116 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DiamondService 中控钻石余额：当前余额与消耗推算、余额序列、告警线
+type DiamondService struct {
+	uc *biz.DiamondUseCase
+}
+
+func NewDiamondService(uc *biz.DiamondUseCase) *DiamondService {
+	return &DiamondService{uc: uc}
+}
+
+func (s *DiamondService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/shops/diamond").Use(middleware.JWTAuth())
+	g.POST("/status", middleware.RequireHousePerm("shop:member:view"), s.Status)
+	g.POST("/series", middleware.RequireHousePerm("shop:member:view"), s.Series)
+	g.POST("/threshold/set", middleware.RequireHousePerm("shop:member:update"), s.SetThreshold)
+}
+
+// Status
+// @Summary      钻石余额与消耗推算
+// @Description  余额取最近一次查询回包/财富推送；按最近 24 小时余额下降量与开桌数推算每桌消耗、剩余可开桌数与可撑小时数（无消耗数据时为空）
+// @Tags         店铺/钻石
+// @Accept       json
+// @Produce      json
+// @Param        in body req.DiamondStatusRequest true "house_gid"
+// @Success      200 {object} response.Body{data=game.DiamondProjection}
+// @Router       /shops/diamond/status [post]
+func (s *DiamondService) Status(c *gin.Context) {
+	var in req.DiamondStatusRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if in.Refresh {
+		if err := s.uc.Refresh(utils.GetUserID(c), in.HouseGID); err != nil {
+			response.Fail(c, ecode.Failed, err)
+			return
+		}
+	}
+	p, err := s.uc.Status(c.Request.Context(), in.HouseGID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Fail(c, ecode.Failed, "no diamond balance recorded yet")
+		return
+	}
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, p)
+}
+
+// Series
+// @Summary      钻石余额时间序列
+// @Tags         店铺/钻石
+// @Accept       json
+// @Produce      json
+// @Param        in body req.DiamondSeriesRequest true "house_gid, 时间范围"
+// @Success      200 {object} response.Body{data=[]game.GameDiamondSnapshot}
+// @Router       /shops/diamond/series [post]
+func (s *DiamondService) Series(c *gin.Context) {
+	var in req.DiamondSeriesRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	end := time.Now()
+	if in.EndTime != nil {
+		end = time.Unix(*in.EndTime, 0)
+	}
+	start := end.Add(-24 * time.Hour)
+	if in.StartTime != nil {
+		start = time.Unix(*in.StartTime, 0)
+	}
+	list, err := s.uc.Series(c.Request.Context(), in.HouseGID, in.CtrlAccountID, start, end)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, list)
+}
+
+// SetThreshold
+// @Summary      设置钻石告警线
+// @Description  中控钻石余额跌到告警线（含）以下时发布一次 house.diamond_low（webhook 可订阅），回到告警线以上后重新计数；0 关闭
+// @Tags         店铺/钻石
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SetDiamondThresholdRequest true "告警线"
+// @Success      200 {object} response.Body
+// @Router       /shops/diamond/threshold/set [post]
+func (s *DiamondService) SetThreshold(c *gin.Context) {
+	var in req.SetDiamondThresholdRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if err := s.uc.SetThreshold(c.Request.Context(), utils.GetUserID(c), in.HouseGID, in.Threshold); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
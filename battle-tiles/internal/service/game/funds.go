@@ -5,6 +5,7 @@ import (
 	"battle-tiles/internal/dal/req"
 	resp "battle-tiles/internal/dal/resp"
 	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
@@ -27,23 +28,23 @@ func NewFundsService(uc *biz.FundsUseCase, mgr plaza.Manager) *FundsService {
 }
 
 func (s *FundsService) RegisterRouter(r *gin.RouterGroup) {
-	g := r.Group("/members").Use(middleware.JWTAuth())
+	g := r.Group("/members").Use(middleware.JWTOrAPIKeyAuth())
 
-	g.POST("/credit/deposit", middleware.RequirePerm("fund:deposit"), s.Deposit)
-	g.POST("/credit/withdraw", middleware.RequirePerm("fund:withdraw"), s.Withdraw)
-	g.POST("/credit/force_withdraw", middleware.RequirePerm("fund:force_withdraw"), s.ForceWithdraw)
-	g.PATCH("/limit", middleware.RequirePerm("fund:limit:update"), s.UpdateLimit)
+	g.POST("/credit/deposit", middleware.RequireHousePerm("fund:deposit"), s.Deposit)
+	g.POST("/credit/withdraw", middleware.RequireHousePerm("fund:withdraw"), s.Withdraw)
+	g.POST("/credit/force_withdraw", middleware.RequireHousePerm("fund:force_withdraw"), s.ForceWithdraw)
+	g.PATCH("/limit", middleware.RequireHousePerm("fund:limit:update"), s.UpdateLimit)
 }
 
 // ---- House settings APIs (fees/share_fee/push_credit) ----
 // 为保持聚合度，将它们放在 /shops 下更合适，但当前文件已引入资金相关 req/resp，复用即可
 func (s *FundsService) registerHouseSettings(r *gin.RouterGroup, hs *HouseSettingsService) {
 	shops := r.Group("/shops").Use(middleware.JWTAuth())
-	shops.POST("/fees/set", middleware.RequirePerm("shop:fees:write"), hs.SetFees)
-	shops.POST("/fees/get", middleware.RequirePerm("shop:fees:view"), hs.Get)
-	shops.POST("/sharefee/set", middleware.RequirePerm("shop:sharefee:write"), hs.SetShare)
-	shops.POST("/pushcredit/set", middleware.RequirePerm("shop:pushcredit:write"), hs.SetPushCredit)
-	shops.POST("/pushcredit/get", middleware.RequirePerm("shop:pushcredit:view"), hs.Get)
+	shops.POST("/fees/set", middleware.RequireHousePerm("shop:fees:write"), hs.SetFees)
+	shops.POST("/fees/get", middleware.RequireHousePerm("shop:fees:view"), hs.Get)
+	shops.POST("/sharefee/set", middleware.RequireHousePerm("shop:sharefee:write"), hs.SetShare)
+	shops.POST("/pushcredit/set", middleware.RequireHousePerm("shop:pushcredit:write"), hs.SetPushCredit)
+	shops.POST("/pushcredit/get", middleware.RequireHousePerm("shop:pushcredit:view"), hs.Get)
 }
 
 // Deposit
@@ -62,6 +63,7 @@ func (s *FundsService) Deposit(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	// 可选：biz_no 兜底
 	if len(in.BizNo) == 0 || len(in.BizNo) > 64 {
 		response.Fail(c, ecode.ParamsFailed, "invalid biz_no")
@@ -100,6 +102,7 @@ func (s *FundsService) Withdraw(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	// 可选：biz_no 兜底
 	if len(in.BizNo) == 0 || len(in.BizNo) > 64 {
 		response.Fail(c, ecode.ParamsFailed, "invalid biz_no")
@@ -143,6 +146,7 @@ func (s *FundsService) ForceWithdraw(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	// 可选：biz_no 兜底
 	if len(in.BizNo) == 0 || len(in.BizNo) > 64 {
 		response.Fail(c, ecode.ParamsFailed, "invalid biz_no")
@@ -181,30 +185,35 @@ func (s *FundsService) UpdateLimit(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	claims, err := utils.GetClaims(c)
 	if err != nil {
 		response.Fail(c, ecode.TokenValidateFailed, err)
 		return
 	}
 
-	w, err := s.uc.UpdateLimit(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.MemberID, in.LimitMin, in.Forbid, in.Reason)
+	ctx := c.Request.Context()
+	w, forbidChanged, err := s.uc.UpdateLimit(ctx, claims.BaseClaims.UserID, in.HouseGID, in.MemberID, in.LimitMin, in.Forbid, in.Reason)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
 
-	// 只有在传入 forbid 且「确实发生变化」时才下发游戏端禁/解指令
-	if in.Forbid != nil && *in.Forbid != w.Forbid {
+	// 只有禁分状态「确实发生变化」时才下发游戏端禁/解指令，结果一并记入审计
+	if forbidChanged {
+		after := map[string]any{"limit_min": w.LimitMin, "forbid": w.Forbid, "game_synced": false}
+		defer auditx.SetAfter(ctx, after)
 		if sess, ok := s.mgr.Get(int(claims.BaseClaims.UserID), int(in.HouseGID)); !ok || sess == nil {
 			c.JSON(http.StatusConflict, response.Body{Code: ecode.Failed, Msg: "session not found or not online for this house"})
 			return
 		}
 		key := fmt.Sprintf("limit-%d-%d-%d", in.HouseGID, in.MemberID, time.Now().UnixNano())
-		if err := s.mgr.ForbidMembers(int(claims.BaseClaims.UserID), int(in.HouseGID), key, []int{int(in.MemberID)}, *in.Forbid); err != nil {
+		if err := s.mgr.ForbidMembers(int(claims.BaseClaims.UserID), int(in.HouseGID), key, []int{int(in.MemberID)}, w.Forbid); err != nil {
 			// 失败按文档返回 409；此处不回滚 DB，保持“以库为准”
 			c.JSON(http.StatusConflict, response.Body{Code: ecode.Failed, Msg: "push forbid to game failed: " + err.Error()})
 			return
 		}
+		after["game_synced"] = true
 	}
 
 	response.Success(c, resp.FundsLimitResponse{
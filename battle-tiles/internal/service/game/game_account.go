@@ -27,14 +27,14 @@ func (s *AccountService) RegisterRouter(r *gin.RouterGroup) {
 
 	// 以下接口需要 JWT 认证
 	auth := g.Use(middleware.JWTAuth())
-	auth.POST("/accounts", s.BindMyAccount)               // 仅 1 条（只建 game_account）
-	auth.GET("/accounts/me", s.GetMyAccount)              // 查询我的账号
-	auth.GET("/accounts/me/houses", s.GetMyAccountHouses) // 查询我的账号绑定的店铺游戏ID
-	auth.DELETE("/accounts/me", s.DeleteMyAccount)        // 解绑我的账号
+	auth.POST("/accounts", middleware.Audit("game:account:bind"), s.BindMyAccount)          // 仅 1 条（只建 game_account）
+	auth.GET("/accounts/me", s.GetMyAccount)                                                // 查询我的账号
+	auth.GET("/accounts/me/houses", s.GetMyAccountHouses)                                   // 查询我的账号绑定的店铺游戏ID
+	auth.DELETE("/accounts/me", middleware.Audit("game:account:unbind"), s.DeleteMyAccount) // 解绑我的账号
 
 	// 管理员接口
 	admin := g.Use(middleware.JWTAuth(), middleware.AdminOnly())
-	admin.POST("/accounts/fix-empty-game-user-id", s.FixEmptyGameUserID) // 修复空的 game_user_id
+	admin.POST("/accounts/fix-empty-game-user-id", middleware.Audit("game:account:fix"), s.FixEmptyGameUserID) // 修复空的 game_user_id
 }
 
 // VerifyAccount
@@ -73,7 +73,7 @@ func (s *AccountService) VerifyAccount(c *gin.Context) {
 
 // BindMyAccount
 // @Summary     绑定“我的”游戏账号（仅允许 1 条）
-// @Description 普通用户仅能绑定1个游戏账号（DB 触发器兜底）；管理员不受限
+// @Description 普通用户仅能绑定1个游戏账号（DB 触发器兜底）；管理员不受限。平台用户、游戏ID、设备（X-Device-Id）、IP 命中平台黑名单时拒绝
 // @Tags        游戏/我的账号
 // @Accept      json
 // @Produce     json
@@ -106,7 +106,8 @@ func (s *AccountService) BindMyAccount(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, "invalid mode")
 		return
 	}
-	acc, err := s.uc.BindSingle(c.Request.Context(), claims.BaseClaims.UserID, mode, in.Account, in.PwdMD5, in.Nickname)
+	client := gameBiz.BlocklistSubject{Device: c.GetHeader("X-Device-Id"), IP: c.ClientIP()}
+	acc, err := s.uc.BindSingle(c.Request.Context(), claims.BaseClaims.UserID, mode, in.Account, in.PwdMD5, in.Nickname, client)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
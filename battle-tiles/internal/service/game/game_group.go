@@ -4,6 +4,7 @@ package game
 import (
 	"battle-tiles/internal/dal/req"
 	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
@@ -86,6 +87,11 @@ func (s *GameGroupService) doForbid(c *gin.Context, forbid bool) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	ctx := c.Request.Context()
+	auditx.SetHouse(ctx, int32(in.HouseGID))
+	auditx.SetTarget(ctx, "game_member", intsToAny(members)...)
+	auditx.SetBefore(ctx, map[string]any{"forbid": !forbid})
+	auditx.SetAfter(ctx, map[string]any{"forbid": forbid, "key": in.Key})
 	response.SuccessWithOK(c)
 }
 
@@ -116,9 +122,17 @@ func (s *GameGroupService) Delete(c *gin.Context) {
 		return
 	}
 	ms := sess.ListMembers()
+	kicked := make([]int, 0, len(ms))
 	for _, m := range ms {
-		_ = s.mgr.KickMember(actor, in.HouseGID, int(m.MemberID))
+		if err := s.mgr.KickMember(actor, in.HouseGID, int(m.MemberID)); err == nil {
+			kicked = append(kicked, int(m.MemberID))
+		}
 	}
+	ctx := c.Request.Context()
+	auditx.SetHouse(ctx, int32(in.HouseGID))
+	auditx.SetTarget(ctx, "game_member", intsToAny(kicked)...)
+	auditx.SetBefore(ctx, map[string]any{"members": len(ms)})
+	auditx.SetAfter(ctx, map[string]any{"kicked": len(kicked)})
 	response.SuccessWithOK(c)
 }
 
@@ -152,7 +166,14 @@ func (s *GameGroupService) Bind(c *gin.Context) {
 		response.Fail(c, ecode.Failed, "application message not found")
 		return
 	}
-	sess.RespondApplication(ai, true)
+	if err := sess.RespondApplication(ai, true); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	ctx := c.Request.Context()
+	auditx.SetHouse(ctx, int32(in.HouseGID))
+	auditx.SetTarget(ctx, "application", in.MessageID)
+	auditx.SetAfter(ctx, map[string]any{"action": "approved"})
 	response.SuccessWithOK(c)
 }
 
@@ -180,3 +201,11 @@ func (s *GameGroupService) Unbind(c *gin.Context) {
 	s.mgr.StopUser(actor, in.HouseGID)
 	response.SuccessWithOK(c)
 }
+
+func intsToAny(ids []int) []any {
+	out := make([]any, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id)
+	}
+	return out
+}
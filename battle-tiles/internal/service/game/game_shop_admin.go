@@ -45,15 +45,15 @@ func NewHouseSettingsService(uc *gameBiz.HouseSettingsUseCase) *HouseSettingsSer
 // RegisterRouter
 func (s *HouseSettingsService) RegisterRouter(r *gin.RouterGroup) {
 	g := r.Group("/shops").Use(middleware.JWTAuth())
-	g.POST("/fees/get", middleware.RequirePerm("shop:fees:view"), s.Get)
-	g.POST("/fees/set", middleware.RequirePerm("shop:fees:write"), s.SetFees)
-	g.POST("/sharefee/set", middleware.RequirePerm("shop:sharefee:write"), s.SetShare)
-	g.POST("/pushcredit/get", middleware.RequirePerm("shop:pushcredit:view"), s.Get)
-	g.POST("/pushcredit/set", middleware.RequirePerm("shop:pushcredit:write"), s.SetPushCredit)
+	g.POST("/fees/get", middleware.RequireHousePerm("shop:fees:view"), s.Get)
+	g.POST("/fees/set", middleware.RequireHousePerm("shop:fees:write"), s.SetFees)
+	g.POST("/sharefee/set", middleware.RequireHousePerm("shop:sharefee:write"), s.SetShare)
+	g.POST("/pushcredit/get", middleware.RequireHousePerm("shop:pushcredit:view"), s.Get)
+	g.POST("/pushcredit/set", middleware.RequireHousePerm("shop:pushcredit:write"), s.SetPushCredit)
 	// 费用结算（基础）
-	g.POST("/fees/settle/insert", middleware.RequirePerm("shop:fees:write"), s.InsertFeeSettle)
-	g.POST("/fees/settle/sum", middleware.RequirePerm("shop:fees:view"), s.SumFeeSettle)
-	g.POST("/fees/settle/payoffs", middleware.RequirePerm("shop:fees:view"), s.ListGroupPayoffs)
+	g.POST("/fees/settle/insert", middleware.RequireHousePerm("shop:fees:write"), s.InsertFeeSettle)
+	g.POST("/fees/settle/sum", middleware.RequireHousePerm("shop:fees:view"), s.SumFeeSettle)
+	g.POST("/fees/settle/payoffs", middleware.RequireHousePerm("shop:fees:view"), s.ListGroupPayoffs)
 }
 
 // Get
@@ -72,6 +72,7 @@ func (s *HouseSettingsService) Get(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	m, err := s.uc.Get(c.Request.Context(), in.HouseGID)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -87,6 +88,7 @@ func (s *HouseSettingsService) SetFees(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	if err := validateFeesJSON(in.FeesJSON); err != nil {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
@@ -106,6 +108,7 @@ func (s *HouseSettingsService) SetShare(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	uid := utils.GetUserID(c)
 	if err := s.uc.SetShareFee(c.Request.Context(), uid, in.HouseGID, in.Share); err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -121,6 +124,7 @@ func (s *HouseSettingsService) SetPushCredit(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	uid := utils.GetUserID(c)
 	if err := s.uc.SetPushCredit(c.Request.Context(), uid, in.HouseGID, in.Credit); err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -136,6 +140,7 @@ func (s *HouseSettingsService) InsertFeeSettle(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	t, err := time.Parse(time.RFC3339, in.FeedAt)
 	if err != nil {
 		response.Fail(c, ecode.ParamsFailed, err)
@@ -155,6 +160,7 @@ func (s *HouseSettingsService) SumFeeSettle(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	start, err := time.Parse(time.RFC3339, in.StartAt)
 	if err != nil {
 		response.Fail(c, ecode.ParamsFailed, err)
@@ -180,6 +186,7 @@ func (s *HouseSettingsService) ListGroupPayoffs(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	start, err := time.Parse(time.RFC3339, in.StartAt)
 	if err != nil {
 		response.Fail(c, ecode.ParamsFailed, err)
@@ -1,12 +1,14 @@
 package game
 
 import (
+	biz "battle-tiles/internal/biz/game"
 	gameModel "battle-tiles/internal/dal/model/game"
 	basicRepo "battle-tiles/internal/dal/repo/basic"
 	gameRepo "battle-tiles/internal/dal/repo/game"
 	"battle-tiles/internal/dal/req"
 	"battle-tiles/internal/dal/resp"
 	"battle-tiles/internal/infra/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
@@ -23,19 +25,20 @@ type ShopApplicationService struct {
 	users    basicRepo.BasicUserRepo
 	auth     basicRepo.AuthRepo
 	sAdm     gameRepo.GameShopAdminRepo
-	logRepo  gameRepo.ShopApplicationLogRepo // 游戏内申请日志
+	apps     *biz.ApplicationUseCase // 决策（含自动规则）与处理记录
 }
 
-func NewShopApplicationService(mgr plaza.Manager, userRepo gameRepo.UserApplicationRepo, users basicRepo.BasicUserRepo, auth basicRepo.AuthRepo, sAdm gameRepo.GameShopAdminRepo, logRepo gameRepo.ShopApplicationLogRepo) *ShopApplicationService {
-	return &ShopApplicationService{mgr: mgr, userRepo: userRepo, users: users, auth: auth, sAdm: sAdm, logRepo: logRepo}
+func NewShopApplicationService(mgr plaza.Manager, userRepo gameRepo.UserApplicationRepo, users basicRepo.BasicUserRepo, auth basicRepo.AuthRepo, sAdm gameRepo.GameShopAdminRepo, apps *biz.ApplicationUseCase) *ShopApplicationService {
+	return &ShopApplicationService{mgr: mgr, userRepo: userRepo, users: users, auth: auth, sAdm: sAdm, apps: apps}
 }
 
 func (s *ShopApplicationService) RegisterRouter(r *gin.RouterGroup) {
 	// ============ 游戏内申请功能（新）============
 	g := r.Group("/shops/game-applications").Use(middleware.JWTAuth())
-	g.POST("/list", middleware.RequirePerm("shop:applications:view"), s.ListGameApplications)
-	g.POST("/approve", middleware.RequirePerm("shop:applications:approve"), s.ApproveGameApplication)
-	g.POST("/reject", middleware.RequirePerm("shop:applications:reject"), s.RejectGameApplication)
+	g.POST("/list", middleware.RequireHousePerm("shop:applications:view"), s.ListGameApplications)
+	g.POST("/approve", middleware.RequireHousePerm("shop:applications:approve"), s.ApproveGameApplication)
+	g.POST("/reject", middleware.RequireHousePerm("shop:applications:reject"), s.RejectGameApplication)
+	g.POST("/bulk", middleware.RequireHousePerm(), s.BulkDecide) // 按 source/agree 在 handler 内校验权限
 
 	// ============ 旧的管理员申请功能（已废弃）============
 	// 注释：入圈申请和管理员申请功能已废弃（2025-11-19）
@@ -300,7 +303,10 @@ func (s *ShopApplicationService) respond(c *gin.Context, agree bool) {
 		}
 		if ai, ok := sess.FindApplicationByID(in.ID); ok && ai != nil {
 			// 下发审批指令（同意/拒绝）
-			sess.RespondApplication(ai, agree)
+			if err := sess.RespondApplication(ai, agree); err != nil {
+				c.JSON(http.StatusConflict, response.Body{Code: ecode.Failed, Msg: err.Error()})
+				return
+			}
 
 			// 通过之后：申请人成为管理员，还需要主动对某个店铺“值班”（启动会话）后，才能做后续操作。
 			if agree && ai.ApplyType == 1 { // 管理员申请
@@ -427,6 +433,7 @@ func (s *ShopApplicationService) ApplyAdmin(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	s.apps.OnPlatformApplication(c.Request.Context(), app)
 	response.SuccessWithOK(c)
 }
 
@@ -443,11 +450,12 @@ func (s *ShopApplicationService) ApplyJoin(c *gin.Context) {
 		c.JSON(http.StatusConflict, response.Body{Code: ecode.Failed, Msg: "duplicate pending join application"})
 		return
 	}
-	app := &gameModel.UserApplication{HouseGID: in.HouseGID, Applicant: uid, Type: 2, AdminUID: in.AdminUserID, Note: in.Note, Status: 0}
+	app := &gameModel.UserApplication{HouseGID: in.HouseGID, Applicant: uid, Type: 2, AdminUID: in.AdminUserID, Note: in.Note, Recommender: in.Recommender, Status: 0}
 	if err := s.userRepo.Insert(c.Request.Context(), app); err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	s.apps.OnPlatformApplication(c.Request.Context(), app)
 	response.SuccessWithOK(c)
 }
 
@@ -470,6 +478,7 @@ func (s *ShopApplicationService) ListGameApplications(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 
 	claims, err := utils.GetClaims(c)
 	if err != nil {
@@ -543,6 +552,7 @@ func (s *ShopApplicationService) respondGameApplication(c *gin.Context, agree bo
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 
 	claims, err := utils.GetClaims(c)
 	if err != nil {
@@ -550,43 +560,64 @@ func (s *ShopApplicationService) respondGameApplication(c *gin.Context, agree bo
 		return
 	}
 
-	// 获取 plaza session
-	sess, ok := s.mgr.Get(int(claims.BaseClaims.UserID), int(in.HouseGID))
-	if !ok {
-		response.Fail(c, ecode.Failed, "会话不存在，请先登录游戏")
+	res, err := s.apps.Decide(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, gameModel.ApplicationSourceGame, []int32{int32(in.MessageID)}, agree, "")
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
 		return
 	}
-
-	// 从内存查找申请信息
-	applyInfo, ok := sess.FindApplicationByID(in.MessageID)
-	if !ok {
-		response.Fail(c, ecode.Failed, "申请信息不存在或已过期")
+	if len(res) > 0 && !res[0].OK {
+		response.Fail(c, ecode.Failed, res[0].Error)
 		return
 	}
+	response.SuccessWithOK(c)
+}
 
-	// 发送响应命令到游戏服务器
-	sess.RespondApplication(applyInfo, agree)
-
-	// 记录操作日志到数据库（异步，不影响主流程）
-	if s.logRepo != nil {
-		action := gameModel.ApplicationActionRejected
-		if agree {
-			action = gameModel.ApplicationActionApproved
-		}
-		go func() {
-			logEntry := &gameModel.GameShopApplicationLog{
-				HouseGID:     int32(applyInfo.HouseGid),
-				ApplierGID:   int32(applyInfo.ApplierGid),
-				ApplierGName: applyInfo.ApplierGName,
-				Action:       action,
-				AdminUserID:  claims.BaseClaims.UserID,
-				CreatedAt:    time.Now(),
-			}
-			if err := s.logRepo.Create(c.Request.Context(), logEntry); err != nil {
-				// 日志记录失败不影响主流程
-			}
-		}()
+// BulkDecide 批量处理申请
+// @Summary 批量通过/拒绝申请
+// @Description source=game 处理游戏内申请（需在线会话），source=platform 处理平台侧待审申请；逐条返回结果，每条都会写入处理记录。
+// @Description 权限按来源与动作分别校验（shop:applications:approve/reject、shop:apply:approve/reject）；管理员申请不参与批量
+// @Tags 店铺/游戏申请
+// @Accept json
+// @Produce json
+// @Param in body req.BulkDecideApplicationsRequest true "house_gid, source, ids, agree"
+// @Success 200 {object} response.Body{data=[]game.ApplicationDecideResult}
+// @Failure 400 {object} response.Body
+// @Failure 401 {object} response.Body
+// @Router /shops/game-applications/bulk [post]
+func (s *ShopApplicationService) BulkDecide(c *gin.Context) {
+	var in req.BulkDecideApplicationsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	perm := bulkDecidePerm(in.Source, in.Agree)
+	if !middleware.HasHousePerm(c, perm) {
+		response.Fail(c, ecode.Failed, "permission denied in this house: "+perm)
+		return
+	}
+	auditx.SetAction(c.Request.Context(), perm)
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
 	}
+	res, err := s.apps.DecideBulk(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.Source, in.IDs, in.Agree, in.Reason)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, res)
+}
 
-	response.SuccessWithOK(c)
+// bulkDecidePerm 批量处理所需权限：与对应的单条接口一致
+func bulkDecidePerm(source string, agree bool) string {
+	prefix := "shop:applications:"
+	if source == gameModel.ApplicationSourcePlatform {
+		prefix = "shop:apply:"
+	}
+	if agree {
+		return prefix + "approve"
+	}
+	return prefix + "reject"
 }
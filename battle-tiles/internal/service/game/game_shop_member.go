@@ -9,6 +9,8 @@ import (
 	"battle-tiles/internal/dal/req"
 	resp "battle-tiles/internal/dal/resp"
 	"battle-tiles/internal/infra/plaza"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
@@ -24,29 +26,30 @@ type GameShopMemberService struct {
 	sAdm  gameRepo.GameShopAdminRepo
 	users basicRepo.BasicUserRepo
 	apps  gameRepo.UserApplicationRepo
+	block *biz.BlocklistUseCase
 }
 
-func NewGameShopMemberService(mgr plaza.Manager, rule *biz.MemberRuleUseCase, sAdm gameRepo.GameShopAdminRepo, users basicRepo.BasicUserRepo, apps gameRepo.UserApplicationRepo) *GameShopMemberService {
-	return &GameShopMemberService{mgr: mgr, rule: rule, sAdm: sAdm, users: users, apps: apps}
+func NewGameShopMemberService(mgr plaza.Manager, rule *biz.MemberRuleUseCase, sAdm gameRepo.GameShopAdminRepo, users basicRepo.BasicUserRepo, apps gameRepo.UserApplicationRepo, block *biz.BlocklistUseCase) *GameShopMemberService {
+	return &GameShopMemberService{mgr: mgr, rule: rule, sAdm: sAdm, users: users, apps: apps, block: block}
 }
 
 func (s *GameShopMemberService) RegisterRouter(r *gin.RouterGroup) {
-	g := r.Group("/shops").Use(middleware.JWTAuth())
-	g.POST("/members/kick", middleware.RequirePerm("shop:member:kick"), s.Kick)
-	g.POST("/members/list", middleware.RequirePerm("shop:member:view"), s.List)
-	g.POST("/members/logout", middleware.RequirePerm("shop:member:logout"), s.Logout)
-	g.POST("/diamond/query", middleware.RequirePerm("shop:member:view"), s.QueryDiamond)
-	g.POST("/members/pull", middleware.RequirePerm("shop:member:view"), s.PullMembers)
+	g := r.Group("/shops").Use(middleware.JWTOrAPIKeyAuth())
+	g.POST("/members/kick", middleware.RequireHousePerm("shop:member:kick"), s.Kick)
+	g.POST("/members/list", middleware.RequireHousePerm("shop:member:view"), s.List)
+	g.POST("/members/logout", middleware.RequireHousePerm("shop:member:logout"), s.Logout)
+	g.POST("/diamond/query", middleware.RequireHousePerm("shop:member:view"), s.QueryDiamond)
+	g.POST("/members/pull", middleware.RequireHousePerm("shop:member:view"), s.PullMembers)
 	// 平台侧：按圈主返回“我圈子的成员”（基于已通过的入圈申请）
-	g.POST("/members/list_platform", middleware.RequirePerm("shop:member:view"), s.ListPlatformMembers)
+	g.POST("/members/list_platform", middleware.RequireHousePerm("shop:member:view"), s.ListPlatformMembers)
 	// 平台侧：从圈中移除成员（标记该成员的入圈记录为移除）
-	g.POST("/members/remove_platform", middleware.RequirePerm("shop:member:kick"), s.RemovePlatformMember)
+	g.POST("/members/remove_platform", middleware.RequireHousePerm("shop:member:kick"), s.RemovePlatformMember)
 	// 平台侧：直接将用户拉入圈子（创建已批准的入圈记录）
-	g.POST("/members/add_platform", middleware.RequirePerm("shop:member:update"), s.AddToPlatformGroup)
+	g.POST("/members/add_platform", middleware.RequireHousePerm("shop:member:update"), s.AddToPlatformGroup)
 	// 成员规则
-	g.POST("/members/rules/vip", middleware.RequirePerm("shop:member:update"), s.SetVIP)
-	g.POST("/members/rules/multi", middleware.RequirePerm("shop:member:update"), s.SetMulti)
-	g.POST("/members/rules/temp_release", middleware.RequirePerm("shop:member:update"), s.SetTempRelease)
+	g.POST("/members/rules/vip", middleware.RequireHousePerm("shop:member:update"), s.SetVIP)
+	g.POST("/members/rules/multi", middleware.RequireHousePerm("shop:member:update"), s.SetMulti)
+	g.POST("/members/rules/temp_release", middleware.RequireHousePerm("shop:member:update"), s.SetTempRelease)
 }
 
 // AddToPlatformGroup 直接将用户拉入圈子（平台：创建已批准的入圈记录）
@@ -107,6 +110,11 @@ func (s *GameShopMemberService) AddToPlatformGroup(c *gin.Context) {
 		response.Fail(c, ecode.Failed, "不能拉入店铺管理员")
 		return
 	}
+	// 平台黑名单
+	if err := s.block.Guard(c.Request.Context(), biz.BlocklistSubject{UserID: in.MemberUserID}); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
 
 	if err := s.apps.AddApprovedJoin(c.Request.Context(), in.HouseGID, admin, in.MemberUserID); err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -263,8 +271,18 @@ func (s *GameShopMemberService) Kick(c *gin.Context) {
 		return
 	}
 	actorUID := int(claims.BaseClaims.UserID)
+	houseGID, _ := middleware.ScopeHouseGID(c)
 
-	if err := s.mgr.KickMember(actorUID, in.HouseGID, in.MemberID); err != nil {
+	var before *plazaUtils.GroupMember
+	if sess, ok := s.mgr.Get(actorUID, int(houseGID)); ok && sess != nil {
+		for _, m := range sess.ListMembers() {
+			if int(m.MemberID) == in.MemberID {
+				before = m
+				break
+			}
+		}
+	}
+	if err := s.mgr.KickMember(actorUID, int(houseGID), in.MemberID); err != nil {
 		if strings.Contains(err.Error(), "session not found") {
 			response.Fail(c, ecode.Failed, "no online session")
 			return
@@ -272,6 +290,12 @@ func (s *GameShopMemberService) Kick(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	ctx := c.Request.Context()
+	auditx.SetTarget(ctx, "game_member", in.MemberID)
+	if before != nil {
+		auditx.SetBefore(ctx, map[string]any{"game_id": before.GameID, "nickname": before.NickName, "member_type": before.MemberType, "member_right": before.MemberRight})
+	}
+	auditx.SetAfter(ctx, map[string]any{"kicked": true})
 
 	response.SuccessWithOK(c)
 }
@@ -357,6 +381,7 @@ func (s *GameShopMemberService) List(c *gin.Context) {
 			MemberID:   m.MemberID,
 			MemberType: m.MemberType,
 			NickName:   m.NickName,
+			Forbid:     m.Forbidden(),
 			GroupID:    0,
 		})
 	}
@@ -416,7 +441,8 @@ func (s *GameShopMemberService) Logout(c *gin.Context) {
 		response.Fail(c, ecode.TokenValidateFailed, err)
 		return
 	}
-	if err := s.mgr.KickMember(int(claims.BaseClaims.UserID), in.HouseGID, in.MemberID); err != nil {
+	houseGID, _ := middleware.ScopeHouseGID(c)
+	if err := s.mgr.KickMember(int(claims.BaseClaims.UserID), int(houseGID), in.MemberID); err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
@@ -5,6 +5,8 @@ import (
 	"battle-tiles/internal/dal/req"
 	resp "battle-tiles/internal/dal/resp"
 	"battle-tiles/internal/infra/plaza"
+	plazaUtils "battle-tiles/internal/utils/plaza"
+	"battle-tiles/pkg/plugin/auditx"
 	"battle-tiles/pkg/plugin/middleware"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
@@ -24,7 +26,7 @@ func (s *ShopTableService) RegisterRouter(r *gin.RouterGroup) {
 	g := r.Group("/shops").Use(middleware.JWTAuth())
 
 	g.POST("/tables/list", middleware.RequirePerm("shop:table:view"), s.List)
-	g.POST("/tables/dismiss", middleware.RequirePerm("shop:table:dismiss"), s.Dismiss)
+	g.POST("/tables/dismiss", middleware.RequireHousePerm("shop:table:dismiss"), s.Dismiss)
 	g.POST("/tables/check", middleware.RequirePerm("shop:table:view"), s.Check)
 	g.POST("/tables/detail", middleware.RequirePerm("shop:table:view"), s.Detail)
 	g.POST("/tables/pull", middleware.RequirePerm("shop:table:view"), s.PullTables)
@@ -103,6 +105,9 @@ func (s *ShopTableService) Dismiss(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, "invalid house_gid or mapped_num")
 		return
 	}
+	if gid, ok := middleware.ScopeHouseGID(c); ok {
+		in.HouseGID = int(gid)
+	}
 
 	claims, err := utils.GetClaims(c)
 	if err != nil {
@@ -119,17 +124,17 @@ func (s *ShopTableService) Dismiss(c *gin.Context) {
 		return
 	}
 
-	kindID := in.KindID
-	if kindID == 0 {
-		if tables := sess.ListTables(); len(tables) > 0 {
-			for _, t := range tables {
-				if t.MappedNum == in.MappedNum {
-					kindID = t.KindID
-					break
-				}
-			}
+	var table *plazaUtils.TableInfo
+	for _, t := range sess.ListTables() {
+		if t.MappedNum == in.MappedNum {
+			table = t
+			break
 		}
 	}
+	kindID := in.KindID
+	if kindID == 0 && table != nil {
+		kindID = table.KindID
+	}
 	if kindID == 0 {
 		c.JSON(http.StatusUnprocessableEntity, response.Body{
 			Code: (ecode.ParamsFailed),
@@ -142,6 +147,12 @@ func (s *ShopTableService) Dismiss(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err)
 		return
 	}
+	ctx := c.Request.Context()
+	auditx.SetTarget(ctx, "table", in.MappedNum)
+	if table != nil {
+		auditx.SetBefore(ctx, map[string]any{"table_id": table.TableID, "group_id": table.GroupID, "kind_id": table.KindID, "base_score": table.BaseScore})
+	}
+	auditx.SetAfter(ctx, map[string]any{"dismissed": true, "kind_id": kindID})
 	response.SuccessWithOK(c)
 }
 
@@ -163,6 +174,9 @@ func (s *ShopTableService) Check(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, "invalid house_gid or mapped_num")
 		return
 	}
+	if gid, ok := middleware.ScopeHouseGID(c); ok {
+		in.HouseGID = int(gid)
+	}
 
 	claims, err := utils.GetClaims(c)
 	if err != nil {
@@ -217,6 +231,9 @@ func (s *ShopTableService) Detail(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, "invalid house_gid or mapped_num")
 		return
 	}
+	if gid, ok := middleware.ScopeHouseGID(c); ok {
+		in.HouseGID = int(gid)
+	}
 
 	claims, err := utils.GetClaims(c)
 	if err != nil {
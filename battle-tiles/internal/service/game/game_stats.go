@@ -2,6 +2,7 @@ package game
 
 import (
 	gameBiz "battle-tiles/internal/biz/game"
+	repo "battle-tiles/internal/dal/repo/game"
 	"battle-tiles/internal/dal/req"
 	"battle-tiles/internal/infra/plaza"
 	"battle-tiles/pkg/plugin/middleware"
@@ -36,6 +37,8 @@ func (s *GameStatsService) RegisterRouter(r *gin.RouterGroup) {
 	g.POST("/member/lastweek", middleware.RequirePerm("stats:view"), s.MemberLastWeek)
 	// 按店铺会话活跃
 	g.GET("/sessions/activeByHouse", s.ActiveByHouse)
+	// 时间序列（趋势图）
+	g.POST("/series", middleware.RequirePerm("stats:view"), s.Series)
 }
 
 // Today
@@ -304,3 +307,49 @@ func (s *GameStatsService) ActiveByHouse(c *gin.Context) {
 	}
 	response.Success(c, out)
 }
+
+// Series
+// @Summary      时间序列统计
+// @Description  按 hour/day/week 分桶返回局数、运费、输赢流水、活跃玩家、上分、下分；可按店铺/圈/成员统计，无数据的桶补零
+// @Tags         统计
+// @Accept       json
+// @Produce      json
+// @Param        in body req.StatsSeriesRequest true "house_gid, bucket, start_time, end_time"
+// @Success      200 {object} response.Body{data=resp.StatsSeriesVO}
+// @Router       /stats/series [post]
+func (s *GameStatsService) Series(c *gin.Context) {
+	var in req.StatsSeriesRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if in.GroupID != nil && in.MemberID != nil {
+		response.Fail(c, ecode.ParamsFailed, "group_id and member_id are mutually exclusive")
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	isSuper := false
+	for _, r := range claims.BaseClaims.Roles {
+		if r == 1 {
+			isSuper = true
+			break
+		}
+	}
+	if !isSuper && s.shopAdminUC != nil {
+		if v, err := s.shopAdminUC.IsAdmin(c.Request.Context(), int32(in.HouseGID), claims.BaseClaims.UserID); err != nil || !v {
+			response.Fail(c, ecode.Failed, fmt.Errorf("permission denied for house"))
+			return
+		}
+	}
+	scope := repo.SeriesScope{HouseGID: in.HouseGID, GroupID: in.GroupID, MemberID: in.MemberID}
+	out, err := s.uc.Series(c.Request.Context(), in.Bucket, scope, time.Unix(in.StartTime, 0), time.Unix(in.EndTime, 0))
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
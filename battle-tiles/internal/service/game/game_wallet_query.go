@@ -18,18 +18,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type WalletQueryService struct{ uc *gameBiz.FundsUseCase }
+type WalletQueryService struct {
+	uc  *gameBiz.FundsUseCase
+	gro *gameBiz.GroResolver
+}
 
-func NewWalletQueryService(uc *gameBiz.FundsUseCase) *WalletQueryService {
-	return &WalletQueryService{uc: uc}
+func NewWalletQueryService(uc *gameBiz.FundsUseCase, gro *gameBiz.GroResolver) *WalletQueryService {
+	return &WalletQueryService{uc: uc, gro: gro}
 }
 
 func (s *WalletQueryService) RegisterRouter(r *gin.RouterGroup) {
-	g := r.Group("/members").Use(middleware.JWTAuth())
-	g.POST("/wallet/get", middleware.RequirePerm("fund:wallet:view"), s.Get)                   // 单人余额
-	g.POST("/wallet/list", middleware.RequirePerm("fund:wallet:view"), s.List)                 // 批量筛选
-	g.POST("/wallet/list_by_group", middleware.RequirePerm("fund:wallet:view"), s.ListByGroup) // 按圈或在线集合筛选
-	g.POST("/ledger/list", middleware.RequirePerm("fund:ledger:view"), s.Ledger)               // 流水
+	g := r.Group("/members").Use(middleware.JWTOrAPIKeyAuth())
+	g.POST("/wallet/get", middleware.RequireHousePerm("fund:wallet:view"), s.Get)                   // 单人余额
+	g.POST("/wallet/list", middleware.RequireHousePerm("fund:wallet:view"), s.List)                 // 批量筛选
+	g.POST("/wallet/list_by_group", middleware.RequireHousePerm("fund:wallet:view"), s.ListByGroup) // 按圈或在线集合筛选
+	g.POST("/ledger/list", middleware.RequireHousePerm("fund:ledger:view"), s.Ledger)               // 流水
 	// 用户战绩明细（基于外部HTTP数据源）
 	g.POST("/battle/details", middleware.RequirePerm("battle:detail:view"), s.BattleDetails)
 	// 本地战绩（已落库）
@@ -50,6 +53,7 @@ func (s *WalletQueryService) Get(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	m, err := s.uc.GetWallet(c.Request.Context(), in.HouseGID, in.MemberID)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -72,6 +76,7 @@ func (s *WalletQueryService) List(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	list, total, err := s.uc.ListWallets(c.Request.Context(), in.HouseGID, in.MinBalance, in.MaxBalance, in.HasCustomLimit, in.Page, in.PageSize)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
@@ -99,6 +104,7 @@ func (s *WalletQueryService) ListByGroup(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 	// 先用店铺级阈值筛选（<= max_balance）
 	max := in.MaxBalance
 	list, total, err := s.uc.ListWallets(c.Request.Context(), in.HouseGID, nil, max, nil, in.Page, in.PageSize)
@@ -123,6 +129,7 @@ func (s *WalletQueryService) Ledger(c *gin.Context) {
 		response.Fail(c, ecode.ParamsFailed, err)
 		return
 	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
 
 	var startPtr, endPtr *time.Time
 	parse := func(s *string) *time.Time {
@@ -201,8 +208,8 @@ func (s *WalletQueryService) BattleDetails(c *gin.Context) {
 		typeid = 0
 	}
 
-	httpc := &httpClient{timeout: 10 * time.Second}
-	list, err := plazaHTTP.GetGroupBattleInfoCtx(c.Request.Context(), httpc, "http://phone.foxuc.com/Ashx/GroService.ashx", in.GroupID, typeid)
+	ctx := c.Request.Context()
+	list, err := plazaHTTP.GetGroupBattleInfoCtx(ctx, s.gro.Doer(), s.gro.Query(ctx), in.GroupID, typeid)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
@@ -242,8 +249,8 @@ func (s *WalletQueryService) ExportBattleHTML(c *gin.Context) {
 	case "thisweek":
 		typeid = 2
 	}
-	httpc := &httpClient{timeout: 10 * time.Second}
-	list, err := plazaHTTP.GetGroupBattleInfoCtx(c.Request.Context(), httpc, "http://phone.foxuc.com/Ashx/GroService.ashx", in.GroupID, typeid)
+	ctx := c.Request.Context()
+	list, err := plazaHTTP.GetGroupBattleInfoCtx(ctx, s.gro.Doer(), s.gro.Query(ctx), in.GroupID, typeid)
 	if err != nil {
 		response.Fail(c, ecode.Failed, err)
 		return
@@ -262,11 +269,3 @@ func (s *WalletQueryService) ExportBattleHTML(c *gin.Context) {
 	b.WriteString("</body></html>")
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(b.String()))
 }
-
-// 轻量HTTP客户端
-type httpClient struct{ timeout time.Duration }
-
-func (h *httpClient) Do(r *http.Request) (*http.Response, error) {
-	client := &http.Client{Timeout: h.timeout}
-	return client.Do(r)
-}
@@ -0,0 +1,241 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/repo/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupSettlementService 圈主结算单（生成/冻结/打款）
+type GroupSettlementService struct {
+	uc *biz.GroupSettlementUseCase
+}
+
+func NewGroupSettlementService(uc *biz.GroupSettlementUseCase) *GroupSettlementService {
+	return &GroupSettlementService{uc: uc}
+}
+
+func (s *GroupSettlementService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/settlements").Use(middleware.JWTAuth())
+	g.POST("/generate", middleware.RequirePerm("settlement:generate"), s.Generate)
+	g.POST("/list", middleware.RequirePerm("settlement:view"), s.List)
+	g.POST("/get", middleware.RequirePerm("settlement:view"), s.Get)
+	g.POST("/freeze", middleware.RequirePerm("settlement:freeze"), s.Freeze)
+	g.POST("/pay", middleware.RequirePerm("settlement:pay"), s.MarkPaid)
+	g.POST("/commission/set", middleware.RequirePerm("settlement:commission:update"), s.SetCommissionRate)
+}
+
+// Generate
+// @Summary      生成圈主结算单
+// @Description  按周期汇总各圈局数、输赢流水、运费与分运结转，计算圈主抽成；已冻结/已打款的结算单不会被重算
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.GenerateSettlementRequest true "house_gid, 周期"
+// @Success      200 {object} response.Body{data=[]game.GameGroupSettlement}
+// @Router       /settlements/generate [post]
+func (s *GroupSettlementService) Generate(c *gin.Context) {
+	var in req.GenerateSettlementRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	start, err := parseRFC3339Ptr(in.StartAt)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	end, err := parseRFC3339Ptr(in.EndAt)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if (start == nil) != (end == nil) {
+		response.Fail(c, ecode.ParamsFailed, "start_at and end_at must be provided together")
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	out, err := s.uc.Generate(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), in.HouseGID, start, end)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// List
+// @Summary      圈主结算单列表
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListSettlementRequest true "筛选条件"
+// @Router       /settlements/list [post]
+func (s *GroupSettlementService) List(c *gin.Context) {
+	var in req.ListSettlementRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	start, err := parseRFC3339Ptr(in.StartAt)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	end, err := parseRFC3339Ptr(in.EndAt)
+	if err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	f := game.SettlementFilter{
+		HouseGID:    in.HouseGID,
+		GroupID:     in.GroupID,
+		AdminUserID: in.AdminUserID,
+		Status:      in.Status,
+		Start:       start,
+		End:         end,
+	}
+	list, total, err := s.uc.List(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), f, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"list":      list,
+		"total":     total,
+		"page":      normPage(in.Page),
+		"page_size": normSize(in.PageSize),
+	})
+}
+
+// Get
+// @Summary      结算单详情
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SettlementIDRequest true "id"
+// @Router       /settlements/get [post]
+func (s *GroupSettlementService) Get(c *gin.Context) {
+	var in req.SettlementIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	out, err := s.uc.Get(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// Freeze
+// @Summary      冻结结算单
+// @Description  草稿 -> 已冻结；冻结后数据锁定，不再随战绩重算
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SettlementIDRequest true "id"
+// @Router       /settlements/freeze [post]
+func (s *GroupSettlementService) Freeze(c *gin.Context) {
+	var in req.SettlementIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	out, err := s.uc.Freeze(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// MarkPaid
+// @Summary      结算单标记已打款
+// @Description  已冻结 -> 已打款
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SettlementIDRequest true "id"
+// @Router       /settlements/pay [post]
+func (s *GroupSettlementService) MarkPaid(c *gin.Context) {
+	var in req.SettlementIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	out, err := s.uc.MarkPaid(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// SetCommissionRate
+// @Summary      设置圈主抽成比例（万分比）
+// @Tags         结算
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SetCommissionRateRequest true "group_id, commission_rate"
+// @Router       /settlements/commission/set [post]
+func (s *GroupSettlementService) SetCommissionRate(c *gin.Context) {
+	var in req.SetCommissionRateRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.SetCommissionRate(c.Request.Context(), claims.BaseClaims.UserID, claims.BaseClaims.IsSuperAdmin(), in.GroupID, in.CommissionRate); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// parseRFC3339Ptr 解析可选的 RFC3339 时间，空串返回 nil
+func parseRFC3339Ptr(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
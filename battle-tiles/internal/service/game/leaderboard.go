@@ -0,0 +1,67 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardService 排行榜
+type LeaderboardService struct {
+	uc *biz.LeaderboardUseCase
+}
+
+func NewLeaderboardService(uc *biz.LeaderboardUseCase) *LeaderboardService {
+	return &LeaderboardService{uc: uc}
+}
+
+func (s *LeaderboardService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/stats").Use(middleware.JWTAuth())
+	g.POST("/leaderboard", middleware.RequireHousePerm("stats:view"), s.Leaderboard)
+}
+
+// Leaderboard
+// @Summary      排行榜
+// @Description  赢分/输分/局数/运费贡献/胜率排行，可按圈、玩法、时间窗口过滤；榜单随战绩入库增量刷新
+// @Tags         统计
+// @Accept       json
+// @Produce      json
+// @Param        in body req.LeaderboardRequest true "house_gid, metric, period"
+// @Success      200 {object} response.Body{data=resp.LeaderboardVO}
+// @Router       /stats/leaderboard [post]
+func (s *LeaderboardService) Leaderboard(c *gin.Context) {
+	var in req.LeaderboardRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	q := biz.LeaderboardQuery{
+		HouseGID: in.HouseGID,
+		GroupID:  in.GroupID,
+		KindID:   in.KindID,
+		Metric:   in.Metric,
+		Period:   in.Period,
+		Limit:    in.Limit,
+		MinGames: in.MinGames,
+	}
+	if in.StartTime != nil {
+		t := time.Unix(*in.StartTime, 0)
+		q.Start = &t
+	}
+	if in.EndTime != nil {
+		t := time.Unix(*in.EndTime, 0)
+		q.End = &t
+	}
+	out, err := s.uc.Query(c.Request.Context(), q)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
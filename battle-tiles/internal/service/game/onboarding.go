@@ -0,0 +1,172 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OnboardingService 成员入店流程（查看/手动发起/重试/撤销/默认值）
+type OnboardingService struct {
+	uc *biz.OnboardingUseCase
+}
+
+func NewOnboardingService(uc *biz.OnboardingUseCase) *OnboardingService {
+	return &OnboardingService{uc: uc}
+}
+
+func (s *OnboardingService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/shops/onboarding").Use(middleware.JWTAuth())
+	g.POST("/list", middleware.RequireHousePerm("shop:member:view"), s.List)
+	// 发起/重试会发放入店赠送，撤销会强制扣回
+	g.POST("/start", middleware.RequireHousePerm("shop:member:update", "fund:deposit"), s.Start)
+	g.POST("/retry", middleware.RequireHousePerm("shop:member:update", "fund:deposit"), s.Retry)
+	g.POST("/cancel", middleware.RequireHousePerm("shop:member:update", "fund:force_withdraw"), s.Cancel)
+	g.POST("/defaults/set", middleware.RequireHousePerm("shop:member:update", "fund:deposit"), s.SetDefaults)
+}
+
+// List
+// @Summary      入店流程列表
+// @Description  step 为最后完成的步骤：pending → member_created → group_assigned → wallet_ready → rules_ready
+// @Tags         店铺/成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListOnboardingRequest true "house_gid, status"
+// @Success      200 {object} response.Body{data=[]game.GameMemberOnboarding}
+// @Router       /shops/onboarding/list [post]
+func (s *OnboardingService) List(c *gin.Context) {
+	var in req.ListOnboardingRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	list, total, err := s.uc.List(c.Request.Context(), in.HouseGID, in.Status, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": normPage(in.Page), "page_size": normSize(in.PageSize)})
+}
+
+// Start
+// @Summary      手动发起入店流程
+// @Description  建成员 → 挂圈子 → 开钱包（店铺默认下限 + 入店赠送）→ 写规则默认值；失败时返回错误，流程停在断点可重试
+// @Tags         店铺/成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.StartOnboardingRequest true "成员信息"
+// @Success      200 {object} response.Body{data=game.GameMemberOnboarding}
+// @Router       /shops/onboarding/start [post]
+func (s *OnboardingService) Start(c *gin.Context) {
+	var in req.StartOnboardingRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	ob, err := s.uc.Start(c.Request.Context(), claims.BaseClaims.UserID, biz.OnboardingInput{
+		HouseGID:        in.HouseGID,
+		ApplicantUserID: in.UserID,
+		GameID:          in.GameID,
+		GameName:        in.GameName,
+		Recommender:     in.Recommender,
+		GroupID:         in.GroupID,
+	})
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, ob)
+}
+
+// Retry
+// @Summary      重试入店流程
+// @Description  从最后完成的步骤之后继续执行
+// @Tags         店铺/成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.OnboardingIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body{data=game.GameMemberOnboarding}
+// @Router       /shops/onboarding/retry [post]
+func (s *OnboardingService) Retry(c *gin.Context) {
+	var in req.OnboardingIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	ob, err := s.uc.Retry(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, ob)
+}
+
+// Cancel
+// @Summary      撤销入店流程
+// @Description  按相反顺序撤销：回退入店赠送、冻结钱包、移出圈子、删除本流程创建的成员
+// @Tags         店铺/成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.OnboardingIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body{data=game.GameMemberOnboarding}
+// @Router       /shops/onboarding/cancel [post]
+func (s *OnboardingService) Cancel(c *gin.Context) {
+	var in req.OnboardingIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	ob, err := s.uc.Cancel(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, ob)
+}
+
+// SetDefaults
+// @Summary      设置新成员默认值
+// @Description  入店流程开钱包时使用的默认下限与入店赠送（分），赠送为 0 不发放
+// @Tags         店铺/成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SetOnboardingDefaultsRequest true "house_gid, limit_min, welcome_credit"
+// @Success      200 {object} response.Body
+// @Router       /shops/onboarding/defaults/set [post]
+func (s *OnboardingService) SetDefaults(c *gin.Context) {
+	var in req.SetOnboardingDefaultsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	if err := s.uc.SetDefaults(c.Request.Context(), claims.BaseClaims.UserID, in.HouseGID, in.LimitMin, in.WelcomeCredit); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
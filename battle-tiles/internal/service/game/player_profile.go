@@ -0,0 +1,58 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlayerProfileService 玩家 360° 视图
+type PlayerProfileService struct {
+	uc *biz.PlayerProfileUseCase
+}
+
+func NewPlayerProfileService(uc *biz.PlayerProfileUseCase) *PlayerProfileService {
+	return &PlayerProfileService{uc: uc}
+}
+
+func (s *PlayerProfileService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/members").Use(middleware.JWTAuth())
+	g.POST("/profile", middleware.RequireHousePerm("shop:member:view"), s.Profile)
+}
+
+// Profile
+// @Summary      玩家 360° 视图
+// @Description  一次返回绑定账号、所在圈、钱包余额/额度/禁分、成员规则、最近流水、最近战绩、全部/今日/本周统计、待审申请与在线状态
+// @Description  钱包与流水分别需要本店铺的 fund:wallet:view / fund:ledger:view，没有时不返回
+// @Tags         成员
+// @Accept       json
+// @Produce      json
+// @Param        in body req.PlayerProfileRequest true "house_gid + game_id 或 user_id"
+// @Success      200 {object} response.Body{data=resp.PlayerProfileVO}
+// @Router       /members/profile [post]
+func (s *PlayerProfileService) Profile(c *gin.Context) {
+	var in req.PlayerProfileRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	if in.GameID == nil && in.UserID == nil {
+		response.Fail(c, ecode.ParamsFailed, "game_id or user_id is required")
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	access := biz.PlayerProfileAccess{
+		Wallet: middleware.HasHousePerm(c, "fund:wallet:view"),
+		Ledger: middleware.HasHousePerm(c, "fund:ledger:view"),
+	}
+	out, err := s.uc.Get(c.Request.Context(), in.HouseGID, in.GameID, in.UserID, access)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportService 店铺定时报表（定义管理/手动执行/执行历史）
+type ReportService struct {
+	uc *biz.ReportUseCase
+}
+
+func NewReportService(uc *biz.ReportUseCase) *ReportService {
+	return &ReportService{uc: uc}
+}
+
+func (s *ReportService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/reports").Use(middleware.JWTAuth())
+	g.POST("/definitions/list", middleware.RequireHousePerm("report:view"), s.ListDefinitions)
+	g.POST("/definitions/save", middleware.RequireHousePerm("report:manage"), s.SaveDefinition)
+	g.POST("/definitions/delete", middleware.RequireHousePerm("report:manage"), s.DeleteDefinition)
+	g.POST("/run", middleware.RequireHousePerm("report:manage"), s.Run)
+	g.POST("/runs/list", middleware.RequireHousePerm("report:view"), s.ListRuns)
+}
+
+// ListDefinitions
+// @Summary      报表定义列表
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListReportDefinitionsRequest true "house_gid"
+// @Success      200 {object} response.Body{data=[]game.GameReportDefinition}
+// @Router       /reports/definitions/list [post]
+func (s *ReportService) ListDefinitions(c *gin.Context) {
+	var in req.ListReportDefinitionsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	out, err := s.uc.ListDefinitions(c.Request.Context(), in.HouseGID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// SaveDefinition
+// @Summary      新建/修改报表定义
+// @Description  报表类型：daily_stats 经营概况、low_balance 低余额成员、top_losers 输分榜、fee_summary 运费汇总、session_outage 会话异常；渠道：file 写入服务端目录、webhook POST JSON
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SaveReportDefinitionRequest true "报表定义"
+// @Success      200 {object} response.Body{data=game.GameReportDefinition}
+// @Router       /reports/definitions/save [post]
+func (s *ReportService) SaveDefinition(c *gin.Context) {
+	var in req.SaveReportDefinitionRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	def := biz.ReportDefinitionInput{
+		HouseGID:   in.HouseGID,
+		Name:       in.Name,
+		ReportType: in.ReportType,
+		Period:     in.Period,
+		Schedule:   in.Schedule,
+		Params: biz.ReportParams{
+			Threshold: in.Params.Threshold,
+			Limit:     in.Params.Limit,
+			GroupID:   in.Params.GroupID,
+		},
+		Channel:       in.Channel,
+		ChannelTarget: in.ChannelTarget,
+		Enabled:       in.Enabled,
+	}
+	if in.ID > 0 {
+		out, err := s.uc.UpdateDefinition(c.Request.Context(), in.ID, def)
+		if err != nil {
+			response.Fail(c, ecode.Failed, err)
+			return
+		}
+		response.Success(c, out)
+		return
+	}
+	out, err := s.uc.CreateDefinition(c.Request.Context(), claims.BaseClaims.UserID, def)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// DeleteDefinition
+// @Summary      删除报表定义
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ReportDefinitionIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body
+// @Router       /reports/definitions/delete [post]
+func (s *ReportService) DeleteDefinition(c *gin.Context) {
+	var in req.ReportDefinitionIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	if err := s.uc.DeleteDefinition(c.Request.Context(), in.HouseGID, in.ID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Run
+// @Summary      立即执行报表
+// @Description  按定义渲染并投递一次，不影响定时计划；返回本次执行记录
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ReportDefinitionIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body{data=game.GameReportRun}
+// @Router       /reports/run [post]
+func (s *ReportService) Run(c *gin.Context) {
+	var in req.ReportDefinitionIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	out, err := s.uc.RunNow(c.Request.Context(), in.HouseGID, in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// ListRuns
+// @Summary      报表执行历史
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListReportRunsRequest true "house_gid, definition_id"
+// @Success      200 {object} response.Body{data=[]game.GameReportRun}
+// @Router       /reports/runs/list [post]
+func (s *ReportService) ListRuns(c *gin.Context) {
+	var in req.ListReportRunsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	list, total, err := s.uc.ListRuns(c.Request.Context(), in.HouseGID, in.DefinitionID, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": normPage(in.Page), "page_size": normSize(in.PageSize)})
+}
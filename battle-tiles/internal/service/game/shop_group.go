@@ -27,14 +27,21 @@ func NewShopGroupService(groupUC *game.ShopGroupUseCase, logger log.Logger) *Sho
 func (s *ShopGroupService) RegisterRouter(r *gin.RouterGroup) {
 	g := r.Group("/groups").Use(middleware.JWTAuth())
 
-	g.POST("/create", s.CreateGroup)          // 创建圈子
-	g.POST("/my", s.GetMyGroup)               // 获取我的圈子
-	g.POST("/list", s.ListGroupsByHouse)      // 获取店铺圈子列表
-	g.POST("/options", s.GetGroupOptions)     // 获取圈子选项列表（用于下拉框）
-	g.POST("/members/add", s.AddMembers)      // 添加成员到圈子
-	g.POST("/members/remove", s.RemoveMember) // 从圈子移除成员
-	g.POST("/members/list", s.ListMembers)    // 获取圈子成员列表
-	g.POST("/my/list", s.ListMyGroups)        // 获取我加入的圈子
+	g.POST("/create", middleware.Audit("shop:group:create"), s.CreateGroup)                 // 创建圈子
+	g.POST("/my", s.GetMyGroup)                                                             // 获取我的圈子
+	g.POST("/list", s.ListGroupsByHouse)                                                    // 获取店铺圈子列表
+	g.POST("/options", s.GetGroupOptions)                                                   // 获取圈子选项列表（用于下拉框）
+	g.POST("/members/add", middleware.Audit("shop:group:member:add"), s.AddMembers)         // 添加成员到圈子
+	g.POST("/members/remove", middleware.Audit("shop:group:member:remove"), s.RemoveMember) // 从圈子移除成员
+	g.POST("/members/list", s.ListMembers)                                                  // 获取圈子成员列表
+	g.POST("/my/list", s.ListMyGroups)                                                      // 获取我加入的圈子
+	g.POST("/update", middleware.Audit("shop:group:update"), s.UpdateGroup)                 // 修改圈子（同步到游戏走 /mirror）
+
+	// 与游戏端群组同步
+	g.POST("/mirror", middleware.RequireHousePerm("shop:group:sync"), s.MirrorGroup)
+	g.POST("/delete", middleware.RequireHousePerm("shop:group:sync"), s.DeleteGroup)
+	g.POST("/game/search", middleware.RequireHousePerm("shop:group:view"), s.SearchGameGroup)
+	g.POST("/game/reconcile", middleware.RequireHousePerm("shop:group:sync"), s.ReconcileGroups)
 }
 
 // CreateGroupReq 创建圈子请求
@@ -42,6 +49,7 @@ type CreateGroupReq struct {
 	HouseGID    int32  `json:"house_gid" binding:"required"`
 	GroupName   string `json:"group_name" binding:"required"`
 	Description string `json:"description"`
+	Mirror      bool   `json:"mirror"` // 同时通过中控会话在游戏端创建群组
 }
 
 // CreateGroup 创建圈子
@@ -66,6 +74,13 @@ func (s *ShopGroupService) CreateGroup(c *gin.Context) {
 		response.Fail(c, ecode.Failed, err.Error())
 		return
 	}
+	if req.Mirror && group.GameGroupID == nil {
+		if group, err = s.groupUC.MirrorGroup(c.Request.Context(), userID, req.HouseGID, group.Id); err != nil {
+			s.log.Errorf("mirror group failed: %v", err)
+			response.Fail(c, ecode.Failed, "圈子已创建，同步到游戏失败: "+err.Error())
+			return
+		}
+	}
 
 	response.Success(c, group)
 }
@@ -149,6 +164,7 @@ func (s *ShopGroupService) ListGroupsByHouse(c *gin.Context) {
 			"admin_user_id": group.AdminUserID,
 			"description":   group.Description,
 			"member_count":  count,
+			"game_group_id": group.GameGroupID,
 			"created_at":    group.CreatedAt,
 		})
 	}
@@ -310,3 +326,134 @@ func (s *ShopGroupService) GetGroupOptions(c *gin.Context) {
 
 	response.Success(c, options)
 }
+
+// UpdateGroupReq 修改圈子请求
+type UpdateGroupReq struct {
+	HouseGID    int32  `json:"house_gid" binding:"required"`
+	GroupID     int32  `json:"group_id" binding:"required"`
+	GroupName   string `json:"group_name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// UpdateGroup 修改圈子（圈主）；同步到游戏端走 /groups/mirror（需 shop:group:sync）
+// POST /api/groups/update
+func (s *ShopGroupService) UpdateGroup(c *gin.Context) {
+	var req UpdateGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, ecode.ParamsFailed, nil)
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if err := s.groupUC.UpdateGroup(c.Request.Context(), req.GroupID, userID, req.GroupName, req.Description); err != nil {
+		s.log.Errorf("update group failed: %v", err)
+		response.Fail(c, ecode.Failed, err.Error())
+		return
+	}
+	response.Success(c, "修改成功")
+}
+
+// GroupSyncReq 圈子同步请求
+type GroupSyncReq struct {
+	HouseGID int32 `json:"house_gid" binding:"required"`
+	GroupID  int32 `json:"group_id" binding:"required"`
+}
+
+// MirrorGroup 同步圈子到游戏端：未关联时创建群组并关联，已关联时同步名称
+// POST /api/groups/mirror
+func (s *ShopGroupService) MirrorGroup(c *gin.Context) {
+	var req GroupSyncReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, ecode.ParamsFailed, nil)
+		return
+	}
+
+	group, err := s.groupUC.MirrorGroup(c.Request.Context(), utils.GetUserID(c), req.HouseGID, req.GroupID)
+	if err != nil {
+		s.log.Errorf("mirror group failed: %v", err)
+		response.Fail(c, ecode.Failed, err.Error())
+		return
+	}
+
+	response.Success(c, group)
+}
+
+// DeleteGroupReq 删除圈子请求
+type DeleteGroupReq struct {
+	HouseGID int32 `json:"house_gid" binding:"required"`
+	GroupID  int32 `json:"group_id" binding:"required"`
+	InGame   bool  `json:"in_game"` // 同时删除已关联的游戏端群组
+}
+
+// DeleteGroup 停用圈子，可同时删除游戏端群组
+// POST /api/groups/delete
+func (s *ShopGroupService) DeleteGroup(c *gin.Context) {
+	var req DeleteGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, ecode.ParamsFailed, nil)
+		return
+	}
+
+	if err := s.groupUC.DeleteGroup(c.Request.Context(), utils.GetUserID(c), req.HouseGID, req.GroupID, req.InGame); err != nil {
+		s.log.Errorf("delete group failed: %v", err)
+		response.Fail(c, ecode.Failed, err.Error())
+		return
+	}
+
+	response.Success(c, "删除成功")
+}
+
+// SearchGameGroupReq 搜索游戏端群组请求
+type SearchGameGroupReq struct {
+	HouseGID    int32 `json:"house_gid" binding:"required"`
+	GameGroupID int32 `json:"game_group_id" binding:"required"`
+}
+
+// SearchGameGroup 按群组标识搜索游戏端群组
+// POST /api/groups/game/search
+func (s *ShopGroupService) SearchGameGroup(c *gin.Context) {
+	var req SearchGameGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, ecode.ParamsFailed, nil)
+		return
+	}
+
+	g, err := s.groupUC.SearchGameGroup(c.Request.Context(), utils.GetUserID(c), req.HouseGID, req.GameGroupID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err.Error())
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"game_group_id":    g.GroupID,
+		"name":             g.Name,
+		"creater_game_id":  g.CreaterGameID,
+		"member_count":     g.MemberCount,
+		"max_member_count": g.MaxMemberCount,
+	})
+}
+
+// ReconcileGroupsReq 圈子对账请求
+type ReconcileGroupsReq struct {
+	HouseGID int32 `json:"house_gid" binding:"required"`
+	Import   bool  `json:"import"` // 为游戏端独有的群组创建本地圈子；否则只出报告
+}
+
+// ReconcileGroups 本地圈子与中控账号所在游戏群组对账，可导入缺失的群组
+// POST /api/groups/game/reconcile
+func (s *ShopGroupService) ReconcileGroups(c *gin.Context) {
+	var req ReconcileGroupsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, ecode.ParamsFailed, nil)
+		return
+	}
+
+	report, err := s.groupUC.ReconcileGroups(c.Request.Context(), utils.GetUserID(c), req.HouseGID, req.Import)
+	if err != nil {
+		s.log.Errorf("reconcile groups failed: %v", err)
+		response.Fail(c, ecode.Failed, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,218 @@
+package game
+
+import (
+	biz "battle-tiles/internal/biz/game"
+	"battle-tiles/internal/dal/req"
+	"battle-tiles/pkg/plugin/middleware"
+	"battle-tiles/pkg/utils"
+	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookService 店铺 webhook（回调地址管理/投递记录/手动重投/测试）
+type WebhookService struct {
+	uc *biz.WebhookUseCase
+}
+
+func NewWebhookService(uc *biz.WebhookUseCase) *WebhookService {
+	return &WebhookService{uc: uc}
+}
+
+func (s *WebhookService) RegisterRouter(r *gin.RouterGroup) {
+	g := r.Group("/shops/webhooks").Use(middleware.JWTAuth())
+	g.POST("/list", middleware.RequireHousePerm("webhook:view"), s.ListEndpoints)
+	g.POST("/save", middleware.RequireHousePerm("webhook:manage"), s.SaveEndpoint)
+	g.POST("/delete", middleware.RequireHousePerm("webhook:manage"), s.DeleteEndpoint)
+	g.POST("/rotateSecret", middleware.RequireHousePerm("webhook:manage"), s.RotateSecret)
+	g.POST("/test", middleware.RequireHousePerm("webhook:manage"), s.Test)
+	g.POST("/deliveries/list", middleware.RequireHousePerm("webhook:view"), s.ListDeliveries)
+	g.POST("/deliveries/redeliver", middleware.RequireHousePerm("webhook:manage"), s.Redeliver)
+}
+
+// ListEndpoints
+// @Summary      webhook 回调地址列表
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListWebhookEndpointsRequest true "house_gid"
+// @Success      200 {object} response.Body{data=[]game.GameWebhookEndpoint}
+// @Router       /shops/webhooks/list [post]
+func (s *WebhookService) ListEndpoints(c *gin.Context) {
+	var in req.ListWebhookEndpointsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	out, err := s.uc.ListEndpoints(c.Request.Context(), in.HouseGID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// SaveEndpoint
+// @Summary      新建/修改 webhook 回调地址
+// @Description  新建时返回签名密钥 secret（仅返回这一次）。投递为 JSON POST，请求头 X-Webhook-Event/X-Webhook-Delivery/X-Webhook-Timestamp/X-Webhook-Signature，
+// @Description  签名为 "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))；非 2xx 按指数退避重试，最多 8 次
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.SaveWebhookEndpointRequest true "回调地址"
+// @Success      200 {object} response.Body{data=object} "data: { endpoint, secret }"
+// @Router       /shops/webhooks/save [post]
+func (s *WebhookService) SaveEndpoint(c *gin.Context) {
+	var in req.SaveWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	claims, err := utils.GetClaims(c)
+	if err != nil {
+		response.Fail(c, ecode.TokenValidateFailed, err)
+		return
+	}
+	ep := biz.WebhookEndpointInput{
+		HouseGID:            in.HouseGID,
+		Name:                in.Name,
+		URL:                 in.URL,
+		Events:              in.Events,
+		LowBalanceThreshold: in.LowBalanceThreshold,
+		Enabled:             in.Enabled,
+	}
+	if in.ID > 0 {
+		out, err := s.uc.UpdateEndpoint(c.Request.Context(), in.ID, ep)
+		if err != nil {
+			response.Fail(c, ecode.Failed, err)
+			return
+		}
+		response.Success(c, gin.H{"endpoint": out})
+		return
+	}
+	out, secret, err := s.uc.CreateEndpoint(c.Request.Context(), claims.BaseClaims.UserID, ep)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"endpoint": out, "secret": secret})
+}
+
+// DeleteEndpoint
+// @Summary      删除 webhook 回调地址
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.WebhookEndpointIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body
+// @Router       /shops/webhooks/delete [post]
+func (s *WebhookService) DeleteEndpoint(c *gin.Context) {
+	var in req.WebhookEndpointIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	if err := s.uc.DeleteEndpoint(c.Request.Context(), in.HouseGID, in.ID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// RotateSecret
+// @Summary      重置 webhook 签名密钥
+// @Description  返回新的 secret，旧密钥立即失效
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.WebhookEndpointIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body{data=object} "data: { secret }"
+// @Router       /shops/webhooks/rotateSecret [post]
+func (s *WebhookService) RotateSecret(c *gin.Context) {
+	var in req.WebhookEndpointIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	secret, err := s.uc.RotateSecret(c.Request.Context(), in.HouseGID, in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"secret": secret})
+}
+
+// Test
+// @Summary      测试 webhook
+// @Description  同步发送一次 ping 事件，返回本次投递记录（含响应码/错误）
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.WebhookEndpointIDRequest true "house_gid, id"
+// @Success      200 {object} response.Body{data=game.GameWebhookDelivery}
+// @Router       /shops/webhooks/test [post]
+func (s *WebhookService) Test(c *gin.Context) {
+	var in req.WebhookEndpointIDRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	out, err := s.uc.Test(c.Request.Context(), in.HouseGID, in.ID)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, out)
+}
+
+// ListDeliveries
+// @Summary      webhook 投递记录
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.ListWebhookDeliveriesRequest true "house_gid, endpoint_id, status"
+// @Success      200 {object} response.Body{data=[]game.GameWebhookDelivery}
+// @Router       /shops/webhooks/deliveries/list [post]
+func (s *WebhookService) ListDeliveries(c *gin.Context) {
+	var in req.ListWebhookDeliveriesRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	list, total, err := s.uc.ListDeliveries(c.Request.Context(), in.HouseGID, in.EndpointID, in.Status, in.Page, in.PageSize)
+	if err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total, "page": normPage(in.Page), "page_size": normSize(in.PageSize)})
+}
+
+// Redeliver
+// @Summary      手动重投
+// @Description  重置重试次数后重新入队，请求体与事件 ID 不变
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Param        in body req.RedeliverWebhookRequest true "house_gid, delivery_id"
+// @Success      200 {object} response.Body
+// @Router       /shops/webhooks/deliveries/redeliver [post]
+func (s *WebhookService) Redeliver(c *gin.Context) {
+	var in req.RedeliverWebhookRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.Fail(c, ecode.ParamsFailed, err)
+		return
+	}
+	in.HouseGID, _ = middleware.ScopeHouseGID(c)
+	if err := s.uc.Redeliver(c.Request.Context(), in.HouseGID, in.DeliveryID); err != nil {
+		response.Fail(c, ecode.Failed, err)
+		return
+	}
+	response.Success(c, nil)
+}
@@ -33,12 +33,12 @@ func (s *OpsService) RegisterRouter(r *gin.RouterGroup) {
 	g.GET("/plaza/health", s.Health)
 	g.POST("/plaza/housesByLogin", s.HousesByLogin)
 	// plaza 控制/数据
-	g.POST("/plaza/forbidMembers", s.ForbidMembers)
+	g.POST("/plaza/forbidMembers", middleware.Audit("ops:plaza:forbid"), s.ForbidMembers)
 	g.POST("/plaza/members/pull", s.PullMembers)
 	g.POST("/plaza/table/query", s.QueryTable)
 	// 申请列表与处理
 	g.POST("/plaza/applications/list", s.ListApplications)
-	g.POST("/plaza/applications/respond", s.RespondApplication)
+	g.POST("/plaza/applications/respond", middleware.Audit("ops:plaza:application:respond"), s.RespondApplication)
 }
 
 // Metrics 返回 plaza.Manager 的指标快照
@@ -231,7 +231,10 @@ func (s *OpsService) RespondApplication(c *gin.Context) {
 	m := s.mgr.GetByUser(int(claims.BaseClaims.UserID))
 	for _, sess := range m {
 		if ai, ok := sess.FindApplicationByID(in.MessageID); ok {
-			sess.RespondApplication(ai, in.Agree)
+			if err := sess.RespondApplication(ai, in.Agree); err != nil {
+				response.Fail(c, 409, err)
+				return
+			}
 			response.SuccessWithOK(c)
 			return
 		}
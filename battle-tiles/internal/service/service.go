@@ -19,6 +19,10 @@ var ProviderSet = wire.NewSet(
 	basic.NewBasicLoginService,
 	basic.NewBasicMenuService,
 	basic.NewBasicPermissionService,
+	basic.NewBasicScopeRoleService,
+	basic.NewBasicAuditService,
+	basic.NewBasicSecurityService,
+	basic.NewBasicAPIKeyService,
 
 	game.NewSessionService,
 	game.NewAccountService,
@@ -35,5 +39,16 @@ var ProviderSet = wire.NewSet(
 	game.NewBattleRecordService,
 	game.NewShopGroupService,
 	game.NewMemberService,
+	game.NewGroupSettlementService,
+	game.NewLeaderboardService,
+	game.NewPlayerProfileService,
+	game.NewReportService,
+	game.NewWebhookService,
+	game.NewApplicationRuleService,
+	game.NewOnboardingService,
+	game.NewBlocklistService,
+	game.NewBattleConfigService,
+	game.NewDiamondService,
+	game.NewBattleSyncService,
 	NewSessionMonitor,
 )
@@ -22,12 +22,13 @@ type SessionMonitor struct {
 	sess     gameRepo.SessionRepo
 	ctrlRepo gameRepo.GameCtrlAccountRepo // 新增：用于检查中控账号状态
 	ctrl     *biz.CtrlSessionUseCase
-	syncMgr  *biz.BattleSyncManager // 新增：战绩同步管理器
+	syncMgr  *biz.BattleSyncManager  // 新增：战绩同步管理器
+	apps     *biz.ApplicationUseCase // 游戏内申请超时检查
 	tick     time.Duration
 	stopC    chan struct{}
 }
 
-func NewSessionMonitor(logger log.Logger, mgr plaza.Manager, cloud cloudRepo.BasePlatformRepo, link gameRepo.GameCtrlAccountHouseRepo, sess gameRepo.SessionRepo, ctrlRepo gameRepo.GameCtrlAccountRepo, syncMgr *biz.BattleSyncManager, uc *biz.CtrlSessionUseCase) *SessionMonitor {
+func NewSessionMonitor(logger log.Logger, mgr plaza.Manager, cloud cloudRepo.BasePlatformRepo, link gameRepo.GameCtrlAccountHouseRepo, sess gameRepo.SessionRepo, ctrlRepo gameRepo.GameCtrlAccountRepo, syncMgr *biz.BattleSyncManager, uc *biz.CtrlSessionUseCase, apps *biz.ApplicationUseCase) *SessionMonitor {
 	m := &SessionMonitor{
 		log:      log.NewHelper(log.With(logger, "module", "service/session_monitor")),
 		mgr:      mgr,
@@ -36,6 +37,7 @@ func NewSessionMonitor(logger log.Logger, mgr plaza.Manager, cloud cloudRepo.Bas
 		sess:     sess,
 		ctrlRepo: ctrlRepo,
 		syncMgr:  syncMgr,
+		apps:     apps,
 		tick:     10 * time.Second,
 		stopC:    make(chan struct{}),
 	}
@@ -118,6 +120,9 @@ func (m *SessionMonitor) syncOnce(ctx context.Context) {
 			if _, ok := m.mgr.GetAnyByHouse(int(hg)); ok {
 				// 已有会话（任意用户）→ 什么都不做,会话启动时已经更新了数据库
 				// 不要在这里频繁调用 EnsureOnlineByHouse,会导致每10秒都更新数据库!
+				if m.apps != nil {
+					m.apps.SweepGame(pctx, hg)
+				}
 			} else {
 				// 无任何会话 → 若有绑定中控且注入了 UseCase，尝试自动拉起（以超管 1 身份）
 				started := false
@@ -13,6 +13,14 @@ const (
 	CmdTypeDeleteMember   = 5
 	CmdTypeQueryTable     = 6
 	CmdTypeQueryDiamond   = 7
+	CmdTypeAppendConfig   = 8
+	CmdTypeModifyConfig   = 9
+	CmdTypeDeleteConfig   = 10
+	CmdTypeQueryRecord    = 11
+	CmdTypeSearchGroup    = 12
+	CmdTypeCreateGroup    = 13
+	CmdTypeUpdateGroup    = 14
+	CmdTypeDeleteGroup    = 15
 )
 
 type GameCommand struct {
@@ -20,6 +28,7 @@ type GameCommand struct {
 	Type   int
 	Key    string
 	Member int
+	House  int // 命令所属店铺；同一连接进入多个店铺时用于分发回包
 	//Flag   bool
 }
 
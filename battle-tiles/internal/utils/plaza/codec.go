@@ -0,0 +1,337 @@
+package plaza
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// 报文布局：按字段顺序声明结构，同一份布局既用于解码回包也用于编码命令。
+// 整数均为小端；字符串为定长 UTF-16（与客户端 Lua 的 readstring/pushstring 一致）
+
+// ErrShortPacket 报文长度不足以按布局解出
+var ErrShortPacket = errors.New("short packet")
+
+// Message 可按布局编解码的报文
+type Message interface {
+	Fields() []Field
+}
+
+// Field 布局中的一个字段
+type Field struct {
+	size int // 定长字节数，变长字段为 -1
+	dec  func(r *reader) error
+	enc  func(w *writer)
+}
+
+// Decode 按布局解码；失败时已解出的字段保留（列表只保留完整的项）
+func Decode(data []byte, m Message) error {
+	r := &reader{data: data}
+	return r.fields(m.Fields())
+}
+
+// Encode 按布局编码（不含 8 字节包头）
+func Encode(m Message) []byte {
+	w := &writer{}
+	w.fields(m.Fields())
+	return w.buf
+}
+
+type reader struct {
+	data []byte
+	off  int
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.off < n {
+		return nil, fmt.Errorf("%w: need %d bytes at offset %d, have %d", ErrShortPacket, n, r.off, len(r.data)-r.off)
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b, nil
+}
+
+func (r *reader) remain() int { return len(r.data) - r.off }
+
+func (r *reader) fields(fs []Field) error {
+	for _, f := range fs {
+		if err := f.dec(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) fields(fs []Field) {
+	for _, f := range fs {
+		f.enc(w)
+	}
+}
+
+func (w *writer) zero(n int) {
+	for i := 0; i < n; i++ {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *writer) utf16(units []uint16) {
+	for _, u := range units {
+		w.buf = append(w.buf, byte(u), byte(u>>8))
+	}
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+func intField[T integer](p *T, size int, signed bool) Field {
+	return Field{
+		size: size,
+		dec: func(r *reader) error {
+			b, err := r.next(size)
+			if err != nil {
+				return err
+			}
+			var v uint64
+			for i := size - 1; i >= 0; i-- {
+				v = v<<8 | uint64(b[i])
+			}
+			if signed && size < 8 {
+				shift := 64 - 8*size
+				*p = T(int64(v<<shift) >> shift)
+			} else {
+				*p = T(v)
+			}
+			return nil
+		},
+		enc: func(w *writer) {
+			v := uint64(*p)
+			for i := 0; i < size; i++ {
+				w.buf = append(w.buf, byte(v>>(8*i)))
+			}
+		},
+	}
+}
+
+// Byte 1 字节无符号整数
+func Byte[T integer](p *T) Field { return intField(p, 1, false) }
+
+// Word 2 字节无符号整数
+func Word[T integer](p *T) Field { return intField(p, 2, false) }
+
+// DWord 4 字节无符号整数
+func DWord[T integer](p *T) Field { return intField(p, 4, false) }
+
+// SDWord 4 字节有符号整数（如底分 LONG）
+func SDWord[T integer](p *T) Field { return intField(p, 4, true) }
+
+// Long 8 字节整数（SCORE）
+func Long[T integer](p *T) Field { return intField(p, 8, true) }
+
+// Str n 个 UTF-16 单元的定长字符串，编码时截断到 n-1 保证以 0 结尾。
+// 解码遇 0 或数据结尾即止：服务端的长文本（系统消息等）常不发满
+func Str(p *string, n int) Field {
+	return Field{
+		size: 2 * n,
+		dec: func(r *reader) error {
+			k := min(r.remain()/2, n)
+			*p = decodeUTF16(r.data[r.off : r.off+2*k])
+			r.off += min(r.remain(), 2*n)
+			return nil
+		},
+		enc: func(w *writer) {
+			units := utf16.Encode([]rune(*p))
+			if len(units) > n-1 {
+				units = units[:n-1]
+			}
+			w.utf16(units)
+			w.zero(2 * (n - len(units)))
+		},
+	}
+}
+
+// StrRest 直到报文结尾的变长字符串
+func StrRest(p *string) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			*p = decodeUTF16(r.data[r.off:])
+			r.off = len(r.data)
+			return nil
+		},
+		enc: func(w *writer) {
+			w.utf16(utf16.Encode([]rune(*p)))
+			w.zero(2)
+		},
+	}
+}
+
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u := uint16(b[i]) | uint16(b[i+1])<<8
+		if u == 0 {
+			break
+		}
+		units = append(units, u)
+	}
+	return string(utf16.Decode(units))
+}
+
+// Skip 跳过 n 字节（不关心的字段），编码时写 0
+func Skip(n int) Field {
+	return Field{
+		size: n,
+		dec: func(r *reader) error {
+			_, err := r.next(n)
+			return err
+		},
+		enc: func(w *writer) { w.zero(n) },
+	}
+}
+
+// PadTo 补齐到报文（列表项内为该项）开头起 n 字节
+func PadTo(n int) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			if r.off < n {
+				_, err := r.next(n - r.off)
+				return err
+			}
+			return nil
+		},
+		enc: func(w *writer) { w.zero(n - len(w.buf)) },
+	}
+}
+
+// Rest 剩余的原始字节
+func Rest(p *[]byte) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			*p = append([]byte(nil), r.data[r.off:]...)
+			r.off = len(r.data)
+			return nil
+		},
+		enc: func(w *writer) { w.buf = append(w.buf, *p...) },
+	}
+}
+
+// ListW 以 WORD 计数开头的列表，每项按 item 返回的布局编解码
+func ListW[E any](items *[]*E, item func(*E) []Field) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			var n uint16
+			if err := Word(&n).dec(r); err != nil {
+				return err
+			}
+			*items = nil
+			for i := 0; i < int(n); i++ {
+				e := new(E)
+				if err := r.fields(item(e)); err != nil {
+					return err
+				}
+				*items = append(*items, e)
+			}
+			return nil
+		},
+		enc: func(w *writer) {
+			n := uint16(len(*items))
+			Word(&n).enc(w)
+			for _, e := range *items {
+				w.fields(item(e))
+			}
+		},
+	}
+}
+
+// Items 直到报文结尾的定长项列表（每项 size 字节），不足一项的尾部忽略
+func Items[E any](items *[]*E, size int, item func(*E) []Field) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			*items = nil
+			for r.remain() >= size {
+				e := new(E)
+				sub := &reader{data: r.data[r.off : r.off+size]}
+				r.off += size
+				if err := sub.fields(item(e)); err != nil {
+					return err
+				}
+				*items = append(*items, e)
+			}
+			r.off = len(r.data)
+			return nil
+		},
+		enc: func(w *writer) {
+			for _, e := range *items {
+				sub := &writer{}
+				sub.fields(item(e))
+				sub.zero(size - len(sub.buf))
+				w.buf = append(w.buf, sub.buf[:size]...)
+			}
+		},
+	}
+}
+
+// Optional 可选的尾部字段：剩余长度不足其中定长部分时整组不解码（present 为 false）；
+// present 为 nil 时编码总是写出，否则只在 *present 为 true 时写出
+func Optional(present *bool, fs ...Field) Field {
+	size := 1
+	if fixed := fixedSize(fs); fixed > 0 {
+		size = fixed
+	}
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			ok := r.remain() >= size
+			if present != nil {
+				*present = ok
+			}
+			if !ok {
+				return nil
+			}
+			return r.fields(fs)
+		},
+		enc: func(w *writer) {
+			if present == nil || *present {
+				w.fields(fs)
+			}
+		},
+	}
+}
+
+// When cond 成立时才有的字段（如按掩码出现的财富字段）
+func When(cond func() bool, fs ...Field) Field {
+	return Field{
+		size: -1,
+		dec: func(r *reader) error {
+			if !cond() {
+				return nil
+			}
+			return r.fields(fs)
+		},
+		enc: func(w *writer) {
+			if cond() {
+				w.fields(fs)
+			}
+		},
+	}
+}
+
+// fixedSize 字段中定长部分的字节数之和
+func fixedSize(fs []Field) int {
+	n := 0
+	for _, f := range fs {
+		if f.size > 0 {
+			n += f.size
+		}
+	}
+	return n
+}
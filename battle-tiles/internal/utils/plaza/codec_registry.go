@@ -0,0 +1,140 @@
+package plaza
+
+import (
+	"battle-tiles/internal/dal/vo/game"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Direction 报文方向
+type Direction int
+
+const (
+	Inbound  Direction = iota // 服务端回包/推送
+	Outbound                  // 客户端命令
+)
+
+// CmdKey 主/子命令号
+type CmdKey struct {
+	Main, Sub uint16
+}
+
+func (k CmdKey) String() string { return fmt.Sprintf("%d/%d", k.Main, k.Sub) }
+
+// Codec 一种报文的编解码器；New 返回承载该报文布局的空结构
+type Codec struct {
+	Key  CmdKey
+	Dir  Direction
+	Name string
+	New  func() Message
+}
+
+// Decode 按布局解码报文数据（不含包头）
+func (c *Codec) Decode(data []byte) (Message, error) {
+	m := c.New()
+	if err := Decode(data, m); err != nil {
+		return m, fmt.Errorf("%s(%s): %w", c.Name, c.Key, err)
+	}
+	return m, nil
+}
+
+// Packet 编码为带命令头的发送包
+func (c *Codec) Packet(m Message) *game.Packer {
+	packer := &game.Packer{}
+	packer.SetCmd(int(c.Key.Main), int(c.Key.Sub))
+	for _, b := range Encode(m) {
+		packer.PushByte(b)
+	}
+	return packer
+}
+
+var registry = struct {
+	sync.RWMutex
+	byKey  map[Direction]map[CmdKey]*Codec
+	byType map[reflect.Type]*Codec // 命令结构 -> 编码器
+}{
+	byKey:  map[Direction]map[CmdKey]*Codec{Inbound: {}, Outbound: {}},
+	byType: map[reflect.Type]*Codec{},
+}
+
+// RegisterCodec 登记编解码器；同方向同命令号或同一命令结构重复登记会 panic
+func RegisterCodec(c Codec) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, dup := registry.byKey[c.Dir][c.Key]; dup {
+		panic(fmt.Sprintf("plaza: codec %s already registered", c.Key))
+	}
+	cp := &c
+	registry.byKey[c.Dir][c.Key] = cp
+	if c.Dir == Outbound {
+		t := reflect.TypeOf(c.New())
+		if _, dup := registry.byType[t]; dup {
+			panic(fmt.Sprintf("plaza: command %v already registered", t))
+		}
+		registry.byType[t] = cp
+	}
+}
+
+// LookupCodec 按方向与命令号查找
+func LookupCodec(dir Direction, main, sub uint16) (*Codec, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.byKey[dir][CmdKey{main, sub}]
+	return c, ok
+}
+
+// Codecs 已登记的全部编解码器（按方向、主、子命令号排序）
+func Codecs() []*Codec {
+	registry.RLock()
+	defer registry.RUnlock()
+	var out []*Codec
+	for _, m := range registry.byKey {
+		for _, c := range m {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Dir != b.Dir {
+			return a.Dir < b.Dir
+		}
+		if a.Key.Main != b.Key.Main {
+			return a.Key.Main < b.Key.Main
+		}
+		return a.Key.Sub < b.Key.Sub
+	})
+	return out
+}
+
+// Pack 按命令结构登记的命令号编码；未登记属于编程错误，直接 panic
+func Pack(m Message) *game.Packer {
+	registry.RLock()
+	c, ok := registry.byType[reflect.TypeOf(m)]
+	registry.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("plaza: command %T not registered", m))
+	}
+	return c.Packet(m)
+}
+
+// 未登记的回包计数（进程级，按命令号）
+var unknownPackets sync.Map // CmdKey -> *atomic.Int64
+
+// countUnknown 计数并返回该命令号累计出现的次数
+func countUnknown(key CmdKey) int64 {
+	v, _ := unknownPackets.LoadOrStore(key, new(atomic.Int64))
+	return v.(*atomic.Int64).Add(1)
+}
+
+// UnknownPacketCounts 未登记回包的累计次数（"main/sub" -> 次数）
+func UnknownPacketCounts() map[string]int64 {
+	out := make(map[string]int64)
+	unknownPackets.Range(func(k, v any) bool {
+		out[k.(CmdKey).String()] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
@@ -0,0 +1,206 @@
+package plaza
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"battle-tiles/internal/consts"
+	"battle-tiles/internal/dal/vo/game"
+)
+
+const testPwd = "ABCDEF0123456789ABCDEF0123456789"
+
+// codecSamples 每个已登记编解码器的样例报文
+func codecSamples() map[Direction]map[CmdKey]Message {
+	group := GroupProperty{GroupID: 60870, CreaterID: 7, CreaterGameID: 880001, MemberCount: 12, MaxMemberCount: 500, Name: "一号店"}
+	cfg := BattleConfig{ConfigID: 9, KindID: 3, BaseScore: 5, PlayCount: 8, PlayerCount: 4, Name: "红中玩法"}
+	record := &BattleRecordPage{PageIndex: 1, PageCount: 3, TotalCount: 120, Records: []*game.BattleInfo{
+		{RoomID: 123456, KindID: 3, BaseScore: -2, CreateTime: 1700000000, Players: []*game.BattleSettle{
+			{UserGameID: 880001, Score: 35}, {UserGameID: 880002, Score: -35},
+		}},
+		{RoomID: 123457, KindID: 5, BaseScore: 1, CreateTime: 1700000600, Players: []*game.BattleSettle{{UserGameID: 880003}}},
+	}}
+	remote := *record
+	head := newLogonHead()
+	k := func(main, sub int) CmdKey { return CmdKey{uint16(main), uint16(sub)} }
+
+	return map[Direction]map[CmdKey]Message{
+		Inbound: {
+			k(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_SUCCESS):     &userLogonReply{game.UserLogonInfo{UserID: 77, GameID: 880001}},
+			k(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_FAILURE):     &LogonFailure{Code: 3, Desc: "密码错误"},
+			k(consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_SERVER): &serverList{Data: []byte{1, 2, 3, 0xc6, 0xed}},
+			k(consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_ACCESS): &accessList{Items: []*Access{{ID: 1, Port: 8700, Addr: "10.0.0.1"}, {ID: 2, Port: 8701, Addr: "10.0.0.2"}}},
+			k(consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_FINISH): &emptyReply{},
+
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_LIST): &TableList{Tables: []*TableInfo{
+				{TableID: 1, MappedNum: 123456, GroupID: 60870, KindID: 3, BaseScore: 5},
+				{TableID: 2, MappedNum: 123457, GroupID: 60871, KindID: 5, BaseScore: -1},
+			}},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_BATTLE_RECORD):  record,
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_RESULT): &DismissTableResult{Code: 1, Msg: "桌子已解散"},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_SITDOWN):   &UserSitDown{GroupID: 60870, MappedNum: 123456, ChairID: 2, UserID: 77, GameID: 880001},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_STANDUP):   &UserStandUp{ChairID: 2, UserID: 77, MappedNum: 123456},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_DISMISS):  &DismissResult{GroupID: 60870, MappedNum: 123456},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_RENEW):    &TableRenew{MappedNum: 123456, NewMappedNum: 654321},
+			k(consts.MDM_GP_REMOTE_SERVICE, consts.SUB_GP_BATTLE_RECORD):  &remote,
+
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_MESSAGE): &applyList{Type: 1, Items: []*applyItem{{
+				MessageID: 11, Status: 0, ApplierID: 78, ApplierGameID: 880002, NickName: "玩家甲",
+				Time:    systemTime{Year: 2024, Month: 5, DayOfWeek: 3, Day: 8, Hour: 12, Minute: 30, Second: 15, Milliseconds: 500},
+				GroupID: 60870, CreatorID: 7,
+			}}},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SEARCH_RESULT):   &searchResult{Found: true, Group: group},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_WEALTH_UPDATE):   &wealthUpdate{UserWealth{Mask: consts.WEALTH_MASK_INGOT | consts.WEALTH_MASK_SCORE, Ingot: 1200, Score: -5}},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_SUCCESS): &emptyReply{},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_FAILURE): &SystemMessage{Type: 1, Text: "权限不足"},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SYSTEM_MESSAGE):  &SystemMessage{Type: 2, Text: "茶馆服务不可用。请稍后再次重试"},
+
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_ITEM):     &groupItems{Items: []*GroupProperty{&group, {GroupID: 60871, Name: "二号店"}}},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_PROPERTY): &group,
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_MEMBER): &groupMemberList{Members: []*GroupMember{
+				{UserID: 77, UserStatus: 1, GameID: 880001, MemberID: 5, MemberType: 2, MemberRight: consts.MEMBER_RIGHT_FORBID, NickName: "馆主"},
+				{UserID: 78, GameID: 880002, MemberID: 6, NickName: "player"},
+			}},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_UPDATE):  &GroupProperty{GroupID: 60870, Name: "改名后"},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_DELETE):  &groupDelete{GroupID: 60870},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_INSERT): &MemberInserted{GroupID: 60870, MemCount: 13, UserID: 79, GameID: 880003},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_DELETE): &MemberDeleted{GroupID: 60870, MemberID: 6},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_UPDATE): &MemberUpdated{GroupID: 60870, MemberID: 6, MemberType: 1, MemberRight: consts.MEMBER_RIGHT_FORBID},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_BATTLE_CONFIG): &battleConfigList{Items: []*BattleConfig{&cfg, {ConfigID: 10, KindID: 5, Name: "跑得快"}}},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_APPEND): &cfg,
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_MODIFY): &BattleConfig{ConfigID: 9, KindID: 3, BaseScore: 10, PlayCount: 16, PlayerCount: 4, Name: "红中"},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_DELETE): &configDelete{ConfigID: 9},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GP_USER_WEALTH):   &userWealthReply{UserWealth: UserWealth{UserID: 77, Ingot: 1200, Medal: 3}, HasMedal: true},
+		},
+		Outbound: {
+			{0, 1}: &heartBeatCmd{},
+			k(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_ACCOUNTS_LUA): &accountLogonCmd{logonHead: head, Password: testPwd, Accounts: "acc01", MachineID: "0123456789ABCDEF0123456789ABCDEF"},
+			k(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_MOBILEPHONE):  &mobileLogonCmd{logonHead: head, Password: testPwd, MobilePhone: "13800000000", MachineID: "0123456789ABCDEF0123456789ABCDEF"},
+
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_QUERY_TABLE):   &queryTableCmd{MappedNum: 123456},
+			k(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_TABLE): &dismissTableCmd{KindID: 3, MappedNum: 123456, UserID: 77, Password: testPwd},
+
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_LOGON_SERVER):      &logonServerCmd{UserID: 77, StationID: consts.STATION_ID, Flag: 4, Password: testPwd},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SEARCH_GROUP):      &searchGroupCmd{GroupID: 60870},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_CREATE_GROUP):      &createGroupCmd{UserID: 77, Name: "一号圈", Password: testPwd},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_UPDATE_GROUP):      &updateGroupCmd{GroupID: 5, Name: "二号圈", UserID: 77, Password: testPwd},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_GROUP):      &deleteGroupCmd{GroupID: 5, UserID: 77, Password: testPwd},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_UPDATE_MEMBER):     &updateMemberCmd{GroupID: 60870, MemberID: 6, UserID: 77, Password: testPwd, Kind: consts.UPMEMBER_KIND_RIGHT, Right: consts.MEMBER_RIGHT_FORBID},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_MEMBER):     &deleteMemberCmd{GroupID: 60870, MemberID: 6, UserID: 77, Password: testPwd},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_RESPOND):     &applyRespondCmd{MessageID: 11, UserID: 77, Password: testPwd, GroupID: 60870, ApplierGameID: 880002, Status: 1},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPEND_CONFIG):     &appendConfigCmd{configCmd{GroupID: 60870, Config: cfg, UserID: 77, Password: testPwd}},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_MODIFY_CONFIG):     &modifyConfigCmd{configCmd{GroupID: 60870, Config: cfg, UserID: 77, Password: testPwd}},
+			k(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_CONFIG):     &deleteConfigCmd{GroupID: 60870, ConfigID: 9, UserID: 77, Password: testPwd},
+			k(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_ENTER_GROUP):       &enterGroupCmd{UserID: 77, GroupID: 60870},
+			k(consts.MDM_GR_USER, consts.SUB_GR_USER_STANDUP):               &userStandUpCmd{TableID: 3, ChairID: 2},
+			k(consts.MDM_GP_USER_SERVICE, consts.SUB_GP_QUERY_WEALTH_LUA):   &queryWealthCmd{UserID: 77},
+			k(consts.MDM_GA_MESSAGE_SERVICE, consts.SUB_GA_ENTER_MESSAGE):   &enterMessageCmd{UserID: 77, StationID: consts.STATION_ID},
+			k(consts.MDM_GP_REMOTE_SERVICE, consts.SUB_GP_QUERY_RECORD_LUA): &queryRecordCmd{UserID: 77, GroupID: 60870, StartTime: 1700000000, EndTime: 1700003600, PageIndex: 2, PageSize: 50},
+		},
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	samples := codecSamples()
+	for _, c := range Codecs() {
+		want, ok := samples[c.Dir][c.Key]
+		if !ok {
+			t.Errorf("%s(%s): no sample", c.Name, c.Key)
+			continue
+		}
+		if reflect.TypeOf(want) != reflect.TypeOf(c.New()) {
+			t.Errorf("%s(%s): sample is %T, codec builds %T", c.Name, c.Key, want, c.New())
+			continue
+		}
+		data := Encode(want)
+		got, err := c.Decode(data)
+		if err != nil {
+			t.Errorf("%s(%s): %v", c.Name, c.Key, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s(%s): round trip mismatch\n got %+v\nwant %+v", c.Name, c.Key, got, want)
+		}
+		if pk := c.Packet(want); pk.Head.Cmd != (game.CmdCommand{MainCmdID: c.Key.Main, SubCmdID: c.Key.Sub}) || !bytes.Equal(pk.Data(), data) {
+			t.Errorf("%s(%s): packet header or payload mismatch", c.Name, c.Key)
+		}
+		delete(samples[c.Dir], c.Key)
+	}
+	for dir, m := range samples {
+		for key := range m {
+			t.Errorf("sample %s (dir %d) has no registered codec", key, dir)
+		}
+	}
+}
+
+// 旧版按偏移手写的命令字节，改为布局后必须一致
+func TestCmdGoldenBytes(t *testing.T) {
+	st := time.Unix(1700000000, 0)
+	cfg := &BattleConfig{ConfigID: 9, KindID: 3, BaseScore: 5, PlayCount: 8, PlayerCount: 4, Name: "红中玩法"}
+	cases := []struct {
+		name string
+		pk   *game.Packer
+		want string
+	}{
+		{"standup", CmdUserStandUp(3, 2), "00000000030004000300020000"},
+		{"forbid", CmdForbidMember(11, testPwd, 22, 33, true), "000000000200060016000000210000000b000000410042004300440045004600300031003200330034003500360037003800390041004200430044004500460030003100320033003400350036003700380039000000020000100000"},
+		{"respond", CmdRespondApplication(77, "abcdef0123456789ABCDEF0123456789", 1, 60870, 888, true), "0000000002000900010000004d000000410042004300440045004600300031003200330034003500360037003800390041004200430044004500460030003100320033003400350036003700380039000000c6ed00007803000001"},
+		{"record", CmdQueryBattleRecord(77, 60870, st, st.Add(time.Hour), 2, 50), "00000000060070004d000000c6ed000000f1536510ff536502003200"},
+		{"create", CmdCreateGroup(77, "abcdef0123456789ABCDEF0123456789", "一号圈"), "00000000020003004d000000004ef753085700000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000410042004300440045004600300031003200330034003500360037003800390041004200430044004500460030003100320033003400350036003700380039000000"},
+		{"append", CmdAppendConfig(77, "abcdef0123456789ABCDEF0123456789", 60870, cfg), "0000000002001e00c6ed00000900000003000500000008000400a27e2d4ea973d56c00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004d000000410042004300440045004600300031003200330034003500360037003800390041004200430044004500460030003100320033003400350036003700380039000000"},
+	}
+	for _, tc := range cases {
+		if got := hex.EncodeToString(tc.pk.Bytes()); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeTolerance(t *testing.T) {
+	// 空搜索结果即未找到
+	var sr searchResult
+	if err := Decode(nil, &sr); err != nil || sr.Found {
+		t.Fatalf("empty search result: found=%v err=%v", sr.Found, err)
+	}
+	// 群组属性名称可缺失
+	g := ParseGroupProperty(Encode(&GroupProperty{GroupID: 1, Name: "x"})[:16])
+	if g == nil || g.GroupID != 1 || g.Name != "" {
+		t.Fatalf("group without name = %+v", g)
+	}
+	// 财富更新截断时只保留完整字段
+	w := ParseWealthUpdate(Encode(&wealthUpdate{UserWealth{Mask: consts.WEALTH_MASK_INGOT | consts.WEALTH_MASK_MEDAL, Ingot: 9, Medal: 1}})[:12])
+	if !w.HasIngot() || w.Ingot != 9 || w.Mask != consts.WEALTH_MASK_INGOT {
+		t.Fatalf("truncated wealth = %+v", w)
+	}
+	// 截断的约战记录丢弃不完整的一条
+	page := ParseBattleRecordPage(Encode(codecSamples()[Inbound][CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_BATTLE_RECORD}])[:50])
+	if page == nil || len(page.Records) != 1 || page.TotalCount != 120 {
+		t.Fatalf("truncated page = %+v", page)
+	}
+}
+
+func TestDispatchFallbacks(t *testing.T) {
+	s := newOfflineSession(60870)
+	before := UnknownPacketCounts()["9/9"]
+	pk := &game.Packer{}
+	pk.SetCmd(9, 9)
+	pk.PushByte(1)
+	s.dispatch("87", routes87, pk)
+	s.dispatch("87", routes87, pk)
+	if got := UnknownPacketCounts()["9/9"]; got != before+2 {
+		t.Fatalf("unknown count = %d, want %d", got, before+2)
+	}
+
+	// 成员列表解析失败也要释放命令队列
+	s._87waitingForCmdResponse.Store(true)
+	pk = &game.Packer{}
+	pk.SetCmd(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_MEMBER)
+	pk.PushByte(1)
+	s.dispatch("87", routes87, pk)
+	if s._87waitingForCmdResponse.Load() {
+		t.Fatal("malformed member list should release the command queue")
+	}
+}
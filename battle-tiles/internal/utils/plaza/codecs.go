@@ -0,0 +1,84 @@
+package plaza
+
+import "battle-tiles/internal/consts"
+
+// 本包已知报文的编解码器。新增报文：声明带 Fields 的结构后在此登记，
+// 回包再在 session_dispatch.go 中挂处理函数
+
+func init() {
+	for _, c := range []Codec{
+		// 82 登录与列表
+		{Key: CmdKey{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_SUCCESS}, Name: "logon_success", New: func() Message { return new(userLogonReply) }},
+		{Key: CmdKey{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_FAILURE}, Name: "logon_failure", New: func() Message { return new(LogonFailure) }},
+		{Key: CmdKey{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_SERVER}, Name: "list_server", New: func() Message { return new(serverList) }},
+		{Key: CmdKey{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_ACCESS}, Name: "list_access", New: func() Message { return new(accessList) }},
+		{Key: CmdKey{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_FINISH}, Name: "list_finish", New: func() Message { return new(emptyReply) }},
+
+		// 约战服务
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_LIST}, Name: "table_list", New: func() Message { return new(TableList) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_BATTLE_RECORD}, Name: "battle_record", New: func() Message { return new(BattleRecordPage) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_RESULT}, Name: "dismiss_result", New: func() Message { return new(DismissTableResult) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_SITDOWN}, Name: "user_sitdown", New: func() Message { return new(UserSitDown) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_STANDUP}, Name: "user_standup", New: func() Message { return new(UserStandUp) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_DISMISS}, Name: "table_dismiss", New: func() Message { return new(DismissResult) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_RENEW}, Name: "table_renew", New: func() Message { return new(TableRenew) }},
+		{Key: CmdKey{consts.MDM_GP_REMOTE_SERVICE, consts.SUB_GP_BATTLE_RECORD}, Name: "remote_battle_record", New: func() Message { return new(BattleRecordPage) }},
+
+		// 逻辑服务
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_MESSAGE}, Name: "apply_message", New: func() Message { return new(applyList) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SEARCH_RESULT}, Name: "search_result", New: func() Message { return new(searchResult) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_WEALTH_UPDATE}, Name: "wealth_update", New: func() Message { return new(wealthUpdate) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_SUCCESS}, Name: "operate_success", New: func() Message { return new(emptyReply) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_FAILURE}, Name: "operate_failure", New: func() Message { return new(SystemMessage) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SYSTEM_MESSAGE}, Name: "system_message", New: func() Message { return new(SystemMessage) }},
+
+		// 群组服务
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_ITEM}, Name: "group_item", New: func() Message { return new(groupItems) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_PROPERTY}, Name: "group_property", New: func() Message { return new(GroupProperty) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_MEMBER}, Name: "group_member", New: func() Message { return new(groupMemberList) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_UPDATE}, Name: "group_update", New: func() Message { return new(GroupProperty) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_DELETE}, Name: "group_delete", New: func() Message { return new(groupDelete) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_INSERT}, Name: "member_insert", New: func() Message { return new(MemberInserted) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_DELETE}, Name: "member_delete", New: func() Message { return new(MemberDeleted) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_UPDATE}, Name: "member_update", New: func() Message { return new(MemberUpdated) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_BATTLE_CONFIG}, Name: "battle_config", New: func() Message { return new(battleConfigList) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_APPEND}, Name: "config_append", New: func() Message { return new(BattleConfig) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_MODIFY}, Name: "config_modify", New: func() Message { return new(BattleConfig) }},
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_DELETE}, Name: "config_delete", New: func() Message { return new(configDelete) }},
+		// 与 SUB_GA_ENTER_FAILURE 同号，只在等待查询财富回包时按此解析
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GP_USER_WEALTH}, Name: "user_wealth", New: func() Message { return new(userWealthReply) }},
+	} {
+		c.Dir = Inbound
+		RegisterCodec(c)
+	}
+
+	for _, c := range []Codec{
+		{Key: CmdKey{0, 1}, Name: "heartbeat", New: func() Message { return new(heartBeatCmd) }},
+		{Key: CmdKey{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_ACCOUNTS_LUA}, Name: "logon_accounts", New: func() Message { return new(accountLogonCmd) }},
+		{Key: CmdKey{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_MOBILEPHONE}, Name: "logon_mobile", New: func() Message { return new(mobileLogonCmd) }},
+
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_QUERY_TABLE}, Name: "query_table", New: func() Message { return new(queryTableCmd) }},
+		{Key: CmdKey{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_TABLE}, Name: "dismiss_table", New: func() Message { return new(dismissTableCmd) }},
+
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_LOGON_SERVER}, Name: "logon_server", New: func() Message { return new(logonServerCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SEARCH_GROUP}, Name: "search_group", New: func() Message { return new(searchGroupCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_CREATE_GROUP}, Name: "create_group", New: func() Message { return new(createGroupCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_UPDATE_GROUP}, Name: "update_group", New: func() Message { return new(updateGroupCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_GROUP}, Name: "delete_group", New: func() Message { return new(deleteGroupCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_UPDATE_MEMBER}, Name: "update_member", New: func() Message { return new(updateMemberCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_MEMBER}, Name: "delete_member", New: func() Message { return new(deleteMemberCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_RESPOND}, Name: "apply_respond", New: func() Message { return new(applyRespondCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPEND_CONFIG}, Name: "append_config", New: func() Message { return new(appendConfigCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_MODIFY_CONFIG}, Name: "modify_config", New: func() Message { return new(modifyConfigCmd) }},
+		{Key: CmdKey{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_CONFIG}, Name: "delete_config", New: func() Message { return new(deleteConfigCmd) }},
+
+		{Key: CmdKey{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_ENTER_GROUP}, Name: "enter_group", New: func() Message { return new(enterGroupCmd) }},
+		{Key: CmdKey{consts.MDM_GR_USER, consts.SUB_GR_USER_STANDUP}, Name: "user_standup", New: func() Message { return new(userStandUpCmd) }},
+		{Key: CmdKey{consts.MDM_GP_USER_SERVICE, consts.SUB_GP_QUERY_WEALTH_LUA}, Name: "query_wealth", New: func() Message { return new(queryWealthCmd) }},
+		{Key: CmdKey{consts.MDM_GA_MESSAGE_SERVICE, consts.SUB_GA_ENTER_MESSAGE}, Name: "enter_message", New: func() Message { return new(enterMessageCmd) }},
+		{Key: CmdKey{consts.MDM_GP_REMOTE_SERVICE, consts.SUB_GP_QUERY_RECORD_LUA}, Name: "query_record", New: func() Message { return new(queryRecordCmd) }},
+	} {
+		c.Dir = Outbound
+		RegisterCodec(c)
+	}
+}
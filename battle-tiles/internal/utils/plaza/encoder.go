@@ -1,6 +1,10 @@
 package plaza
 
-import "battle-tiles/internal/dal/vo/game"
+import (
+	"battle-tiles/internal/dal/vo/game"
+	"errors"
+	"fmt"
+)
 
 var (
 	sendMap = []byte{
@@ -46,6 +50,9 @@ const (
 	SocketVersion = 0x1
 )
 
+// ErrPacketSize 包头中的长度小于包头或超出收到的数据
+var ErrPacketSize = errors.New("bad packet size")
+
 type Encoder struct {
 	sendRound        byte
 	recvRound        byte
@@ -59,6 +66,9 @@ func (e *Encoder) Decrypt(data []byte) (*game.Packer, error) {
 	if err := packer.Load(data); err != nil {
 		return nil, err
 	}
+	if n := packer.BufferSize(); n < 8 || n > len(data) {
+		return nil, fmt.Errorf("%w: head=%d len=%d", ErrPacketSize, n, len(data))
+	}
 
 	if err := e.doDecrypt(packer); err != nil {
 		return nil, err
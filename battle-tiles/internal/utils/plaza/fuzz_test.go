@@ -0,0 +1,152 @@
+package plaza
+
+import (
+	"errors"
+	"testing"
+)
+
+// 模糊测试：任意（截断、错位、超长）报文都只能返回错误或部分结果，不能 panic。
+// go test 只跑种子；深跑用 go test -run=^$ -fuzz=FuzzParse ./internal/utils/plaza
+
+// parsers 全部 ParseXxx
+var parsers = map[string]func([]byte) any{
+	"UserLogon":          func(b []byte) any { return ParseUserLogon(b) },
+	"ServerAgent":        func(b []byte) any { return ParseServerAgent(b) },
+	"ListAccess":         func(b []byte) any { return ParseListAccess(b) },
+	"SystemMessage":      func(b []byte) any { return ParseSystemMessage(b) },
+	"MessageList":        func(b []byte) any { return ParseMessageList(b) },
+	"LogonFailure":       func(b []byte) any { return ParseLogonFailure(b) },
+	"GroupMember":        func(b []byte) any { return ParseGroupMember(b) },
+	"UserSitDown":        func(b []byte) any { return ParseUserSitDown(b) },
+	"UserStandUp":        func(b []byte) any { return ParseUserStandUp(b) },
+	"TableUserItem":      func(b []byte) any { return ParseTableUserItem(b) },
+	"TableList":          func(b []byte) any { return ParseTableList(b) },
+	"ApplyList":          func(b []byte) any { return ParseApplyList(b) },
+	"DismissTable":       func(b []byte) any { return ParseDismissTable(b) },
+	"BattleOpFail":       func(b []byte) any { return ParseBattleOpFail(b) },
+	"DismissTableResult": func(b []byte) any { return ParseDismissTableResult(b) },
+	"MemberInserted":     func(b []byte) any { return ParseMemberInserted(b) },
+	"MemberUpdated":      func(b []byte) any { return ParseMemberUpdated(b) },
+	"MemberDeleted":      func(b []byte) any { return ParseMemberDeleted(b) },
+	"TableUserList":      func(b []byte) any { return ParseTableUserList(b) },
+	"TableDismissed":     func(b []byte) any { return ParseTableDismissed(b) },
+	"TableRenew":         func(b []byte) any { return ParseTableRenew(b) },
+	"BattleConfigList":   func(b []byte) any { return ParseBattleConfigList(b) },
+	"BattleConfigItem":   func(b []byte) any { return ParseBattleConfigItem(b) },
+	"BattleConfigDelete": func(b []byte) any { return ParseBattleConfigDelete(b) },
+	"UserWealth":         func(b []byte) any { return ParseUserWealth(b) },
+	"WealthUpdate":       func(b []byte) any { return ParseWealthUpdate(b) },
+	"GroupProperty":      func(b []byte) any { return ParseGroupProperty(b) },
+	"GroupItems":         func(b []byte) any { return ParseGroupItems(b) },
+	"GroupDelete":        func(b []byte) any { return ParseGroupDelete(b) },
+	"BattleRecordPage":   func(b []byte) any { return ParseBattleRecordPage(b) },
+}
+
+// addPacketSeeds 以各样例报文及其截断、奇数长度版本作种子
+func addPacketSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0xff})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff}) // 超大计数/长度
+	f.Add([]byte{0, 0, 1, 0, 0})                // 截断的接入列表
+	for _, m := range codecSamples()[Inbound] {
+		data := Encode(m)
+		f.Add(data)
+		f.Add(data[:len(data)/2])
+		if len(data) > 1 {
+			f.Add(data[:len(data)-1])
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	addPacketSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for name, parse := range parsers {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("Parse%s panic on % x: %v", name, data, r)
+					}
+				}()
+				parse(data)
+			}()
+		}
+	})
+}
+
+func FuzzCodecDecode(f *testing.F) {
+	addPacketSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, c := range Codecs() {
+			if c.Dir != Inbound {
+				continue
+			}
+			if _, err := c.Decode(data); err != nil && !errors.Is(err, ErrShortPacket) {
+				t.Fatalf("%s: unexpected error %v", c.Name, err)
+			}
+		}
+	})
+}
+
+func FuzzEncoderDecrypt(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 0, 4, 0, 0, 0, 0, 0})       // 长度小于包头
+	f.Add([]byte{1, 0, 0xff, 0xff, 0, 0, 0, 0}) // 长度超出数据
+	f.Add((&Encoder{}).Encrypt(Pack(&heartBeatCmd{})))
+	f.Add((&Encoder{}).Encrypt(CmdUserStandUp(1, 2)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var e Encoder
+		for i := 0; i < 2; i++ { // 第二次用上一次留下的解密状态
+			pk, err := e.Decrypt(data)
+			if err != nil {
+				continue
+			}
+			if n := len(pk.Data()); n != pk.BufferSize()-8 {
+				t.Fatalf("data len = %d, packet size %d", n, pk.BufferSize())
+			}
+		}
+	})
+}
+
+func FuzzRecvBufAdd(f *testing.F) {
+	f.Add([]byte{}, uint16(0))
+	f.Add([]byte{1, 0, 8, 0, 1, 2, 3, 4}, uint16(3))
+	f.Add([]byte{1, 0, 0, 0, 1, 2, 3, 4}, uint16(0))     // 长度为 0
+	f.Add([]byte{1, 0, 10, 0, 1, 2, 3, 4, 5}, uint16(2)) // 不完整
+	f.Add([]byte{1, 0, 8, 0, 1, 2, 3, 4, 1, 0, 9, 0, 1, 2, 3, 4, 5}, uint16(11))
+	f.Fuzz(func(t *testing.T, data []byte, split uint16) {
+		ch := make(chan []byte, len(data)/headSize+1) // 每个包至少一个包头，不会阻塞
+		rb := NewRecvBuf(ch)
+		at := min(int(split), len(data))
+		for _, part := range [][]byte{data[:at], data[at:]} {
+			if err := rb.Add(part); err != nil {
+				if !errors.Is(err, ErrPacketSize) {
+					t.Fatalf("unexpected error %v", err)
+				}
+				break
+			}
+		}
+		close(ch)
+		for p := range ch {
+			if len(p) < headSize || int(p[2])|int(p[3])<<8 != len(p) {
+				t.Fatalf("bad packet % x", p)
+			}
+		}
+	})
+}
+
+func TestHandlePacketSurvives(t *testing.T) {
+	s := newOfflineSession(60870)
+	s._87encoder = &Encoder{}
+	s._87handlePacket([]byte{1, 0, 4, 0, 0, 0, 0, 0})
+	if got := s.ProtocolErrors(); got != 1 {
+		t.Fatalf("errors = %d, want 1", got)
+	}
+
+	// 处理中的 panic 被拦下并计数
+	s._87encoder = nil
+	s._87handlePacket([]byte{1, 0, 8, 0, 0, 0, 0, 0})
+	if got := s.ProtocolErrors(); got != 2 {
+		t.Fatalf("errors = %d, want 2", got)
+	}
+}
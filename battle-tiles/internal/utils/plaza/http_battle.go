@@ -16,16 +16,15 @@ type HTTPDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
-func GetGroupNewBattleInfoCtx(ctx context.Context, httpc HTTPDoer, base string, group, typid int) ([]*game.BattleInfo, error) {
-	// base 例如: http://phone2.foxuc.com/Ashx/GroService.ashx
+func GetGroupNewBattleInfoCtx(ctx context.Context, httpc HTTPDoer, gro GroEndpoint, group, typid int) ([]*game.BattleInfo, error) {
 	st := strconv.FormatInt(time.Now().Unix(), 10)
 	ps := url.Values{}
 	ps.Set("groupid", strconv.Itoa(group))
 	ps.Set("servertime", st)
 	ps.Set("typeid", strconv.Itoa(typid))
-	ps.Set("token", token(group, "WH3001", st))
+	ps.Set("token", token(group, gro.PrivateKey, st))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"?action=GetGroupNewBattleInfo", strings.NewReader(ps.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gro.BaseURL+"?action=GetGroupNewBattleInfo", strings.NewReader(ps.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -47,19 +46,27 @@ func GetGroupNewBattleInfoCtx(ctx context.Context, httpc HTTPDoer, base string,
 	return parseBattleInfo(string(body)), nil
 }
 
-func GetGroupBattleInfoCtx(ctx context.Context, httpc HTTPDoer, base string, group, typid int) ([]*game.BattleInfo, error) {
+// HTTPBattlePageSize getgroupbattleinfo 单页条数上限
+const HTTPBattlePageSize = 50
+
+func GetGroupBattleInfoCtx(ctx context.Context, httpc HTTPDoer, gro GroEndpoint, group, typid int) ([]*game.BattleInfo, error) {
+	return GetGroupBattleInfoPageCtx(ctx, httpc, gro, group, typid, 1, HTTPBattlePageSize)
+}
+
+// GetGroupBattleInfoPageCtx 分页拉取（pageIndex 从 1 开始）；返回条数小于 pageSize 即为最后一页
+func GetGroupBattleInfoPageCtx(ctx context.Context, httpc HTTPDoer, gro GroEndpoint, group, typid, pageIndex, pageSize int) ([]*game.BattleInfo, error) {
 	// typeid=0 今日 / 1 昨日 / 2 本周
 	st := strconv.FormatInt(time.Now().Unix(), 10)
 	ps := url.Values{}
 	ps.Set("groupid", strconv.Itoa(group))
 	ps.Set("typeid", strconv.Itoa(typid))
 	ps.Set("servertime", st)
-	ps.Set("pageIndex", "1")
-	ps.Set("PageSize", "50")
+	ps.Set("pageIndex", strconv.Itoa(pageIndex))
+	ps.Set("PageSize", strconv.Itoa(pageSize))
 	ps.Set("StationID", "2000")
-	ps.Set("token", token(group, "WH3001", st))
+	ps.Set("token", token(group, gro.PrivateKey, st))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"?action=getgroupbattleinfo", strings.NewReader(ps.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gro.BaseURL+"?action=getgroupbattleinfo", strings.NewReader(ps.Encode()))
 	if err != nil {
 		return nil, err
 	}
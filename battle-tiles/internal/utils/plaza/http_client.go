@@ -0,0 +1,126 @@
+package plaza
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GroEndpoint GroService.ashx 的地址与 token 签名私钥
+type GroEndpoint struct {
+	BaseURL    string // 例: http://phone2.foxuc.com/Ashx/GroService.ashx
+	PrivateKey string
+}
+
+// ErrCircuitOpen 上游连续失败已熔断，冷却结束前直接拒绝
+var ErrCircuitOpen = errors.New("gro service circuit open")
+
+// DoerOptions ResilientDoer 参数；零值使用默认值
+type DoerOptions struct {
+	Timeout         time.Duration // 单次请求超时，默认 10s
+	Retries         int           // 网络错误/5xx 的重试次数，默认 0
+	BreakerFailures int           // 连续失败多少次后熔断，<=0 不熔断
+	BreakerCooldown time.Duration // 熔断后多久放行一次试探请求，默认 30s
+}
+
+// ResilientDoer 带超时、重试与熔断的 HTTPDoer，供各 GroService 调用方共用
+type ResilientDoer struct {
+	client *http.Client
+	opts   DoerOptions
+
+	mu        sync.Mutex
+	failures  int       // 连续失败次数
+	openUntil time.Time // 熔断截止时间
+}
+
+func NewResilientDoer(opts DoerOptions) *ResilientDoer {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = 30 * time.Second
+	}
+	return &ResilientDoer{client: &http.Client{Timeout: opts.Timeout}, opts: opts}
+}
+
+func (d *ResilientDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.allow(); err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= d.opts.Retries; attempt++ {
+		if attempt > 0 {
+			// 线性退避；请求已取消时不再重试
+			select {
+			case <-req.Context().Done():
+				d.record(false)
+				return nil, req.Context().Err()
+			case <-time.After(time.Duration(attempt) * 200 * time.Millisecond):
+			}
+		}
+		r, err := d.attempt(req, attempt)
+		if err == nil {
+			d.record(true)
+			return r, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	d.record(false)
+	return nil, lastErr
+}
+
+func (d *ResilientDoer) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	r := req
+	if attempt > 0 && req.Body != nil {
+		if req.GetBody == nil {
+			return nil, errors.New("request body cannot be replayed")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r = req.Clone(req.Context())
+		r.Body = body
+	}
+	resp, err := d.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		resp.Body.Close()
+		return nil, fmt.Errorf("gro service status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// allow 熔断期间拒绝；冷却结束后放行（半开），成功即恢复，失败则重新熔断
+func (d *ResilientDoer) allow() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.opts.BreakerFailures > 0 && time.Now().Before(d.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+func (d *ResilientDoer) record(ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ok {
+		d.failures = 0
+		d.openUntil = time.Time{}
+		return
+	}
+	d.failures++
+	if d.opts.BreakerFailures > 0 && d.failures >= d.opts.BreakerFailures {
+		d.openUntil = time.Now().Add(d.opts.BreakerCooldown)
+	}
+}
@@ -0,0 +1,62 @@
+package plaza
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResilientDoerRetriesAndBreaks(t *testing.T) {
+	var calls atomic.Int32
+	var fail atomic.Bool
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	d := NewResilientDoer(DoerOptions{Retries: 1, BreakerFailures: 2, BreakerCooldown: 50 * time.Millisecond})
+	do := func() error {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("a=1"))
+		resp, err := d.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// 每次调用含 1 次重试；连续 2 次失败后熔断
+	for i := 0; i < 2; i++ {
+		if err := do(); err == nil {
+			t.Fatal("want error from 502")
+		}
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("calls = %d, want 4", calls.Load())
+	}
+	if err := do(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want circuit open", err)
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("open circuit still reached upstream")
+	}
+
+	// 冷却后放行试探请求，成功即恢复
+	time.Sleep(60 * time.Millisecond)
+	fail.Store(false)
+	if err := do(); err != nil {
+		t.Fatalf("probe after cooldown: %v", err)
+	}
+	if err := do(); err != nil {
+		t.Fatalf("after recovery: %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package plaza
 
 import (
+	"fmt"
 	"sync"
 )
 
+// headSize 包头（版本、校验、长度、主/子命令号）的字节数
+const headSize = 8
+
 type RecvBuf struct {
 	buffer  [1024 * 1024]byte
 	size    int
@@ -17,35 +21,39 @@ func NewRecvBuf(handler chan []byte) *RecvBuf {
 	return rf
 }
 
-func (that *RecvBuf) Add(data []byte) {
+// Add 追加收到的数据并把其中完整的包交给 handler。
+// 包头长度非法或缓冲溢出时丢弃已缓存的数据并返回 ErrPacketSize：
+// 之后的字节流已无法对齐（解密状态也随之错位），调用方应重连
+func (that *RecvBuf) Add(data []byte) (err error) {
 	that.mut.Lock()
 	defer that.mut.Unlock()
 	defer func() {
-		_ = recover()
+		_ = recover() // handler 在关闭会话时可能已关闭
 	}()
 
-	for _, d := range data {
-		that.buffer[that.size] = d
-		that.size++
+	if that.size+len(data) > len(that.buffer) {
+		that.size = 0
+		return fmt.Errorf("%w: buffer overflow by %d bytes", ErrPacketSize, len(data))
 	}
+	that.size += copy(that.buffer[that.size:], data)
 
-	for {
-		if sz := that.getPacketSize(); sz <= that.size {
-			packet := make([]byte, sz)
-			for i := 0; i < sz; i++ {
-				packet[i] = that.buffer[i]
-			}
-			for i := sz; i < that.size; i++ {
-				that.buffer[i-sz] = that.buffer[i]
-			}
-			that.size -= sz
-
-			that.handler <- packet
-		} else {
+	for that.size >= 4 {
+		sz := that.getPacketSize()
+		if sz < headSize {
+			that.size = 0
+			return fmt.Errorf("%w: head=%d", ErrPacketSize, sz)
+		}
+		if sz > that.size {
 			break
 		}
-	}
+		packet := make([]byte, sz)
+		copy(packet, that.buffer[:sz])
+		copy(that.buffer[:], that.buffer[sz:that.size])
+		that.size -= sz
 
+		that.handler <- packet
+	}
+	return nil
 }
 
 func (that *RecvBuf) getPacketSize() int {
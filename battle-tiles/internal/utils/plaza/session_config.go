@@ -4,10 +4,12 @@ package plaza
 import (
 	"battle-tiles/internal/consts"
 	"battle-tiles/internal/dal/vo/game"
+	"errors"
 	"fmt"
 	"net"
 	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -47,6 +49,7 @@ type IPlazaHandler interface {
 	OnMemberListUpdated(members []*GroupMember)
 	OnMemberInserted(member *MemberInserted)
 	OnMemberDeleted(member *MemberDeleted)
+	OnMemberUpdated(member *MemberUpdated) // 成员类型/权限变化（含游戏端设置的禁止）
 	OnMemberRightUpdated(key string, memberID int, success bool)
 	OnLoginDone(success bool)
 	OnRoomListUpdated(tables []*TableInfo)
@@ -56,6 +59,10 @@ type IPlazaHandler interface {
 	OnDismissTable(table int)
 	OnAppliesForHouse(applyInfos []*ApplyInfo)
 	OnReconnectFailed(houseGID int, retryCount int) // 新增：重连失败回调
+	OnBattleConfigsUpdated(configs []*BattleConfig) // 玩法列表变化（全量/增删改后均回调全量）
+	OnDiamondUpdated(diamond int64, pushed bool)    // 钻石余额（pushed: 游戏端财富更新推送，否则为查询回包）
+	OnGroupUpdated(prop *GroupProperty)             // 店铺属性（进入时推送/变化推送）
+	OnGroupDeleted(groupID int)                     // 店铺被游戏端移除
 }
 
 /* =========================
@@ -65,26 +72,50 @@ type IPlazaHandler interface {
 type ForbidMemberTag struct {
 	Key      string
 	MemberID int
+	HouseGID int
 }
 
 type Session struct {
-	cfg SessionConfig
+	// 连接与登录态，同一中控账号进入的多个店铺共用
+	*plazaConn
+
+	cfg      SessionConfig
+	houseGID int
+	handler  IPlazaHandler
+
+	tables       *cache.Cache
+	members      *cache.Cache
+	applications *cache.Cache
+	// battleConfigs: 店铺玩法，key=ConfigID
+	battleConfigs *cache.Cache
+	// groupProperty: 最近一次店铺属性
+	groupProperty atomic.Pointer[GroupProperty]
+}
+
+// plazaConn 一条 82/87 连接：登录一次，可通过 CmdGroupService 进入多个店铺。
+// 读写循环与重连都在建立连接的会话（owner）上运行，推送按群组标识分发给各店铺会话
+type plazaConn struct {
+	owner *Session
 
 	autoReconnect         bool
 	userName              string
 	userID                int
 	userPwd               string
-	houseGID              int
-	handler               IPlazaHandler
 	lastForbidCmdKey      string
 	lastGroupMemKey       string
 	lastCmdType           atomic.Int64
+	lastCmdHouse          atomic.Int64 // 最近一条已发送命令所属的店铺
 	dontReportApplicatons bool
 
 	shutdown   atomic.Bool
 	restarting atomic.Bool
 	restarted  atomic.Bool
 
+	// 协议错误计数：包头非法、解密/解析失败、处理回包时 panic
+	protoErrors atomic.Int64
+	// 带群组标识但不属于已进入店铺的推送（已丢弃）
+	unroutedPushes atomic.Int64
+
 	// 被踢下线计数器
 	kickedOfflineCount int
 	lastKickedTime     time.Time
@@ -107,12 +138,25 @@ type Session struct {
 	_82quitChan   chan bool
 	_82connReady  atomic.Bool
 
-	tables       *cache.Cache
-	members      *cache.Cache
-	applications *cache.Cache
+	// diamond: 最近一次得到的钻石余额（账号级），-1 表示未知
+	diamond atomic.Int64
+	// 战绩分页查询：同一连接串行，recordWait 为等待中的回包通道
+	recordMu   sync.Mutex
+	recordWait atomic.Pointer[chan *BattleRecordPage]
+	// 账号所在的群组（SUB_GA_GROUP_ITEM 推送），groupID -> *GroupProperty
+	groups sync.Map
+	// 群组搜索/增删改：同一连接串行，groupWait 为等待中的结果通道
+	groupMu   sync.Mutex
+	groupWait atomic.Pointer[chan groupOpResult]
 
 	// houses: cache latest discovered group/house ids
 	houses *cache.Cache
+
+	// 已进入的店铺：houseGID -> 店铺会话（同一店铺只保留最后进入的会话）
+	enteredMu sync.RWMutex
+	entered   map[int]*Session
+	// 最近一次进入（CmdGroupService）的店铺；不带群组标识的推送归属于它
+	activeHouse atomic.Int64
 }
 
 /* =========================
@@ -122,22 +166,20 @@ type Session struct {
 // 新的构造器：完全由 cfg 决定拨号与行为
 func NewSessionWithConfig(cfg SessionConfig) (*Session, error) {
 	s := new(Session)
-	s.cfg = cfg
+	s.plazaConn = &plazaConn{owner: s, entered: make(map[int]*Session)}
 
 	s.autoReconnect = cfg.AutoReconnect
 	s.userName = cfg.Identifier
 	s.userID = cfg.UserID
 	s.userPwd = cfg.UserPwdMD5
-	s.houseGID = cfg.HouseGID
-	s.handler = cfg.Handler
 
 	s._82quitChan = make(chan bool)
 	s._87quitChan = make(chan bool)
 
-	s.tables = cache.New(10*time.Minute, 10*time.Minute)
-	s.members = cache.New(10*time.Minute, 10*time.Minute)
-	s.applications = cache.New(10*time.Minute, 10*time.Minute)
+	s.diamond.Store(-1)
+	s.activeHouse.Store(int64(cfg.HouseGID))
 	s.houses = cache.New(10*time.Minute, 10*time.Minute)
+	s.initHouse(cfg)
 	if err := s.doLogonServer82(); err != nil {
 		return nil, err
 	}
@@ -201,12 +243,13 @@ func (that *Session) close() {
 
 func (that *Session) prepareForbidCmd(key string, member int, forbid bool) {
 	cmd := that._87cmdQueue.Top()
-	if cmd == nil || cmd.Type != CmdTypeForbid || cmd.Key != key {
+	if cmd == nil || cmd.Type != CmdTypeForbid || cmd.Key != key || cmd.House != that.houseGID {
 		that._87cmdQueue.AddHead(&GameCommand{
 			Pack:   CmdForbidMember(uint32(that.userID), that.userPwd, uint32(that.houseGID), uint32(member), forbid),
 			Type:   CmdTypeForbid,
 			Key:    key,
 			Member: member,
+			House:  that.houseGID,
 		})
 	}
 }
@@ -252,6 +295,7 @@ func (that *Session) doSendCommand() {
 			that._87forbidTagStack.Push(&ForbidMemberTag{
 				Key:      cmd.Key,
 				MemberID: cmd.Member,
+				HouseGID: cmd.House,
 			})
 		} else if cmd.Type == CmdTypeGetGroupMember {
 			if cmd.Key == that.lastGroupMemKey {
@@ -277,11 +321,14 @@ func (that *Session) doSendCommand() {
 			}
 		} else {
 			that.lastCmdType.Store(int64(cmd.Type))
+			that.lastCmdHouse.Store(int64(cmd.House))
 			that._87waitingForCmdResponse.Store(true)
 			if cmd.Type == CmdTypeForbid {
 				that.lastForbidCmdKey = cmd.Key
 			} else if cmd.Type == CmdTypeGetGroupMember {
 				that.lastGroupMemKey = cmd.Key
+				// 进入店铺：之后不带群组标识的推送归属该店铺
+				that.activeHouse.Store(int64(cmd.House))
 			}
 		}
 	}
@@ -389,7 +436,14 @@ func (that *Session) _87serverWaitForData() {
 			}
 		}
 		if n > 0 {
-			that._87buffer.Add(buf[:n])
+			if err := that._87buffer.Add(buf[:n]); err != nil {
+				that.protoErrors.Add(1)
+				logger.Errorf("87服务器数据流错位:%v", err)
+				if !that.shutdown.Load() {
+					that.Restart()
+				}
+				return
+			}
 		}
 	}
 }
@@ -409,7 +463,14 @@ func (that *Session) _82serverWaitForData() {
 			}
 		}
 		if n > 0 {
-			that._82buffer.Add(buf[:n])
+			if err := that._82buffer.Add(buf[:n]); err != nil {
+				that.protoErrors.Add(1)
+				logger.Errorf("82服务器数据流错位:%v", err)
+				if !that._87connReady.Load() && !that.shutdown.Load() {
+					that.Restart()
+				}
+				return
+			}
 		}
 	}
 }
@@ -443,179 +504,29 @@ func (that *Session) _82ServerHandleData() {
 }
 
 /* =========================
-   Protocol 分发（与原来一致）
+   Protocol 分发（路由见 session_dispatch.go）
    ========================= */
 
 func (that *Session) _87handlePacket(data []byte) {
+	defer that.recoverPacket("87", data)
 	packer, err := that._87encoder.Decrypt(data)
 	if err != nil {
+		that.protoErrors.Add(1)
 		logger.Error(err)
 		return
 	}
-
-	switch packer.Head.Cmd.MainCmdID {
-	case consts.MDM_GA_BATTLE_SERVICE: // 1
-		switch packer.Head.Cmd.SubCmdID {
-		case consts.SUB_GA_TABLE_LIST:
-			tables := ParseTableList(packer.Data())
-			that.setTables(tables.Tables)
-			that.handler.OnRoomListUpdated(tables.Tables)
-			logger.Infof("tables push count=%d", len(tables.Tables))
-		case consts.SUB_GA_DISMISS_RESULT:
-			that._87waitingForCmdResponse.Store(false)
-			result := ParseDismissTableResult(packer.Data())
-			logger.Infof("解散桌子结果:%v\n", result)
-		case consts.SUB_GA_USER_SITDOWN:
-			user := ParseUserSitDown(packer.Data())
-			that.handler.OnUserSitDown(user)
-			// 增量保障：根据坐下事件确保桌子存在于快照
-			that.ensureTableByMappedNum(int(user.MappedNum))
-		case consts.SUB_GA_USER_STANDUP:
-			user := ParseUserStandUp(packer.Data())
-			that.handler.OnUserStandUp(user)
-			// 站起事件不移除桌，避免频繁抖动；如需可在无玩家时清理
-		case consts.SUB_GA_TABLE_DISMISS:
-			table := ParseTableDismissed(packer.Data())
-			that.handler.OnDismissTable(table.MappedNum)
-			that.removeTableByMappedNum(table.MappedNum)
-		case consts.SUB_GA_TABLE_RENEW:
-			item := ParseTableRenew(packer.Data())
-			that.handler.OnTableRenew(item)
-			that.renewTableMappedNum(item.MappedNum, item.NewMappedNum)
-		}
-
-	case consts.MDM_GA_LOGIC_SERVICE: // 2
-		switch packer.Head.Cmd.SubCmdID {
-		case consts.SUB_GA_APPLY_MESSAGE:
-			if that.dontReportApplicatons {
-				that.dontReportApplicatons = false
-			} else {
-				applyInfos := ParseApplyList(packer.Data())
-				if len(applyInfos) != 0 {
-					that.saveApplications(applyInfos)
-					that.handler.OnAppliesForHouse(applyInfos)
-				}
-			}
-		case consts.SUB_GA_OPERATE_SUCCESS:
-			tag := that._87forbidTagStack.Pop()
-			if tag != nil {
-				that._87cmdQueue.Remove(tag.Key)
-				go that.handler.OnMemberRightUpdated(strings.Split(tag.Key, ":")[0], tag.MemberID, true)
-			}
-			that._87waitingForCmdResponse.Store(false)
-		case consts.SUB_GA_OPERATE_FAILURE:
-			msg := ParseSystemMessage(packer.Data())
-			logger.Infof("[%d]接收到操作失败:%s", that.houseGID, msg.Text)
-			tag := that._87forbidTagStack.Pop()
-			if tag != nil {
-				logger.Infof("解禁用户失败:%v", tag)
-				that._87cmdQueue.Remove(tag.Key)
-				go that.handler.OnMemberRightUpdated(strings.Split(tag.Key, ":")[0], tag.MemberID, false)
-			}
-			that._87waitingForCmdResponse.Store(false)
-		case consts.SUB_GA_SYSTEM_MESSAGE:
-			msg := ParseSystemMessage(packer.Data())
-			logger.Infof("[%d]接收到系统消息:%s", that.houseGID, msg.Text)
-			if strings.Contains(msg.Text, "您的账号在其他地方登录，您被迫下线") {
-				// 检查是否频繁被踢下线
-				now := time.Now()
-				if now.Sub(that.lastKickedTime) < 2*time.Minute {
-					that.kickedOfflineCount++
-				} else {
-					// 超过2分钟,重置计数器
-					that.kickedOfflineCount = 1
-				}
-				that.lastKickedTime = now
-
-				// 如果2分钟内被踢下线超过5次,停止自动重连
-				if that.kickedOfflineCount > 5 {
-					logger.Errorf("[%d]账号频繁被踢下线(2分钟内%d次),停止自动重连", that.houseGID, that.kickedOfflineCount)
-					that.autoReconnect = false
-					that.Shutdown()
-					// 通知上层需要停用中控账号
-					if that.handler != nil {
-						that.handler.OnReconnectFailed(that.houseGID, that.kickedOfflineCount)
-					}
-					return
-				}
-
-				if that.autoReconnect {
-					that.dontReportApplicatons = true
-					// 添加延迟,避免疯狂重试导致内存占满
-					// 延迟时间随着重试次数增加: 5秒, 10秒, 15秒, 20秒, 25秒
-					delaySeconds := that.kickedOfflineCount * 5
-					logger.Infof("[%d]账号被踢下线,将在%d秒后重连(第%d次)", that.houseGID, delaySeconds, that.kickedOfflineCount)
-					go func() {
-						time.Sleep(time.Duration(delaySeconds) * time.Second)
-						that.Restart()
-					}()
-				}
-			} else if strings.Contains(msg.Text, "茶馆服务不可用。请稍后再次重试") {
-				if that.autoReconnect {
-					that.dontReportApplicatons = true
-					that.Restart()
-				}
-			}
-		}
-
-	case consts.MDM_GA_GROUP_SERVICE: // 3
-		switch packer.Head.Cmd.SubCmdID {
-		case consts.SUB_GA_GROUP_MEMBER:
-			that._87waitingForCmdResponse.Store(false)
-			mems := ParseGroupMember(packer.Data())
-			that.setMembers(mems)
-			that.handler.OnMemberListUpdated(mems)
-			logger.Infof("members push count=%d", len(mems))
-		case consts.SUB_GA_MEMBER_INSERT:
-			val := ParseMemberInserted(packer.Data())
-			that.handler.OnMemberInserted(val)
-		case consts.SUB_GA_MEMBER_DELETE:
-			if that.lastCmdType.Load() == CmdTypeDeleteMember {
-				logger.Info("踢出成员成功")
-				that.lastCmdType.Store(-1)
-				that._87waitingForCmdResponse.Store(false)
-			} else {
-				val := ParseMemberDeleted(packer.Data())
-				that.handler.OnMemberDeleted(val)
-			}
-		}
-	}
+	that.dispatch("87", routes87, packer)
 }
 
 func (that *Session) _82handlePacket(data []byte) {
+	defer that.recoverPacket("82", data)
 	packer, err := that._82encoder.Decrypt(data)
 	if err != nil {
+		that.protoErrors.Add(1)
 		logger.Error(err.Error())
 		return
 	}
-
-	if packer.Head.Cmd.MainCmdID == 101 && packer.Head.Cmd.SubCmdID == 108 {
-		accs := ParseListAccess(packer.Data())
-		if len(accs) > 0 {
-			that._87portChan <- int(accs[0].Port)
-		}
-	} else if packer.Head.Cmd.MainCmdID == consts.MDM_MB_SERVER_LIST {
-		switch packer.Head.Cmd.SubCmdID {
-		case consts.SUB_MB_LIST_SERVER:
-			// Debug: dump first bytes for locating offsets (extended to 256 bytes)
-			data := packer.Data()
-			head := hexHead(data, 256)
-			logger.Infof("MDM_MB_SERVER_LIST/SUB_MB_LIST_SERVER len=%d head=%s", len(data), head)
-			// Extract candidate house ids
-			if ids := ExtractHouseIDsFromServerList(data); len(ids) > 0 {
-				n := len(ids)
-				if n > 20 {
-					n = 20
-				}
-				logger.Infof("server_list candidates=%d sample=%v", len(ids), ids[:n])
-				for _, id := range ids {
-					that.appendHouse(id)
-				}
-			}
-		case consts.SUB_MB_LIST_FINISH:
-			logger.Info("MDM_MB_SERVER_LIST, SUB_MB_LIST_FINISH got")
-		}
-	}
+	that.dispatch("82", routes82, packer)
 }
 
 /* =========================
@@ -637,24 +548,36 @@ func (that *Session) ForbidMembers(key string, members []int, forbid bool) {
 
 func (that *Session) GetGroupMembers() {
 	cmd := that._87cmdQueue.Last()
-	if cmd == nil || cmd.Type != CmdTypeGetGroupMember {
+	if cmd == nil || cmd.Type != CmdTypeGetGroupMember || cmd.House != that.houseGID {
 		gc := &GameCommand{
 			Pack:   CmdGroupService(uint32(that.userID), uint32(that.houseGID)),
 			Type:   CmdTypeGetGroupMember,
 			Key:    fmt.Sprintf("%d", time.Now().UnixNano()),
 			Member: that.houseGID,
+			House:  that.houseGID,
 		}
 		that._87cmdQueue.Push(gc)
 		logger.Infof("enqueue GetGroupMembers key=%s house=%d", gc.Key, that.houseGID)
 	}
 }
 
-func (that *Session) RespondApplication(applyInfo *ApplyInfo, agree bool) {
+// ErrSessionClosed 会话已关闭，命令不会再发出
+var ErrSessionClosed = errors.New("session closed")
+
+// RespondApplication 回发申请处理结果（只入队，不等服务端回包）；会话已关闭时返回 ErrSessionClosed
+func (that *Session) RespondApplication(applyInfo *ApplyInfo, agree bool) error {
+	if applyInfo == nil {
+		return errors.New("nil application")
+	}
+	if that.shutdown.Load() {
+		return ErrSessionClosed
+	}
 	that._87cmdQueue.Push(&GameCommand{
 		Pack: CmdRespondApplication(that.userID, that.userPwd, applyInfo.MessageId, applyInfo.HouseGid, applyInfo.AplierId, agree),
 		Type: CmdTypeRespondApply,
 		Key:  fmt.Sprintf("respond_app-%d", time.Now().UnixNano()),
 	})
+	return nil
 }
 
 func (that *Session) GetDiamond() {
@@ -665,6 +588,18 @@ func (that *Session) GetDiamond() {
 	})
 }
 
+// setDiamond 钻石是账号级的，通知连接上的每个店铺
+func (that *Session) setDiamond(v int64, pushed bool) {
+	that.diamond.Store(v)
+	that.eachHouse(func(h *Session) { h.handler.OnDiamondUpdated(v, pushed) })
+}
+
+// Diamond 最近一次得到的钻石余额；尚未查询到时 ok=false
+func (that *Session) Diamond() (int64, bool) {
+	v := that.diamond.Load()
+	return v, v >= 0
+}
+
 func (that *Session) DismissTable(kindId int, mappedNum int) {
 	that._87cmdQueue.AddHead(&GameCommand{
 		Pack: CmdDismissRoom(that.userID, that.userPwd, kindId, mappedNum),
@@ -673,6 +608,65 @@ func (that *Session) DismissTable(kindId int, mappedNum int) {
 	})
 }
 
+// AppendBattleConfig 添加玩法；结果以 SUB_GA_CONFIG_APPEND 推送回写快照
+func (that *Session) AppendBattleConfig(cfg *BattleConfig) {
+	that._87cmdQueue.Push(&GameCommand{
+		Pack:  CmdAppendConfig(that.userID, that.userPwd, that.houseGID, cfg),
+		Type:  CmdTypeAppendConfig,
+		Key:   fmt.Sprintf("append_config-%d", time.Now().UnixNano()),
+		House: that.houseGID,
+	})
+}
+
+// ModifyBattleConfig 修改玩法
+func (that *Session) ModifyBattleConfig(cfg *BattleConfig) {
+	that._87cmdQueue.Push(&GameCommand{
+		Pack:  CmdModifyConfig(that.userID, that.userPwd, that.houseGID, cfg),
+		Type:  CmdTypeModifyConfig,
+		Key:   fmt.Sprintf("modify_config-%d-%d", time.Now().UnixNano(), cfg.ConfigID),
+		House: that.houseGID,
+	})
+}
+
+// DeleteBattleConfig 删除玩法
+func (that *Session) DeleteBattleConfig(configID uint32) {
+	that._87cmdQueue.Push(&GameCommand{
+		Pack:  CmdDeleteConfig(that.userID, that.userPwd, that.houseGID, configID),
+		Type:  CmdTypeDeleteConfig,
+		Key:   fmt.Sprintf("delete_config-%d-%d", time.Now().UnixNano(), configID),
+		House: that.houseGID,
+	})
+}
+
+// configHouse 玩法增删改推送归属上一条玩法命令的店铺
+func (that *plazaConn) configHouse() *Session {
+	switch that.lastCmdType.Load() {
+	case CmdTypeAppendConfig, CmdTypeModifyConfig, CmdTypeDeleteConfig:
+		if h := that.house(int(that.lastCmdHouse.Load())); h != nil {
+			return h
+		}
+	}
+	return that.current()
+}
+
+// eachGroupHouse 店铺属性/移除推送：已进入该群组时只通知该店铺，否则通知连接上的每个店铺
+func (that *plazaConn) eachGroupHouse(groupID int, fn func(h *Session)) {
+	if h := that.house(groupID); h != nil {
+		fn(h)
+		return
+	}
+	that.eachHouse(fn)
+}
+
+// releaseConfigCmd 玩法变更推送即视为上一条玩法命令已响应
+func (that *plazaConn) releaseConfigCmd() {
+	switch that.lastCmdType.Load() {
+	case CmdTypeAppendConfig, CmdTypeModifyConfig, CmdTypeDeleteConfig:
+		that.lastCmdType.Store(-1)
+		that._87waitingForCmdResponse.Store(false)
+	}
+}
+
 func (that *Session) KickOffMember(houseGid int, memberId int) {
 	that._87cmdQueue.Push(&GameCommand{
 		Pack: CmdDeleteMember(that.userID, that.userPwd, houseGid, memberId),
@@ -693,9 +687,18 @@ func (that *Session) QueryTable(tabMappedNum int) {
 	})
 }
 
+// Shutdown 离开本店铺；连接上没有其他店铺时关闭连接
 func (that *Session) Shutdown() {
+	if that.leave() > 0 {
+		return
+	}
+	that.shutdownConn()
+}
+
+// shutdownConn 关闭连接（连接上的全部店铺随之下线）
+func (that *plazaConn) shutdownConn() {
 	that.shutdown.Store(true)
-	that.close() // close() 中已经清理了 cache
+	that.owner.close() // close() 中已经清理了 cache
 
 	safeCloseBoolChan(that._87quitChan)
 	safeCloseBoolChan(that._82quitChan)
@@ -709,6 +712,11 @@ func (that *Session) Shutdown() {
    ========================= */
 
 func (that *Session) Restart() {
+	// 重连针对整条连接，由建立连接的会话执行
+	if that != that.owner {
+		that.owner.Restart()
+		return
+	}
 	// 使用 CompareAndSwap 确保只有一个重启过程在运行
 	// 如果已经在重启中,直接返回,避免启动多个 goroutine
 	if that.shutdown.Load() {
@@ -799,6 +807,67 @@ func (that *Session) setMembers(arr []*GroupMember) {
 	that.members.Set("members", cloneMembers(arr), cache.DefaultExpiration)
 }
 
+// updateMember 按推送更新成员快照（替换为新副本，不改动已发出的快照），并补齐 GameID
+func (that *Session) updateMember(upd *MemberUpdated) {
+	arr := that.ListMembers()
+	for i, m := range arr {
+		if m == nil || m.MemberID != upd.MemberID {
+			continue
+		}
+		cp := *m
+		cp.MemberType = upd.MemberType
+		cp.MemberRight = upd.MemberRight
+		arr[i] = &cp
+		upd.GameID = m.GameID
+		that.setMembers(arr)
+		return
+	}
+}
+
+func (that *Session) removeMember(memberID uint32) {
+	arr := that.ListMembers()
+	if arr == nil {
+		return
+	}
+	out := arr[:0]
+	for _, m := range arr {
+		if m != nil && m.MemberID != memberID {
+			out = append(out, m)
+		}
+	}
+	that.setMembers(out)
+}
+
+// GroupProperty 最近一次店铺属性（尚未收到时为 nil）
+func (that *Session) GroupProperty() *GroupProperty {
+	if p := that.groupProperty.Load(); p != nil {
+		cp := *p
+		return &cp
+	}
+	return nil
+}
+
+func (that *Session) setBattleConfigs(arr []*BattleConfig) {
+	that.battleConfigs.Flush()
+	for _, c := range arr {
+		that.battleConfigs.SetDefault(fmt.Sprintf("%d", c.ConfigID), c)
+	}
+}
+
+// ListBattleConfigs 读取玩法快照（按 ConfigID 升序）
+func (that *Session) ListBattleConfigs() []*BattleConfig {
+	items := that.battleConfigs.Items()
+	out := make([]*BattleConfig, 0, len(items))
+	for _, it := range items {
+		if c, ok := it.Object.(*BattleConfig); ok {
+			cp := *c
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ConfigID < out[j].ConfigID })
+	return out
+}
+
 func (that *Session) appendHouse(houseGID int) {
 	if houseGID <= 0 {
 		return
@@ -877,6 +946,14 @@ func (that *Session) createNewSession() bool {
 	logger.Infof("========== 重启茶馆 %d", that.houseGID)
 	start := time.Now()
 
+	// 记下连接上已进入的店铺，新连接建立后逐个重新进入；本会话已离开店铺时新连接只登录不登记
+	var houses []*Session
+	that.eachHouse(func(h *Session) { houses = append(houses, h) })
+	cfg := that.cfg
+	if that.house(that.houseGID) != that {
+		cfg.HouseGID, cfg.Handler = 0, nil
+	}
+
 	// 关闭旧连接(只关闭连接,不设置 shutdown 标志)
 	that.close()
 	time.Sleep(50 * time.Millisecond)
@@ -886,8 +963,8 @@ LOOP:
 	if retry > 30 {
 		logger.Errorf("重复连接house%d失败超过30次,不再尝试", that.houseGID)
 		// 通知上层重连失败,需要自动停用中控账号
-		if that.handler != nil {
-			that.handler.OnReconnectFailed(that.houseGID, retry-1)
+		for _, h := range houses {
+			h.handler.OnReconnectFailed(h.houseGID, retry-1)
 		}
 		return false
 	}
@@ -895,7 +972,7 @@ LOOP:
 	retry++
 
 	// 重新用 cfg 构建
-	s, err := NewSessionWithConfig(that.cfg)
+	s, err := NewSessionWithConfig(cfg)
 	if err != nil {
 		logger.Errorf(">>>重启失败 (第%d次尝试)", retry-1)
 		time.Sleep(5 * time.Second) // 改为5秒间隔
@@ -916,7 +993,16 @@ LOOP:
 		}
 	}
 
-	that.handler.OnSessionRestarted(s)
+	for _, h := range houses {
+		ns := s
+		if h != that {
+			if ns, err = s.EnterHouse(h.houseGID, h.handler); err != nil {
+				logger.Errorf("[%d]重连后进入店铺失败:%v", h.houseGID, err)
+				continue
+			}
+		}
+		h.handler.OnSessionRestarted(ns)
+	}
 	return true
 }
 
@@ -0,0 +1,443 @@
+package plaza
+
+import (
+	"battle-tiles/internal/consts"
+	"battle-tiles/internal/dal/vo/game"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// packetRoute 一种回包的处理：按登记的编解码器解码后交给 handle；
+// 解码失败时调用 malformed（为空则只记日志），用于释放等待该回包的命令
+type packetRoute struct {
+	handle    func(s *Session, m Message)
+	malformed func(s *Session)
+}
+
+// on 以具体报文类型挂处理函数
+func on[M Message](handle func(s *Session, m M)) packetRoute {
+	return packetRoute{handle: func(s *Session, m Message) { handle(s, m.(M)) }}
+}
+
+// orElse 挂解码失败时的处理
+func (r packetRoute) orElse(malformed func(s *Session)) packetRoute {
+	r.malformed = malformed
+	return r
+}
+
+// 82/87 连接的回包路由，按主/子命令号
+var routes82, routes87 map[CmdKey]packetRoute
+
+func init() {
+	releaseCmd := func(s *Session) { s._87waitingForCmdResponse.Store(false) }
+
+	routes82 = map[CmdKey]packetRoute{
+		{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_SUCCESS}:     on((*Session).onLogonSuccess),
+		{consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_FAILURE}:     on((*Session).onLogonFailure),
+		{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_ACCESS}: on((*Session).onListAccess),
+		{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_SERVER}: on((*Session).onListServer),
+		{consts.MDM_MB_SERVER_LIST, consts.SUB_MB_LIST_FINISH}: on((*Session).onListFinish),
+	}
+
+	record := on((*Session).onBattleRecordPage).orElse(func(s *Session) { s.onBattleRecordPage(nil) })
+	groupProperty := on((*Session).onGroupProperty)
+	config := on((*Session).onConfigItem).orElse(func(s *Session) { s.releaseConfigCmd() })
+	routes87 = map[CmdKey]packetRoute{
+		// 约战服务
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_LIST}:     on((*Session).onTableList),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_RESULT}: on((*Session).onDismissResult).orElse(releaseCmd),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_SITDOWN}:   on((*Session).onUserSitDown),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_USER_STANDUP}:   on((*Session).onUserStandUp),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_DISMISS}:  on((*Session).onTableDismiss),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_TABLE_RENEW}:    on((*Session).onTableRenew),
+		{consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_BATTLE_RECORD}:  record,
+		{consts.MDM_GP_REMOTE_SERVICE, consts.SUB_GP_BATTLE_RECORD}:  record, // 部分服务端按查询时的主命令回包
+
+		// 逻辑服务
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_MESSAGE}:   on((*Session).onApplyMessage),
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SEARCH_RESULT}:   on((*Session).onSearchResult),
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_SUCCESS}: on((*Session).onOperateSuccess),
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_OPERATE_FAILURE}: on((*Session).onOperateFailure).orElse(func(s *Session) { s.onOperateFailure(&SystemMessage{}) }),
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_WEALTH_UPDATE}:   on((*Session).onWealthUpdate),
+		{consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_SYSTEM_MESSAGE}:  on((*Session).onSystemMessage),
+
+		// 群组服务
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_MEMBER}:   on((*Session).onGroupMembers).orElse(releaseCmd),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_INSERT}:  on((*Session).onMemberInserted),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_UPDATE}:  on((*Session).onMemberUpdated),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_MEMBER_DELETE}:  on((*Session).onMemberDeleted).orElse(func(s *Session) { s.onMemberDeleted(nil) }),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_ITEM}:     on((*Session).onGroupItems),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_PROPERTY}: groupProperty,
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_UPDATE}:   groupProperty,
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_GROUP_DELETE}:   on((*Session).onGroupDelete),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_BATTLE_CONFIG}:  on((*Session).onBattleConfigs),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_APPEND}:  config,
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_MODIFY}:  config,
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_CONFIG_DELETE}:  on((*Session).onConfigDelete).orElse(func(s *Session) { s.releaseConfigCmd() }),
+		{consts.MDM_GA_GROUP_SERVICE, consts.SUB_GP_USER_WEALTH}:    on((*Session).onUserWealth).orElse(func(s *Session) { s.onUserWealth(nil) }),
+	}
+}
+
+// dispatch 按路由分发回包；未登记的回包计数，每种首次出现时记录包头便于补充协议
+func (that *Session) dispatch(conn string, routes map[CmdKey]packetRoute, packer *game.Packer) {
+	key := CmdKey{packer.Head.Cmd.MainCmdID, packer.Head.Cmd.SubCmdID}
+	route, ok := routes[key]
+	codec, known := LookupCodec(Inbound, key.Main, key.Sub)
+	if !ok || !known {
+		data := packer.Data()
+		if n := countUnknown(key); n == 1 {
+			logger.Warnf("[%d]%s 未登记的回包 %s len=%d head=%s", that.houseGID, conn, key, len(data), hexHead(data, 64))
+		} else {
+			logger.Debugf("[%d]%s 未登记的回包 %s len=%d count=%d", that.houseGID, conn, key, len(data), n)
+		}
+		return
+	}
+	m, err := codec.Decode(packer.Data())
+	if err != nil {
+		that.protoErrors.Add(1)
+		logger.Warnf("[%d]%s 回包解析失败: %v", that.houseGID, conn, err)
+		if route.malformed != nil {
+			route.malformed(that)
+		}
+		return
+	}
+	route.handle(that, m)
+}
+
+// recoverPacket 处理单个回包时的 panic 只记日志并计数，读循环与会话照常运行
+func (that *Session) recoverPacket(conn string, data []byte) {
+	if r := recover(); r != nil {
+		that.protoErrors.Add(1)
+		logger.Errorf("[%d]%s 处理回包 panic: %v len=%d\n%s", that.houseGID, conn, r, len(data), debug.Stack())
+	}
+}
+
+// ProtocolErrors 所在连接累计的协议错误数（同一连接上的店铺会话共用）
+func (that *Session) ProtocolErrors() int64 {
+	return that.protoErrors.Load()
+}
+
+// UnroutedPushes 所在连接因群组标识未进入而丢弃的推送数
+func (that *Session) UnroutedPushes() int64 {
+	return that.unroutedPushes.Load()
+}
+
+/* ---------- 82 ---------- */
+
+func (that *Session) onLogonSuccess(m *userLogonReply) {
+	logger.Debugf("[%d]82 登录成功 user=%d game=%d", that.houseGID, m.UserID, m.GameID)
+}
+
+func (that *Session) onLogonFailure(m *LogonFailure) {
+	logger.Errorf("[%d]82 登录失败(%d):%s", that.houseGID, m.Code, m.Desc)
+}
+
+func (that *Session) onListAccess(m *accessList) {
+	if len(m.Items) > 0 {
+		that._87portChan <- int(m.Items[0].Port)
+	}
+}
+
+func (that *Session) onListServer(m *serverList) {
+	// Debug: dump first bytes for locating offsets (extended to 256 bytes)
+	head := hexHead(m.Data, 256)
+	logger.Infof("MDM_MB_SERVER_LIST/SUB_MB_LIST_SERVER len=%d head=%s", len(m.Data), head)
+	// Extract candidate house ids
+	if ids := ExtractHouseIDsFromServerList(m.Data); len(ids) > 0 {
+		n := len(ids)
+		if n > 20 {
+			n = 20
+		}
+		logger.Infof("server_list candidates=%d sample=%v", len(ids), ids[:n])
+		for _, id := range ids {
+			that.appendHouse(id)
+		}
+	}
+}
+
+func (that *Session) onListFinish(*emptyReply) {
+	logger.Info("MDM_MB_SERVER_LIST, SUB_MB_LIST_FINISH got")
+}
+
+/* ---------- 87：约战服务 ---------- */
+
+func (that *Session) onTableList(tables *TableList) {
+	groups := groupTables(tables.Tables)
+	if len(groups) == 0 {
+		groups[0] = nil
+	}
+	for groupID, list := range groups {
+		if h := that.houseOr(groupID); h != nil {
+			h.setTables(list)
+			h.handler.OnRoomListUpdated(list)
+		}
+	}
+	logger.Infof("tables push count=%d", len(tables.Tables))
+}
+
+func (that *Session) onDismissResult(result *DismissTableResult) {
+	that._87waitingForCmdResponse.Store(false)
+	logger.Infof("解散桌子结果:%v\n", result)
+}
+
+func (that *Session) onUserSitDown(user *UserSitDown) {
+	if h := that.houseOr(int(user.GroupID)); h != nil {
+		h.handler.OnUserSitDown(user)
+		// 增量保障：根据坐下事件确保桌子存在于快照
+		h.ensureTableByMappedNum(int(user.MappedNum))
+	}
+}
+
+func (that *Session) onUserStandUp(user *UserStandUp) {
+	if h := that.houseByTable(int(user.MappedNum)); h != nil {
+		h.handler.OnUserStandUp(user)
+	}
+	// 站起事件不移除桌，避免频繁抖动；如需可在无玩家时清理
+}
+
+func (that *Session) onTableDismiss(table *DismissResult) {
+	if h := that.houseOr(table.GroupID); h != nil {
+		h.handler.OnDismissTable(table.MappedNum)
+		h.removeTableByMappedNum(table.MappedNum)
+	}
+}
+
+func (that *Session) onTableRenew(item *TableRenew) {
+	if h := that.houseByTable(item.MappedNum); h != nil {
+		h.handler.OnTableRenew(item)
+		h.renewTableMappedNum(item.MappedNum, item.NewMappedNum)
+	}
+}
+
+/* ---------- 87：逻辑服务 ---------- */
+
+func (that *Session) onApplyMessage(m *applyList) {
+	if that.dontReportApplicatons {
+		that.dontReportApplicatons = false
+		return
+	}
+	byHouse := make(map[*Session][]*ApplyInfo)
+	for _, a := range m.infos() {
+		if h := that.houseOr(a.HouseGid); h != nil {
+			byHouse[h] = append(byHouse[h], a)
+		}
+	}
+	for h, list := range byHouse {
+		h.saveApplications(list)
+		h.handler.OnAppliesForHouse(list)
+	}
+}
+
+func (that *Session) onSearchResult(m *searchResult) {
+	if that.pendingGroupOp() != CmdTypeSearchGroup {
+		return
+	}
+	if m.Found && m.Group.GroupID != 0 {
+		g := m.Group
+		that.finishGroupOp(groupOpResult{group: &g})
+	} else {
+		that.finishGroupOp(groupOpResult{err: ErrGroupNotFound})
+	}
+}
+
+func (that *Session) onOperateSuccess(*emptyReply) {
+	if that.pendingGroupOp() >= 0 {
+		that.finishGroupOp(groupOpResult{})
+		return
+	}
+	tag := that._87forbidTagStack.Pop()
+	if tag != nil {
+		that._87cmdQueue.Remove(tag.Key)
+		if h := that.houseOr(tag.HouseGID); h != nil {
+			go h.handler.OnMemberRightUpdated(strings.Split(tag.Key, ":")[0], tag.MemberID, true)
+		}
+	}
+	that._87waitingForCmdResponse.Store(false)
+}
+
+func (that *Session) onOperateFailure(msg *SystemMessage) {
+	logger.Infof("[%d]接收到操作失败:%s", that.houseGID, msg.Text)
+	if that.pendingGroupOp() >= 0 {
+		that.finishGroupOp(groupOpResult{err: errors.New(msg.Text)})
+		return
+	}
+	tag := that._87forbidTagStack.Pop()
+	if tag != nil {
+		logger.Infof("解禁用户失败:%v", tag)
+		that._87cmdQueue.Remove(tag.Key)
+		if h := that.houseOr(tag.HouseGID); h != nil {
+			go h.handler.OnMemberRightUpdated(strings.Split(tag.Key, ":")[0], tag.MemberID, false)
+		}
+	}
+	that._87waitingForCmdResponse.Store(false)
+}
+
+func (that *Session) onWealthUpdate(m *wealthUpdate) {
+	if m.HasIngot() {
+		that.setDiamond(m.Ingot, true)
+	}
+}
+
+func (that *Session) onSystemMessage(msg *SystemMessage) {
+	logger.Infof("[%d]接收到系统消息:%s", that.houseGID, msg.Text)
+	if strings.Contains(msg.Text, "您的账号在其他地方登录，您被迫下线") {
+		// 检查是否频繁被踢下线
+		now := time.Now()
+		if now.Sub(that.lastKickedTime) < 2*time.Minute {
+			that.kickedOfflineCount++
+		} else {
+			// 超过2分钟,重置计数器
+			that.kickedOfflineCount = 1
+		}
+		that.lastKickedTime = now
+
+		// 如果2分钟内被踢下线超过5次,停止自动重连
+		if that.kickedOfflineCount > 5 {
+			logger.Errorf("[%d]账号频繁被踢下线(2分钟内%d次),停止自动重连", that.houseGID, that.kickedOfflineCount)
+			that.autoReconnect = false
+			that.shutdownConn()
+			// 通知上层需要停用中控账号（连接上的每个店铺）
+			count := that.kickedOfflineCount
+			that.eachHouse(func(h *Session) {
+				h.handler.OnReconnectFailed(h.houseGID, count)
+			})
+			return
+		}
+
+		if that.autoReconnect {
+			that.dontReportApplicatons = true
+			// 添加延迟,避免疯狂重试导致内存占满
+			// 延迟时间随着重试次数增加: 5秒, 10秒, 15秒, 20秒, 25秒
+			delaySeconds := that.kickedOfflineCount * 5
+			logger.Infof("[%d]账号被踢下线,将在%d秒后重连(第%d次)", that.houseGID, delaySeconds, that.kickedOfflineCount)
+			go func() {
+				time.Sleep(time.Duration(delaySeconds) * time.Second)
+				that.Restart()
+			}()
+		}
+	} else if strings.Contains(msg.Text, "茶馆服务不可用。请稍后再次重试") {
+		if that.autoReconnect {
+			that.dontReportApplicatons = true
+			that.Restart()
+		}
+	}
+}
+
+/* ---------- 87：群组服务 ---------- */
+
+func (that *Session) onGroupMembers(m *groupMemberList) {
+	that._87waitingForCmdResponse.Store(false)
+	if h := that.current(); h != nil {
+		h.setMembers(m.Members)
+		h.handler.OnMemberListUpdated(m.Members)
+	}
+	logger.Infof("members push count=%d", len(m.Members))
+}
+
+func (that *Session) onMemberInserted(val *MemberInserted) {
+	if h := that.houseOr(int(val.GroupID)); h != nil {
+		h.handler.OnMemberInserted(val)
+		// 插入推送不带成员标识，重新拉取成员列表
+		h.GetGroupMembers()
+	}
+}
+
+func (that *Session) onMemberUpdated(val *MemberUpdated) {
+	if h := that.houseOr(int(val.GroupID)); h != nil {
+		h.updateMember(val)
+		h.handler.OnMemberUpdated(val)
+	}
+}
+
+// onMemberDeleted 等待踢人回包时只释放命令队列；val 为 nil 表示回包无法解析
+func (that *Session) onMemberDeleted(val *MemberDeleted) {
+	if that.lastCmdType.Load() == CmdTypeDeleteMember {
+		logger.Info("踢出成员成功")
+		that.lastCmdType.Store(-1)
+		that._87waitingForCmdResponse.Store(false)
+		return
+	}
+	if val == nil {
+		return
+	}
+	if h := that.houseOr(int(val.GroupID)); h != nil {
+		h.removeMember(val.MemberID)
+		h.handler.OnMemberDeleted(val)
+	}
+}
+
+func (that *Session) onGroupItems(m *groupItems) {
+	items := m.valid()
+	that.upsertGroups(items...)
+	if that.pendingGroupOp() == CmdTypeCreateGroup && len(items) > 0 {
+		that.finishGroupOp(groupOpResult{group: items[len(items)-1]})
+	}
+}
+
+func (that *Session) onGroupProperty(prop *GroupProperty) {
+	if h := that.house(int(prop.GroupID)); h != nil {
+		h.groupProperty.Store(prop)
+	}
+	if _, ok := that.groups.Load(prop.GroupID); ok && prop.Name != "" {
+		that.upsertGroups(prop)
+	}
+	that.eachGroupHouse(int(prop.GroupID), func(h *Session) { h.handler.OnGroupUpdated(prop) })
+	if that.pendingGroupOp() == CmdTypeUpdateGroup {
+		that.finishGroupOp(groupOpResult{})
+	}
+}
+
+func (that *Session) onGroupDelete(m *groupDelete) {
+	id := m.GroupID
+	if id > 0 {
+		that.groups.Delete(id)
+	}
+	if that.pendingGroupOp() == CmdTypeDeleteGroup {
+		that.finishGroupOp(groupOpResult{})
+	}
+	if id > 0 {
+		if h := that.house(int(id)); h != nil {
+			h.setMembers(nil)
+			h.setBattleConfigs(nil)
+		}
+		that.eachGroupHouse(int(id), func(h *Session) { h.handler.OnGroupDeleted(int(id)) })
+	}
+}
+
+func (that *Session) onBattleConfigs(m *battleConfigList) {
+	if h := that.current(); h != nil {
+		h.setBattleConfigs(m.Items)
+		h.handler.OnBattleConfigsUpdated(h.ListBattleConfigs())
+	}
+}
+
+func (that *Session) onConfigItem(cfg *BattleConfig) {
+	if h := that.configHouse(); h != nil {
+		h.battleConfigs.SetDefault(fmt.Sprintf("%d", cfg.ConfigID), cfg)
+		h.handler.OnBattleConfigsUpdated(h.ListBattleConfigs())
+	}
+	that.releaseConfigCmd()
+}
+
+func (that *Session) onConfigDelete(m *configDelete) {
+	if h := that.configHouse(); h != nil && m.ConfigID > 0 {
+		h.battleConfigs.Delete(fmt.Sprintf("%d", m.ConfigID))
+		h.handler.OnBattleConfigsUpdated(h.ListBattleConfigs())
+	}
+	that.releaseConfigCmd()
+}
+
+// onUserWealth 与 SUB_GA_ENTER_FAILURE 同号，只在等待查询财富回包时处理；m 为 nil 表示回包无法解析
+func (that *Session) onUserWealth(m *userWealthReply) {
+	if that.lastCmdType.Load() != CmdTypeQueryDiamond {
+		return
+	}
+	that.lastCmdType.Store(-1)
+	that._87waitingForCmdResponse.Store(false)
+	if m != nil {
+		that.setDiamond(m.Ingot, false)
+	}
+}
@@ -0,0 +1,118 @@
+package plaza
+
+import (
+	"battle-tiles/internal/dal/vo/game"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 群组操作的等待上限（未设置截止时间时）
+const groupOpTimeout = 10 * time.Second
+
+// ErrGroupNotFound 搜索结果为空
+var ErrGroupNotFound = errors.New("group not found")
+
+type groupOpResult struct {
+	group *GroupProperty // 创建/搜索的结果；修改、删除或只收到“操作成功”时为 nil
+	err   error
+}
+
+// SearchGroup 按群组标识搜索
+func (that *Session) SearchGroup(ctx context.Context, groupID int) (*GroupProperty, error) {
+	return that.groupOp(ctx, CmdTypeSearchGroup, CmdSearchGroup(groupID))
+}
+
+// CreateGroup 以中控账号创建群组；服务端只回“操作成功”时返回 nil 群组，以随后的 SUB_GA_GROUP_ITEM 推送为准
+func (that *Session) CreateGroup(ctx context.Context, name string) (*GroupProperty, error) {
+	return that.groupOp(ctx, CmdTypeCreateGroup, CmdCreateGroup(that.userID, that.userPwd, name))
+}
+
+// UpdateGroup 修改群组名称
+func (that *Session) UpdateGroup(ctx context.Context, groupID int, name string) error {
+	_, err := that.groupOp(ctx, CmdTypeUpdateGroup, CmdUpdateGroup(that.userID, that.userPwd, groupID, name))
+	return err
+}
+
+// DeleteGroup 删除群组
+func (that *Session) DeleteGroup(ctx context.Context, groupID int) error {
+	_, err := that.groupOp(ctx, CmdTypeDeleteGroup, CmdDeleteGroup(that.userID, that.userPwd, groupID))
+	return err
+}
+
+// ListGroups 账号所在的群组（按标识排序）
+func (that *Session) ListGroups() []*GroupProperty {
+	var out []*GroupProperty
+	that.groups.Range(func(_, v any) bool {
+		out = append(out, v.(*GroupProperty))
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].GroupID < out[j].GroupID })
+	return out
+}
+
+func (that *Session) groupOp(ctx context.Context, typ int, pack *game.Packer) (*GroupProperty, error) {
+	if !that._87connReady.Load() || that.shutdown.Load() {
+		return nil, errors.New("session not ready")
+	}
+	that.groupMu.Lock()
+	defer that.groupMu.Unlock()
+
+	ch := make(chan groupOpResult, 1)
+	that.groupWait.Store(&ch)
+	that._87cmdQueue.Push(&GameCommand{
+		Pack: pack,
+		Type: typ,
+		Key:  fmt.Sprintf("group_op-%d-%d", typ, time.Now().UnixNano()),
+	})
+
+	timer := time.NewTimer(groupOpTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.group, r.err
+	case <-ctx.Done():
+		that.abandonGroupOp(&ch)
+		return nil, ctx.Err()
+	case <-timer.C:
+		that.abandonGroupOp(&ch)
+		return nil, errors.New("group operation timeout")
+	}
+}
+
+// pendingGroupOp 当前等待回包的群组操作类型，没有则为 -1
+func (that *Session) pendingGroupOp() int64 {
+	switch t := that.lastCmdType.Load(); t {
+	case CmdTypeSearchGroup, CmdTypeCreateGroup, CmdTypeUpdateGroup, CmdTypeDeleteGroup:
+		return t
+	}
+	return -1
+}
+
+// abandonGroupOp 放弃等待；命令已发出但回包未到时解除发送阻塞，迟到的回包丢弃
+func (that *Session) abandonGroupOp(ch *chan groupOpResult) {
+	that.groupWait.CompareAndSwap(ch, nil)
+	if t := that.pendingGroupOp(); t >= 0 && that.lastCmdType.CompareAndSwap(t, -1) {
+		that._87waitingForCmdResponse.Store(false)
+	}
+}
+
+// finishGroupOp 回包到达：释放命令队列并唤醒等待方
+func (that *Session) finishGroupOp(r groupOpResult) {
+	if t := that.pendingGroupOp(); t >= 0 && that.lastCmdType.CompareAndSwap(t, -1) {
+		that._87waitingForCmdResponse.Store(false)
+	}
+	if ch := that.groupWait.Swap(nil); ch != nil {
+		*ch <- r
+	}
+}
+
+func (that *Session) upsertGroups(list ...*GroupProperty) {
+	for _, g := range list {
+		if g != nil && g.GroupID != 0 {
+			that.groups.Store(g.GroupID, g)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package plaza
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// initHouse 初始化店铺级状态；有处理器时登记到连接上接收推送
+func (that *Session) initHouse(cfg SessionConfig) {
+	that.cfg = cfg
+	that.houseGID = cfg.HouseGID
+	that.handler = cfg.Handler
+
+	that.tables = cache.New(10*time.Minute, 10*time.Minute)
+	that.members = cache.New(10*time.Minute, 10*time.Minute)
+	that.applications = cache.New(10*time.Minute, 10*time.Minute)
+	that.battleConfigs = cache.New(cache.NoExpiration, 0)
+
+	if cfg.Handler != nil {
+		that.enteredMu.Lock()
+		that.entered[cfg.HouseGID] = that
+		that.enteredMu.Unlock()
+	}
+}
+
+// EnterHouse 在本连接上进入另一个店铺，返回该店铺的会话（共用登录态与命令队列，不再重新登录）。
+// 进入命令由返回会话的 GetGroupMembers 下发
+func (that *Session) EnterHouse(houseGID int, handler IPlazaHandler) (*Session, error) {
+	if that.shutdown.Load() {
+		return nil, errors.New("session closed")
+	}
+	if houseGID <= 0 || handler == nil {
+		return nil, errors.New("invalid house or handler")
+	}
+	cfg := that.owner.cfg
+	cfg.HouseGID = houseGID
+	cfg.Handler = handler
+
+	s := &Session{plazaConn: that.plazaConn}
+	s.initHouse(cfg)
+	return s, nil
+}
+
+// EnteredHouses 本连接已进入的店铺（升序）
+func (that *Session) EnteredHouses() []int {
+	that.enteredMu.RLock()
+	defer that.enteredMu.RUnlock()
+	out := make([]int, 0, len(that.entered))
+	for id := range that.entered {
+		out = append(out, id)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// SameConn 两个会话是否共用同一条连接
+func (that *Session) SameConn(other *Session) bool {
+	return other != nil && that.plazaConn == other.plazaConn
+}
+
+// Closed 连接已关闭（主动关闭或被踢后停止重连），不能再进入店铺
+func (that *Session) Closed() bool {
+	return that.shutdown.Load()
+}
+
+// leave 离开本店铺，返回连接上剩余的店铺数
+func (that *Session) leave() int {
+	that.enteredMu.Lock()
+	defer that.enteredMu.Unlock()
+	if that.entered[that.houseGID] == that {
+		delete(that.entered, that.houseGID)
+	}
+	return len(that.entered)
+}
+
+// house 按群组标识找已进入的店铺会话
+func (c *plazaConn) house(houseGID int) *Session {
+	c.enteredMu.RLock()
+	defer c.enteredMu.RUnlock()
+	return c.entered[houseGID]
+}
+
+// current 最近进入的店铺；它已离开时取任一已进入的店铺，都没有时为 nil
+func (c *plazaConn) current() *Session {
+	c.enteredMu.RLock()
+	defer c.enteredMu.RUnlock()
+	if s, ok := c.entered[int(c.activeHouse.Load())]; ok {
+		return s
+	}
+	for _, s := range c.entered {
+		return s
+	}
+	return nil
+}
+
+// houseOr 按推送中的群组标识分发；标识为 0 时归属最近进入的店铺，
+// 不是本连接已进入的群组时丢弃并计数（不能记到其他店铺上）
+func (c *plazaConn) houseOr(groupID int) *Session {
+	if groupID == 0 {
+		return c.current()
+	}
+	if s := c.house(groupID); s != nil {
+		return s
+	}
+	c.unroutedPushes.Add(1)
+	return nil
+}
+
+// houseByTable 站起/续桌推送不带群组标识，按桌台快照找所属店铺
+func (c *plazaConn) houseByTable(mappedNum int) *Session {
+	var found *Session
+	c.eachHouse(func(s *Session) {
+		if found == nil && s.hasTable(mappedNum) {
+			found = s
+		}
+	})
+	if found != nil {
+		return found
+	}
+	return c.current()
+}
+
+// eachHouse 对已进入的每个店铺回调（在锁外执行）
+func (c *plazaConn) eachHouse(fn func(s *Session)) {
+	c.enteredMu.RLock()
+	list := make([]*Session, 0, len(c.entered))
+	for _, s := range c.entered {
+		list = append(list, s)
+	}
+	c.enteredMu.RUnlock()
+	for _, s := range list {
+		fn(s)
+	}
+}
+
+func (that *Session) hasTable(mappedNum int) bool {
+	if that.tables == nil || mappedNum <= 0 {
+		return false
+	}
+	for _, t := range that.ListTables() {
+		if t.MappedNum == mappedNum {
+			return true
+		}
+	}
+	return false
+}
+
+// groupTables 按群组标识拆分桌台推送
+func groupTables(tables []*TableInfo) map[int][]*TableInfo {
+	out := make(map[int][]*TableInfo)
+	for _, t := range tables {
+		out[t.GroupID] = append(out[t.GroupID], t)
+	}
+	return out
+}
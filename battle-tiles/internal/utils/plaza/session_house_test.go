@@ -0,0 +1,169 @@
+package plaza
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"battle-tiles/internal/consts"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// 路由测试不需要回调
+type stubHandler struct{ IPlazaHandler }
+
+// newOfflineSession 不拨号，只搭出连接与店铺结构
+func newOfflineSession(houseGID int) *Session {
+	s := new(Session)
+	s.plazaConn = &plazaConn{owner: s, entered: make(map[int]*Session)}
+	s.houses = cache.New(time.Minute, time.Minute)
+	s.activeHouse.Store(int64(houseGID))
+	s.initHouse(SessionConfig{HouseGID: houseGID, Handler: stubHandler{}})
+	return s
+}
+
+func TestSessionEnterHouseRouting(t *testing.T) {
+	a := newOfflineSession(100)
+	b, err := a.EnterHouse(200, stubHandler{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.SameConn(b) {
+		t.Fatal("entered house should share the connection")
+	}
+	if got := a.EnteredHouses(); !reflect.DeepEqual(got, []int{100, 200}) {
+		t.Fatalf("entered = %v", got)
+	}
+
+	// 未带群组标识归属最近进入的店铺；未进入的群组丢弃并计数
+	a.activeHouse.Store(200)
+	if a.houseOr(100) != a || a.houseOr(0) != b || a.houseOr(999) != nil {
+		t.Fatal("group routing mismatch")
+	}
+	if a.UnroutedPushes() != 1 || b.UnroutedPushes() != 1 {
+		t.Fatalf("unrouted = %d/%d, want 1 on the shared connection", a.UnroutedPushes(), b.UnroutedPushes())
+	}
+	b.setTables([]*TableInfo{{MappedNum: 7, GroupID: 200}})
+	a.activeHouse.Store(100)
+	if a.houseByTable(7) != b || a.houseByTable(8) != a {
+		t.Fatal("table routing mismatch")
+	}
+	groups := groupTables([]*TableInfo{{GroupID: 100}, {GroupID: 200}, {GroupID: 100}})
+	if len(groups[100]) != 2 || len(groups[200]) != 1 {
+		t.Fatalf("groupTables = %v", groups)
+	}
+
+	// 离开一个店铺不断开连接，最后一个离开才关闭
+	a.Shutdown()
+	if a.Closed() {
+		t.Fatal("connection closed while another house is still entered")
+	}
+	if got := b.EnteredHouses(); !reflect.DeepEqual(got, []int{200}) {
+		t.Fatalf("entered after leave = %v", got)
+	}
+	if a.current() != b {
+		t.Fatal("current should fall back to a remaining house")
+	}
+	b.Shutdown()
+	if !b.Closed() {
+		t.Fatal("connection should close after the last house leaves")
+	}
+	if _, err := b.EnterHouse(300, stubHandler{}); err == nil {
+		t.Fatal("entering a closed connection should fail")
+	}
+}
+
+// pushRecorder 记录店铺收到的成员/群组推送
+type pushRecorder struct {
+	IPlazaHandler
+	updated []*MemberUpdated
+	groups  []*GroupProperty
+	deleted []int
+}
+
+func (r *pushRecorder) OnMemberUpdated(m *MemberUpdated) { r.updated = append(r.updated, m) }
+func (r *pushRecorder) OnGroupUpdated(p *GroupProperty)  { r.groups = append(r.groups, p) }
+func (r *pushRecorder) OnGroupDeleted(groupID int)       { r.deleted = append(r.deleted, groupID) }
+
+func TestGroupPushesUpdateHouse(t *testing.T) {
+	a := newOfflineSession(100)
+	rec := &pushRecorder{}
+	b, err := a.EnterHouse(200, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push := func(sub uint16, m Message) {
+		c, ok := LookupCodec(Inbound, consts.MDM_GA_GROUP_SERVICE, sub)
+		if !ok {
+			t.Fatalf("no codec for sub %d", sub)
+		}
+		a.dispatch("87", routes87, c.Packet(m))
+	}
+
+	b.setMembers([]*GroupMember{{MemberID: 6, GameID: 880002}, {MemberID: 7, GameID: 880003}})
+	before := b.ListMembers()
+
+	// 游戏端禁止成员：快照更新、回调带上 GameID，已发出的快照不变
+	push(consts.SUB_GA_MEMBER_UPDATE, &MemberUpdated{GroupID: 200, MemberID: 6, MemberType: 1, MemberRight: consts.MEMBER_RIGHT_FORBID})
+	if len(rec.updated) != 1 || rec.updated[0].GameID != 880002 || !rec.updated[0].Forbidden() {
+		t.Fatalf("member update callback = %+v", rec.updated)
+	}
+	if got := b.ListMembers(); !got[0].Forbidden() || got[0].MemberType != 1 || got[1].Forbidden() {
+		t.Fatalf("members after update = %+v", got)
+	}
+	if before[0].Forbidden() {
+		t.Fatal("earlier member snapshot was mutated")
+	}
+	// 快照中没有的成员：回调不带 GameID
+	push(consts.SUB_GA_MEMBER_UPDATE, &MemberUpdated{GroupID: 200, MemberID: 99})
+	if len(rec.updated) != 2 || rec.updated[1].GameID != 0 {
+		t.Fatalf("unknown member update = %+v", rec.updated)
+	}
+
+	push(consts.SUB_GA_GROUP_PROPERTY, &GroupProperty{GroupID: 200, MemberCount: 2, MaxMemberCount: 500, Name: "二号店"})
+	if p := b.GroupProperty(); p == nil || p.Name != "二号店" || p.MemberCount != 2 || len(rec.groups) != 1 {
+		t.Fatalf("group property = %+v, callbacks = %d", p, len(rec.groups))
+	}
+	if a.GroupProperty() != nil {
+		t.Fatal("group property routed to the wrong house")
+	}
+
+	// 店铺被移除：成员快照清空
+	push(consts.SUB_GA_GROUP_DELETE, &groupDelete{GroupID: 200})
+	if b.ListMembers() != nil || !reflect.DeepEqual(rec.deleted, []int{200}) {
+		t.Fatalf("after delete members = %v, deleted = %v", b.ListMembers(), rec.deleted)
+	}
+}
+
+func TestUnroutedGroupPushDropped(t *testing.T) {
+	a := newOfflineSession(100)
+	rec := &pushRecorder{}
+	b, err := a.EnterHouse(200, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push := func(sub uint16, m Message) {
+		c, ok := LookupCodec(Inbound, consts.MDM_GA_GROUP_SERVICE, sub)
+		if !ok {
+			t.Fatalf("no codec for sub %d", sub)
+		}
+		a.dispatch("87", routes87, c.Packet(m))
+	}
+
+	// 未进入的群组：不落到任何店铺，计数
+	push(consts.SUB_GA_MEMBER_UPDATE, &MemberUpdated{GroupID: 999, MemberID: 6})
+	if len(rec.updated) != 0 {
+		t.Fatalf("unrouted push delivered: %+v", rec.updated)
+	}
+	if a.UnroutedPushes() != 1 {
+		t.Fatalf("unrouted = %d, want 1", a.UnroutedPushes())
+	}
+
+	// 未带群组标识：归属最近进入的店铺，不计数
+	a.activeHouse.Store(200)
+	push(consts.SUB_GA_MEMBER_UPDATE, &MemberUpdated{GroupID: 0, MemberID: 6})
+	if len(rec.updated) != 1 || b.UnroutedPushes() != 1 {
+		t.Fatalf("group-less push: updated = %+v, unrouted = %d", rec.updated, b.UnroutedPushes())
+	}
+}
@@ -0,0 +1,62 @@
+package plaza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 未设置截止时间时单页查询的等待上限
+const battleRecordTimeout = 10 * time.Second
+
+// QueryBattleRecords 通过会话查询一页约战记录（结算时间 [start, end)，pageIndex 从 0 开始）。
+// 同一会话串行执行；超时或 ctx 取消时放弃等待并释放命令队列
+func (that *Session) QueryBattleRecords(ctx context.Context, start, end time.Time, pageIndex, pageSize int) (*BattleRecordPage, error) {
+	if !that._87connReady.Load() || that.shutdown.Load() {
+		return nil, errors.New("session not ready")
+	}
+	that.recordMu.Lock()
+	defer that.recordMu.Unlock()
+
+	ch := make(chan *BattleRecordPage, 1)
+	that.recordWait.Store(&ch)
+	that._87cmdQueue.Push(&GameCommand{
+		Pack: CmdQueryBattleRecord(that.userID, that.houseGID, start, end, pageIndex, pageSize),
+		Type: CmdTypeQueryRecord,
+		Key:  fmt.Sprintf("query_record-%d-%d", pageIndex, time.Now().UnixNano()),
+	})
+
+	timer := time.NewTimer(battleRecordTimeout)
+	defer timer.Stop()
+	select {
+	case page := <-ch:
+		if page == nil {
+			return nil, errors.New("malformed battle record response")
+		}
+		return page, nil
+	case <-ctx.Done():
+		that.abandonRecordQuery(&ch)
+		return nil, ctx.Err()
+	case <-timer.C:
+		that.abandonRecordQuery(&ch)
+		return nil, errors.New("battle record query timeout")
+	}
+}
+
+// abandonRecordQuery 放弃等待；命令已发出但回包未到时解除发送阻塞，迟到的回包丢弃
+func (that *Session) abandonRecordQuery(ch *chan *BattleRecordPage) {
+	that.recordWait.CompareAndSwap(ch, nil)
+	if that.lastCmdType.CompareAndSwap(CmdTypeQueryRecord, -1) {
+		that._87waitingForCmdResponse.Store(false)
+	}
+}
+
+func (that *Session) onBattleRecordPage(page *BattleRecordPage) {
+	if that.lastCmdType.CompareAndSwap(CmdTypeQueryRecord, -1) {
+		that._87waitingForCmdResponse.Store(false)
+	}
+	if ch := that.recordWait.Swap(nil); ch != nil {
+		*ch <- page
+	}
+}
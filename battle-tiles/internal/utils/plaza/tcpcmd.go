@@ -4,173 +4,449 @@ import (
 	"battle-tiles/internal/consts"
 	"battle-tiles/internal/dal/vo/game"
 	"strings"
+	"time"
 )
 
-func CmdAccountLogon(account string, pwd string) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_ACCOUNTS_LUA)
-	packer.PushWord(consts.INVALID_ITEM)
-	packer.PushWord(consts.MARKET_ID)
-	packer.PushByte(consts.DEVICE_TYPE)
-	packer.PushDWord(consts.APP_VERSION)
-	packer.PushDWord(consts.CLIENT_VERSION)
-	packer.PushDWord(consts.STATION_ID)
-	packer.PushDWord(0)
-	packer.PushString(pwd, consts.LEN_MD5)
-	packer.PushString(account, consts.LEN_ACCOUNTS)
-	packer.PushString(consts.MachineID(), consts.LEN_MACHINE_ID)
-	packer.PushString("", consts.LEN_MOBILE_PHONE)
-
-	packer.PaddingTo(249)
-	return packer
-}
+// 客户端命令：每个命令一个结构，布局见 Fields，命令号在 codecs.go 中登记
 
-func CmdForbidMember(user uint32, pwd string, group uint32, member uint32, forbid bool) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_UPDATE_MEMBER)
-	packer.PushDWord(group)
-	packer.PushDWord(member)
+// logonHead 82 登录命令的公共头
+type logonHead struct {
+	ModuleID      uint16
+	MarketID      uint16
+	DeviceType    byte
+	AppVersion    uint32
+	ClientVersion uint32
+	StationID     uint32
+	Reserved      uint32
+}
 
-	packer.PushDWord(user)
-	packer.PushString(pwd, consts.LEN_PASSWORD)
+func newLogonHead() logonHead {
+	return logonHead{
+		ModuleID:      consts.INVALID_ITEM,
+		MarketID:      consts.MARKET_ID,
+		DeviceType:    consts.DEVICE_TYPE,
+		AppVersion:    consts.APP_VERSION,
+		ClientVersion: consts.CLIENT_VERSION,
+		StationID:     consts.STATION_ID,
+	}
+}
 
-	packer.PushWord(consts.UPMEMBER_KIND_RIGHT)
-	if forbid {
-		packer.PushDWord(0x1000)
-	} else {
-		packer.PushDWord(0)
+func (m *logonHead) fields() []Field {
+	return []Field{
+		Word(&m.ModuleID), Word(&m.MarketID), Byte(&m.DeviceType),
+		DWord(&m.AppVersion), DWord(&m.ClientVersion), DWord(&m.StationID), DWord(&m.Reserved),
 	}
-	packer.PaddingTo(84)
+}
+
+// accountLogonCmd 账号登录（SUB_MB_LOGON_ACCOUNTS_LUA）
+type accountLogonCmd struct {
+	logonHead
+	Password    string
+	Accounts    string
+	MachineID   string
+	MobilePhone string
+}
+
+func (m *accountLogonCmd) Fields() []Field {
+	return append(m.logonHead.fields(),
+		Str(&m.Password, consts.LEN_MD5),
+		Str(&m.Accounts, consts.LEN_ACCOUNTS),
+		Str(&m.MachineID, consts.LEN_MACHINE_ID),
+		Str(&m.MobilePhone, consts.LEN_MOBILE_PHONE),
+		PadTo(249),
+	)
+}
+
+func CmdAccountLogon(account string, pwd string) *game.Packer {
+	return Pack(&accountLogonCmd{
+		logonHead: newLogonHead(),
+		Password:  pwd,
+		Accounts:  account,
+		MachineID: consts.MachineID(),
+	})
+}
+
+// mobileLogonCmd 手机号登录（SUB_MB_LOGON_MOBILEPHONE）
+type mobileLogonCmd struct {
+	logonHead
+	Password    string
+	MobilePhone string
+	MachineID   string
+}
 
-	return packer
+func (m *mobileLogonCmd) Fields() []Field {
+	return append(m.logonHead.fields(),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		Str(&m.MobilePhone, consts.LEN_MOBILE_PHONE),
+		Str(&m.MachineID, consts.LEN_MACHINE_ID),
+		PadTo(185),
+	)
 }
 
 func CmdMobileLogon(mobile string, pwd string) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_MB_LOGON, consts.SUB_MB_LOGON_MOBILEPHONE)
-	packer.PushWord(consts.INVALID_ITEM)
-	packer.PushWord(consts.MARKET_ID)
-	packer.PushByte(consts.DEVICE_TYPE)
+	return Pack(&mobileLogonCmd{
+		logonHead:   newLogonHead(),
+		Password:    strings.ToUpper(pwd),
+		MobilePhone: mobile,
+		MachineID:   strings.ToUpper(consts.MachineID()),
+	})
+}
 
-	packer.PushDWord(consts.APP_VERSION)
-	packer.PushDWord(consts.CLIENT_VERSION)
+// updateMemberCmd 修改成员权限（SUB_GA_UPDATE_MEMBER）
+type updateMemberCmd struct {
+	GroupID  uint32
+	MemberID uint32
+	UserID   uint32
+	Password string
+	Kind     uint16
+	Right    uint32
+}
 
-	packer.PushDWord(consts.STATION_ID)
-	packer.PushDWord(0)
+func (m *updateMemberCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.MemberID), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		Word(&m.Kind), DWord(&m.Right),
+		PadTo(84),
+	}
+}
 
-	packer.PushString(strings.ToUpper(pwd), consts.LEN_PASSWORD)
-	packer.PushString(mobile, consts.LEN_MOBILE_PHONE)
-	packer.PushString(strings.ToUpper(consts.MachineID()), consts.LEN_MACHINE_ID)
+func CmdForbidMember(user uint32, pwd string, group uint32, member uint32, forbid bool) *game.Packer {
+	cmd := &updateMemberCmd{GroupID: group, MemberID: member, UserID: user, Password: pwd, Kind: consts.UPMEMBER_KIND_RIGHT}
+	if forbid {
+		cmd.Right = consts.MEMBER_RIGHT_FORBID
+	}
+	return Pack(cmd)
+}
 
-	packer.PaddingTo(185)
+// enterMessageCmd 进入消息服务（SUB_GA_ENTER_MESSAGE）
+type enterMessageCmd struct {
+	UserID    uint32
+	StationID uint16
+}
 
-	return packer
+func (m *enterMessageCmd) Fields() []Field {
+	return []Field{DWord(&m.UserID), Word(&m.StationID), PadTo(8)}
 }
 
 func CmdMsgServerEnterMsg(dwUserID uint32) *game.Packer {
-	packer := &game.Packer{}
+	return Pack(&enterMessageCmd{UserID: dwUserID, StationID: consts.STATION_ID})
+}
 
-	packer.SetCmd(consts.MDM_GA_MESSAGE_SERVICE, consts.SUB_GA_ENTER_MESSAGE)
-	packer.PushDWord(dwUserID)
-	packer.PushWord(consts.STATION_ID)
-	packer.PaddingTo(8)
+// logonServerCmd 登录逻辑服务（SUB_GA_LOGON_SERVER）
+type logonServerCmd struct {
+	UserID    uint32
+	StationID uint32
+	Flag      uint32 // 固定为 4
+	Password  string
+}
 
-	return packer
+func (m *logonServerCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.UserID), DWord(&m.StationID), DWord(&m.Flag),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		PadTo(78),
+	}
 }
 
 func CmdLogonServer(userID uint32, pwdMD5 string) *game.Packer {
-	packer := &game.Packer{}
-
-	packer.SetCmd(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_LOGON_SERVER)
-	packer.PushDWord(userID)
-	packer.PushDWord(consts.STATION_ID)
-	packer.PushDWord(4)
-	packer.PushString(strings.ToUpper(pwdMD5), consts.LEN_PASSWORD)
+	return Pack(&logonServerCmd{UserID: userID, StationID: consts.STATION_ID, Flag: 4, Password: strings.ToUpper(pwdMD5)})
+}
 
-	packer.PaddingTo(78)
+// enterGroupCmd 进入群组（SUB_GA_ENTER_GROUP）
+type enterGroupCmd struct {
+	UserID  uint32
+	GroupID uint32
+}
 
-	return packer
+func (m *enterGroupCmd) Fields() []Field {
+	return []Field{DWord(&m.UserID), DWord(&m.GroupID), PadTo(8)}
 }
 
 func CmdGroupService(userID uint32, groupID uint32) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_GROUP_SERVICE, consts.SUB_GA_ENTER_GROUP)
-	packer.PushDWord(userID)
-	packer.PushDWord(groupID)
-	packer.PaddingTo(8)
-	return packer
+	return Pack(&enterGroupCmd{UserID: userID, GroupID: groupID})
+}
+
+// userStandUpCmd 用户站起（SUB_GR_USER_STANDUP）
+type userStandUpCmd struct {
+	TableID    uint16
+	ChairID    uint16
+	ForceLeave byte
+}
+
+func (m *userStandUpCmd) Fields() []Field {
+	return []Field{Word(&m.TableID), Word(&m.ChairID), Byte(&m.ForceLeave), PadTo(5)}
 }
 
 func CmdUserStandUp(tableID, chairID int) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GR_USER, consts.SUB_GR_USER_STANDUP)
-	packer.PushWord(uint16(tableID))
-	packer.PushWord(uint16(chairID))
-	packer.PushByte(0)
-	packer.PaddingTo(5)
-	return packer
+	return Pack(&userStandUpCmd{TableID: uint16(tableID), ChairID: uint16(chairID)})
 }
 
+// heartBeatCmd 心跳（0/1，无数据）
+type heartBeatCmd struct{}
+
+func (m *heartBeatCmd) Fields() []Field { return nil }
+
 func CmdHeartBeat() *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(0, 1)
-	packer.PaddingTo(0)
-	return packer
+	return Pack(&heartBeatCmd{})
+}
+
+// dismissTableCmd 解散桌台（SUB_GA_DISMISS_TABLE）
+type dismissTableCmd struct {
+	KindID    uint16
+	MappedNum uint32
+	UserID    uint32
+	Password  string
+}
+
+func (m *dismissTableCmd) Fields() []Field {
+	return []Field{
+		Word(&m.KindID), DWord(&m.MappedNum), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		PadTo(76),
+	}
 }
 
 func CmdDismissRoom(userID int, pwdMD5 string, kindID, mappedNum int) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_DISMISS_TABLE)
-	packer.PushWord(uint16(kindID))
-	packer.PushDWord(uint32(mappedNum))
-	packer.PushDWord(uint32(userID))
-	packer.PushString(strings.ToUpper(pwdMD5), consts.LEN_PASSWORD)
-	packer.PaddingTo(76)
-	return packer
+	return Pack(&dismissTableCmd{
+		KindID:    uint16(kindID),
+		MappedNum: uint32(mappedNum),
+		UserID:    uint32(userID),
+		Password:  strings.ToUpper(pwdMD5),
+	})
+}
+
+// applyRespondCmd 处理入群申请（SUB_GA_APPLY_RESPOND）
+type applyRespondCmd struct {
+	MessageID     uint32
+	UserID        uint32
+	Password      string
+	GroupID       uint32
+	ApplierGameID uint32
+	Status        byte
+}
+
+func (m *applyRespondCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.MessageID), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		DWord(&m.GroupID), DWord(&m.ApplierGameID), Byte(&m.Status),
+		PadTo(83),
+	}
 }
 
 func CmdRespondApplication(userId int, pwd string, msgId int, houseGid int, applierGid int, agree bool) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_APPLY_RESPOND)
-	packer.PushDWord(uint32(msgId))
-	packer.PushDWord(uint32(userId))
-	packer.PushString(strings.ToUpper(pwd), consts.LEN_PASSWORD)
-	packer.PushDWord(uint32(houseGid))
-	packer.PushDWord(uint32(applierGid))
+	cmd := &applyRespondCmd{
+		MessageID:     uint32(msgId),
+		UserID:        uint32(userId),
+		Password:      strings.ToUpper(pwd),
+		GroupID:       uint32(houseGid),
+		ApplierGameID: uint32(applierGid),
+		Status:        byte(consts.APPLY_STATUS_REFUSE),
+	}
 	if agree {
-		packer.PushByte(byte(consts.APPLY_STATUS_AGREE))
-	} else {
-		packer.PushByte(byte(consts.APPLY_STATUS_REFUSE))
+		cmd.Status = byte(consts.APPLY_STATUS_AGREE)
+	}
+	return Pack(cmd)
+}
+
+// deleteMemberCmd 踢出成员（SUB_GA_DELETE_MEMBER）
+type deleteMemberCmd struct {
+	GroupID  uint32
+	MemberID uint32
+	UserID   uint32
+	Password string
+}
+
+func (m *deleteMemberCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.MemberID), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+		PadTo(78),
 	}
-	packer.PaddingTo(83)
-	return packer
 }
 
 func CmdDeleteMember(userID int, pwdMD5 string, houseGid int, memId int) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_LOGIC_SERVICE, consts.SUB_GA_DELETE_MEMBER)
-	packer.PushDWord(uint32(houseGid))
-	packer.PushDWord(uint32(memId))
-	packer.PushDWord(uint32(userID))
-	packer.PushString(strings.ToUpper(pwdMD5), consts.LEN_PASSWORD)
+	return Pack(&deleteMemberCmd{
+		GroupID:  uint32(houseGid),
+		MemberID: uint32(memId),
+		UserID:   uint32(userID),
+		Password: strings.ToUpper(pwdMD5),
+	})
+}
 
-	packer.PaddingTo(78)
-	return packer
+// queryTableCmd 查询桌台（SUB_GA_QUERY_TABLE）
+type queryTableCmd struct {
+	Reserved  uint32
+	MappedNum uint32
+}
+
+func (m *queryTableCmd) Fields() []Field {
+	return []Field{DWord(&m.Reserved), DWord(&m.MappedNum)}
 }
 
 func CmdQueryTable(tabMappedNum int) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GA_BATTLE_SERVICE, consts.SUB_GA_QUERY_TABLE)
-	packer.PushDWord(uint32(0))
-	packer.PushDWord(uint32(tabMappedNum))
-	packer.PaddingTo(6)
-	return packer
+	return Pack(&queryTableCmd{MappedNum: uint32(tabMappedNum)})
+}
+
+// queryWealthCmd 查询财富（SUB_GP_QUERY_WEALTH_LUA）
+type queryWealthCmd struct {
+	UserID uint32
 }
 
+func (m *queryWealthCmd) Fields() []Field { return []Field{DWord(&m.UserID)} }
+
 func CmdQueryDiamond(userID int) *game.Packer {
-	packer := &game.Packer{}
-	packer.SetCmd(consts.MDM_GP_USER_SERVICE, consts.SUB_GP_QUERY_WEALTH_LUA)
-	packer.PushDWord(uint32(userID))
-	packer.PaddingTo(4)
-	return packer
+	return Pack(&queryWealthCmd{UserID: uint32(userID)})
+}
+
+// queryRecordCmd 分页查询约战记录（SUB_GP_QUERY_RECORD_LUA）
+type queryRecordCmd struct {
+	UserID    uint32
+	GroupID   uint32
+	StartTime uint32
+	EndTime   uint32
+	PageIndex uint16
+	PageSize  uint16
+}
+
+func (m *queryRecordCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.UserID), DWord(&m.GroupID), DWord(&m.StartTime), DWord(&m.EndTime),
+		Word(&m.PageIndex), Word(&m.PageSize),
+	}
+}
+
+// CmdQueryBattleRecord 分页查询店铺约战记录（按结算时间区间，秒级时间戳），回包 SUB_GA_BATTLE_RECORD
+func CmdQueryBattleRecord(userID int, houseGid int, start, end time.Time, pageIndex, pageSize int) *game.Packer {
+	return Pack(&queryRecordCmd{
+		UserID:    uint32(userID),
+		GroupID:   uint32(houseGid),
+		StartTime: uint32(start.Unix()),
+		EndTime:   uint32(end.Unix()),
+		PageIndex: uint16(pageIndex),
+		PageSize:  uint16(pageSize),
+	})
+}
+
+// configCmd 添加/修改玩法（SUB_GA_APPEND_CONFIG / SUB_GA_MODIFY_CONFIG），两者布局相同
+type configCmd struct {
+	GroupID  uint32
+	Config   BattleConfig
+	UserID   uint32
+	Password string
+}
+
+func (m *configCmd) Fields() []Field {
+	return append(append([]Field{DWord(&m.GroupID)}, m.Config.Fields()...),
+		DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+	)
+}
+
+type appendConfigCmd struct{ configCmd }
+
+type modifyConfigCmd struct{ configCmd }
+
+// CmdAppendConfig 添加玩法（ConfigID 由服务端分配，传 0）
+func CmdAppendConfig(userID int, pwdMD5 string, houseGid int, cfg *BattleConfig) *game.Packer {
+	return Pack(&appendConfigCmd{configCmd{GroupID: uint32(houseGid), Config: *cfg, UserID: uint32(userID), Password: strings.ToUpper(pwdMD5)}})
+}
+
+// CmdModifyConfig 修改玩法
+func CmdModifyConfig(userID int, pwdMD5 string, houseGid int, cfg *BattleConfig) *game.Packer {
+	return Pack(&modifyConfigCmd{configCmd{GroupID: uint32(houseGid), Config: *cfg, UserID: uint32(userID), Password: strings.ToUpper(pwdMD5)}})
+}
+
+// deleteConfigCmd 删除玩法（SUB_GA_DELETE_CONFIG）
+type deleteConfigCmd struct {
+	GroupID  uint32
+	ConfigID uint32
+	UserID   uint32
+	Password string
+}
+
+func (m *deleteConfigCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.ConfigID), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+	}
+}
+
+// CmdDeleteConfig 删除玩法
+func CmdDeleteConfig(userID int, pwdMD5 string, houseGid int, configID uint32) *game.Packer {
+	return Pack(&deleteConfigCmd{GroupID: uint32(houseGid), ConfigID: configID, UserID: uint32(userID), Password: strings.ToUpper(pwdMD5)})
+}
+
+// searchGroupCmd 搜索群组（SUB_GA_SEARCH_GROUP）
+type searchGroupCmd struct {
+	GroupID uint32
+}
+
+func (m *searchGroupCmd) Fields() []Field { return []Field{DWord(&m.GroupID)} }
+
+// CmdSearchGroup 按群组标识搜索，回包 SUB_GA_SEARCH_RESULT（无结果时为空包）
+func CmdSearchGroup(groupID int) *game.Packer {
+	return Pack(&searchGroupCmd{GroupID: uint32(groupID)})
+}
+
+// createGroupCmd 创建群组（SUB_GA_CREATE_GROUP）
+type createGroupCmd struct {
+	UserID   uint32
+	Name     string
+	Password string
+}
+
+func (m *createGroupCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.UserID),
+		Str(&m.Name, consts.LEN_GROUP_NAME),
+		Str(&m.Password, consts.LEN_PASSWORD),
+	}
+}
+
+// CmdCreateGroup 创建群组，成功推送 SUB_GA_GROUP_ITEM（或 SUB_GA_OPERATE_SUCCESS）
+func CmdCreateGroup(userID int, pwdMD5 string, name string) *game.Packer {
+	return Pack(&createGroupCmd{UserID: uint32(userID), Name: name, Password: strings.ToUpper(pwdMD5)})
+}
+
+// updateGroupCmd 修改群组（SUB_GA_UPDATE_GROUP）
+type updateGroupCmd struct {
+	GroupID  uint32
+	Name     string
+	UserID   uint32
+	Password string
+}
+
+func (m *updateGroupCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID),
+		Str(&m.Name, consts.LEN_GROUP_NAME),
+		DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+	}
+}
+
+// CmdUpdateGroup 修改群组名称，成功推送 SUB_GA_GROUP_UPDATE（或 SUB_GA_OPERATE_SUCCESS）
+func CmdUpdateGroup(userID int, pwdMD5 string, groupID int, name string) *game.Packer {
+	return Pack(&updateGroupCmd{GroupID: uint32(groupID), Name: name, UserID: uint32(userID), Password: strings.ToUpper(pwdMD5)})
+}
+
+// deleteGroupCmd 删除群组（SUB_GA_DELETE_GROUP）
+type deleteGroupCmd struct {
+	GroupID  uint32
+	UserID   uint32
+	Password string
+}
+
+func (m *deleteGroupCmd) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.UserID),
+		Str(&m.Password, consts.LEN_PASSWORD),
+	}
+}
+
+// CmdDeleteGroup 删除群组，成功推送 SUB_GA_GROUP_DELETE（或 SUB_GA_OPERATE_SUCCESS）
+func CmdDeleteGroup(userID int, pwdMD5 string, groupID int) *game.Packer {
+	return Pack(&deleteGroupCmd{GroupID: uint32(groupID), UserID: uint32(userID), Password: strings.ToUpper(pwdMD5)})
 }
@@ -3,87 +3,80 @@ package plaza
 import (
 	"battle-tiles/internal/consts"
 	"battle-tiles/internal/dal/vo/game"
-	"bytes"
 	"fmt"
 	"time"
-	"unicode/utf16"
-	"unicode/utf8"
 )
 
+// Response 按偏移读取的旧式解析；越界的读取返回 0（字符串读到结尾为止），
+// 首个越界错误记录在 Err 中
 type Response struct {
 	data []byte
+	err  error
+}
+
+// Err 首个越界读取的错误
+func (that *Response) Err() error {
+	return that.err
+}
+
+func (that *Response) has(offset, n int) bool {
+	if offset >= 0 && offset+n <= len(that.data) {
+		return true
+	}
+	if that.err == nil {
+		that.err = fmt.Errorf("%w: need %d bytes at offset %d, have %d", ErrShortPacket, n, offset, len(that.data))
+	}
+	return false
+}
+
+func (that *Response) readUint(offset, n int) uint64 {
+	if !that.has(offset, n) {
+		return 0
+	}
+	var v uint64
+	for i := n - 1; i >= 0; i-- {
+		v = v<<8 | uint64(that.data[offset+i])
+	}
+	return v
 }
 
 func (that *Response) ReadByte(offset int) byte {
-	return that.data[offset]
+	return byte(that.readUint(offset, 1))
 }
 
 func (that *Response) ReadWord(offset int) uint16 {
-	lo := uint16(that.data[offset])
-	offset++
-	hi := uint16(that.data[offset])
-	return hi<<8 + lo
+	return uint16(that.readUint(offset, 2))
 }
 
 func (that *Response) ReadDWord(offset int) uint32 {
-	b1 := uint32(that.data[offset])
-	offset++
-	b2 := uint32(that.data[offset])
-	offset++
-	b3 := uint32(that.data[offset])
-	offset++
-	b4 := uint32(that.data[offset])
-	return b4<<24 + b3<<16 + b2<<8 + b1
+	return uint32(that.readUint(offset, 4))
 }
 
 func (that *Response) ReadLong(offset int) uint64 {
-	b1 := uint64(that.data[offset])
-	offset++
-	b2 := uint64(that.data[offset])
-	offset++
-	b3 := uint64(that.data[offset])
-	offset++
-	b4 := uint64(that.data[offset])
-	offset++
-	b5 := uint64(that.data[offset])
-	offset++
-	b6 := uint64(that.data[offset])
-	offset++
-	b7 := uint64(that.data[offset])
-	offset++
-	b8 := uint64(that.data[offset])
-	return b8<<56 + b7<<48 + b6<<40 + b5<<32 + b4<<24 + b3<<16 + b2<<8 + b1
+	return that.readUint(offset, 8)
 }
 
 func (that *Response) ReadString(offset int, ln int) string {
-	u16s := make([]uint16, 1)
-	ret := &bytes.Buffer{}
-	b8buf := make([]byte, 4)
-	for i := offset; i < len(that.data) && i < offset+(ln*2); i += 2 {
-		if that.data[i] == 0 && that.data[i+1] == 0 {
-			break
-		}
-
-		u16s[0] = uint16(that.data[i]) + (uint16(that.data[i+1]) << 8)
-		r := utf16.Decode(u16s)
-		n := utf8.EncodeRune(b8buf, r[0])
-		ret.Write(b8buf[:n])
+	if !that.has(offset, 0) {
+		return ""
 	}
-
-	return ret.String()
+	end := min(len(that.data), offset+2*max(ln, 0))
+	return decodeUTF16(that.data[offset:end])
 }
 
-func ParseUserLogon(data []byte) *game.UserLogonInfo {
-	var res Response
-	res.data = data
+// userLogonReply 登录成功（SUB_MB_LOGON_SUCCESS）
+type userLogonReply struct {
+	game.UserLogonInfo
+}
 
-	var info game.UserLogonInfo
-	offset := 7
-	info.UserID = res.ReadDWord(offset)
-	offset += 4
-	info.GameID = res.ReadDWord(offset)
+func (m *userLogonReply) Fields() []Field {
+	return []Field{Skip(7), DWord(&m.UserID), DWord(&m.GameID)}
+}
 
-	return &info
+func ParseUserLogon(data []byte) *game.UserLogonInfo {
+	var m userLogonReply
+	_ = Decode(data, &m)
+	return &m.UserLogonInfo
 }
 
 type ServerAgentList struct {
@@ -102,17 +95,13 @@ func ParseServerAgent(data []byte) []*ServerAgentList {
 
 	var ret []*ServerAgentList
 	offset := 0
-	for {
-		if offset >= len(data) {
-			break
-		}
-
+	for offset < len(data) {
 		sal := &ServerAgentList{}
 		sal.ServerID = res.ReadWord(offset)
 		offset += 2
 		count := int(res.ReadWord(offset))
 		offset += 2
-		for i := 0; i < count; i++ {
+		for i := 0; i < count && res.Err() == nil; i++ {
 			agent := &ServerAgent{}
 			agent.AgentID = res.ReadWord(offset)
 			offset += 2
@@ -120,6 +109,9 @@ func ParseServerAgent(data []byte) []*ServerAgentList {
 			offset += 2
 			sal.Agents = append(sal.Agents, agent)
 		}
+		if res.Err() != nil { // 截断的尾项丢弃
+			break
+		}
 
 		ret = append(ret, sal)
 	}
@@ -133,40 +125,52 @@ type Access struct {
 	Addr string
 }
 
+// LenAccessItem 单条接入地址的字节数
+const LenAccessItem = 4 + consts.LEN_SERVER*2
+
+func (m *Access) Fields() []Field {
+	return []Field{Word(&m.ID), Word(&m.Port), Str(&m.Addr, consts.LEN_SERVER)}
+}
+
+// accessList 接入地址列表（MDM_MB_SERVER_LIST / SUB_MB_LIST_ACCESS）
+type accessList struct {
+	Items []*Access
+}
+
+func (m *accessList) Fields() []Field {
+	return []Field{Items(&m.Items, LenAccessItem, (*Access).Fields)}
+}
+
 func ParseListAccess(data []byte) []*Access {
-	var res Response
-	res.data = data
+	var m accessList
+	_ = Decode(data, &m)
+	return m.Items
+}
 
-	var ret []*Access
-	count := len(data) / 68 //LEN_ACCESS_ITEM
-	var offset int
-	for i := 0; i < count; i++ {
-		offset = i * 68
-		acc := &Access{}
-		acc.ID = res.ReadWord(offset)
-		offset += 2
-		acc.Port = res.ReadWord(offset)
-		offset += 2
-		acc.Addr = res.ReadString(offset, consts.LEN_SERVER)
-		offset += consts.LEN_SERVER
-		ret = append(ret, acc)
-	}
-	return ret
+// serverList 房间列表（SUB_MB_LIST_SERVER），结构未定，保留原始字节
+type serverList struct {
+	Data []byte
 }
 
+func (m *serverList) Fields() []Field { return []Field{Rest(&m.Data)} }
+
+// emptyReply 无数据的回包（操作成功、列表完成等）
+type emptyReply struct{}
+
+func (m *emptyReply) Fields() []Field { return nil }
+
 type SystemMessage struct {
 	Type uint16
 	Text string
 }
 
-func ParseSystemMessage(data []byte) *SystemMessage {
-	var res Response
-	res.data = data
+func (m *SystemMessage) Fields() []Field {
+	return []Field{Word(&m.Type), Str(&m.Text, 128)}
+}
 
+func ParseSystemMessage(data []byte) *SystemMessage {
 	sm := &SystemMessage{}
-
-	sm.Type = res.ReadWord(0)
-	sm.Text = res.ReadString(2, 128)
+	_ = Decode(data, sm)
 	return sm
 }
 
@@ -182,16 +186,15 @@ func ParseMessageList(data []byte) []*MessageItem {
 
 	var ret []*MessageItem
 	offset := 0
-	for {
-		if offset >= len(data) {
-			break
-		}
-
+	for offset < len(data) {
 		item := &MessageItem{}
 		item.Type = res.ReadByte(offset)
 		offset++
 		item.Length = res.ReadWord(offset)
 		offset += 2
+		if res.Err() != nil { // 截断的尾项丢弃
+			break
+		}
 		item.Message = res.ReadString(offset, int(item.Length))
 		offset += int(item.Length)
 
@@ -259,105 +262,81 @@ type LogonFailure struct {
 	Desc string
 }
 
-func ParseLogonFailure(data []byte) *LogonFailure {
-	var res Response
-	res.data = data
+func (m *LogonFailure) Fields() []Field {
+	return []Field{DWord(&m.Code), Str(&m.Desc, 128)}
+}
 
+func ParseLogonFailure(data []byte) *LogonFailure {
 	var ret LogonFailure
-	ret.Code = int(res.ReadDWord(0))
-	ret.Desc = res.ReadString(4, 128)
+	_ = Decode(data, &ret)
 	return &ret
-
 }
 
 type GroupMember struct {
-	UserID     uint32
-	UserStatus int
-	GameID     uint32
-	MemberID   uint32
-	MemberType int
-	NickName   string
+	UserID      uint32
+	UserStatus  int
+	GameID      uint32
+	MemberID    uint32
+	MemberType  int
+	MemberRight uint32
+	NickName    string
+}
+
+// Forbidden 成员权限含禁止位（游戏端或本系统禁分）
+func (m *GroupMember) Forbidden() bool { return m.MemberRight&consts.MEMBER_RIGHT_FORBID != 0 }
+
+// LenGroupMemberItem 单个成员的字节数（之后的入群时间、约战统计等不解析）
+const LenGroupMemberItem = 127
+
+func (m *GroupMember) Fields() []Field {
+	return []Field{
+		DWord(&m.UserID),
+		DWord(&m.GameID),
+		Byte(&m.UserStatus), // struct.cbGender
+		Skip(1),             // struct.cbUserStatus
+		Str(&m.NickName, consts.LEN_ACCOUNTS),
+		Skip(4), // struct.dwCustomID
+		DWord(&m.MemberID),
+		Byte(&m.MemberType),
+		DWord(&m.MemberRight),
+	}
 }
 
-func ParseGroupMember(data []byte) []*GroupMember {
-	var res Response
-	res.data = data
+// groupMemberList 成员列表（SUB_GA_GROUP_MEMBER）：4 字节头之后为定长成员项
+type groupMemberList struct {
+	Members []*GroupMember
+}
+
+func (m *groupMemberList) Fields() []Field {
+	return []Field{Skip(4), Items(&m.Members, LenGroupMemberItem, (*GroupMember).Fields)}
+}
 
-	var members []*GroupMember
-	//_ := res.ReadDWord(0)
-
-	count := (len(data) - 4) / 127
-	offset := 4
-	for i := 0; i < count; i++ {
-		offset = 4 + i*127
-		//user Info
-		mem := new(GroupMember)
-		members = append(members, mem)
-		mem.UserID = res.ReadDWord(offset)
-		offset += 4
-		mem.GameID = res.ReadDWord(offset)
-		offset += 4
-
-		mem.UserStatus = int(res.ReadByte(offset))
-		offset += 1 //struct.cbGender      = pBuffer:readbyte()
-		offset += 1 // struct.cbUserStatus  = pBuffer:readbyte()
-		mem.NickName = res.ReadString(offset, consts.LEN_ACCOUNTS)
-		offset += consts.LEN_ACCOUNTS * 2 // struct.szNickName    = pBuffer:readstring(df.LEN_ACCOUNTS)
-		offset += 4                       // struct.dwCustomID    = pBuffer:readdword()
-
-		//member Info
-		mem.MemberID = res.ReadDWord(offset)
-		offset += 4
-
-		mem.MemberType = int(res.ReadByte(offset))
-		//fmt.Printf("member name:%s,member type:%d,member id:%d,gameID=%d,status=%d\n", mem.NickName, mem.MemberType, mem.MemberID, mem.GameID, mem.UserStatus)
-		//offset += 1 //struct.cbMemberType    = pBuffer:readbyte()                            --成员类型
-		//offset += 4 //struct.dwMemberRight   = pBuffer:readdword()                           --成员权限
-		//offset += 2 //struct.JoinDateTime.wYear 	 	  = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wMonth 	 	  = pBuffer:readword()
-		//offset += 2 //sstruct.JoinDateTime.wDayOfWeek 	  = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wDay = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wHour = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wMinute = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wSecond = pBuffer:readword()
-		//offset += 2 //struct.JoinDateTime.wMilliseconds = pBuffer:readword()
-		//offset += 4 //struct.dwCreateCount = pBuffer:readdword()                                --创建次数
-		//offset += 4 //struct.dwBattleCount = pBuffer:readdword()                                --参与次数
-		//
-		////最近战绩
-		//offset += 2 // struct.BattleDateTime.wYear = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wMonth = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wDayOfWeek = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wDay = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wHour = pBuffer:readword()
-		//offset += 2 //sstruct.BattleDateTime.wMinute = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wSecond = pBuffer:readword()
-		//offset += 2 //struct.BattleDateTime.wMilliseconds = pBuffer:readword()
-	}
-
-	return members
+func ParseGroupMember(data []byte) []*GroupMember {
+	var m groupMemberList
+	_ = Decode(data, &m)
+	return m.Members
 }
 
 type UserSitDown struct {
+	GroupID   uint32
 	UserID    uint32
 	GameID    uint32
 	MappedNum uint32
 	ChairID   uint16
 }
 
-func ParseUserSitDown(data []byte) *UserSitDown {
-	var res Response
-	res.data = data
+// Fields 群组、映射编号之后为 TableUserItem，只取椅子与用户标识
+func (m *UserSitDown) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.MappedNum),
+		Skip(2), // wFaceID
+		Word(&m.ChairID), DWord(&m.UserID), DWord(&m.GameID),
+	}
+}
 
+func ParseUserSitDown(data []byte) *UserSitDown {
 	var ret UserSitDown
-	offset := 4
-	ret.MappedNum = res.ReadDWord(offset)
-
-	tableUser := ParseTableUserItem(data[8:])
-	ret.UserID = tableUser.UserID
-	ret.ChairID = tableUser.ChairID
-	ret.GameID = tableUser.GameID
-
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -370,15 +349,13 @@ type UserStandUp struct {
 	//struct.dwMappedNum 	= pBuffer:readdword()						    --映射编号
 }
 
-func ParseUserStandUp(data []byte) *UserStandUp {
-	var res Response
-	res.data = data
+func (m *UserStandUp) Fields() []Field {
+	return []Field{Word(&m.ChairID), DWord(&m.UserID), DWord(&m.MappedNum)}
+}
 
+func ParseUserStandUp(data []byte) *UserStandUp {
 	var ret UserStandUp
-
-	ret.ChairID = res.ReadWord(0)
-	ret.UserID = res.ReadDWord(2)
-	ret.MappedNum = res.ReadDWord(6)
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -398,23 +375,16 @@ type TableUserItem struct {
 	//struct.szNickName = pBuffer:readstring(df.LEN_ACCOUNTS)							--用户昵称 80
 }
 
-func ParseTableUserItem(data []byte) *TableUserItem {
-	var res Response
-	res.data = data
+func (m *TableUserItem) Fields() []Field {
+	return []Field{
+		Word(&m.FaceID), Word(&m.ChairID), DWord(&m.UserID), DWord(&m.GameID), DWord(&m.CustomID),
+		Str(&m.NickName, consts.LEN_ACCOUNTS),
+	}
+}
 
+func ParseTableUserItem(data []byte) *TableUserItem {
 	var ret TableUserItem
-	offset := 0
-	ret.FaceID = res.ReadWord(offset)
-	offset += 2
-	ret.ChairID = res.ReadWord(offset)
-	offset += 2
-	ret.UserID = res.ReadDWord(offset)
-	offset += 4
-	ret.GameID = res.ReadDWord(offset)
-	offset += 4
-	ret.CustomID = res.ReadDWord(offset)
-	offset += 4
-	ret.NickName = res.ReadString(offset, consts.LEN_ACCOUNTS)
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -423,6 +393,11 @@ type TableList struct {
 	Tables []*TableInfo
 }
 
+// Fields Count 不在布局中，由 ParseTableList 按解出的桌台数填充
+func (m *TableList) Fields() []Field {
+	return []Field{ListW(&m.Tables, (*TableInfo).Fields)}
+}
+
 type TableInfo struct {
 	TableID   int
 	MappedNum int
@@ -435,61 +410,25 @@ type TableInfo struct {
 	//struct.wUserCount    = pBuffer:readword() 							--用户数量 12
 }
 
-func ParseTableList(data []byte) *TableList {
-	var res Response
-	res.data = data
-
-	var ret TableList
-	offset := 0
-	ret.Count = int(res.ReadWord(offset))
-	offset += 2
-
-	for i := 0; i < ret.Count; i++ {
-		tableID := int(res.ReadWord(offset))
-		offset += 2
-		offset += 2
-		offset += 4
-		mappedNum := int(res.ReadDWord(offset))
-		offset += 4
-		//passwd := res.ReadString(offset, LEN_PASSWORD)
-		offset += 33 * 2
-
-		groupID := int(res.ReadDWord(offset))
-		offset += 4
-		offset += 4
-
-		kindID := int(res.ReadWord(offset))
-		offset += 2
-		offset += 2
-		offset += 4
-		offset += 63 * 2
-
-		offset += 4
-
-		offset += 2
-		offset += 2
-		offset += 1
-		offset += 1
-		offset += 1
-		offset += 2
-		offset += 4
-
-		baseScore := res.ReadLong(offset)
-		offset += 8
-		offset += 8
-
-		offset += 2
-		offset += 1
-
-		ret.Tables = append(ret.Tables, &TableInfo{
-			TableID:   tableID,
-			MappedNum: mappedNum,
-			GroupID:   groupID,
-			KindID:    kindID,
-			BaseScore: int(baseScore),
-		})
+func (m *TableInfo) Fields() []Field {
+	return []Field{
+		Word(&m.TableID),
+		Skip(2 + 4),
+		DWord(&m.MappedNum),
+		Skip(33 * 2), // 桌台密码
+		DWord(&m.GroupID),
+		Skip(4),
+		Word(&m.KindID),
+		Skip(2 + 4 + 63*2 + 4 + 2 + 2 + 1 + 1 + 1 + 2 + 4),
+		Long(&m.BaseScore),
+		Skip(8 + 2 + 1),
 	}
+}
 
+func ParseTableList(data []byte) *TableList {
+	var ret TableList
+	_ = Decode(data, &ret)
+	ret.Count = len(ret.Tables)
 	return &ret
 }
 
@@ -505,111 +444,93 @@ type ApplyInfo struct {
 	CreatedAt     int64
 }
 
-func ParseApplyList(data []byte) []*ApplyInfo {
-	var res Response
-	res.data = data
+// applyList 申请消息（SUB_GA_APPLY_MESSAGE）
+type applyList struct {
+	Type  byte
+	Items []*applyItem
+}
 
-	offset := 0
-	applyType := int(res.ReadByte(offset))
-	fmt.Printf("申请状态:%d\n", applyType)
-	offset += 1
+func (m *applyList) Fields() []Field {
+	return []Field{Byte(&m.Type), ListW(&m.Items, (*applyItem).Fields)}
+}
 
-	applyCount := int(res.ReadWord(offset))
-	offset += 2
+type applyItem struct {
+	MessageID     uint32
+	Status        byte
+	ApplierID     uint32
+	ApplierGameID uint32
+	NickName      string
+	Time          systemTime
+	GroupID       uint32
+	CreatorID     uint32
+}
 
-	var result []*ApplyInfo
-	for i := 0; i < applyCount; i++ {
-		// struct.dwMessageID		  = pBuffer:readdword()					        --消息标识
-		messageId := res.ReadDWord(offset)
-		offset += 4
-		// struct.cbMessageStatus    = pBuffer:readbyte()					        --消息状态
-		status := res.ReadByte(offset)
-		offset += 1
-		// --申请信息
-		// struct.dwApplyerID 		  = pBuffer:readdword()					        --用户标识
-		applierId := res.ReadDWord(offset)
-		offset += 4
-		gid := res.ReadDWord(offset)
-		// struct.dwApplyerGameID    = pBuffer:readdword()				            --游戏标识
-		offset += 4
-		// struct.dwApplyerCustomID  = pBuffer:readdword()							--头像标识
-		offset += 4
-		// struct.szApplyerNickName  = pBuffer:readstring(df.LEN_ACCOUNTS) 	    --用户昵称
-		gname := res.ReadString(offset, consts.LEN_ACCOUNTS)
-		offset += consts.LEN_ACCOUNTS * 2
-		// struct.ApplyDateTime      = {}						                    --申请时间
-		// struct.ApplyDateTime.wYear 	 	   = pBuffer:readword()
-		year := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wMonth        = pBuffer:readword()
-		mon := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wDayOfWeek    = pBuffer:readword()
-		offset += 2
-		// // struct.ApplyDateTime.wDay 		   = pBuffer:readword()
-		day := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wHour 	 	   = pBuffer:readword()
-		hour := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wMinute 	   = pBuffer:readword()
-		min := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wSecond 	   = pBuffer:readword()
-		sec := res.ReadWord(offset)
-		offset += 2
-		// // struct.ApplyDateTime.wMilliseconds = pBuffer:readword()
-		ms := res.ReadWord(offset)
-		offset += 2
+func (m *applyItem) Fields() []Field {
+	return append(append([]Field{
+		DWord(&m.MessageID),
+		Byte(&m.Status),
+		DWord(&m.ApplierID),
+		DWord(&m.ApplierGameID),
+		Skip(4), // dwApplyerCustomID
+		Str(&m.NickName, consts.LEN_ACCOUNTS),
+	}, m.Time.Fields()...),
+		DWord(&m.GroupID),
+		DWord(&m.CreatorID),
+		Skip(consts.LEN_GROUP_NAME*2), // szGroupName
+	)
+}
 
-		// --群组信息
-		// struct.dwGroupID          = pBuffer:readdword()					    --群组标识
-		houseGid := res.ReadDWord(offset)
-		offset += 4
-		// struct.dwCreaterID        = pBuffer:readdword()				        --馆主标识
-		creatorID := res.ReadDWord(offset)
-		offset += 4
-		// struct.szGroupName        = pBuffer:readstring(df.LEN_GROUP_NAME)		--群组名称
-		// groupName := res.ReadString(offset, LEN_GROUP_NAME)
-		// fmt.Println(groupName)
-		offset += consts.LEN_GROUP_NAME * 2
-
-		t, _ := time.Parse("2006-01-02 15:04:05", fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", year, mon, day, hour, min, sec))
-		createdAt := t.Unix() + int64(ms)
+// systemTime Windows SYSTEMTIME（8 个 WORD）
+type systemTime struct {
+	Year, Month, DayOfWeek, Day, Hour, Minute, Second, Milliseconds uint16
+}
 
+func (t *systemTime) Fields() []Field {
+	return []Field{
+		Word(&t.Year), Word(&t.Month), Word(&t.DayOfWeek), Word(&t.Day),
+		Word(&t.Hour), Word(&t.Minute), Word(&t.Second), Word(&t.Milliseconds),
+	}
+}
+
+func (m *applyList) infos() []*ApplyInfo {
+	var result []*ApplyInfo
+	for _, it := range m.Items {
+		tm := it.Time
+		t, _ := time.ParseInLocation("2006-01-02 15:04:05", fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", tm.Year, tm.Month, tm.Day, tm.Hour, tm.Minute, tm.Second), time.Local)
 		result = append(result, &ApplyInfo{
-			AplierId:      int(applierId),
-			ApplierGid:    int(gid),
-			ApplierGName:  gname,
-			HouseGid:      int(houseGid),
-			MessageId:     int(messageId),
-			MessageStatus: int(status),
-			ApplyType:     applyType,
-			AdminUserID:   int(creatorID),
-			CreatedAt:     createdAt,
+			AplierId:      int(it.ApplierID),
+			ApplierGid:    int(it.ApplierGameID),
+			ApplierGName:  it.NickName,
+			HouseGid:      int(it.GroupID),
+			MessageId:     int(it.MessageID),
+			MessageStatus: int(it.Status),
+			ApplyType:     int(m.Type),
+			AdminUserID:   int(it.CreatorID),
+			CreatedAt:     t.Unix() + int64(tm.Milliseconds),
 		})
 	}
-
-	// if applyType == APPLY_MSG_TYPE_RESPOND {
-	// 	hlogger.Info("忽略申请信息,这是一个Respond")
-	// 	return nil
-	// }
 	return result
 }
 
+func ParseApplyList(data []byte) []*ApplyInfo {
+	var m applyList
+	_ = Decode(data, &m)
+	return m.infos()
+}
+
+// DismissResult 桌台解散推送（SUB_GA_TABLE_DISMISS）
 type DismissResult struct {
 	GroupID   int
 	MappedNum int
 }
 
-func ParseDismissTable(data []byte) *DismissResult {
-	var res Response
-	res.data = data
+func (m *DismissResult) Fields() []Field {
+	return []Field{DWord(&m.GroupID), DWord(&m.MappedNum)}
+}
 
+func ParseDismissTable(data []byte) *DismissResult {
 	var ret DismissResult
-	ret.GroupID = int(res.ReadDWord(0))
-	ret.MappedNum = int(res.ReadDWord(4))
-
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -618,14 +539,13 @@ type BattleOpFail struct {
 	Msg  string
 }
 
-func ParseBattleOpFail(data []byte) *BattleOpFail {
-	var res Response
-	res.data = data
+func (m *BattleOpFail) Fields() []Field {
+	return []Field{DWord(&m.Code), StrRest(&m.Msg)}
+}
 
+func ParseBattleOpFail(data []byte) *BattleOpFail {
 	var ret BattleOpFail
-	ret.Code = int(res.ReadDWord(0))
-	ret.Msg = res.ReadString(4, len(data)-4)
-
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -634,14 +554,13 @@ type DismissTableResult struct {
 	Msg  string
 }
 
-func ParseDismissTableResult(data []byte) *DismissTableResult {
-	var res Response
-	res.data = data
+func (m *DismissTableResult) Fields() []Field {
+	return []Field{Byte(&m.Code), StrRest(&m.Msg)}
+}
 
+func ParseDismissTableResult(data []byte) *DismissTableResult {
 	var ret DismissTableResult
-	ret.Code = int(res.ReadByte(0))
-	ret.Msg = res.ReadString(1, len(data)-1)
-
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -654,34 +573,57 @@ type MemberInserted struct {
 	GameID uint32
 }
 
-func ParseMemberInserted(data []byte) *MemberInserted {
-	var res Response
-	res.data = data
+func (m *MemberInserted) Fields() []Field {
+	return []Field{DWord(&m.GroupID), Word(&m.MemCount), DWord(&m.UserID), DWord(&m.GameID)}
+}
 
+func ParseMemberInserted(data []byte) *MemberInserted {
 	var ret MemberInserted
-	offset := 0
-	ret.GroupID = res.ReadDWord(0)
-	offset += 4
-	ret.MemCount = res.ReadWord(offset)
-	offset += 2
-	ret.UserID = res.ReadDWord(offset)
-	offset += 4
-	ret.GameID = res.ReadDWord(offset)
+	_ = Decode(data, &ret)
 	return &ret
+}
 
+// MemberUpdated 成员更新推送（SUB_GA_MEMBER_UPDATE），类型与权限为更新后的全量值
+type MemberUpdated struct {
+	GroupID     uint32
+	MemberID    uint32
+	MemberType  int
+	MemberRight uint32
+	GameID      uint32 // 会话按成员快照补齐，快照中没有时为 0
+	//struct.dwGroupID     = pBuffer:readdword()							--群组标识 4
+	//struct.dwMemberID    = pBuffer:readdword()							--成员标识 8
+	//struct.cbMemberType  = pBuffer:readbyte()							--成员类型 9
+	//struct.dwMemberRight = pBuffer:readdword()							--成员权限 13
+}
+
+// Forbidden 更新后是否禁止
+func (m *MemberUpdated) Forbidden() bool { return m.MemberRight&consts.MEMBER_RIGHT_FORBID != 0 }
+
+func (m *MemberUpdated) Fields() []Field {
+	return []Field{DWord(&m.GroupID), DWord(&m.MemberID), Byte(&m.MemberType), DWord(&m.MemberRight)}
+}
+
+// ParseMemberUpdated 长度不足返回 nil
+func ParseMemberUpdated(data []byte) *MemberUpdated {
+	var ret MemberUpdated
+	if Decode(data, &ret) != nil {
+		return nil
+	}
+	return &ret
 }
 
 type MemberDeleted struct {
+	GroupID  uint32
 	MemberID uint32
 }
 
-func ParseMemberDeleted(data []byte) *MemberDeleted {
-	var res Response
-	res.data = data
+func (m *MemberDeleted) Fields() []Field {
+	return []Field{DWord(&m.GroupID), DWord(&m.MemberID)}
+}
 
+func ParseMemberDeleted(data []byte) *MemberDeleted {
 	var ret MemberDeleted
-	offset := 4
-	ret.MemberID = res.ReadDWord(offset)
+	_ = Decode(data, &ret)
 	return &ret
 }
 
@@ -693,17 +635,10 @@ func ParseTableUserList(data []byte) *TableUserList {
 }
 
 func ParseTableDismissed(data []byte) *TableInfo {
-	var res Response
-	res.data = data
-
-	offset := 0
-	groupID := res.ReadDWord(offset)
-	offset += 4
-	mappedNum := res.ReadDWord(offset)
-
+	r := ParseDismissTable(data)
 	return &TableInfo{
-		GroupID:   int(groupID),
-		MappedNum: int(mappedNum),
+		GroupID:   r.GroupID,
+		MappedNum: r.MappedNum,
 	}
 }
 
@@ -712,17 +647,323 @@ type TableRenew struct {
 	NewMappedNum int
 }
 
+func (m *TableRenew) Fields() []Field {
+	return []Field{DWord(&m.MappedNum), Skip(2), DWord(&m.NewMappedNum)}
+}
+
 func ParseTableRenew(data []byte) *TableRenew {
-	var res Response
-	res.data = data
+	var ret TableRenew
+	_ = Decode(data, &ret)
+	return &ret
+}
 
-	offset := 0
-	mappedNum := res.ReadDWord(offset)
-	offset += 6
-	newMappedNum := res.ReadDWord(offset)
+// BattleConfig 店铺约战玩法（SUB_GA_BATTLE_CONFIG / CONFIG_APPEND / CONFIG_MODIFY）
+type BattleConfig struct {
+	ConfigID    uint32
+	KindID      uint16
+	BaseScore   uint32
+	PlayCount   uint16 // 局数
+	PlayerCount uint16 // 人数
+	Name        string
+	//struct.dwConfigID   = pBuffer:readdword()							--玩法标识 4
+	//struct.wKindID      = pBuffer:readword()							--游戏类型 6
+	//struct.lBaseScore   = pBuffer:readdword()							--游戏底分 10
+	//struct.wPlayCount   = pBuffer:readword()							--游戏局数 12
+	//struct.wPlayerCount = pBuffer:readword()							--游戏人数 14
+	//struct.szConfigName = pBuffer:readstring(df.LEN_CONFIG_NAME)		--玩法名称 78
+}
+
+// LenBattleConfigItem 单条玩法的字节数
+const LenBattleConfigItem = 14 + consts.LEN_CONFIG_NAME*2
+
+func (m *BattleConfig) Fields() []Field {
+	return []Field{
+		DWord(&m.ConfigID), Word(&m.KindID), DWord(&m.BaseScore), Word(&m.PlayCount), Word(&m.PlayerCount),
+		Str(&m.Name, consts.LEN_CONFIG_NAME),
+	}
+}
+
+// battleConfigList 全量玩法（SUB_GA_BATTLE_CONFIG）
+type battleConfigList struct {
+	Items []*BattleConfig
+}
+
+func (m *battleConfigList) Fields() []Field {
+	return []Field{Items(&m.Items, LenBattleConfigItem, (*BattleConfig).Fields)}
+}
+
+// ParseBattleConfigList 全量玩法列表（按定长项切分，不足一项的尾部忽略）
+func ParseBattleConfigList(data []byte) []*BattleConfig {
+	var m battleConfigList
+	_ = Decode(data, &m)
+	if m.Items == nil {
+		m.Items = []*BattleConfig{}
+	}
+	return m.Items
+}
+
+// ParseBattleConfigItem 单条玩法（添加/修改推送），长度不足返回 nil
+func ParseBattleConfigItem(data []byte) *BattleConfig {
+	if len(data) < LenBattleConfigItem {
+		return nil
+	}
+	var cfg BattleConfig
+	_ = Decode(data, &cfg)
+	return &cfg
+}
+
+// configDelete 玩法删除推送（SUB_GA_CONFIG_DELETE）
+type configDelete struct {
+	ConfigID uint32
+}
+
+func (m *configDelete) Fields() []Field { return []Field{DWord(&m.ConfigID)} }
+
+// ParseBattleConfigDelete 玩法删除推送，返回玩法标识
+func ParseBattleConfigDelete(data []byte) uint32 {
+	var m configDelete
+	if Decode(data, &m) != nil {
+		return 0
+	}
+	return m.ConfigID
+}
+
+// UserWealth 财富信息（查询回包 SUB_GP_USER_WEALTH / 推送 SUB_GA_WEALTH_UPDATE），钻石即 Ingot
+type UserWealth struct {
+	UserID   uint32
+	Mask     byte // 本次包含的字段（consts.WEALTH_MASK_*），查询回包为全量
+	Ingot    int64
+	Medal    int64
+	Score    int64
+	RoomCard int64
+	//查询回包：
+	//struct.dwUserID     = pBuffer:readdword()							--用户标识 4
+	//struct.lUserIngot   = pBuffer:readscore()							--用户钻石 12
+	//struct.lUserMedal   = pBuffer:readscore()							--用户奖牌 20
+	//struct.lUserScore   = pBuffer:readscore()							--用户金币 28
+	//struct.lRoomCard    = pBuffer:readscore()							--用户房卡 36
+	//财富更新：
+	//struct.cbWealthMask = pBuffer:readbyte()							--财富掩码 1
+	//之后按掩码位（钻石/奖牌/金币/房卡）顺序，每个置位字段一个 SCORE
+}
+
+// HasIngot 是否包含钻石
+func (w *UserWealth) HasIngot() bool { return w != nil && w.Mask&consts.WEALTH_MASK_INGOT != 0 }
+
+// userWealthReply 查询财富回包：用户标识与钻石必有，其余按长度依次可选
+type userWealthReply struct {
+	UserWealth
+	HasMedal, HasScore, HasRoomCard bool
+}
+
+func (m *userWealthReply) Fields() []Field {
+	return []Field{
+		DWord(&m.UserID), Long(&m.Ingot),
+		Optional(&m.HasMedal, Long(&m.Medal)),
+		Optional(&m.HasScore, Long(&m.Score)),
+		Optional(&m.HasRoomCard, Long(&m.RoomCard)),
+	}
+}
+
+func (m *userWealthReply) wealth() *UserWealth {
+	w := m.UserWealth
+	w.Mask = consts.WEALTH_MASK_INGOT
+	for _, f := range []struct {
+		has  bool
+		mask byte
+	}{
+		{m.HasMedal, consts.WEALTH_MASK_MEDAL},
+		{m.HasScore, consts.WEALTH_MASK_SCORE},
+		{m.HasRoomCard, consts.WEALTH_MASK_ROOMCARD},
+	} {
+		if f.has {
+			w.Mask |= f.mask
+		}
+	}
+	return &w
+}
+
+// ParseUserWealth 查询财富回包；至少要有用户标识与钻石，否则返回 nil
+func ParseUserWealth(data []byte) *UserWealth {
+	var m userWealthReply
+	if Decode(data, &m) != nil {
+		return nil
+	}
+	return m.wealth()
+}
+
+// 财富更新中字段的出现顺序
+var wealthMasks = []byte{consts.WEALTH_MASK_INGOT, consts.WEALTH_MASK_MEDAL, consts.WEALTH_MASK_SCORE, consts.WEALTH_MASK_ROOMCARD}
+
+// wealthUpdate 财富更新推送（SUB_GA_WEALTH_UPDATE）
+type wealthUpdate struct {
+	UserWealth
+}
+
+func (m *wealthUpdate) Fields() []Field {
+	fs := []Field{Byte(&m.Mask)}
+	for i, dst := range []*int64{&m.Ingot, &m.Medal, &m.Score, &m.RoomCard} {
+		bit := wealthMasks[i]
+		fs = append(fs, When(func() bool { return m.Mask&bit != 0 }, Long(dst)))
+	}
+	return fs
+}
+
+// ParseWealthUpdate 财富更新推送；字段不完整的部分忽略，掩码同步去掉
+func ParseWealthUpdate(data []byte) *UserWealth {
+	if len(data) < 1 {
+		return nil
+	}
+	var m wealthUpdate
+	if Decode(data, &m) != nil {
+		// 只保留完整的前几个置位字段
+		n, mask := (len(data)-1)/8, byte(0)
+		for _, bit := range wealthMasks {
+			if m.Mask&bit != 0 && n > 0 {
+				mask |= bit
+				n--
+			}
+		}
+		m.Mask = mask
+	}
+	return &m.UserWealth
+}
+
+// GroupProperty 群组（店铺）属性（SUB_GA_GROUP_PROPERTY / SUB_GA_GROUP_UPDATE）
+type GroupProperty struct {
+	GroupID        uint32
+	CreaterID      uint32
+	CreaterGameID  uint32
+	MemberCount    uint16
+	MaxMemberCount uint16
+	Name           string
+	//struct.dwGroupID       = pBuffer:readdword()						--群组标识 4
+	//struct.dwCreaterID     = pBuffer:readdword()						--群主标识 8
+	//struct.dwCreaterGameID = pBuffer:readdword()						--群主游戏ID 12
+	//struct.wMemberCount    = pBuffer:readword()						--成员数量 14
+	//struct.wMaxMemberCount = pBuffer:readword()						--成员上限 16
+	//struct.szGroupName     = pBuffer:readstring(df.LEN_GROUP_NAME)	--群组名称 80
+}
+
+// LenGroupProperty 群组属性的字节数
+const LenGroupProperty = 16 + consts.LEN_GROUP_NAME*2
+
+// Fields 名称可缺失
+func (m *GroupProperty) Fields() []Field {
+	return []Field{
+		DWord(&m.GroupID), DWord(&m.CreaterID), DWord(&m.CreaterGameID),
+		Word(&m.MemberCount), Word(&m.MaxMemberCount),
+		Optional(nil, Str(&m.Name, consts.LEN_GROUP_NAME)),
+	}
+}
+
+// ParseGroupProperty 群组属性/更新推送；不足定长部分返回 nil，名称缺失时为空
+func ParseGroupProperty(data []byte) *GroupProperty {
+	var ret GroupProperty
+	if Decode(data, &ret) != nil {
+		return nil
+	}
+	return &ret
+}
+
+// groupItems 群组列表（SUB_GA_GROUP_ITEM），每项为一个定长群组属性
+type groupItems struct {
+	Items []*GroupProperty
+}
 
-	return &TableRenew{
-		MappedNum:    int(mappedNum),
-		NewMappedNum: int(newMappedNum),
+func (m *groupItems) Fields() []Field {
+	return []Field{Items(&m.Items, LenGroupProperty, (*GroupProperty).Fields)}
+}
+
+func (m *groupItems) valid() []*GroupProperty {
+	var out []*GroupProperty
+	for _, g := range m.Items {
+		if g.GroupID != 0 {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// ParseGroupItems 群组列表，忽略标识为 0 的空项
+func ParseGroupItems(data []byte) []*GroupProperty {
+	var m groupItems
+	_ = Decode(data, &m)
+	return m.valid()
+}
+
+// searchResult 搜索结果（SUB_GA_SEARCH_RESULT），无结果时为空包
+type searchResult struct {
+	Found bool
+	Group GroupProperty
+}
+
+func (m *searchResult) Fields() []Field {
+	return []Field{Optional(&m.Found, m.Group.Fields()...)}
+}
+
+// groupDelete 群组移除推送（SUB_GA_GROUP_DELETE）
+type groupDelete struct {
+	GroupID uint32
+}
+
+func (m *groupDelete) Fields() []Field { return []Field{DWord(&m.GroupID)} }
+
+// ParseGroupDelete 群组移除推送，返回群组标识
+func ParseGroupDelete(data []byte) uint32 {
+	var m groupDelete
+	if Decode(data, &m) != nil {
+		return 0
+	}
+	return m.GroupID
+}
+
+// BattleRecordPage 约战记录分页回包（SUB_GA_BATTLE_RECORD），PageIndex 从 0 开始
+type BattleRecordPage struct {
+	PageIndex  uint16
+	PageCount  uint16
+	TotalCount uint32
+	Records    []*game.BattleInfo
+	//struct.wPageIndex   = pBuffer:readword()								--当前页 2
+	//struct.wPageCount   = pBuffer:readword()								--总页数 4
+	//struct.dwTotalCount = pBuffer:readdword()								--总记录数 8
+	//struct.wRecordCount = pBuffer:readword()								--本页记录数 10
+	//每条记录：
+	//record.dwRoomID     = pBuffer:readdword()								--房间号 4
+	//record.wKindID      = pBuffer:readword()								--游戏类型 6
+	//record.lBaseScore   = pBuffer:readdword()								--游戏底分 10
+	//record.dwCreateTime = pBuffer:readdword()								--开局时间 14
+	//record.wPlayerCount = pBuffer:readword()								--玩家数量 16
+	//之后每个玩家：dwGameID(4) + lScore(SCORE 8)
+}
+
+// LastPage 是否已是最后一页
+func (p *BattleRecordPage) LastPage() bool {
+	return p == nil || int(p.PageIndex)+1 >= int(p.PageCount) || len(p.Records) == 0
+}
+
+func (p *BattleRecordPage) Fields() []Field {
+	return []Field{
+		Word(&p.PageIndex), Word(&p.PageCount), DWord(&p.TotalCount),
+		ListW(&p.Records, battleRecordFields),
+	}
+}
+
+func battleRecordFields(b *game.BattleInfo) []Field {
+	return []Field{
+		DWord(&b.RoomID), Word(&b.KindID), SDWord(&b.BaseScore), DWord(&b.CreateTime),
+		ListW(&b.Players, func(s *game.BattleSettle) []Field {
+			return []Field{DWord(&s.UserGameID), Long(&s.Score)}
+		}),
+	}
+}
+
+// ParseBattleRecordPage 逐条解析，截断的记录及其后内容丢弃；头部不足返回 nil
+func ParseBattleRecordPage(data []byte) *BattleRecordPage {
+	if len(data) < 10 {
+		return nil
 	}
+	page := &BattleRecordPage{}
+	_ = Decode(data, page)
+	return page
 }
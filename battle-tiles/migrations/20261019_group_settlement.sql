@@ -0,0 +1,86 @@
+-- ============================================
+-- 圈主结算单
+-- 日期: 2026-10-19
+-- 说明: 按周期为每个圈生成结算单（局数/输赢流水/运费/分运结转/圈主抽成），
+--       支持 草稿 -> 冻结 -> 已打款 的状态流转
+-- ============================================
+
+-- ============================================
+-- 1. 圈子增加圈主抽成比例
+-- ============================================
+
+ALTER TABLE "public"."game_shop_group" ADD COLUMN IF NOT EXISTS "commission_rate" int4 NOT NULL DEFAULT 0;
+COMMENT ON COLUMN "public"."game_shop_group"."commission_rate" IS '圈主抽成比例（万分比，按圈内运费计）';
+
+-- ============================================
+-- 2. 创建圈主结算单表
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_group_settlement" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "group_id" int4 NOT NULL,
+    "group_name" varchar(64) NOT NULL DEFAULT '',
+    "admin_user_id" int4 NOT NULL,
+    "period_start" timestamptz(6) NOT NULL,
+    "period_end" timestamptz(6) NOT NULL,
+    "games" int8 NOT NULL DEFAULT 0,
+    "player_games" int8 NOT NULL DEFAULT 0,
+    "score_net" int8 NOT NULL DEFAULT 0,
+    "score_flow" int8 NOT NULL DEFAULT 0,
+    "fee_total" int8 NOT NULL DEFAULT 0,
+    "fee_share_net" int8 NOT NULL DEFAULT 0,
+    "commission_rate" int4 NOT NULL DEFAULT 0,
+    "commission_amount" int8 NOT NULL DEFAULT 0,
+    "status" int4 NOT NULL DEFAULT 0,
+    "frozen_at" timestamptz(6),
+    "frozen_by" int4 NOT NULL DEFAULT 0,
+    "paid_at" timestamptz(6),
+    "paid_by" int4 NOT NULL DEFAULT 0,
+    "remark" varchar(255) NOT NULL DEFAULT '',
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_group_settlement" IS '圈主结算单';
+COMMENT ON COLUMN "public"."game_group_settlement"."house_gid" IS '店铺号';
+COMMENT ON COLUMN "public"."game_group_settlement"."group_id" IS '圈子ID';
+COMMENT ON COLUMN "public"."game_group_settlement"."admin_user_id" IS '圈主用户ID';
+COMMENT ON COLUMN "public"."game_group_settlement"."period_start" IS '结算周期开始（含）';
+COMMENT ON COLUMN "public"."game_group_settlement"."period_end" IS '结算周期结束（不含）';
+COMMENT ON COLUMN "public"."game_group_settlement"."games" IS '局数';
+COMMENT ON COLUMN "public"."game_group_settlement"."player_games" IS '人次';
+COMMENT ON COLUMN "public"."game_group_settlement"."score_net" IS '圈内成员输赢合计';
+COMMENT ON COLUMN "public"."game_group_settlement"."score_flow" IS '输赢流水（绝对值合计）';
+COMMENT ON COLUMN "public"."game_group_settlement"."fee_total" IS '圈内产生的运费（分）';
+COMMENT ON COLUMN "public"."game_group_settlement"."fee_share_net" IS '分运结转净额（正数=应付其他圈，负数=应收其他圈）';
+COMMENT ON COLUMN "public"."game_group_settlement"."commission_rate" IS '抽成比例（万分比，生成时快照）';
+COMMENT ON COLUMN "public"."game_group_settlement"."commission_amount" IS '圈主抽成（分）';
+COMMENT ON COLUMN "public"."game_group_settlement"."status" IS '状态：0=草稿 1=已冻结 2=已打款';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_group_settlement_period" ON "public"."game_group_settlement" ("house_gid", "group_id", "period_start", "period_end");
+CREATE INDEX IF NOT EXISTS "idx_group_settlement_admin" ON "public"."game_group_settlement" ("admin_user_id");
+CREATE INDEX IF NOT EXISTS "idx_group_settlement_status" ON "public"."game_group_settlement" ("status");
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('settlement:view', '查看结算单', 'shop', '查看圈主结算单'),
+('settlement:generate', '生成结算单', 'shop', '按周期生成/重算圈主结算单'),
+('settlement:freeze', '冻结结算单', 'shop', '冻结结算单，锁定数据'),
+('settlement:pay', '结算打款', 'shop', '将结算单标记为已打款'),
+('settlement:commission:update', '设置抽成比例', 'shop', '设置圈主抽成比例')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code LIKE 'settlement:%' AND is_deleted = false
+ON CONFLICT DO NOTHING;
+
+-- 店铺管理员可查看自己店铺的结算单
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 2, id FROM "public"."basic_permission" WHERE code IN ('settlement:view') AND is_deleted = false
+ON CONFLICT DO NOTHING;
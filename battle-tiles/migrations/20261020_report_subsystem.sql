@@ -0,0 +1,90 @@
+-- ============================================
+-- 店铺定时报表
+-- 日期: 2026-10-20
+-- 说明: 店铺按 cron 定义报表（经营概况/低余额成员/输分榜/运费汇总/会话异常），
+--       由 asynq 定时任务 report:dispatch 渲染并投递到文件或 webhook，执行历史按店铺保存
+-- ============================================
+
+-- ============================================
+-- 1. 报表定义
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_report_definition" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "name" varchar(64) NOT NULL,
+    "report_type" varchar(32) NOT NULL,
+    "period" varchar(16) NOT NULL DEFAULT 'daily',
+    "schedule" varchar(64) NOT NULL,
+    "params" jsonb NOT NULL DEFAULT '{}',
+    "channel" varchar(16) NOT NULL,
+    "channel_target" varchar(512) NOT NULL DEFAULT '',
+    "enabled" bool NOT NULL DEFAULT true,
+    "last_run_at" timestamptz(6),
+    "next_run_at" timestamptz(6),
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_report_definition" IS '店铺定时报表定义';
+COMMENT ON COLUMN "public"."game_report_definition"."house_gid" IS '店铺号';
+COMMENT ON COLUMN "public"."game_report_definition"."report_type" IS '报表类型：daily_stats/low_balance/top_losers/fee_summary/session_outage';
+COMMENT ON COLUMN "public"."game_report_definition"."period" IS '统计窗口：daily=昨日 weekly=上周';
+COMMENT ON COLUMN "public"."game_report_definition"."schedule" IS 'cron 表达式（5 段）或 @daily 等描述符';
+COMMENT ON COLUMN "public"."game_report_definition"."params" IS '报表参数（threshold/limit/group_id）';
+COMMENT ON COLUMN "public"."game_report_definition"."channel" IS '投递渠道：file/webhook';
+COMMENT ON COLUMN "public"."game_report_definition"."channel_target" IS '投递目标（webhook 地址）';
+COMMENT ON COLUMN "public"."game_report_definition"."next_run_at" IS '下次执行时间';
+
+CREATE INDEX IF NOT EXISTS "idx_report_def_house" ON "public"."game_report_definition" ("house_gid");
+CREATE INDEX IF NOT EXISTS "idx_report_def_enabled" ON "public"."game_report_definition" ("enabled");
+
+-- ============================================
+-- 2. 执行历史
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_report_run" (
+    "id" SERIAL PRIMARY KEY,
+    "definition_id" int4 NOT NULL,
+    "house_gid" int4 NOT NULL,
+    "report_type" varchar(32) NOT NULL,
+    "trigger" varchar(16) NOT NULL DEFAULT 'schedule',
+    "status" varchar(16) NOT NULL,
+    "range_start" timestamptz(6) NOT NULL,
+    "range_end" timestamptz(6) NOT NULL,
+    "rows" int4 NOT NULL DEFAULT 0,
+    "channel" varchar(16) NOT NULL,
+    "delivery_ref" varchar(512) NOT NULL DEFAULT '',
+    "error_message" text NOT NULL DEFAULT '',
+    "started_at" timestamptz(6) NOT NULL,
+    "finished_at" timestamptz(6)
+);
+
+COMMENT ON TABLE "public"."game_report_run" IS '店铺定时报表执行历史';
+COMMENT ON COLUMN "public"."game_report_run"."trigger" IS '触发方式：schedule=定时 manual=手动';
+COMMENT ON COLUMN "public"."game_report_run"."status" IS '状态：running/success/failed';
+COMMENT ON COLUMN "public"."game_report_run"."rows" IS '明细行数';
+COMMENT ON COLUMN "public"."game_report_run"."delivery_ref" IS '投递结果（文件路径/HTTP 状态）';
+
+CREATE INDEX IF NOT EXISTS "idx_report_run_def" ON "public"."game_report_run" ("definition_id");
+CREATE INDEX IF NOT EXISTS "idx_report_run_house" ON "public"."game_report_run" ("house_gid");
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('report:view', '查看定时报表', 'shop', '查看报表定义与执行历史'),
+('report:manage', '管理定时报表', 'shop', '新建/修改/删除报表定义，手动执行报表')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code LIKE 'report:%' AND is_deleted = false
+ON CONFLICT DO NOTHING;
+
+-- 店铺管理员可管理自己店铺的报表
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 2, id FROM "public"."basic_permission" WHERE code IN ('report:view', 'report:manage') AND is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,77 @@
+-- ============================================
+-- 店铺作用域 RBAC
+-- 日期: 2026-10-21
+-- 说明: 角色可按店铺（可选：圈）授予，同一用户可在店铺 A 是运营、在店铺 B 是管理员。
+--       RequireHousePerm 中间件只认作用域授权，全局角色授予的店铺权限不再跨店生效。
+-- ============================================
+
+-- ============================================
+-- 1. 作用域角色授权表
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."basic_user_scope_role" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" int4 NOT NULL,
+    "role_id" int4 NOT NULL,
+    "house_gid" int4 NOT NULL,
+    "group_id" int4 NOT NULL DEFAULT 0,
+    "granted_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."basic_user_scope_role" IS '店铺/圈作用域角色授权';
+COMMENT ON COLUMN "public"."basic_user_scope_role"."user_id" IS '用户ID';
+COMMENT ON COLUMN "public"."basic_user_scope_role"."role_id" IS '角色ID（basic_role.id）';
+COMMENT ON COLUMN "public"."basic_user_scope_role"."house_gid" IS '店铺号';
+COMMENT ON COLUMN "public"."basic_user_scope_role"."group_id" IS '圈子ID（0=整个店铺）';
+COMMENT ON COLUMN "public"."basic_user_scope_role"."granted_by" IS '授权人';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_user_scope_role" ON "public"."basic_user_scope_role" ("user_id", "role_id", "house_gid", "group_id");
+CREATE INDEX IF NOT EXISTS "idx_user_scope_role_house" ON "public"."basic_user_scope_role" ("house_gid");
+
+-- ============================================
+-- 2. 店铺运营角色
+-- ============================================
+
+INSERT INTO "public"."basic_role" ("code", "name", "parent_id", "remark", "first_letter", "pinyin_code", "enable", "is_deleted")
+SELECT 'shop_operator', '店铺运营', -1, '店铺内成员查看/踢人/下线', 'D', 'dianpuyunying', true, false
+WHERE NOT EXISTS (SELECT 1 FROM "public"."basic_role" WHERE code = 'shop_operator' AND is_deleted = false);
+
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT r.id, p.id
+FROM "public"."basic_role" r, "public"."basic_permission" p
+WHERE r.code = 'shop_operator' AND r.is_deleted = false
+  AND p.code IN ('shop:member:view', 'shop:member:kick', 'shop:member:logout') AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('role:scope:view', '查看店铺角色', 'system', '查看店铺内的作用域角色授权'),
+('role:scope:assign', '授予店铺角色', 'system', '在店铺/圈范围内授予或撤销角色')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code LIKE 'role:scope:%' AND is_deleted = false
+ON CONFLICT DO NOTHING;
+
+-- 店铺管理员可在自己店铺内授予运营
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 2, id FROM "public"."basic_permission" WHERE code IN ('role:scope:view', 'role:scope:assign') AND is_deleted = false
+ON CONFLICT DO NOTHING;
+
+-- ============================================
+-- 4. 按现有店铺管理员回填作用域授权
+-- ============================================
+
+INSERT INTO "public"."basic_user_scope_role" ("user_id", "role_id", "house_gid", "group_id")
+SELECT a.user_id, r.id, a.house_gid, 0
+FROM "public"."game_shop_admin" a
+JOIN "public"."basic_role" r
+  ON r.is_deleted = false
+ AND r.code = CASE WHEN a.role = 'operator' THEN 'shop_operator' ELSE 'shop_admin' END
+WHERE a.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,66 @@
+-- ============================================
+-- 管理操作审计日志
+-- 日期: 2026-10-22
+-- 说明: 所有写操作（非 GET）由 AuditTrail 中间件统一记录操作人、店铺、操作码、对象、变更前后及结果；
+--       默认保留 180 天，由 asynq 任务 audit:purge 每日清理。
+-- ============================================
+
+-- ============================================
+-- 1. 审计日志表
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."basic_audit_log" (
+    "id" SERIAL PRIMARY KEY,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "platform" varchar(64) NOT NULL DEFAULT '',
+    "actor_id" int4 NOT NULL DEFAULT 0,
+    "actor_name" varchar(64) NOT NULL DEFAULT '',
+    "house_gid" int4 NOT NULL DEFAULT 0,
+    "action" varchar(100) NOT NULL,
+    "method" varchar(10) NOT NULL DEFAULT '',
+    "path" varchar(255) NOT NULL DEFAULT '',
+    "target_type" varchar(64) NOT NULL DEFAULT '',
+    "target_ids" varchar(512) NOT NULL DEFAULT '',
+    "before_json" jsonb,
+    "after_json" jsonb,
+    "reason" varchar(255) NOT NULL DEFAULT '',
+    "client_ip" varchar(64) NOT NULL DEFAULT '',
+    "user_agent" varchar(255) NOT NULL DEFAULT '',
+    "success" bool NOT NULL DEFAULT false,
+    "result_code" int4 NOT NULL DEFAULT 0,
+    "result_msg" varchar(255) NOT NULL DEFAULT '',
+    "latency_ms" int8 NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE "public"."basic_audit_log" IS '管理操作审计日志';
+COMMENT ON COLUMN "public"."basic_audit_log"."platform" IS '平台';
+COMMENT ON COLUMN "public"."basic_audit_log"."actor_id" IS '操作人';
+COMMENT ON COLUMN "public"."basic_audit_log"."actor_name" IS '操作人用户名';
+COMMENT ON COLUMN "public"."basic_audit_log"."house_gid" IS '店铺号';
+COMMENT ON COLUMN "public"."basic_audit_log"."action" IS '操作码（与权限码一致）';
+COMMENT ON COLUMN "public"."basic_audit_log"."target_type" IS '对象类型';
+COMMENT ON COLUMN "public"."basic_audit_log"."target_ids" IS '对象ID（逗号分隔）';
+COMMENT ON COLUMN "public"."basic_audit_log"."before_json" IS '变更前';
+COMMENT ON COLUMN "public"."basic_audit_log"."after_json" IS '变更后';
+COMMENT ON COLUMN "public"."basic_audit_log"."reason" IS '原因/备注';
+COMMENT ON COLUMN "public"."basic_audit_log"."success" IS '是否成功';
+COMMENT ON COLUMN "public"."basic_audit_log"."result_code" IS '业务返回码';
+COMMENT ON COLUMN "public"."basic_audit_log"."latency_ms" IS '耗时（毫秒）';
+
+CREATE INDEX IF NOT EXISTS "idx_audit_log_created" ON "public"."basic_audit_log" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_log_actor" ON "public"."basic_audit_log" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_log_house" ON "public"."basic_audit_log" ("house_gid");
+CREATE INDEX IF NOT EXISTS "idx_audit_log_action" ON "public"."basic_audit_log" ("action");
+
+-- ============================================
+-- 2. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('audit:view', '查看审计日志', 'system', '按操作人/店铺/操作/对象/时间检索审计日志')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code = 'audit:view' AND is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,68 @@
+-- ============================================
+-- 登录加固：登录记录 + TOTP 二次验证
+-- 日期: 2026-10-23
+-- 说明: 失败计数与锁定存 Redis（按用户名 5 次 / 按 IP 20 次，30 分钟窗口，锁定 1 分钟起翻倍、封顶 1 小时）；
+--       登录尝试（含失败、锁定）写入 basic_login_history；开启 TOTP 的账号登录需提交 otp_code。
+-- ============================================
+
+-- ============================================
+-- 1. 登录记录
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."basic_login_history" (
+    "id" SERIAL PRIMARY KEY,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "user_id" int4 NOT NULL DEFAULT 0,
+    "username" varchar(50) NOT NULL DEFAULT '',
+    "client_ip" varchar(64) NOT NULL DEFAULT '',
+    "user_agent" varchar(255) NOT NULL DEFAULT '',
+    "success" bool NOT NULL DEFAULT false,
+    "fail_reason" varchar(64) NOT NULL DEFAULT '',
+    "used_totp" bool NOT NULL DEFAULT false
+);
+
+COMMENT ON TABLE "public"."basic_login_history" IS '登录记录';
+COMMENT ON COLUMN "public"."basic_login_history"."user_id" IS '用户ID（用户不存在时为0）';
+COMMENT ON COLUMN "public"."basic_login_history"."username" IS '登录名';
+COMMENT ON COLUMN "public"."basic_login_history"."client_ip" IS '客户端IP';
+COMMENT ON COLUMN "public"."basic_login_history"."success" IS '是否成功';
+COMMENT ON COLUMN "public"."basic_login_history"."fail_reason" IS '失败原因：no_user/bad_password/bad_totp/locked';
+COMMENT ON COLUMN "public"."basic_login_history"."used_totp" IS '是否经过二次验证';
+
+CREATE INDEX IF NOT EXISTS "idx_login_history_created" ON "public"."basic_login_history" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_login_history_user" ON "public"."basic_login_history" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_login_history_username" ON "public"."basic_login_history" ("username");
+CREATE INDEX IF NOT EXISTS "idx_login_history_ip" ON "public"."basic_login_history" ("client_ip");
+
+-- ============================================
+-- 2. TOTP 配置
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."basic_user_totp" (
+    "user_id" int4 PRIMARY KEY,
+    "secret" varchar(64) NOT NULL,
+    "enabled" bool NOT NULL DEFAULT false,
+    "last_step" int8 NOT NULL DEFAULT 0,
+    "enabled_at" timestamptz(6),
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."basic_user_totp" IS '用户 TOTP 二次验证';
+COMMENT ON COLUMN "public"."basic_user_totp"."secret" IS 'base32 密钥';
+COMMENT ON COLUMN "public"."basic_user_totp"."enabled" IS '是否已启用（生成密钥后需提交动态码确认）';
+COMMENT ON COLUMN "public"."basic_user_totp"."last_step" IS '最近一次使用的时间步（防重放）';
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('login:history:view', '查看登录记录', 'system', '检索所有用户的登录尝试'),
+('user:security:manage', '账号安全管理', 'system', '解除登录锁定、重置用户二次验证')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code IN ('login:history:view', 'user:security:manage') AND is_deleted = false
+ON CONFLICT DO NOTHING;
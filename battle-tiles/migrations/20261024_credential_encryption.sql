@@ -0,0 +1,24 @@
+-- ============================================
+-- 游戏账号密码落库加密
+-- 日期: 2026-10-24
+-- 说明: pwd_md5 改存信封加密后的密文（base64），pwd_key_ver 记录加密所用的主密钥版本，0 表示历史明文。
+--       主密钥只在配置 global.crypto 中，SQL 不做加密：执行本脚本后运行
+--       go run ./cmd/credential-rotate -conf ./configs/config.yaml
+--       将存量明文按 active_version 加密；之后每次轮换主密钥同样执行该命令。
+-- ============================================
+
+ALTER TABLE game_ctrl_account
+    ALTER COLUMN pwd_md5 TYPE VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS pwd_key_ver INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE game_account
+    ALTER COLUMN pwd_md5 TYPE VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS pwd_key_ver INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_game_ctrl_account_pwd_key_ver ON game_ctrl_account (pwd_key_ver);
+CREATE INDEX IF NOT EXISTS idx_game_account_pwd_key_ver ON game_account (pwd_key_ver);
+
+COMMENT ON COLUMN game_ctrl_account.pwd_md5 IS '加密后的密码 MD5';
+COMMENT ON COLUMN game_ctrl_account.pwd_key_ver IS '加密主密钥版本，0 为未加密';
+COMMENT ON COLUMN game_account.pwd_md5 IS '加密后的密码 MD5';
+COMMENT ON COLUMN game_account.pwd_key_ver IS '加密主密钥版本，0 为未加密';
@@ -0,0 +1,42 @@
+-- ============================================
+-- 服务账号 API Key
+-- 日期: 2026-10-25
+-- 说明: 服务账号是 role=service 且没有密码的 basic_user，只能通过 X-API-Key 调用接口；
+--       Key 明文只在签发时返回一次，库里保存 sha256；权限码与限定店铺保存在 Key 上。
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."basic_api_key" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" int4 NOT NULL,
+    "name" varchar(64) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "perms" jsonb NOT NULL DEFAULT '[]'::jsonb,
+    "house_gids" jsonb NOT NULL DEFAULT '[]'::jsonb,
+    "expires_at" timestamptz(6),
+    "last_used_at" timestamptz(6),
+    "last_used_ip" varchar(64) NOT NULL DEFAULT '',
+    "revoked_at" timestamptz(6),
+    "revoked_by" int4 NOT NULL DEFAULT 0,
+    "created_by" int4 NOT NULL,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_basic_api_key_prefix" ON "public"."basic_api_key" ("prefix");
+CREATE INDEX IF NOT EXISTS "idx_basic_api_key_user" ON "public"."basic_api_key" ("user_id");
+
+COMMENT ON TABLE "public"."basic_api_key" IS '服务账号 API Key';
+COMMENT ON COLUMN "public"."basic_api_key"."prefix" IS '公开前缀，用于定位 Key';
+COMMENT ON COLUMN "public"."basic_api_key"."key_hash" IS 'sha256(完整 Key)';
+COMMENT ON COLUMN "public"."basic_api_key"."perms" IS '权限码';
+COMMENT ON COLUMN "public"."basic_api_key"."house_gids" IS '限定店铺，空为不限';
+
+-- 权限
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('apikey:manage', '管理 API Key', 'system', '创建服务账号、签发与吊销 API Key')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code = 'apikey:manage' AND is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,82 @@
+-- ============================================
+-- 店铺 webhook
+-- 日期: 2026-10-26
+-- 说明: 店铺注册回调地址并订阅业务事件（上下分、禁分、申请、桌台解散、会话下线、战绩入库、低余额），
+--       事件按订阅生成投递记录，经 asynq 任务 webhook:deliver 以 HMAC-SHA256 签名 POST，失败退避重试；
+--       签名密钥用 global.crypto 主密钥加密保存，随 credential-rotate 一起轮换
+-- ============================================
+
+-- ============================================
+-- 1. 回调地址
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_webhook_endpoint" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "name" varchar(64) NOT NULL DEFAULT '',
+    "url" varchar(512) NOT NULL,
+    "secret" varchar(255) NOT NULL,
+    "secret_key_ver" int4 NOT NULL DEFAULT 0,
+    "events" jsonb NOT NULL DEFAULT '[]',
+    "low_balance_threshold" int4 NOT NULL DEFAULT 0,
+    "enabled" bool NOT NULL DEFAULT true,
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_webhook_endpoint" IS '店铺 webhook 回调地址';
+COMMENT ON COLUMN "public"."game_webhook_endpoint"."house_gid" IS '店铺号';
+COMMENT ON COLUMN "public"."game_webhook_endpoint"."secret" IS '签名密钥（主密钥加密）';
+COMMENT ON COLUMN "public"."game_webhook_endpoint"."secret_key_ver" IS '加密签名密钥所用的主密钥版本';
+COMMENT ON COLUMN "public"."game_webhook_endpoint"."events" IS '订阅的事件类型列表';
+COMMENT ON COLUMN "public"."game_webhook_endpoint"."low_balance_threshold" IS 'member.low_balance 阈值：下分后余额从高于该值跌到不高于该值时触发';
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_ep_house" ON "public"."game_webhook_endpoint" ("house_gid");
+CREATE INDEX IF NOT EXISTS "idx_webhook_ep_key_ver" ON "public"."game_webhook_endpoint" ("secret_key_ver");
+
+-- ============================================
+-- 2. 投递记录
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_webhook_delivery" (
+    "id" SERIAL PRIMARY KEY,
+    "endpoint_id" int4 NOT NULL,
+    "house_gid" int4 NOT NULL,
+    "event_id" varchar(64) NOT NULL,
+    "event_type" varchar(32) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "attempts" int4 NOT NULL DEFAULT 0,
+    "last_status_code" int4 NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "last_attempt_at" timestamptz(6),
+    "delivered_at" timestamptz(6),
+    "created_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_webhook_delivery" IS '店铺 webhook 投递记录';
+COMMENT ON COLUMN "public"."game_webhook_delivery"."event_id" IS '事件ID（X-Webhook-Delivery），重投不变，接收方可据此去重';
+COMMENT ON COLUMN "public"."game_webhook_delivery"."payload" IS '实际 POST 的请求体';
+COMMENT ON COLUMN "public"."game_webhook_delivery"."status" IS '状态：pending/success/failed';
+COMMENT ON COLUMN "public"."game_webhook_delivery"."attempts" IS '已投递次数';
+COMMENT ON COLUMN "public"."game_webhook_delivery"."last_status_code" IS '最近一次 HTTP 响应码（0 表示未收到响应）';
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_dlv_endpoint" ON "public"."game_webhook_delivery" ("endpoint_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_dlv_house" ON "public"."game_webhook_delivery" ("house_gid", "id" DESC);
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('webhook:view', '查看店铺 webhook', 'shop', '查看回调地址与投递记录'),
+('webhook:manage', '管理店铺 webhook', 'shop', '新建/修改/删除回调地址，重置密钥，测试与手动重投')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员与店铺管理员（按店铺作用域生效）
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT r.role_id, p.id FROM "public"."basic_permission" p
+CROSS JOIN (VALUES (1), (2)) AS r(role_id)
+WHERE p.code IN ('webhook:view', 'webhook:manage') AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
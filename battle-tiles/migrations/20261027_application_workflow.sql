@@ -0,0 +1,107 @@
+-- ============================================
+-- 店铺申请处理 v2
+-- 日期: 2026-10-27
+-- 说明: 按店铺配置自动处理规则（推荐人已是成员自动通过 / 游戏ID名单自动拒绝等），
+--       待审申请超过 application_expire_hours 自动拒绝（游戏内申请由会话巡检处理，平台申请由 asynq 任务 application:expire 处理），
+--       支持批量通过/拒绝；手动、规则、超时三种决策都写入 game_shop_application_log，规则决策记录命中的规则
+-- ============================================
+
+-- ============================================
+-- 1. 自动处理规则
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_application_rule" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "name" varchar(64) NOT NULL,
+    "priority" int4 NOT NULL DEFAULT 100,
+    "condition" varchar(32) NOT NULL,
+    "action" varchar(16) NOT NULL,
+    "params" jsonb NOT NULL DEFAULT '{}',
+    "enabled" bool NOT NULL DEFAULT true,
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_application_rule" IS '店铺申请自动处理规则';
+COMMENT ON COLUMN "public"."game_application_rule"."priority" IS '优先级，升序匹配，命中第一条即执行';
+COMMENT ON COLUMN "public"."game_application_rule"."condition" IS '条件：recommender_member/blocklist/any';
+COMMENT ON COLUMN "public"."game_application_rule"."action" IS '动作：approve/reject';
+COMMENT ON COLUMN "public"."game_application_rule"."params" IS '参数：game_ids 名单，apply_types/sources 限定范围';
+
+CREATE INDEX IF NOT EXISTS "idx_app_rule_house" ON "public"."game_application_rule" ("house_gid");
+
+-- ============================================
+-- 2. 处理记录
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_shop_application_log" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "applier_gid" int4 NOT NULL DEFAULT 0,
+    "applier_gname" varchar(64) NOT NULL DEFAULT '',
+    "action" int4 NOT NULL,
+    "admin_user_id" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "public"."game_shop_application_log"
+    ADD COLUMN IF NOT EXISTS "source" varchar(16) NOT NULL DEFAULT 'game',
+    ADD COLUMN IF NOT EXISTS "application_id" int4 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "apply_type" int4 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "applier_user_id" int4 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "recommender" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "decider" varchar(16) NOT NULL DEFAULT 'manual',
+    ADD COLUMN IF NOT EXISTS "rule_id" int4,
+    ADD COLUMN IF NOT EXISTS "rule_name" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "reason" varchar(255) NOT NULL DEFAULT '';
+
+COMMENT ON TABLE "public"."game_shop_application_log" IS '店铺申请处理记录';
+COMMENT ON COLUMN "public"."game_shop_application_log"."source" IS '来源：game 游戏内申请 / platform 平台申请';
+COMMENT ON COLUMN "public"."game_shop_application_log"."application_id" IS '游戏消息ID / 平台申请ID';
+COMMENT ON COLUMN "public"."game_shop_application_log"."action" IS '结果：1通过 2拒绝 3超时';
+COMMENT ON COLUMN "public"."game_shop_application_log"."decider" IS '决策方式：manual/rule/expiry';
+COMMENT ON COLUMN "public"."game_shop_application_log"."rule_id" IS '命中的自动规则（decider=rule）';
+
+CREATE INDEX IF NOT EXISTS "idx_app_log_house" ON "public"."game_shop_application_log" ("house_gid", "id" DESC);
+-- 同一申请只保留一条处理记录：先写记录再回发协议/落状态，重复推送或并发处理只有一方成功
+DELETE FROM "public"."game_shop_application_log" a
+USING "public"."game_shop_application_log" b
+WHERE a.application_id > 0
+  AND a.house_gid = b.house_gid AND a.source = b.source AND a.application_id = b.application_id
+  AND a.id > b.id;
+
+DROP INDEX IF EXISTS "public"."idx_app_log_application";
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_app_log_application" ON "public"."game_shop_application_log" ("house_gid", "source", "application_id")
+WHERE application_id > 0;
+
+-- ============================================
+-- 3. 平台申请推荐人、店铺过期设置
+-- ============================================
+
+ALTER TABLE "public"."game_user_application"
+    ADD COLUMN IF NOT EXISTS "recommender" varchar(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "public"."game_user_application"."recommender" IS '推荐人游戏ID（可选）';
+COMMENT ON COLUMN "public"."game_user_application"."status" IS '0待审,1通过,2拒绝,3移除,4超时';
+
+ALTER TABLE "public"."game_house_settings"
+    ADD COLUMN IF NOT EXISTS "application_expire_hours" int4 NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "public"."game_house_settings"."application_expire_hours" IS '待处理申请过期小时数，0 不过期';
+
+-- ============================================
+-- 4. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('shop:applications:rules', '管理申请自动处理规则', 'shop', '新建/修改/删除自动处理规则，设置申请过期时间')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员与店铺管理员（按店铺作用域生效）
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT r.role_id, p.id FROM "public"."basic_permission" p
+CROSS JOIN (VALUES (1), (2)) AS r(role_id)
+WHERE p.code = 'shop:applications:rules' AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,60 @@
+-- ============================================
+-- 成员入店流程
+-- 日期: 2026-10-28
+-- 说明: 入圈审批通过后按固定顺序执行：建 game_member → 写圈子关系/已通过入圈记录 → 开钱包（店铺默认下限 + 入店赠送）→ 写成员规则默认值，
+--       每步完成即落库（step），失败记录 last_error 停在断点，可重试；撤销时按相反顺序回退本流程产生的数据
+-- ============================================
+
+-- ============================================
+-- 1. 入店流程
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_member_onboarding" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "source" varchar(16) NOT NULL DEFAULT 'manual',
+    "application_id" int4 NOT NULL DEFAULT 0,
+    "applicant_user_id" int4 NOT NULL DEFAULT 0,
+    "game_id" int4 NOT NULL DEFAULT 0,
+    "game_name" varchar(64) NOT NULL DEFAULT '',
+    "recommender" varchar(64) NOT NULL DEFAULT '',
+    "group_id" int4,
+    "member_id" int4 NOT NULL DEFAULT 0,
+    "member_created" bool NOT NULL DEFAULT false,
+    "group_linked" bool NOT NULL DEFAULT false,
+    "welcome_credit" int4 NOT NULL DEFAULT 0,
+    "step" varchar(32) NOT NULL DEFAULT 'pending',
+    "status" varchar(16) NOT NULL DEFAULT 'running',
+    "attempts" int4 NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "completed_at" timestamptz(6)
+);
+
+COMMENT ON TABLE "public"."game_member_onboarding" IS '成员入店流程';
+COMMENT ON COLUMN "public"."game_member_onboarding"."source" IS '来源：game 游戏内申请 / platform 平台申请 / manual 手动发起';
+COMMENT ON COLUMN "public"."game_member_onboarding"."member_created" IS '成员由本流程创建（撤销时删除）';
+COMMENT ON COLUMN "public"."game_member_onboarding"."group_linked" IS '圈子成员关系由本流程写入（撤销时移除）';
+COMMENT ON COLUMN "public"."game_member_onboarding"."welcome_credit" IS '已发放的入店赠送（分），业务号 onboard-{id}';
+COMMENT ON COLUMN "public"."game_member_onboarding"."step" IS '最后完成的步骤：pending/member_created/group_assigned/wallet_ready/rules_ready';
+COMMENT ON COLUMN "public"."game_member_onboarding"."status" IS '状态：running/failed/completed/compensated';
+
+CREATE INDEX IF NOT EXISTS "idx_onboarding_house" ON "public"."game_member_onboarding" ("house_gid", "id" DESC);
+CREATE INDEX IF NOT EXISTS "idx_onboarding_status" ON "public"."game_member_onboarding" ("status");
+-- 同一申请只建一条流程：重复推送或并发审批时只有一方写入，另一方复用已有流程
+DROP INDEX IF EXISTS "public"."idx_onboarding_application";
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_onboarding_application" ON "public"."game_member_onboarding" ("house_gid", "source", "application_id")
+WHERE application_id > 0;
+
+-- ============================================
+-- 2. 店铺新成员默认值
+-- ============================================
+
+ALTER TABLE "public"."game_house_settings"
+    ADD COLUMN IF NOT EXISTS "onboard_limit_min" int4 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "onboard_welcome_credit" int4 NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "public"."game_house_settings"."onboard_limit_min" IS '新成员钱包默认下限（分）';
+COMMENT ON COLUMN "public"."game_house_settings"."onboard_welcome_credit" IS '新成员入店赠送（分），0 不赠送';
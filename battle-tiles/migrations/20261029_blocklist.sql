@@ -0,0 +1,44 @@
+-- ============================================
+-- 平台黑名单
+-- 日期: 2026-10-29
+-- 说明: 跨店铺生效的黑名单（游戏ID/平台用户/设备/IP），记录原因、过期时间与上报店铺；
+--       命中后：申请自动拒绝（decider=blocklist）、禁止拉入圈子、禁止绑定游戏账号、游戏ID落座即踢出店铺
+-- ============================================
+
+-- ============================================
+-- 1. 黑名单
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_blocklist" (
+    "id" SERIAL PRIMARY KEY,
+    "kind" varchar(16) NOT NULL,
+    "value" varchar(128) NOT NULL,
+    "reason" varchar(255) NOT NULL DEFAULT '',
+    "reported_house_gid" int4 NOT NULL DEFAULT 0,
+    "expire_at" timestamptz(6),
+    "created_by" int4 NOT NULL DEFAULT 0,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_blocklist" IS '平台黑名单';
+COMMENT ON COLUMN "public"."game_blocklist"."kind" IS '标识类型：game_id 游戏ID / user 平台用户ID / device 设备标识 / ip';
+COMMENT ON COLUMN "public"."game_blocklist"."reported_house_gid" IS '上报店铺，0 为平台直接添加';
+COMMENT ON COLUMN "public"."game_blocklist"."expire_at" IS '过期时间，空为永久';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_blocklist_kind_value" ON "public"."game_blocklist" ("kind", "value");
+CREATE INDEX IF NOT EXISTS "idx_blocklist_house" ON "public"."game_blocklist" ("reported_house_gid");
+
+-- ============================================
+-- 2. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('blocklist:view', '查看平台黑名单', 'system', '查看全平台黑名单'),
+('blocklist:manage', '管理平台黑名单', 'system', '加入/移出平台黑名单')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+-- 超级管理员拥有所有权限
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, id FROM "public"."basic_permission" WHERE code IN ('blocklist:view', 'blocklist:manage') AND is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,58 @@
+-- ============================================
+-- 店铺玩法管理
+-- 日期: 2026-10-30
+-- 说明: 中控会话解析 SUB_GA_BATTLE_CONFIG 及玩法增删改推送，落库为店铺玩法快照；
+--       游戏端删除的玩法只标记 removed；战绩入库时按 kind_id + base_score 唯一匹配玩法写入 config_id
+-- ============================================
+
+-- ============================================
+-- 1. 店铺玩法
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_battle_config" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "config_id" int4 NOT NULL,
+    "kind_id" int4 NOT NULL,
+    "base_score" int4 NOT NULL DEFAULT 0,
+    "play_count" int4 NOT NULL DEFAULT 0,
+    "player_count" int4 NOT NULL DEFAULT 0,
+    "name" varchar(64) NOT NULL DEFAULT '',
+    "removed" bool NOT NULL DEFAULT false,
+    "removed_at" timestamptz(6),
+    "created_at" timestamptz(6) NOT NULL DEFAULT now(),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_battle_config" IS '店铺玩法快照';
+COMMENT ON COLUMN "public"."game_battle_config"."config_id" IS '游戏端玩法标识';
+COMMENT ON COLUMN "public"."game_battle_config"."play_count" IS '局数';
+COMMENT ON COLUMN "public"."game_battle_config"."player_count" IS '人数';
+COMMENT ON COLUMN "public"."game_battle_config"."removed" IS '游戏端已删除（保留给历史战绩关联）';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_battle_config_house" ON "public"."game_battle_config" ("house_gid", "config_id");
+
+-- ============================================
+-- 2. 战绩关联玩法
+-- ============================================
+
+ALTER TABLE "public"."game_battle_record"
+    ADD COLUMN IF NOT EXISTS "config_id" int4;
+
+COMMENT ON COLUMN "public"."game_battle_record"."config_id" IS '对局所用玩法（game_battle_config.config_id），无法唯一确定时为空';
+
+CREATE INDEX IF NOT EXISTS "idx_battle_config_id" ON "public"."game_battle_record" ("house_gid", "config_id");
+
+-- ============================================
+-- 3. 权限
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('shop:table:config', '管理玩法', 'shop', '通过中控会话添加/修改/删除店铺玩法')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT r.role_id, p.id FROM "public"."basic_permission" p
+CROSS JOIN (VALUES (1), (2)) AS r(role_id)
+WHERE p.code = 'shop:table:config' AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
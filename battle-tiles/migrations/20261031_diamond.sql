@@ -0,0 +1,34 @@
+-- ============================================
+-- 中控钻石余额跟踪与告警
+-- 日期: 2026-10-31
+-- 说明: 会话解析查询财富回包（SUB_GP_USER_WEALTH）与财富更新推送（SUB_GA_WEALTH_UPDATE），
+--       按店铺+中控账号记录钻石余额序列；余额跌到店铺告警线以下时发布 house.diamond_low
+-- ============================================
+
+-- ============================================
+-- 1. 钻石余额序列
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_diamond_snapshot" (
+    "id" SERIAL PRIMARY KEY,
+    "house_gid" int4 NOT NULL,
+    "ctrl_account_id" int4 NOT NULL,
+    "balance" int8 NOT NULL,
+    "source" varchar(16) NOT NULL,
+    "created_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_diamond_snapshot" IS '中控账号钻石余额序列（余额不变时最多每 10 分钟记录一次）';
+COMMENT ON COLUMN "public"."game_diamond_snapshot"."ctrl_account_id" IS '中控账号（game_ctrl_account.id）';
+COMMENT ON COLUMN "public"."game_diamond_snapshot"."source" IS 'query: 主动查询回包; push: 游戏端财富更新推送';
+
+CREATE INDEX IF NOT EXISTS "idx_diamond_house_at" ON "public"."game_diamond_snapshot" ("house_gid", "created_at");
+
+-- ============================================
+-- 2. 店铺告警线
+-- ============================================
+
+ALTER TABLE "public"."game_house_settings"
+    ADD COLUMN IF NOT EXISTS "diamond_alert_threshold" int8 NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "public"."game_house_settings"."diamond_alert_threshold" IS '中控钻石余额告警线，0 不告警';
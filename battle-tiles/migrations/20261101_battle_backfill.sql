@@ -0,0 +1,36 @@
+-- ============================================
+-- 战绩分页回填与同步高水位
+-- 日期: 2026-11-01
+-- 说明: 按店铺记录已入库的最新一局 (create_time, room_id) 与增量同步已覆盖到的时间；
+--       增量同步发现覆盖不衔接时在 game_sync_log 记缺口，管理员可按日期调用 /battle-sync/backfill 回填
+-- ============================================
+
+-- ============================================
+-- 1. 高水位表
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS "public"."game_battle_watermark" (
+    "house_gid" int4 NOT NULL PRIMARY KEY,
+    "last_create_time" int8 NOT NULL DEFAULT 0,
+    "last_room_id" int4 NOT NULL DEFAULT 0,
+    "covered_until" timestamptz(6),
+    "updated_at" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE "public"."game_battle_watermark" IS '店铺战绩同步高水位（只前进不后退）';
+COMMENT ON COLUMN "public"."game_battle_watermark"."last_create_time" IS '已入库最新一局的时间（秒）';
+COMMENT ON COLUMN "public"."game_battle_watermark"."last_room_id" IS '同一秒内按房间号取大';
+COMMENT ON COLUMN "public"."game_battle_watermark"."covered_until" IS '此前的战绩已由增量同步完整拉取';
+
+-- ============================================
+-- 2. 回填权限（超级管理员）
+-- ============================================
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('battles:backfill', '回填战绩', 'battle', '按日期分页回填店铺战绩、查看同步高水位')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT 1, p.id FROM "public"."basic_permission" p
+WHERE p.code = 'battles:backfill' AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
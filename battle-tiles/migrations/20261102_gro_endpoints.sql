@@ -0,0 +1,15 @@
+-- ============================================
+-- GroService 接口地址与私钥按平台覆盖
+-- 日期: 2026-11-02
+-- 说明: 默认值在配置 global.game.gro；base_platform 中非空的字段覆盖对应平台（修改后 1 分钟内生效）。
+--       base_platform 在云平台库（cloud），本脚本在该库执行
+-- ============================================
+
+ALTER TABLE "public"."base_platform"
+    ADD COLUMN IF NOT EXISTS "gro_battle_url" varchar(255),
+    ADD COLUMN IF NOT EXISTS "gro_query_url" varchar(255),
+    ADD COLUMN IF NOT EXISTS "gro_private_key" varchar(64);
+
+COMMENT ON COLUMN "public"."base_platform"."gro_battle_url" IS '战绩同步接口地址（GroService.ashx），为空使用配置';
+COMMENT ON COLUMN "public"."base_platform"."gro_query_url" IS '战绩查询接口地址，为空使用配置';
+COMMENT ON COLUMN "public"."base_platform"."gro_private_key" IS 'GroService token 签名私钥，为空使用配置';
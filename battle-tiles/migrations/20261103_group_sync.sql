@@ -0,0 +1,25 @@
+-- ============================================
+-- 圈子与游戏端群组同步
+-- 日期: 2026-11-03
+-- 说明: 本地圈子记录对应的游戏端群组标识；通过中控会话创建/改名/删除群组，
+--       并可把中控账号所在的群组导入为本地圈子（/groups/game/reconcile 出对账报告）
+-- ============================================
+
+ALTER TABLE "public"."game_shop_group"
+    ADD COLUMN IF NOT EXISTS "game_group_id" int4;
+
+COMMENT ON COLUMN "public"."game_shop_group"."game_group_id" IS '游戏端群组标识，未同步为空';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_shop_group_game_group"
+    ON "public"."game_shop_group" ("house_gid", "game_group_id")
+    WHERE is_active = true AND game_group_id IS NOT NULL;
+
+INSERT INTO "public"."basic_permission" ("code", "name", "category", "description") VALUES
+('shop:group:sync', '同步圈子', 'shop', '通过中控会话在游戏端创建/修改/删除群组，导入并对账')
+ON CONFLICT (code) WHERE is_deleted = false DO NOTHING;
+
+INSERT INTO "public"."basic_role_permission_rel" ("role_id", "permission_id")
+SELECT r.role_id, p.id FROM "public"."basic_permission" p
+CROSS JOIN (VALUES (1), (2)) AS r(role_id)
+WHERE p.code = 'shop:group:sync' AND p.is_deleted = false
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,133 @@
+// Package auditx 审计记录的上下文载体。
+// HTTP 层由 middleware.AuditTrail 在请求上下文中放入 *Entry，业务层通过 SetTarget/SetBefore/SetAfter/SetReason
+// 补充对象与变更前后的数据，请求结束后统一写入 Sink；非 HTTP 场景（定时任务等）可直接调用 Write。
+package auditx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Entry 一条审计记录
+type Entry struct {
+	mu sync.Mutex
+
+	Platform   string
+	ActorID    int32
+	ActorName  string
+	HouseGID   int32
+	Action     string // 与 RequirePerm 相同的权限码
+	Method     string
+	Path       string
+	TargetType string
+	TargetIDs  []string
+	Before     any
+	After      any
+	Reason     string
+	ClientIP   string
+	UserAgent  string
+	Success    bool
+	ResultCode int
+	ResultMsg  string
+	LatencyMs  int64
+	CreatedAt  time.Time
+}
+
+// Sink 审计落库
+type Sink interface {
+	WriteAudit(ctx context.Context, e *Entry) error
+}
+
+var globalSink Sink
+
+// BindSink 在应用启动时注入审计落库实现
+func BindSink(s Sink) { globalSink = s }
+
+// Enabled 是否已注入落库实现
+func Enabled() bool { return globalSink != nil }
+
+type ctxKey struct{}
+
+// WithEntry 把审计记录放入上下文
+func WithEntry(ctx context.Context, e *Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, e)
+}
+
+// FromContext 取当前请求的审计记录（无则返回 nil）
+func FromContext(ctx context.Context) *Entry {
+	e, _ := ctx.Value(ctxKey{}).(*Entry)
+	return e
+}
+
+// SetAction 覆盖操作码（默认取路由上的权限码）
+func SetAction(ctx context.Context, action string) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.Action = action
+		e.mu.Unlock()
+	}
+}
+
+// SetHouse 设置店铺号
+func SetHouse(ctx context.Context, houseGID int32) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.HouseGID = houseGID
+		e.mu.Unlock()
+	}
+}
+
+// SetTarget 设置操作对象（类型 + ID 列表）
+func SetTarget(ctx context.Context, targetType string, ids ...any) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.TargetType = targetType
+		e.TargetIDs = e.TargetIDs[:0]
+		for _, id := range ids {
+			e.TargetIDs = append(e.TargetIDs, fmt.Sprint(id))
+		}
+		e.mu.Unlock()
+	}
+}
+
+// SetBefore 记录变更前的数据
+func SetBefore(ctx context.Context, v any) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.Before = v
+		e.mu.Unlock()
+	}
+}
+
+// SetAfter 记录变更后的数据
+func SetAfter(ctx context.Context, v any) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.After = v
+		e.mu.Unlock()
+	}
+}
+
+// SetReason 记录操作原因/备注
+func SetReason(ctx context.Context, reason string) {
+	if e := FromContext(ctx); e != nil {
+		e.mu.Lock()
+		e.Reason = reason
+		e.mu.Unlock()
+	}
+}
+
+// Write 直接写一条审计记录（未注入 Sink 时忽略）
+func Write(ctx context.Context, e *Entry) error {
+	if globalSink == nil || e == nil {
+		return nil
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return globalSink.WriteAudit(ctx, e)
+}
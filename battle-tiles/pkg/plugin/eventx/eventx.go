@@ -0,0 +1,100 @@
+// Package eventx 业务事件发布。
+// 业务层在动作完成（事务提交）后调用 Publish，由启动时注入的 Publisher（如店铺 webhook 投递）异步处理；
+// 未注入时为空操作，发布失败只记日志，不影响主流程。
+package eventx
+
+import (
+	"context"
+	"time"
+
+	pdb "battle-tiles/pkg/plugin/dbx"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+// 事件类型
+const (
+	TypeFundsDeposit        = "funds.deposit"           // 上分入账
+	TypeFundsWithdraw       = "funds.withdraw"          // 下分（含强制下分）入账
+	TypeMemberForbidden     = "member.forbidden"        // 成员被禁分
+	TypeMemberUnforbidden   = "member.unforbidden"      // 成员解除禁分
+	TypeMemberLowBalance    = "member.low_balance"      // 成员余额跌破阈值（按订阅方阈值派生）
+	TypeApplicationReceived = "application.received"    // 收到游戏内入圈申请
+	TypeApplicationDecided  = "application.decided"     // 申请已通过/拒绝
+	TypeTableDismissed      = "table.dismissed"         // 桌台解散
+	TypeSessionOffline      = "session.offline"         // 中控会话下线
+	TypeBattleIngested      = "battle.ingested"         // 战绩入库
+	TypeMemberBlockKicked   = "member.blocklist_kicked" // 黑名单成员落座被踢出
+	TypeHouseDiamondLow     = "house.diamond_low"       // 中控钻石余额跌破店铺告警线
+)
+
+// Types 全部可订阅的事件类型
+var Types = []string{
+	TypeFundsDeposit,
+	TypeFundsWithdraw,
+	TypeMemberForbidden,
+	TypeMemberUnforbidden,
+	TypeMemberLowBalance,
+	TypeApplicationReceived,
+	TypeApplicationDecided,
+	TypeTableDismissed,
+	TypeSessionOffline,
+	TypeBattleIngested,
+	TypeMemberBlockKicked,
+	TypeHouseDiamondLow,
+}
+
+// Event 一条业务事件
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Platform   string    `json:"-"`
+	HouseGID   int32     `json:"house_gid"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Publisher 事件处理方
+type Publisher interface {
+	PublishEvent(ctx context.Context, e *Event) error
+}
+
+var globalPublisher Publisher
+
+// Bind 在应用启动时注入事件处理方
+func Bind(p Publisher) { globalPublisher = p }
+
+// Enabled 是否已注入事件处理方
+func Enabled() bool { return globalPublisher != nil }
+
+// Publish 异步发布事件。ctx 只用来取平台标识，处理时换成独立的上下文，请求结束不影响投递
+func Publish(ctx context.Context, houseGID int32, typ string, data any) {
+	p := globalPublisher
+	if p == nil || ctx == nil {
+		return
+	}
+	e := &Event{
+		ID:         uuid.NewString(),
+		Type:       typ,
+		Platform:   pdb.GetDBKeyFromCtx(ctx),
+		HouseGID:   houseGID,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+	if e.Platform == "" {
+		log.Warnf("eventx: drop %s house=%d: platform not found in context", typ, houseGID)
+		return
+	}
+	bg := pdb.NewCtxWithDB(ctx)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("eventx: publish %s house=%d panic: %v", typ, houseGID, r)
+			}
+		}()
+		if err := p.PublishEvent(bg, e); err != nil {
+			log.Errorf("eventx: publish %s house=%d: %v", typ, houseGID, err)
+		}
+	}()
+}
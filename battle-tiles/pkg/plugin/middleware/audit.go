@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"battle-tiles/pkg/plugin/auditx"
+	pdb "battle-tiles/pkg/plugin/dbx"
+	"battle-tiles/pkg/utils/request"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	ctxAuditActionKey = "audit_action"
+	auditBodyLimit    = 4 << 10
+)
+
+// 只读权限码后缀，命中的路由不记审计
+var readOnlyPermSuffixes = []string{":view", ":export", ":list"}
+
+// AuditTrail 全局审计中间件（挂在 Engine 上，先于业务路由）。
+// 路由经 RequirePerm / RequireAnyPerm / RequireHousePerm 放行时会把非只读的权限码记为操作码，
+// 也可以用 Audit(action) 显式指定；没有操作码的请求不落审计。
+func AuditTrail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auditx.Enabled() || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		e := &auditx.Entry{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			CreatedAt: start,
+		}
+		if houseGID, _, ok, _ := scopeFromRequest(c); ok {
+			e.HouseGID = houseGID
+		}
+		c.Request = c.Request.WithContext(auditx.WithEntry(c.Request.Context(), e))
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		if action := c.GetString(ctxAuditActionKey); action != "" && e.Action == "" {
+			e.Action = action
+		}
+		if e.Action == "" {
+			return
+		}
+		if v, ok := c.Get("claims"); ok {
+			if claims, ok := v.(*request.CustomClaims); ok {
+				e.ActorID = claims.BaseClaims.UserID
+				e.ActorName = claims.BaseClaims.Username
+			}
+		}
+		ctx := c.Request.Context()
+		e.Platform = pdb.GetDBKeyFromCtx(ctx)
+		e.LatencyMs = time.Since(start).Milliseconds()
+
+		var body struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		e.ResultCode = -1
+		if json.Unmarshal(w.buf.Bytes(), &body) == nil {
+			e.ResultCode = body.Code
+			e.ResultMsg = body.Msg
+		}
+		e.Success = w.Status() < http.StatusBadRequest && e.ResultCode == 0
+
+		if err := auditx.Write(context.WithoutCancel(ctx), e); err != nil {
+			log.Errorf("write audit log failed: action=%s path=%s err=%v", e.Action, e.Path, err)
+		}
+	}
+}
+
+// Audit 显式指定路由的审计操作码（用于没有挂权限校验的写接口）
+func Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ctxAuditActionKey, action)
+		c.Next()
+	}
+}
+
+// markAudit 权限校验通过后记录操作码：取第一个非只读的权限码
+func markAudit(c *gin.Context, perms []string) {
+	if c.GetString(ctxAuditActionKey) != "" {
+		return
+	}
+	for _, p := range perms {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !isReadOnlyPerm(p) {
+			c.Set(ctxAuditActionKey, p)
+			return
+		}
+	}
+}
+
+func isReadOnlyPerm(p string) bool {
+	for _, s := range readOnlyPermSuffixes {
+		if strings.HasSuffix(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditWriter 截留响应体前 4KB，用于解析业务返回码
+type auditWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if rest := auditBodyLimit - w.buf.Len(); rest > 0 {
+		if len(b) > rest {
+			w.buf.Write(b[:rest])
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
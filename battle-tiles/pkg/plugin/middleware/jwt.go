@@ -4,9 +4,11 @@ import (
 	pdb "battle-tiles/pkg/plugin/dbx"
 	"battle-tiles/pkg/utils"
 	"battle-tiles/pkg/utils/ecode"
+	"battle-tiles/pkg/utils/request"
 	"battle-tiles/pkg/utils/response"
 	"context"
 	"errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,6 +49,12 @@ func JWTAuth() gin.HandlerFunc {
 		}
 		ctx := c.Request.Context()
 		ctx = context.WithValue(ctx, pdb.CtxDBKey, dbName)
+		if err = checkSession(ctx, claims); err != nil {
+			response.Fail(c, ecode.TokenValidateFailed, err)
+			utils.ClearToken(c)
+			c.Abort()
+			return
+		}
 		c.Request = c.Request.WithContext(ctx)
 		c.Set("claims", claims)
 		c.Next()
@@ -88,9 +96,30 @@ func WSJWTAuth() gin.HandlerFunc {
 		}
 		ctx := c.Request.Context()
 		ctx = context.WithValue(ctx, pdb.CtxDBKey, dbName)
+		if err = checkSession(ctx, claims); err != nil {
+			response.Fail(c, ecode.TokenValidateFailed, err)
+			c.Abort()
+			return
+		}
 		c.Request = c.Request.WithContext(ctx)
 		c.Set("claims", claims)
 		c.Next()
 
 	}
 }
+
+// checkSession 校验 token 所属会话：已登出/被吊销的会话、权限变更前签发的 token 一律拒绝
+func checkSession(ctx context.Context, claims *request.CustomClaims) error {
+	sc := sessionChecker()
+	if sc == nil {
+		return nil
+	}
+	if claims.SessionID == "" {
+		return errors.New("会话已失效，请重新登录")
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return sc.CheckSession(ctx, claims.UserID, claims.SessionID, issuedAt)
+}
@@ -20,6 +20,7 @@ func RequirePerm(perms ...string) gin.HandlerFunc {
 		}
 		// 超级管理员直接放行（角色ID=1）
 		if claims.BaseClaims.IsSuperAdmin() {
+			markAudit(c, perms)
 			c.Next()
 			return
 		}
@@ -42,6 +43,7 @@ func RequirePerm(perms ...string) gin.HandlerFunc {
 				return
 			}
 		}
+		markAudit(c, perms)
 		c.Next()
 	}
 }
@@ -57,6 +59,7 @@ func RequireAnyPerm(perms ...string) gin.HandlerFunc {
 		}
 		// 超级管理员直接放行
 		if claims.BaseClaims.IsSuperAdmin() {
+			markAudit(c, perms)
 			c.Next()
 			return
 		}
@@ -74,6 +77,7 @@ func RequireAnyPerm(perms ...string) gin.HandlerFunc {
 		for _, p := range perms {
 			p = strings.ToLower(strings.TrimSpace(p))
 			if _, ok := set[p]; ok {
+				markAudit(c, perms)
 				c.Next()
 				return
 			}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"battle-tiles/pkg/utils/request"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeScopedStore houseGID -> 用户在该店铺的权限码
+type fakeScopedStore struct {
+	houses map[int32][]string
+}
+
+func (fakeScopedStore) GetUserPermCodes(context.Context, int32) (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
+func (s fakeScopedStore) GetUserHousePermCodes(_ context.Context, _, houseGID, _ int32) (map[string]struct{}, error) {
+	return permSet(s.houses[houseGID]), nil
+}
+
+func TestRequireHousePermScopeConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	BindPermissionStore(fakeScopedStore{houses: map[int32][]string{100: {"shop:member:kick"}}})
+	t.Cleanup(func() { globalStore = nil })
+
+	var executed int32
+	r := gin.New()
+	r.POST("/kick", func(c *gin.Context) {
+		c.Set("claims", &request.CustomClaims{BaseClaims: request.BaseClaims{UserID: 7}})
+	}, RequireHousePerm("shop:member:kick"), func(c *gin.Context) {
+		executed, _ = ScopeHouseGID(c)
+		c.Status(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name   string
+		query  string
+		header string
+		body   string
+		want   int32
+	}{
+		{name: "body only", body: `{"house_gid":100}`, want: 100},
+		{name: "query and body agree", query: "?house_gid=100", body: `{"house_gid":100}`, want: 100},
+		{name: "query vs body", query: "?house_gid=100", body: `{"house_gid":200}`},
+		{name: "header vs body", header: "100", body: `{"house_gid":200}`},
+		{name: "unparseable query", query: "?house_gid=abc", body: `{"house_gid":100}`},
+		{name: "no permission in house", body: `{"house_gid":200}`},
+	}
+	for _, tc := range cases {
+		executed = 0
+		req := httptest.NewRequest(http.MethodPost, "/kick"+tc.query, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.header != "" {
+			req.Header.Set("X-House-GID", tc.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if executed != tc.want {
+			t.Errorf("%s: handler ran with house %d, want %d (response %s)", tc.name, executed, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestRequireHousePermAPIKeyHouses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// 内层 store 给所有店铺授权，限制只能来自 Key 的 HouseGIDs
+	BindPermissionStore(fakeScopedStore{houses: map[int32][]string{100: {"fund:deposit"}, 200: {"fund:deposit"}}})
+	t.Cleanup(func() { globalStore = nil })
+
+	var executed int32
+	r := gin.New()
+	r.POST("/deposit", func(c *gin.Context) {
+		p := &APIKeyPrincipal{KeyID: 1, UserID: 9, Perms: []string{"fund:deposit"}, HouseGIDs: []int32{100}}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), apiKeyCtxKey{}, p))
+		c.Set("claims", &request.CustomClaims{BaseClaims: request.BaseClaims{UserID: 9}})
+	}, RequireHousePerm("fund:deposit"), func(c *gin.Context) {
+		executed, _ = ScopeHouseGID(c)
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		body string
+		want int32
+	}{
+		{body: `{"house_gid":100}`, want: 100},
+		{body: `{"house_gid":200}`},
+	} {
+		executed = 0
+		req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if executed != tc.want {
+			t.Errorf("%s: handler ran with house %d, want %d (response %s)", tc.body, executed, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestHasHousePerm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	BindPermissionStore(fakeScopedStore{houses: map[int32][]string{100: {"shop:member:view", "fund:wallet:view"}}})
+	t.Cleanup(func() { globalStore = nil })
+
+	var wallet, ledger bool
+	r := gin.New()
+	r.POST("/profile", func(c *gin.Context) {
+		c.Set("claims", &request.CustomClaims{BaseClaims: request.BaseClaims{UserID: 7}})
+	}, RequireHousePerm("shop:member:view"), func(c *gin.Context) {
+		wallet, ledger = HasHousePerm(c, "fund:wallet:view"), HasHousePerm(c, "FUND:ledger:view")
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(`{"house_gid":100}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if !wallet || ledger {
+		t.Fatalf("wallet = %v, ledger = %v, want true/false", wallet, ledger)
+	}
+}
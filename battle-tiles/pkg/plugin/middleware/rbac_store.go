@@ -8,8 +8,14 @@ type PermissionStore interface {
 	GetUserPermCodes(ctx context.Context, userID int32) (map[string]struct{}, error)
 }
 
+// ScopedPermissionStore：按店铺/圈作用域返回权限码（RequireHousePerm 使用）
+type ScopedPermissionStore interface {
+	// 返回用户在店铺（groupID > 0 时含该圈）内通过作用域角色获得的权限码集合
+	GetUserHousePermCodes(ctx context.Context, userID, houseGID, groupID int32) (map[string]struct{}, error)
+}
+
 // 全局绑定（通过 Init 在应用启动时注入）
 var globalStore PermissionStore
 
-func BindPermissionStore(s PermissionStore) { globalStore = s }
+func BindPermissionStore(s PermissionStore) { globalStore = apiKeyAwareStore{inner: s} }
 func store() PermissionStore                { return globalStore }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"context"
+	"time"
+)
+
+// SessionChecker：校验 access token 对应的登录会话是否仍有效（未登出/未吊销/权限未变更）
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID int32, sid string, issuedAt time.Time) error
+}
+
+// 全局绑定（应用启动时注入；未注入时只校验签名与过期）
+var globalSessionChecker SessionChecker
+
+func BindSessionChecker(s SessionChecker) { globalSessionChecker = s }
+func sessionChecker() SessionChecker      { return globalSessionChecker }
@@ -62,6 +62,12 @@ func GetWSToken(c *gin.Context) string {
 }
 
 func GetClaims(c *gin.Context) (*request.CustomClaims, error) {
+	// 认证中间件已解析过的（含 API Key 认证的服务账号）直接复用
+	if v, ok := c.Get("claims"); ok {
+		if claims, ok := v.(*request.CustomClaims); ok {
+			return claims, nil
+		}
+	}
 	token := GetToken(c)
 	j := NewJWT()
 	claims, err := j.ParseToken(token)
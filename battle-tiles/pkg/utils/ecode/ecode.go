@@ -36,6 +36,8 @@ const (
 	ParamsAnalysisFailed      = 4031 // 参数解析错误
 	SameDataSaveFailed        = 4032 // 已存在相同数据，保存失败
 	Unauthorized              = 4033 // 未授权
+	LoginLocked               = 4034 // 登录失败次数过多，已锁定
+	TOTPRequired              = 4035 // 需要二次验证动态码
 )
 
 func Text(code int) string {
@@ -35,4 +35,6 @@ var zhCNText = map[int]string{
 	WebSocketCreateConnFailed: "创建websocket连接失败",
 	ParamsAnalysisFailed:      "参数解析异常",
 	SameDataSaveFailed:        "已存在相同数据，保存失败",
+	LoginLocked:               "登录失败次数过多，已锁定",
+	TOTPRequired:              "需要二次验证动态码",
 }
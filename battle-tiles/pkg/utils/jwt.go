@@ -14,8 +14,9 @@ import (
 
 const (
 	SigningKey                = "mc"
-	HeaderSignTokenBufferTime = "1d" // HeaderSignTokenBufferTime 签名验证 缓冲时间
-	HeaderSignTokenExpires    = "7d" // HeaderSignTokenExpires 签名有效期为 天
+	HeaderSignTokenBufferTime = "1d"  // HeaderSignTokenBufferTime 签名验证 缓冲时间
+	HeaderSignTokenExpires    = "7d"  // HeaderSignTokenExpires 签名有效期为 天
+	AccessTokenExpires        = "15m" // AccessTokenExpires access token 有效期，过期后用刷新令牌换新
 )
 
 var singleGroup = &singleflight.Group{}
@@ -61,6 +62,7 @@ func NewJWT() *JWT {
 
 func (j *JWT) CreateClaims(baseClaims request.BaseClaims) request.CustomClaims {
 	bf, _ := ParseDuration(HeaderSignTokenBufferTime)
+	ttl, _ := ParseDuration(AccessTokenExpires)
 	now := time.Now()
 
 	claims := request.CustomClaims{
@@ -71,7 +73,7 @@ func (j *JWT) CreateClaims(baseClaims request.BaseClaims) request.CustomClaims {
 			Audience:  jwt.ClaimStrings{SigningKey},                  // 受众
 			NotBefore: jwt.NewNumericDate(now.Add(-1 * time.Minute)), // 签名生效时间（提前1分钟，避免时钟偏移问题）
 			IssuedAt:  jwt.NewNumericDate(now),                       // 签发时间
-			ExpiresAt: jwt.NewNumericDate(now.Add(bf)),               // 过期时间 1天  配置文件
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),              // 过期时间 短期，靠刷新令牌续期
 			Issuer:    SigningKey,                                    // 签名的发行者
 		},
 	}
@@ -162,8 +164,9 @@ func (j *JWT) ParseToken(tokenString string) (*request.CustomClaims, error) {
 			if ve.Errors&jwt.ValidationErrorMalformed != 0 {
 				return nil, TokenMalformed
 			} else if ve.Errors&jwt.ValidationErrorExpired != 0 {
+				// 过期时仍返回 claims 供调用方读取用户信息，但必须带上错误
 				if claims, ok := token.Claims.(*request.CustomClaims); ok {
-					return claims, nil
+					return claims, TokenExpired
 				}
 				// Token is expired
 				return nil, TokenExpired
@@ -19,6 +19,8 @@ type BaseClaims struct {
 
 	Roles []int32  `json:"roles,omitempty"`
 	Perms []string `json:"perms,omitempty"`
+
+	SessionID string `json:"sid,omitempty"` // 登录会话ID，JWTAuth 据此校验是否已被吊销
 }
 
 func (b BaseClaims) IsSuperAdmin() bool {
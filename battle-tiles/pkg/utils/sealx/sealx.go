@@ -0,0 +1,171 @@
+// Package sealx 信封加密：每条数据随机生成数据密钥（DEK）做 AES-256-GCM 加密，
+// DEK 再由按版本管理的主密钥（KEK）加密后与密文存在一起。轮换主密钥时只需用新版本重新封装。
+package sealx
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	keySize   = 32
+	nonceSize = 12
+	// 封装后的 DEK 长度：DEK + GCM tag
+	wrappedSize = keySize + 16
+)
+
+var (
+	ErrNoActiveKey = errors.New("sealx: active key not configured")
+	ErrUnknownKey  = errors.New("sealx: unknown key version")
+	ErrMalformed   = errors.New("sealx: malformed ciphertext")
+)
+
+// Keyring 按版本保存主密钥；Seal 总是使用 active 版本，Open 按密文记录的版本取钥
+type Keyring struct {
+	active int32
+	keys   map[int32][]byte
+}
+
+// NewKeyring keys 为 版本 -> base64 编码的 32 字节密钥；版本号必须大于 0（0 保留给未加密的历史数据）
+func NewKeyring(active int32, keys map[int32]string) (*Keyring, error) {
+	kr := &Keyring{active: active, keys: make(map[int32][]byte, len(keys))}
+	for ver, b64 := range keys {
+		if ver <= 0 {
+			return nil, fmt.Errorf("sealx: invalid key version %d", ver)
+		}
+		k, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("sealx: decode key v%d: %w", ver, err)
+		}
+		if len(k) != keySize {
+			return nil, fmt.Errorf("sealx: key v%d must be %d bytes, got %d", ver, keySize, len(k))
+		}
+		kr.keys[ver] = k
+	}
+	if active != 0 {
+		if _, ok := kr.keys[active]; !ok {
+			return nil, fmt.Errorf("sealx: active key v%d not in keyring", active)
+		}
+	}
+	return kr, nil
+}
+
+// Active 当前写入使用的版本（0 表示未配置）
+func (k *Keyring) Active() int32 {
+	if k == nil {
+		return 0
+	}
+	return k.active
+}
+
+// Versions 已加载的全部版本（升序）
+func (k *Keyring) Versions() []int32 {
+	if k == nil {
+		return nil
+	}
+	out := make([]int32, 0, len(k.keys))
+	for v := range k.keys {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Seal 用 active 版本加密，返回 base64 密文与版本号
+func (k *Keyring) Seal(plain string) (string, int32, error) {
+	if k == nil || k.active == 0 {
+		return "", 0, ErrNoActiveKey
+	}
+	kek := k.keys[k.active]
+
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", 0, err
+	}
+	wrapped, err := gcmSeal(kek, dek, versionAAD(k.active))
+	if err != nil {
+		return "", 0, err
+	}
+	body, err := gcmSeal(dek, []byte(plain), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	buf := make([]byte, 0, len(wrapped)+len(body))
+	buf = append(buf, wrapped...)
+	buf = append(buf, body...)
+	return base64.StdEncoding.EncodeToString(buf), k.active, nil
+}
+
+// Open 按版本解密 Seal 的结果
+func (k *Keyring) Open(sealed string, ver int32) (string, error) {
+	if k == nil {
+		return "", ErrUnknownKey
+	}
+	kek, ok := k.keys[ver]
+	if !ok {
+		return "", fmt.Errorf("%w: v%d", ErrUnknownKey, ver)
+	}
+	buf, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(buf) < 2*nonceSize+wrappedSize+16 {
+		return "", ErrMalformed
+	}
+	split := nonceSize + wrappedSize
+	dek, err := gcmOpen(kek, buf[:split], versionAAD(ver))
+	if err != nil {
+		return "", err
+	}
+	plain, err := gcmOpen(dek, buf[split:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// 版本号参与 DEK 封装的认证，防止把密文挪到别的版本下解
+func versionAAD(ver int32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(ver))
+	return b
+}
+
+// gcmSeal 输出 nonce || ciphertext
+func gcmSeal(key, plain, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, aad), nil
+}
+
+func gcmOpen(key, data, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < nonceSize {
+		return nil, ErrMalformed
+	}
+	out, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], aad)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return out, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
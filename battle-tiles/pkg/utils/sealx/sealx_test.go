@@ -0,0 +1,73 @@
+package sealx
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), keySize)))
+}
+
+func TestSealOpenAndRotate(t *testing.T) {
+	const plain = "E10ADC3949BA59ABBE56E057F20F883E"
+
+	v1, err := NewKeyring(1, map[int32]string{1: testKey('a')})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, ver, err := v1.Seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ver != 1 || strings.Contains(sealed, plain) {
+		t.Fatalf("unexpected seal result ver=%d sealed=%s", ver, sealed)
+	}
+	if len(sealed) > 255 {
+		t.Fatalf("sealed value too long for column: %d", len(sealed))
+	}
+
+	// 轮换：v2 为 active，v1 仍可解
+	v2, err := NewKeyring(2, map[int32]string{1: testKey('a'), 2: testKey('b')})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v2.Open(sealed, ver)
+	if err != nil || got != plain {
+		t.Fatalf("open old version: got=%q err=%v", got, err)
+	}
+	resealed, ver2, err := v2.Seal(got)
+	if err != nil || ver2 != 2 {
+		t.Fatalf("reseal: ver=%d err=%v", ver2, err)
+	}
+	if got, err = v2.Open(resealed, ver2); err != nil || got != plain {
+		t.Fatalf("open new version: got=%q err=%v", got, err)
+	}
+
+	// 版本号被篡改
+	if _, err = v2.Open(sealed, 2); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for wrong version, got %v", err)
+	}
+	// 旧版本下线后无法再解
+	if _, err = v1.Open(resealed, 2); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestNewKeyringValidation(t *testing.T) {
+	if _, err := NewKeyring(2, map[int32]string{1: testKey('a')}); err == nil {
+		t.Fatal("expected error for missing active key")
+	}
+	if _, err := NewKeyring(1, map[int32]string{1: "c2hvcnQ="}); err == nil {
+		t.Fatal("expected error for short key")
+	}
+	kr, err := NewKeyring(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = kr.Seal("x"); !errors.Is(err, ErrNoActiveKey) {
+		t.Fatalf("expected ErrNoActiveKey, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量（8 位）
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, c := range cases {
+		got, err := TOTPCode(secret, c.unix/TOTPPeriod, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("T=%d: got %s, want %s", c.unix, got, c.want)
+		}
+	}
+}
+
+func TestVerifyTOTP_Skew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	prev, _ := TOTPCode(secret, TOTPStep(now)-1, TOTPDigits)
+	if step, ok := VerifyTOTP(secret, prev, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("previous step should be accepted, got step=%d ok=%v", step, ok)
+	}
+	old, _ := TOTPCode(secret, TOTPStep(now)-3, TOTPDigits)
+	if _, ok := VerifyTOTP(secret, old, now, 1); ok {
+		t.Fatal("code outside skew window should be rejected")
+	}
+	if _, ok := VerifyTOTP(secret, "12345", now, 1); ok {
+		t.Fatal("short code should be rejected")
+	}
+}
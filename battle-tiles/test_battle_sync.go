@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	plazautils "battle-tiles/internal/utils/plaza"
@@ -12,7 +13,11 @@ import (
 func main() {
 	ctx := context.Background()
 	httpClient := &http.Client{Timeout: 10 * time.Second}
-	baseURL := "http://phone2.foxuc.com/Ashx/GroService.ashx"
+	// 地址与私钥同 configs/config.yaml 的 global.game.gro，可指向 mock
+	gro := plazautils.GroEndpoint{BaseURL: os.Getenv("GRO_BATTLE_URL"), PrivateKey: os.Getenv("GRO_PRIVATE_KEY")}
+	if gro.BaseURL == "" {
+		gro.BaseURL = "http://phone2.foxuc.com/Ashx/GroService.ashx"
+	}
 	houseGID := 60870
 
 	// 测试不同的时间范围
@@ -27,7 +32,7 @@ func main() {
 
 	for _, t := range typeids {
 		fmt.Printf("\n=== 测试 %s (typeid=%d) ===\n", t.desc, t.id)
-		battles, err := plazautils.GetGroupNewBattleInfoCtx(ctx, httpClient, baseURL, houseGID, t.id)
+		battles, err := plazautils.GetGroupNewBattleInfoCtx(ctx, httpClient, gro, houseGID, t.id)
 		if err != nil {
 			fmt.Printf("错误: %v\n", err)
 			continue